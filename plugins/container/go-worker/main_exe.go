@@ -5,11 +5,12 @@ package main
 /*
 #include <stdio.h>
 #include <stdbool.h>
-void echo_cb(const char *json, bool added, bool initial_state) {
+void echo_cb(const char *json, int kind, bool initial_state) {
 	if (initial_state) {
 		printf("[Pre-existing] Json: %s\n", json);
 	} else {
-		printf("[%s] Json: %s\n", added ? "Added" : "Removed", json);
+		const char *kinds[] = {"Removed", "Added", "Updated", "Died", "Paused", "Unpaused", "OOMKilled", "Stats"};
+		printf("[%s] Json: %s\n", kinds[kind], json);
 	}
 }
 */
@@ -59,7 +60,7 @@ func main() {
 			 "enabled":true,
 			 "sockets":[
 				"/run/podman/podman.sock",
-				"/run/user/1000/podman/podman.sock"
+				"/run/user/*/podman/podman.sock"
 			 ]
 		  }
       }
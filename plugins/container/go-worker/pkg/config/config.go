@@ -5,19 +5,237 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"sync"
+	"time"
 )
 
 const (
 	HookCreate = 1 << iota
 	HookStart
 	HookRemove
+	HookDie
+	HookPause
+	HookHealth
+	HookOOM
 
-	defaultLabelMaxLen = 100
+	defaultLabelMaxLen      = 100
+	defaultListConcurrency  = 10
+	defaultInspectTimeoutMs = 5000
+	defaultListTimeoutMs    = 30000
+	defaultCacheTTLMs       = 60000
+	defaultCacheMaxEntries  = 4096
+	defaultEventQueueSize   = 1024
+
+	defaultConnectRetryMaxBackoffMs = 30000
+
+	defaultCircuitBreakerCooldownMs = 30000
+
+	defaultDigestResolutionTimeoutMs  = 3000
+	defaultDigestResolutionCacheTTLMs = 3600000
+
+	defaultEcsMetadataTimeoutMs  = 2000
+	defaultEcsMetadataCacheTTLMs = 300000
+
+	defaultNomadAddress    = "http://127.0.0.1:4646"
+	defaultNomadTimeoutMs  = 2000
+	defaultNomadCacheTTLMs = 300000
+
+	// EventQueueBlock makes the engines wait for the plugin to consume events when the event queue is full.
+	EventQueueBlock = "block"
+	// EventQueueDropOldest makes room for new events by dropping the oldest queued added, updated and stats ones.
+	EventQueueDropOldest = "drop_oldest"
 )
 
+// defaultIgnoredImages returns the images of the kubernetes pod sandbox (pause) containers.
+func defaultIgnoredImages() []string {
+	return []string{
+		"registry.k8s.io/pause",
+		"k8s.gcr.io/pause",
+		"gcr.io/google_containers/pause*",
+		"mcr.microsoft.com/oss/kubernetes/pause",
+		"*.dkr.ecr.*.amazonaws.com/eks/pause",
+	}
+}
+
+// EngineTLS is the TLS material used to attach to remote engine endpoints (ie: docker "tcp://" sockets).
+type EngineTLS struct {
+	// CA, Cert and Key are paths to PEM files.
+	CA   string `json:"ca"`
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+	// ServerName pins the name verified against the daemon certificate, instead of the endpoint host.
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Enabled returns whether any TLS option is set.
+func (t EngineTLS) Enabled() bool {
+	return t.CA != "" || t.Cert != "" || t.ServerName != "" || t.InsecureSkipVerify
+}
+
 type SocketsEngine struct {
 	Enabled bool     `json:"enabled"`
 	Sockets []string `json:"sockets"`
+	// TimeoutMs overrides InspectTimeoutMs for the engine, when set.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// ListTimeoutMs overrides ListTimeoutMs for the engine, when set.
+	ListTimeoutMs int `json:"list_timeout_ms,omitempty"`
+	// TLS is used by remote sockets, where supported (ie: docker).
+	TLS EngineTLS `json:"tls,omitzero"`
+	// TLSEndpoints overrides TLS for specific remote sockets, by socket.
+	TLSEndpoints map[string]EngineTLS `json:"tls_endpoints,omitempty"`
+	// Namespaces restricts the engine to the specified namespaces, where supported (ie: containerd).
+	// When empty, all namespaces are considered.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// LogLevel overrides the worker log level for the engine, when set.
+	LogLevel string `json:"log_level,omitempty"`
+	// Fallback attaches the engine to the first responsive socket only, probing them again in order
+	// whenever its events stream ends, where supported (ie: cri).
+	Fallback bool `json:"fallback,omitempty"`
+	// Contexts are docker CLI contexts names whose endpoints the engine attaches to, where supported (ie: docker).
+	Contexts []string `json:"contexts,omitempty"`
+	// Rootless attaches the engine to the rootless daemons of all users too, where supported (ie: docker).
+	Rootless bool `json:"rootless,omitempty"`
+	// SimulateCfg shapes the synthetic containers, where supported (ie: simulate).
+	SimulateCfg
+}
+
+// SimulateCfg configures the synthetic containers generated by the simulate engine, for load testing.
+type SimulateCfg struct {
+	// CreateRate is the number of containers created per second.
+	CreateRate int `json:"create_rate,omitempty"`
+	// LifetimeMs is how long containers run before being removed; 0 never removes them.
+	LifetimeMs int `json:"lifetime_ms,omitempty"`
+	// MaxContainers bounds the running containers, pausing the creations when reached; 0 is unbounded.
+	MaxContainers int `json:"max_containers,omitempty"`
+	// Seed makes the generated metadata reproducible; 0 picks a random one.
+	Seed uint64 `json:"seed,omitempty"`
+}
+
+// RegistryAuth holds the credentials to access a registry.
+type RegistryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// DigestResolutionCfg configures the lookup of the digest of images only referenced by tag,
+// from their registries.
+type DigestResolutionCfg struct {
+	Enabled bool `json:"enabled"`
+	// TimeoutMs bounds each registry lookup.
+	TimeoutMs int `json:"timeout_ms"`
+	// CacheTTLMs is the expiration of resolved digests.
+	CacheTTLMs int `json:"cache_ttl_ms"`
+	// Auths are the registries credentials, by registry host (eg: "docker.io").
+	Auths map[string]RegistryAuth `json:"auths,omitempty"`
+}
+
+// EcsMetadataCfg configures the lookup of the ECS task metadata of containers
+// from the task metadata endpoint (v4).
+type EcsMetadataCfg struct {
+	Enabled bool `json:"enabled"`
+	// TimeoutMs bounds each task metadata lookup.
+	TimeoutMs int `json:"timeout_ms"`
+	// CacheTTLMs is the expiration of the task metadata, shared by the containers of a task.
+	CacheTTLMs int `json:"cache_ttl_ms"`
+}
+
+// NomadCfg configures the lookup of the allocations of nomad task containers
+// from the local nomad client API.
+type NomadCfg struct {
+	Enabled bool `json:"enabled"`
+	// Address is the nomad HTTP API address, eg: "http://127.0.0.1:4646".
+	Address string `json:"address"`
+	// Token is the ACL token used to read allocations, if ACLs are enabled.
+	Token string `json:"token,omitempty"`
+	// TimeoutMs bounds each allocation lookup.
+	TimeoutMs int `json:"timeout_ms"`
+	// CacheTTLMs is the expiration of the allocations, shared by the containers of an allocation.
+	CacheTTLMs int `json:"cache_ttl_ms"`
+}
+
+// EnvCfg configures which containers environment variables are reported.
+// Patterns are shell globs (see path.Match) matched against variables names.
+type EnvCfg struct {
+	// Allowlist restricts the reported variables to the matching ones; all of them when empty.
+	Allowlist []string `json:"allowlist,omitempty"`
+	// Redact are the variables whose values are replaced with a placeholder, eg: "*PASSWORD*".
+	Redact []string `json:"redact,omitempty"`
+}
+
+// AnnotationsCfg configures which CRI containers and pods annotations are reported.
+// Patterns are shell globs (see path.Match) matched against annotations keys, eg: "sidecar.istio.io/*".
+type AnnotationsCfg struct {
+	// Include are the reported annotations; none of them when empty.
+	Include []string `json:"include,omitempty"`
+}
+
+// LabelSelectorsCfg scopes the reported containers by their labels, including their pod ones.
+// Each selector is a comma-separated list of requirements, all of them to be satisfied:
+// "key=value", "key!=value", "key" (the label exists) or "!key" (the label does not exist).
+type LabelSelectorsCfg struct {
+	// Include restricts the reported containers to the ones matching any selector; all of them when empty.
+	Include []string `json:"include,omitempty"`
+	// Exclude drops the containers matching any selector.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IgnoreCfg configures the infrastructure containers whose events are not sent, nor cached.
+// Patterns are shell globs (see path.Match).
+type IgnoreCfg struct {
+	// Images are matched against the containers images, with and without their tag or digest.
+	Images []string `json:"images"`
+	// Names are matched against the containers names.
+	Names []string `json:"names"`
+}
+
+// Payload sections, that can be excluded from the events payload.
+const (
+	PayloadMounts      = "mounts"
+	PayloadEnv         = "env"
+	PayloadLabels      = "labels"
+	PayloadAnnotations = "annotations"
+	PayloadHealthcheck = "healthcheck"
+	PayloadCniJson     = "cni_json"
+)
+
+// PayloadCfg configures the metadata sections serialized into the events sent to the plugin,
+// trading their completeness against their size.
+type PayloadCfg struct {
+	// Exclude are the sections left out of the events payload, eg: "mounts".
+	Exclude []string `json:"exclude,omitempty"`
+	// Digest adds a digest of the full configuration of the containers to the events,
+	// computed before any section is excluded, to detect configuration drifts.
+	Digest bool `json:"digest,omitempty"`
+}
+
+// CircuitBreakerCfg configures the circuit breaker of the engines inspections:
+// after Failures consecutive timed out inspections, the engine is not inspected anymore,
+// reporting the minimal infos of its containers, until a probe inspection succeeds
+// after CooldownMs.
+type CircuitBreakerCfg struct {
+	// Failures is the number of consecutive timeouts opening the breaker; 0 disables it.
+	Failures int `json:"failures"`
+	// CooldownMs is the time between the probe inspections of an engine, while its breaker is open.
+	CooldownMs int `json:"cooldown_ms"`
+}
+
+// WorkerLimitsCfg bounds the resources used by the worker, so that the enrichment
+// does not compete with the plugin events processing on constrained nodes.
+type WorkerLimitsCfg struct {
+	// MaxGoroutines bounds the goroutines of the worker: once reached, containers are inspected
+	// without spawning further goroutines; 0 disables it.
+	MaxGoroutines int `json:"max_goroutines"`
+	// GoMaxProcs overrides GOMAXPROCS of the worker runtime, when set.
+	GoMaxProcs int `json:"gomaxprocs"`
+}
+
+// EventQueueCfg configures the queue of the events waiting to be consumed by the plugin.
+type EventQueueCfg struct {
+	// Size is the max number of queued events.
+	Size int `json:"size"`
+	// Policy is applied when the queue is full: EventQueueBlock or EventQueueDropOldest.
+	Policy string `json:"policy"`
 }
 
 type EngineCfg struct {
@@ -27,6 +245,61 @@ type EngineCfg struct {
 	HostRoot       string                   `json:"host_root"`
 	Hooks          byte                     `json:"hooks"`
 	LogLevel       logLevel                 `json:"log_level"`
+	// ListConcurrency is the max number of containers inspected concurrently
+	// while listing pre-existing containers.
+	ListConcurrency int `json:"list_concurrency"`
+	// InspectTimeoutMs bounds each container inspection, while listing pre-existing containers and on container events,
+	// health checks and resource usage sampling included.
+	InspectTimeoutMs int `json:"inspect_timeout_ms"`
+	// ListTimeoutMs bounds the initial listing of pre-existing containers of each engine.
+	ListTimeoutMs int `json:"list_timeout_ms"`
+	// CacheTTLMs is the expiration of containers metadata cache entries.
+	CacheTTLMs int `json:"cache_ttl_ms"`
+	// CacheMaxEntries is the max number of containers in the metadata cache.
+	CacheMaxEntries int `json:"cache_max_entries"`
+	// CacheSnapshotPath is the file the metadata cache is saved to when the worker stops,
+	// and reloaded from when it starts; empty disables it.
+	CacheSnapshotPath string `json:"cache_snapshot_path"`
+	// EnrichTimeoutMs is how long to wait for a container inspection before sending its minimal infos,
+	// followed by an update event once the inspection completes.
+	EnrichTimeoutMs int `json:"enrich_timeout_ms"`
+	// DigestResolution configures the lookup of missing image digests from registries.
+	DigestResolution DigestResolutionCfg `json:"digest_resolution"`
+	// EcsMetadata configures the lookup of ECS task metadata from the task metadata endpoint.
+	EcsMetadata EcsMetadataCfg `json:"ecs_metadata"`
+	// Nomad configures the lookup of nomad allocations from the local nomad client API.
+	Nomad NomadCfg `json:"nomad"`
+	// Env configures the reported environment variables.
+	Env EnvCfg `json:"env"`
+	// Annotations configures the reported CRI annotations.
+	Annotations AnnotationsCfg `json:"annotations"`
+	// LabelSelectors scopes the reported containers by their labels.
+	LabelSelectors LabelSelectorsCfg `json:"label_selectors"`
+	// Ignore drops the events of infrastructure containers, eg: pod sandboxes.
+	Ignore IgnoreCfg `json:"ignore"`
+	// SuppressPodSandboxes drops the events of pod sandbox (pause) containers;
+	// their network infos are still reported in the metadata of their workload containers.
+	SuppressPodSandboxes bool `json:"suppress_pod_sandboxes"`
+	// Payload shapes the metadata serialized into the events, eg: to leave out the mounts.
+	Payload PayloadCfg `json:"payload"`
+	// EventQueue bounds the events waiting to be consumed by the plugin.
+	EventQueue EventQueueCfg `json:"event_queue"`
+	// CircuitBreaker stops inspecting the containers of engines that keep timing out.
+	CircuitBreaker CircuitBreakerCfg `json:"circuit_breaker"`
+	// WorkerLimits bounds the goroutines and CPUs used by the worker.
+	WorkerLimits WorkerLimitsCfg `json:"worker_limits"`
+	// ConnectRetryMaxBackoffMs bounds the backoff between attempts to attach to engines
+	// that could not be reached at startup; 0 disables the retries.
+	ConnectRetryMaxBackoffMs int `json:"connect_retry_max_backoff_ms"`
+	// ReconcileIntervalMs is the interval of the periodic listing of the containers of each engine,
+	// reconciled with the ones notified by events; 0 disables it.
+	ReconcileIntervalMs int `json:"reconcile_interval_ms"`
+	// StatsIntervalMs is the interval of the sampling of the resource usage of the running containers,
+	// notified through stats events; 0 disables it.
+	StatsIntervalMs int `json:"stats_interval_ms"`
+	// LookupTimeoutMs bounds the synchronous lookups of containers missing from the plugin state;
+	// 0 disables them, leaving only the asynchronous fetch.
+	LookupTimeoutMs int `json:"lookup_timeout_ms"`
 }
 
 // logLevel wraps slog.Level to support JSON unmarshaling from string
@@ -51,7 +324,13 @@ func (l logLevel) Level() slog.Level {
 	return slog.Level(l)
 }
 
-var c EngineCfg
+var (
+	c EngineCfg
+	// enginesMu guards c.SocketsEngines, replaced by LoadEngines while the worker runs.
+	enginesMu sync.RWMutex
+	// logSink routes the worker logs through the plugin logger, when set.
+	logSink LogSink
+)
 
 // updateSlogHandler updates the default slog handler with the current log level from config
 func updateSlogHandler() {
@@ -60,13 +339,39 @@ func updateSlogHandler() {
 	if level == 0 {
 		level = slog.LevelError
 	}
-	slog.SetDefault(slog.New(newFalcoLogHandler(os.Stdout, level)))
+	handler := newFalcoLogHandler(os.Stdout, level)
+	handler.sink = logSink
+	slog.SetDefault(slog.New(handler))
+}
+
+// SetLogSink routes the worker logs through sink; a nil sink restores writing them to stdout.
+// Loggers derived from the default one before the call are not affected.
+func SetLogSink(sink LogSink) {
+	logSink = sink
+	updateSlogHandler()
 }
 
 // Init sets cfg default values
 func init() {
 	c.LabelMaxLen = defaultLabelMaxLen
 	c.WithSize = false
+	c.ListConcurrency = defaultListConcurrency
+	c.InspectTimeoutMs = defaultInspectTimeoutMs
+	c.ListTimeoutMs = defaultListTimeoutMs
+	c.CacheTTLMs = defaultCacheTTLMs
+	c.CacheMaxEntries = defaultCacheMaxEntries
+	c.DigestResolution.TimeoutMs = defaultDigestResolutionTimeoutMs
+	c.DigestResolution.CacheTTLMs = defaultDigestResolutionCacheTTLMs
+	c.EcsMetadata.TimeoutMs = defaultEcsMetadataTimeoutMs
+	c.EcsMetadata.CacheTTLMs = defaultEcsMetadataCacheTTLMs
+	c.Nomad.Address = defaultNomadAddress
+	c.Nomad.TimeoutMs = defaultNomadTimeoutMs
+	c.Nomad.CacheTTLMs = defaultNomadCacheTTLMs
+	c.EventQueue.Size = defaultEventQueueSize
+	c.EventQueue.Policy = EventQueueBlock
+	c.ConnectRetryMaxBackoffMs = defaultConnectRetryMaxBackoffMs
+	c.CircuitBreaker.CooldownMs = defaultCircuitBreakerCooldownMs
+	c.Ignore.Images = defaultIgnoredImages()
 	// We will always override it when called by C++ plugin.
 	// By default, for go-worker executable (make exe) and go-worker tests,
 	// we attach remove hook too.
@@ -77,7 +382,9 @@ func init() {
 }
 
 func Load(initCfg string) error {
+	enginesMu.Lock()
 	err := json.Unmarshal([]byte(initCfg), &c)
+	enginesMu.Unlock()
 	if err != nil {
 		return err
 	}
@@ -87,10 +394,34 @@ func Load(initCfg string) error {
 	return nil
 }
 
+// LoadEngines replaces the engines configuration with the one of cfg, ignoring the other options,
+// that can not be changed while the worker runs.
+func LoadEngines(cfg string) error {
+	var newCfg struct {
+		SocketsEngines map[string]SocketsEngine `json:"engines"`
+	}
+	if err := json.Unmarshal([]byte(cfg), &newCfg); err != nil {
+		return err
+	}
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	c.SocketsEngines = newCfg.SocketsEngines
+	return nil
+}
+
 func Get() EngineCfg {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
 	return c
 }
 
+// engineCfg returns the configuration of an engine.
+func engineCfg(engine string) SocketsEngine {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return c.SocketsEngines[engine]
+}
+
 func GetLabelMaxLen() int {
 	return c.LabelMaxLen
 }
@@ -99,6 +430,202 @@ func GetWithSize() bool {
 	return c.WithSize
 }
 
+// GetListConcurrency returns the max number of concurrent inspections while listing containers;
+// at least 1.
+func GetListConcurrency() int {
+	return max(c.ListConcurrency, 1)
+}
+
+// GetInspectTimeout returns the timeout of each container inspection, health checks and resource usage sampling included;
+// 0 means no timeout.
+func GetInspectTimeout() time.Duration {
+	return time.Duration(max(c.InspectTimeoutMs, 0)) * time.Millisecond
+}
+
+// GetListTimeout returns the deadline of the initial listing of containers;
+// 0 means no deadline.
+func GetListTimeout() time.Duration {
+	return time.Duration(max(c.ListTimeoutMs, 0)) * time.Millisecond
+}
+
+// GetCacheTTL returns the expiration of containers metadata cache entries;
+// 0 means entries never expire, and only get evicted when the cache is full.
+func GetCacheTTL() time.Duration {
+	return time.Duration(max(c.CacheTTLMs, 0)) * time.Millisecond
+}
+
+// GetCacheMaxEntries returns the max number of containers in the metadata cache;
+// 0 means the cache is disabled.
+func GetCacheMaxEntries() int {
+	return max(c.CacheMaxEntries, 0)
+}
+
+// GetCacheSnapshotPath returns the file the metadata cache is saved to and reloaded from;
+// empty means the cache is not persisted.
+func GetCacheSnapshotPath() string {
+	return c.CacheSnapshotPath
+}
+
+// GetEnrichTimeout returns how long to wait for a container inspection before sending its minimal infos;
+// 0 means always waiting for the inspection.
+func GetEnrichTimeout() time.Duration {
+	return time.Duration(max(c.EnrichTimeoutMs, 0)) * time.Millisecond
+}
+
+// GetDigestResolution returns the config of the lookup of missing image digests from registries.
+func GetDigestResolution() DigestResolutionCfg {
+	return c.DigestResolution
+}
+
+// GetEcsMetadata returns the config of the lookup of ECS task metadata.
+func GetEcsMetadata() EcsMetadataCfg {
+	return c.EcsMetadata
+}
+
+// GetNomad returns the config of the lookup of nomad allocations.
+func GetNomad() NomadCfg {
+	return c.Nomad
+}
+
+// GetEnv returns the config of the reported containers environment variables.
+func GetEnv() EnvCfg {
+	return c.Env
+}
+
+// GetAnnotations returns the config of the reported CRI containers and pods annotations.
+func GetAnnotations() AnnotationsCfg {
+	return c.Annotations
+}
+
+// GetLabelSelectors returns the selectors scoping the reported containers by their labels.
+func GetLabelSelectors() LabelSelectorsCfg {
+	return c.LabelSelectors
+}
+
+// GetIgnore returns the config of the ignored infrastructure containers.
+func GetIgnore() IgnoreCfg {
+	return c.Ignore
+}
+
+// GetSuppressPodSandboxes returns whether the events of pod sandbox containers are dropped.
+func GetSuppressPodSandboxes() bool {
+	return c.SuppressPodSandboxes
+}
+
+// GetPayload returns the config of the metadata serialized into the events.
+func GetPayload() PayloadCfg {
+	return c.Payload
+}
+
+// GetEventQueueSize returns the max number of events waiting to be consumed by the plugin;
+// at least 1.
+func GetEventQueueSize() int {
+	return max(c.EventQueue.Size, 1)
+}
+
+// GetEventQueuePolicy returns the policy applied when the event queue is full;
+// unknown policies fall back at EventQueueBlock.
+func GetEventQueuePolicy() string {
+	if c.EventQueue.Policy == EventQueueDropOldest {
+		return EventQueueDropOldest
+	}
+	return EventQueueBlock
+}
+
+// GetCircuitBreakerFailures returns the number of consecutive timed out inspections
+// opening the circuit breaker of an engine; 0 means the breaker is disabled.
+func GetCircuitBreakerFailures() int {
+	return max(c.CircuitBreaker.Failures, 0)
+}
+
+// GetCircuitBreakerCooldown returns the time between the probe inspections of an engine
+// whose circuit breaker is open.
+func GetCircuitBreakerCooldown() time.Duration {
+	return time.Duration(max(c.CircuitBreaker.CooldownMs, 0)) * time.Millisecond
+}
+
+// GetMaxGoroutines returns the max number of goroutines of the worker;
+// 0 means no limit.
+func GetMaxGoroutines() int {
+	return max(c.WorkerLimits.MaxGoroutines, 0)
+}
+
+// GetGoMaxProcs returns the GOMAXPROCS override of the worker runtime;
+// 0 means keeping the runtime default.
+func GetGoMaxProcs() int {
+	return max(c.WorkerLimits.GoMaxProcs, 0)
+}
+
+// GetConnectRetryMaxBackoff returns the max backoff between attempts to attach to engines
+// that could not be reached at startup; 0 means not retrying.
+func GetConnectRetryMaxBackoff() time.Duration {
+	return time.Duration(max(c.ConnectRetryMaxBackoffMs, 0)) * time.Millisecond
+}
+
+// GetReconcileInterval returns the interval of the periodic reconciliation of the containers of each engine;
+// 0 means no periodic reconciliation.
+func GetReconcileInterval() time.Duration {
+	return time.Duration(max(c.ReconcileIntervalMs, 0)) * time.Millisecond
+}
+
+// GetStatsInterval returns the interval of the sampling of the resource usage of the running containers;
+// 0 means no sampling.
+func GetStatsInterval() time.Duration {
+	return time.Duration(max(c.StatsIntervalMs, 0)) * time.Millisecond
+}
+
+// GetLookupTimeout returns the timeout of the synchronous lookups of containers;
+// 0 means no synchronous lookups.
+func GetLookupTimeout() time.Duration {
+	return time.Duration(max(c.LookupTimeoutMs, 0)) * time.Millisecond
+}
+
+// GetEngineInspectTimeout returns the timeout of each container inspection of an engine,
+// health checks and resource usage sampling included,
+// falling back at GetInspectTimeout() when the engine does not override it.
+func GetEngineInspectTimeout(engine string) time.Duration {
+	if timeoutMs := engineCfg(engine).TimeoutMs; timeoutMs > 0 {
+		return time.Duration(timeoutMs) * time.Millisecond
+	}
+	return GetInspectTimeout()
+}
+
+// GetEngineListTimeout returns the deadline of the initial listing of the containers of an engine,
+// falling back at GetListTimeout() when the engine does not override it.
+func GetEngineListTimeout(engine string) time.Duration {
+	if timeoutMs := engineCfg(engine).ListTimeoutMs; timeoutMs > 0 {
+		return time.Duration(timeoutMs) * time.Millisecond
+	}
+	return GetListTimeout()
+}
+
+// GetEngineTLS returns the TLS material of a remote socket of an engine:
+// its own one, if configured, otherwise the engine one.
+func GetEngineTLS(engine, socket string) EngineTLS {
+	eCfg := engineCfg(engine)
+	if endpointTLS, ok := eCfg.TLSEndpoints[socket]; ok {
+		return endpointTLS
+	}
+	return eCfg.TLS
+}
+
+// GetEngineLogLevel returns the log level of an engine, if it overrides the worker one.
+func GetEngineLogLevel(engine string) (slog.Level, bool) {
+	logLevel := engineCfg(engine).LogLevel
+	if logLevel == "" {
+		return 0, false
+	}
+	return toSlogLevel(logLevel), true
+}
+
+func GetEngineNamespaces(engine string) []string {
+	return engineCfg(engine).Namespaces
+}
+
+func GetEngineSimulate(engine string) SimulateCfg {
+	return engineCfg(engine).SimulateCfg
+}
+
 func GetHostRoot() string {
 	return c.HostRoot
 }
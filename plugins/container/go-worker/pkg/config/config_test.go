@@ -123,6 +123,466 @@ func TestEngineCfg_UnmarshalJSON(t *testing.T) {
 			},
 			wantError: false,
 		},
+		{
+			name: "config with containerd namespaces",
+			json: `{
+				"engines": {
+					"containerd": {
+						"enabled": true,
+						"sockets": ["/run/containerd/containerd.sock"],
+						"namespaces": ["default", "k8s.io", "moby"]
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"containerd": {
+						Enabled:    true,
+						Sockets:    []string{"/run/containerd/containerd.sock"},
+						Namespaces: []string{"default", "k8s.io", "moby"},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with docker contexts",
+			json: `{
+				"engines": {
+					"docker": {
+						"enabled": true,
+						"sockets": ["/var/run/docker.sock"],
+						"contexts": ["remote"]
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"docker": {
+						Enabled:  true,
+						Sockets:  []string{"/var/run/docker.sock"},
+						Contexts: []string{"remote"},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with docker rootless daemons",
+			json: `{
+				"engines": {
+					"docker": {
+						"enabled": true,
+						"sockets": ["/var/run/docker.sock"],
+						"rootless": true
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"docker": {
+						Enabled:  true,
+						Sockets:  []string{"/var/run/docker.sock"},
+						Rootless: true,
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with simulate engine",
+			json: `{
+				"engines": {
+					"simulate": {
+						"enabled": true,
+						"create_rate": 100,
+						"lifetime_ms": 30000,
+						"max_containers": 500,
+						"seed": 42
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"simulate": {
+						Enabled: true,
+						SimulateCfg: SimulateCfg{
+							CreateRate:    100,
+							LifetimeMs:    30000,
+							MaxContainers: 500,
+							Seed:          42,
+						},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with cri fallback sockets",
+			json: `{
+				"engines": {
+					"cri": {
+						"enabled": true,
+						"sockets": ["/run/containerd/containerd.sock", "/run/crio/crio.sock"],
+						"fallback": true
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"cri": {
+						Enabled:  true,
+						Sockets:  []string{"/run/containerd/containerd.sock", "/run/crio/crio.sock"},
+						Fallback: true,
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with per-engine timeout and tls",
+			json: `{
+				"engines": {
+					"docker": {
+						"enabled": true,
+						"sockets": ["tcp://192.168.1.10:2376"],
+						"timeout_ms": 2000,
+						"list_timeout_ms": 5000,
+						"tls": {
+							"ca": "/etc/docker/ca.pem",
+							"cert": "/etc/docker/cert.pem",
+							"key": "/etc/docker/key.pem"
+						},
+						"tls_endpoints": {
+							"tcp://192.168.1.11:2376": {
+								"ca": "/etc/docker/other/ca.pem",
+								"server_name": "docker.internal"
+							}
+						}
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"docker": {
+						Enabled:       true,
+						Sockets:       []string{"tcp://192.168.1.10:2376"},
+						TimeoutMs:     2000,
+						ListTimeoutMs: 5000,
+						TLS: EngineTLS{
+							CA:   "/etc/docker/ca.pem",
+							Cert: "/etc/docker/cert.pem",
+							Key:  "/etc/docker/key.pem",
+						},
+						TLSEndpoints: map[string]EngineTLS{
+							"tcp://192.168.1.11:2376": {
+								CA:         "/etc/docker/other/ca.pem",
+								ServerName: "docker.internal",
+							},
+						},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with per-engine log level",
+			json: `{
+				"engines": {
+					"containerd": {
+						"enabled": true,
+						"sockets": ["/run/containerd/containerd.sock"],
+						"log_level": "debug"
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				SocketsEngines: map[string]SocketsEngine{
+					"containerd": {
+						Enabled:  true,
+						Sockets:  []string{"/run/containerd/containerd.sock"},
+						LogLevel: "debug",
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with listing bounds",
+			json: `{
+				"list_concurrency": 4,
+				"inspect_timeout_ms": 1000,
+				"list_timeout_ms": 10000
+			}`,
+			wantCfg: EngineCfg{
+				ListConcurrency:  4,
+				InspectTimeoutMs: 1000,
+				ListTimeoutMs:    10000,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with metadata cache",
+			json: `{
+				"cache_ttl_ms": 120000,
+				"cache_max_entries": 100,
+				"cache_snapshot_path": "/var/lib/falco/container-cache.json"
+			}`,
+			wantCfg: EngineCfg{
+				CacheTTLMs:        120000,
+				CacheMaxEntries:   100,
+				CacheSnapshotPath: "/var/lib/falco/container-cache.json",
+			},
+			wantError: false,
+		},
+		{
+			name: "config with enrichment timeout",
+			json: `{
+				"enrich_timeout_ms": 200
+			}`,
+			wantCfg: EngineCfg{
+				EnrichTimeoutMs: 200,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with digest resolution",
+			json: `{
+				"digest_resolution": {
+					"enabled": true,
+					"timeout_ms": 1000,
+					"cache_ttl_ms": 60000,
+					"auths": {
+						"quay.io": {"username": "user", "password": "pass"}
+					}
+				}
+			}`,
+			wantCfg: EngineCfg{
+				DigestResolution: DigestResolutionCfg{
+					Enabled:    true,
+					TimeoutMs:  1000,
+					CacheTTLMs: 60000,
+					Auths: map[string]RegistryAuth{
+						"quay.io": {Username: "user", Password: "pass"},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with ecs metadata",
+			json: `{
+				"ecs_metadata": {
+					"enabled": true,
+					"timeout_ms": 500,
+					"cache_ttl_ms": 0
+				}
+			}`,
+			wantCfg: EngineCfg{
+				EcsMetadata: EcsMetadataCfg{
+					Enabled:    true,
+					TimeoutMs:  500,
+					CacheTTLMs: 0,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with nomad",
+			json: `{
+				"nomad": {
+					"enabled": true,
+					"address": "https://nomad.service.consul:4646",
+					"token": "secret",
+					"timeout_ms": 500,
+					"cache_ttl_ms": 0
+				}
+			}`,
+			wantCfg: EngineCfg{
+				Nomad: NomadCfg{
+					Enabled:    true,
+					Address:    "https://nomad.service.consul:4646",
+					Token:      "secret",
+					TimeoutMs:  500,
+					CacheTTLMs: 0,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with env",
+			json: `{
+				"env": {
+					"allowlist": ["APP_*", "DEPLOYMENT_ID"],
+					"redact": ["*_TOKEN"]
+				}
+			}`,
+			wantCfg: EngineCfg{
+				Env: EnvCfg{
+					Allowlist: []string{"APP_*", "DEPLOYMENT_ID"},
+					Redact:    []string{"*_TOKEN"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with annotations",
+			json: `{
+				"annotations": {
+					"include": ["sidecar.istio.io/*", "seccomp.security.alpha.kubernetes.io/pod"]
+				}
+			}`,
+			wantCfg: EngineCfg{
+				Annotations: AnnotationsCfg{
+					Include: []string{"sidecar.istio.io/*", "seccomp.security.alpha.kubernetes.io/pod"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with ignore",
+			json: `{
+				"ignore": {
+					"names": ["k8s_POD_*"]
+				}
+			}`,
+			wantCfg: EngineCfg{
+				Ignore: IgnoreCfg{
+					Names: []string{"k8s_POD_*"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with label selectors",
+			json: `{
+				"label_selectors": {
+					"include": ["team=security"],
+					"exclude": ["env!=prod,!critical"]
+				}
+			}`,
+			wantCfg: EngineCfg{
+				LabelSelectors: LabelSelectorsCfg{
+					Include: []string{"team=security"},
+					Exclude: []string{"env!=prod,!critical"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with payload",
+			json: `{
+				"payload": {
+					"exclude": ["mounts", "env"],
+					"digest": true
+				}
+			}`,
+			wantCfg: EngineCfg{
+				Payload: PayloadCfg{
+					Exclude: []string{PayloadMounts, PayloadEnv},
+					Digest:  true,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with circuit breaker",
+			json: `{
+				"circuit_breaker": {
+					"failures": 3,
+					"cooldown_ms": 10000
+				}
+			}`,
+			wantCfg: EngineCfg{
+				CircuitBreaker: CircuitBreakerCfg{
+					Failures:   3,
+					CooldownMs: 10000,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with worker limits",
+			json: `{
+				"worker_limits": {
+					"max_goroutines": 500,
+					"gomaxprocs": 2
+				}
+			}`,
+			wantCfg: EngineCfg{
+				WorkerLimits: WorkerLimitsCfg{
+					MaxGoroutines: 500,
+					GoMaxProcs:    2,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with pod sandboxes suppression",
+			json: `{
+				"suppress_pod_sandboxes": true
+			}`,
+			wantCfg: EngineCfg{
+				SuppressPodSandboxes: true,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with event queue",
+			json: `{
+				"event_queue": {
+					"size": 64,
+					"policy": "drop_oldest"
+				}
+			}`,
+			wantCfg: EngineCfg{
+				EventQueue: EventQueueCfg{
+					Size:   64,
+					Policy: EventQueueDropOldest,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "config with connect retry max backoff",
+			json: `{
+				"connect_retry_max_backoff_ms": 5000
+			}`,
+			wantCfg: EngineCfg{
+				ConnectRetryMaxBackoffMs: 5000,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with reconcile interval",
+			json: `{
+				"reconcile_interval_ms": 300000
+			}`,
+			wantCfg: EngineCfg{
+				ReconcileIntervalMs: 300000,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with stats interval",
+			json: `{
+				"stats_interval_ms": 10000
+			}`,
+			wantCfg: EngineCfg{
+				StatsIntervalMs: 10000,
+			},
+			wantError: false,
+		},
+		{
+			name: "config with lookup timeout",
+			json: `{
+				"lookup_timeout_ms": 100
+			}`,
+			wantCfg: EngineCfg{
+				LookupTimeoutMs: 100,
+			},
+			wantError: false,
+		},
 		{
 			name: "config with debug log level as string",
 			json: `{
@@ -221,6 +681,75 @@ func TestEngineCfg_UnmarshalJSON(t *testing.T) {
 				if tt.wantCfg.Hooks != 0 {
 					assert.Equal(t, tt.wantCfg.Hooks, cfg.Hooks)
 				}
+				if tt.wantCfg.ListConcurrency != 0 {
+					assert.Equal(t, tt.wantCfg.ListConcurrency, cfg.ListConcurrency)
+				}
+				if tt.wantCfg.InspectTimeoutMs != 0 {
+					assert.Equal(t, tt.wantCfg.InspectTimeoutMs, cfg.InspectTimeoutMs)
+				}
+				if tt.wantCfg.ListTimeoutMs != 0 {
+					assert.Equal(t, tt.wantCfg.ListTimeoutMs, cfg.ListTimeoutMs)
+				}
+				if tt.wantCfg.CacheTTLMs != 0 {
+					assert.Equal(t, tt.wantCfg.CacheTTLMs, cfg.CacheTTLMs)
+				}
+				if tt.wantCfg.CacheMaxEntries != 0 {
+					assert.Equal(t, tt.wantCfg.CacheMaxEntries, cfg.CacheMaxEntries)
+				}
+				if tt.wantCfg.CacheSnapshotPath != "" {
+					assert.Equal(t, tt.wantCfg.CacheSnapshotPath, cfg.CacheSnapshotPath)
+				}
+				if tt.wantCfg.EnrichTimeoutMs != 0 {
+					assert.Equal(t, tt.wantCfg.EnrichTimeoutMs, cfg.EnrichTimeoutMs)
+				}
+				if tt.wantCfg.DigestResolution.Enabled {
+					assert.Equal(t, tt.wantCfg.DigestResolution, cfg.DigestResolution)
+				}
+				if tt.wantCfg.EcsMetadata.Enabled {
+					assert.Equal(t, tt.wantCfg.EcsMetadata, cfg.EcsMetadata)
+				}
+				if tt.wantCfg.Nomad.Enabled {
+					assert.Equal(t, tt.wantCfg.Nomad, cfg.Nomad)
+				}
+				if len(tt.wantCfg.Env.Allowlist) > 0 || len(tt.wantCfg.Env.Redact) > 0 {
+					assert.Equal(t, tt.wantCfg.Env, cfg.Env)
+				}
+				if len(tt.wantCfg.Annotations.Include) > 0 {
+					assert.Equal(t, tt.wantCfg.Annotations, cfg.Annotations)
+				}
+				if len(tt.wantCfg.Ignore.Names) > 0 {
+					assert.Equal(t, tt.wantCfg.Ignore, cfg.Ignore)
+				}
+				if len(tt.wantCfg.LabelSelectors.Include) > 0 || len(tt.wantCfg.LabelSelectors.Exclude) > 0 {
+					assert.Equal(t, tt.wantCfg.LabelSelectors, cfg.LabelSelectors)
+				}
+				if tt.wantCfg.SuppressPodSandboxes {
+					assert.True(t, cfg.SuppressPodSandboxes)
+				}
+				if len(tt.wantCfg.Payload.Exclude) > 0 {
+					assert.Equal(t, tt.wantCfg.Payload, cfg.Payload)
+				}
+				if tt.wantCfg.CircuitBreaker.Failures != 0 {
+					assert.Equal(t, tt.wantCfg.CircuitBreaker, cfg.CircuitBreaker)
+				}
+				if tt.wantCfg.WorkerLimits != (WorkerLimitsCfg{}) {
+					assert.Equal(t, tt.wantCfg.WorkerLimits, cfg.WorkerLimits)
+				}
+				if tt.wantCfg.EventQueue.Size != 0 {
+					assert.Equal(t, tt.wantCfg.EventQueue, cfg.EventQueue)
+				}
+				if tt.wantCfg.ConnectRetryMaxBackoffMs != 0 {
+					assert.Equal(t, tt.wantCfg.ConnectRetryMaxBackoffMs, cfg.ConnectRetryMaxBackoffMs)
+				}
+				if tt.wantCfg.ReconcileIntervalMs != 0 {
+					assert.Equal(t, tt.wantCfg.ReconcileIntervalMs, cfg.ReconcileIntervalMs)
+				}
+				if tt.wantCfg.StatsIntervalMs != 0 {
+					assert.Equal(t, tt.wantCfg.StatsIntervalMs, cfg.StatsIntervalMs)
+				}
+				if tt.wantCfg.LookupTimeoutMs != 0 {
+					assert.Equal(t, tt.wantCfg.LookupTimeoutMs, cfg.LookupTimeoutMs)
+				}
 				if len(tt.wantCfg.SocketsEngines) > 0 {
 					assert.Equal(t, tt.wantCfg.SocketsEngines, cfg.SocketsEngines)
 				}
@@ -258,3 +787,46 @@ func TestLogLevel_Level(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadIgnoreDefaults(t *testing.T) {
+	t.Cleanup(func() {
+		c.Ignore = IgnoreCfg{Images: defaultIgnoredImages()}
+	})
+
+	require.NoError(t, Load(`{"ignore": {"names": ["k8s_POD_*"]}}`))
+	// Unset images keep the default ones
+	assert.Equal(t, defaultIgnoredImages(), GetIgnore().Images)
+	assert.Equal(t, []string{"k8s_POD_*"}, GetIgnore().Names)
+
+	require.NoError(t, Load(`{"ignore": {"images": []}}`))
+	assert.Empty(t, GetIgnore().Images)
+}
+
+func TestGetEngineTLS(t *testing.T) {
+	require.NoError(t, Load(`{"engines": {"docker": {"enabled": true, "tls": {"ca": "/etc/docker/ca.pem"}, "tls_endpoints": {"tcp://192.168.1.11:2376": {"server_name": "docker.internal"}}}}}`))
+	t.Cleanup(func() {
+		_ = Load(`{"engines": null}`)
+	})
+
+	assert.Equal(t, EngineTLS{CA: "/etc/docker/ca.pem"}, GetEngineTLS("docker", "tcp://192.168.1.10:2376"))
+	assert.Equal(t, EngineTLS{ServerName: "docker.internal"}, GetEngineTLS("docker", "tcp://192.168.1.11:2376"))
+	assert.Equal(t, EngineTLS{}, GetEngineTLS("podman", "tcp://192.168.1.11:2376"))
+}
+
+func TestLoadEngines(t *testing.T) {
+	require.NoError(t, Load(`{"label_max_len": 20, "engines": {"docker": {"enabled": true, "sockets": ["/var/run/docker.sock"]}}}`))
+	t.Cleanup(func() {
+		_ = Load(`{"label_max_len": 100, "engines": null}`)
+	})
+
+	// Only the engines are replaced
+	require.NoError(t, LoadEngines(`{"label_max_len": 50, "engines": {"podman": {"enabled": true, "sockets": ["/run/podman/podman.sock"]}}}`))
+	assert.Equal(t, map[string]SocketsEngine{
+		"podman": {Enabled: true, Sockets: []string{"/run/podman/podman.sock"}},
+	}, Get().SocketsEngines)
+	assert.Equal(t, 20, Get().LabelMaxLen)
+
+	// Invalid configurations are discarded
+	assert.Error(t, LoadEngines(`{"engines": []}`))
+	assert.Contains(t, Get().SocketsEngines, "podman")
+}
@@ -8,11 +8,22 @@ import (
 	"strings"
 )
 
+// engineLogAttr is the attribute of the engines loggers, rendered as a prefix of their messages,
+// eg: "[docker] message".
+const engineLogAttr = "engine"
+
+// LogSink receives the log messages of the worker, already filtered by level,
+// when they have to be routed through the plugin logger instead of being written by the worker.
+type LogSink func(level slog.Level, msg string)
+
 // falcoLogHandler implements slog.Handler with Falco's log format:
 // Thu Nov 06 11:46:17 2025: [container-engine] [info]: message
+// When sink is set, messages are passed to it instead, without timestamp and level.
 type falcoLogHandler struct {
 	writer io.Writer
+	sink   LogSink
 	level  slog.Level
+	prefix string
 	attrs  []slog.Attr
 	groups []string
 }
@@ -32,15 +43,14 @@ func (h *falcoLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
 
 func (h *falcoLogHandler) Handle(ctx context.Context, record slog.Record) error {
 	// Format: Thu Nov 06 11:46:17 2025: [container-engine] [info]: message
-	timestamp := record.Time.Format("Mon Jan 02 15:04:05 2006:")
-	levelStr := levelToString(record.Level)
-
-	// Build the log line
 	var buf strings.Builder
-	buf.WriteString(timestamp)
-	buf.WriteString(" [container-engine] [")
-	buf.WriteString(levelStr)
-	buf.WriteString("]: ")
+	if h.sink == nil {
+		buf.WriteString(record.Time.Format("Mon Jan 02 15:04:05 2006:"))
+		buf.WriteString(" [container-engine] [")
+		buf.WriteString(levelToString(record.Level))
+		buf.WriteString("]: ")
+	}
+	buf.WriteString(h.prefix)
 	buf.WriteString(record.Message)
 
 	// Add attributes if any
@@ -62,24 +72,46 @@ func (h *falcoLogHandler) Handle(ctx context.Context, record slog.Record) error
 		})
 	}
 
+	if h.sink != nil {
+		h.sink(record.Level, buf.String())
+		return nil
+	}
 	buf.WriteString("\n")
 	_, err := h.writer.Write([]byte(buf.String()))
 	return err
 }
 
+// WithAttrs returns a handler with the given attributes.
+// The engine attribute is rendered as a prefix, and applies the engine log level, if configured.
 func (h *falcoLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &falcoLogHandler{
+	handler := &falcoLogHandler{
 		writer: h.writer,
+		sink:   h.sink,
 		level:  h.level,
-		attrs:  append(h.attrs, attrs...),
+		prefix: h.prefix,
+		attrs:  append([]slog.Attr{}, h.attrs...),
 		groups: h.groups,
 	}
+	for _, attr := range attrs {
+		if attr.Key != engineLogAttr {
+			handler.attrs = append(handler.attrs, attr)
+			continue
+		}
+		engine := attr.Value.String()
+		handler.prefix = "[" + engine + "] "
+		if level, ok := GetEngineLogLevel(engine); ok {
+			handler.level = level
+		}
+	}
+	return handler
 }
 
 func (h *falcoLogHandler) WithGroup(name string) slog.Handler {
 	return &falcoLogHandler{
 		writer: h.writer,
+		sink:   h.sink,
 		level:  h.level,
+		prefix: h.prefix,
 		attrs:  h.attrs,
 		groups: append(h.groups, name),
 	}
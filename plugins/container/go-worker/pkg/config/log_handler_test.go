@@ -228,6 +228,56 @@ func TestFalcoLogHandler_WithAttrs(t *testing.T) {
 	assert.Contains(t, output, " version=1")
 }
 
+func TestFalcoLogHandler_EngineAttr(t *testing.T) {
+	t.Cleanup(func() { _ = Load(`{"engines": null}`) })
+	require.NoError(t, Load(`{"engines": {"docker": {"enabled": true, "log_level": "debug"}}}`))
+
+	var buf bytes.Buffer
+	handler := newFalcoLogHandler(&buf, slog.LevelError)
+
+	docker := handler.WithAttrs([]slog.Attr{slog.String("engine", "docker")}).(*falcoLogHandler)
+	assert.Equal(t, slog.LevelDebug, docker.level)
+	assert.Empty(t, docker.attrs)
+	podman := handler.WithAttrs([]slog.Attr{slog.String("engine", "podman")}).(*falcoLogHandler)
+	assert.Equal(t, slog.LevelError, podman.level)
+
+	record := slog.NewRecord(time.Now(), slog.LevelDebug, "container create event", 0)
+	record.AddAttrs(slog.String("container_id", "abc"))
+	require.NoError(t, docker.Handle(context.Background(), record))
+	assert.Contains(t, buf.String(), "[container-engine] [debug]: [docker] container create event container_id=abc")
+}
+
+func TestFalcoLogHandler_Sink(t *testing.T) {
+	var (
+		levels []slog.Level
+		msgs   []string
+	)
+	handler := newFalcoLogHandler(nil, slog.LevelInfo)
+	handler.sink = func(level slog.Level, msg string) {
+		levels = append(levels, level)
+		msgs = append(msgs, msg)
+	}
+	logger := slog.New(handler).With("engine", "cri")
+	logger.Debug("filtered")
+	logger.Warn("events stream dropped", "err", "EOF")
+
+	assert.Equal(t, []slog.Level{slog.LevelWarn}, levels)
+	assert.Equal(t, []string{"[cri] events stream dropped err=EOF"}, msgs)
+}
+
+func TestSetLogSink(t *testing.T) {
+	t.Cleanup(func() { SetLogSink(nil) })
+
+	var msgs []string
+	SetLogSink(func(_ slog.Level, msg string) { msgs = append(msgs, msg) })
+	slog.Error("routed")
+	assert.Equal(t, []string{"routed"}, msgs)
+
+	SetLogSink(nil)
+	slog.Error("written")
+	assert.Equal(t, []string{"routed"}, msgs)
+}
+
 func TestLevelToString(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,25 @@
+package container
+
+import (
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// includedAnnotations returns the annotations whose keys match the configured include patterns,
+// skipping values longer than the configured label max length, eg: last applied configurations.
+func includedAnnotations(annotations map[string]string) map[string]string {
+	include := config.GetAnnotations().Include
+	if len(include) == 0 || len(annotations) == 0 {
+		return nil
+	}
+	var included map[string]string
+	for key, val := range annotations {
+		if !matchPatterns(include, key) || len(val) > config.GetLabelMaxLen() {
+			continue
+		}
+		if included == nil {
+			included = make(map[string]string)
+		}
+		included[key] = val
+	}
+	return included
+}
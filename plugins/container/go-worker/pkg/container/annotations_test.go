@@ -0,0 +1,34 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func TestIncludedAnnotations(t *testing.T) {
+	t.Cleanup(func() {
+		_ = config.Load(`{"annotations": {"include": []}}`)
+	})
+	annotations := map[string]string{
+		"sidecar.istio.io/inject":                          "true",
+		"sidecar.istio.io/status":                          strings.Repeat("x", 1000),
+		"seccomp.security.alpha.kubernetes.io/pod":         "unconfined",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	}
+
+	// No annotation is reported by default
+	assert.Nil(t, includedAnnotations(annotations))
+
+	require.NoError(t, config.Load(`{"annotations": {"include": ["sidecar.istio.io/*", "seccomp.security.alpha.kubernetes.io/pod"]}}`))
+	assert.Equal(t, map[string]string{
+		"sidecar.istio.io/inject":                  "true",
+		"seccomp.security.alpha.kubernetes.io/pod": "unconfined",
+	}, includedAnnotations(annotations))
+	assert.Nil(t, includedAnnotations(map[string]string{"foo": "bar"}))
+	assert.Nil(t, includedAnnotations(nil))
+}
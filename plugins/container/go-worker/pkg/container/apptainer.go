@@ -0,0 +1,170 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const apptainerPollInterval = 2 * time.Second
+
+func init() {
+	engineGenerators[typeApptainer] = newApptainerEngine
+}
+
+// apptainerEngine enumerates Apptainer (and Singularity) instances through the instance files
+// stored under the instances directory of their users, ie:
+// ~/.apptainer/instances/app/<hostname>/<user>/<name>/<name>.json
+// (~/.singularity/instances/sing/... for Singularity).
+// Apptainer has no daemon: instances are run directly by their users, eg: on HPC hosts.
+type apptainerEngine struct {
+	logger       *slog.Logger
+	instancesDir string
+}
+
+// apptainerInstance is the subset of an Apptainer instance file we are interested in.
+type apptainerInstance struct {
+	// Pid of the instance, used by Apptainer to name its cgroup.
+	Pid    int    `json:"pid"`
+	Name   string `json:"name"`
+	User   string `json:"user"`
+	Image  string `json:"image"`
+	IP     string `json:"ip"`
+	Cgroup bool   `json:"cgroup"`
+	UserNs bool   `json:"userns"`
+}
+
+func newApptainerEngine(_ context.Context, logger *slog.Logger, instancesDir string) (Engine, error) {
+	stat, err := os.Stat(instancesDir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", instancesDir)
+	}
+	return &apptainerEngine{
+		logger:       logger,
+		instancesDir: instancesDir,
+	}, nil
+}
+
+func (a *apptainerEngine) copy(ctx context.Context) (Engine, error) {
+	return newApptainerEngine(ctx, a.logger, a.instancesDir)
+}
+
+func (a *apptainerEngine) instanceToInfo(instance *apptainerInstance, created time.Time) event.Info {
+	// Like for the cgroups they are extracted from, the pid is the container ID.
+	// Instances are started as soon as they are created.
+	id := strconv.Itoa(instance.Pid)
+	info := event.Info{
+		Container: event.Container{
+			Type:          typeApptainer.ToCTValue(),
+			ID:            id,
+			Name:          instance.Name,
+			Image:         instance.Image,
+			User:          instance.User,
+			CPUPeriod:     defaultCpuPeriod,
+			CPUShares:     defaultCpuShares,
+			CreatedTime:   created.Unix(),
+			CreatedAt:     unixNano(created),
+			StartedAt:     unixNano(created),
+			FullID:        id,
+			Ip:            instance.IP,
+			UserNamespace: instance.UserNs,
+			Labels:        map[string]string{},
+			Mounts:        []event.Mount{},
+			PortMappings:  []event.PortMapping{},
+			Size:          -1,
+		},
+	}
+	if instance.Cgroup {
+		fillProcCgroupLimits(&info.Container, instance.Pid)
+	}
+	return info
+}
+
+// readInstance reads an instance file, skipping the stale ones, left behind by instances
+// not stopped cleanly, or belonging to other hosts sharing the same home directories.
+func (a *apptainerEngine) readInstance(path string) (*event.Event, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var instance apptainerInstance
+	if err = json.Unmarshal(data, &instance); err != nil {
+		return nil, err
+	}
+	if instance.Pid <= 0 {
+		return nil, fmt.Errorf("instance file %s has no pid", path)
+	}
+	if _, err = os.Stat(filepath.Join(config.GetHostRoot(), "/proc", strconv.Itoa(instance.Pid))); err != nil {
+		return nil, fmt.Errorf("instance %s is not running: %w", instance.Name, err)
+	}
+	return &event.Event{
+		Info:     a.instanceToInfo(&instance, stat.ModTime()),
+		IsCreate: true,
+	}, nil
+}
+
+// instanceFiles returns the paths of the instance files, laid out as
+// <instances dir>/<app|sing>/<hostname>/<user>/<name>/<name>.json.
+func (a *apptainerEngine) instanceFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(a.instancesDir, "*", "*", "*", "*", "*.json"))
+}
+
+func (a *apptainerEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
+	evts, err := a.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, evt := range evts {
+		if evt.ID == containerId {
+			return &evt, nil
+		}
+	}
+	return nil, fmt.Errorf("instance %s not found in %s", containerId, a.instancesDir)
+}
+
+func (a *apptainerEngine) Name() string {
+	return string(typeApptainer)
+}
+
+func (a *apptainerEngine) Sock() string {
+	return a.instancesDir
+}
+
+func (a *apptainerEngine) List(_ context.Context) ([]event.Event, error) {
+	paths, err := a.instanceFiles()
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(paths))
+	for _, path := range paths {
+		evt, err := a.readInstance(path)
+		if err != nil {
+			a.logger.LogAttrs(context.Background(), slog.LevelDebug, "failed to read instance file", slog.String("path", path), slog.String("err", err.Error()))
+			continue
+		}
+		evts = append(evts, *evt)
+	}
+	return evts, nil
+}
+
+// Listen polls the instances directory, where an instance file
+// is created for each started instance and removed once it gets stopped.
+func (a *apptainerEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, a.logger, apptainerPollInterval, a.List)
+}
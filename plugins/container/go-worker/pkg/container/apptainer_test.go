@@ -0,0 +1,89 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func writeApptainerInstance(t *testing.T, instancesDir, name string, pid int) string {
+	dir := filepath.Join(instancesDir, "app", "node01", "alice", name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, name+".json")
+	err := os.WriteFile(path, []byte(`{
+  "pid": `+strconv.Itoa(pid)+`,
+  "ppid": 1,
+  "name": "`+name+`",
+  "user": "alice",
+  "image": "/home/alice/images/lolcow.sif",
+  "userns": true,
+  "cgroup": false,
+  "ip": "10.22.0.2",
+  "logErrPath": "/home/alice/.apptainer/instances/logs/node01/alice/`+name+`.err",
+  "logOutPath": "/home/alice/.apptainer/instances/logs/node01/alice/`+name+`.out"
+}`), 0644)
+	require.NoError(t, err)
+	created := time.Date(2024, 11, 7, 10, 30, 3, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, created, created))
+	return path
+}
+
+func TestApptainer(t *testing.T) {
+	instancesDir := t.TempDir()
+	pid := os.Getpid()
+	writeApptainerInstance(t, instancesDir, "cow", pid)
+	// Stale instance file, whose process is gone
+	writeApptainerInstance(t, instancesDir, "stale", 1<<30)
+
+	engine, err := newApptainerEngine(context.Background(), slog.Default(), instancesDir)
+	require.NoError(t, err)
+
+	expectedEvent := event.Event{
+		Info: event.Info{
+			Container: event.Container{
+				Type:          typeApptainer.ToCTValue(),
+				ID:            strconv.Itoa(pid),
+				Name:          "cow",
+				Image:         "/home/alice/images/lolcow.sif",
+				User:          "alice",
+				CPUPeriod:     defaultCpuPeriod,
+				CPUShares:     defaultCpuShares,
+				CreatedTime:   1730975403,
+				CreatedAt:     1730975403000000000,
+				StartedAt:     1730975403000000000,
+				FullID:        strconv.Itoa(pid),
+				Ip:            "10.22.0.2",
+				UserNamespace: true,
+				Labels:        map[string]string{},
+				Mounts:        []event.Mount{},
+				PortMappings:  []event.PortMapping{},
+				Size:          -1,
+			},
+		},
+		IsCreate: true,
+	}
+
+	events, err := engine.List(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, expectedEvent, events[0])
+
+	evt, err := engine.(getter).get(context.Background(), strconv.Itoa(pid))
+	require.NoError(t, err)
+	assert.Equal(t, expectedEvent, *evt)
+
+	_, err = engine.(getter).get(context.Background(), strconv.Itoa(1<<30))
+	assert.Error(t, err)
+
+	_, err = newApptainerEngine(context.Background(), slog.Default(), filepath.Join(instancesDir, "missing"))
+	assert.Error(t, err)
+}
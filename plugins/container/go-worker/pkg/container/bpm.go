@@ -0,0 +1,188 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const bpmPollInterval = 2 * time.Second
+
+func init() {
+	engineGenerators[typeBpm] = newBpmEngine
+}
+
+// bpmEngine enumerates BOSH Process Manager (bpm) containers
+// through the runc state files stored under bpm runc root directory,
+// ie: /var/vcap/sys/run/bpm-runc/<id>/state.json.
+type bpmEngine struct {
+	logger  *slog.Logger
+	runcDir string
+}
+
+// runcState is the subset of runc libcontainer state we are interested in.
+type runcState struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	// Cgroup directories by controller; the cgroup v2 one has an empty key.
+	CgroupPaths map[string]string `json:"cgroup_paths"`
+	Config      struct {
+		Rootfs string   `json:"rootfs"`
+		Labels []string `json:"labels"`
+		Mounts []struct {
+			Source      string `json:"source"`
+			Destination string `json:"destination"`
+			Device      string `json:"device"`
+			Flags       int    `json:"flags"`
+		} `json:"mounts"`
+		// Namespaces unshared from the host ones, eg: NEWPID, NEWNET, NEWIPC.
+		Namespaces []struct {
+			Type string `json:"type"`
+		} `json:"namespaces"`
+	} `json:"config"`
+}
+
+func newBpmEngine(_ context.Context, logger *slog.Logger, runcDir string) (Engine, error) {
+	stat, err := os.Stat(runcDir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", runcDir)
+	}
+	return &bpmEngine{
+		logger:  logger,
+		runcDir: runcDir,
+	}, nil
+}
+
+func (b *bpmEngine) copy(ctx context.Context) (Engine, error) {
+	return newBpmEngine(ctx, b.logger, b.runcDir)
+}
+
+// bpmName returns the "<job>/<process>" name of a bpm container,
+// from the runc bundle label, eg: "bundle=/var/vcap/data/bpm/bundles/<job>/<process>".
+// Falls back at the container ID.
+func bpmName(state *runcState) string {
+	for _, label := range state.Config.Labels {
+		if bundle, ok := strings.CutPrefix(label, "bundle="); ok {
+			process := filepath.Base(bundle)
+			job := filepath.Base(filepath.Dir(bundle))
+			return job + "/" + process
+		}
+	}
+	return state.ID
+}
+
+// hasNamespace returns whether the container has its own namespace of the provided type,
+// instead of sharing the host one.
+func (state *runcState) hasNamespace(nsType string) bool {
+	for _, ns := range state.Config.Namespaces {
+		if ns.Type == nsType {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bpmEngine) stateToInfo(state *runcState) event.Info {
+	mounts := make([]event.Mount, 0, len(state.Config.Mounts))
+	for _, m := range state.Config.Mounts {
+		mounts = append(mounts, event.Mount{
+			Source:      m.Source,
+			Destination: m.Destination,
+			// MS_RDONLY
+			RW:   m.Flags&0x1 == 0,
+			Type: m.Device,
+		})
+	}
+	info := event.Info{
+		Container: event.Container{
+			Type:         typeBpm.ToCTValue(),
+			ID:           state.ID,
+			Name:         bpmName(state),
+			Image:        state.Config.Rootfs,
+			CPUPeriod:    defaultCpuPeriod,
+			CPUShares:    defaultCpuShares,
+			CreatedTime:  state.Created.Unix(),
+			CreatedAt:    unixNano(state.Created),
+			FullID:       state.ID,
+			HostIPC:      !state.hasNamespace("NEWIPC"),
+			HostNetwork:  !state.hasNamespace("NEWNET"),
+			HostPID:      !state.hasNamespace("NEWPID"),
+			Labels:       map[string]string{},
+			Mounts:       mounts,
+			PortMappings: []event.PortMapping{},
+			Size:         -1,
+		},
+	}
+	if cgroupDir, ok := state.CgroupPaths[""]; ok {
+		fillCgroupLimits(&info.Container, filepath.Join(config.GetHostRoot(), cgroupDir))
+	}
+	return info
+}
+
+func (b *bpmEngine) readState(id string) (*event.Event, error) {
+	data, err := os.ReadFile(filepath.Join(b.runcDir, id, "state.json"))
+	if err != nil {
+		return nil, err
+	}
+	var state runcState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.ID == "" {
+		state.ID = id
+	}
+	return &event.Event{
+		Info:     b.stateToInfo(&state),
+		IsCreate: true,
+	}, nil
+}
+
+func (b *bpmEngine) get(_ context.Context, containerId string) (*event.Event, error) {
+	return b.readState(containerId)
+}
+
+func (b *bpmEngine) Name() string {
+	return string(typeBpm)
+}
+
+func (b *bpmEngine) Sock() string {
+	return b.runcDir
+}
+
+func (b *bpmEngine) List(_ context.Context) ([]event.Event, error) {
+	entries, err := os.ReadDir(b.runcDir)
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		evt, err := b.readState(entry.Name())
+		if err != nil {
+			b.logger.LogAttrs(context.Background(), slog.LevelDebug, "failed to read runc state", slog.String("container_id", entry.Name()), slog.String("err", err.Error()))
+			continue
+		}
+		evts = append(evts, *evt)
+	}
+	return evts, nil
+}
+
+// Listen polls the runc root directory, where a state directory
+// is created for each started container and removed once it gets deleted.
+func (b *bpmEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, b.logger, bpmPollInterval, b.List)
+}
@@ -0,0 +1,96 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const runcStateJson = `{
+  "id": "redis.redis-server",
+  "init_process_pid": 4242,
+  "created": "2024-11-07T10:30:03Z",
+  "config": {
+    "rootfs": "/var/vcap/data/bpm/bundles/redis/redis-server/rootfs",
+    "labels": [
+      "bundle=/var/vcap/data/bpm/bundles/redis/redis-server"
+    ],
+    "mounts": [
+      {
+        "source": "/var/vcap/jobs/redis",
+        "destination": "/var/vcap/jobs/redis",
+        "device": "bind",
+        "flags": 1
+      },
+      {
+        "source": "/var/vcap/data/redis",
+        "destination": "/var/vcap/data/redis",
+        "device": "bind",
+        "flags": 0
+      }
+    ],
+    "namespaces": [
+      {"type": "NEWNS"},
+      {"type": "NEWUTS"},
+      {"type": "NEWIPC"},
+      {"type": "NEWPID"}
+    ]
+  }
+}`
+
+func TestBpm(t *testing.T) {
+	runcDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(runcDir, "redis.redis-server"), 0755))
+	err := os.WriteFile(filepath.Join(runcDir, "redis.redis-server", "state.json"), []byte(runcStateJson), 0644)
+	require.NoError(t, err)
+	// Stale directory without a state file
+	require.NoError(t, os.Mkdir(filepath.Join(runcDir, "stale"), 0755))
+
+	engine, err := newBpmEngine(context.Background(), slog.Default(), runcDir)
+	require.NoError(t, err)
+
+	expectedEvent := event.Event{
+		Info: event.Info{
+			Container: event.Container{
+				Type:        typeBpm.ToCTValue(),
+				ID:          "redis.redis-server",
+				Name:        "redis/redis-server",
+				Image:       "/var/vcap/data/bpm/bundles/redis/redis-server/rootfs",
+				CPUPeriod:   defaultCpuPeriod,
+				CPUShares:   defaultCpuShares,
+				CreatedTime: 1730975403,
+				CreatedAt:   1730975403000000000,
+				FullID:      "redis.redis-server",
+				HostNetwork: true,
+				Labels:      map[string]string{},
+				Mounts: []event.Mount{
+					{Source: "/var/vcap/jobs/redis", Destination: "/var/vcap/jobs/redis", RW: false, Type: "bind"},
+					{Source: "/var/vcap/data/redis", Destination: "/var/vcap/data/redis", RW: true, Type: "bind"},
+				},
+				PortMappings: []event.PortMapping{},
+				Size:         -1,
+			},
+		},
+		IsCreate: true,
+	}
+
+	events, err := engine.List(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, expectedEvent, events[0])
+
+	evt, err := engine.(getter).get(context.Background(), "redis.redis-server")
+	assert.NoError(t, err)
+	assert.Equal(t, &expectedEvent, evt)
+
+	evt, err = engine.(getter).get(context.Background(), "stale")
+	assert.Error(t, err)
+	assert.Nil(t, evt)
+}
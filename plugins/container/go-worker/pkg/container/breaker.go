@@ -0,0 +1,92 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// breakers holds the circuit breakers of the engines inspections, by engine type.
+var breakers sync.Map
+
+// circuitBreaker stops the inspections of an engine after config.GetCircuitBreakerFailures()
+// consecutive timeouts, so that a hung runtime daemon does not keep stalling the listings and the events streams:
+// while open, only a probe inspection is allowed every config.GetCircuitBreakerCooldown(),
+// closing the breaker as soon as one succeeds.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func breakerOf(engine engineType) *circuitBreaker {
+	b, _ := breakers.LoadOrStore(engine, &circuitBreaker{})
+	return b.(*circuitBreaker)
+}
+
+// allow returns whether an inspection can be performed, that must be followed by a call to done.
+func (b *circuitBreaker) allow() bool {
+	threshold := config.GetCircuitBreakerFailures()
+	if threshold == 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// done accounts for the outcome of an inspection, returning whether it opened the breaker.
+func (b *circuitBreaker) done(timedOut bool) bool {
+	threshold := config.GetCircuitBreakerFailures()
+	if threshold == 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !timedOut {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures < threshold {
+		return false
+	}
+	b.openUntil = time.Now().Add(config.GetCircuitBreakerCooldown())
+	return b.failures == threshold
+}
+
+// guardedInspect calls inspect bounded by the inspect timeout of the engine, through its circuit breaker.
+// While the breaker is open, inspect gets an expired context,
+// so that engines fill the containers with the minimal set of infos they already have.
+func guardedInspect(ctx context.Context, engine engineType, inspect func(ctx context.Context)) {
+	b := breakerOf(engine)
+	if !b.allow() {
+		expiredCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		inspect(expiredCtx)
+		return
+	}
+	inspectCtx, cancel := inspectContext(ctx, engine)
+	defer cancel()
+	inspect(inspectCtx)
+	// Only the inspection timeout accounts for a failure, not the one of the caller.
+	timedOut := errors.Is(inspectCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	if b.done(timedOut) {
+		countBreakerOpened()
+		slog.Default().LogAttrs(ctx, slog.LevelWarn, "engine inspections keep timing out, reporting minimal infos",
+			slog.String("engine", string(engine)), slog.Duration("cooldown", config.GetCircuitBreakerCooldown()))
+	}
+}
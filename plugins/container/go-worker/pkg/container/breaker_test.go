@@ -0,0 +1,79 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	require.NoError(t, config.Load(`{"circuit_breaker": {"failures": 2, "cooldown_ms": 50}}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"circuit_breaker": {"failures": 0, "cooldown_ms": 30000}}`)
+	})
+
+	b := &circuitBreaker{}
+	assert.True(t, b.allow())
+	assert.False(t, b.done(true))
+	// Successes reset the consecutive failures
+	assert.True(t, b.allow())
+	assert.False(t, b.done(false))
+	assert.True(t, b.allow())
+	assert.False(t, b.done(true))
+	assert.True(t, b.allow())
+	assert.True(t, b.done(true))
+	assert.False(t, b.allow())
+
+	// A single probe is allowed after the cooldown
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+	assert.False(t, b.done(true))
+	assert.False(t, b.allow())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.allow())
+	assert.False(t, b.done(false))
+	assert.True(t, b.allow())
+}
+
+func TestGuardedInspect(t *testing.T) {
+	require.NoError(t, config.Load(`{"inspect_timeout_ms": 10, "circuit_breaker": {"failures": 1, "cooldown_ms": 60000}}`))
+	t.Cleanup(func() {
+		breakers.Delete(typeGarden)
+		_ = config.Load(`{"inspect_timeout_ms": 5000, "circuit_breaker": {"failures": 0, "cooldown_ms": 30000}}`)
+	})
+
+	opened := Metric(MetricBreakerOpened)
+	// A canceled caller does not account for a failure
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	guardedInspect(ctx, typeGarden, func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	assert.Equal(t, opened, Metric(MetricBreakerOpened))
+
+	// A stuck inspection opens the breaker
+	guardedInspect(context.Background(), typeGarden, func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	assert.Equal(t, opened+1, Metric(MetricBreakerOpened))
+
+	// Following inspections get an expired context
+	var expired bool
+	guardedInspect(context.Background(), typeGarden, func(ctx context.Context) {
+		expired = ctx.Err() != nil
+	})
+	assert.True(t, expired)
+
+	// Other engines are not affected
+	guardedInspect(context.Background(), typeDocker, func(ctx context.Context) {
+		expired = ctx.Err() != nil
+	})
+	assert.False(t, expired)
+}
@@ -0,0 +1,137 @@
+package container
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// metadata caches the infos of containers seen by the worker, so that repeated lookups
+// (ie: fetcher requests and resyncs after reconnections) do not need to inspect them again.
+// It is nil, thus disabled, until InitCache is called.
+var metadata *metadataCache
+
+type cacheEntry struct {
+	info    event.Info
+	expires time.Time
+}
+
+// metadataCache is a LRU cache of container infos keyed by container ID,
+// whose entries expire after ttl, if set.
+type metadataCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	lru        *list.List
+	entries    map[string]*list.Element
+}
+
+func newMetadataCache(ttl time.Duration, maxEntries int) *metadataCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &metadataCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// InitCache sets up the containers metadata cache, the tracking of the infos sent for them
+// and of the suppressed ones, from the current config.
+func InitCache() {
+	metadata = newMetadataCache(config.GetCacheTTL(), config.GetCacheMaxEntries())
+	initPayloadTracker()
+	initIDSets()
+}
+
+// CacheEvent keeps the metadata cache in sync with an event sent by an engine:
+// infos of created containers are stored, while removed containers are evicted.
+// Partial events are not stored, since they lack most infos,
+// and neither are lifecycle events of existing containers (die, pause, unpause, oom, stats)
+// and ignored infrastructure containers.
+func CacheEvent(evt event.Event) {
+	if evt.IsDie || evt.IsPause || evt.IsUnpause || evt.IsOOM || evt.IsStats {
+		return
+	}
+	if !evt.IsCreate {
+		metadata.remove(evt.ID)
+	} else if !evt.IsPartial && !matchIgnore(evt.Info) {
+		metadata.add(evt.Info)
+	}
+}
+
+// cachedInfo returns the cached infos of a container, if any, or its minimal infos.
+func cachedInfo(minimal event.Info) event.Info {
+	if info, ok := metadata.get(minimal.ID); ok {
+		return info
+	}
+	return minimal
+}
+
+func (m *metadataCache) get(id string) (event.Info, bool) {
+	if m == nil {
+		return event.Info{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[id]
+	if !ok {
+		countCacheLookup(false)
+		return event.Info{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if m.ttl > 0 && time.Now().After(entry.expires) {
+		m.lru.Remove(elem)
+		delete(m.entries, id)
+		countCacheLookup(false)
+		return event.Info{}, false
+	}
+	m.lru.MoveToFront(elem)
+	countCacheLookup(true)
+	return entry.info, true
+}
+
+// add stores the infos of a container, evicting the least recently used one when full.
+// The expiration of an existing entry is only pushed back when its infos changed,
+// so that entries served by the cache itself still expire.
+func (m *metadataCache) add(info event.Info) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := time.Now().Add(m.ttl)
+	if elem, ok := m.entries[info.ID]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if !reflect.DeepEqual(entry.info, info) {
+			entry.info = info
+			entry.expires = expires
+		}
+		m.lru.MoveToFront(elem)
+		return
+	}
+	m.entries[info.ID] = m.lru.PushFront(&cacheEntry{info: info, expires: expires})
+	if m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*cacheEntry).info.ID)
+	}
+}
+
+func (m *metadataCache) remove(id string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.entries[id]; ok {
+		m.lru.Remove(elem)
+		delete(m.entries, id)
+	}
+}
@@ -0,0 +1,95 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func cacheInfo(id, name string) event.Info {
+	return event.Info{Container: event.Container{ID: id, Name: name}}
+}
+
+func TestMetadataCache(t *testing.T) {
+	m := newMetadataCache(time.Hour, 2)
+	m.add(cacheInfo("first", "first"))
+	m.add(cacheInfo("second", "second"))
+
+	info, ok := m.get("first")
+	assert.True(t, ok)
+	assert.Equal(t, "first", info.Name)
+
+	// "second" is the least recently used one
+	m.add(cacheInfo("third", "third"))
+	_, ok = m.get("second")
+	assert.False(t, ok)
+	_, ok = m.get("first")
+	assert.True(t, ok)
+	_, ok = m.get("third")
+	assert.True(t, ok)
+
+	// Updated infos replace the cached ones
+	m.add(cacheInfo("first", "renamed"))
+	info, ok = m.get("first")
+	assert.True(t, ok)
+	assert.Equal(t, "renamed", info.Name)
+
+	m.remove("first")
+	_, ok = m.get("first")
+	assert.False(t, ok)
+}
+
+func TestMetadataCacheTTL(t *testing.T) {
+	m := newMetadataCache(20*time.Millisecond, 10)
+	m.add(cacheInfo("first", "first"))
+	_, ok := m.get("first")
+	assert.True(t, ok)
+
+	// Adding the same infos again does not postpone the expiration
+	time.Sleep(15 * time.Millisecond)
+	m.add(cacheInfo("first", "first"))
+	time.Sleep(15 * time.Millisecond)
+	_, ok = m.get("first")
+	assert.False(t, ok)
+}
+
+func TestCacheEvent(t *testing.T) {
+	metadata = newMetadataCache(time.Hour, 10)
+	t.Cleanup(func() {
+		metadata = nil
+	})
+
+	CacheEvent(event.Event{Info: cacheInfo("partial", ""), IsCreate: true, IsPartial: true})
+	_, ok := metadata.get("partial")
+	assert.False(t, ok)
+
+	CacheEvent(event.Event{Info: cacheInfo("full", "full"), IsCreate: true})
+	_, ok = metadata.get("full")
+	assert.True(t, ok)
+
+	// Dead containers are kept, since they might be restarted
+	CacheEvent(event.Event{Info: cacheInfo("full", ""), IsDie: true})
+	info, ok := metadata.get("full")
+	assert.True(t, ok)
+	assert.Equal(t, "full", info.Name)
+
+	CacheEvent(event.Event{Info: cacheInfo("full", ""), IsCreate: false})
+	_, ok = metadata.get("full")
+	assert.False(t, ok)
+
+	// Ignored infrastructure containers are not cached
+	pause := cacheInfo("pause", "pause")
+	pause.Image = "registry.k8s.io/pause:3.9"
+	CacheEvent(event.Event{Info: pause, IsCreate: true})
+	_, ok = metadata.get("pause")
+	assert.False(t, ok)
+
+	// A disabled cache never hits
+	metadata = newMetadataCache(time.Hour, 0)
+	CacheEvent(event.Event{Info: cacheInfo("full", "full"), IsCreate: true})
+	_, ok = metadata.get("full")
+	assert.False(t, ok)
+}
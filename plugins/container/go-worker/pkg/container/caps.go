@@ -0,0 +1,23 @@
+package container
+
+import "strings"
+
+// normalizeCaps returns caps as upper case, "CAP_" prefixed, capability names;
+// engines accept both "NET_ADMIN" and "cap_net_admin" forms, and "ALL".
+func normalizeCaps(caps []string) []string {
+	if len(caps) == 0 {
+		return nil
+	}
+	normalized := make([]string, 0, len(caps))
+	for _, capability := range caps {
+		capability = strings.ToUpper(strings.TrimSpace(capability))
+		if capability == "" {
+			continue
+		}
+		if capability != "ALL" && !strings.HasPrefix(capability, "CAP_") {
+			capability = "CAP_" + capability
+		}
+		normalized = append(normalized, capability)
+	}
+	return normalized
+}
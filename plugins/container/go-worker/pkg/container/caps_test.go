@@ -0,0 +1,36 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCaps(t *testing.T) {
+	tCases := map[string]struct {
+		caps         []string
+		expectedCaps []string
+	}{
+		"nil": {},
+		"empty": {
+			caps: []string{},
+		},
+		"docker form": {
+			caps:         []string{"NET_ADMIN", "SYS_PTRACE"},
+			expectedCaps: []string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"},
+		},
+		"prefixed and lower case": {
+			caps:         []string{"CAP_SYS_ADMIN", "cap_net_raw", " chown "},
+			expectedCaps: []string{"CAP_SYS_ADMIN", "CAP_NET_RAW", "CAP_CHOWN"},
+		},
+		"all": {
+			caps:         []string{"all", ""},
+			expectedCaps: []string{"ALL"},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCaps, normalizeCaps(tc.caps))
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package container
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	cgroupV2Root = "/sys/fs/cgroup"
+	// Each cgroup v1 controller hierarchy is mounted in its own subdirectory, eg: /sys/fs/cgroup/cpuset
+	cgroupV1Root = "/sys/fs/cgroup"
+)
+
+// Drivers managing the containers cgroups
+const (
+	cgroupDriverSystemd  = "systemd"
+	cgroupDriverCgroupfs = "cgroupfs"
+)
+
+// cgroupLimits are the resource limits of a cgroup v2 directory, normalized as the container engines report them,
+// ie: 0 when unlimited, and swapLimit being the memory+swap limit.
+type cgroupLimits struct {
+	cpuQuota       int64
+	cpuPeriod      int64
+	memoryLimit    int64
+	swapLimit      int64
+	pidsLimit      int64
+	cpusetCPUCount int64
+}
+
+// readCgroupValue reads the first line of a cgroup interface file;
+// "max" values are returned as empty strings.
+func readCgroupValue(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	value, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if value == "max" {
+		return ""
+	}
+	return value
+}
+
+func parseCgroupInt(value string) int64 {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// readCgroupLimits reads cpu.max, memory.max, memory.swap.max, pids.max and cpuset.cpus.effective
+// of a cgroup v2 directory.
+func readCgroupLimits(dir string) cgroupLimits {
+	var limits cgroupLimits
+
+	// cpu.max: "$MAX $PERIOD", eg: "50000 100000" or "max 100000"
+	if quota, period, ok := strings.Cut(readCgroupValue(dir, "cpu.max"), " "); ok && quota != "max" {
+		limits.cpuQuota = parseCgroupInt(quota)
+		limits.cpuPeriod = parseCgroupInt(period)
+	}
+	limits.memoryLimit = parseCgroupInt(readCgroupValue(dir, "memory.max"))
+	if limits.memoryLimit > 0 {
+		if swap := readCgroupValue(dir, "memory.swap.max"); swap != "" {
+			limits.swapLimit = limits.memoryLimit + parseCgroupInt(swap)
+		} else if _, err := os.Stat(filepath.Join(dir, "memory.swap.max")); err == nil {
+			// Unlimited swap
+			limits.swapLimit = -1
+		}
+	}
+	limits.pidsLimit = parseCgroupInt(readCgroupValue(dir, "pids.max"))
+	// Only available when the cpuset controller is enabled for the cgroup
+	limits.cpusetCPUCount = countCPUSet(readCgroupValue(dir, "cpuset.cpus.effective"))
+	return limits
+}
+
+// procCgroupV2Dir returns the cgroup v2 directory of a process, from the "0::<path>" entry of /proc/<pid>/cgroup;
+// it is empty when the host does not use cgroups v2.
+func procCgroupV2Dir(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	hostRoot := config.GetHostRoot()
+	f, err := os.Open(filepath.Join(hostRoot, "/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			dir := filepath.Join(hostRoot, cgroupV2Root, path)
+			// Hybrid hosts have the cgroup v2 entry too, without controllers
+			if _, err := os.Stat(filepath.Join(dir, "cgroup.controllers")); err != nil {
+				return ""
+			}
+			return dir
+		}
+	}
+	return ""
+}
+
+// procCgroupV1Dir returns the directory of a process in a cgroup v1 controller hierarchy,
+// from the "<id>:<controllers>:<path>" entries of /proc/<pid>/cgroup; it is empty when the controller is not mounted.
+func procCgroupV1Dir(pid int, controller string) string {
+	if pid <= 0 {
+		return ""
+	}
+	hostRoot := config.GetHostRoot()
+	f, err := os.Open(filepath.Join(hostRoot, "/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 || !slices.Contains(strings.Split(fields[1], ","), controller) {
+			continue
+		}
+		dir := filepath.Join(hostRoot, cgroupV1Root, controller, fields[2])
+		if _, err := os.Stat(dir); err != nil {
+			return ""
+		}
+		return dir
+	}
+	return ""
+}
+
+// fillProcCgroupLimits fills the resource limits of a container from the cgroup of its process, if any;
+// on cgroup v1 hosts, only the effective cpuset is read.
+func fillProcCgroupLimits(ctr *event.Container, pid int) {
+	if dir := procCgroupV2Dir(pid); dir != "" {
+		fillCgroupLimits(ctr, dir)
+	} else if dir = procCgroupV1Dir(pid, "cpuset"); dir != "" {
+		if count := countCPUSet(readCgroupValue(dir, "cpuset.effective_cpus")); count > 0 {
+			ctr.CPUSetCPUCount = count
+		}
+	}
+}
+
+// fillCgroupLimits fills the resource limits not reported by the container engine
+// from the container cgroup v2 directory, if any. The cpus the container can run on are always taken
+// from its effective cpuset, since it is narrowed by the parent cgroups and changed by dynamic cpu managers,
+// eg: the kubelet static policy one, unlike the configured one.
+func fillCgroupLimits(ctr *event.Container, dir string) {
+	if dir == "" {
+		return
+	}
+	limits := readCgroupLimits(dir)
+	if limits.cpusetCPUCount > 0 {
+		ctr.CPUSetCPUCount = limits.cpusetCPUCount
+	}
+	if ctr.CPUQuota == 0 && limits.cpuQuota > 0 {
+		ctr.CPUQuota = limits.cpuQuota
+		ctr.CPUPeriod = limits.cpuPeriod
+	}
+	if ctr.MemoryLimit == 0 {
+		ctr.MemoryLimit = limits.memoryLimit
+		if ctr.SwapLimit == 0 {
+			ctr.SwapLimit = limits.swapLimit
+		}
+	}
+	if ctr.PidsLimit == 0 {
+		ctr.PidsLimit = limits.pidsLimit
+	}
+}
+
+// procCgroupPath returns the cgroup path of a process, relative to the cgroup root, from /proc/<pid>/cgroup:
+// the cgroup v2 one, or the memory controller one on cgroup v1 hosts.
+func procCgroupPath(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(config.GetHostRoot(), "/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var v1Path string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			// Hybrid hosts have the cgroup v2 entry too, at the root when not used
+			if fields[2] != "/" {
+				return fields[2]
+			}
+		} else if slices.Contains(strings.Split(fields[1], ","), "memory") {
+			v1Path = fields[2]
+		}
+	}
+	return v1Path
+}
+
+// cgroupPathDriver guesses the driver of a cgroup path: systemd places containers in
+// ".scope" units under ".slice" units, eg: /system.slice/docker-<id>.scope.
+func cgroupPathDriver(path string) string {
+	if path == "" || path == "/" {
+		return ""
+	}
+	for _, elem := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasSuffix(elem, ".slice") || strings.HasSuffix(elem, ".scope") {
+			return cgroupDriverSystemd
+		}
+	}
+	return cgroupDriverCgroupfs
+}
+
+// expandSlice expands a systemd slice name to its cgroup path,
+// eg: "kubepods-besteffort.slice" to "/kubepods.slice/kubepods-besteffort.slice".
+func expandSlice(slice string) string {
+	name, ok := strings.CutSuffix(slice, ".slice")
+	if !ok || name == "-" {
+		return ""
+	}
+	var path, prefix string
+	for _, part := range strings.Split(name, "-") {
+		prefix += part
+		path += "/" + prefix + ".slice"
+		prefix += "-"
+	}
+	return path
+}
+
+// specCgroupPath returns the cgroup path and driver of an OCI spec cgroupsPath:
+// systemd ones are in the "slice:prefix:name" form, eg: "kubepods.slice:cri-containerd:<id>",
+// standing for the "<prefix>-<name>.scope" unit under the slice.
+func specCgroupPath(cgroupsPath string) (string, string) {
+	if cgroupsPath == "" {
+		return "", ""
+	}
+	parts := strings.Split(cgroupsPath, ":")
+	if len(parts) != 3 || strings.HasPrefix(cgroupsPath, "/") {
+		return filepath.Join("/", cgroupsPath), cgroupDriverCgroupfs
+	}
+	slice, prefix, name := parts[0], parts[1], parts[2]
+	if slice == "" {
+		slice = "system.slice"
+	}
+	unit := name
+	if !strings.HasSuffix(name, ".slice") {
+		unit = name + ".scope"
+		if prefix != "" {
+			unit = prefix + "-" + unit
+		}
+	}
+	return expandSlice(slice) + "/" + unit, cgroupDriverSystemd
+}
+
+// fillCgroupPath fills the cgroup path and driver of a container from its OCI spec cgroupsPath, if any,
+// otherwise from the cgroup of its process.
+func fillCgroupPath(ctr *event.Container, cgroupsPath string, pid int) {
+	if ctr.CgroupPath, ctr.CgroupDriver = specCgroupPath(cgroupsPath); ctr.CgroupPath != "" {
+		return
+	}
+	ctr.CgroupPath = procCgroupPath(pid)
+	ctr.CgroupDriver = cgroupPathDriver(ctr.CgroupPath)
+}
@@ -0,0 +1,229 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCgroupFiles(t *testing.T, dir string, files map[string]string) {
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+}
+
+func TestReadCgroupLimits(t *testing.T) {
+	tCases := map[string]struct {
+		files          map[string]string
+		expectedLimits cgroupLimits
+	}{
+		"unlimited": {
+			files: map[string]string{
+				"cpu.max":         "max 100000\n",
+				"memory.max":      "max\n",
+				"memory.swap.max": "max\n",
+				"pids.max":        "max\n",
+			},
+		},
+		"limited": {
+			files: map[string]string{
+				"cpu.max":               "50000 100000\n",
+				"memory.max":            "536870912\n",
+				"memory.swap.max":       "268435456\n",
+				"pids.max":              "100\n",
+				"cpuset.cpus.effective": "0-3,8\n",
+			},
+			expectedLimits: cgroupLimits{
+				cpuQuota:       50000,
+				cpuPeriod:      100000,
+				memoryLimit:    536870912,
+				swapLimit:      805306368,
+				pidsLimit:      100,
+				cpusetCPUCount: 5,
+			},
+		},
+		"unlimited swap": {
+			files: map[string]string{
+				"memory.max":      "536870912\n",
+				"memory.swap.max": "max\n",
+			},
+			expectedLimits: cgroupLimits{
+				memoryLimit: 536870912,
+				swapLimit:   -1,
+			},
+		},
+		"swap accounting disabled": {
+			files: map[string]string{
+				"memory.max": "536870912\n",
+			},
+			expectedLimits: cgroupLimits{
+				memoryLimit: 536870912,
+			},
+		},
+		"missing files": {},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCgroupFiles(t, dir, tc.files)
+			assert.Equal(t, tc.expectedLimits, readCgroupLimits(dir))
+		})
+	}
+}
+
+func TestFillCgroupLimits(t *testing.T) {
+	hostRoot := t.TempDir()
+	err := config.Load(`{"host_root": "` + hostRoot + `"}`)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = config.Load(`{"host_root": ""}`) })
+
+	writeCgroupFiles(t, filepath.Join(hostRoot, "proc", "42"), map[string]string{
+		"cgroup": "0::/system.slice/docker-abc.scope\n",
+	})
+	cgroupDir := filepath.Join(hostRoot, cgroupV2Root, "system.slice", "docker-abc.scope")
+	writeCgroupFiles(t, cgroupDir, map[string]string{
+		"cgroup.controllers":    "cpu memory pids\n",
+		"cpu.max":               "200000 100000\n",
+		"memory.max":            "1073741824\n",
+		"memory.swap.max":       "0\n",
+		"pids.max":              "512\n",
+		"cpuset.cpus.effective": "2-3\n",
+	})
+	// Cgroup v1 host, only exposing a hybrid cgroup v2 hierarchy
+	writeCgroupFiles(t, filepath.Join(hostRoot, "proc", "43"), map[string]string{
+		"cgroup": "12:pids:/docker/def\n5:cpu,cpuset:/docker/def\n0::/docker/def\n",
+	})
+	writeCgroupFiles(t, filepath.Join(hostRoot, cgroupV2Root, "docker", "def"), nil)
+	writeCgroupFiles(t, filepath.Join(hostRoot, cgroupV1Root, "cpuset", "docker", "def"), map[string]string{
+		"cpuset.effective_cpus": "0,2\n",
+	})
+
+	assert.Equal(t, cgroupDir, procCgroupV2Dir(42))
+	assert.Empty(t, procCgroupV2Dir(43))
+	assert.Empty(t, procCgroupV2Dir(44))
+	assert.Empty(t, procCgroupV2Dir(0))
+	assert.Equal(t, filepath.Join(hostRoot, cgroupV1Root, "cpuset", "docker", "def"), procCgroupV1Dir(43, "cpuset"))
+	assert.Empty(t, procCgroupV1Dir(43, "memory"))
+	assert.Empty(t, procCgroupV1Dir(44, "cpuset"))
+
+	// Limits reported by the container engine are kept, unlike the configured cpuset
+	ctr := event.Container{CPUPeriod: defaultCpuPeriod, MemoryLimit: 2147483648, SwapLimit: 4294967296, CPUSetCPUCount: 4}
+	fillProcCgroupLimits(&ctr, 42)
+	assert.Equal(t, event.Container{
+		CPUPeriod:      100000,
+		CPUQuota:       200000,
+		MemoryLimit:    2147483648,
+		SwapLimit:      4294967296,
+		PidsLimit:      512,
+		CPUSetCPUCount: 2,
+	}, ctr)
+
+	ctr = event.Container{CPUPeriod: defaultCpuPeriod}
+	fillProcCgroupLimits(&ctr, 42)
+	assert.Equal(t, event.Container{
+		CPUPeriod:      100000,
+		CPUQuota:       200000,
+		MemoryLimit:    1073741824,
+		SwapLimit:      1073741824,
+		PidsLimit:      512,
+		CPUSetCPUCount: 2,
+	}, ctr)
+
+	// Only the effective cpuset is read from cgroup v1 hierarchies
+	ctr = event.Container{CPUPeriod: defaultCpuPeriod}
+	fillProcCgroupLimits(&ctr, 43)
+	assert.Equal(t, event.Container{CPUPeriod: defaultCpuPeriod, CPUSetCPUCount: 2}, ctr)
+
+	ctr = event.Container{CPUPeriod: defaultCpuPeriod, CPUSetCPUCount: 4}
+	fillProcCgroupLimits(&ctr, 44)
+	assert.Equal(t, event.Container{CPUPeriod: defaultCpuPeriod, CPUSetCPUCount: 4}, ctr)
+}
+
+func TestSpecCgroupPath(t *testing.T) {
+	tCases := map[string]struct {
+		cgroupsPath    string
+		expectedPath   string
+		expectedDriver string
+	}{
+		"empty": {},
+		"cgroupfs": {
+			cgroupsPath:    "/kubepods/besteffort/pod123/abc",
+			expectedPath:   "/kubepods/besteffort/pod123/abc",
+			expectedDriver: cgroupDriverCgroupfs,
+		},
+		"cgroupfs relative": {
+			cgroupsPath:    "default/abc",
+			expectedPath:   "/default/abc",
+			expectedDriver: cgroupDriverCgroupfs,
+		},
+		"systemd": {
+			cgroupsPath:    "kubepods-besteffort-pod123.slice:cri-containerd:abc",
+			expectedPath:   "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod123.slice/cri-containerd-abc.scope",
+			expectedDriver: cgroupDriverSystemd,
+		},
+		"systemd default slice": {
+			cgroupsPath:    ":nerdctl:abc",
+			expectedPath:   "/system.slice/nerdctl-abc.scope",
+			expectedDriver: cgroupDriverSystemd,
+		},
+		"systemd root slice": {
+			cgroupsPath:    "-.slice:crio:abc",
+			expectedPath:   "/crio-abc.scope",
+			expectedDriver: cgroupDriverSystemd,
+		},
+		"systemd slice unit": {
+			cgroupsPath:    "machine.slice:libpod:abc.slice",
+			expectedPath:   "/machine.slice/abc.slice",
+			expectedDriver: cgroupDriverSystemd,
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			path, driver := specCgroupPath(tc.cgroupsPath)
+			assert.Equal(t, tc.expectedPath, path)
+			assert.Equal(t, tc.expectedDriver, driver)
+		})
+	}
+}
+
+func TestFillCgroupPath(t *testing.T) {
+	hostRoot := t.TempDir()
+	err := config.Load(`{"host_root": "` + hostRoot + `"}`)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = config.Load(`{"host_root": ""}`) })
+
+	writeCgroupFiles(t, filepath.Join(hostRoot, "proc", "42"), map[string]string{
+		"cgroup": "0::/system.slice/docker-abc.scope\n",
+	})
+	// Cgroup v1 host, with an unused cgroup v2 hierarchy
+	writeCgroupFiles(t, filepath.Join(hostRoot, "proc", "43"), map[string]string{
+		"cgroup": "12:pids:/docker/def\n4:memory:/docker/def\n0::/\n",
+	})
+
+	var ctr event.Container
+	fillCgroupPath(&ctr, "", 42)
+	assert.Equal(t, "/system.slice/docker-abc.scope", ctr.CgroupPath)
+	assert.Equal(t, cgroupDriverSystemd, ctr.CgroupDriver)
+
+	ctr = event.Container{}
+	fillCgroupPath(&ctr, "", 43)
+	assert.Equal(t, "/docker/def", ctr.CgroupPath)
+	assert.Equal(t, cgroupDriverCgroupfs, ctr.CgroupDriver)
+
+	// The OCI spec wins over the process cgroup
+	ctr = event.Container{}
+	fillCgroupPath(&ctr, "/kubepods/abc", 42)
+	assert.Equal(t, "/kubepods/abc", ctr.CgroupPath)
+	assert.Equal(t, cgroupDriverCgroupfs, ctr.CgroupDriver)
+
+	ctr = event.Container{}
+	fillCgroupPath(&ctr, "", 44)
+	assert.Empty(t, ctr.CgroupPath)
+	assert.Empty(t, ctr.CgroupDriver)
+}
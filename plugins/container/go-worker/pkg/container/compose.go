@@ -0,0 +1,33 @@
+package container
+
+import (
+	"strconv"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// Labels set by docker compose (and nerdctl compose) on service containers;
+// podman-compose sets them too, besides its own ones.
+const (
+	composeProjectLabel         = "com.docker.compose.project"
+	composeServiceLabel         = "com.docker.compose.service"
+	composeContainerNumberLabel = "com.docker.compose.container-number"
+	podmanComposeProjectLabel   = "io.podman.compose.project"
+	podmanComposeServiceLabel   = "io.podman.compose.service"
+)
+
+// setComposeMetadata sets the compose project, service and replica number of a container from its labels.
+// The service name is also the DNS name of the container on the project networks.
+func setComposeMetadata(ctr *event.Container, labels map[string]string) {
+	lookup := func(key, fallbackKey string) string {
+		if val := labels[key]; val != "" {
+			return val
+		}
+		return labels[fallbackKey]
+	}
+	ctr.ComposeProject = lookup(composeProjectLabel, podmanComposeProjectLabel)
+	ctr.ComposeService = lookup(composeServiceLabel, podmanComposeServiceLabel)
+	if number, err := strconv.ParseInt(labels[composeContainerNumberLabel], 10, 64); err == nil && number > 0 {
+		ctr.ComposeNumber = number
+	}
+}
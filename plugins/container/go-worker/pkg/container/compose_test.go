@@ -0,0 +1,59 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetComposeMetadata(t *testing.T) {
+	tCases := map[string]struct {
+		labels   map[string]string
+		expected event.Container
+	}{
+		"not a compose container": {
+			labels: map[string]string{"foo": "bar"},
+		},
+		"docker compose": {
+			labels: map[string]string{
+				"com.docker.compose.project":          "shop",
+				"com.docker.compose.service":          "frontend",
+				"com.docker.compose.container-number": "1",
+			},
+			expected: event.Container{
+				ComposeProject: "shop",
+				ComposeService: "frontend",
+				ComposeNumber:  1,
+			},
+		},
+		"invalid container number": {
+			labels: map[string]string{
+				"com.docker.compose.project":          "shop",
+				"com.docker.compose.service":          "frontend",
+				"com.docker.compose.container-number": "first",
+			},
+			expected: event.Container{
+				ComposeProject: "shop",
+				ComposeService: "frontend",
+			},
+		},
+		"podman-compose": {
+			labels: map[string]string{
+				"io.podman.compose.project": "shop",
+				"io.podman.compose.service": "frontend",
+			},
+			expected: event.Container{
+				ComposeProject: "shop",
+				ComposeService: "frontend",
+			},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var ctr event.Container
+			setComposeMetadata(&ctr, tc.labels)
+			assert.Equal(t, tc.expected, ctr)
+		})
+	}
+}
@@ -2,14 +2,17 @@ package container
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/containerd/containerd/api/events"
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
+	eventtypes "github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/containerd/v2/pkg/oci"
 	"github.com/containerd/typeurl/v2"
@@ -19,31 +22,95 @@ import (
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 )
 
+// containerdRestartCountLabel is set by the containerd restart monitor,
+// eg: on nerdctl containers with a restart policy.
+const containerdRestartCountLabel = "containerd.io/restart.count"
+
 func init() {
 	engineGenerators[typeContainerd] = newContainerdEngine
 }
 
 type containerdEngine struct {
 	client *containerd.Client
+	logger *slog.Logger
 	socket string
+	// namespaces is the configured set of namespaces to be watched; empty means all of them.
+	namespaces map[string]struct{}
+	// known tracks the containers notified by the engine, seeded by List.
+	known *knownContainers
 }
 
-func newContainerdEngine(_ context.Context, _ *slog.Logger, socket string) (Engine, error) {
-	client, err := containerd.New(socket)
+func newContainerdEngine(_ context.Context, logger *slog.Logger, socket string) (Engine, error) {
+	address := socket
+	if isNamedPipe(socket) {
+		address = namedPipePath(socket)
+	}
+	client, err := containerd.New(address)
 	if err != nil {
 		return nil, err
 	}
-	return &containerdEngine{client: client, socket: socket}, nil
+	namespacesCfg := config.GetEngineNamespaces(string(typeContainerd))
+	namespacesSet := make(map[string]struct{}, len(namespacesCfg))
+	for _, namespace := range namespacesCfg {
+		namespacesSet[namespace] = struct{}{}
+	}
+	return &containerdEngine{client: client, logger: logger, socket: socket, namespaces: namespacesSet, known: newKnownContainers()}, nil
 }
 
 func (c *containerdEngine) copy(ctx context.Context) (Engine, error) {
-	// TODO: change to use logger member once handled
-	return newContainerdEngine(ctx, nil, c.socket)
+	return newContainerdEngine(ctx, c.logger, c.socket)
+}
+
+// isNamespaceEnabled returns whether the namespace is watched by the engine.
+func (c *containerdEngine) isNamespaceEnabled(namespace string) bool {
+	if len(c.namespaces) == 0 {
+		return true
+	}
+	_, ok := c.namespaces[namespace]
+	return ok
+}
+
+// listNamespaces returns the namespaces to be inspected:
+// the configured ones if any, otherwise all the namespaces known by containerd.
+func (c *containerdEngine) listNamespaces(ctx context.Context) ([]string, error) {
+	if len(c.namespaces) > 0 {
+		namespacesList := make([]string, 0, len(c.namespaces))
+		for namespace := range c.namespaces {
+			namespacesList = append(namespacesList, namespace)
+		}
+		return namespacesList, nil
+	}
+	return c.client.NamespaceService().List(ctx)
 }
 
-func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, container containerd.Container) event.Info {
-	info, err := container.Info(namespacedContext)
+// ociIDMappings converts the user namespace mappings of an OCI spec.
+func ociIDMappings(mappings []specs.LinuxIDMapping) []event.IDMapping {
+	var res []event.IDMapping
+	for _, m := range mappings {
+		res = append(res, event.IDMapping{ContainerID: m.ContainerID, HostID: m.HostID, Size: m.Size})
+	}
+	return res
+}
+
+// snapshotUsage returns the bytes used by the writable layer of a container,
+// as reported by its snapshotter; -1 if unknown.
+func (c *containerdEngine) snapshotUsage(namespacedContext context.Context, info containers.Container) int64 {
+	if info.Snapshotter == "" || info.SnapshotKey == "" {
+		return -1
+	}
+	usage, err := c.client.SnapshotService(info.Snapshotter).Usage(namespacedContext, info.SnapshotKey)
 	if err != nil {
+		c.logger.LogAttrs(namespacedContext, slog.LevelDebug, "failed to get snapshot usage", slog.String("snapshotter", info.Snapshotter), slog.String("key", info.SnapshotKey), slog.String("err", err.Error()))
+		return -1
+	}
+	return usage.Size
+}
+
+// ctrToInfo inspects a container. If its infos cannot be retrieved, the inspection error is returned
+// along with the infos not depending on them.
+func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, container containerd.Container) (event.Info, error) {
+	info, inspectErr := container.Info(namespacedContext)
+	if inspectErr != nil {
 		info = containers.Container{}
 	}
 	spec, err := container.Spec(namespacedContext)
@@ -88,6 +155,11 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 		}
 	}
 
+	var pidsLimit int64
+	if spec.Linux != nil && spec.Linux.Resources != nil && spec.Linux.Resources.Pids != nil {
+		pidsLimit = max(spec.Linux.Resources.Pids.Limit, 0)
+	}
+
 	// Mounts related
 	mounts := make([]event.Mount, 0)
 	for _, m := range spec.Mounts {
@@ -107,6 +179,7 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 			Mode:        mode,
 			RW:          !readOnly,
 			Propagation: spec.Linux.RootfsPropagation,
+			Type:        m.Type,
 		})
 	}
 
@@ -115,14 +188,20 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 		hostIPC     = true
 		hostPID     = true
 		hostNetwork = true
+		userns      bool
+		netns       string
 	)
 	if spec.Linux != nil {
 		for _, ns := range spec.Linux.Namespaces {
+			if ns.Type == specs.UserNamespace {
+				userns = true
+			}
 			if ns.Type == specs.PIDNamespace {
 				hostPID = false
 			}
 			if ns.Type == specs.NetworkNamespace {
 				hostNetwork = false
+				netns = ns.Path
 			}
 			if ns.Type == specs.IPCNamespace {
 				hostIPC = false
@@ -136,18 +215,34 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 		imageDigest string
 		imageRepo   string
 		imageTag    string
-		imageSize   int64 = -1
+		imgSize     int64
+		imgLayers   int64
 	)
-	// TODO this is an extra API call; shall we move it behing config.GetWithSize()?
-	// Or rename `with_size` option with something more generic like `full_info`?
 	image, _ := container.Image(namespacedContext)
 	if image != nil {
 		imageDigest = image.Target().Digest.String()
+		// Computing the size walks the whole image content,
+		// thus it is only done when sizes are requested.
 		if config.GetWithSize() {
-			imageSize = image.Target().Size
+			imgSize, _ = image.Size(namespacedContext)
 		}
+		if diffIDs, err := image.RootFS(namespacedContext); err == nil {
+			imgLayers = int64(len(diffIDs))
+		}
+	}
+	imageRef := parseImageReference(info.Image)
+	imageRepo, imageTag = imageRef.repository, imageRef.tag
+	if imageDigest == "" {
+		imageDigest = imageRef.digest
+	}
+	imageDigest = resolveImageDigest(info.Image, imageDigest)
+
+	// Writable layer related: computing the usage might walk the whole
+	// snapshot, thus it is only done when sizes are requested.
+	var size int64 = -1
+	if config.GetWithSize() {
+		size = c.snapshotUsage(namespacedContext, info)
 	}
-	imageRepo, imageTag = parseImageRepoTag(info.Image)
 
 	// Network related - TODO
 
@@ -166,11 +261,19 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 	}
 
 	isPodSandbox := info.Labels["io.cri-containerd.kind"] == "sandbox"
+	// Workload containers join the network namespace of their sandbox
+	var podSandboxNetNS string
+	if isPodSandbox || info.SandboxID != "" {
+		podSandboxNetNS = netns
+	}
 
-	var podSandboxLabels map[string]string
+	var (
+		podSandboxLabels map[string]string
+		sandboxLabels    map[string]string
+	)
 	sandbox, _ := c.client.LoadSandbox(namespacedContext, info.SandboxID)
 	if sandbox != nil {
-		sandboxLabels, _ := sandbox.Labels(namespacedContext)
+		sandboxLabels, _ = sandbox.Labels(namespacedContext)
 		if len(sandboxLabels) > 0 {
 			podSandboxLabels = make(map[string]string)
 			for key, val := range sandboxLabels {
@@ -203,7 +306,29 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 		privileged = false
 	}
 
-	return event.Info{
+	var capEffective []string
+	if spec.Process != nil && spec.Process.Capabilities != nil {
+		capEffective = normalizeCaps(spec.Process.Capabilities.Effective)
+	}
+
+	// Seccomp profiles are only known by their rules in the OCI spec
+	secOpts := securityOpts{seccompProfile: seccompUnconfined}
+	if spec.Linux != nil && spec.Linux.Seccomp != nil {
+		secOpts.seccompProfile = seccompCustom
+	}
+	if spec.Process != nil {
+		secOpts.apparmorProfile = spec.Process.ApparmorProfile
+		secOpts.selinuxLabel = spec.Process.SelinuxLabel
+		secOpts.noNewPrivileges = spec.Process.NoNewPrivileges
+	}
+
+	var uidMappings, gidMappings []event.IDMapping
+	if userns {
+		uidMappings = ociIDMappings(spec.Linux.UIDMappings)
+		gidMappings = ociIDMappings(spec.Linux.GIDMappings)
+	}
+
+	evtInfo := event.Info{
 		Container: event.Container{
 			Type:             typeContainerd.ToCTValue(),
 			ID:               shortContainerID(container.ID()),
@@ -212,13 +337,18 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 			ImageDigest:      imageDigest,
 			ImageRepo:        imageRepo,
 			ImageTag:         imageTag,
+			ImageRegistry:    imageRef.registry,
+			ImageSize:        imgSize,
+			ImageLayers:      imgLayers,
 			User:             strconv.FormatUint(uint64(spec.Process.User.UID), 10),
+			UserIDs:          fmt.Sprintf("%d:%d", spec.Process.User.UID, spec.Process.User.GID),
 			CPUPeriod:        int64(cpuPeriod),
 			CPUQuota:         cpuQuota,
 			CPUShares:        int64(cpuShares),
 			CPUSetCPUCount:   cpusetCount,
 			CreatedTime:      info.CreatedAt.Unix(),
-			Env:              spec.Process.Env,
+			CreatedAt:        unixNano(info.CreatedAt),
+			Env:              filterEnv(spec.Process.Env),
 			FullID:           container.ID(),
 			HostIPC:          hostIPC,
 			HostNetwork:      hostNetwork,
@@ -228,17 +358,52 @@ func (c *containerdEngine) ctrToInfo(namespacedContext context.Context, containe
 			Labels:           labels,
 			MemoryLimit:      memoryLimit,
 			SwapLimit:        swapLimit,
+			PidsLimit:        pidsLimit,
 			PodSandboxID:     info.SandboxID,
+			PodSandboxNetNS:  podSandboxNetNS,
 			Privileged:       privileged,
+			Runtime:          info.Runtime.Name,
+			SandboxedRuntime: isSandboxedRuntime(info.Runtime.Name),
+			Isolation:        specIsolation(spec.Windows),
+			CapEffective:     capEffective,
+			SeccompProfile:   secOpts.seccompProfile,
+			AppArmorProfile:  secOpts.apparmorProfile,
+			SELinuxLabel:     secOpts.selinuxLabel,
+			NoNewPrivileges:  secOpts.noNewPrivileges,
+			UserNamespace:    userns,
+			UIDMappings:      uidMappings,
+			GIDMappings:      gidMappings,
 			PodSandboxLabels: podSandboxLabels,
 			Mounts:           mounts,
-			Size:             imageSize,
+			Size:             size,
+			Snapshotter:      info.Snapshotter,
 		},
 	}
+	if count, err := strconv.ParseInt(info.Labels[containerdRestartCountLabel], 10, 64); err == nil {
+		evtInfo.RestartCount = count
+	}
+	var gpus gpuDevices
+	gpus.addEnv(spec.Process.Env)
+	gpus.addCDIAnnotations(spec.Annotations)
+	if spec.Linux != nil {
+		for _, dev := range spec.Linux.Devices {
+			gpus.addDeviceNodes(dev.Path)
+		}
+	}
+	evtInfo.GPUDevices = gpus
+	if spec.Linux != nil {
+		evtInfo.CgroupPath, evtInfo.CgroupDriver = specCgroupPath(spec.Linux.CgroupsPath)
+	}
+	setK8sPodMetadata(&evtInfo.Container, info.Labels, sandboxLabels)
+	// Containers of nerdctl compose services
+	setComposeMetadata(&evtInfo.Container, info.Labels)
+	// Containers of nomad allocations, through the nomad containerd driver
+	setNomadMetadata(namespacedContext, &evtInfo.Container, info.Labels, spec.Process.Env)
+	return evtInfo, inspectErr
 }
 
 func (c *containerdEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
-	namespacesList, err := c.client.NamespaceService().List(ctx)
+	namespacesList, err := c.listNamespaces(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -246,8 +411,9 @@ func (c *containerdEngine) get(ctx context.Context, containerId string) (*event.
 		namespacedContext := namespaces.WithNamespace(ctx, namespace)
 		container, err := c.client.LoadContainer(namespacedContext, containerId)
 		if err == nil {
+			info, _ := c.ctrToInfo(namespacedContext, container)
 			return &event.Event{
-				Info:     c.ctrToInfo(namespacedContext, container),
+				Info:     info,
 				IsCreate: true,
 			}, nil
 		}
@@ -263,25 +429,52 @@ func (c *containerdEngine) Sock() string {
 	return c.socket
 }
 
+// List lists all containers, considering them already known by the listener.
 func (c *containerdEngine) List(ctx context.Context) ([]event.Event, error) {
-	namespacesList, err := c.client.NamespaceService().List(ctx)
+	evts, err := c.list(ctx, true)
+	if err == nil {
+		c.known.seed(evts)
+	}
+	return evts, err
+}
+
+// list lists all containers, serving their infos from the metadata cache if cached.
+func (c *containerdEngine) list(ctx context.Context, cached bool) ([]event.Event, error) {
+	namespacesList, err := c.listNamespaces(ctx)
 	if err != nil {
 		return nil, err
 	}
-	evts := make([]event.Event, 0)
+	var (
+		containersList      []containerd.Container
+		containersNamespace []string
+	)
 	for _, namespace := range namespacesList {
 		namespacedContext := namespaces.WithNamespace(ctx, namespace)
-		containersList, err := c.client.Containers(namespacedContext)
+		nsContainers, err := c.client.Containers(namespacedContext)
 		if err != nil {
 			continue
 		}
-		for _, container := range containersList {
-			evts = append(evts, event.Event{
-				Info:     c.ctrToInfo(namespacedContext, container),
-				IsCreate: true,
-			})
+		containersList = append(containersList, nsContainers...)
+		for range nsContainers {
+			containersNamespace = append(containersNamespace, namespace)
 		}
 	}
+	evts := make([]event.Event, len(containersList))
+	inspectAll(ctx, typeContainerd, len(containersList), func(ctx context.Context, idx int) {
+		if cached {
+			if info, ok := metadata.get(shortContainerID(containersList[idx].ID())); ok {
+				evts[idx] = event.Event{Info: info, IsCreate: true}
+				return
+			}
+		}
+		start := time.Now()
+		info, err := c.ctrToInfo(namespaces.WithNamespace(ctx, containersNamespace[idx]), containersList[idx])
+		countInspect(typeContainerd, err, start)
+		evts[idx] = event.Event{
+			Info:     info,
+			IsCreate: true,
+		}
+	})
 	return evts, nil
 }
 
@@ -299,71 +492,189 @@ func (c *containerdEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-ch
 	if config.IsHookEnabled(config.HookRemove) {
 		topics = append(topics, `topic=="/containers/delete"`)
 	}
+	if config.IsHookEnabled(config.HookDie) {
+		topics = append(topics, `topic=="/tasks/exit"`)
+	}
+	// OOM kills are also needed by the die hook, to flag OOM killed containers
+	if config.IsHookEnabled(config.HookDie) || config.IsHookEnabled(config.HookOOM) {
+		topics = append(topics, `topic=="/tasks/oom"`)
+	}
+	if config.IsHookEnabled(config.HookPause) {
+		topics = append(topics, `topic=="/tasks/paused"`, `topic=="/tasks/resumed"`)
+	}
+	// Image pulls are correlated with the containers created from the pulled images:
+	// pulling an image creates its record, or updates it when the image is pulled again.
+	if config.IsHookEnabled(config.HookCreate) || config.IsHookEnabled(config.HookStart) {
+		topics = append(topics, `topic=="/images/create"`, `topic=="/images/update"`)
+	}
 
-	eventsCh, _ := eventsClient.Subscribe(ctx, topics...)
+	eventsCh, errCh := eventsClient.Subscribe(ctx, topics...)
+	enr := newEnricher(typeContainerd, outCh)
+	exits := make(exitTracker)
 	wg.Add(1)
 	go func() {
 		defer close(outCh)
 		defer wg.Done()
+		defer enr.wait()
+		bo := newBackoff(reconnectMinBackoff, reconnectMaxBackoff)
+		ep := endpoint{engine: typeContainerd, socket: c.Sock()}
 		for {
-			select {
-			case <-ctx.Done():
+			err := supervise(ctx, c.logger, string(typeContainerd), func() error {
+				return c.processEvents(ctx, eventsCh, errCh, enr, exits, outCh)
+			})
+			// The events stream dropped (eg: daemon restart):
+			// reconnect and resync containers, since events might have been lost.
+			if err == nil || ctx.Err() != nil || !waitReconnect(ctx, c.logger, ep, bo, err) {
+				return
+			}
+			eventsCh, errCh = eventsClient.Subscribe(ctx, topics...)
+			if !resync(ctx, c.logger, ep, bo, uncached(c.list), c.known, outCh) {
 				return
-			case ev, ok := <-eventsCh:
-				if !ok {
-					// eventsCh has been closed - kill the goroutine
-					return
+			}
+		}
+	}()
+	return c.known.forward(ctx, wg, c.logger, uncached(c.list), outCh), nil
+}
+
+// processEvents notifies the events of eventsCh until ctx is done or the events stream drops,
+// returning the error that dropped it.
+func (c *containerdEngine) processEvents(ctx context.Context, eventsCh <-chan *eventtypes.Envelope, errCh <-chan error,
+	enr *enricher, exits exitTracker, outCh chan<- event.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return streamError(err)
+		case ev, ok := <-eventsCh:
+			if !ok {
+				// eventsCh has been closed - kill the goroutine
+				return nil
+			}
+			if ev == nil {
+				// Nothing to do for null event
+				break
+			}
+			if !c.isNamespaceEnabled(ev.Namespace) {
+				c.logger.LogAttrs(ctx, config.LevelTrace, "skipping event from disabled namespace", slog.String("namespace", ev.Namespace), slog.String("topic", ev.Topic))
+				break
+			}
+			var (
+				id       string
+				isCreate bool
+				image    string
+				taskExit *events.TaskExit
+				paused   bool
+				resumed  bool
+				oom      bool
+			)
+			switch ev.Topic {
+			case "/containers/create":
+				ctrCreate := events.ContainerCreate{}
+				_ = typeurl.UnmarshalTo(ev.Event, &ctrCreate)
+				id = ctrCreate.ID
+				isCreate = true
+				image = ctrCreate.Image
+			case "/tasks/start":
+				ctrStart := events.TaskStart{}
+				_ = typeurl.UnmarshalTo(ev.Event, &ctrStart)
+				id = ctrStart.ContainerID
+				isCreate = true
+			case "/containers/delete":
+				ctrDelete := events.ContainerDelete{}
+				_ = typeurl.UnmarshalTo(ev.Event, &ctrDelete)
+				id = ctrDelete.ID
+				isCreate = false
+			case "/tasks/exit":
+				taskExit = &events.TaskExit{}
+				_ = typeurl.UnmarshalTo(ev.Event, taskExit)
+				if taskExit.ID != taskExit.ContainerID {
+					// Exec'd process exit: the container is still running.
+					continue
 				}
-				if ev == nil {
-					// Nothing to do for null event
-					break
+				id = taskExit.ContainerID
+			case "/tasks/oom":
+				taskOOM := events.TaskOOM{}
+				_ = typeurl.UnmarshalTo(ev.Event, &taskOOM)
+				id = taskOOM.ContainerID
+				oom = true
+			case "/tasks/paused":
+				ctrPaused := events.TaskPaused{}
+				_ = typeurl.UnmarshalTo(ev.Event, &ctrPaused)
+				id = ctrPaused.ContainerID
+				paused = true
+			case "/tasks/resumed":
+				ctrResumed := events.TaskResumed{}
+				_ = typeurl.UnmarshalTo(ev.Event, &ctrResumed)
+				id = ctrResumed.ContainerID
+				resumed = true
+			case "/images/create":
+				imgCreate := events.ImageCreate{}
+				_ = typeurl.UnmarshalTo(ev.Event, &imgCreate)
+				c.logger.LogAttrs(ctx, config.LevelTrace, "image pull event", slog.String("image", imgCreate.Name))
+				imagePulls.pulled(imgCreate.Name, ev.Timestamp.UnixNano())
+				continue
+			case "/images/update":
+				imgUpdate := events.ImageUpdate{}
+				_ = typeurl.UnmarshalTo(ev.Event, &imgUpdate)
+				c.logger.LogAttrs(ctx, config.LevelTrace, "image pull event", slog.String("image", imgUpdate.Name))
+				imagePulls.pulled(imgUpdate.Name, ev.Timestamp.UnixNano())
+				continue
+			}
+			// minimum set of infos - either for containers/delete
+			// or for other hooks but with an error or a slow inspection.
+			minimal := event.Info{
+				Container: event.Container{
+					Type:   typeContainerd.ToCTValue(),
+					ID:     shortContainerID(id),
+					FullID: id,
+					Image:  image,
+				},
+			}
+			if paused {
+				outCh <- pausedEvent(minimal, ev.Timestamp.UnixNano())
+				break
+			}
+			if resumed {
+				outCh <- unpausedEvent(minimal)
+				break
+			}
+			if oom {
+				evt := exits.oom(minimal)
+				if config.IsHookEnabled(config.HookOOM) {
+					outCh <- evt
 				}
-				var (
-					id       string
-					isCreate bool
-					image    string
-					info     event.Info
-				)
-				switch ev.Topic {
-				case "/containers/create":
-					ctrCreate := events.ContainerCreate{}
-					_ = typeurl.UnmarshalTo(ev.Event, &ctrCreate)
-					id = ctrCreate.ID
-					isCreate = true
-					image = ctrCreate.Image
-				case "/tasks/start":
-					ctrStart := events.TaskStart{}
-					_ = typeurl.UnmarshalTo(ev.Event, &ctrStart)
-					id = ctrStart.ContainerID
-					isCreate = true
-				case "/containers/delete":
-					ctrDelete := events.ContainerDelete{}
-					_ = typeurl.UnmarshalTo(ev.Event, &ctrDelete)
-					id = ctrDelete.ID
-					isCreate = false
+				break
+			}
+			if taskExit != nil {
+				outCh <- exits.died(minimal, int64(taskExit.ExitStatus), taskExit.ExitedAt.AsTime().UnixNano())
+				break
+			}
+			if !isCreate {
+				enr.forget(minimal.ID)
+				exits.removed(&minimal)
+				outCh <- event.Event{
+					Info:     minimal,
+					IsCreate: false,
 				}
-				namespacedContext := namespaces.WithNamespace(ctx, ev.Namespace)
+				break
+			}
+			namespace := ev.Namespace
+			// Restarted containers carry the exit status of their last run
+			last, restarted := exits.last(minimal.ID)
+			enr.enrich(ctx, minimal, func(ctx context.Context) (event.Info, error) {
+				namespacedContext := namespaces.WithNamespace(ctx, namespace)
 				container, err := c.client.LoadContainer(namespacedContext, id)
 				if err != nil {
-					// minimum set of infos - either for containers/delete
-					// or for other hooks but with an error.
-					info = event.Info{
-						Container: event.Container{
-							Type:   typeContainerd.ToCTValue(),
-							ID:     shortContainerID(id),
-							FullID: id,
-							Image:  image,
-						},
-					}
-				} else {
-					info = c.ctrToInfo(namespacedContext, container)
+					return event.Info{}, err
 				}
-				outCh <- event.Event{
-					Info:     info,
-					IsCreate: isCreate,
+				// Partial infos are sent anyway, as when listing
+				info, _ := c.ctrToInfo(namespacedContext, container)
+				if restarted {
+					last.attach(&info)
 				}
-			}
+				return info, nil
+			})
 		}
-	}()
-	return outCh, nil
+	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testContainerd(t *testing.T, withFetcher bool) {
@@ -25,19 +26,23 @@ func testContainerd(t *testing.T, withFetcher bool) {
 
 	const containerdSocket = "/run/containerd/containerd.sock"
 	client, err := containerd.New(containerdSocket)
+	if err == nil {
+		// The client connects lazily: make sure the daemon is reachable.
+		_, err = client.Version(context.Background())
+	}
 	if err != nil {
 		t.Skip("Socket "+containerdSocket+" mandatory to run containerd tests:", err.Error())
 	}
 
 	engine, err := newContainerdEngine(context.Background(), slog.Default(), containerdSocket)
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	namespacedCtx := namespaces.WithNamespace(context.Background(), "test_ns")
 
 	// Pull image
 	if _, err = client.GetImage(namespacedCtx, "docker.io/library/alpine:3.20.3"); err != nil {
 		_, err = client.Pull(namespacedCtx, "docker.io/library/alpine:3.20.3")
-		assert.NoError(t, err)
+		require.NoError(t, err)
 	}
 
 	id := uuid.New()
@@ -70,7 +75,11 @@ func testContainerd(t *testing.T, withFetcher bool) {
 				Path: "/proc/foo",
 			}),
 			oci.WithPrivileged))
-	assert.NoError(t, err)
+	require.NoError(t, err)
+	spec, err := ctr.Spec(namespacedCtx)
+	require.NoError(t, err)
+	ctrInfo, err := ctr.Info(namespacedCtx)
+	require.NoError(t, err)
 
 	expectedEvent := event.Event{
 		Info: event.Info{
@@ -81,6 +90,8 @@ func testContainerd(t *testing.T, withFetcher bool) {
 				Image:            "docker.io/library/alpine:3.20.3",
 				ImageRepo:        "docker.io/library/alpine",
 				ImageTag:         "3.20.3",
+				ImageRegistry:    "docker.io",
+				ImageLayers:      1,
 				ImageDigest:      "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
 				CPUPeriod:        defaultCpuPeriod,
 				CPUQuota:         cpuQuota,
@@ -94,9 +105,15 @@ func testContainerd(t *testing.T, withFetcher bool) {
 				Labels:           map[string]string{},
 				PodSandboxID:     "",
 				Privileged:       true,
+				Runtime:          ctrInfo.Runtime.Name,
+				CapEffective:     spec.Process.Capabilities.Effective,
+				SeccompProfile:   "unconfined",
+				AppArmorProfile:  spec.Process.ApparmorProfile,
+				SELinuxLabel:     spec.Process.SelinuxLabel,
 				PodSandboxLabels: nil,
 				Mounts:           []event.Mount{},
 				User:             "0",
+				UserIDs:          "0:0",
 				Size:             -1,
 			}},
 		IsCreate: true,
@@ -117,6 +134,7 @@ func testContainerd(t *testing.T, withFetcher bool) {
 			found = true
 			// We don't have these before creation
 			expectedEvent.CreatedTime = evt.CreatedTime
+			expectedEvent.CreatedAt = evt.CreatedAt
 			expectedEvent.Ip = evt.Ip
 			assert.Equal(t, expectedEvent, evt)
 		}
@@ -132,7 +150,7 @@ func testContainerd(t *testing.T, withFetcher bool) {
 	})
 
 	listCh, err := engine.Listen(cancelCtx, &wg)
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	err = ctr.Delete(namespacedCtx)
 	assert.NoError(t, err)
@@ -155,3 +173,39 @@ func testContainerd(t *testing.T, withFetcher bool) {
 func TestContainerd(t *testing.T) {
 	testContainerd(t, false)
 }
+
+func TestContainerdNamespaces(t *testing.T) {
+	tCases := map[string]struct {
+		namespaces      map[string]struct{}
+		namespace       string
+		expectedEnabled bool
+	}{
+		"All namespaces": {
+			namespaces:      map[string]struct{}{},
+			namespace:       "k8s.io",
+			expectedEnabled: true,
+		},
+		"Configured namespace": {
+			namespaces:      map[string]struct{}{"k8s.io": {}, "moby": {}},
+			namespace:       "moby",
+			expectedEnabled: true,
+		},
+		"Not configured namespace": {
+			namespaces:      map[string]struct{}{"k8s.io": {}, "moby": {}},
+			namespace:       "default",
+			expectedEnabled: false,
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			engine := containerdEngine{namespaces: tc.namespaces}
+			assert.Equal(t, tc.expectedEnabled, engine.isNamespaceEnabled(tc.namespace))
+			if len(tc.namespaces) > 0 {
+				namespacesList, err := engine.listNamespaces(context.Background())
+				assert.NoError(t, err)
+				assert.Len(t, namespacesList, len(tc.namespaces))
+			}
+		})
+	}
+}
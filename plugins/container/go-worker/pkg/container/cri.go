@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/opencontainers/runtime-spec/specs-go"
 	internalapi "k8s.io/cri-api/pkg/apis"
 	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	remote "k8s.io/cri-client/pkg"
@@ -18,7 +21,17 @@ import (
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 )
 
-const maxCNILen = 4096
+const (
+	maxCNILen = 4096
+	// criOAnnotationsPrefix is the prefix of the CRI-O annotations reported in the container metadata,
+	// eg: io.kubernetes.cri-o.userns-mode, io.kubernetes.cri-o.ContainerType.
+	criOAnnotationsPrefix = "io.kubernetes."
+	// criOOMKilledReason is the reason reported by both containerd and CRI-O
+	// for exited containers killed by the OOM killer.
+	criOOMKilledReason = "OOMKilled"
+	// criDefaultNetwork names the pod network reported in the pod sandbox status.
+	criDefaultNetwork = "default"
+)
 
 func init() {
 	engineGenerators[typeCri] = newCriEngine
@@ -27,8 +40,13 @@ func init() {
 type criEngine struct {
 	logger  *slog.Logger
 	client  internalapi.RuntimeService
+	images  internalapi.ImageManagerService
 	runtime int // as CT_FOO value
-	socket  string
+	// runtimeName is the name of the runtime as reported by the CRI Version API, eg: containerd, cri-o.
+	runtimeName string
+	socket      string
+	// known tracks the containers notified by the engine, seeded by List.
+	known *knownContainers
 }
 
 // See https://github.com/falcosecurity/libs/blob/4d04cad02cd27e53cb18f431361a4d031836bb75/userspace/libsinsp/cri.hpp#L71
@@ -49,22 +67,41 @@ func newCriEngine(ctx context.Context, logger *slog.Logger, socket string) (Engi
 	if err != nil {
 		return nil, err
 	}
+	images, err := remote.NewRemoteImageService(socket, 5*time.Second, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 	return &criEngine{
-		logger:  logger,
-		client:  client,
-		runtime: getRuntime(version.RuntimeName),
-		socket:  socket,
+		logger:      logger,
+		client:      client,
+		images:      images,
+		runtime:     getRuntime(version.RuntimeName),
+		runtimeName: version.RuntimeName,
+		socket:      socket,
+		known:       newKnownContainers(),
 	}, nil
 }
 
+// runtimeID returns the runtime-native ID of a container, ie: its ID prefixed by the runtime name,
+// the way kubelet reports it in the pod status (eg: containerd://<full id>).
+func (c *criEngine) runtimeID(id string) string {
+	if id == "" || c.runtimeName == "" {
+		return ""
+	}
+	return c.runtimeName + "://" + id
+}
+
 func (c *criEngine) copy(ctx context.Context) (Engine, error) {
 	return newCriEngine(ctx, c.logger, c.socket)
 }
 
 // Structures that maps container.Info() map
 type criInfo struct {
+	Pid        int   `json:"pid"`
 	Privileged *bool `json:"privileged"`
-	Config     *struct {
+	// Runtime of the container shim, eg: io.containerd.runc.v2; containerd only
+	RuntimeType string `json:"runtimeType"`
+	Config      *struct {
 		Image *struct {
 			Image string `json:"image"`
 		} `json:"image"`
@@ -72,22 +109,71 @@ type criInfo struct {
 			Key   string `json:"key"`
 			Value string `json:"value"`
 		} `json:"envs"`
+		CDIDevices []struct {
+			Name string `json:"name"`
+		} `json:"CDI_devices"`
 		Linux *struct {
 			SecurityContext *struct {
-				Privileged *bool `json:"privileged"`
+				Privileged   *bool            `json:"privileged"`
+				Capabilities *criCapabilities `json:"capabilities"`
+				Seccomp      *struct {
+					ProfileType  v1.SecurityProfile_ProfileType `json:"profile_type"`
+					LocalhostRef string                         `json:"localhost_ref"`
+				} `json:"seccomp"`
 			} `json:"security_context"`
 		} `json:"linux"`
 	} `json:"config"`
 	RuntimeSpec *struct {
 		Annotations map[string]string `json:"annotations"`
-		Linux       *struct {
+		Process     *struct {
+			Capabilities *struct {
+				Effective []string `json:"effective"`
+			} `json:"capabilities"`
+			ApparmorProfile string `json:"apparmorProfile"`
+			SelinuxLabel    string `json:"selinuxLabel"`
+			NoNewPrivileges bool   `json:"noNewPrivileges"`
+		} `json:"process"`
+		Linux *struct {
 			SecurityContext *struct {
 				Privileged *bool `json:"privileged"`
 			} `json:"security_context"`
+			Seccomp    json.RawMessage `json:"seccomp"`
+			Namespaces []struct {
+				Type string `json:"type"`
+			} `json:"namespaces"`
+			UIDMappings []event.IDMapping `json:"uidMappings"`
+			GIDMappings []event.IDMapping `json:"gidMappings"`
+			Devices     []struct {
+				Path string `json:"path"`
+			} `json:"devices"`
+			CgroupsPath string `json:"cgroupsPath"`
 		} `json:"linux"`
+		Windows *specs.Windows `json:"windows"`
 	} `json:"runtimeSpec"`
 }
 
+// criCapabilities maps the capabilities requested in the container config
+type criCapabilities struct {
+	AddCapabilities  []string `json:"add_capabilities"`
+	DropCapabilities []string `json:"drop_capabilities"`
+}
+
+// Structure that maps the verbose image status "info" entry
+type criImageInfo struct {
+	ImageSpec *struct {
+		RootFS *struct {
+			DiffIDs []string `json:"diff_ids"`
+		} `json:"rootfs"`
+	} `json:"imageSpec"`
+}
+
+func (info *criImageInfo) getLayers() int64 {
+	if info.ImageSpec != nil && info.ImageSpec.RootFS != nil {
+		return int64(len(info.ImageSpec.RootFS.DiffIDs))
+	}
+	return 0
+}
+
 func (info *criInfo) getPrivileged() bool {
 	if info.RuntimeSpec != nil &&
 		info.RuntimeSpec.Linux != nil &&
@@ -110,6 +196,91 @@ func (info *criInfo) getPrivileged() bool {
 	return false
 }
 
+func (info *criInfo) getCapabilities() criCapabilities {
+	if info.Config != nil &&
+		info.Config.Linux != nil &&
+		info.Config.Linux.SecurityContext != nil &&
+		info.Config.Linux.SecurityContext.Capabilities != nil {
+		return *info.Config.Linux.SecurityContext.Capabilities
+	}
+	return criCapabilities{}
+}
+
+func (info *criInfo) getEffectiveCapabilities() []string {
+	if info.RuntimeSpec != nil &&
+		info.RuntimeSpec.Process != nil &&
+		info.RuntimeSpec.Process.Capabilities != nil {
+		return info.RuntimeSpec.Process.Capabilities.Effective
+	}
+	return nil
+}
+
+func (info *criInfo) getSecurityOpts() securityOpts {
+	var secOpts securityOpts
+	if info.RuntimeSpec != nil && info.RuntimeSpec.Process != nil {
+		secOpts.apparmorProfile = info.RuntimeSpec.Process.ApparmorProfile
+		secOpts.selinuxLabel = info.RuntimeSpec.Process.SelinuxLabel
+		secOpts.noNewPrivileges = info.RuntimeSpec.Process.NoNewPrivileges
+	}
+
+	// Prefer the profile requested in the config, that has a name.
+	if info.Config != nil &&
+		info.Config.Linux != nil &&
+		info.Config.Linux.SecurityContext != nil &&
+		info.Config.Linux.SecurityContext.Seccomp != nil {
+		seccomp := info.Config.Linux.SecurityContext.Seccomp
+		switch seccomp.ProfileType {
+		case v1.SecurityProfile_RuntimeDefault:
+			secOpts.seccompProfile = seccompRuntimeDefault
+		case v1.SecurityProfile_Unconfined:
+			secOpts.seccompProfile = seccompUnconfined
+		case v1.SecurityProfile_Localhost:
+			secOpts.seccompProfile = "localhost/" + strings.TrimPrefix(seccomp.LocalhostRef, "/")
+		}
+		return secOpts
+	}
+	if info.RuntimeSpec != nil && info.RuntimeSpec.Linux != nil {
+		if len(info.RuntimeSpec.Linux.Seccomp) == 0 || string(info.RuntimeSpec.Linux.Seccomp) == "null" {
+			secOpts.seccompProfile = seccompUnconfined
+		} else {
+			secOpts.seccompProfile = seccompCustom
+		}
+	}
+	return secOpts
+}
+
+// hasNamespace returns whether the runtime spec lists a namespace of the provided type;
+// missing namespaces are shared with the host.
+func (info *criInfo) hasNamespace(nsType string) bool {
+	if info.RuntimeSpec == nil || info.RuntimeSpec.Linux == nil {
+		return false
+	}
+	for _, ns := range info.RuntimeSpec.Linux.Namespaces {
+		if ns.Type == nsType {
+			return true
+		}
+	}
+	return false
+}
+
+// getHostNamespaces returns whether the container shares the host ipc, network and pid namespaces,
+// from its runtime spec; ok is false when the runtime spec is not available.
+func (info *criInfo) getHostNamespaces() (hostIPC, hostNetwork, hostPID, ok bool) {
+	if info.RuntimeSpec == nil || info.RuntimeSpec.Linux == nil || len(info.RuntimeSpec.Linux.Namespaces) == 0 {
+		return false, false, false, false
+	}
+	return !info.hasNamespace("ipc"), !info.hasNamespace("network"), !info.hasNamespace("pid"), true
+}
+
+// getUserNamespace returns whether the container runs in its own user namespace,
+// and its uid and gid mappings.
+func (info *criInfo) getUserNamespace() (bool, []event.IDMapping, []event.IDMapping) {
+	if !info.hasNamespace("user") {
+		return false, nil, nil
+	}
+	return true, info.RuntimeSpec.Linux.UIDMappings, info.RuntimeSpec.Linux.GIDMappings
+}
+
 func (info *criInfo) getEnvs() []string {
 	var env []string
 
@@ -130,6 +301,57 @@ func (info *criInfo) getAnnotation(key string) (string, bool) {
 	return "", false
 }
 
+// getAnnotations returns the runtime spec annotations matching the provided prefix,
+// skipping values longer than the configured label max length.
+func (info *criInfo) getAnnotations(prefix string) map[string]string {
+	annotations := make(map[string]string)
+	if info.RuntimeSpec != nil {
+		for key, val := range info.RuntimeSpec.Annotations {
+			if strings.HasPrefix(key, prefix) && len(val) <= config.GetLabelMaxLen() {
+				annotations[key] = val
+			}
+		}
+	}
+	return annotations
+}
+
+// getGPUDevices returns the GPU devices requested through the container config envs and CDI devices,
+// and the ones found among the runtime spec annotations and devices.
+func (info *criInfo) getGPUDevices() []string {
+	var gpus gpuDevices
+	gpus.addEnv(info.getEnvs())
+	if info.Config != nil {
+		for _, dev := range info.Config.CDIDevices {
+			gpus.addCDI(dev.Name)
+		}
+	}
+	if info.RuntimeSpec != nil {
+		gpus.addCDIAnnotations(info.RuntimeSpec.Annotations)
+		if info.RuntimeSpec.Linux != nil {
+			for _, dev := range info.RuntimeSpec.Linux.Devices {
+				gpus.addDeviceNodes(dev.Path)
+			}
+		}
+	}
+	return gpus
+}
+
+// getCgroupsPath returns the cgroupsPath of the runtime spec.
+func (info *criInfo) getCgroupsPath() string {
+	if info.RuntimeSpec != nil && info.RuntimeSpec.Linux != nil {
+		return info.RuntimeSpec.Linux.CgroupsPath
+	}
+	return ""
+}
+
+// getIsolation returns the isolation mode of windows containers, from the runtime spec.
+func (info *criInfo) getIsolation() string {
+	if info.RuntimeSpec != nil {
+		return specIsolation(info.RuntimeSpec.Windows)
+	}
+	return ""
+}
+
 func (info *criInfo) getImage() string {
 	if info.Config != nil &&
 		info.Config.Image != nil {
@@ -150,9 +372,61 @@ type cniSandboxInfo struct {
 	} `json:"cniResult"`
 	RuntimeSpec *struct {
 		Annotations map[string]string `json:"annotations"`
+		Linux       *struct {
+			Namespaces []struct {
+				Type string `json:"type"`
+				Path string `json:"path"`
+			} `json:"namespaces"`
+		} `json:"linux"`
 	} `json:"runtimeSpec"`
 }
 
+// getNetNS returns the path of the sandbox network namespace, if any.
+func (info *cniSandboxInfo) getNetNS() string {
+	if info.RuntimeSpec == nil || info.RuntimeSpec.Linux == nil {
+		return ""
+	}
+	for _, ns := range info.RuntimeSpec.Linux.Namespaces {
+		if ns.Type == "network" {
+			return ns.Path
+		}
+	}
+	return ""
+}
+
+// criStatusNetworks returns the default network of a pod sandbox, from its status,
+// used when the runtime does not report the results of its CNI plugins.
+func criStatusNetworks(status *v1.PodSandboxNetworkStatus) []event.Network {
+	network := event.Network{Name: criDefaultNetwork}
+	setNetworkIP(&network, net.ParseIP(status.GetIp()), nil)
+	for _, additional := range status.GetAdditionalIps() {
+		setNetworkIP(&network, net.ParseIP(additional.GetIp()), nil)
+	}
+	if network.IP == "" && network.IPv6 == "" {
+		return nil
+	}
+	return []event.Network{network}
+}
+
+// imageStats returns the size and the number of layers of image, as reported by the runtime image service.
+func (c *criEngine) imageStats(ctx context.Context, image string) (int64, int64) {
+	if image == "" {
+		return 0, 0
+	}
+	status, err := c.images.ImageStatus(ctx, &v1.ImageSpec{Image: image}, true)
+	if err != nil || status.GetImage() == nil {
+		return 0, 0
+	}
+	var (
+		layers    int64
+		imageInfo criImageInfo
+	)
+	if err = json.Unmarshal([]byte(status.GetInfo()["info"]), &imageInfo); err == nil {
+		layers = imageInfo.getLayers()
+	}
+	return int64(status.GetImage().GetSize()), layers
+}
+
 func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podSandboxStatus *v1.PodSandboxStatus,
 	info map[string]string, sandboxInfo map[string]string) event.Info {
 
@@ -206,6 +480,8 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 			Destination: m.ContainerPath,
 			RW:          !m.Readonly,
 			Propagation: propagation,
+			// CRI mounts are always bind mounts of host paths
+			Type: "bind",
 		})
 	}
 
@@ -225,8 +501,13 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 
 	var cniJson string
 	var cniInfo cniSandboxInfo
+	var networks []event.Network
 	jsonInfo, present = sandboxInfo["info"]
 	if present {
+		var sandboxCNI containerdCNIResult
+		if err := json.Unmarshal([]byte(jsonInfo), &sandboxCNI); err == nil {
+			networks = sandboxCNI.networks()
+		}
 		err := json.Unmarshal([]byte(jsonInfo), &cniInfo)
 		if err == nil {
 			if cniInfo.CNIResult != nil && cniInfo.CNIResult.Interfaces != nil {
@@ -243,6 +524,7 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 			} else if cniInfo.RuntimeSpec != nil {
 				if val, ok := cniInfo.RuntimeSpec.Annotations["io.kubernetes.cri-o.CNIResult"]; ok {
 					cniJson = val
+					networks = parseCNIResultNetworks(val)
 				}
 			}
 
@@ -324,7 +606,12 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 		}
 	}
 
-	imageRepo, imageTag = parseImageRepoTag(imageName)
+	parsedImageRef := parseImageReference(imageName)
+	imageRepo, imageTag = parsedImageRef.repository, parsedImageRef.tag
+	if imageDigest == "" {
+		imageDigest = parsedImageRef.digest
+	}
+	imageDigest = resolveImageDigest(imageName, imageDigest)
 
 	if getTagFromImage {
 		_, tag := parseImageRepoTag(ctr.GetImage().GetImage())
@@ -340,6 +627,28 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 		imageID = ctr.GetImageId()
 	}
 
+	imageSize, imageLayers := c.imageStats(ctx, imageRef)
+	secOpts := ctrInfo.getSecurityOpts()
+	userns, uidMappings, gidMappings := ctrInfo.getUserNamespace()
+
+	// Fall back at the runtime handler of the pod, ie: of its runtime class, eg: "kata".
+	runtime := ctrInfo.RuntimeType
+	if runtime == "" {
+		runtime = podSandboxStatus.GetRuntimeHandler()
+	}
+
+	var annotations map[string]string
+	if c.runtime == typeCrio.ToCTValue() {
+		annotations = ctrInfo.getAnnotations(criOAnnotationsPrefix)
+	}
+	// Annotations of the container and of its pod matching the include patterns, eg: of service meshes
+	if included := includedAnnotations(ctr.GetAnnotations()); len(included) > 0 {
+		if annotations == nil {
+			annotations = make(map[string]string, len(included))
+		}
+		maps.Copy(annotations, included)
+	}
+
 	evtInfo := event.Info{
 		Container: event.Container{
 			Type:             c.runtime,
@@ -350,35 +659,89 @@ func (c *criEngine) ctrToInfo(ctx context.Context, ctr *v1.ContainerStatus, podS
 			ImageID:          imageID,
 			ImageRepo:        imageRepo,
 			ImageTag:         imageTag,
+			ImageRegistry:    parsedImageRef.registry,
+			ImageSize:        imageSize,
+			ImageLayers:      imageLayers,
 			User:             strconv.FormatInt(ctr.GetUser().GetLinux().GetUid(), 10),
+			UserIDs:          fmt.Sprintf("%d:%d", ctr.GetUser().GetLinux().GetUid(), ctr.GetUser().GetLinux().GetGid()),
 			CniJson:          cniJson,
 			CPUPeriod:        cpuPeriod,
 			CPUQuota:         cpuQuota,
 			CPUShares:        cpuShares,
 			CPUSetCPUCount:   cpusetCount,
 			CreatedTime:      nanoSecondsToUnix(ctr.CreatedAt),
-			Env:              ctrInfo.getEnvs(),
+			CreatedAt:        ctr.GetCreatedAt(),
+			StartedAt:        ctr.GetStartedAt(),
+			Env:              filterEnv(ctrInfo.getEnvs()),
 			FullID:           ctr.Id,
+			RuntimeID:        c.runtimeID(ctr.Id),
 			Labels:           labels,
 			MemoryLimit:      memoryLimit,
 			SwapLimit:        swapLimit,
 			PodSandboxID:     podSandboxID,
+			PodSandboxNetNS:  cniInfo.getNetNS(),
 			Privileged:       ctrInfo.getPrivileged(),
+			Runtime:          runtime,
+			SandboxedRuntime: isSandboxedRuntime(runtime),
+			Isolation:        ctrInfo.getIsolation(),
+			CapAdd:           normalizeCaps(ctrInfo.getCapabilities().AddCapabilities),
+			CapDrop:          normalizeCaps(ctrInfo.getCapabilities().DropCapabilities),
+			CapEffective:     normalizeCaps(ctrInfo.getEffectiveCapabilities()),
+			SeccompProfile:   secOpts.seccompProfile,
+			AppArmorProfile:  secOpts.apparmorProfile,
+			SELinuxLabel:     secOpts.selinuxLabel,
+			NoNewPrivileges:  secOpts.noNewPrivileges,
+			UserNamespace:    userns,
+			UIDMappings:      uidMappings,
+			GIDMappings:      gidMappings,
+			GPUDevices:       ctrInfo.getGPUDevices(),
 			PodSandboxLabels: podSandboxLabels,
+			Annotations:      annotations,
+			PodAnnotations:   includedAnnotations(podSandboxStatus.GetAnnotations()),
+			LogPath:          ctr.GetLogPath(),
 			Mounts:           mounts,
 			Size:             size,
+			// The kubelet bumps the attempt of a container each time it gets restarted.
+			RestartCount: int64(ctr.GetMetadata().GetAttempt()),
 		},
 	}
 
+	if ctr.GetState() == v1.ContainerState_CONTAINER_EXITED && ctr.GetFinishedAt() > 0 {
+		evtInfo.ExitCode = int64(ctr.GetExitCode())
+		evtInfo.FinishedAt = ctr.GetFinishedAt()
+		evtInfo.OOMKilled = ctr.GetReason() == criOOMKilledReason
+	}
+
 	if podSandboxStatus.Linux != nil && podSandboxStatus.Linux.Namespaces != nil &&
 		podSandboxStatus.Linux.Namespaces.Options != nil {
 		evtInfo.HostIPC = podSandboxStatus.Linux.Namespaces.Options.Ipc == v1.NamespaceMode_NODE
 		evtInfo.HostNetwork = podSandboxStatus.Linux.Namespaces.Options.Network == v1.NamespaceMode_NODE
 		evtInfo.HostPID = podSandboxStatus.Linux.Namespaces.Options.Pid == v1.NamespaceMode_NODE
+	} else if hostIPC, hostNetwork, hostPID, ok := ctrInfo.getHostNamespaces(); ok {
+		// Fall back at the namespaces of the container runtime spec
+		evtInfo.HostIPC = hostIPC
+		evtInfo.HostNetwork = hostNetwork
+		evtInfo.HostPID = hostPID
 	}
 
 	if podSandboxStatus.Network != nil {
 		evtInfo.Ip = podSandboxStatus.Network.Ip
+		if len(networks) == 0 {
+			networks = criStatusNetworks(podSandboxStatus.Network)
+		}
+	}
+	evtInfo.Networks = networks
+	fillProcCgroupLimits(&evtInfo.Container, ctrInfo.Pid)
+	fillCgroupPath(&evtInfo.Container, ctrInfo.getCgroupsPath(), ctrInfo.Pid)
+
+	setK8sPodMetadata(&evtInfo.Container, ctr.Labels, podSandboxStatus.Labels)
+	if podSandboxStatus.Metadata != nil {
+		evtInfo.PodName = podSandboxStatus.Metadata.Name
+		evtInfo.PodNamespace = podSandboxStatus.Metadata.Namespace
+		evtInfo.PodUID = podSandboxStatus.Metadata.Uid
+	}
+	if evtInfo.K8sContainerName == "" {
+		evtInfo.K8sContainerName = ctr.GetMetadata().GetName()
 	}
 
 	return evtInfo
@@ -392,7 +755,8 @@ func (c *criEngine) get(ctx context.Context, containerId string) (*event.Event,
 	ctr := ctrs[0]
 	container, err := c.client.ContainerStatus(ctx, ctr.Id, true)
 	if err == nil {
-		podSandboxStatus, _ := c.client.PodSandboxStatus(ctx, ctr.GetPodSandboxId(), false)
+		// verbose true to return the sandbox network infos
+		podSandboxStatus, _ := c.client.PodSandboxStatus(ctx, ctr.GetPodSandboxId(), true)
 		if podSandboxStatus == nil {
 			podSandboxStatus = &v1.PodSandboxStatusResponse{}
 		}
@@ -412,31 +776,56 @@ func (c *criEngine) Sock() string {
 	return c.socket
 }
 
+// List lists all containers, considering them already known by the listener.
 func (c *criEngine) List(ctx context.Context) ([]event.Event, error) {
+	evts, err := c.list(ctx, true)
+	if err == nil {
+		c.known.seed(evts)
+	}
+	return evts, err
+}
+
+// list lists all containers, serving their infos from the metadata cache if cached.
+func (c *criEngine) list(ctx context.Context, cached bool) ([]event.Event, error) {
 	ctrs, err := c.client.ListContainers(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	evts := make([]event.Event, len(ctrs))
-	for idx, ctr := range ctrs {
+	inspectAll(ctx, typeCri, len(ctrs), func(ctx context.Context, idx int) {
+		ctr := ctrs[idx]
+		if cached {
+			if info, ok := metadata.get(shortContainerID(ctr.Id)); ok {
+				evts[idx] = event.Event{Info: info, IsCreate: true}
+				return
+			}
+		}
 		// verbose true to return container.Info
+		start := time.Now()
 		container, err := c.client.ContainerStatus(ctx, ctr.Id, true)
-		if err != nil || container.Status == nil {
+		if err == nil && container.Status == nil {
+			err = fmt.Errorf("no status for container %s", ctr.Id)
+		}
+		countInspect(typeCri, err, start)
+		if err != nil {
 			evts[idx] = event.Event{
-				IsCreate: true,
+				IsCreate:  true,
+				IsPartial: true,
 				Info: event.Info{
 					Container: event.Container{
 						Type:        c.runtime,
 						ID:          shortContainerID(ctr.Id),
 						FullID:      ctr.Id,
+						RuntimeID:   c.runtimeID(ctr.Id),
 						ImageID:     ctr.ImageId,
 						CreatedTime: nanoSecondsToUnix(ctr.CreatedAt),
+						CreatedAt:   ctr.CreatedAt,
 						Labels:      ctr.Labels,
 					},
 				},
 			}
 		} else {
-			podSandboxStatus, _ := c.client.PodSandboxStatus(ctx, ctr.GetPodSandboxId(), false)
+			podSandboxStatus, _ := c.client.PodSandboxStatus(ctx, ctr.GetPodSandboxId(), true)
 			if podSandboxStatus == nil {
 				podSandboxStatus = &v1.PodSandboxStatusResponse{}
 			}
@@ -445,7 +834,7 @@ func (c *criEngine) List(ctx context.Context) ([]event.Event, error) {
 				Info:     c.ctrToInfo(ctx, container.Status, podSandboxStatus.GetStatus(), container.GetInfo(), podSandboxStatus.GetInfo()),
 			}
 		}
-	}
+	})
 	return evts, nil
 }
 
@@ -473,85 +862,117 @@ func (c *criEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan even
 	}
 
 	outCh := make(chan event.Event)
+	enr := newEnricher(typeCri, outCh)
 	wg.Add(1)
 	go func() {
 		defer close(outCh)
 		defer wg.Done()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case _, ok := <-containerEventsErrorCh:
-				if !ok {
-					// containerEventsErrorCh has been closed - block further reads from channel
-					containerEventsErrorCh = nil
-				}
-			case evt, ok := <-containerEventsCh:
-				if !ok {
-					// containerEventsCh has been closed - kill the goroutine.
-					// It happens only if the producer goroutine leaves because of errors.
-					return
+		defer enr.wait()
+		supervise(ctx, c.logger, string(typeCri), func() error {
+			return c.processEvents(ctx, containerEventsCh, containerEventsErrorCh, enr, outCh)
+		})
+	}()
+	if config.GetReconcileInterval() > 0 {
+		// Periodically reconcile the known containers, since events might be missed under load.
+		return c.known.forward(ctx, wg, c.logger, uncached(c.list), outCh), nil
+	}
+	return outCh, nil
+}
+
+// processEvents notifies the events of containerEventsCh until ctx is done or containerEventsCh gets closed.
+func (c *criEngine) processEvents(ctx context.Context, containerEventsCh <-chan *v1.ContainerEventResponse,
+	containerEventsErrorCh <-chan error, enr *enricher, outCh chan<- event.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-containerEventsErrorCh:
+			if !ok {
+				// containerEventsErrorCh has been closed - block further reads from channel
+				containerEventsErrorCh = nil
+			}
+		case evt, ok := <-containerEventsCh:
+			if !ok {
+				// containerEventsCh has been closed - kill the goroutine.
+				// It happens only if the producer goroutine leaves because of errors.
+				return nil
+			}
+			if evt == nil {
+				// Nothing to do for nil event
+				break
+			}
+			switch evt.ContainerEventType {
+			case v1.ContainerEventType_CONTAINER_CREATED_EVENT:
+				if !config.IsHookEnabled(config.HookCreate) {
+					// Skip
+					c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container created event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
+					continue
 				}
-				if evt == nil {
-					// Nothing to do for nil event
-					break
+			case v1.ContainerEventType_CONTAINER_STARTED_EVENT:
+				if !config.IsHookEnabled(config.HookStart) {
+					// Skip
+					c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container started event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
+					continue
 				}
-				switch evt.ContainerEventType {
-				case v1.ContainerEventType_CONTAINER_CREATED_EVENT:
-					if !config.IsHookEnabled(config.HookCreate) {
-						// Skip
-						c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container created event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
-						continue
-					}
-				case v1.ContainerEventType_CONTAINER_STARTED_EVENT:
-					if !config.IsHookEnabled(config.HookStart) {
-						// Skip
-						c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container started event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
-						continue
-					}
-				case v1.ContainerEventType_CONTAINER_DELETED_EVENT:
-					if !config.IsHookEnabled(config.HookRemove) {
-						// Skip
-						c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container deleted event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
-						continue
-					}
-				default:
-					// Unhandled event type
-					c.logger.LogAttrs(ctx, config.LevelTrace, "unhandled event type", slog.String("event_type", evt.ContainerEventType.String()))
+			case v1.ContainerEventType_CONTAINER_DELETED_EVENT:
+				if !config.IsHookEnabled(config.HookRemove) {
+					// Skip
+					c.logger.LogAttrs(ctx, config.LevelTrace, "skipping container deleted event because hook is disabled by configuration", slog.String("container_id", evt.ContainerId))
 					continue
 				}
-				c.logger.LogAttrs(ctx, config.LevelTrace, "sending container event", slog.String("container_id", evt.ContainerId), slog.String("event_type", evt.ContainerEventType.String()))
-				c.sendAsyncEvent(ctx, evt, outCh)
+			default:
+				// Unhandled event type
+				c.logger.LogAttrs(ctx, config.LevelTrace, "unhandled event type", slog.String("event_type", evt.ContainerEventType.String()))
+				continue
 			}
+			c.logger.LogAttrs(ctx, config.LevelTrace, "sending container event", slog.String("container_id", evt.ContainerId), slog.String("event_type", evt.ContainerEventType.String()))
+			c.sendAsyncEvent(ctx, evt, outCh, enr)
 		}
-	}()
-	return outCh, nil
+	}
 }
 
-func (c *criEngine) sendAsyncEvent(ctx context.Context, evt *v1.ContainerEventResponse, outCh chan<- event.Event) {
-	var info event.Info
-	returnInfo := true
-	ctr, err := c.client.ContainerStatus(ctx, evt.ContainerId, returnInfo)
-	if err != nil || ctr == nil {
-		info = event.Info{
-			Container: event.Container{
-				Type:         c.runtime,
-				ID:           shortContainerID(evt.ContainerId),
-				FullID:       evt.ContainerId,
-				CreatedTime:  nanoSecondsToUnix(evt.CreatedAt),
-				IsPodSandbox: true,
-			},
+func (c *criEngine) sendAsyncEvent(ctx context.Context, evt *v1.ContainerEventResponse, outCh chan<- event.Event, enr *enricher) {
+	// Sandbox events carry the sandbox ID as container ID
+	isPodSandbox := evt.ContainerId == evt.GetPodSandboxStatus().GetId()
+	minimal := event.Info{
+		Container: event.Container{
+			Type:         c.runtime,
+			ID:           shortContainerID(evt.ContainerId),
+			FullID:       evt.ContainerId,
+			RuntimeID:    c.runtimeID(evt.ContainerId),
+			CreatedTime:  nanoSecondsToUnix(evt.CreatedAt),
+			CreatedAt:    evt.CreatedAt,
+			IsPodSandbox: isPodSandbox,
+		},
+	}
+	inspect := func(ctx context.Context) (event.Info, error) {
+		returnInfo := true
+		ctr, err := c.client.ContainerStatus(ctx, evt.ContainerId, returnInfo)
+		if err != nil {
+			return event.Info{}, err
+		}
+		if ctr == nil {
+			return event.Info{}, fmt.Errorf("no status for container %s", evt.ContainerId)
 		}
-	} else {
 		cPodSandbox := evt.GetPodSandboxStatus()
 		podSandboxStatus, _ := c.client.PodSandboxStatus(ctx, cPodSandbox.GetId(), returnInfo)
 		if podSandboxStatus == nil {
 			podSandboxStatus = &v1.PodSandboxStatusResponse{}
 		}
-		info = c.ctrToInfo(ctx, ctr.GetStatus(), cPodSandbox, ctr.GetInfo(), podSandboxStatus.GetInfo())
+		return c.ctrToInfo(ctx, ctr.GetStatus(), cPodSandbox, ctr.GetInfo(), podSandboxStatus.GetInfo()), nil
+	}
+
+	if evt.ContainerEventType != v1.ContainerEventType_CONTAINER_DELETED_EVENT {
+		enr.enrich(ctx, minimal, inspect)
+		return
+	}
+	enr.forget(minimal.ID)
+	info, err := inspect(ctx)
+	if err != nil {
+		info = minimal
 	}
 	outCh <- event.Event{
 		Info:     info,
-		IsCreate: evt.ContainerEventType != v1.ContainerEventType_CONTAINER_DELETED_EVENT,
+		IsCreate: false,
 	}
 }
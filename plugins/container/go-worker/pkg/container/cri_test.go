@@ -52,7 +52,12 @@ func TestCRIInfoMap(t *testing.T) {
 	  "cpu_quota": 2000,
 	  "cpuset_cpus": "1-3"
 	},
-	"security_context": {}
+	"security_context": {
+	  "capabilities": {
+		"add_capabilities": ["NET_ADMIN"],
+		"drop_capabilities": ["CAP_SYS_CHROOT"]
+	  }
+	}
   }
 },
 "runtimeSpec": {
@@ -204,6 +209,132 @@ func TestCRIInfoMap(t *testing.T) {
 	var ctrInfo criInfo
 	err := json.Unmarshal([]byte(jsonInfo), &ctrInfo)
 	assert.NoError(t, err)
+
+	annotations := ctrInfo.getAnnotations(criOAnnotationsPrefix)
+	assert.Len(t, annotations, 7)
+	assert.Equal(t, "default", annotations["io.kubernetes.cri.sandbox-namespace"])
+	assert.Empty(t, ctrInfo.getAnnotations("io.kubernetes.cri-o."))
+
+	assert.Equal(t, criCapabilities{
+		AddCapabilities:  []string{"NET_ADMIN"},
+		DropCapabilities: []string{"CAP_SYS_CHROOT"},
+	}, ctrInfo.getCapabilities())
+	assert.Len(t, ctrInfo.getEffectiveCapabilities(), 14)
+	assert.Contains(t, ctrInfo.getEffectiveCapabilities(), "CAP_NET_RAW")
+
+	assert.Equal(t, securityOpts{
+		seccompProfile:  seccompUnconfined,
+		apparmorProfile: "cri-containerd.apparmor.d",
+	}, ctrInfo.getSecurityOpts())
+}
+
+func TestCRISecurityOpts(t *testing.T) {
+	tCases := map[string]struct {
+		jsonInfo        string
+		expectedSecOpts securityOpts
+	}{
+		"empty": {
+			jsonInfo: `{}`,
+		},
+		"runtime default from config": {
+			jsonInfo:        `{"config": {"linux": {"security_context": {"seccomp": {}}}}, "runtimeSpec": {"linux": {"seccomp": {"defaultAction": "SCMP_ACT_ERRNO"}}}}`,
+			expectedSecOpts: securityOpts{seccompProfile: seccompRuntimeDefault},
+		},
+		"localhost from config": {
+			jsonInfo:        `{"config": {"linux": {"security_context": {"seccomp": {"profile_type": 2, "localhost_ref": "profiles/audit.json"}}}}}`,
+			expectedSecOpts: securityOpts{seccompProfile: "localhost/profiles/audit.json"},
+		},
+		"unconfined from config": {
+			jsonInfo:        `{"config": {"linux": {"security_context": {"seccomp": {"profile_type": 1}}}}}`,
+			expectedSecOpts: securityOpts{seccompProfile: seccompUnconfined},
+		},
+		"from runtime spec": {
+			jsonInfo: `{"runtimeSpec": {"process": {"apparmorProfile": "crio-default", "selinuxLabel": "system_u:system_r:container_t:s0:c1,c2", "noNewPrivileges": true},
+				"linux": {"seccomp": {"defaultAction": "SCMP_ACT_ERRNO"}}}}`,
+			expectedSecOpts: securityOpts{
+				seccompProfile:  seccompCustom,
+				apparmorProfile: "crio-default",
+				selinuxLabel:    "system_u:system_r:container_t:s0:c1,c2",
+				noNewPrivileges: true,
+			},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var ctrInfo criInfo
+			assert.NoError(t, json.Unmarshal([]byte(tc.jsonInfo), &ctrInfo))
+			assert.Equal(t, tc.expectedSecOpts, ctrInfo.getSecurityOpts())
+		})
+	}
+}
+
+func TestCRIHostNamespaces(t *testing.T) {
+	tCases := map[string]struct {
+		jsonInfo            string
+		expectedHostIPC     bool
+		expectedHostNetwork bool
+		expectedHostPID     bool
+		expectedOk          bool
+	}{
+		"empty": {
+			jsonInfo: `{}`,
+		},
+		"private namespaces": {
+			jsonInfo:   `{"runtimeSpec": {"linux": {"namespaces": [{"type": "pid"}, {"type": "ipc"}, {"type": "network", "path": "/proc/42/ns/net"}]}}}`,
+			expectedOk: true,
+		},
+		"host network and pid": {
+			jsonInfo:            `{"runtimeSpec": {"linux": {"namespaces": [{"type": "mount"}, {"type": "ipc"}]}}}`,
+			expectedHostNetwork: true,
+			expectedHostPID:     true,
+			expectedOk:          true,
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var ctrInfo criInfo
+			assert.NoError(t, json.Unmarshal([]byte(tc.jsonInfo), &ctrInfo))
+			hostIPC, hostNetwork, hostPID, ok := ctrInfo.getHostNamespaces()
+			assert.Equal(t, tc.expectedHostIPC, hostIPC)
+			assert.Equal(t, tc.expectedHostNetwork, hostNetwork)
+			assert.Equal(t, tc.expectedHostPID, hostPID)
+			assert.Equal(t, tc.expectedOk, ok)
+		})
+	}
+}
+
+func TestCRIUserNamespace(t *testing.T) {
+	tCases := map[string]struct {
+		jsonInfo            string
+		expectedUserns      bool
+		expectedUIDMappings []event.IDMapping
+		expectedGIDMappings []event.IDMapping
+	}{
+		"empty": {
+			jsonInfo: `{}`,
+		},
+		"no user namespace": {
+			jsonInfo: `{"runtimeSpec": {"linux": {"namespaces": [{"type": "pid"}, {"type": "network"}]}}}`,
+		},
+		"user namespace": {
+			jsonInfo: `{"runtimeSpec": {"linux": {"namespaces": [{"type": "pid"}, {"type": "user"}],
+				"uidMappings": [{"containerID": 0, "hostID": 100000, "size": 65536}],
+				"gidMappings": [{"containerID": 0, "hostID": 200000, "size": 65536}]}}}`,
+			expectedUserns:      true,
+			expectedUIDMappings: []event.IDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}},
+			expectedGIDMappings: []event.IDMapping{{ContainerID: 0, HostID: 200000, Size: 65536}},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var ctrInfo criInfo
+			assert.NoError(t, json.Unmarshal([]byte(tc.jsonInfo), &ctrInfo))
+			userns, uidMappings, gidMappings := ctrInfo.getUserNamespace()
+			assert.Equal(t, tc.expectedUserns, userns)
+			assert.Equal(t, tc.expectedUIDMappings, uidMappings)
+			assert.Equal(t, tc.expectedGIDMappings, gidMappings)
+		})
+	}
 }
 
 func testCRIFake(t *testing.T, withFetcher bool) {
@@ -216,6 +347,8 @@ func testCRIFake(t *testing.T, withFetcher bool) {
 	t.Cleanup(func() {
 		fakeRuntime.Stop()
 	})
+	fakeRuntime.ImageService.SetFakeImageSize(1024)
+	fakeRuntime.ImageService.SetFakeImages([]string{"alpine:3.20.3"})
 
 	engine, err := newCriEngine(context.Background(), slog.Default(), endpoint)
 	assert.NoError(t, err)
@@ -274,15 +407,20 @@ func testCRIFake(t *testing.T, withFetcher bool) {
 				ImageID:          "",
 				ImageRepo:        "alpine",
 				ImageTag:         "3.20.3",
+				ImageRegistry:    "docker.io",
+				ImageSize:        1024,
 				User:             "0",
+				UserIDs:          "0:0",
 				CPUPeriod:        defaultCpuPeriod,
 				CPUQuota:         0,
 				CPUShares:        defaultCpuShares,
 				CPUSetCPUCount:   0,
 				Env:              nil, // not returned in fake mode
 				FullID:           "test_sandbox_test_container_0",
+				RuntimeID:        "fakeRuntime://test_sandbox_test_container_0",
 				Labels:           map[string]string{"foo": "bar", "io.kubernetes.sandbox.id": "test_sandbox_test_container_0"},
 				PodSandboxID:     "test_sandbox_test_container_0",
+				K8sContainerName: "test_container",
 				Privileged:       false,
 				PodSandboxLabels: map[string]string{},
 				Mounts:           []event.Mount{},
@@ -309,6 +447,8 @@ func testCRIFake(t *testing.T, withFetcher bool) {
 			found = true
 			// We don't have this before creation
 			expectedEvent.CreatedTime = evt.CreatedTime
+			expectedEvent.CreatedAt = evt.CreatedAt
+			expectedEvent.StartedAt = evt.StartedAt
 			assert.Equal(t, expectedEvent, evt)
 		}
 	}
@@ -353,6 +493,8 @@ func testCRI(t *testing.T, withFetcher bool) {
 		_, err = imageClient.PullImage(context.Background(), imageSpec, nil, podSandboxConfig)
 		assert.NoError(t, err)
 	}
+	imageStatus, err := imageClient.ImageStatus(context.Background(), imageSpec, false)
+	assert.NoError(t, err)
 
 	ctr, err := client.CreateContainer(context.Background(), sandboxName, &v1.ContainerConfig{
 		Metadata: &v1.ContainerMetadata{
@@ -378,7 +520,14 @@ func testCRI(t *testing.T, withFetcher bool) {
 		},
 	}, podSandboxConfig)
 	assert.NoError(t, err)
+	ctrStatus, err := client.ContainerStatus(context.Background(), ctr, true)
+	assert.NoError(t, err)
+	var ctrInfo criInfo
+	assert.NoError(t, json.Unmarshal([]byte(ctrStatus.GetInfo()["info"]), &ctrInfo))
+	secOpts := ctrInfo.getSecurityOpts()
 
+	containerdDefaultCaps := []string{"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_FSETID", "CAP_FOWNER", "CAP_MKNOD", "CAP_NET_RAW", "CAP_SETGID",
+		"CAP_SETUID", "CAP_SETFCAP", "CAP_SETPCAP", "CAP_NET_BIND_SERVICE", "CAP_SYS_CHROOT", "CAP_KILL", "CAP_AUDIT_WRITE"}
 	expectedEvent := event.Event{
 		Info: event.Info{
 			Container: event.Container{
@@ -390,16 +539,30 @@ func testCRI(t *testing.T, withFetcher bool) {
 				ImageID:          "3.20.3",
 				ImageRepo:        "docker.io/library/alpine",
 				ImageTag:         "3.20.3",
+				ImageRegistry:    "docker.io",
+				ImageSize:        int64(imageStatus.GetImage().GetSize()),
+				ImageLayers:      1,
 				User:             "0",
+				UserIDs:          "0:0",
 				CPUPeriod:        defaultCpuPeriod,
 				CPUQuota:         2000,
 				CPUShares:        defaultCpuShares,
 				CPUSetCPUCount:   3,
 				Env:              []string{"test=container"},
 				FullID:           ctr,
+				RuntimeID:        "containerd://" + ctr,
 				Labels:           map[string]string{"foo": "bar", "io.kubernetes.sandbox.id": sandboxName, "io.kubernetes.pod.name": "test", "io.kubernetes.pod.namespace": "default", "io.kubernetes.pod.uid": id.String()},
 				PodSandboxID:     sandboxName,
+				PodName:          "test",
+				PodNamespace:     "default",
+				PodUID:           id.String(),
+				K8sContainerName: "test_container",
 				Privileged:       false,
+				CapEffective:     containerdDefaultCaps,
+				SeccompProfile:   secOpts.seccompProfile,
+				AppArmorProfile:  secOpts.apparmorProfile,
+				SELinuxLabel:     secOpts.selinuxLabel,
+				NoNewPrivileges:  secOpts.noNewPrivileges,
 				PodSandboxLabels: map[string]string{},
 				Mounts:           []event.Mount{},
 				IsPodSandbox:     true,
@@ -429,7 +592,10 @@ func testCRI(t *testing.T, withFetcher bool) {
 			found = true
 			// We don't have these before creation
 			expectedEvent.CreatedTime = evt.CreatedTime
+			expectedEvent.CreatedAt = evt.CreatedAt
+			expectedEvent.StartedAt = evt.StartedAt
 			expectedEvent.Ip = evt.Ip
+			expectedEvent.Networks = evt.Networks
 			assert.Equal(t, expectedEvent, evt)
 		}
 	}
@@ -458,7 +624,9 @@ func testCRI(t *testing.T, withFetcher bool) {
 				Type:        typeContainerd.ToCTValue(),
 				ID:          ctr[:shortIDLength],
 				FullID:      ctr,
+				RuntimeID:   "containerd://" + ctr,
 				CreatedTime: expectedEvent.CreatedTime,
+				CreatedAt:   expectedEvent.CreatedAt,
 			}},
 		IsCreate: false,
 	}
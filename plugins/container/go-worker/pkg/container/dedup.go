@@ -0,0 +1,87 @@
+package container
+
+import (
+	"cmp"
+	"container/list"
+	"hash/fnv"
+	"sync"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// payloadTrackerMaxEntries bounds the tracked containers when the metadata cache is disabled.
+const payloadTrackerMaxEntries = 4096
+
+// sentPayloads tracks a hash of the last infos sent to the plugin for each container, by container ID,
+// to drop the update events that would not change them, eg: on noisy label refreshes.
+// Removed containers are not always notified (ie: without the remove hook), thus the least recently
+// sent containers are evicted, as for the metadata cache: their next update is just sent anyway.
+var sentPayloads = newPayloadTracker(payloadTrackerMaxEntries)
+
+type payloadHash struct {
+	id  string
+	sum uint64
+}
+
+type payloadTracker struct {
+	mu         sync.Mutex
+	maxEntries int
+	lru        *list.List
+	hashes     map[string]*list.Element
+}
+
+func newPayloadTracker(maxEntries int) *payloadTracker {
+	return &payloadTracker{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		hashes:     make(map[string]*list.Element),
+	}
+}
+
+// initPayloadTracker bounds the tracked containers as the metadata cache, from the current config.
+func initPayloadTracker() {
+	sentPayloads = newPayloadTracker(cmp.Or(config.GetCacheMaxEntries(), payloadTrackerMaxEntries))
+}
+
+// IsRedundant returns whether an event, whose serialized infos are payload, must not be sent
+// since it is an update carrying the same infos last sent for the container.
+// It must be called for every event about to be sent, in order, to keep track of the sent infos.
+func IsRedundant(evt event.Event, payload string) bool {
+	return sentPayloads.isRedundant(evt, payload)
+}
+
+func (t *payloadTracker) isRedundant(evt event.Event, payload string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch evt.Kind() {
+	case event.KindAdded, event.KindUpdated:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(payload))
+		sum := h.Sum64()
+		if elem, ok := t.hashes[evt.ID]; ok {
+			t.lru.MoveToFront(elem)
+			entry := elem.Value.(*payloadHash)
+			if entry.sum == sum && evt.IsUpdate {
+				countDeduplicated()
+				return true
+			}
+			entry.sum = sum
+			return false
+		}
+		t.hashes[evt.ID] = t.lru.PushFront(&payloadHash{id: evt.ID, sum: sum})
+		if t.lru.Len() > t.maxEntries {
+			oldest := t.lru.Back()
+			t.lru.Remove(oldest)
+			delete(t.hashes, oldest.Value.(*payloadHash).id)
+		}
+	case event.KindRemoved, event.KindDied, event.KindPaused, event.KindUnpaused:
+		// The plugin updates its infos on lifecycle events too,
+		// thus the next update must be sent anyway.
+		if elem, ok := t.hashes[evt.ID]; ok {
+			t.lru.Remove(elem)
+			delete(t.hashes, evt.ID)
+		}
+	}
+	return false
+}
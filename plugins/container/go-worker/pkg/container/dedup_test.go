@@ -0,0 +1,56 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestPayloadTracker(t *testing.T) {
+	tracker := newPayloadTracker(payloadTrackerMaxEntries)
+	info := cacheInfo("test", "test")
+	created := event.Event{Info: info, IsCreate: true}
+	updated := event.Event{Info: info, IsCreate: true, IsUpdate: true}
+	deduplicated := Metric(MetricDeduplicated)
+
+	assert.False(t, tracker.isRedundant(created, created.String()))
+	// Created events are always sent, eg: on reconciliations
+	assert.False(t, tracker.isRedundant(created, created.String()))
+	assert.True(t, tracker.isRedundant(updated, updated.String()))
+	assert.Equal(t, deduplicated+1, Metric(MetricDeduplicated))
+
+	changed := updated
+	changed.Labels = map[string]string{"team": "security"}
+	assert.False(t, tracker.isRedundant(changed, changed.String()))
+	assert.True(t, tracker.isRedundant(changed, changed.String()))
+
+	// Stats events do not change the sent infos
+	stats := event.Event{Info: info, IsStats: true}
+	assert.False(t, tracker.isRedundant(stats, stats.String()))
+	assert.True(t, tracker.isRedundant(changed, changed.String()))
+
+	// Dead containers infos got updated by the plugin
+	died := event.Event{Info: info, IsDie: true}
+	assert.False(t, tracker.isRedundant(died, died.String()))
+	assert.False(t, tracker.isRedundant(changed, changed.String()))
+
+	removed := event.Event{Info: info}
+	assert.False(t, tracker.isRedundant(removed, removed.String()))
+	assert.NotContains(t, tracker.hashes, "test")
+	assert.False(t, tracker.isRedundant(updated, updated.String()))
+}
+
+func TestPayloadTrackerEviction(t *testing.T) {
+	tracker := newPayloadTracker(1)
+	first := event.Event{Info: cacheInfo("first", "test"), IsCreate: true, IsUpdate: true}
+	second := event.Event{Info: cacheInfo("second", "test"), IsCreate: true, IsUpdate: true}
+
+	assert.False(t, tracker.isRedundant(first, first.String()))
+	assert.True(t, tracker.isRedundant(first, first.String()))
+	// The least recently sent container is evicted, and its next update sent anyway
+	assert.False(t, tracker.isRedundant(second, second.String()))
+	assert.Len(t, tracker.hashes, 1)
+	assert.False(t, tracker.isRedundant(first, first.String()))
+}
@@ -0,0 +1,32 @@
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// configDigest returns a stable digest of the configuration of a container, ie: of all of its metadata
+// but its runtime state (eg: the restarts and the health status), which change while the container runs.
+// Map keys are serialized sorted, so equal configurations always get the same digest.
+func configDigest(ctr event.Container) string {
+	ctr.ConfigDigest = ""
+	ctr.StartedAt = 0
+	ctr.Size = 0
+	ctr.RestartCount = 0
+	ctr.ExitCode = 0
+	ctr.FinishedAt = 0
+	ctr.OOMKilled = false
+	ctr.PausedAt = 0
+	ctr.HealthStatus = ""
+	ctr.HealthOutput = ""
+	ctr.Stats = nil
+	data, err := json.Marshal(ctr)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,313 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const discoveryInterval = 5 * time.Second
+
+// Discovery is a fake engine bound to a socket glob pattern, eg: "/run/user/*/podman/podman.sock",
+// or to a socket that does not exist yet, eg: when the container runtime is not running yet.
+// Sockets already matching the pattern at startup get their own engine through Generators();
+// the discovery engine periodically re-expands the pattern and attaches a new engine
+// (with its own listener goroutine) to every socket that appears later on,
+// forwarding all their events to its output channel.
+// For plain sockets, the parent directory is also watched through inotify,
+// to attach as soon as the socket appears.
+type discovery struct {
+	logger     *slog.Logger
+	engineType engineType
+	generator  engineGenerator
+	pattern    string
+	// resolved tracks the resolved paths of the sockets attached by all the engines of the same type.
+	resolved *socketSet
+
+	mu sync.Mutex
+	// attached tracks sockets already attached, either at startup or by the discovery itself.
+	attached map[string]struct{}
+	// getters are copies of the attached discovered engines, by socket, used by the fetcher engine.
+	getters map[string]getter
+}
+
+// socketSet tracks the resolved paths of the sockets attached by the engines of a type,
+// to avoid attaching twice to the same socket through different paths,
+// eg: /var/run/crio/crio.sock and /run/crio/crio.sock.
+type socketSet struct {
+	mu    sync.Mutex
+	paths map[string]struct{}
+}
+
+func newSocketSet() *socketSet {
+	return &socketSet{paths: make(map[string]struct{})}
+}
+
+// add returns the resolved path of a socket, and whether it was not attached yet.
+// Sockets that cannot be resolved, eg: because they do not exist, are never tracked.
+func (s *socketSet) add(socket string) (string, bool) {
+	resolved, err := filepath.EvalSymlinks(socket)
+	if err != nil {
+		return "", true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.paths[resolved]; ok {
+		return resolved, false
+	}
+	s.paths[resolved] = struct{}{}
+	return resolved, true
+}
+
+func (s *socketSet) remove(resolved string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.paths, resolved)
+}
+
+// autoSocket is a sockets entry standing for the well-known sockets of an engine,
+// so that the same config works across hosts running different container runtimes.
+const autoSocket = "auto"
+
+// wellKnownSockets are the sockets probed by each engine configured with autoSocket.
+// Sockets that do not exist are watched by discovery engines, like any other configured socket.
+var wellKnownSockets = map[engineType][]string{
+	typeDocker: {
+		"/var/run/docker.sock",
+		rootlessDockerSocket,
+	},
+	typePodman: {
+		"/run/podman/podman.sock",
+		"/run/user/*/podman/podman.sock", // rootless
+	},
+	typeContainerd: {
+		"/run/containerd/containerd.sock",
+		"/run/k3s/containerd/containerd.sock",
+		"/var/snap/microk8s/common/run/containerd.sock",
+		"/run/host-containerd/containerd.sock", // bottlerocket
+	},
+	typeCri: {
+		"/run/containerd/containerd.sock",
+		"/run/crio/crio.sock",
+		"/run/k3s/containerd/containerd.sock",
+		"/var/snap/microk8s/common/run/containerd.sock",
+		"/run/host-containerd/containerd.sock", // bottlerocket
+	},
+}
+
+// expandSockets replaces the autoSocket entry of the sockets of an engine with its well-known sockets,
+// skipping the ones that are already configured.
+func expandSockets(engine engineType, sockets []string) []string {
+	if !slices.Contains(sockets, autoSocket) {
+		return sockets
+	}
+	expanded := make([]string, 0, len(sockets)+len(wellKnownSockets[engine]))
+	for _, socket := range sockets {
+		if socket != autoSocket {
+			expanded = append(expanded, socket)
+		}
+	}
+	for _, socket := range wellKnownSockets[engine] {
+		if !slices.Contains(expanded, socket) {
+			expanded = append(expanded, socket)
+		}
+	}
+	return expanded
+}
+
+func isSocketPattern(socket string) bool {
+	return strings.ContainsAny(socket, "*?[")
+}
+
+func newDiscoveryEngine(logger *slog.Logger, engineType engineType, generator engineGenerator,
+	pattern string, attached []string, resolved *socketSet) *discovery {
+	d := &discovery{
+		logger:     logger,
+		engineType: engineType,
+		generator:  generator,
+		pattern:    pattern,
+		resolved:   resolved,
+		attached:   make(map[string]struct{}, len(attached)),
+		getters:    make(map[string]getter),
+	}
+	for _, socket := range attached {
+		d.attached[socket] = struct{}{}
+	}
+	return d
+}
+
+func (d *discovery) copy(_ context.Context) (Engine, error) {
+	// Discovered engines are shared with the fetcher, that will access them through get().
+	return d, nil
+}
+
+func (d *discovery) get(ctx context.Context, containerId string) (*event.Event, error) {
+	d.mu.Lock()
+	getters := make([]getter, 0, len(d.getters))
+	for _, g := range d.getters {
+		getters = append(getters, g)
+	}
+	d.mu.Unlock()
+	for _, g := range getters {
+		evt, _ := g.get(ctx, containerId)
+		if evt != nil {
+			return evt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *discovery) Name() string {
+	return string(d.engineType)
+}
+
+func (d *discovery) Sock() string {
+	return d.pattern
+}
+
+func (d *discovery) List(_ context.Context) ([]event.Event, error) {
+	// Pre-existing containers of sockets matching at startup are listed by their own engines.
+	return []event.Event{}, nil
+}
+
+// discover returns the sockets matching the pattern that are not attached yet.
+func (d *discovery) discover() []string {
+	matches, err := filepath.Glob(d.pattern)
+	if err != nil {
+		return nil
+	}
+	sockets := make([]string, 0)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, match := range matches {
+		if _, ok := d.attached[match]; !ok {
+			sockets = append(sockets, match)
+		}
+	}
+	return sockets
+}
+
+func (d *discovery) attach(ctx context.Context, wg *sync.WaitGroup, socket string, outCh chan<- event.Event) {
+	resolved, ok := d.resolved.add(socket)
+	if !ok {
+		// Already attached through another path; it is checked again on next discovery,
+		// in case the other path is detached.
+		d.logger.LogAttrs(ctx, config.LevelTrace, "discovered socket already attached", slog.String("socket", socket), slog.String("resolved", resolved))
+		return
+	}
+	engine, err := d.generator(ctx, d.logger, socket)
+	if err != nil {
+		// Not ready yet; we will retry on next discovery.
+		d.logger.LogAttrs(ctx, slog.LevelDebug, "failed to attach discovered socket", slog.String("socket", socket), slog.String("err", err.Error()))
+		d.resolved.remove(resolved)
+		return
+	}
+	ch, err := engine.Listen(ctx, wg)
+	if err != nil {
+		d.logger.LogAttrs(ctx, slog.LevelDebug, "failed to listen on discovered socket", slog.String("socket", socket), slog.String("err", err.Error()))
+		d.resolved.remove(resolved)
+		return
+	}
+	d.logger.LogAttrs(ctx, slog.LevelInfo, "attached discovered socket", slog.String("socket", socket))
+	notifyStatus(endpoint{engine: d.engineType, socket: socket}, event.EngineStatusUp, nil)
+
+	d.mu.Lock()
+	d.attached[socket] = struct{}{}
+	if cp, ok := engine.(copier); ok {
+		if e, _ := cp.copy(context.Background()); e != nil {
+			if g, ok := e.(getter); ok {
+				d.getters[socket] = g
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	// Pre-existing containers on a socket discovered after startup are notified as new ones.
+	containers, err := ListRecovered(ctx, engine)
+	if err == nil {
+		for _, ctr := range containers {
+			select {
+			case outCh <- ctr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for evt := range ch {
+			select {
+			case outCh <- evt:
+			case <-ctx.Done():
+				// Keep draining until the engine closes its channel.
+			}
+		}
+		// The engine stopped listening; allow it to be discovered again.
+		d.mu.Lock()
+		delete(d.attached, socket)
+		delete(d.getters, socket)
+		d.mu.Unlock()
+		d.resolved.remove(resolved)
+	}()
+}
+
+func (d *discovery) attachDiscovered(ctx context.Context, wg *sync.WaitGroup, outCh chan<- event.Event) {
+	for _, socket := range d.discover() {
+		d.logger.LogAttrs(ctx, config.LevelTrace, "discovered new socket", slog.String("socket", socket))
+		d.attach(ctx, wg, socket, outCh)
+	}
+}
+
+// Listen periodically re-expands the socket pattern, attaching
+// a new engine to each newly discovered socket.
+func (d *discovery) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	var notifyCh <-chan struct{}
+	if !isSocketPattern(d.pattern) {
+		// The parent directory might not exist yet either; periodic discovery still covers it.
+		ch, err := watchDir(ctx, filepath.Dir(d.pattern))
+		if err != nil {
+			d.logger.LogAttrs(ctx, slog.LevelDebug, "failed to watch socket directory", slog.String("socket", d.pattern), slog.String("err", err.Error()))
+		} else {
+			notifyCh = ch
+		}
+	}
+
+	outCh := make(chan event.Event)
+	// engineWg accounts for discovered engines listeners,
+	// that need to be stopped before closing the output channel.
+	engineWg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer func() {
+			engineWg.Wait()
+			close(outCh)
+			wg.Done()
+		}()
+		ticker := time.NewTicker(discoveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-notifyCh:
+				if !ok {
+					notifyCh = nil
+					break
+				}
+				d.attachDiscovered(ctx, engineWg, outCh)
+			case <-ticker.C:
+				d.attachDiscovered(ctx, engineWg, outCh)
+			}
+		}
+	}()
+	return outCh, nil
+}
@@ -0,0 +1,135 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestIsSocketPattern(t *testing.T) {
+	assert.True(t, isSocketPattern("/run/user/*/podman/podman.sock"))
+	assert.True(t, isSocketPattern("/run/user/100?/podman/podman.sock"))
+	assert.False(t, isSocketPattern("/run/podman/podman.sock"))
+}
+
+func TestExpandSockets(t *testing.T) {
+	sockets := []string{"/var/run/docker.sock"}
+	assert.Equal(t, sockets, expandSockets(typeDocker, sockets))
+
+	assert.Equal(t, []string{"/custom/docker.sock", "/var/run/docker.sock", "/run/user/*/docker.sock"},
+		expandSockets(typeDocker, []string{"/custom/docker.sock", autoSocket, "/var/run/docker.sock"}))
+	assert.Equal(t, wellKnownSockets[typeCri], expandSockets(typeCri, []string{autoSocket}))
+
+	// Engines without well-known sockets
+	assert.Empty(t, expandSockets(typeFixture, []string{autoSocket}))
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, uid := range []string{"1000", "1001"} {
+		dir := filepath.Join(root, "run", "user", uid, "podman")
+		assert.NoError(t, os.MkdirAll(dir, 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "podman.sock"), nil, 0o644))
+	}
+
+	pattern := filepath.Join(root, "run", "user", "*", "podman", "podman.sock")
+	attached := filepath.Join(root, "run", "user", "1000", "podman", "podman.sock")
+	noopGenerator := func(context.Context, *slog.Logger, string) (Engine, error) {
+		return nil, nil
+	}
+	d := newDiscoveryEngine(slog.Default(), typePodman, noopGenerator, pattern, []string{attached}, newSocketSet())
+	assert.Equal(t, string(typePodman), d.Name())
+	assert.Equal(t, pattern, d.Sock())
+
+	// Only the socket not attached at startup must be discovered
+	assert.Equal(t, []string{filepath.Join(root, "run", "user", "1001", "podman", "podman.sock")}, d.discover())
+
+	// A socket appearing later on must be discovered too
+	dir := filepath.Join(root, "run", "user", "1002", "podman")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "podman.sock"), nil, 0o644))
+	assert.Len(t, d.discover(), 2)
+}
+
+func TestDiscoveryHotPlug(t *testing.T) {
+	root := t.TempDir()
+	// Use the fixture engine, whose "socket" is a directory, as attached engine
+	socket := filepath.Join(root, "fixtures")
+	d := newDiscoveryEngine(slog.Default(), typeFixture, newFixtureEngine, socket, nil, newSocketSet())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	ch, err := d.Listen(ctx, &wg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// Create the socket once the discovery is already listening:
+	// its parent directory is watched, thus it must be attached before next periodic discovery.
+	tmp := filepath.Join(t.TempDir(), "fixtures")
+	require.NoError(t, os.Mkdir(tmp, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "ctr.json"), []byte(`{"container": {"id": "hotplug"}}`), 0o644))
+	require.NoError(t, os.Rename(tmp, socket))
+
+	select {
+	case evt := <-ch:
+		assert.Equal(t, "hotplug", evt.ID)
+		assert.True(t, evt.IsCreate)
+	case <-time.After(discoveryInterval / 2):
+		t.Fatal("socket not attached on creation")
+	}
+	d.mu.Lock()
+	assert.Contains(t, d.getters, socket)
+	d.mu.Unlock()
+
+	// The getter of a detached socket is dropped
+	cancel()
+	for range ch {
+	}
+	wg.Wait()
+	assert.Empty(t, d.getters)
+}
+
+func TestDiscoverySkipsResolvedSockets(t *testing.T) {
+	root := t.TempDir()
+	// Use the fixture engine, whose "socket" is a directory, reachable through two paths
+	socket := filepath.Join(root, "fixtures")
+	require.NoError(t, os.Mkdir(socket, 0o755))
+	link := filepath.Join(root, "link")
+	require.NoError(t, os.Symlink(socket, link))
+
+	resolved := newSocketSet()
+	_, ok := resolved.add(socket)
+	require.True(t, ok)
+	d := newDiscoveryEngine(slog.Default(), typeFixture, newFixtureEngine, link, nil, resolved)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	outCh := make(chan event.Event)
+
+	// The socket is already attached through its real path
+	d.attach(ctx, &wg, link, outCh)
+	assert.Empty(t, d.attached)
+
+	// Once detached from its real path, it is attached through the link
+	resolved.remove(socket)
+	d.attach(ctx, &wg, link, outCh)
+	assert.Contains(t, d.attached, link)
+	_, ok = resolved.add(socket)
+	assert.False(t, ok)
+}
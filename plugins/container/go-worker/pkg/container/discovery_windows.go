@@ -0,0 +1,12 @@
+//go:build windows
+
+package container
+
+func init() {
+	// Windows engines listen on named pipes, rather than on unix sockets.
+	wellKnownSockets = map[engineType][]string{
+		typeDocker:     {"npipe:////./pipe/docker_engine"},
+		typeContainerd: {"npipe:////./pipe/containerd-containerd"},
+		typeCri:        {"npipe:////./pipe/containerd-containerd"},
+	}
+}
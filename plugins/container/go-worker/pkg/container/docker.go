@@ -3,8 +3,12 @@ package container
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,13 +17,13 @@ import (
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 )
 
-
 func init() {
 	engineGenerators[typeDocker] = newDockerEngine
 }
@@ -28,20 +32,83 @@ type dockerEngine struct {
 	*client.Client
 	logger *slog.Logger
 	socket string
+	// dockerContext is the docker CLI context the engine was attached to, if any.
+	dockerContext string
+	// versionMu guards the negotiation of the API version with the daemon.
+	versionMu sync.Mutex
+	// apiVersion is the API version negotiated with the daemon, once done.
+	apiVersion string
+	// known tracks the containers notified by the engine, seeded by List.
+	known *knownContainers
 }
 
 func newDockerEngine(_ context.Context, logger *slog.Logger, socket string) (Engine, error) {
-	cl, err := client.NewClientWithOpts(client.FromEnv,
-		client.WithAPIVersionNegotiation(),
-		client.WithHost(enforceUnixProtocolIfEmpty(socket)))
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if isRemoteSocket(socket) {
+		engineTLS := config.GetEngineTLS(string(typeDocker), socket)
+		if engineTLS.Enabled() {
+			reloader, err := newTLSReloader(engineTLS)
+			if err != nil {
+				return nil, err
+			}
+			// Each new connection gets the current TLS material, to follow certificates rotations.
+			opts = append(opts, client.WithScheme("https"), client.WithHTTPClient(&http.Client{
+				Transport: &http.Transport{DialTLSContext: reloader.dialTLS},
+			}))
+		}
+	}
+	// WithHost must come after WithHTTPClient, since it configures the client transport.
+	opts = append(opts, client.WithHost(enforceUnixProtocolIfEmpty(socket)))
+	cl, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerEngine{Client: cl, logger: logger, socket: socket, known: newKnownContainers()}, nil
+}
+
+// newDockerContextEngine attaches to the docker endpoint of a docker CLI context,
+// as stored under $DOCKER_CONFIG/contexts (or ~/.docker/contexts).
+func newDockerContextEngine(_ context.Context, logger *slog.Logger, contextName string) (Engine, error) {
+	dockerCtx, err := loadDockerContext(dockerConfigDir(), contextName)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+	if dockerCtx.TLSConfig != nil {
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: dockerCtx.TLSConfig},
+		}))
+	}
+	// WithHost must come after WithHTTPClient, since it configures the client transport.
+	opts = append(opts, client.WithHost(dockerCtx.Host))
+	cl, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
-	return &dockerEngine{Client: cl, logger: logger, socket: socket}, nil
+	return &dockerEngine{Client: cl, logger: logger, socket: dockerCtx.Host, dockerContext: contextName, known: newKnownContainers()}, nil
 }
 
 func (dc *dockerEngine) copy(ctx context.Context) (Engine, error) {
-	return newDockerEngine(ctx, dc.logger, dc.socket)
+	var (
+		e   Engine
+		err error
+	)
+	if dc.dockerContext != "" {
+		e, err = newDockerContextEngine(ctx, dc.logger, dc.dockerContext)
+	} else {
+		e, err = newDockerEngine(ctx, dc.logger, dc.socket)
+	}
+	version := dc.negotiatedAPIVersion()
+	if err != nil || version == "" {
+		return e, err
+	}
+	// Reuse the negotiated API version, instead of negotiating it again.
+	copied := e.(*dockerEngine)
+	if err = client.WithVersion(version)(copied.Client); err != nil {
+		return nil, err
+	}
+	copied.apiVersion = version
+	return copied, nil
 }
 
 func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResponse) event.Info {
@@ -62,6 +129,8 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 			Mode:        m.Mode,
 			RW:          m.RW,
 			Propagation: string(m.Propagation),
+			Type:        string(m.Type),
+			Name:        m.Name,
 		})
 	}
 
@@ -74,30 +143,22 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 	if netCfg == nil {
 		netCfg = &container.NetworkSettings{}
 	}
+	// Stopped containers have no port bound yet: report the configured ones, possibly with ranges of host ports.
+	ports := netCfg.Ports
+	if len(ports) == 0 {
+		ports = hostCfg.PortBindings
+	}
 	portMappings := make([]event.PortMapping, 0)
-	for port, portBindings := range netCfg.Ports {
-		if port.Proto() != "tcp" {
-			continue
-		}
-		containerPort := port.Int()
+	for port, portBindings := range ports {
 		for _, portBinding := range portBindings {
-			hostIP, err := parsePortBindingHostIP(portBinding.HostIP)
-			if err != nil {
-				continue
-			}
-
-			hostPort, err := parsePortBindingHostPort(portBinding.HostPort)
+			portMapping, err := newPortMapping(port.Int(), port.Proto(), portBinding.HostIP, portBinding.HostPort)
 			if err != nil {
 				continue
 			}
-
-			portMappings = append(portMappings, event.PortMapping{
-				HostIP:        hostIP,
-				HostPort:      hostPort,
-				ContainerPort: containerPort,
-			})
+			portMappings = append(portMappings, portMapping)
 		}
 	}
+	sortPortMappings(portMappings)
 	cfg := ctr.Config
 	if cfg == nil {
 		cfg = &container.Config{}
@@ -155,6 +216,16 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 		imageID = strings.TrimPrefix(img.ID, "sha256:")
 	}
 
+	// cfg.Image might be an image ID: fall back to the repository then.
+	imageRef := parseImageReference(cfg.Image)
+	if imageRef.registry == "" {
+		imageRef = parseImageReference(imageRepo)
+	}
+	if imageDigest == "" {
+		imageDigest = imageRef.digest
+	}
+	imageDigest = resolveImageDigest(cfg.Image, imageDigest)
+
 	labels := make(map[string]string)
 	for key, val := range cfg.Labels {
 		if len(val) <= config.GetLabelMaxLen() {
@@ -162,18 +233,42 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 		}
 	}
 
+	// Dockershim labels workload containers with their sandbox ID
+	var podSandboxID, podSandboxNetNS string
+	if isPodSandbox {
+		podSandboxID = ctr.ID
+		podSandboxNetNS = netCfg.SandboxKey
+	} else {
+		podSandboxID = cfg.Labels[k8sSandboxIDLabel]
+	}
+
 	ip := netCfg.IPAddress
+	networks := dockerNetworks(netCfg.Networks)
 	if ip == "" {
 		if hostCfg.NetworkMode.IsContainer() {
 			secondaryID := hostCfg.NetworkMode.ConnectedContainer()
 			secondary, _ := dc.ContainerInspect(ctx, secondaryID)
 			if secondary.NetworkSettings != nil {
 				ip = secondary.NetworkSettings.IPAddress
+				networks = dockerNetworks(secondary.NetworkSettings.Networks)
+				// Workload containers join the network namespace of their sandbox
+				if podSandboxID != "" {
+					podSandboxNetNS = secondary.NetworkSettings.SandboxKey
+				}
 			}
 		}
 	}
 
+	if ip == "" {
+		// Containers only attached to user-defined networks
+		ip = firstNetworkIP(networks)
+	}
+
 	createdTime, _ := time.Parse(time.RFC3339Nano, ctr.Created)
+	var startedTime time.Time
+	if ctr.State != nil {
+		startedTime, _ = time.Parse(time.RFC3339Nano, ctr.State.StartedAt)
+	}
 
 	var (
 		cpuShares int64 = defaultCpuShares
@@ -192,7 +287,33 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 		size = *ctr.SizeRw
 	}
 
-	return event.Info{
+	var healthStatus, healthOutput string
+	if dc.supportsAPI(dockerHealthAPIVersion) {
+		healthStatus, healthOutput = dockerHealth(ctr.State)
+	}
+
+	secOpts := parseSecurityOpts(hostCfg.SecurityOpt, hostCfg.Privileged)
+	// Prefer the actually applied profiles
+	if ctr.AppArmorProfile != "" {
+		secOpts.apparmorProfile = ctr.AppArmorProfile
+	}
+	if ctr.ProcessLabel != "" {
+		secOpts.selinuxLabel = ctr.ProcessLabel
+	}
+
+	// Docker does not expose the user namespace mappings (eg: with userns-remap);
+	// read them from the container init process.
+	var uidMappings, gidMappings []event.IDMapping
+	if !hostCfg.UsernsMode.IsHost() && ctr.State != nil {
+		uidMappings, gidMappings = procIDMappings(ctr.State.Pid)
+	}
+
+	var pidsLimit int64
+	if hostCfg.PidsLimit != nil && *hostCfg.PidsLimit > 0 {
+		pidsLimit = *hostCfg.PidsLimit
+	}
+
+	info := event.Info{
 		Container: event.Container{
 			Type:             typeDocker.ToCTValue(),
 			ID:               shortContainerID(ctr.ID),
@@ -202,28 +323,247 @@ func (dc *dockerEngine) ctrToInfo(ctx context.Context, ctr container.InspectResp
 			ImageID:          imageID,
 			ImageRepo:        imageRepo,
 			ImageTag:         imageTag,
+			ImageRegistry:    imageRef.registry,
+			ImageSize:        img.Size,
+			ImageLayers:      int64(len(img.RootFS.Layers)),
 			User:             cfg.User,
 			CPUPeriod:        cpuPeriod,
 			CPUQuota:         hostCfg.CPUQuota,
 			CPUShares:        cpuShares,
 			CPUSetCPUCount:   cpusetCount,
 			CreatedTime:      createdTime.Unix(),
-			Env:              cfg.Env,
+			CreatedAt:        unixNano(createdTime),
+			StartedAt:        unixNano(startedTime),
+			Env:              filterEnv(cfg.Env),
 			FullID:           ctr.ID,
 			HostIPC:          hostCfg.IpcMode.IsHost(),
 			HostNetwork:      hostCfg.NetworkMode.IsHost(),
 			HostPID:          hostCfg.PidMode.IsHost(),
 			Ip:               ip,
+			Networks:         networks,
 			IsPodSandbox:     isPodSandbox,
 			Labels:           labels,
 			MemoryLimit:      hostCfg.Memory,
 			SwapLimit:        hostCfg.MemorySwap,
+			PidsLimit:        pidsLimit,
+			PodSandboxID:     podSandboxID,
+			PodSandboxNetNS:  podSandboxNetNS,
 			Privileged:       hostCfg.Privileged,
+			Runtime:          hostCfg.Runtime,
+			SandboxedRuntime: isSandboxedRuntime(hostCfg.Runtime),
+			Isolation:        dockerIsolation(hostCfg.Isolation),
+			CapAdd:           normalizeCaps(hostCfg.CapAdd),
+			CapDrop:          normalizeCaps(hostCfg.CapDrop),
+			SeccompProfile:   secOpts.seccompProfile,
+			AppArmorProfile:  secOpts.apparmorProfile,
+			SELinuxLabel:     secOpts.selinuxLabel,
+			NoNewPrivileges:  secOpts.noNewPrivileges,
+			UserNamespace:    len(uidMappings) > 0,
+			UIDMappings:      uidMappings,
+			GIDMappings:      gidMappings,
 			PortMappings:     portMappings,
 			Mounts:           mounts,
 			Size:             size,
+			EngineSocket:     dc.socket,
+			RootlessUID:      rootlessUID(dc.socket),
+			HealthStatus:     healthStatus,
+			HealthOutput:     healthOutput,
+			RestartCount:     int64(ctr.RestartCount),
 		},
 	}
+	if ctr.State != nil {
+		fillProcCgroupLimits(&info.Container, ctr.State.Pid)
+		fillCgroupPath(&info.Container, "", ctr.State.Pid)
+		setDockerExitStatus(&info.Container, ctr.State)
+	}
+	if hc := cfg.Healthcheck; hc != nil {
+		info.Healthcheck = newHealthProbe(hc.Test, hc.Interval, hc.Timeout, hc.StartPeriod, hc.Retries)
+	}
+	var gpus gpuDevices
+	gpus.addEnv(cfg.Env)
+	gpus.addDeviceRequests(hostCfg.DeviceRequests)
+	for _, dev := range hostCfg.Devices {
+		gpus.addDeviceNodes(dev.PathOnHost)
+	}
+	info.GPUDevices = gpus
+	// Containers of kubernetes pods, through dockershim
+	setK8sPodMetadata(&info.Container, cfg.Labels, nil)
+	// Containers of compose services
+	setComposeMetadata(&info.Container, cfg.Labels)
+	// Containers of ECS tasks, through the ECS agent
+	setEcsTaskMetadata(ctx, &info.Container, cfg.Labels, cfg.Env)
+	// Containers of nomad allocations, through the nomad docker driver
+	setNomadMetadata(ctx, &info.Container, cfg.Labels, cfg.Env)
+	return info
+}
+
+// dockerIsolation returns the isolation mode of a windows container;
+// linux containers only have the default isolation, thus an empty one.
+func dockerIsolation(isolation container.Isolation) string {
+	switch {
+	case isolation.IsHyperV():
+		return isolationHyperV
+	case isolation.IsProcess():
+		return isolationProcess
+	}
+	return ""
+}
+
+// addDeviceRequests adds the GPUs requested through device requests, ie: through --gpus, or as CDI devices.
+// As done by the docker daemon, a count of N GPUs gets the first N ones.
+func (d *gpuDevices) addDeviceRequests(reqs []container.DeviceRequest) {
+	for _, req := range reqs {
+		if req.Driver == "cdi" {
+			d.addCDI(req.DeviceIDs...)
+			continue
+		}
+		if req.Driver != "nvidia" && !slices.ContainsFunc(req.Capabilities, func(caps []string) bool {
+			return slices.Contains(caps, cdiGpuClass)
+		}) {
+			continue
+		}
+		switch {
+		case len(req.DeviceIDs) > 0:
+			d.add(req.DeviceIDs...)
+		case req.Count < 0:
+			d.add("all")
+		default:
+			for i := range req.Count {
+				d.add(strconv.Itoa(i))
+			}
+		}
+	}
+}
+
+// dockerHealth returns the health status of a container, if it has a healthcheck,
+// and the output of its last check, if failing.
+func dockerHealth(state *container.State) (string, string) {
+	if state == nil || state.Health == nil {
+		return "", ""
+	}
+	var output string
+	if n := len(state.Health.Log); n > 0 && state.Health.Log[n-1] != nil && state.Health.Log[n-1].ExitCode != 0 {
+		output = strings.TrimSpace(state.Health.Log[n-1].Output)
+	}
+	return string(state.Health.Status), output
+}
+
+// dockerNetworks returns the networks a container is attached to.
+func dockerNetworks(endpoints map[string]*network.EndpointSettings) []event.Network {
+	networks := make([]event.Network, 0, len(endpoints))
+	for name, endpoint := range endpoints {
+		if endpoint == nil {
+			continue
+		}
+		// DNSNames (API >= 1.44) holds the container name too, besides the user-specified aliases.
+		aliases := endpoint.DNSNames
+		if len(aliases) == 0 {
+			aliases = endpoint.Aliases
+		}
+		networks = append(networks, event.Network{
+			Name:    name,
+			IP:      endpoint.IPAddress,
+			IPv6:    endpoint.GlobalIPv6Address,
+			MAC:     endpoint.MacAddress,
+			Gateway: endpoint.Gateway,
+			Aliases: aliases,
+		})
+	}
+	return sortNetworks(networks)
+}
+
+// setDockerExitStatus sets the exit status of the last run of a stopped container.
+// Docker resets it once the container gets restarted, except for the finished-at timestamp.
+func setDockerExitStatus(ctr *event.Container, state *container.State) {
+	if state.Running || state.Restarting {
+		return
+	}
+	finishedAt, err := time.Parse(time.RFC3339Nano, state.FinishedAt)
+	if err != nil || finishedAt.IsZero() {
+		// Never started
+		return
+	}
+	ctr.ExitCode = int64(state.ExitCode)
+	ctr.FinishedAt = finishedAt.UnixNano()
+	ctr.OOMKilled = state.OOMKilled
+}
+
+// healthEvent builds the update event notifying the new health status of a container.
+// When the container cannot be inspected, the cached infos are updated with the status from the event;
+// if there are none, no event is sent.
+func (dc *dockerEngine) healthEvent(ctx context.Context, minimal event.Info, msg events.Message) (event.Event, bool) {
+	inspectCtx, cancel := inspectContext(ctx, typeDocker)
+	defer cancel()
+	ctrJson, _, err := dc.ContainerInspectWithRaw(inspectCtx, msg.Actor.ID, config.GetWithSize())
+	if err == nil {
+		return event.Event{Info: dc.ctrToInfo(inspectCtx, ctrJson), IsCreate: true, IsUpdate: true}, true
+	}
+	info, ok := metadata.get(minimal.ID)
+	if !ok {
+		return event.Event{}, false
+	}
+	info.HealthStatus = strings.TrimSpace(strings.TrimPrefix(string(msg.Action), string(events.ActionHealthStatus)+":"))
+	return event.Event{Info: info, IsCreate: true, IsUpdate: true}, true
+}
+
+// dockerMemoryUsage returns the memory usage of a container, excluding the inactive page cache,
+// as the docker CLI does.
+func dockerMemoryUsage(mem container.MemoryStats) uint64 {
+	// cgroup v2 reports inactive_file, cgroup v1 total_inactive_file
+	inactive, ok := mem.Stats["inactive_file"]
+	if !ok {
+		inactive = mem.Stats["total_inactive_file"]
+	}
+	if inactive > mem.Usage {
+		return mem.Usage
+	}
+	return mem.Usage - inactive
+}
+
+// newDockerUsageSample returns the usage sample of a container from its stats.
+func newDockerUsageSample(id string, stats container.StatsResponse) usageSample {
+	return usageSample{
+		minimal: event.Info{
+			Container: event.Container{
+				Type:   typeDocker.ToCTValue(),
+				ID:     shortContainerID(id),
+				FullID: id,
+			},
+		},
+		cpuNano:     stats.CPUStats.CPUUsage.TotalUsage,
+		memoryUsage: dockerMemoryUsage(stats.MemoryStats),
+		memoryLimit: stats.MemoryStats.Limit,
+		pids:        stats.PidsStats.Current,
+		sampledAt:   stats.Read.UnixNano(),
+	}
+}
+
+// sampleUsage samples the resource usage of the running containers, one at a time.
+func (dc *dockerEngine) sampleUsage(ctx context.Context) ([]usageSample, error) {
+	containers, err := dc.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]usageSample, 0, len(containers))
+	for _, ctr := range containers {
+		statsCtx, cancel := inspectContext(ctx, typeDocker)
+		resp, err := dc.ContainerStatsOneShot(statsCtx, ctr.ID)
+		if err != nil {
+			cancel()
+			dc.logger.LogAttrs(ctx, slog.LevelDebug, "failed to get container stats", slog.String("container_id", ctr.ID), slog.String("err", err.Error()))
+			continue
+		}
+		var stats container.StatsResponse
+		err = json.NewDecoder(resp.Body).Decode(&stats)
+		_ = resp.Body.Close()
+		cancel()
+		if err != nil {
+			dc.logger.LogAttrs(ctx, slog.LevelDebug, "failed to decode container stats", slog.String("container_id", ctr.ID), slog.String("err", err.Error()))
+			continue
+		}
+		samples = append(samples, newDockerUsageSample(ctr.ID, stats))
+	}
+	return samples, nil
 }
 
 func (dc *dockerEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
@@ -246,29 +586,56 @@ func (dc *dockerEngine) Sock() string {
 	return dc.socket
 }
 
+// List lists all containers, considering them already known by the listener.
+// Being called before Listen, it also negotiates the API version with the daemon.
 func (dc *dockerEngine) List(ctx context.Context) ([]event.Event, error) {
+	if err := dc.negotiateAPIVersion(ctx); err != nil {
+		return nil, err
+	}
+	evts, err := dc.list(ctx, true)
+	if err == nil {
+		dc.known.seed(evts)
+	}
+	return evts, err
+}
+
+// list lists all containers, serving their infos from the metadata cache if cached.
+func (dc *dockerEngine) list(ctx context.Context, cached bool) ([]event.Event, error) {
 	containers, err := dc.ContainerList(ctx, container.ListOptions{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	evts := make([]event.Event, len(containers))
-	for idx, ctr := range containers {
+	inspectAll(ctx, typeDocker, len(containers), func(ctx context.Context, idx int) {
+		ctr := containers[idx]
+		if cached {
+			if info, ok := metadata.get(shortContainerID(ctr.ID)); ok {
+				evts[idx] = event.Event{Info: info, IsCreate: true}
+				return
+			}
+		}
+		start := time.Now()
 		ctrJson, _, err := dc.ContainerInspectWithRaw(ctx, ctr.ID, config.GetWithSize())
+		countInspect(typeDocker, err, start)
 		if err != nil {
 			// Minimum set of infos
 			evts[idx] = event.Event{
 				Info: event.Info{
 					Container: event.Container{
-						Type:        typeDocker.ToCTValue(),
-						ID:          shortContainerID(ctr.ID),
-						Image:       ctr.Image,
-						FullID:      ctr.ID,
-						ImageID:     ctr.ImageID,
-						CreatedTime: nanoSecondsToUnix(ctr.Created),
+						Type:         typeDocker.ToCTValue(),
+						ID:           shortContainerID(ctr.ID),
+						Image:        ctr.Image,
+						FullID:       ctr.ID,
+						ImageID:      ctr.ImageID,
+						CreatedTime:  ctr.Created, // seconds since epoch
+						CreatedAt:    ctr.Created * int64(time.Second),
+						EngineSocket: dc.socket,
+						RootlessUID:  rootlessUID(dc.socket),
 					},
 				},
-				IsCreate: true,
+				IsCreate:  true,
+				IsPartial: true,
 			}
 		} else {
 			evts[idx] = event.Event{
@@ -276,11 +643,17 @@ func (dc *dockerEngine) List(ctx context.Context) ([]event.Event, error) {
 				IsCreate: true,
 			}
 		}
-	}
+	})
 	return evts, nil
 }
 
 func (dc *dockerEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	if err := dc.negotiateAPIVersion(ctx); errors.Is(err, errUnsupportedDockerAPI) {
+		return nil, err
+	} else if err != nil {
+		// The daemon might not be reachable yet: the client negotiates the version on its first request.
+		dc.logger.LogAttrs(ctx, slog.LevelDebug, "failed to negotiate docker API version", slog.String("err", err.Error()))
+	}
 	outCh := make(chan event.Event)
 
 	flts := filters.NewArgs()
@@ -294,58 +667,143 @@ func (dc *dockerEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan
 	if config.IsHookEnabled(config.HookRemove) {
 		flts.Add("event", string(events.ActionDestroy))
 	}
+	if config.IsHookEnabled(config.HookDie) {
+		flts.Add("event", string(events.ActionDie))
+	}
+	// OOM kills are also needed by the die hook, to flag OOM killed containers
+	if config.IsHookEnabled(config.HookDie) || config.IsHookEnabled(config.HookOOM) {
+		flts.Add("event", string(events.ActionOOM))
+	}
+	if config.IsHookEnabled(config.HookPause) {
+		flts.Add("event", string(events.ActionPause))
+		flts.Add("event", string(events.ActionUnPause))
+	}
+	// Image pulls are correlated with the containers created from the pulled images
+	if config.IsHookEnabled(config.HookCreate) || config.IsHookEnabled(config.HookStart) {
+		flts.Add("type", string(events.ImageEventType))
+		flts.Add("event", string(events.ActionPull))
+	}
+	if config.IsHookEnabled(config.HookHealth) {
+		if dc.supportsAPI(dockerHealthAPIVersion) {
+			// Matches all the "health_status: <status>" actions.
+			flts.Add("event", string(events.ActionHealthStatus))
+		} else {
+			dc.logger.LogAttrs(ctx, slog.LevelWarn, "health hook not supported by the docker API version, skipping it", slog.String("version", dc.ClientVersion()))
+		}
+	}
 
-	msgs, _ := dc.Events(ctx, events.ListOptions{Filters: flts})
+	msgs, errs := dc.Events(ctx, events.ListOptions{Filters: flts})
 	wg.Add(1)
+	enr := newEnricher(typeDocker, outCh)
+	exits := make(exitTracker)
 	go func() {
 		defer close(outCh)
 		defer wg.Done()
+		defer enr.wait()
+		bo := newBackoff(reconnectMinBackoff, reconnectMaxBackoff)
+		ep := endpoint{engine: typeDocker, socket: dc.Sock()}
 		for {
-			select {
-			case <-ctx.Done():
+			err := supervise(ctx, dc.logger, string(typeDocker), func() error {
+				return dc.processEvents(ctx, msgs, errs, enr, exits, outCh)
+			})
+			// The events stream dropped (eg: daemon restart):
+			// reconnect and resync containers, since events might have been lost.
+			if err == nil || ctx.Err() != nil || !waitReconnect(ctx, dc.logger, ep, bo, err) {
 				return
-			case msg, ok := <-msgs:
-				if !ok {
-					// msgs has been closed - kill the goroutine
-					return
+			}
+			msgs, errs = dc.Events(ctx, events.ListOptions{Filters: flts})
+			if !resync(ctx, dc.logger, ep, bo, uncached(dc.list), dc.known, outCh) {
+				return
+			}
+		}
+	}()
+	return sampleStats(ctx, wg, dc.logger, dc.sampleUsage, dc.known.forward(ctx, wg, dc.logger, uncached(dc.list), outCh)), nil
+}
+
+// processEvents notifies the events of msgs until ctx is done or the events stream drops,
+// returning the error that dropped it.
+func (dc *dockerEngine) processEvents(ctx context.Context, msgs <-chan events.Message, errs <-chan error, enr *enricher,
+	exits exitTracker, outCh chan<- event.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return streamError(err)
+		case msg, ok := <-msgs:
+			if !ok {
+				// msgs has been closed - kill the goroutine
+				return nil
+			}
+			if msg.Type == events.ImageEventType {
+				if msg.Action == events.ActionPull {
+					// The actor ID is the pulled reference, eg: "nginx:latest"
+					dc.logger.LogAttrs(ctx, config.LevelTrace, "image pull event", slog.String("image", msg.Actor.ID))
+					imagePulls.pulled(msg.Actor.ID, msg.TimeNano)
 				}
-				var (
-					ctrJson container.InspectResponse
-					err     error
-				)
-				switch msg.Action {
-				case events.ActionCreate, events.ActionStart:
-					dc.logger.LogAttrs(ctx, config.LevelTrace, "container create or start event", slog.String("container_id", msg.Actor.ID))
-					ctrJson, _, err = dc.ContainerInspectWithRaw(ctx, msg.Actor.ID, config.GetWithSize())
-					if err == nil {
-						outCh <- event.Event{
-							Info:     dc.ctrToInfo(ctx, ctrJson),
-							IsCreate: true,
-						}
+				break
+			}
+			// Minimum set of infos, sent for ActionDestroy
+			// AND as a fallback whenever ContainerInspectWithRaw fails or is too slow.
+			minimal := event.Info{
+				Container: event.Container{
+					Type:         typeDocker.ToCTValue(),
+					ID:           shortContainerID(msg.Actor.ID),
+					FullID:       msg.Actor.ID,
+					Image:        msg.Actor.Attributes["image"],
+					EngineSocket: dc.socket,
+					RootlessUID:  rootlessUID(dc.socket),
+				},
+			}
+			switch msg.Action {
+			case events.ActionCreate, events.ActionStart:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container create or start event", slog.String("container_id", msg.Actor.ID))
+				// Restarted containers carry the exit status of their last run
+				last, restarted := exits.last(minimal.ID)
+				enr.enrich(ctx, minimal, func(ctx context.Context) (event.Info, error) {
+					ctrJson, _, err := dc.ContainerInspectWithRaw(ctx, msg.Actor.ID, config.GetWithSize())
+					if err != nil {
+						return event.Info{}, err
 					}
-				case events.ActionDestroy:
-					dc.logger.LogAttrs(ctx, config.LevelTrace, "container destroy event", slog.String("container_id", msg.Actor.ID))
-					err = errors.New("inspect useless on action destroy")
-				}
-
-				// This is called for ActionDestroy
-				// AND as a fallback whenever ContainerInspectWithRaw fails.
-				if err != nil {
-					// At least send an event with the minimum set of data
-					outCh <- event.Event{
-						Info: event.Info{
-							Container: event.Container{
-								Type:   typeDocker.ToCTValue(),
-								ID:     shortContainerID(msg.Actor.ID),
-								FullID: msg.Actor.ID,
-								Image:  msg.Actor.Attributes["image"],
-							},
-						},
-						IsCreate: msg.Action != events.ActionDestroy,
+					info := dc.ctrToInfo(ctx, ctrJson)
+					if restarted && info.FinishedAt == 0 {
+						last.attach(&info)
 					}
+					return info, nil
+				})
+			case events.ActionDie:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container die event", slog.String("container_id", msg.Actor.ID))
+				exitCode, _ := strconv.ParseInt(msg.Actor.Attributes["exitCode"], 10, 64)
+				outCh <- exits.died(minimal, exitCode, msg.TimeNano)
+			case events.ActionOOM:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container oom event", slog.String("container_id", msg.Actor.ID))
+				evt := exits.oom(minimal)
+				if config.IsHookEnabled(config.HookOOM) {
+					outCh <- evt
+				}
+			case events.ActionPause:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container pause event", slog.String("container_id", msg.Actor.ID))
+				outCh <- pausedEvent(minimal, msg.TimeNano)
+			case events.ActionUnPause:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container unpause event", slog.String("container_id", msg.Actor.ID))
+				outCh <- unpausedEvent(minimal)
+			case events.ActionDestroy:
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container destroy event", slog.String("container_id", msg.Actor.ID))
+				enr.forget(minimal.ID)
+				exits.removed(&minimal)
+				outCh <- event.Event{
+					Info:     minimal,
+					IsCreate: false,
+				}
+			default:
+				if !strings.HasPrefix(string(msg.Action), string(events.ActionHealthStatus)) {
+					break
+				}
+				dc.logger.LogAttrs(ctx, config.LevelTrace, "container health status event", slog.String("container_id", msg.Actor.ID), slog.String("action", string(msg.Action)))
+				if evt, ok := dc.healthEvent(ctx, minimal, msg); ok {
+					outCh <- evt
 				}
 			}
 		}
-	}()
-	return outCh, nil
+	}
 }
@@ -0,0 +1,151 @@
+package container
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+const defaultDockerContext = "default"
+
+// dockerContext is the docker endpoint of a context stored by the docker CLI.
+// See https://docs.docker.com/engine/manage-resources/contexts/
+type dockerContext struct {
+	Name      string
+	Host      string
+	TLSConfig *tls.Config
+}
+
+type dockerContextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// dockerConfigDir returns the docker CLI configuration directory,
+// ie: $DOCKER_CONFIG or ~/.docker.
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+// dockerContextDir returns the directory holding a context metadata or tls material,
+// named after the sha256 digest of the context name.
+func dockerContextDir(configDir, kind, name string) string {
+	digest := sha256.Sum256([]byte(name))
+	return filepath.Join(configDir, "contexts", kind, hex.EncodeToString(digest[:]))
+}
+
+func loadDockerContextTLS(configDir, name string, skipVerify bool) (*tls.Config, error) {
+	tlsDir := filepath.Join(dockerContextDir(configDir, "tls", name), "docker")
+	if _, err := os.Stat(tlsDir); err != nil {
+		if os.IsNotExist(err) && !skipVerify {
+			// No tls material, nor tls options
+			return nil, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: skipVerify,
+	}
+	if ca, err := os.ReadFile(filepath.Join(tlsDir, "ca.pem")); err == nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse ca of docker context %q", name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	cert, certErr := os.ReadFile(filepath.Join(tlsDir, "cert.pem"))
+	key, keyErr := os.ReadFile(filepath.Join(tlsDir, "key.pem"))
+	if certErr == nil && keyErr == nil {
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate of docker context %q: %w", name, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+	return tlsConfig, nil
+}
+
+// loadEngineTLS builds the TLS config of remote engine sockets from the configured PEM files;
+// unlike docker contexts tls material, configured files must exist.
+func loadEngineTLS(engineTLS config.EngineTLS) (*tls.Config, error) {
+	if !engineTLS.Enabled() {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         engineTLS.ServerName,
+		InsecureSkipVerify: engineTLS.InsecureSkipVerify,
+	}
+	if engineTLS.CA != "" {
+		ca, err := os.ReadFile(engineTLS.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse tls ca %q", engineTLS.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if engineTLS.Cert != "" || engineTLS.Key != "" {
+		keyPair, err := tls.LoadX509KeyPair(engineTLS.Cert, engineTLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+	return tlsConfig, nil
+}
+
+// loadDockerContext resolves the docker endpoint of a context, including its tls material.
+func loadDockerContext(configDir, name string) (*dockerContext, error) {
+	if name == defaultDockerContext {
+		// The default context is not stored; it points to the default docker socket.
+		return nil, errors.New("default docker context is not supported, configure its socket instead")
+	}
+	data, err := os.ReadFile(filepath.Join(dockerContextDir(configDir, "meta", name), "meta.json"))
+	if err != nil {
+		return nil, err
+	}
+	var meta dockerContextMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	endpoint, ok := meta.Endpoints["docker"]
+	if !ok || endpoint.Host == "" {
+		return nil, fmt.Errorf("docker context %q has no docker endpoint", name)
+	}
+	if strings.HasPrefix(endpoint.Host, "ssh://") {
+		return nil, fmt.Errorf("docker context %q: ssh endpoints are not supported", name)
+	}
+
+	tlsConfig, err := loadDockerContextTLS(configDir, name, endpoint.SkipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerContext{
+		Name:      name,
+		Host:      endpoint.Host,
+		TLSConfig: tlsConfig,
+	}, nil
+}
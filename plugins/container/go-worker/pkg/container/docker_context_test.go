@@ -0,0 +1,164 @@
+package container
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func writeDockerContext(t *testing.T, configDir, name, meta string) {
+	metaDir := dockerContextDir(configDir, "meta", name)
+	require.NoError(t, os.MkdirAll(metaDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644))
+}
+
+// writeDockerContextTLS stores a self-signed certificate as ca, client certificate and key of a context.
+func writeDockerContextTLS(t *testing.T, configDir, name string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "docker"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	tlsDir := filepath.Join(dockerContextDir(configDir, "tls", name), "docker")
+	require.NoError(t, os.MkdirAll(tlsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tlsDir, "ca.pem"), certPem, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tlsDir, "cert.pem"), certPem, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tlsDir, "key.pem"), keyPem, 0600))
+}
+
+func TestLoadDockerContext(t *testing.T) {
+	configDir := t.TempDir()
+	writeDockerContext(t, configDir, "plain", `{"Name": "plain", "Endpoints": {"docker": {"Host": "tcp://10.0.0.1:2375"}}}`)
+	writeDockerContext(t, configDir, "secure", `{"Name": "secure", "Endpoints": {"docker": {"Host": "tcp://10.0.0.2:2376"}}}`)
+	writeDockerContextTLS(t, configDir, "secure")
+	writeDockerContext(t, configDir, "insecure", `{"Name": "insecure", "Endpoints": {"docker": {"Host": "tcp://10.0.0.3:2376", "SkipTLSVerify": true}}}`)
+	writeDockerContext(t, configDir, "ssh", `{"Name": "ssh", "Endpoints": {"docker": {"Host": "ssh://user@10.0.0.4"}}}`)
+	writeDockerContext(t, configDir, "k8s", `{"Name": "k8s", "Endpoints": {"kubernetes": {}}}`)
+
+	tCases := map[string]struct {
+		name         string
+		expectedHost string
+		expectTLS    bool
+		expectCert   bool
+		expectSkip   bool
+		expectErr    bool
+	}{
+		"plain": {
+			name:         "plain",
+			expectedHost: "tcp://10.0.0.1:2375",
+		},
+		"tls material": {
+			name:         "secure",
+			expectedHost: "tcp://10.0.0.2:2376",
+			expectTLS:    true,
+			expectCert:   true,
+		},
+		"skip tls verify": {
+			name:         "insecure",
+			expectedHost: "tcp://10.0.0.3:2376",
+			expectTLS:    true,
+			expectSkip:   true,
+		},
+		"ssh endpoint": {
+			name:      "ssh",
+			expectErr: true,
+		},
+		"no docker endpoint": {
+			name:      "k8s",
+			expectErr: true,
+		},
+		"missing context": {
+			name:      "missing",
+			expectErr: true,
+		},
+		"default context": {
+			name:      defaultDockerContext,
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			dockerCtx, err := loadDockerContext(configDir, tc.name)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.name, dockerCtx.Name)
+			assert.Equal(t, tc.expectedHost, dockerCtx.Host)
+			if !tc.expectTLS {
+				assert.Nil(t, dockerCtx.TLSConfig)
+				return
+			}
+			require.NotNil(t, dockerCtx.TLSConfig)
+			assert.Equal(t, tc.expectSkip, dockerCtx.TLSConfig.InsecureSkipVerify)
+			assert.Equal(t, tc.expectCert, dockerCtx.TLSConfig.RootCAs != nil)
+			assert.Equal(t, tc.expectCert, len(dockerCtx.TLSConfig.Certificates) == 1)
+		})
+	}
+}
+
+func TestLoadEngineTLS(t *testing.T) {
+	configDir := t.TempDir()
+	writeDockerContextTLS(t, configDir, "remote")
+	tlsDir := filepath.Join(dockerContextDir(configDir, "tls", "remote"), "docker")
+
+	tlsConfig, err := loadEngineTLS(config.EngineTLS{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = loadEngineTLS(config.EngineTLS{
+		CA:   filepath.Join(tlsDir, "ca.pem"),
+		Cert: filepath.Join(tlsDir, "cert.pem"),
+		Key:  filepath.Join(tlsDir, "key.pem"),
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = loadEngineTLS(config.EngineTLS{ServerName: "docker.internal"})
+	require.NoError(t, err)
+	assert.Equal(t, "docker.internal", tlsConfig.ServerName)
+
+	tlsConfig, err = loadEngineTLS(config.EngineTLS{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Nil(t, tlsConfig.RootCAs)
+
+	// Configured files must exist
+	_, err = loadEngineTLS(config.EngineTLS{CA: filepath.Join(tlsDir, "missing.pem")})
+	assert.Error(t, err)
+	_, err = loadEngineTLS(config.EngineTLS{Cert: filepath.Join(tlsDir, "cert.pem")})
+	assert.Error(t, err)
+	// Not a PEM ca
+	_, err = loadEngineTLS(config.EngineTLS{CA: filepath.Join(tlsDir, "key.pem")})
+	assert.Error(t, err)
+}
@@ -47,6 +47,8 @@ func testDocker(t *testing.T, withFetcher bool) {
 		},
 	}, nil, nil, "test_container")
 	assert.NoError(t, err)
+	ctrInspect, err := dockerClient.ContainerInspect(context.Background(), ctr.ID)
+	assert.NoError(t, err)
 
 	imgInspect, err := dockerClient.ImageInspect(context.Background(), "alpine:3.20.3")
 	assert.NoError(t, err)
@@ -55,26 +57,35 @@ func testDocker(t *testing.T, withFetcher bool) {
 	expectedEvent := event.Event{
 		Info: event.Info{
 			Container: event.Container{
-				Type:           typeDocker.ToCTValue(),
-				ID:             ctr.ID[:shortIDLength],
-				Name:           "test_container",
-				Image:          "alpine:3.20.3",
-				ImageDigest:    "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
-				ImageID:        imageId,
-				ImageRepo:      "alpine",
-				ImageTag:       "3.20.3",
-				User:           "testuser",
-				CPUPeriod:      defaultCpuPeriod,
-				CPUQuota:       2000,
-				CPUShares:      defaultCpuShares,
-				CPUSetCPUCount: 2, // 0-1
-				Env:            []string{"env=env", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
-				FullID:         ctr.ID,
-				Labels:         map[string]string{"foo": "bar"},
-				Privileged:     true,
-				Mounts:         []event.Mount{},
-				PortMappings:   []event.PortMapping{},
-				Size:           -1,
+				Type:            typeDocker.ToCTValue(),
+				ID:              ctr.ID[:shortIDLength],
+				Name:            "test_container",
+				Image:           "alpine:3.20.3",
+				ImageDigest:     "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
+				ImageID:         imageId,
+				ImageRepo:       "alpine",
+				ImageTag:        "3.20.3",
+				ImageRegistry:   "docker.io",
+				ImageSize:       imgInspect.Size,
+				ImageLayers:     1,
+				SeccompProfile:  "unconfined",
+				AppArmorProfile: ctrInspect.AppArmorProfile,
+				SELinuxLabel:    ctrInspect.ProcessLabel,
+				User:            "testuser",
+				CPUPeriod:       defaultCpuPeriod,
+				CPUQuota:        2000,
+				CPUShares:       defaultCpuShares,
+				CPUSetCPUCount:  2, // 0-1
+				Env:             []string{"env=env", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+				FullID:          ctr.ID,
+				Labels:          map[string]string{"foo": "bar"},
+				Privileged:      true,
+				Runtime:         ctrInspect.HostConfig.Runtime,
+				Networks:        dockerNetworks(ctrInspect.NetworkSettings.Networks),
+				Mounts:          []event.Mount{},
+				PortMappings:    []event.PortMapping{},
+				Size:            -1,
+				EngineSocket:    client.DefaultDockerHost,
 			}},
 		IsCreate: true,
 	}
@@ -94,6 +105,8 @@ func testDocker(t *testing.T, withFetcher bool) {
 			found = true
 			// We don't have this before creation
 			expectedEvent.CreatedTime = evt.CreatedTime
+			expectedEvent.CreatedAt = evt.CreatedAt
+			expectedEvent.StartedAt = evt.StartedAt
 			assert.Equal(t, expectedEvent, evt)
 		}
 	}
@@ -115,10 +128,11 @@ func testDocker(t *testing.T, withFetcher bool) {
 	expectedEvent = event.Event{
 		Info: event.Info{
 			Container: event.Container{
-				Type:   typeDocker.ToCTValue(),
-				ID:     ctr.ID[:shortIDLength],
-				FullID: ctr.ID,
-				Image:  "alpine:3.20.3",
+				Type:         typeDocker.ToCTValue(),
+				ID:           ctr.ID[:shortIDLength],
+				FullID:       ctr.ID,
+				Image:        "alpine:3.20.3",
+				EngineSocket: client.DefaultDockerHost,
 			}},
 		IsCreate: false,
 	}
@@ -130,3 +144,77 @@ func testDocker(t *testing.T, withFetcher bool) {
 func TestDocker(t *testing.T) {
 	testDocker(t, false)
 }
+
+func TestDockerHealth(t *testing.T) {
+	tCases := map[string]struct {
+		state          *container.State
+		expectedStatus string
+		expectedOutput string
+	}{
+		"no state": {},
+		"no healthcheck": {
+			state: &container.State{},
+		},
+		"healthy": {
+			state: &container.State{Health: &container.Health{
+				Status: container.Healthy,
+				Log:    []*container.HealthcheckResult{{ExitCode: 0, Output: "ok"}},
+			}},
+			expectedStatus: "healthy",
+		},
+		"unhealthy": {
+			state: &container.State{Health: &container.Health{
+				Status: container.Unhealthy,
+				Log: []*container.HealthcheckResult{
+					{ExitCode: 0, Output: "ok"},
+					{ExitCode: 1, Output: "connection refused\n"},
+				},
+			}},
+			expectedStatus: "unhealthy",
+			expectedOutput: "connection refused",
+		},
+		"starting": {
+			state:          &container.State{Health: &container.Health{Status: container.Starting}},
+			expectedStatus: "starting",
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			status, output := dockerHealth(tc.state)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+func TestDockerMemoryUsage(t *testing.T) {
+	// cgroup v2
+	assert.Equal(t, uint64(60), dockerMemoryUsage(container.MemoryStats{Usage: 100, Stats: map[string]uint64{"inactive_file": 40}}))
+	// cgroup v1
+	assert.Equal(t, uint64(70), dockerMemoryUsage(container.MemoryStats{Usage: 100, Stats: map[string]uint64{"total_inactive_file": 30}}))
+	assert.Equal(t, uint64(100), dockerMemoryUsage(container.MemoryStats{Usage: 100}))
+	assert.Equal(t, uint64(10), dockerMemoryUsage(container.MemoryStats{Usage: 10, Stats: map[string]uint64{"inactive_file": 40}}))
+}
+
+func TestDockerGpuDeviceRequests(t *testing.T) {
+	var gpus gpuDevices
+	gpus.addDeviceRequests([]container.DeviceRequest{
+		// --gpus all
+		{Count: -1, Capabilities: [][]string{{"gpu"}}},
+		// --gpus '"device=1,2"'
+		{DeviceIDs: []string{"1", "2"}, Capabilities: [][]string{{"gpu"}}},
+		// --gpus 2
+		{Driver: "nvidia", Count: 2},
+		// --device nvidia.com/gpu=0
+		{Driver: "cdi", DeviceIDs: []string{"nvidia.com/gpu=0", "vendor.com/net=eth0"}},
+		{Driver: "other", Count: -1, Capabilities: [][]string{{"tpu"}}},
+	})
+	assert.Equal(t, gpuDevices{"all", "1", "2", "0", "nvidia.com/gpu=0"}, gpus)
+}
+
+func TestDockerIsolation(t *testing.T) {
+	assert.Equal(t, isolationHyperV, dockerIsolation(container.IsolationHyperV))
+	assert.Equal(t, isolationProcess, dockerIsolation(container.IsolationProcess))
+	assert.Empty(t, dockerIsolation(container.IsolationDefault))
+	assert.Empty(t, dockerIsolation(""))
+}
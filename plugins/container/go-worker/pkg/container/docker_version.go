@@ -0,0 +1,88 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/client"
+)
+
+const (
+	// dockerMinAPIVersion is the oldest docker API the engine attaches to.
+	dockerMinAPIVersion = "1.21"
+	// dockerHealthAPIVersion is the first docker API supporting containers health checks.
+	dockerHealthAPIVersion = "1.24"
+)
+
+// errUnsupportedDockerAPI is returned when attaching to daemons older than dockerMinAPIVersion.
+var errUnsupportedDockerAPI = errors.New("unsupported docker API version")
+
+// serverAPIVersionRe matches the API version of the daemon in the errors returned to clients using a newer one, eg:
+// "client is newer than server (client API version: 1.24, server API version: 1.22)" from daemons older than 1.24,
+// or "client version 1.50 is too new. Maximum supported API version is 1.43" from newer ones.
+var serverAPIVersionRe = regexp.MustCompile(`(?:server API version: |Maximum supported API version is )([0-9]+\.[0-9]+)`)
+
+// serverAPIVersion returns the API version of the daemon, if err reports that the client one is too new.
+func serverAPIVersion(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	matches := serverAPIVersionRe.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// negotiateAPIVersion negotiates the API version with the daemon, ensuring it is at least dockerMinAPIVersion.
+// Daemons that do not advertise their API version (ie: older than 1.24), or that do not support
+// the version forced through DOCKER_API_VERSION, make the client fall back at the version they report
+// on the first versioned request, instead of failing all requests with "client version too new" errors.
+// The version is only negotiated once, since the client must not be updated while in use:
+// it is done by List and Listen, before the listener goroutines use the client.
+func (dc *dockerEngine) negotiateAPIVersion(ctx context.Context) error {
+	dc.versionMu.Lock()
+	defer dc.versionMu.Unlock()
+	if dc.apiVersion != "" {
+		return nil
+	}
+	ping, err := dc.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	dc.NegotiateAPIVersionPing(ping)
+	if _, err = dc.ServerVersion(ctx); err != nil {
+		version, ok := serverAPIVersion(err)
+		if !ok {
+			return err
+		}
+		if err = client.WithVersion(version)(dc.Client); err != nil {
+			return err
+		}
+	}
+	version := dc.ClientVersion()
+	if versions.LessThan(version, dockerMinAPIVersion) {
+		return fmt.Errorf("%w %s, at least %s is required", errUnsupportedDockerAPI, version, dockerMinAPIVersion)
+	}
+	dc.logger.LogAttrs(ctx, slog.LevelDebug, "negotiated docker API version", slog.String("version", version))
+	dc.apiVersion = version
+	return nil
+}
+
+// negotiatedAPIVersion returns the API version negotiated with the daemon, if done.
+func (dc *dockerEngine) negotiatedAPIVersion() string {
+	dc.versionMu.Lock()
+	defer dc.versionMu.Unlock()
+	return dc.apiVersion
+}
+
+// supportsAPI returns whether the negotiated API version is at least version;
+// before negotiation, the client uses the latest one.
+func (dc *dockerEngine) supportsAPI(version string) bool {
+	clientVersion := dc.ClientVersion()
+	return clientVersion == "" || !versions.LessThan(clientVersion, version)
+}
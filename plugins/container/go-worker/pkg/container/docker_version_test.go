@@ -0,0 +1,84 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAPIVersion(t *testing.T) {
+	version, ok := serverAPIVersion(errors.New("Error response from daemon: client is newer than server (client API version: 1.24, server API version: 1.22)"))
+	assert.True(t, ok)
+	assert.Equal(t, "1.22", version)
+
+	version, ok = serverAPIVersion(errors.New("Error response from daemon: client version 1.50 is too new. Maximum supported API version is 1.43"))
+	assert.True(t, ok)
+	assert.Equal(t, "1.43", version)
+
+	_, ok = serverAPIVersion(errors.New("Cannot connect to the Docker daemon"))
+	assert.False(t, ok)
+	_, ok = serverAPIVersion(nil)
+	assert.False(t, ok)
+}
+
+// fakeDockerDaemon serves the version endpoints of a daemon not advertising its API version,
+// that rejects requests with a newer one.
+func fakeDockerDaemon(t *testing.T, apiVersion string) *dockerEngine {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			_, _ = w.Write([]byte("OK"))
+		case "/v" + apiVersion + "/version":
+			_, _ = w.Write([]byte(`{"ApiVersion": "` + apiVersion + `"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message": "client is newer than server (server API version: ` + apiVersion + `)"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	cl, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithAPIVersionNegotiation())
+	require.NoError(t, err)
+	return &dockerEngine{Client: cl, logger: slog.Default(), socket: srv.URL, known: newKnownContainers()}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	dc := fakeDockerDaemon(t, "1.22")
+	assert.True(t, dc.supportsAPI(dockerHealthAPIVersion))
+
+	require.NoError(t, dc.negotiateAPIVersion(context.Background()))
+	assert.Equal(t, "1.22", dc.ClientVersion())
+	assert.False(t, dc.supportsAPI(dockerHealthAPIVersion))
+
+	// Copies reuse the negotiated version
+	copied, err := dc.copy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.22", copied.(*dockerEngine).ClientVersion())
+
+	dc = fakeDockerDaemon(t, "1.20")
+	assert.ErrorIs(t, dc.negotiateAPIVersion(context.Background()), errUnsupportedDockerAPI)
+}
+
+func TestNegotiateAPIVersionConcurrently(t *testing.T) {
+	dc := fakeDockerDaemon(t, "1.22")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, dc.negotiateAPIVersion(context.Background()))
+			_, err := dc.copy(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, "1.22", dc.negotiatedAPIVersion())
+}
@@ -0,0 +1,200 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// Labels set by the ECS agent on task containers.
+const (
+	ecsTaskArnLabel       = "com.amazonaws.ecs.task-arn"
+	ecsClusterLabel       = "com.amazonaws.ecs.cluster"
+	ecsTaskFamilyLabel    = "com.amazonaws.ecs.task-definition-family"
+	ecsTaskRevisionLabel  = "com.amazonaws.ecs.task-definition-version"
+	ecsMetadataURIEnvName = "ECS_CONTAINER_METADATA_URI_V4"
+)
+
+// ecsMetadata resolves the ECS task metadata of containers from the task metadata endpoint.
+// It is nil, thus disabled, until InitEcsMetadataResolver is called with the lookup enabled.
+var ecsMetadata *ecsResolver
+
+// ecsTask is the subset of the task metadata endpoint (v4) response we report.
+type ecsTask struct {
+	TaskARN     string         `json:"TaskARN"`
+	Cluster     string         `json:"Cluster"`
+	ServiceName string         `json:"ServiceName"`
+	Family      string         `json:"Family"`
+	Revision    string         `json:"Revision"`
+	Containers  []ecsContainer `json:"Containers,omitempty"`
+}
+
+// ecsContainer is a container of an ECS task, as reported by the task metadata endpoint.
+type ecsContainer struct {
+	DockerID    string            `json:"DockerId"`
+	Name        string            `json:"Name"`
+	Image       string            `json:"Image"`
+	ImageID     string            `json:"ImageID"`
+	Labels      map[string]string `json:"Labels"`
+	KnownStatus string            `json:"KnownStatus"`
+	CreatedAt   time.Time         `json:"CreatedAt"`
+	StartedAt   time.Time         `json:"StartedAt"`
+	Limits      struct {
+		// CPU units, ie: 1024 per vCPU
+		CPU float64 `json:"CPU"`
+		// MiB
+		Memory int64 `json:"Memory"`
+	} `json:"Limits"`
+	Networks []struct {
+		NetworkMode   string   `json:"NetworkMode"`
+		IPv4Addresses []string `json:"IPv4Addresses"`
+	} `json:"Networks"`
+}
+
+type ecsEntry struct {
+	task    ecsTask
+	ok      bool
+	expires time.Time
+}
+
+// ecsResolver looks up the metadata of ECS tasks, caching the results by task
+// (failed lookups too, to not hammer the endpoint).
+type ecsResolver struct {
+	client  *http.Client
+	timeout time.Duration
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]ecsEntry
+}
+
+func newEcsResolver(cfg config.EcsMetadataCfg, client *http.Client) *ecsResolver {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &ecsResolver{
+		client:  client,
+		timeout: time.Duration(max(cfg.TimeoutMs, 0)) * time.Millisecond,
+		ttl:     time.Duration(max(cfg.CacheTTLMs, 0)) * time.Millisecond,
+		entries: make(map[string]ecsEntry),
+	}
+}
+
+// InitEcsMetadataResolver sets up the lookup of ECS task metadata from the current config.
+func InitEcsMetadataResolver() {
+	ecsMetadata = newEcsResolver(config.GetEcsMetadata(), http.DefaultClient)
+}
+
+// setEcsTaskMetadata sets the ECS task metadata of a container from its labels, then from the
+// task metadata endpoint advertised in its environment, when the lookup is enabled:
+// only the endpoint reports the service name.
+func setEcsTaskMetadata(ctx context.Context, ctr *event.Container, labels map[string]string, env []string) {
+	ctr.EcsTaskArn = labels[ecsTaskArnLabel]
+	ctr.EcsCluster = ecsClusterName(labels[ecsClusterLabel])
+	ctr.EcsTaskFamily = labels[ecsTaskFamilyLabel]
+	ctr.EcsTaskRevision = labels[ecsTaskRevisionLabel]
+	if ecsMetadata == nil {
+		return
+	}
+	var metadataURI string
+	for _, kv := range env {
+		if val, ok := strings.CutPrefix(kv, ecsMetadataURIEnvName+"="); ok {
+			metadataURI = val
+			break
+		}
+	}
+	if metadataURI == "" {
+		return
+	}
+	task, ok := ecsMetadata.resolve(ctx, ctr.EcsTaskArn, metadataURI)
+	if !ok {
+		return
+	}
+	if task.TaskARN != "" {
+		ctr.EcsTaskArn = task.TaskARN
+	}
+	if task.Cluster != "" {
+		ctr.EcsCluster = ecsClusterName(task.Cluster)
+	}
+	if task.Family != "" {
+		ctr.EcsTaskFamily = task.Family
+	}
+	if task.Revision != "" {
+		ctr.EcsTaskRevision = task.Revision
+	}
+	ctr.EcsServiceName = task.ServiceName
+}
+
+// ecsClusterName returns the name of a cluster, given its name or ARN,
+// eg: "arn:aws:ecs:us-west-2:111122223333:cluster/default".
+func ecsClusterName(cluster string) string {
+	if !strings.HasPrefix(cluster, "arn:") {
+		return cluster
+	}
+	if _, name, ok := strings.Cut(cluster, ":cluster/"); ok {
+		return name
+	}
+	return cluster
+}
+
+// resolve returns the metadata of the task of a container, whose metadata endpoint is metadataURI.
+// Task metadata is cached by task ARN, when known, since it is shared by all the task containers.
+func (r *ecsResolver) resolve(ctx context.Context, taskArn, metadataURI string) (ecsTask, bool) {
+	key := taskArn
+	if key == "" {
+		key = metadataURI
+	}
+
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	r.mu.Unlock()
+	if ok && (r.ttl == 0 || time.Now().Before(entry.expires)) {
+		return entry.task, entry.ok
+	}
+
+	lookupCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		lookupCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	task, err := fetchEcsTask(lookupCtx, r.client, metadataURI)
+	if err != nil && ctx.Err() != nil {
+		// Do not cache lookups interrupted by the caller.
+		return ecsTask{}, false
+	}
+	task.Containers = nil
+
+	r.mu.Lock()
+	r.entries[key] = ecsEntry{task: task, ok: err == nil, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return task, err == nil
+}
+
+// fetchEcsTask retrieves the task metadata from the "/task" path of a container metadata endpoint.
+func fetchEcsTask(ctx context.Context, client *http.Client, metadataURI string) (ecsTask, error) {
+	taskURL := strings.TrimSuffix(metadataURI, "/") + "/task"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, taskURL, nil)
+	if err != nil {
+		return ecsTask{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return ecsTask{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ecsTask{}, fmt.Errorf("unexpected status from %s: %s", taskURL, res.Status)
+	}
+	var task ecsTask
+	if err = json.NewDecoder(res.Body).Decode(&task); err != nil {
+		return ecsTask{}, err
+	}
+	return task, nil
+}
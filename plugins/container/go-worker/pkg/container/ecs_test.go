@@ -0,0 +1,85 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const testEcsTaskArn = "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c"
+
+func TestSetEcsTaskMetadata(t *testing.T) {
+	var taskHits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4/ctr1/task", "/v4/ctr2/task":
+			taskHits.Add(1)
+			_, _ = w.Write([]byte(`{
+				"Cluster": "arn:aws:ecs:us-west-2:111122223333:cluster/default",
+				"TaskARN": "` + testEcsTaskArn + `",
+				"Family": "web",
+				"Revision": "7",
+				"ServiceName": "web-svc"
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	labels := map[string]string{
+		ecsTaskArnLabel:      testEcsTaskArn,
+		ecsClusterLabel:      "default",
+		ecsTaskFamilyLabel:   "web",
+		ecsTaskRevisionLabel: "6",
+	}
+
+	// Lookup disabled: labels only
+	ecsMetadata = nil
+	var ctr event.Container
+	setEcsTaskMetadata(context.Background(), &ctr, labels, []string{ecsMetadataURIEnvName + "=" + srv.URL + "/v4/ctr1"})
+	assert.Equal(t, testEcsTaskArn, ctr.EcsTaskArn)
+	assert.Equal(t, "default", ctr.EcsCluster)
+	assert.Equal(t, "web", ctr.EcsTaskFamily)
+	assert.Equal(t, "6", ctr.EcsTaskRevision)
+	assert.Empty(t, ctr.EcsServiceName)
+	assert.Equal(t, int32(0), taskHits.Load())
+
+	ecsMetadata = newEcsResolver(config.EcsMetadataCfg{Enabled: true, TimeoutMs: 1000}, srv.Client())
+	t.Cleanup(func() { ecsMetadata = nil })
+	for _, uri := range []string{srv.URL + "/v4/ctr1", srv.URL + "/v4/ctr2"} {
+		ctr = event.Container{}
+		setEcsTaskMetadata(context.Background(), &ctr, labels, []string{"PATH=/usr/bin", ecsMetadataURIEnvName + "=" + uri})
+		assert.Equal(t, testEcsTaskArn, ctr.EcsTaskArn)
+		assert.Equal(t, "default", ctr.EcsCluster)
+		assert.Equal(t, "web", ctr.EcsTaskFamily)
+		assert.Equal(t, "7", ctr.EcsTaskRevision)
+		assert.Equal(t, "web-svc", ctr.EcsServiceName)
+	}
+	// Task metadata is shared by the task containers
+	assert.Equal(t, int32(1), taskHits.Load())
+
+	// Failed lookups fall back at labels
+	ctr = event.Container{}
+	setEcsTaskMetadata(context.Background(), &ctr, map[string]string{ecsClusterLabel: "other"}, []string{ecsMetadataURIEnvName + "=" + srv.URL + "/v4/unknown"})
+	assert.Equal(t, "other", ctr.EcsCluster)
+	assert.Empty(t, ctr.EcsServiceName)
+
+	// Not an ECS container
+	ctr = event.Container{}
+	setEcsTaskMetadata(context.Background(), &ctr, nil, []string{"PATH=/usr/bin"})
+	assert.Equal(t, event.Container{}, ctr)
+}
+
+func TestEcsClusterName(t *testing.T) {
+	assert.Equal(t, "default", ecsClusterName("default"))
+	assert.Equal(t, "prod", ecsClusterName("arn:aws:ecs:us-west-2:111122223333:cluster/prod"))
+	assert.Equal(t, "", ecsClusterName(""))
+}
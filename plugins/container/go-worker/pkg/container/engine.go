@@ -1,6 +1,7 @@
 package container
 
 import (
+	"cmp"
 	"context"
 	"encoding/binary"
 	"fmt"
@@ -9,11 +10,14 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/distribution/reference"
+
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 )
@@ -30,6 +34,14 @@ const (
 	typeCri        engineType = "cri"
 	typeCrio       engineType = "cri-o"
 	typeContainerd engineType = "containerd"
+	typeLxc        engineType = "lxc"
+	typeLibvirtLxc engineType = "libvirt_lxc"
+	typeBpm        engineType = "bpm"
+	typeFargate    engineType = "fargate"
+	typeGarden     engineType = "garden"
+	typeApptainer  engineType = "apptainer"
+	typeFixture    engineType = "fixture"
+	typeSimulate   engineType = "simulate"
 )
 
 type engineType string
@@ -40,6 +52,10 @@ func (t engineType) ToCTValue() int {
 	switch t {
 	case typeDocker:
 		return 0
+	case typeLxc:
+		return 1
+	case typeLibvirtLxc:
+		return 2
 	case typePodman:
 		return 11
 	case typeCri:
@@ -48,19 +64,37 @@ func (t engineType) ToCTValue() int {
 		return 7
 	case typeCrio:
 		return 8
+	case typeBpm:
+		return 9
+	case typeFargate:
+		return 12
+	case typeGarden:
+		return 13
+	case typeApptainer:
+		return 14
 	default:
 		return 0xffff // unknown
 	}
 }
 
+// EngineKey identifies the engines generated for an entry of an engine configuration, ie: a socket
+// (along with the sockets matching it, for patterns), a docker context, or the whole engine
+// for the fallback and simulate ones. Engines are attached and detached by key when the configuration is reloaded.
+type EngineKey struct {
+	Engine  string
+	Socket  string
+	Context string
+}
+
 type engineGenerator func(context.Context, *slog.Logger, string) (Engine, error)
 type EngineGenerator func(ctx context.Context) (Engine, error)
 
 // Hooked up by each engine through init()
 var engineGenerators = make(map[engineType]engineGenerator)
 
-func Generators() ([]EngineGenerator, error) {
-	generators := make([]EngineGenerator, 0)
+// KeyedGenerators returns the generators of the engines enabled by the configuration, by EngineKey.
+func KeyedGenerators() (map[EngineKey][]EngineGenerator, error) {
+	generators := make(map[EngineKey][]EngineGenerator)
 
 	c := config.Get()
 	for engineName, engineGen := range engineGenerators {
@@ -68,18 +102,106 @@ func Generators() ([]EngineGenerator, error) {
 		if !ok || !eCfg.Enabled {
 			continue
 		}
+		// Engines panicking while connecting (ie: on a malformed engine response) are not attached.
+		engineGen = recoveredGenerator(engineGen)
+		// The simulate engine generates its containers, without any socket.
+		if engineName == typeSimulate {
+			key := EngineKey{Engine: string(engineName)}
+			generators[key] = append(generators[key], func(ctx context.Context) (Engine, error) {
+				return engineGen(ctx, slog.With("engine", engineName), "")
+			})
+			continue
+		}
+		// Attach to the first responsive socket only, falling back at the next ones, where supported (ie: cri).
+		if eCfg.Fallback && engineName == typeCri {
+			sockets := fallbackSockets(engineName, eCfg.Sockets)
+			key := EngineKey{Engine: string(engineName)}
+			generators[key] = append(generators[key], func(ctx context.Context) (Engine, error) {
+				return newFallbackEngine(ctx, slog.With("engine", engineName), engineName, engineGen, sockets)
+			})
+			continue
+		}
+		// Track resolved socket paths to avoid attaching twice to the same socket
+		// through different paths, even once discovered.
+		resolvedSockets := newSocketSet()
 		// For each specified socket, return a closure to generate its engine
-		for _, socket := range eCfg.Sockets {
+		sockets := expandSockets(engineName, eCfg.Sockets)
+		if eCfg.Rootless && engineName == typeDocker && !slices.Contains(sockets, rootlessDockerSocket) {
+			sockets = append(sockets, rootlessDockerSocket)
+		}
+		for _, socket := range sockets {
+			key := EngineKey{Engine: string(engineName), Socket: socket}
+			if isRemoteSocket(socket) || isNamedPipe(socket) {
+				// Remote endpoints and named pipes are neither on the host filesystem, nor discoverable
+				generators[key] = append(generators[key], func(ctx context.Context) (Engine, error) {
+					return newEngineOrRetry(ctx, slog.With("engine", engineName), engineName, engineGen, socket)
+				})
+				continue
+			}
 			// Properly account for HOST_ROOT env variable
 			socket = filepath.Join(config.GetHostRoot(), socket)
+			if isSocketPattern(socket) {
+				// Attach an engine to each socket already matching the pattern,
+				// and a discovery engine taking care of the ones that will appear later.
+				matches, err := filepath.Glob(socket)
+				if err != nil {
+					continue
+				}
+				for _, match := range matches {
+					if _, ok := resolvedSockets.add(match); !ok {
+						continue
+					}
+					generators[key] = append(generators[key], func(ctx context.Context) (Engine, error) {
+						return newEngineOrRetry(ctx, slog.With("engine", engineName), engineName, engineGen, match)
+					})
+				}
+				generators[key] = append(generators[key], func(_ context.Context) (Engine, error) {
+					return newDiscoveryEngine(slog.With("engine", engineName), engineName, engineGen, socket, matches, resolvedSockets), nil
+				})
+				continue
+			}
+			if _, ok := resolvedSockets.add(socket); !ok {
+				continue
+			}
 			// Even if `stat` returns an err that is not NotExist,
 			// try to generate an engine for the socket.
+			// If the engine is not reachable yet, eg: the container runtime is still starting,
+			// keep retrying in background.
 			if _, statErr := os.Stat(socket); !os.IsNotExist(statErr) {
-				generators = append(generators, func(ctx context.Context) (Engine, error) {
-					return engineGen(ctx, slog.With("engine", engineName), socket)
+				generators[key] = append(generators[key], func(ctx context.Context) (Engine, error) {
+					return newEngineOrRetry(ctx, slog.With("engine", engineName), engineName, engineGen, socket)
+				})
+			} else {
+				// The socket does not exist yet, eg: the container runtime is not running yet;
+				// attach a discovery engine to it, that will attach as soon as it appears.
+				generators[key] = append(generators[key], func(_ context.Context) (Engine, error) {
+					return newDiscoveryEngine(slog.With("engine", engineName), engineName, engineGen, socket, nil, resolvedSockets), nil
 				})
 			}
 		}
+		// Docker engines can also be attached to the endpoints of docker CLI contexts.
+		if engineName == typeDocker {
+			for _, dockerCtx := range eCfg.Contexts {
+				key := EngineKey{Engine: string(engineName), Context: dockerCtx}
+				generators[key] = append(generators[key], func(ctx context.Context) (e Engine, err error) {
+					defer recoverAsError(&err)
+					return newDockerContextEngine(ctx, slog.With("engine", engineName), dockerCtx)
+				})
+			}
+		}
+	}
+	return generators, nil
+}
+
+// Generators returns the generators of all the engines enabled by the configuration.
+func Generators() ([]EngineGenerator, error) {
+	keyed, err := KeyedGenerators()
+	if err != nil {
+		return nil, err
+	}
+	generators := make([]EngineGenerator, 0)
+	for _, keyGenerators := range keyed {
+		generators = append(generators, keyGenerators...)
 	}
 	return generators, nil
 }
@@ -103,6 +225,26 @@ type Engine interface {
 	Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error)
 }
 
+// isRemoteSocket returns whether a socket is a remote endpoint, eg: "tcp://192.168.1.10:2376",
+// or the "http://169.254.170.2/v4/<id>" ECS task metadata endpoint.
+func isRemoteSocket(socket string) bool {
+	return strings.HasPrefix(socket, "tcp://") || strings.HasPrefix(socket, "http://") || strings.HasPrefix(socket, "https://")
+}
+
+// namedPipeScheme prefixes the windows named pipes sockets, eg: "npipe:////./pipe/docker_engine".
+const namedPipeScheme = "npipe://"
+
+// isNamedPipe returns whether a socket is a windows named pipe.
+func isNamedPipe(socket string) bool {
+	return strings.HasPrefix(socket, namedPipeScheme)
+}
+
+// namedPipePath returns the path of a named pipe socket, eg: `\\.\pipe\docker_engine`,
+// for the clients dialing plain paths (ie: containerd).
+func namedPipePath(socket string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(socket, namedPipeScheme), "/", `\`)
+}
+
 func enforceUnixProtocolIfEmpty(socket string) string {
 	base, _ := url.Parse(socket)
 	if base.Scheme == "" {
@@ -116,6 +258,14 @@ func nanoSecondsToUnix(ns int64) int64 {
 	return time.Unix(0, ns).Unix()
 }
 
+// unixNano returns the nanoseconds since epoch of t, or 0 if t is the zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
 // Examples:
 // 1,7 -> 2
 // 1-4,7 -> 4 + 1 -> 5
@@ -156,31 +306,89 @@ func shortContainerID(id string) string {
 	return id
 }
 
-// parsePortBindingHostIP parses the provided address string and returns a numerical representation of it.
-// TODO(ekoops): add IPv6 addresses support.
-func parsePortBindingHostIP(hostIP string) (uint32, error) {
+// isSandboxedRuntime returns whether an OCI runtime runs containers in a sandbox,
+// ie: Kata Containers micro VMs or gVisor user-space kernel, eg: "io.containerd.kata.v2", "runsc".
+func isSandboxedRuntime(runtime string) bool {
+	runtime = strings.ToLower(runtime)
+	return strings.Contains(runtime, "kata") || strings.Contains(runtime, "runsc") || strings.Contains(runtime, "gvisor")
+}
+
+// parsePortBindingHostIP parses the provided address string and returns a numerical representation of it,
+// for IPv4 addresses, or its canonical string representation, for IPv6 ones.
+// IPv4-mapped IPv6 addresses are returned as IPv4 ones, and empty ones, binding on all the host addresses, as 0.
+func parsePortBindingHostIP(hostIP string) (uint32, string, error) {
+	if hostIP == "" {
+		return 0, "", nil
+	}
 	addr, err := netip.ParseAddr(hostIP)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
+	addr = addr.Unmap()
 	if addr.Is6() {
-		// TODO(ekoops): handle IPv6 addresses.
-		return 0, fmt.Errorf("ipv6 addresses are not supported")
+		return 0, addr.String(), nil
 	}
 
 	ipv4Addr := addr.As4()
-	return binary.BigEndian.Uint32(ipv4Addr[:]), nil
+	return binary.BigEndian.Uint32(ipv4Addr[:]), "", nil
+}
+
+// parsePortBindingHostPort parses the provided port string, or range of ports (eg: "8000-8010"),
+// and returns a numerical representation of its first and last ports, that are the same for single ports.
+func parsePortBindingHostPort(port string) (uint16, uint16, error) {
+	startStr, endStr, isRange := strings.Cut(port, "-")
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return uint16(start), uint16(start), nil
+	}
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid port range %q", port)
+	}
+	return uint16(start), uint16(end), nil
 }
 
-// parsePortBindingHostPort parses the provided port string and returns a numerical representation of it.
-func parsePortBindingHostPort(port string) (uint16, error) {
-	convertedPort, err := strconv.ParseUint(port, 10, 16)
+// newPortMapping returns the mapping of a container port, published on a host address and port, or range of ports.
+func newPortMapping(containerPort int, proto, hostIP, hostPort string) (event.PortMapping, error) {
+	parsedHostIP, parsedHostIPv6, err := parsePortBindingHostIP(hostIP)
+	if err != nil {
+		return event.PortMapping{}, err
+	}
+	start, end, err := parsePortBindingHostPort(hostPort)
 	if err != nil {
-		return 0, err
+		return event.PortMapping{}, err
+	}
+	mapping := event.PortMapping{
+		HostIP:        parsedHostIP,
+		HostIPv6:      parsedHostIPv6,
+		HostPort:      start,
+		ContainerPort: containerPort,
+		Protocol:      proto,
 	}
+	if end != start {
+		mapping.HostPortEnd = end
+	}
+	return mapping, nil
+}
 
-	return uint16(convertedPort), nil
+// sortPortMappings sorts port mappings by container port, protocol and host address, since engines report them as maps.
+func sortPortMappings(mappings []event.PortMapping) {
+	slices.SortFunc(mappings, func(a, b event.PortMapping) int {
+		return cmp.Or(
+			cmp.Compare(a.ContainerPort, b.ContainerPort),
+			strings.Compare(a.Protocol, b.Protocol),
+			cmp.Compare(a.HostIP, b.HostIP),
+			strings.Compare(a.HostIPv6, b.HostIPv6),
+			cmp.Compare(a.HostPort, b.HostPort),
+		)
+	})
 }
 
 // parseImageRepoTag parses a container image string and returns the repository and tag.
@@ -220,3 +428,37 @@ func parseImageRepoTag(image string) (repo, tag string) {
 
 	return image[:lastColon], image[lastColon+1:]
 }
+
+// imageReference holds the components of a container image reference.
+type imageReference struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// parseImageReference decomposes a container image reference into its registry, repository, tag and digest.
+// The repository and tag are the ones returned by parseImageRepoTag, ie: as written in the reference,
+// while the registry is the normalized one (ie: "docker.io" for "nginx").
+// References that are not valid (eg: image IDs) only get the repository and tag.
+//
+// Examples:
+//   - "nginx:1.25" -> ("docker.io", "nginx", "1.25", "")
+//   - "registry.example.com:5000/foo/bar:latest@sha256:digest" -> ("registry.example.com:5000", "registry.example.com:5000/foo/bar", "latest", "sha256:digest")
+func parseImageReference(image string) imageReference {
+	var ref imageReference
+	ref.repository, ref.tag = parseImageRepoTag(image)
+	if strings.HasPrefix(image, "sha256:") {
+		// Image ID, that would be parsed as the "sha256" docker hub repository.
+		return ref
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ref
+	}
+	ref.registry = reference.Domain(named)
+	if digested, ok := named.(reference.Digested); ok {
+		ref.digest = digested.Digest().String()
+	}
+	return ref
+}
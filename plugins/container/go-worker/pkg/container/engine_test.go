@@ -1,10 +1,18 @@
 package container
 
 import (
+	"context"
 	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
 	"github.com/docker/docker/client"
 	"github.com/stretchr/testify/assert"
-	"testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 )
 
 func TestEnforceUnixProtocol(t *testing.T) {
@@ -71,6 +79,7 @@ func TestParsePortBindingHostIP(t *testing.T) {
 	tCases := map[string]struct {
 		hostIP          string
 		parsedHostIP    uint32
+		parsedHostIPv6  string
 		successExpected bool
 	}{
 		"127.0.0.1": {
@@ -85,20 +94,40 @@ func TestParsePortBindingHostIP(t *testing.T) {
 		},
 		"IPv6 address": {
 			hostIP:          "fe80::1",
-			parsedHostIP:    0,
-			successExpected: false,
+			parsedHostIPv6:  "fe80::1",
+			successExpected: true,
+		},
+		"IPv6 unspecified address": {
+			hostIP:          "::",
+			parsedHostIPv6:  "::",
+			successExpected: true,
+		},
+		"Non canonical IPv6 address": {
+			hostIP:          "2001:DB8:0:0:0:0:0:1",
+			parsedHostIPv6:  "2001:db8::1",
+			successExpected: true,
+		},
+		"IPv4-mapped IPv6 address": {
+			hostIP:          "::ffff:10.0.0.1",
+			parsedHostIP:    binary.BigEndian.Uint32([]byte{10, 0, 0, 1}),
+			successExpected: true,
+		},
+		"All host addresses": {
+			hostIP:          "",
+			successExpected: true,
 		},
 	}
 
 	for name, tc := range tCases {
 		t.Run(name, func(t *testing.T) {
 			if !tc.successExpected {
-				_, err := parsePortBindingHostIP(tc.hostIP)
+				_, _, err := parsePortBindingHostIP(tc.hostIP)
 				assert.Error(t, err)
 			} else {
-				parsedHostIP, err := parsePortBindingHostIP(tc.hostIP)
+				parsedHostIP, parsedHostIPv6, err := parsePortBindingHostIP(tc.hostIP)
 				assert.NoError(t, err)
 				assert.Equal(t, tc.parsedHostIP, parsedHostIP)
+				assert.Equal(t, tc.parsedHostIPv6, parsedHostIPv6)
 			}
 		})
 	}
@@ -106,14 +135,30 @@ func TestParsePortBindingHostIP(t *testing.T) {
 
 func TestParsePortBindingHostPort(t *testing.T) {
 	tCases := map[string]struct {
-		hostPort        string
-		parsedHostPort  uint16
-		successExpected bool
+		hostPort          string
+		parsedHostPort    uint16
+		parsedHostPortEnd uint16
+		successExpected   bool
 	}{
 		"1000": {
-			hostPort:        "1000",
-			parsedHostPort:  1000,
-			successExpected: true,
+			hostPort:          "1000",
+			parsedHostPort:    1000,
+			parsedHostPortEnd: 1000,
+			successExpected:   true,
+		},
+		"Range": {
+			hostPort:          "8000-8010",
+			parsedHostPort:    8000,
+			parsedHostPortEnd: 8010,
+			successExpected:   true,
+		},
+		"Reversed range": {
+			hostPort:        "8010-8000",
+			successExpected: false,
+		},
+		"Wrong range end": {
+			hostPort:        "8000-",
+			successExpected: false,
 		},
 		"Wrong literal": {
 			hostPort:        "Wrong literal",
@@ -130,17 +175,49 @@ func TestParsePortBindingHostPort(t *testing.T) {
 	for name, tc := range tCases {
 		t.Run(name, func(t *testing.T) {
 			if !tc.successExpected {
-				_, err := parsePortBindingHostPort(tc.hostPort)
+				_, _, err := parsePortBindingHostPort(tc.hostPort)
 				assert.Error(t, err)
 			} else {
-				parsedHostPort, err := parsePortBindingHostPort(tc.hostPort)
+				parsedHostPort, parsedHostPortEnd, err := parsePortBindingHostPort(tc.hostPort)
 				assert.NoError(t, err)
 				assert.Equal(t, tc.parsedHostPort, parsedHostPort)
+				assert.Equal(t, tc.parsedHostPortEnd, parsedHostPortEnd)
 			}
 		})
 	}
 }
 
+func TestPortMappings(t *testing.T) {
+	var portMappings []event.PortMapping
+	for _, binding := range []struct {
+		containerPort int
+		proto         string
+		hostIP        string
+		hostPort      string
+	}{
+		{53, "udp", "::", "5353"},
+		{80, "tcp", "0.0.0.0", "8000-8010"},
+		{53, "udp", "0.0.0.0", "5353"},
+		{53, "tcp", "127.0.0.1", "5353"},
+		{9000, "sctp", "", "9000"},
+	} {
+		portMapping, err := newPortMapping(binding.containerPort, binding.proto, binding.hostIP, binding.hostPort)
+		require.NoError(t, err)
+		portMappings = append(portMappings, portMapping)
+	}
+	_, err := newPortMapping(80, "tcp", "0.0.0.0", "http")
+	assert.Error(t, err)
+
+	sortPortMappings(portMappings)
+	assert.Equal(t, []event.PortMapping{
+		{HostIP: binary.BigEndian.Uint32([]byte{127, 0, 0, 1}), HostPort: 5353, ContainerPort: 53, Protocol: "tcp"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp"},
+		{HostIPv6: "::", HostPort: 5353, ContainerPort: 53, Protocol: "udp"},
+		{HostPort: 8000, HostPortEnd: 8010, ContainerPort: 80, Protocol: "tcp"},
+		{HostPort: 9000, ContainerPort: 9000, Protocol: "sctp"},
+	}, portMappings)
+}
+
 func TestParseImageRepoTag(t *testing.T) {
 	tCases := map[string]struct {
 		image        string
@@ -207,3 +284,124 @@ func TestParseImageRepoTag(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratorsSkipDuplicatedSockets(t *testing.T) {
+	root := t.TempDir()
+	socket := filepath.Join(root, "crio.sock")
+	assert.NoError(t, os.WriteFile(socket, nil, 0o644))
+	link := filepath.Join(root, "link.sock")
+	assert.NoError(t, os.Symlink(socket, link))
+
+	err := config.Load(`{"engines": {"cri": {"enabled": true, "sockets": ["` + socket + `", "` + link + `"]}}}`)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"engines": null}`)
+	})
+
+	generators, err := Generators()
+	assert.NoError(t, err)
+	assert.Len(t, generators, 1)
+}
+
+func TestGeneratorsMissingSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "crio.sock")
+	err := config.Load(`{"engines": {"cri": {"enabled": true, "sockets": ["` + socket + `"]}}}`)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"engines": null}`)
+	})
+
+	// A discovery engine waits for the socket to appear
+	generators, err := Generators()
+	assert.NoError(t, err)
+	require.Len(t, generators, 1)
+	engine, err := generators[0](context.Background())
+	assert.NoError(t, err)
+	assert.IsType(t, &discovery{}, engine)
+	assert.Equal(t, socket, engine.Sock())
+}
+
+func TestGeneratorsRemoteSocket(t *testing.T) {
+	err := config.Load(`{"host_root": "/host", "engines": {"cri": {"enabled": true, "sockets": ["tcp://192.168.1.10:2376"]}}}`)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"host_root": "", "engines": null}`)
+	})
+
+	// Remote sockets are neither prefixed with the host root, nor discovered
+	assert.True(t, isRemoteSocket("tcp://192.168.1.10:2376"))
+	assert.True(t, isRemoteSocket("http://169.254.170.2/v4/cd189a933e5849daa93386466019ab50-2495160603"))
+	assert.False(t, isRemoteSocket("/var/run/docker.sock"))
+	assert.False(t, isRemoteSocket("unix:///var/run/docker.sock"))
+	generators, err := Generators()
+	assert.NoError(t, err)
+	assert.Len(t, generators, 1)
+}
+
+func TestGeneratorsNamedPipe(t *testing.T) {
+	err := config.Load(`{"host_root": "/host", "engines": {"docker": {"enabled": true, "sockets": ["npipe:////./pipe/docker_engine"]}}}`)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"host_root": "", "engines": null}`)
+	})
+
+	// Named pipes are neither prefixed with the host root, nor discovered
+	assert.True(t, isNamedPipe("npipe:////./pipe/docker_engine"))
+	assert.False(t, isNamedPipe("/var/run/docker.sock"))
+	assert.Equal(t, `\\.\pipe\containerd-containerd`, namedPipePath("npipe:////./pipe/containerd-containerd"))
+	generators, err := Generators()
+	assert.NoError(t, err)
+	assert.Len(t, generators, 1)
+}
+
+func TestParseImageReference(t *testing.T) {
+	tCases := map[string]struct {
+		image       string
+		expectedRef imageReference
+	}{
+		"Empty": {
+			image: "",
+		},
+		"Docker hub short name": {
+			image:       "nginx:1.25",
+			expectedRef: imageReference{registry: "docker.io", repository: "nginx", tag: "1.25"},
+		},
+		"Docker hub fully qualified": {
+			image:       "docker.io/library/alpine:3.20.3",
+			expectedRef: imageReference{registry: "docker.io", repository: "docker.io/library/alpine", tag: "3.20.3"},
+		},
+		"Registry with port, tag and digest": {
+			image: "registry.example.com:5000/foo/bar:latest@sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
+			expectedRef: imageReference{
+				registry:   "registry.example.com:5000",
+				repository: "registry.example.com:5000/foo/bar",
+				tag:        "latest",
+				digest:     "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
+			},
+		},
+		"Localhost": {
+			image:       "localhost/myimage",
+			expectedRef: imageReference{registry: "localhost", repository: "localhost/myimage"},
+		},
+		"Image ID": {
+			image:       "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
+			expectedRef: imageReference{repository: "sha256", tag: "1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a"},
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRef, parseImageReference(tc.image))
+		})
+	}
+}
+
+func TestIsSandboxedRuntime(t *testing.T) {
+	assert.True(t, isSandboxedRuntime("io.containerd.kata.v2"))
+	assert.True(t, isSandboxedRuntime("kata-runtime"))
+	assert.True(t, isSandboxedRuntime("runsc"))
+	assert.True(t, isSandboxedRuntime("io.containerd.runsc.v1"))
+	assert.False(t, isSandboxedRuntime("io.containerd.runc.v2"))
+	assert.False(t, isSandboxedRuntime("crun"))
+	assert.False(t, isSandboxedRuntime(""))
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// tlsReloader provides the TLS config of a remote engine endpoint, built from its PEM files,
+// loading them again whenever any of them changes, so that rotated certificates are picked up
+// by new connections without restarting the worker.
+type tlsReloader struct {
+	engineTLS config.EngineTLS
+
+	mu        sync.Mutex
+	tlsConfig *tls.Config
+	// modTimes are the modification times of the PEM files the current config was loaded from.
+	modTimes []time.Time
+}
+
+// newTLSReloader loads the TLS config of an endpoint; its PEM files must exist.
+func newTLSReloader(engineTLS config.EngineTLS) (*tlsReloader, error) {
+	r := &tlsReloader{engineTLS: engineTLS}
+	if _, err := r.config(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// pemModTimes returns the modification times of the configured PEM files;
+// missing files have a zero time.
+func (r *tlsReloader) pemModTimes() []time.Time {
+	files := []string{r.engineTLS.CA, r.engineTLS.Cert, r.engineTLS.Key}
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		if file == "" {
+			continue
+		}
+		if fi, err := os.Stat(file); err == nil {
+			modTimes[i] = fi.ModTime()
+		}
+	}
+	return modTimes
+}
+
+// config returns the current TLS config, reloading the PEM files when they changed.
+// While they cannot be loaded, eg: the certificate got rotated before its key, the last valid config is kept.
+func (r *tlsReloader) config() (*tls.Config, error) {
+	modTimes := r.pemModTimes()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tlsConfig != nil && slices.EqualFunc(modTimes, r.modTimes, time.Time.Equal) {
+		return r.tlsConfig, nil
+	}
+	tlsConfig, err := loadEngineTLS(r.engineTLS)
+	if err != nil {
+		if r.tlsConfig != nil {
+			return r.tlsConfig, nil
+		}
+		return nil, err
+	}
+	r.tlsConfig = tlsConfig
+	r.modTimes = modTimes
+	return tlsConfig, nil
+}
+
+// dialTLS opens a TLS connection to addr with the current TLS config.
+func (r *tlsReloader) dialTLS(ctx context.Context, network, addr string) (net.Conn, error) {
+	tlsConfig, err := r.config()
+	if err != nil {
+		return nil, err
+	}
+	dialer := tls.Dialer{Config: tlsConfig}
+	return dialer.DialContext(ctx, network, addr)
+}
@@ -0,0 +1,147 @@
+package container
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a leaf certificate.
+func (ca *testCA) issue(t *testing.T, serial int64, dnsName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestTLSReloader(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, 2, "docker.internal", x509.ExtKeyUsageServerAuth)
+	serverKeyPair, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+
+	// The daemon requires client certificates, reporting the serial of the presented one
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].SerialNumber.String()))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	engineTLS := config.EngineTLS{
+		CA:   filepath.Join(dir, "ca.pem"),
+		Cert: filepath.Join(dir, "cert.pem"),
+		Key:  filepath.Join(dir, "key.pem"),
+	}
+	writeClientCert := func(serial int64, modTime time.Time) {
+		cert, key := ca.issue(t, serial, "falco", x509.ExtKeyUsageClientAuth)
+		require.NoError(t, os.WriteFile(engineTLS.Cert, cert, 0644))
+		require.NoError(t, os.WriteFile(engineTLS.Key, key, 0600))
+		require.NoError(t, os.Chtimes(engineTLS.Cert, modTime, modTime))
+		require.NoError(t, os.Chtimes(engineTLS.Key, modTime, modTime))
+	}
+	require.NoError(t, os.WriteFile(engineTLS.CA, ca.pem, 0644))
+	writeClientCert(10, time.Now().Add(-time.Minute))
+
+	get := func(r *tlsReloader) (string, error) {
+		cl := &http.Client{Transport: &http.Transport{DialTLSContext: r.dialTLS, DisableKeepAlives: true}}
+		resp, err := cl.Get(srv.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 16)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n]), nil
+	}
+
+	// The daemon certificate is issued for the pinned name, not for its address
+	r, err := newTLSReloader(engineTLS)
+	require.NoError(t, err)
+	_, err = get(r)
+	assert.Error(t, err)
+
+	engineTLS.ServerName = "docker.internal"
+	r, err = newTLSReloader(engineTLS)
+	require.NoError(t, err)
+	serial, err := get(r)
+	require.NoError(t, err)
+	assert.Equal(t, "10", serial)
+
+	// Rotated client certificates are presented by new connections
+	writeClientCert(11, time.Now())
+	serial, err = get(r)
+	require.NoError(t, err)
+	assert.Equal(t, "11", serial)
+
+	// Until the rotation completes, the last valid material is kept
+	require.NoError(t, os.WriteFile(engineTLS.Key, []byte("partial"), 0600))
+	serial, err = get(r)
+	require.NoError(t, err)
+	assert.Equal(t, "11", serial)
+
+	// Configured files must exist at startup
+	_, err = newTLSReloader(config.EngineTLS{CA: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package container
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+type inspectFunc func(ctx context.Context) (event.Info, error)
+
+type inspectResult struct {
+	info event.Info
+	err  error
+}
+
+// enricher sends the infos of containers notified by an events stream,
+// without blocking the stream on slow inspections:
+// when an inspection does not complete within the enrichment timeout,
+// the minimal set of infos is sent right away, followed by an update event
+// carrying the full infos once the inspection completes.
+type enricher struct {
+	engine  engineType
+	outCh   chan<- event.Event
+	timeout time.Duration
+	wg      sync.WaitGroup
+	// mu serializes follow-up updates with the events stream,
+	// so that no update is sent after a container got removed.
+	mu sync.Mutex
+	// pending tracks the inspections still running after the timeout, by container ID.
+	pending map[string]chan inspectResult
+}
+
+func newEnricher(engine engineType, outCh chan<- event.Event) *enricher {
+	return &enricher{
+		engine:  engine,
+		outCh:   outCh,
+		timeout: config.GetEnrichTimeout(),
+		pending: make(map[string]chan inspectResult),
+	}
+}
+
+// enrich sends a create event for a container, with the infos returned by inspect.
+// If inspect fails, the minimal infos are sent instead.
+// Once the worker reached its max number of goroutines, inspect is run synchronously,
+// as if the enrichment timeout was not set.
+func (e *enricher) enrich(ctx context.Context, minimal event.Info, inspect inspectFunc) {
+	inspect = e.guarded(e.counted(recovered(withImagePull(inspect))))
+	if e.timeout <= 0 || !spawnAllowed() {
+		info, err := inspect(ctx)
+		e.send(minimal, info, err)
+		return
+	}
+
+	resCh := make(chan inspectResult, 1)
+	go func() {
+		info, err := inspect(ctx)
+		resCh <- inspectResult{info: info, err: err}
+	}()
+	timer := time.NewTimer(e.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-resCh:
+		e.send(minimal, res.info, res.err)
+		return
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	e.outCh <- event.Event{Info: minimal, IsCreate: true, IsPartial: true}
+	e.mu.Lock()
+	e.pending[minimal.ID] = resCh
+	e.mu.Unlock()
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		var res inspectResult
+		select {
+		case res = <-resCh:
+		case <-ctx.Done():
+			return
+		}
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.pending[minimal.ID] != resCh {
+			// The container got removed (or created again) in the meantime
+			return
+		}
+		delete(e.pending, minimal.ID)
+		if res.err != nil {
+			return
+		}
+		select {
+		case e.outCh <- event.Event{Info: res.info, IsCreate: true, IsUpdate: true}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// guarded wraps inspect to bound it by the inspect timeout of the engine, through its circuit breaker.
+// While the breaker is open, inspect is not called at all, thus the minimal infos are sent.
+func (e *enricher) guarded(inspect inspectFunc) inspectFunc {
+	return func(ctx context.Context) (event.Info, error) {
+		var (
+			info event.Info
+			err  error
+		)
+		guardedInspect(ctx, e.engine, func(ctx context.Context) {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				return
+			}
+			info, err = inspect(ctx)
+		})
+		return info, err
+	}
+}
+
+// counted wraps inspect to account for the inspection, and its latency, in the worker metrics.
+func (e *enricher) counted(inspect inspectFunc) inspectFunc {
+	return func(ctx context.Context) (event.Info, error) {
+		start := time.Now()
+		info, err := inspect(ctx)
+		countInspect(e.engine, err, start)
+		return info, err
+	}
+}
+
+func (e *enricher) send(minimal, info event.Info, err error) {
+	if err != nil {
+		e.outCh <- event.Event{Info: minimal, IsCreate: true, IsPartial: true}
+		return
+	}
+	e.outCh <- event.Event{Info: info, IsCreate: true}
+}
+
+// forget drops the pending update of a container, before sending its remove event.
+func (e *enricher) forget(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.pending, id)
+}
+
+// wait waits for pending updates; it must be called before closing the output channel.
+func (e *enricher) wait() {
+	e.wg.Wait()
+}
@@ -0,0 +1,88 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestEnricher(t *testing.T) {
+	minimal := event.Info{Container: event.Container{ID: "test"}}
+	full := event.Info{Container: event.Container{ID: "test", Name: "test", Image: "alpine"}}
+	slowInspect := func(release <-chan struct{}) inspectFunc {
+		return func(_ context.Context) (event.Info, error) {
+			<-release
+			return full, nil
+		}
+	}
+	failingInspect := func(_ context.Context) (event.Info, error) {
+		return event.Info{}, errors.New("no such container")
+	}
+
+	tCases := map[string]struct {
+		timeout        time.Duration
+		inspect        func(release <-chan struct{}) inspectFunc
+		removed        bool
+		expectedEvents []event.Event
+	}{
+		"no timeout": {
+			inspect:        slowInspect,
+			expectedEvents: []event.Event{{Info: full, IsCreate: true}},
+		},
+		"no timeout, failing inspect": {
+			inspect: func(_ <-chan struct{}) inspectFunc {
+				return failingInspect
+			},
+			expectedEvents: []event.Event{{Info: minimal, IsCreate: true, IsPartial: true}},
+		},
+		"slow inspect": {
+			timeout: 10 * time.Millisecond,
+			inspect: slowInspect,
+			expectedEvents: []event.Event{
+				{Info: minimal, IsCreate: true, IsPartial: true},
+				{Info: full, IsCreate: true, IsUpdate: true},
+			},
+		},
+		"slow inspect of removed container": {
+			timeout: 10 * time.Millisecond,
+			inspect: slowInspect,
+			removed: true,
+			expectedEvents: []event.Event{
+				{Info: minimal, IsCreate: true, IsPartial: true},
+			},
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			outCh := make(chan event.Event, 2)
+			enr := newEnricher(typeDocker, outCh)
+			enr.timeout = tc.timeout
+			release := make(chan struct{})
+			if tc.timeout == 0 {
+				// The inspection is waited for
+				close(release)
+			}
+			enr.enrich(context.Background(), minimal, tc.inspect(release))
+			if tc.removed {
+				enr.forget(minimal.ID)
+			}
+			if tc.timeout > 0 {
+				close(release)
+			}
+			enr.wait()
+			close(outCh)
+
+			evts := make([]event.Event, 0)
+			for evt := range outCh {
+				evts = append(evts, evt)
+			}
+			assert.Equal(t, tc.expectedEvents, evts)
+		})
+	}
+}
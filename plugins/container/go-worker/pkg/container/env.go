@@ -0,0 +1,42 @@
+package container
+
+import (
+	"path"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// redactedEnvValue replaces the values of redacted environment variables.
+const redactedEnvValue = "<redacted>"
+
+// filterEnv applies the configured allowlist and redaction patterns to env,
+// a list of "KEY=value" entries.
+func filterEnv(env []string) []string {
+	cfg := config.GetEnv()
+	if len(env) == 0 || (len(cfg.Allowlist) == 0 && len(cfg.Redact) == 0) {
+		return env
+	}
+	filtered := make([]string, 0, len(env))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if len(cfg.Allowlist) > 0 && !matchPatterns(cfg.Allowlist, key) {
+			continue
+		}
+		if matchPatterns(cfg.Redact, key) {
+			kv = key + "=" + redactedEnvValue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
+
+// matchPatterns returns whether s matches any of the shell glob patterns.
+func matchPatterns(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, s); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func TestFilterEnv(t *testing.T) {
+	env := []string{"PATH=/usr/bin:/bin", "APP_NAME=web", "APP_DB_PASSWORD=hunter2", "DEPLOYMENT_ID=42", "GITHUB_TOKEN=ghp_x", "EMPTY"}
+
+	tCases := map[string]struct {
+		cfg         string
+		env         []string
+		expectedEnv []string
+	}{
+		"no filters": {
+			cfg:         `{"env": {"allowlist": null, "redact": null}}`,
+			env:         env,
+			expectedEnv: env,
+		},
+		"allowlist": {
+			cfg:         `{"env": {"allowlist": ["APP_*", "DEPLOYMENT_ID", "EMPTY"], "redact": null}}`,
+			env:         env,
+			expectedEnv: []string{"APP_NAME=web", "APP_DB_PASSWORD=hunter2", "DEPLOYMENT_ID=42", "EMPTY"},
+		},
+		"redact": {
+			cfg:         `{"env": {"allowlist": null, "redact": ["*PASSWORD*", "*_TOKEN"]}}`,
+			env:         env,
+			expectedEnv: []string{"PATH=/usr/bin:/bin", "APP_NAME=web", "APP_DB_PASSWORD=<redacted>", "DEPLOYMENT_ID=42", "GITHUB_TOKEN=<redacted>", "EMPTY"},
+		},
+		"allowlist and redact": {
+			cfg:         `{"env": {"allowlist": ["APP_*"], "redact": ["*PASSWORD*"]}}`,
+			env:         env,
+			expectedEnv: []string{"APP_NAME=web", "APP_DB_PASSWORD=<redacted>"},
+		},
+		"nil env": {
+			cfg: `{"env": {"allowlist": ["APP_*"], "redact": null}}`,
+		},
+	}
+
+	t.Cleanup(func() {
+		_ = config.Load(`{"env": {"allowlist": null, "redact": null}}`)
+	})
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, config.Load(tc.cfg))
+			assert.Equal(t, tc.expectedEnv, filterEnv(tc.env))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package container
+
+import (
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+type exitStatus struct {
+	code       int64
+	finishedAt int64
+	oomKilled  bool
+}
+
+// attach sets the exit status on the infos of a container.
+func (s exitStatus) attach(info *event.Info) {
+	info.ExitCode = s.code
+	info.FinishedAt = s.finishedAt
+	info.OOMKilled = s.oomKilled
+}
+
+// exitTracker remembers the exit status of dead containers, by container ID,
+// so that it can be attached to their remove event too, or to their start event once restarted.
+// It is only accessed by the goroutine reading an engine events stream.
+type exitTracker map[string]exitStatus
+
+// oom records that a process of a container got killed by the OOM killer,
+// which is notified by engines before the die event of the container,
+// and builds the OOM event of the container, carrying its cached infos, if any.
+func (t exitTracker) oom(minimal event.Info) event.Event {
+	t[minimal.ID] = exitStatus{oomKilled: true}
+	return event.Event{Info: cachedInfo(minimal), IsOOM: true}
+}
+
+// died builds the die event of a container,
+// carrying the cached infos of the container, if any, and its exit status.
+func (t exitTracker) died(minimal event.Info, code, finishedAt int64) event.Event {
+	info := cachedInfo(minimal)
+	// A pending OOM kill has no finished-at timestamp yet; any other entry is a previous run.
+	prev := t[minimal.ID]
+	status := exitStatus{code: code, finishedAt: finishedAt, oomKilled: prev.oomKilled && prev.finishedAt == 0}
+	status.attach(&info)
+	t[minimal.ID] = status
+	return event.Event{Info: info, IsDie: true}
+}
+
+// last returns the exit status of the previous run of a container, if known,
+// to be attached to its infos once restarted.
+func (t exitTracker) last(id string) (exitStatus, bool) {
+	status, ok := t[id]
+	if !ok || status.finishedAt == 0 {
+		return exitStatus{}, false
+	}
+	return status, true
+}
+
+// removed attaches the exit status of a removed container, if known, to its infos.
+func (t exitTracker) removed(info *event.Info) {
+	status, ok := t.last(info.ID)
+	delete(t, info.ID)
+	if !ok {
+		return
+	}
+	status.attach(info)
+}
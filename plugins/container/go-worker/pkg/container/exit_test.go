@@ -0,0 +1,76 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestExitTracker(t *testing.T) {
+	metadata = newMetadataCache(time.Hour, 10)
+	t.Cleanup(func() {
+		metadata = nil
+	})
+	metadata.add(cacheInfo("cached", "cached"))
+
+	exits := make(exitTracker)
+
+	// Die events carry the cached infos, when available
+	evt := exits.died(cacheInfo("cached", ""), 137, 1000)
+	assert.True(t, evt.IsDie)
+	assert.Equal(t, event.KindDied, evt.Kind())
+	assert.Equal(t, "cached", evt.Name)
+	assert.Equal(t, int64(137), evt.ExitCode)
+	assert.Equal(t, int64(1000), evt.FinishedAt)
+
+	// Cached infos are left untouched
+	info, ok := metadata.get("cached")
+	assert.True(t, ok)
+	assert.Zero(t, info.ExitCode)
+
+	evt = exits.died(cacheInfo("uncached", ""), 1, 2000)
+	assert.Equal(t, "uncached", evt.ID)
+	assert.Equal(t, int64(1), evt.ExitCode)
+	assert.False(t, evt.OOMKilled)
+
+	// OOM kills are notified before the die event
+	evt = exits.oom(cacheInfo("oomed", ""))
+	assert.True(t, evt.IsOOM)
+	assert.Equal(t, event.KindOOMKilled, evt.Kind())
+	assert.False(t, evt.OOMKilled)
+	_, ok = exits.last("oomed")
+	assert.False(t, ok)
+	evt = exits.died(cacheInfo("oomed", ""), 137, 3000)
+	assert.True(t, evt.OOMKilled)
+
+	// Restarted containers carry the exit status of their last run
+	status, ok := exits.last("oomed")
+	assert.True(t, ok)
+	restarted := cacheInfo("oomed", "")
+	status.attach(&restarted)
+	assert.Equal(t, int64(137), restarted.ExitCode)
+	assert.Equal(t, int64(3000), restarted.FinishedAt)
+	assert.True(t, restarted.OOMKilled)
+
+	// Further runs are not flagged by previous OOM kills
+	evt = exits.died(cacheInfo("oomed", ""), 0, 4000)
+	assert.False(t, evt.OOMKilled)
+
+	// Remove events carry the exit status of dead containers
+	removed := cacheInfo("cached", "")
+	exits.removed(&removed)
+	assert.Equal(t, int64(137), removed.ExitCode)
+	assert.Equal(t, int64(1000), removed.FinishedAt)
+	assert.NotContains(t, exits, "cached")
+
+	removed = cacheInfo("alive", "")
+	exits.removed(&removed)
+	assert.Zero(t, removed.FinishedAt)
+
+	// OOM events carry the cached infos, when available
+	evt = exits.oom(cacheInfo("cached", ""))
+	assert.Equal(t, "cached", evt.Name)
+}
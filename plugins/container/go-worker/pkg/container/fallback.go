@@ -0,0 +1,237 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// fallback is an engine bound to an ordered list of sockets, eg: the CRI endpoints of both containerd and CRI-O,
+// so that a single configuration works across nodes running either of them, like kubelet --container-runtime-endpoint.
+// It attaches to the first responsive socket; whenever the events stream of the attached engine ends,
+// eg: because the runtime went down, the sockets are probed again from the first one, with an exponential backoff,
+// and the known containers are reconciled with the ones listed by the newly attached engine.
+type fallback struct {
+	logger     *slog.Logger
+	engineType engineType
+	generator  engineGenerator
+	sockets    []string
+	known      *knownContainers
+
+	mu sync.Mutex
+	// engine is the attached engine, nil while probing the sockets.
+	engine Engine
+	// getter is a copy of the attached engine, used by the fetcher engine.
+	getter getter
+}
+
+// fallbackSockets resolves the sockets of a fallback engine, keeping their order:
+// local sockets account for HOST_ROOT env variable, and patterns are replaced by their current matches.
+func fallbackSockets(engine engineType, sockets []string) []string {
+	res := make([]string, 0, len(sockets))
+	for _, socket := range expandSockets(engine, sockets) {
+		if isRemoteSocket(socket) || isNamedPipe(socket) {
+			res = append(res, socket)
+			continue
+		}
+		socket = filepath.Join(config.GetHostRoot(), socket)
+		if isSocketPattern(socket) {
+			matches, _ := filepath.Glob(socket)
+			res = append(res, matches...)
+			continue
+		}
+		res = append(res, socket)
+	}
+	return res
+}
+
+// newFallbackEngine attaches an engine to the first responsive socket; if none is responsive and retries are enabled,
+// the sockets keep being probed in background.
+func newFallbackEngine(ctx context.Context, logger *slog.Logger, engineType engineType, generator engineGenerator,
+	sockets []string) (Engine, error) {
+	f := &fallback{
+		logger:     logger,
+		engineType: engineType,
+		generator:  generator,
+		sockets:    sockets,
+		known:      newKnownContainers(),
+	}
+	if engine := f.probe(ctx); engine != nil {
+		f.attach(engine)
+		return f, nil
+	}
+	if config.GetConnectRetryMaxBackoff() == 0 {
+		return nil, fmt.Errorf("none of the sockets %v is responsive", sockets)
+	}
+	logger.LogAttrs(ctx, slog.LevelWarn, "no responsive socket, probing in background", slog.Any("sockets", sockets))
+	notifyStatus(endpoint{engine: engineType, socket: f.Sock()}, event.EngineStatusDown, fmt.Errorf("none of the sockets %v is responsive", sockets))
+	return f, nil
+}
+
+// probe tries to attach an engine to each socket, in order, returning the first attached one.
+// It returns nil if none is responsive.
+func (f *fallback) probe(ctx context.Context) Engine {
+	for _, socket := range f.sockets {
+		engine, err := f.generator(ctx, f.logger, socket)
+		if err == nil {
+			return engine
+		}
+		f.logger.LogAttrs(ctx, slog.LevelDebug, "failed to attach socket", slog.String("socket", socket), slog.String("err", err.Error()))
+	}
+	return nil
+}
+
+// connect probes the sockets, with an exponential backoff, until one is responsive.
+// It returns nil if ctx got cancelled in the meantime.
+func (f *fallback) connect(ctx context.Context, bo *backoff) Engine {
+	for {
+		timer := time.NewTimer(bo.next())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if engine := f.probe(ctx); engine != nil {
+			return engine
+		}
+	}
+}
+
+func (f *fallback) attach(engine Engine) {
+	var g getter
+	if cp, ok := engine.(copier); ok {
+		if e, _ := cp.copy(context.Background()); e != nil {
+			g = e.(getter)
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.engine = engine
+	f.getter = g
+}
+
+func (f *fallback) detach() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.engine = nil
+	f.getter = nil
+}
+
+func (f *fallback) attached() Engine {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.engine
+}
+
+func (f *fallback) copy(_ context.Context) (Engine, error) {
+	// The attached engine is shared with the fetcher, that will access it through get().
+	return f, nil
+}
+
+func (f *fallback) get(ctx context.Context, containerId string) (*event.Event, error) {
+	f.mu.Lock()
+	g := f.getter
+	f.mu.Unlock()
+	if g == nil {
+		return nil, nil
+	}
+	return g.get(ctx, containerId)
+}
+
+func (f *fallback) Name() string {
+	return string(f.engineType)
+}
+
+// Sock returns the socket of the attached engine, or the first socket while probing.
+func (f *fallback) Sock() string {
+	if engine := f.attached(); engine != nil {
+		return engine.Sock()
+	}
+	if len(f.sockets) == 0 {
+		return ""
+	}
+	return f.sockets[0]
+}
+
+func (f *fallback) List(ctx context.Context) ([]event.Event, error) {
+	engine := f.attached()
+	if engine == nil {
+		// Pre-existing containers are notified once a socket gets attached.
+		return []event.Event{}, nil
+	}
+	evts, err := engine.List(ctx)
+	if err == nil {
+		f.known.seed(evts)
+	}
+	return evts, err
+}
+
+// Listen forwards the events of the attached engine, attaching to the first responsive socket
+// each time its events stream ends.
+func (f *fallback) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer func() {
+			close(outCh)
+			wg.Done()
+		}()
+		send := func(evt event.Event) {
+			f.known.track(evt)
+			select {
+			case outCh <- evt:
+			case <-ctx.Done():
+				// Keep draining until the engine closes its channel.
+			}
+		}
+
+		bo := newBackoff(reconnectMinBackoff, reconnectMaxBackoff)
+		for {
+			engine := f.attached()
+			resync := engine == nil
+			if resync {
+				if engine = f.connect(ctx, bo); engine == nil {
+					return
+				}
+				f.attach(engine)
+			}
+			ch, err := engine.Listen(ctx, wg)
+			if err != nil {
+				f.logger.LogAttrs(ctx, slog.LevelWarn, "failed to listen on socket", slog.String("socket", engine.Sock()), slog.String("err", err.Error()))
+				f.detach()
+				continue
+			}
+			f.logger.LogAttrs(ctx, slog.LevelInfo, "attached socket", slog.String("socket", engine.Sock()))
+			notifyStatus(endpoint{engine: f.engineType, socket: engine.Sock()}, event.EngineStatusUp, nil)
+			bo.reset()
+
+			if resync {
+				// Notify what changed since the previous socket got detached, if any:
+				// pre-existing containers are notified as new ones.
+				if evts, err := listUncached(ctx, engine); err == nil {
+					for _, evt := range f.known.reconcile(evts) {
+						send(evt)
+					}
+				}
+			}
+			for evt := range ch {
+				send(evt)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			f.logger.LogAttrs(ctx, slog.LevelWarn, "events stream ended, probing the sockets again", slog.String("socket", engine.Sock()))
+			notifyStatus(endpoint{engine: f.engineType, socket: engine.Sock()}, event.EngineStatusDown, nil)
+			reconnects.Add(1)
+			f.detach()
+		}
+	}()
+	return outCh, nil
+}
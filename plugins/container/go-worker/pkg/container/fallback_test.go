@@ -0,0 +1,135 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// stoppableEngine is an engine whose events stream ends once stopped, as if its runtime went down.
+type stoppableEngine struct {
+	Engine
+	stopped chan struct{}
+}
+
+func (s *stoppableEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		select {
+		case <-s.stopped:
+		case <-ctx.Done():
+		}
+	}()
+	return s.Engine.Listen(ctx, wg)
+}
+
+func TestFallbackSockets(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, config.Load(`{"host_root": "`+root+`"}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"host_root": ""}`)
+	})
+	dir := filepath.Join(root, "run", "k3s", "containerd")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "containerd.sock"), nil, 0o644))
+
+	// Order is kept, and patterns are replaced by their matches
+	assert.Equal(t, []string{
+		filepath.Join(root, "run", "crio", "crio.sock"),
+		filepath.Join(dir, "containerd.sock"),
+		"tcp://192.168.1.10:10010",
+	}, fallbackSockets(typeCri, []string{"/run/crio/crio.sock", "/run/*/containerd/containerd.sock", "tcp://192.168.1.10:10010"}))
+}
+
+func TestNewFallbackEngine(t *testing.T) {
+	failingGenerator := func(context.Context, *slog.Logger, string) (Engine, error) {
+		return nil, errors.New("connection refused")
+	}
+	sockets := []string{"/run/containerd/containerd.sock", "/run/crio/crio.sock"}
+
+	// Retries are enabled by default
+	engine, err := newFallbackEngine(context.Background(), slog.Default(), typeCri, failingGenerator, sockets)
+	require.NoError(t, err)
+	assert.Equal(t, string(typeCri), engine.Name())
+	assert.Equal(t, sockets[0], engine.Sock())
+	evts, err := engine.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, evts)
+
+	require.NoError(t, config.Load(`{"connect_retry_max_backoff_ms": 0}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"connect_retry_max_backoff_ms": 30000}`)
+	})
+	_, err = newFallbackEngine(context.Background(), slog.Default(), typeCri, failingGenerator, sockets)
+	assert.Error(t, err)
+}
+
+func TestFallbackReprobe(t *testing.T) {
+	// Use the fixture engine, whose "socket" is a directory, as attached engine
+	first := filepath.Join(t.TempDir(), "missing")
+	second := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(second, "ctr.json"), []byte(`{"container": {"id": "second"}}`), 0o644))
+
+	var (
+		mu       sync.Mutex
+		attached *stoppableEngine
+	)
+	generator := func(ctx context.Context, logger *slog.Logger, socket string) (Engine, error) {
+		if _, err := os.Stat(socket); err != nil {
+			return nil, err
+		}
+		engine, err := newFixtureEngine(ctx, logger, socket)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		attached = &stoppableEngine{Engine: engine, stopped: make(chan struct{})}
+		return attached, nil
+	}
+
+	// First socket is not responsive, thus the second one gets attached
+	f, err := newFallbackEngine(context.Background(), slog.Default(), typeFixture, generator, []string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, second, f.Sock())
+	evts, err := f.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, evts, 1)
+	assert.Equal(t, "second", evts[0].ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	ch, err := f.Listen(ctx, &wg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// The first socket becomes responsive, and the attached one goes down:
+	// the first socket gets attached, and the containers are reconciled.
+	require.NoError(t, os.Mkdir(first, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(first, "ctr.json"), []byte(`{"container": {"id": "first"}}`), 0o644))
+	mu.Lock()
+	close(attached.stopped)
+	mu.Unlock()
+
+	got := make(map[string]bool)
+	for range 2 {
+		evt := waitOnChannelOrTimeout(t, ch)
+		got[evt.ID] = evt.IsCreate
+	}
+	assert.Equal(t, map[string]bool{"first": true, "second": false}, got)
+	assert.Equal(t, first, f.Sock())
+}
@@ -0,0 +1,169 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	fargatePollInterval = 5 * time.Second
+	fargateTimeout      = 5 * time.Second
+)
+
+func init() {
+	engineGenerators[typeFargate] = newFargateEngine
+}
+
+// fargateEngine enumerates the containers of the ECS task Falco runs in,
+// through the task metadata endpoint (v4), ie: http://169.254.170.2/v4/<id>.
+// It needs no container runtime socket, that is not exposed to tasks on Fargate.
+type fargateEngine struct {
+	client      *http.Client
+	logger      *slog.Logger
+	metadataURI string
+}
+
+func newFargateEngine(ctx context.Context, logger *slog.Logger, metadataURI string) (Engine, error) {
+	f := &fargateEngine{
+		client:      &http.Client{Timeout: fargateTimeout},
+		logger:      logger,
+		metadataURI: metadataURI,
+	}
+	// Make sure that the endpoint is actually served
+	if _, err := fetchEcsTask(ctx, f.client, f.metadataURI); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fargateEngine) copy(ctx context.Context) (Engine, error) {
+	return newFargateEngine(ctx, f.logger, f.metadataURI)
+}
+
+// ecsTaskID returns the ID of a task, ie: the last segment of its ARN,
+// eg: "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c".
+func ecsTaskID(taskArn string) string {
+	return taskArn[strings.LastIndex(taskArn, "/")+1:]
+}
+
+func (f *fargateEngine) ctrToInfo(task *ecsTask, ctr *ecsContainer) event.Info {
+	imageRepo, imageTag := parseImageRepoTag(ctr.Image)
+	imageRef := parseImageReference(ctr.Image)
+
+	labels := make(map[string]string)
+	for key, val := range ctr.Labels {
+		if len(val) <= config.GetLabelMaxLen() {
+			labels[key] = val
+		}
+	}
+
+	var ip string
+	for _, network := range ctr.Networks {
+		if len(network.IPv4Addresses) > 0 {
+			ip = network.IPv4Addresses[0]
+			break
+		}
+	}
+
+	var createdTime int64
+	if !ctr.CreatedAt.IsZero() {
+		createdTime = ctr.CreatedAt.Unix()
+	}
+
+	cpuShares := int64(defaultCpuShares)
+	if ctr.Limits.CPU > 0 {
+		cpuShares = int64(ctr.Limits.CPU)
+	}
+
+	info := event.Info{
+		Container: event.Container{
+			Type:            typeFargate.ToCTValue(),
+			ID:              ctr.DockerID,
+			Name:            ctr.Name,
+			Image:           ctr.Image,
+			ImageDigest:     imageRef.digest,
+			ImageID:         strings.TrimPrefix(ctr.ImageID, "sha256:"),
+			ImageRepo:       imageRepo,
+			ImageTag:        imageTag,
+			ImageRegistry:   imageRef.registry,
+			CPUPeriod:       defaultCpuPeriod,
+			CPUShares:       cpuShares,
+			CreatedTime:     createdTime,
+			CreatedAt:       unixNano(ctr.CreatedAt),
+			StartedAt:       unixNano(ctr.StartedAt),
+			FullID:          ctr.DockerID,
+			Ip:              ip,
+			Labels:          labels,
+			MemoryLimit:     ctr.Limits.Memory << 20,
+			PortMappings:    []event.PortMapping{},
+			Mounts:          []event.Mount{},
+			Size:            -1,
+			EcsTaskArn:      task.TaskARN,
+			EcsCluster:      ecsClusterName(task.Cluster),
+			EcsServiceName:  task.ServiceName,
+			EcsTaskFamily:   task.Family,
+			EcsTaskRevision: task.Revision,
+		},
+	}
+	// Task containers cgroups are laid out as /ecs/<task id>/<container id>
+	fillCgroupLimits(&info.Container, filepath.Join(config.GetHostRoot(), cgroupV2Root, "ecs", ecsTaskID(task.TaskARN), ctr.DockerID))
+	return info
+}
+
+func (f *fargateEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
+	task, err := fetchEcsTask(ctx, f.client, f.metadataURI)
+	if err != nil {
+		return nil, err
+	}
+	for i := range task.Containers {
+		if ctr := &task.Containers[i]; ctr.DockerID == containerId {
+			return &event.Event{
+				Info:     f.ctrToInfo(&task, ctr),
+				IsCreate: true,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s not found in task %s", containerId, task.TaskARN)
+}
+
+func (f *fargateEngine) Name() string {
+	return string(typeFargate)
+}
+
+func (f *fargateEngine) Sock() string {
+	return f.metadataURI
+}
+
+func (f *fargateEngine) List(ctx context.Context) ([]event.Event, error) {
+	task, err := fetchEcsTask(ctx, f.client, f.metadataURI)
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(task.Containers))
+	for i := range task.Containers {
+		ctr := &task.Containers[i]
+		// Containers not started yet have no ID
+		if ctr.DockerID == "" || ctr.KnownStatus == "STOPPED" {
+			continue
+		}
+		evts = append(evts, event.Event{
+			Info:     f.ctrToInfo(&task, ctr),
+			IsCreate: true,
+		})
+	}
+	return evts, nil
+}
+
+// Listen polls the task metadata endpoint, that has no events API.
+func (f *fargateEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, f.logger, fargatePollInterval, f.List)
+}
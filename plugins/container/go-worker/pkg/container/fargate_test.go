@@ -0,0 +1,123 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const ecsTaskJson = `{
+  "Cluster": "arn:aws:ecs:us-west-2:111122223333:cluster/default",
+  "TaskARN": "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+  "Family": "web",
+  "Revision": "7",
+  "ServiceName": "web-svc",
+  "LaunchType": "FARGATE",
+  "Containers": [
+    {
+      "DockerId": "158d1c8083dd49d6b527399fd6414f5c-2495160603",
+      "Name": "nginx",
+      "DockerName": "nginx",
+      "Image": "public.ecr.aws/nginx/nginx:1.25",
+      "ImageID": "sha256:a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
+      "Labels": {
+        "com.amazonaws.ecs.container-name": "nginx"
+      },
+      "DesiredStatus": "RUNNING",
+      "KnownStatus": "RUNNING",
+      "Limits": {"CPU": 256, "Memory": 512},
+      "CreatedAt": "2024-11-07T10:30:03Z",
+      "StartedAt": "2024-11-07T10:30:04.25Z",
+      "Type": "NORMAL",
+      "Networks": [
+        {"NetworkMode": "awsvpc", "IPv4Addresses": ["10.0.2.106"]}
+      ]
+    },
+    {
+      "DockerId": "158d1c8083dd49d6b527399fd6414f5c-3935541716",
+      "Name": "migrations",
+      "Image": "public.ecr.aws/migrations:latest",
+      "KnownStatus": "STOPPED"
+    },
+    {
+      "Name": "pending",
+      "Image": "public.ecr.aws/pending:latest",
+      "KnownStatus": "PENDING"
+    }
+  ]
+}`
+
+func TestFargate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v4/158d1c8083dd49d6b527399fd6414f5c-1/task" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(ecsTaskJson))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := newFargateEngine(context.Background(), slog.Default(), srv.URL+"/v4/unknown")
+	assert.Error(t, err)
+
+	engine, err := newFargateEngine(context.Background(), slog.Default(), srv.URL+"/v4/158d1c8083dd49d6b527399fd6414f5c-1")
+	require.NoError(t, err)
+
+	expectedEvent := event.Event{
+		Info: event.Info{
+			Container: event.Container{
+				Type:            typeFargate.ToCTValue(),
+				ID:              "158d1c8083dd49d6b527399fd6414f5c-2495160603",
+				Name:            "nginx",
+				Image:           "public.ecr.aws/nginx/nginx:1.25",
+				ImageID:         "a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
+				ImageRepo:       "public.ecr.aws/nginx/nginx",
+				ImageTag:        "1.25",
+				ImageRegistry:   "public.ecr.aws",
+				CPUPeriod:       defaultCpuPeriod,
+				CPUShares:       256,
+				CreatedTime:     1730975403,
+				CreatedAt:       1730975403000000000,
+				StartedAt:       1730975404250000000,
+				FullID:          "158d1c8083dd49d6b527399fd6414f5c-2495160603",
+				Ip:              "10.0.2.106",
+				Labels:          map[string]string{"com.amazonaws.ecs.container-name": "nginx"},
+				MemoryLimit:     512 << 20,
+				PortMappings:    []event.PortMapping{},
+				Mounts:          []event.Mount{},
+				Size:            -1,
+				EcsTaskArn:      "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+				EcsCluster:      "default",
+				EcsServiceName:  "web-svc",
+				EcsTaskFamily:   "web",
+				EcsTaskRevision: "7",
+			},
+		},
+		IsCreate: true,
+	}
+
+	// Stopped and pending containers are skipped
+	evts, err := engine.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, evts, 1)
+	assert.Equal(t, expectedEvent, evts[0])
+
+	evt, err := engine.(getter).get(context.Background(), "158d1c8083dd49d6b527399fd6414f5c-2495160603")
+	require.NoError(t, err)
+	assert.Equal(t, expectedEvent, *evt)
+
+	_, err = engine.(getter).get(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestEcsTaskID(t *testing.T) {
+	assert.Equal(t, "158d1c8083dd49d6b527399fd6414f5c", ecsTaskID("arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c"))
+	assert.Equal(t, "", ecsTaskID(""))
+}
@@ -3,6 +3,8 @@ package container
 import (
 	"context"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
@@ -16,9 +18,24 @@ FetcherChan requests are published through a CGO exposed API: AskForContainerInf
 */
 
 type fetcher struct {
-	getters     []getter
+	mu sync.RWMutex
+	// getters are copies of the attached engines, by the key they were attached with,
+	// tried in attach order.
+	getters     map[EngineKey][]getter
+	keys        []EngineKey
 	ctx         context.Context
 	fetcherChan chan string
+	// lookups are the synchronous lookups in flight, by container ID.
+	lookupsMu sync.Mutex
+	lookups   map[string]*lookup
+}
+
+// lookup is a synchronous lookup in flight, whose result is shared with the
+// concurrent lookups of the same container once done is closed.
+type lookup struct {
+	done chan struct{}
+	evt  event.Event
+	ok   bool
 }
 
 // NewFetcherEngine returns a fetcher engine.
@@ -26,15 +43,31 @@ type fetcher struct {
 // trying all container engines enabled.
 func NewFetcherEngine(_ context.Context, fetcherChan chan string, containerEngines []Engine) Engine {
 	f := fetcher{
-		getters: make([]getter, len(containerEngines)),
+		getters: make(map[EngineKey][]getter),
 		// Since podman relies upon context to store
 		// connection-related info,
 		// we need a unique context for fetcher
 		// to avoid tampering with real podman engine context.
 		ctx:         context.Background(),
 		fetcherChan: fetcherChan,
+		lookups:     make(map[string]*lookup),
 	}
-	for i, engine := range containerEngines {
+	f.Attach(EngineKey{}, containerEngines)
+	return &f
+}
+
+// Attacher is implemented by the fetcher engine, to follow the engines attached and detached
+// when the engines configuration is reloaded.
+type Attacher interface {
+	// Attach adds the engines of key to the ones the containers are fetched from.
+	Attach(key EngineKey, containerEngines []Engine)
+	// Detach removes the engines of key.
+	Detach(key EngineKey)
+}
+
+func (f *fetcher) Attach(key EngineKey, containerEngines []Engine) {
+	getters := make([]getter, 0, len(containerEngines))
+	for _, engine := range containerEngines {
 		copyEngine, ok := engine.(copier)
 		if !ok {
 			// We need all engines to implement the copier interface to be copied by fetcher.
@@ -43,10 +76,35 @@ func NewFetcherEngine(_ context.Context, fetcherChan chan string, containerEngin
 		e, _ := copyEngine.copy(f.ctx)
 		if e != nil {
 			// No type check since Engine interface extends getter.
-			f.getters[i] = e.(getter)
+			getters = append(getters, e.(getter))
 		}
 	}
-	return &f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.getters[key]; !ok {
+		f.keys = append(f.keys, key)
+	}
+	f.getters[key] = getters
+}
+
+func (f *fetcher) Detach(key EngineKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.getters, key)
+	f.keys = slices.DeleteFunc(f.keys, func(k EngineKey) bool {
+		return k == key
+	})
+}
+
+// allGetters returns the getters of all the attached engines.
+func (f *fetcher) allGetters() []getter {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	getters := make([]getter, 0, len(f.keys))
+	for _, key := range f.keys {
+		getters = append(getters, f.getters[key]...)
+	}
+	return getters
 }
 
 func (f *fetcher) Name() string {
@@ -67,8 +125,6 @@ func (f *fetcher) List(_ context.Context) ([]event.Event, error) {
 // a retry is set up every containerFetchRetryInterval until containerFetchRetryTimeout is reached.
 // On success, publish event on output channel.
 func (f *fetcher) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
-	const containerFetchRetryInterval = 30 * time.Millisecond
-	const containerFetchRetryTimeout = 150 * time.Millisecond
 	outCh := make(chan event.Event)
 	wg.Add(1)
 	go func() {
@@ -77,38 +133,118 @@ func (f *fetcher) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.
 			wg.Done()
 		}()
 		containerFirstSeen := make(map[string]time.Time)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case containerId := <-f.fetcherChan:
-				found := false
-				now := time.Now()
-				if containerRequestTime, exists := containerFirstSeen[containerId]; exists {
-					if now.Sub(containerRequestTime) > containerFetchRetryTimeout {
-						delete(containerFirstSeen, containerId)
-						break
-					}
-				} else {
-					containerFirstSeen[containerId] = now
+		supervise(ctx, slog.Default(), "fetcher", func() error {
+			return f.processRequests(ctx, containerFirstSeen, outCh)
+		})
+	}()
+	return outCh, nil
+}
+
+// processRequests inspects the containers published on the fetcher channel until ctx is done.
+func (f *fetcher) processRequests(ctx context.Context, containerFirstSeen map[string]time.Time, outCh chan<- event.Event) error {
+	const containerFetchRetryInterval = 30 * time.Millisecond
+	const containerFetchRetryTimeout = 150 * time.Millisecond
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case containerId := <-f.fetcherChan:
+			if info, ok := metadata.get(containerId); ok {
+				// Already known container, no need to inspect it again
+				delete(containerFirstSeen, containerId)
+				outCh <- event.Event{Info: info, IsCreate: true}
+				break
+			}
+			found := false
+			now := time.Now()
+			if containerRequestTime, exists := containerFirstSeen[containerId]; exists {
+				if now.Sub(containerRequestTime) > containerFetchRetryTimeout {
+					delete(containerFirstSeen, containerId)
+					break
 				}
-				for _, e := range f.getters {
-					evt, _ := e.get(f.ctx, containerId)
-					if evt != nil {
-						outCh <- *evt
-						found = true
-						delete(containerFirstSeen, containerId)
-						break
-					}
+			} else {
+				containerFirstSeen[containerId] = now
+			}
+			for _, e := range f.allGetters() {
+				evt, _ := e.get(f.ctx, containerId)
+				if evt != nil {
+					outCh <- *evt
+					found = true
+					delete(containerFirstSeen, containerId)
+					break
 				}
-				if !found {
-					go func() {
-						time.Sleep(containerFetchRetryInterval)
-						f.fetcherChan <- containerId
-					}()
+			}
+			if !found {
+				if !spawnAllowed() {
+					// Give up the retries instead of piling up goroutines
+					delete(containerFirstSeen, containerId)
+					break
 				}
+				go func() {
+					time.Sleep(containerFetchRetryInterval)
+					f.fetcherChan <- containerId
+				}()
 			}
 		}
+	}
+}
+
+// Looker is implemented by the fetcher engine, to synchronously look up single containers.
+type Looker interface {
+	Lookup(containerID string, timeout time.Duration) (event.Event, bool)
+}
+
+// Lookup synchronously returns the infos of a container, from the metadata cache or
+// trying all container engines enabled, within timeout.
+// As for Listen, the inspection is retried every containerLookupRetryInterval,
+// since the container might not be known by its engine yet.
+// Found containers are cached, so that further lookups do not inspect them again.
+// Concurrent lookups of the same container wait for the one in flight instead of
+// inspecting it again.
+func (f *fetcher) Lookup(containerID string, timeout time.Duration) (event.Event, bool) {
+	const containerLookupRetryInterval = 30 * time.Millisecond
+	countLookup()
+	if info, ok := metadata.get(containerID); ok {
+		return event.Event{Info: info, IsCreate: true}, true
+	}
+	ctx, cancel := context.WithTimeout(f.ctx, timeout)
+	defer cancel()
+	f.lookupsMu.Lock()
+	if l, ok := f.lookups[containerID]; ok {
+		f.lookupsMu.Unlock()
+		select {
+		case <-l.done:
+			if l.ok {
+				return l.evt, true
+			}
+		case <-ctx.Done():
+		}
+		countLookupFailure()
+		return event.Event{}, false
+	}
+	l := &lookup{done: make(chan struct{})}
+	f.lookups[containerID] = l
+	f.lookupsMu.Unlock()
+	defer func() {
+		f.lookupsMu.Lock()
+		delete(f.lookups, containerID)
+		f.lookupsMu.Unlock()
+		close(l.done)
 	}()
-	return outCh, nil
+	for {
+		for _, e := range f.allGetters() {
+			evt, _ := getRecovered(ctx, e, containerID)
+			if evt != nil {
+				CacheEvent(*evt)
+				l.evt, l.ok = *evt, true
+				return *evt, true
+			}
+		}
+		select {
+		case <-ctx.Done():
+			countLookupFailure()
+			return event.Event{}, false
+		case <-time.After(containerLookupRetryInterval):
+		}
+	}
 }
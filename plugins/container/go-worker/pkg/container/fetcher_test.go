@@ -4,7 +4,12 @@ import (
 	"context"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -61,6 +66,8 @@ func testFetcher(t *testing.T, containerEngine Engine, containerId string, expec
 	evt := waitOnChannelOrTimeout(t, listCh)
 	// This needs to be updated on the fly
 	expectedEvent.CreatedTime = evt.CreatedTime
+	expectedEvent.CreatedAt = evt.CreatedAt
+	expectedEvent.StartedAt = evt.StartedAt
 	// In some cases, the env ordering might differ thus we manually check it and then copy it
 	for _, env := range expectedEvent.Env {
 		assert.Contains(t, evt.Env, env)
@@ -68,3 +75,99 @@ func testFetcher(t *testing.T, containerEngine Engine, containerId string, expec
 	expectedEvent.Env = evt.Env
 	assert.Equal(t, expectedEvent, evt)
 }
+
+func TestFetcherLookup(t *testing.T) {
+	dir := t.TempDir()
+	engine, err := newFixtureEngine(context.Background(), slog.Default(), dir)
+	require.NoError(t, err)
+
+	f := NewFetcherEngine(context.Background(), make(chan string), []Engine{engine})
+	looker, ok := f.(Looker)
+	require.True(t, ok)
+
+	// Unknown containers time out
+	lookups, failed := Metric(MetricLookups), Metric(MetricLookupsFailed)
+	_, ok = looker.Lookup("lookup-ctr", 100*time.Millisecond)
+	assert.False(t, ok)
+	assert.Equal(t, lookups+1, Metric(MetricLookups))
+	assert.Equal(t, failed+1, Metric(MetricLookupsFailed))
+
+	// Containers not yet known by their engine are retried until the timeout
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(dir, "lookup.json"), []byte(`{"container": {"type": 0, "id": "lookup-ctr", "name": "lookup"}}`), 0644)
+	}()
+	evt, ok := looker.Lookup("lookup-ctr", 5*time.Second)
+	assert.True(t, ok)
+	assert.True(t, evt.IsCreate)
+	assert.Equal(t, "lookup", evt.Name)
+	assert.Equal(t, failed+1, Metric(MetricLookupsFailed))
+}
+
+func TestFetcherAttach(t *testing.T) {
+	dir := t.TempDir()
+	engine, err := newFixtureEngine(context.Background(), slog.Default(), dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"container": {"type": 0, "id": "attach-ctr-a", "name": "a"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"container": {"type": 0, "id": "attach-ctr-b", "name": "b"}}`), 0644))
+
+	f := NewFetcherEngine(context.Background(), make(chan string), nil)
+	looker := f.(Looker)
+	attacher, ok := f.(Attacher)
+	require.True(t, ok)
+
+	_, ok = looker.Lookup("attach-ctr-a", 100*time.Millisecond)
+	assert.False(t, ok)
+
+	key := EngineKey{Engine: string(typeFixture), Socket: dir}
+	attacher.Attach(key, []Engine{engine})
+	evt, ok := looker.Lookup("attach-ctr-a", 100*time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, "a", evt.Name)
+
+	attacher.Detach(key)
+	_, ok = looker.Lookup("attach-ctr-b", 100*time.Millisecond)
+	assert.False(t, ok)
+}
+
+// blockingGetter counts the inspections, returning the container once released.
+type blockingGetter struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (g *blockingGetter) get(_ context.Context, containerId string) (*event.Event, error) {
+	g.calls.Add(1)
+	<-g.release
+	return &event.Event{Info: cacheInfo(containerId, "inflight"), IsCreate: true}, nil
+}
+
+func TestFetcherLookupInFlight(t *testing.T) {
+	g := &blockingGetter{release: make(chan struct{})}
+	f := NewFetcherEngine(context.Background(), make(chan string), nil).(*fetcher)
+	f.getters[EngineKey{}] = []getter{g}
+
+	var wg sync.WaitGroup
+	results := make([]bool, 3)
+	lookup := func(i int, timeout time.Duration) {
+		defer wg.Done()
+		_, results[i] = f.Lookup("inflight-ctr", timeout)
+	}
+	wg.Add(1)
+	go lookup(0, 5*time.Second)
+	require.Eventually(t, func() bool { return g.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// Lookups of a container in flight wait for it, within their own timeout
+	wg.Add(1)
+	lookup(1, 50*time.Millisecond)
+	assert.False(t, results[1])
+	wg.Add(1)
+	go lookup(2, 5*time.Second)
+	time.Sleep(50 * time.Millisecond)
+
+	close(g.release)
+	wg.Wait()
+	assert.True(t, results[0])
+	assert.True(t, results[2])
+	assert.Equal(t, int32(1), g.calls.Load())
+}
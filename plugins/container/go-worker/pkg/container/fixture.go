@@ -0,0 +1,116 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const fixturePollInterval = 1 * time.Second
+
+func init() {
+	engineGenerators[typeFixture] = newFixtureEngine
+}
+
+// fixtureEngine loads containers metadata from a directory of JSON files,
+// one container per file, using the same format sent by the go-worker, ie:
+//
+//	{"container": {"type": 0, "id": "2400edb296c5", "name": "sharp_poincare", ...}}
+//
+// It allows to deterministically test the plugin, and to inject metadata
+// produced elsewhere on hosts where no container engine can be reached.
+// Files added, updated or removed in the directory are notified as container events.
+type fixtureEngine struct {
+	logger *slog.Logger
+	dir    string
+}
+
+func newFixtureEngine(_ context.Context, logger *slog.Logger, dir string) (Engine, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &fixtureEngine{
+		logger: logger,
+		dir:    dir,
+	}, nil
+}
+
+func (f *fixtureEngine) copy(ctx context.Context) (Engine, error) {
+	return newFixtureEngine(ctx, f.logger, f.dir)
+}
+
+func readFixture(path string) (*event.Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info event.Info
+	if err = json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		return nil, errors.New("missing container id")
+	}
+	if info.FullID == "" {
+		info.FullID = info.ID
+	}
+	return &event.Event{
+		Info:     info,
+		IsCreate: true,
+	}, nil
+}
+
+func (f *fixtureEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
+	evts, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, evt := range evts {
+		if evt.ID == containerId || evt.FullID == containerId {
+			return &evt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fixtureEngine) Name() string {
+	return string(typeFixture)
+}
+
+func (f *fixtureEngine) Sock() string {
+	return f.dir
+}
+
+func (f *fixtureEngine) List(_ context.Context) ([]event.Event, error) {
+	paths, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(paths))
+	for _, path := range paths {
+		evt, err := readFixture(path)
+		if err != nil {
+			f.logger.LogAttrs(context.Background(), slog.LevelWarn, "failed to load fixture", slog.String("path", path), slog.String("err", err.Error()))
+			continue
+		}
+		evts = append(evts, *evt)
+	}
+	return evts, nil
+}
+
+// Listen polls the fixtures directory for changes.
+func (f *fixtureEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, f.logger, fixturePollInterval, f.List)
+}
@@ -0,0 +1,87 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func writeFixture(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func nextFixtureEvent(t *testing.T, ch <-chan event.Event) event.Event {
+	select {
+	case evt := <-ch:
+		return evt
+	case <-time.After(5 * fixturePollInterval):
+		require.FailNow(t, "timed out waiting for fixture event")
+	}
+	return event.Event{}
+}
+
+func TestFixture(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "first.json", `{"container": {"type": 0, "id": "2400edb296c5", "full_id": "2400edb296c5e0e1b4c0ab1d3f9ed7a1e0bd4f3c9d5e2e1f8a7b6c5d4e3f2a1b", "name": "first", "image": "fedora:38"}}`)
+	writeFixture(t, dir, "second.json", `{"container": {"type": 10, "id": "static-ctr", "name": "second"}}`)
+	// Invalid fixtures are skipped
+	writeFixture(t, dir, "noid.json", `{"container": {"name": "noid"}}`)
+	writeFixture(t, dir, "broken.json", `{"container":`)
+	writeFixture(t, dir, "README.txt", `not a fixture`)
+
+	engine, err := newFixtureEngine(context.Background(), slog.Default(), dir)
+	require.NoError(t, err)
+
+	events, err := engine.List(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "2400edb296c5", events[0].ID)
+	assert.Equal(t, "2400edb296c5e0e1b4c0ab1d3f9ed7a1e0bd4f3c9d5e2e1f8a7b6c5d4e3f2a1b", events[0].FullID)
+	assert.Equal(t, "fedora:38", events[0].Image)
+	assert.True(t, events[0].IsCreate)
+	assert.Equal(t, 10, events[1].Type)
+	// FullID defaults to ID
+	assert.Equal(t, "static-ctr", events[1].FullID)
+
+	evt, err := engine.(getter).get(context.Background(), "static-ctr")
+	assert.NoError(t, err)
+	assert.Equal(t, &events[1], evt)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	ch, err := engine.Listen(ctx, &wg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// New fixture
+	writeFixture(t, dir, "third.json", `{"container": {"type": 0, "id": "third-ctr", "name": "third"}}`)
+	evt2 := nextFixtureEvent(t, ch)
+	assert.True(t, evt2.IsCreate)
+	assert.Equal(t, "third-ctr", evt2.ID)
+
+	// Updated fixture
+	writeFixture(t, dir, "second.json", `{"container": {"type": 10, "id": "static-ctr", "name": "second-updated"}}`)
+	evt2 = nextFixtureEvent(t, ch)
+	assert.True(t, evt2.IsCreate)
+	assert.Equal(t, "static-ctr", evt2.ID)
+	assert.Equal(t, "second-updated", evt2.Name)
+
+	// Removed fixture
+	require.NoError(t, os.Remove(filepath.Join(dir, "first.json")))
+	evt2 = nextFixtureEvent(t, ch)
+	assert.False(t, evt2.IsCreate)
+	assert.Equal(t, "2400edb296c5", evt2.ID)
+	assert.Equal(t, "first", evt2.Name)
+}
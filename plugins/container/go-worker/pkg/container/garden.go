@@ -0,0 +1,241 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	// Garden has no events API; poll the containers list instead.
+	gardenPollInterval = 5 * time.Second
+	gardenTimeout      = 5 * time.Second
+
+	// Network properties set by Diego on app instances containers, from the cloud controller.
+	gardenAppGUIDProperty   = "network.app_id"
+	gardenSpaceGUIDProperty = "network.space_id"
+	gardenOrgGUIDProperty   = "network.org_id"
+)
+
+func init() {
+	engineGenerators[typeGarden] = newGardenEngine
+}
+
+// gardenEngine talks with the Garden API, exposed on a unix socket by the garden
+// server of Cloud Foundry Diego cells, to enumerate app instances and tasks containers.
+type gardenEngine struct {
+	client *http.Client
+	logger *slog.Logger
+	socket string
+}
+
+// See https://github.com/cloudfoundry/garden/blob/main/routes/routes.go
+type gardenContainerInfo struct {
+	State         string              `json:"State"`
+	ContainerIP   string              `json:"ContainerIP"`
+	ExternalIP    string              `json:"ExternalIP"`
+	ContainerPath string              `json:"ContainerPath"`
+	Properties    map[string]string   `json:"Properties"`
+	MappedPorts   []gardenPortMapping `json:"MappedPorts"`
+}
+
+type gardenPortMapping struct {
+	HostPort      uint32 `json:"HostPort"`
+	ContainerPort uint32 `json:"ContainerPort"`
+}
+
+type gardenContainerInfoEntry struct {
+	Info gardenContainerInfo `json:"Info"`
+	Err  *struct {
+		Message string `json:"Message"`
+	} `json:"Err"`
+}
+
+type gardenMemoryLimits struct {
+	LimitInBytes int64 `json:"limit_in_bytes"`
+}
+
+type gardenCPULimits struct {
+	Weight        int64 `json:"weight"`
+	LimitInShares int64 `json:"limit_in_shares"`
+}
+
+func newGardenEngine(ctx context.Context, logger *slog.Logger, socket string) (Engine, error) {
+	socketPath := strings.TrimPrefix(socket, "unix://")
+	g := &gardenEngine{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+			Timeout: gardenTimeout,
+		},
+		logger: logger,
+		socket: socket,
+	}
+	// Make sure that the socket is actually served by garden
+	if err := g.request(ctx, "/ping", nil); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *gardenEngine) copy(ctx context.Context) (Engine, error) {
+	return newGardenEngine(ctx, g.logger, g.socket)
+}
+
+// request performs a GET request to the garden API, decoding the response into out, if not nil.
+func (g *gardenEngine) request(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://garden"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("garden request %q failed with status %d", path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// limits returns the memory limit and the cpu shares of a container.
+// Failures are not fatal, since the container may be gone in the meantime.
+func (g *gardenEngine) limits(ctx context.Context, handle string) (int64, int64) {
+	var memoryLimits gardenMemoryLimits
+	if err := g.request(ctx, "/containers/"+url.PathEscape(handle)+"/limits/memory", &memoryLimits); err != nil {
+		g.logger.LogAttrs(ctx, slog.LevelDebug, "failed to get container memory limits",
+			slog.String("container_id", handle), slog.String("err", err.Error()))
+	}
+	var cpuLimits gardenCPULimits
+	if err := g.request(ctx, "/containers/"+url.PathEscape(handle)+"/limits/cpu", &cpuLimits); err != nil {
+		g.logger.LogAttrs(ctx, slog.LevelDebug, "failed to get container cpu limits",
+			slog.String("container_id", handle), slog.String("err", err.Error()))
+	}
+	// Weight superseded LimitInShares, that is still set by older clients.
+	cpuShares := cpuLimits.Weight
+	if cpuShares == 0 {
+		cpuShares = cpuLimits.LimitInShares
+	}
+	if cpuShares == 0 {
+		cpuShares = defaultCpuShares
+	}
+	return memoryLimits.LimitInBytes, cpuShares
+}
+
+func (g *gardenEngine) ctrToInfo(ctx context.Context, handle string, info *gardenContainerInfo) event.Info {
+	labels := make(map[string]string)
+	for key, val := range info.Properties {
+		if len(val) <= config.GetLabelMaxLen() {
+			labels[key] = val
+		}
+	}
+
+	portMappings := make([]event.PortMapping, 0, len(info.MappedPorts))
+	hostIP, _, _ := parsePortBindingHostIP(info.ExternalIP)
+	for _, port := range info.MappedPorts {
+		portMappings = append(portMappings, event.PortMapping{
+			HostIP:        hostIP,
+			HostPort:      uint16(port.HostPort),
+			ContainerPort: int(port.ContainerPort),
+			// Garden only maps tcp ports
+			Protocol: "tcp",
+		})
+	}
+
+	memoryLimit, cpuShares := g.limits(ctx, handle)
+
+	// Handles are app instances or tasks GUIDs: they are not truncated,
+	// since they are the IDs extracted from the containers cgroups.
+	return event.Info{
+		Container: event.Container{
+			Type:         typeGarden.ToCTValue(),
+			ID:           handle,
+			Name:         handle,
+			CPUPeriod:    defaultCpuPeriod,
+			CPUShares:    cpuShares,
+			FullID:       handle,
+			Ip:           info.ContainerIP,
+			Labels:       labels,
+			MemoryLimit:  memoryLimit,
+			Mounts:       []event.Mount{},
+			PortMappings: portMappings,
+			Size:         -1,
+			CfAppGUID:    info.Properties[gardenAppGUIDProperty],
+			CfSpaceGUID:  info.Properties[gardenSpaceGUIDProperty],
+			CfOrgGUID:    info.Properties[gardenOrgGUIDProperty],
+		},
+	}
+}
+
+func (g *gardenEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
+	var info gardenContainerInfo
+	if err := g.request(ctx, "/containers/"+url.PathEscape(containerId)+"/info", &info); err != nil {
+		return nil, err
+	}
+	return &event.Event{
+		Info:     g.ctrToInfo(ctx, containerId, &info),
+		IsCreate: true,
+	}, nil
+}
+
+func (g *gardenEngine) Name() string {
+	return string(typeGarden)
+}
+
+func (g *gardenEngine) Sock() string {
+	return g.socket
+}
+
+func (g *gardenEngine) List(ctx context.Context) ([]event.Event, error) {
+	var handles struct {
+		Handles []string `json:"Handles"`
+	}
+	if err := g.request(ctx, "/containers", &handles); err != nil {
+		return nil, err
+	}
+	if len(handles.Handles) == 0 {
+		return []event.Event{}, nil
+	}
+	var entries map[string]gardenContainerInfoEntry
+	query := url.Values{"handles": {strings.Join(handles.Handles, ",")}}
+	if err := g.request(ctx, "/containers/bulk_info?"+query.Encode(), &entries); err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(entries))
+	for _, handle := range handles.Handles {
+		entry, ok := entries[handle]
+		// Containers destroyed in the meantime are reported with an error
+		if !ok || entry.Err != nil {
+			continue
+		}
+		evts = append(evts, event.Event{
+			Info:     g.ctrToInfo(ctx, handle, &entry.Info),
+			IsCreate: true,
+		})
+	}
+	return evts, nil
+}
+
+// Listen polls the containers list every gardenPollInterval,
+// sending create and remove events for containers that appeared or disappeared since last poll.
+func (g *gardenEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, g.logger, gardenPollInterval, g.List)
+}
@@ -0,0 +1,123 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const gardenContainerInfoJson = `{
+  "State": "active",
+  "HostIP": "10.254.0.1",
+  "ContainerIP": "10.254.0.2",
+  "ExternalIP": "10.0.16.12",
+  "ContainerPath": "/var/vcap/data/garden/depot/9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+  "Properties": {
+    "executor:owner": "executor",
+    "network.app_id": "7c2d1f5e-3a4b-4c6d-8e9f-0a1b2c3d4e5f",
+    "network.space_id": "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+    "network.org_id": "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"
+  },
+  "MappedPorts": [
+    {"HostPort": 61001, "ContainerPort": 8080}
+  ]
+}`
+
+func startFakeGarden(t *testing.T) string {
+	socket := filepath.Join(t.TempDir(), "garden.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/containers", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"Handles": ["9a4b8c2d-1e3f-4a5b-6c7d-8e9f", "destroyed"]}`))
+	})
+	mux.HandleFunc("/containers/bulk_info", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("handles") != "9a4b8c2d-1e3f-4a5b-6c7d-8e9f,destroyed" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "9a4b8c2d-1e3f-4a5b-6c7d-8e9f": {"Info": ` + gardenContainerInfoJson + `},
+  "destroyed": {"Err": {"Type": "ContainerNotFoundError", "Message": "unknown handle: destroyed"}}
+}`))
+	})
+	mux.HandleFunc("/containers/9a4b8c2d-1e3f-4a5b-6c7d-8e9f/info", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(gardenContainerInfoJson))
+	})
+	mux.HandleFunc("/containers/9a4b8c2d-1e3f-4a5b-6c7d-8e9f/limits/memory", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"limit_in_bytes": 1073741824}`))
+	})
+	mux.HandleFunc("/containers/9a4b8c2d-1e3f-4a5b-6c7d-8e9f/limits/cpu", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"weight": 1024, "limit_in_shares": 1024}`))
+	})
+	server := &http.Server{Handler: mux}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+	return socket
+}
+
+func TestGarden(t *testing.T) {
+	socket := startFakeGarden(t)
+
+	engine, err := newGardenEngine(context.Background(), slog.Default(), socket)
+	require.NoError(t, err)
+
+	expectedEvent := event.Event{
+		Info: event.Info{
+			Container: event.Container{
+				Type:      typeGarden.ToCTValue(),
+				ID:        "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+				Name:      "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+				CPUPeriod: defaultCpuPeriod,
+				CPUShares: 1024,
+				FullID:    "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+				Ip:        "10.254.0.2",
+				Labels: map[string]string{
+					"executor:owner":   "executor",
+					"network.app_id":   "7c2d1f5e-3a4b-4c6d-8e9f-0a1b2c3d4e5f",
+					"network.space_id": "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+					"network.org_id":   "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a",
+				},
+				MemoryLimit: 1 << 30,
+				Mounts:      []event.Mount{},
+				PortMappings: []event.PortMapping{
+					{HostIP: 0x0a00100c, HostPort: 61001, ContainerPort: 8080, Protocol: "tcp"},
+				},
+				Size:        -1,
+				CfAppGUID:   "7c2d1f5e-3a4b-4c6d-8e9f-0a1b2c3d4e5f",
+				CfSpaceGUID: "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+				CfOrgGUID:   "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a",
+			},
+		},
+		IsCreate: true,
+	}
+
+	// Containers destroyed in the meantime must be skipped
+	events, err := engine.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, expectedEvent, events[0])
+
+	evt, err := engine.(getter).get(context.Background(), "9a4b8c2d-1e3f-4a5b-6c7d-8e9f")
+	require.NoError(t, err)
+	assert.Equal(t, expectedEvent, *evt)
+
+	_, err = engine.(getter).get(context.Background(), "unknown")
+	assert.Error(t, err)
+}
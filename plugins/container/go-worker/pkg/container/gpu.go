@@ -0,0 +1,80 @@
+package container
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// nvidiaVisibleDevicesEnv requests the nvidia container runtime the GPUs to expose to a container,
+	// eg: "all", "0,1" or "GPU-<uuid>".
+	nvidiaVisibleDevicesEnv = "NVIDIA_VISIBLE_DEVICES"
+	// cdiAnnotationPrefix prefixes the annotations requesting CDI devices,
+	// whose values are comma-separated fully qualified CDI device names, eg: "nvidia.com/gpu=0".
+	cdiAnnotationPrefix = "cdi.k8s.io/"
+	// cdiGpuClass is the class of the CDI devices (ie: "<vendor>/<class>=<name>") exposing GPUs.
+	cdiGpuClass = "gpu"
+)
+
+// gpuDeviceNodeRegex matches the device nodes of nvidia GPUs, of DRM (eg: intel and AMD) GPUs, and the AMD compute node.
+var gpuDeviceNodeRegex = regexp.MustCompile(`^/dev/(nvidia[0-9]+|dri/(card|renderD)[0-9]+|kfd)$`)
+
+// gpuDevices collects the GPU devices requested by, or assigned to, a container, without duplicates.
+// Devices are reported as found in the container metadata, ie:
+//   - "all", indexes or UUIDs, from nvidia runtime requests (eg: "0", "GPU-<uuid>")
+//   - fully qualified CDI device names (eg: "nvidia.com/gpu=0")
+//   - device node paths (eg: "/dev/nvidia0", "/dev/dri/renderD128")
+type gpuDevices []string
+
+func (d *gpuDevices) add(devices ...string) {
+	for _, device := range devices {
+		device = strings.TrimSpace(device)
+		if device != "" && !slices.Contains(*d, device) {
+			*d = append(*d, device)
+		}
+	}
+}
+
+// addEnv adds the GPUs requested through the nvidia runtime environment variable, from env, a list of "KEY=value" entries.
+func (d *gpuDevices) addEnv(env []string) {
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		if key != nvidiaVisibleDevicesEnv || value == "void" || value == "none" {
+			continue
+		}
+		d.add(strings.Split(value, ",")...)
+	}
+}
+
+// addCDI adds the GPUs among CDI devices.
+func (d *gpuDevices) addCDI(names ...string) {
+	for _, name := range names {
+		kind, _, ok := strings.Cut(strings.TrimSpace(name), "=")
+		if !ok {
+			continue
+		}
+		if _, class, _ := strings.Cut(kind, "/"); class == cdiGpuClass {
+			d.add(name)
+		}
+	}
+}
+
+// addCDIAnnotations adds the GPUs among the CDI devices requested through annotations.
+func (d *gpuDevices) addCDIAnnotations(annotations map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		if strings.HasPrefix(key, cdiAnnotationPrefix) {
+			d.addCDI(strings.Split(annotations[key], ",")...)
+		}
+	}
+}
+
+// addDeviceNodes adds the GPU device nodes among the device paths.
+func (d *gpuDevices) addDeviceNodes(paths ...string) {
+	for _, path := range paths {
+		if gpuDeviceNodeRegex.MatchString(path) {
+			d.add(path)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGpuDevices(t *testing.T) {
+	tCases := map[string]struct {
+		env         []string
+		cdi         []string
+		annotations map[string]string
+		devices     []string
+		expected    gpuDevices
+	}{
+		"none": {
+			env:         []string{"PATH=/usr/bin"},
+			annotations: map[string]string{"foo": "bar"},
+			devices:     []string{"/dev/fuse", "/dev/nvidiactl"},
+		},
+		"nvidia runtime env": {
+			env:      []string{"NVIDIA_VISIBLE_DEVICES=0, 1,GPU-fef8089b", "PATH=/usr/bin"},
+			expected: gpuDevices{"0", "1", "GPU-fef8089b"},
+		},
+		"nvidia runtime env without devices": {
+			env: []string{"NVIDIA_VISIBLE_DEVICES=void"},
+		},
+		"cdi devices": {
+			cdi:      []string{"nvidia.com/gpu=all", "vendor.com/net=eth0", "invalid"},
+			expected: gpuDevices{"nvidia.com/gpu=all"},
+		},
+		"cdi annotations": {
+			annotations: map[string]string{
+				"cdi.k8s.io/b": "amd.com/gpu=1",
+				"cdi.k8s.io/a": "nvidia.com/gpu=0,vendor.com/net=eth0",
+				"other":        "nvidia.com/gpu=2",
+			},
+			expected: gpuDevices{"nvidia.com/gpu=0", "amd.com/gpu=1"},
+		},
+		"device nodes": {
+			devices:  []string{"/dev/nvidia0", "/dev/nvidia-uvm", "/dev/dri/renderD128", "/dev/kfd"},
+			expected: gpuDevices{"/dev/nvidia0", "/dev/dri/renderD128", "/dev/kfd"},
+		},
+		"duplicates": {
+			env:      []string{"NVIDIA_VISIBLE_DEVICES=0,0"},
+			cdi:      []string{"nvidia.com/gpu=all"},
+			devices:  []string{"/dev/nvidia0", "/dev/nvidia0"},
+			expected: gpuDevices{"0", "nvidia.com/gpu=all", "/dev/nvidia0"},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var gpus gpuDevices
+			gpus.addEnv(tc.env)
+			gpus.addCDI(tc.cdi...)
+			gpus.addCDIAnnotations(tc.annotations)
+			gpus.addDeviceNodes(tc.devices...)
+			assert.Equal(t, tc.expected, gpus)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package container
+
+import (
+	"strings"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// newHealthProbe returns the probe of a docker-style healthcheck, with the exe and args its command is run with:
+// {"CMD", args...} runs the arguments directly, while {"CMD-SHELL", command} runs the command through the shell.
+// Disabled ({"NONE"}) and inherited ({}) healthchecks have no probe.
+func newHealthProbe(test []string, interval, timeout, startPeriod time.Duration, retries int) *event.HealthProbe {
+	if len(test) < 2 {
+		return nil
+	}
+	probe := &event.HealthProbe{
+		Interval:    int64(interval),
+		Timeout:     int64(timeout),
+		StartPeriod: int64(startPeriod),
+		Retries:     int64(retries),
+	}
+	switch test[0] {
+	case "CMD":
+		probe.Exe = test[1]
+		probe.Args = test[2:]
+	case "CMD-SHELL":
+		probe.Exe = "/bin/sh"
+		probe.Args = []string{"-c", strings.Join(test[1:], " ")}
+	default:
+		return nil
+	}
+	return probe
+}
@@ -0,0 +1,30 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestNewHealthProbe(t *testing.T) {
+	assert.Nil(t, newHealthProbe(nil, 0, 0, 0, 0))
+	assert.Nil(t, newHealthProbe([]string{"NONE"}, 0, 0, 0, 0))
+	assert.Nil(t, newHealthProbe([]string{"UNKNOWN", "true"}, 0, 0, 0, 0))
+
+	assert.Equal(t, &event.HealthProbe{
+		Exe:         "curl",
+		Args:        []string{"-f", "http://localhost/"},
+		Interval:    int64(30 * time.Second),
+		Timeout:     int64(5 * time.Second),
+		StartPeriod: int64(10 * time.Second),
+		Retries:     3,
+	}, newHealthProbe([]string{"CMD", "curl", "-f", "http://localhost/"}, 30*time.Second, 5*time.Second, 10*time.Second, 3))
+
+	assert.Equal(t, &event.HealthProbe{
+		Exe:  "/bin/sh",
+		Args: []string{"-c", "nc -z localhost 5432 || exit 1"},
+	}, newHealthProbe([]string{"CMD-SHELL", "nc -z localhost 5432 || exit 1"}, 0, 0, 0, 0))
+}
@@ -0,0 +1,42 @@
+package container
+
+import (
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// ignored tracks the IDs of the ignored infrastructure containers,
+// since their lifecycle and remove events might not carry their image and name.
+var ignored = newIDSet(idSetMaxEntries)
+
+// matchIgnore returns whether a container is an infrastructure one, ignored by config.
+func matchIgnore(info event.Info) bool {
+	cfg := config.GetIgnore()
+	if len(cfg.Images) == 0 && len(cfg.Names) == 0 {
+		return false
+	}
+	if info.Name != "" && matchPatterns(cfg.Names, info.Name) {
+		return true
+	}
+	if info.Image == "" {
+		return false
+	}
+	repo, _ := parseImageRepoTag(info.Image)
+	return matchPatterns(cfg.Images, info.Image) || matchPatterns(cfg.Images, repo)
+}
+
+// isIgnored returns whether an event concerns an infrastructure container ignored by config,
+// keeping track of the ignored containers for their next events.
+func isIgnored(evt event.Event) bool {
+	switch {
+	case evt.IsDie || evt.IsPause || evt.IsUnpause || evt.IsOOM || evt.IsStats:
+		return ignored.has(evt.ID)
+	case !evt.IsCreate:
+		dropped := ignored.has(evt.ID) || matchIgnore(evt.Info)
+		ignored.set(evt.ID, false)
+		return dropped
+	}
+	dropped := matchIgnore(evt.Info)
+	ignored.set(evt.ID, dropped)
+	return dropped
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func ignoreInfo(id, name, image string) event.Info {
+	return event.Info{Container: event.Container{ID: id, Name: name, Image: image}}
+}
+
+func TestMatchIgnore(t *testing.T) {
+	tCases := map[string]struct {
+		cfg      string
+		info     event.Info
+		expected bool
+	}{
+		"default pause image": {
+			info:     ignoreInfo("pause", "", "registry.k8s.io/pause:3.9"),
+			expected: true,
+		},
+		"default pause image by digest": {
+			info:     ignoreInfo("pause", "", "registry.k8s.io/pause@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"),
+			expected: true,
+		},
+		"default eks pause image": {
+			info:     ignoreInfo("pause", "", "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5"),
+			expected: true,
+		},
+		"workload image": {
+			info:     ignoreInfo("nginx", "", "nginx:1.25"),
+			expected: false,
+		},
+		"name pattern": {
+			cfg:      `{"ignore": {"names": ["k8s_POD_*"]}}`,
+			info:     ignoreInfo("pod", "k8s_POD_coredns", "sha256:da86e6ba6ca1"),
+			expected: true,
+		},
+		"image pattern with tag": {
+			cfg:      `{"ignore": {"images": ["docker.io/library/busybox:1.*"]}}`,
+			info:     ignoreInfo("busybox", "", "docker.io/library/busybox:1.36"),
+			expected: true,
+		},
+		"disabled": {
+			cfg:      `{"ignore": {"images": [], "names": []}}`,
+			info:     ignoreInfo("pause", "", "registry.k8s.io/pause:3.9"),
+			expected: false,
+		},
+	}
+	defaults, err := json.Marshal(config.GetIgnore())
+	require.NoError(t, err)
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			if tc.cfg != "" {
+				require.NoError(t, config.Load(tc.cfg))
+				t.Cleanup(func() {
+					_ = config.Load(fmt.Sprintf(`{"ignore": %s}`, defaults))
+				})
+			}
+			assert.Equal(t, tc.expected, matchIgnore(tc.info))
+		})
+	}
+}
+
+func TestIsSuppressedIgnored(t *testing.T) {
+	pause := event.Event{Info: ignoreInfo("pause", "", "registry.k8s.io/pause:3.9"), IsCreate: true}
+	assert.True(t, IsSuppressed(pause))
+	assert.False(t, IsSuppressed(event.Event{Info: ignoreInfo("nginx", "", "nginx:1.25"), IsCreate: true}))
+
+	// Lifecycle and remove events follow their container, even without its image
+	assert.True(t, IsSuppressed(event.Event{Info: ignoreInfo("pause", "", ""), IsCreate: true, IsDie: true}))
+	assert.True(t, IsSuppressed(event.Event{Info: ignoreInfo("pause", "", "")}))
+	assert.False(t, ignored.has("pause"))
+}
@@ -0,0 +1,50 @@
+//go:build linux
+
+package container
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// watchDir watches dir through inotify, notifying the returned channel
+// whenever an entry gets created (or moved) inside it.
+// The channel is closed once ctx is done.
+func watchDir(ctx context.Context, dir string) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = syscall.InotifyAddWatch(fd, dir, syscall.IN_CREATE|syscall.IN_MOVED_TO); err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+	// Being non-blocking, the fd is handled by the runtime poller:
+	// closing the file unblocks any pending read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-ctx.Done()
+		_ = f.Close()
+	}()
+
+	notifyCh := make(chan struct{}, 1)
+	go func() {
+		defer close(notifyCh)
+		buf := make([]byte, 4096)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			if n > 0 {
+				// Coalesce notifications: the receiver re-scans the directory anyway.
+				select {
+				case notifyCh <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return notifyCh, nil
+}
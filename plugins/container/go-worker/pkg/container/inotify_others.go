@@ -0,0 +1,12 @@
+//go:build !linux
+
+package container
+
+import (
+	"context"
+	"errors"
+)
+
+func watchDir(_ context.Context, _ string) (<-chan struct{}, error) {
+	return nil, errors.New("inotify is only supported on linux")
+}
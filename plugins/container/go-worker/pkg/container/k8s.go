@@ -0,0 +1,101 @@
+package container
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// Labels set by the kubelet on pod containers and sandboxes,
+// through dockershim (cri-dockerd), containerd and CRI-O.
+const (
+	k8sPodNameLabel       = "io.kubernetes.pod.name"
+	k8sPodNamespaceLabel  = "io.kubernetes.pod.namespace"
+	k8sPodUIDLabel        = "io.kubernetes.pod.uid"
+	k8sContainerNameLabel = "io.kubernetes.container.name"
+	k8sSandboxIDLabel     = "io.kubernetes.sandbox.id"
+)
+
+// Labels set by the workload controllers on the pods they own; CRI runtimes
+// report them among the sandbox labels.
+const (
+	k8sPodTemplateHashLabel       = "pod-template-hash"
+	k8sControllerRevisionLabel    = "controller-revision-hash"
+	k8sPodTemplateGenerationLabel = "pod-template-generation"
+	k8sStatefulSetPodNameLabel    = "statefulset.kubernetes.io/pod-name"
+	k8sJobNameLabel               = "batch.kubernetes.io/job-name"
+	k8sLegacyJobNameLabel         = "job-name"
+)
+
+// Kinds of the workloads owning pods.
+const (
+	k8sDeploymentKind  = "Deployment"
+	k8sStatefulSetKind = "StatefulSet"
+	k8sDaemonSetKind   = "DaemonSet"
+	k8sJobKind         = "Job"
+)
+
+// k8sDeploymentPodNameRe matches the names of the pods of deployments, made of the deployment name,
+// the pod template hash and a random suffix, both made of the characters of generated names.
+var k8sDeploymentPodNameRe = regexp.MustCompile(`^(.+)-[bcdfghjklmnpqrstvwxz2456789]{6,10}-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+
+// setK8sPodMetadata sets the pod metadata of a kubernetes container from its labels,
+// falling back at its sandbox ones. Unlike labels, they are never dropped because of their length.
+func setK8sPodMetadata(ctr *event.Container, labels, sandboxLabels map[string]string) {
+	lookup := func(key string) string {
+		if val := labels[key]; val != "" {
+			return val
+		}
+		return sandboxLabels[key]
+	}
+	ctr.PodName = lookup(k8sPodNameLabel)
+	ctr.PodNamespace = lookup(k8sPodNamespaceLabel)
+	ctr.PodUID = lookup(k8sPodUIDLabel)
+	// Sandboxes have no container name
+	ctr.K8sContainerName = labels[k8sContainerNameLabel]
+	ctr.K8sWorkloadKind, ctr.K8sWorkloadName = k8sPodWorkload(ctr.PodName, lookup)
+}
+
+// k8sPodWorkload infers the kind and name of the workload owning a pod, from the labels set by its controller,
+// falling back at the pod name for deployments when labels are not available, eg: with dockershim.
+// Bare pods and pods of unknown controllers have no workload.
+func k8sPodWorkload(podName string, lookup func(key string) string) (string, string) {
+	if podName == "" {
+		return "", ""
+	}
+	// Drops the random suffix of generated pod names
+	trimSuffix := func(name string) string {
+		if idx := strings.LastIndexByte(name, '-'); idx > 0 {
+			return name[:idx]
+		}
+		return ""
+	}
+	if jobName := lookup(k8sJobNameLabel); jobName != "" {
+		return k8sJobKind, jobName
+	}
+	if jobName := lookup(k8sLegacyJobNameLabel); jobName != "" {
+		return k8sJobKind, jobName
+	}
+	if lookup(k8sStatefulSetPodNameLabel) == podName {
+		// Pods of statefulsets are named after their ordinal
+		if name := trimSuffix(podName); name != "" {
+			return k8sStatefulSetKind, name
+		}
+	}
+	if hash := lookup(k8sPodTemplateHashLabel); hash != "" {
+		if name, ok := strings.CutSuffix(trimSuffix(podName), "-"+hash); ok && name != "" {
+			return k8sDeploymentKind, name
+		}
+		return "", ""
+	}
+	if lookup(k8sControllerRevisionLabel) != "" && lookup(k8sPodTemplateGenerationLabel) != "" {
+		if name := trimSuffix(podName); name != "" {
+			return k8sDaemonSetKind, name
+		}
+	}
+	if matches := k8sDeploymentPodNameRe.FindStringSubmatch(podName); matches != nil && lookup(k8sControllerRevisionLabel) == "" {
+		return k8sDeploymentKind, matches[1]
+	}
+	return "", ""
+}
@@ -0,0 +1,131 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetK8sPodMetadata(t *testing.T) {
+	tCases := map[string]struct {
+		labels        map[string]string
+		sandboxLabels map[string]string
+		expected      event.Container
+	}{
+		"not a kubernetes container": {
+			labels: map[string]string{"foo": "bar"},
+		},
+		"from container labels": {
+			labels: map[string]string{
+				"io.kubernetes.pod.name":       "nginx-7c5ddbdf54-2xkqv",
+				"io.kubernetes.pod.namespace":  "default",
+				"io.kubernetes.pod.uid":        "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e",
+				"io.kubernetes.container.name": "nginx",
+			},
+			expected: event.Container{
+				PodName:          "nginx-7c5ddbdf54-2xkqv",
+				PodNamespace:     "default",
+				PodUID:           "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e",
+				K8sContainerName: "nginx",
+				K8sWorkloadKind:  "Deployment",
+				K8sWorkloadName:  "nginx",
+			},
+		},
+		"from sandbox labels": {
+			labels: map[string]string{"io.kubernetes.container.name": "nginx"},
+			sandboxLabels: map[string]string{
+				"io.kubernetes.pod.name":      "nginx-7c5ddbdf54-2xkqv",
+				"io.kubernetes.pod.namespace": "default",
+				"io.kubernetes.pod.uid":       "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e",
+				// Only read from the container labels
+				"io.kubernetes.container.name": "POD",
+			},
+			expected: event.Container{
+				PodName:          "nginx-7c5ddbdf54-2xkqv",
+				PodNamespace:     "default",
+				PodUID:           "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e",
+				K8sContainerName: "nginx",
+				K8sWorkloadKind:  "Deployment",
+				K8sWorkloadName:  "nginx",
+			},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			var ctr event.Container
+			setK8sPodMetadata(&ctr, tc.labels, tc.sandboxLabels)
+			assert.Equal(t, tc.expected, ctr)
+		})
+	}
+}
+
+func TestK8sPodWorkload(t *testing.T) {
+	tCases := map[string]struct {
+		podName      string
+		labels       map[string]string
+		expectedKind string
+		expectedName string
+	}{
+		"bare pod": {
+			podName: "debug",
+		},
+		"deployment": {
+			podName:      "web-api-7c5ddbdf54-2xkqv",
+			labels:       map[string]string{"pod-template-hash": "7c5ddbdf54"},
+			expectedKind: "Deployment",
+			expectedName: "web-api",
+		},
+		"deployment from pod name": {
+			podName:      "web-api-7c5ddbdf54-2xkqv",
+			expectedKind: "Deployment",
+			expectedName: "web-api",
+		},
+		"replicaset not owned by a deployment": {
+			podName: "web-api-2xkqv",
+			labels:  map[string]string{"pod-template-hash": "7c5ddbdf54"},
+		},
+		"statefulset": {
+			podName: "db-0",
+			labels: map[string]string{
+				"controller-revision-hash":           "db-5d4c8b9f7",
+				"statefulset.kubernetes.io/pod-name": "db-0",
+			},
+			expectedKind: "StatefulSet",
+			expectedName: "db",
+		},
+		"daemonset": {
+			podName: "node-exporter-x7b2k",
+			labels: map[string]string{
+				"controller-revision-hash": "6b8d9f4c7d",
+				"pod-template-generation":  "3",
+			},
+			expectedKind: "DaemonSet",
+			expectedName: "node-exporter",
+		},
+		"job": {
+			podName: "backup-28391040-5tq7m",
+			labels: map[string]string{
+				"batch.kubernetes.io/job-name": "backup-28391040",
+				"job-name":                     "backup-28391040",
+			},
+			expectedKind: "Job",
+			expectedName: "backup-28391040",
+		},
+		"job with legacy label": {
+			podName:      "migrate-5tq7m",
+			labels:       map[string]string{"job-name": "migrate"},
+			expectedKind: "Job",
+			expectedName: "migrate",
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			kind, workload := k8sPodWorkload(tc.podName, func(key string) string {
+				return tc.labels[key]
+			})
+			assert.Equal(t, tc.expectedKind, kind)
+			assert.Equal(t, tc.expectedName, workload)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package container
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const libvirtLxcPollInterval = 2 * time.Second
+
+func init() {
+	engineGenerators[typeLibvirtLxc] = newLibvirtLxcEngine
+}
+
+// libvirtLxcEngine enumerates running libvirt LXC domains through the live status files
+// that the libvirt LXC driver keeps under its state directory (ie: /run/libvirt/lxc/<domain>.xml).
+// The state directory is used in place of libvirt RPC socket, whose XDR based protocol
+// would require linking libvirt client library.
+type libvirtLxcEngine struct {
+	logger   *slog.Logger
+	stateDir string
+	// systemd is true when domains are started through systemd-machined,
+	// changing the container ID extracted from the cgroup layout.
+	systemd bool
+}
+
+type libvirtDomStatus struct {
+	XMLName xml.Name      `xml:"domstatus"`
+	State   string        `xml:"state,attr"`
+	Domain  libvirtDomain `xml:"domain"`
+}
+
+type libvirtDomain struct {
+	Type   string `xml:"type,attr"`
+	ID     string `xml:"id,attr"`
+	Name   string `xml:"name"`
+	UUID   string `xml:"uuid"`
+	Memory struct {
+		Unit  string `xml:"unit,attr"`
+		Value int64  `xml:",chardata"`
+	} `xml:"memory"`
+	VCPU struct {
+		CPUSet string `xml:"cpuset,attr"`
+		Value  int64  `xml:",chardata"`
+	} `xml:"vcpu"`
+	CPUTune struct {
+		Shares int64 `xml:"shares"`
+		Period int64 `xml:"period"`
+		Quota  int64 `xml:"quota"`
+	} `xml:"cputune"`
+	OS struct {
+		Init string `xml:"init"`
+	} `xml:"os"`
+	Devices struct {
+		Filesystems []struct {
+			Type   string `xml:"type,attr"`
+			Source struct {
+				Dir string `xml:"dir,attr"`
+			} `xml:"source"`
+			Target struct {
+				Dir string `xml:"dir,attr"`
+			} `xml:"target"`
+			ReadOnly *struct{} `xml:"readonly"`
+		} `xml:"filesystem"`
+	} `xml:"devices"`
+}
+
+func newLibvirtLxcEngine(_ context.Context, logger *slog.Logger, stateDir string) (Engine, error) {
+	stat, err := os.Stat(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", stateDir)
+	}
+	_, err = os.Stat(filepath.Join(config.GetHostRoot(), "/run/systemd/system"))
+	return &libvirtLxcEngine{
+		logger:   logger,
+		stateDir: stateDir,
+		systemd:  err == nil,
+	}, nil
+}
+
+func (l *libvirtLxcEngine) copy(ctx context.Context) (Engine, error) {
+	return newLibvirtLxcEngine(ctx, l.logger, l.stateDir)
+}
+
+// systemdEscape escapes a string the way systemd does for unit names,
+// eg: "lxc-1234-my-container" -> "lxc\x2d1234\x2dmy\x2dcontainer".
+func systemdEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+			c == ':' || c == '_' || (c == '.' && i > 0) {
+			b.WriteByte(c)
+		} else {
+			_, _ = fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String()
+}
+
+// containerID returns the ID of the domain, as extracted from cgroups by the libvirt_lxc matcher:
+// the domain name, or "<id>\x2d<escaped name>" for domains running in a systemd machine scope,
+// eg: /machine.slice/machine-lxc\x2d2293906\x2dlibvirt\x2dcontainer.scope.
+func (l *libvirtLxcEngine) containerID(domain *libvirtDomain) string {
+	if l.systemd && domain.ID != "" {
+		return systemdEscape(domain.ID + "-" + domain.Name)
+	}
+	return domain.Name
+}
+
+func libvirtMemoryToBytes(value int64, unit string) int64 {
+	switch strings.ToLower(unit) {
+	case "b", "bytes":
+		return value
+	case "k", "kib", "":
+		// KiB is the default unit
+		return value << 10
+	case "m", "mib":
+		return value << 20
+	case "g", "gib":
+		return value << 30
+	case "kb":
+		return value * 1000
+	case "mb":
+		return value * 1000 * 1000
+	case "gb":
+		return value * 1000 * 1000 * 1000
+	}
+	return 0
+}
+
+// libvirtMountType maps libvirt filesystem types to the mount types reported by the other engines.
+func libvirtMountType(fsType string) string {
+	switch fsType {
+	case "mount", "":
+		// "mount" (the default) bind mounts a host directory
+		return "bind"
+	case "ram":
+		return "tmpfs"
+	}
+	return fsType
+}
+
+func (l *libvirtLxcEngine) domainToInfo(status *libvirtDomStatus, created time.Time) event.Info {
+	domain := &status.Domain
+
+	var (
+		cpuShares int64 = defaultCpuShares
+		cpuPeriod int64 = defaultCpuPeriod
+		cpuQuota  int64
+	)
+	if domain.CPUTune.Shares > 0 {
+		cpuShares = domain.CPUTune.Shares
+	}
+	if domain.CPUTune.Period > 0 {
+		cpuPeriod = domain.CPUTune.Period
+	}
+	if domain.CPUTune.Quota > 0 {
+		cpuQuota = domain.CPUTune.Quota
+	}
+	cpusetCount := countCPUSet(domain.VCPU.CPUSet)
+	if cpusetCount == 0 {
+		cpusetCount = domain.VCPU.Value
+	}
+
+	var image string
+	mounts := make([]event.Mount, 0)
+	for _, fs := range domain.Devices.Filesystems {
+		if fs.Target.Dir == "/" {
+			// The root filesystem is the closest thing to an image for libvirt-lxc domains
+			image = fs.Source.Dir
+		}
+		mounts = append(mounts, event.Mount{
+			Source:      fs.Source.Dir,
+			Destination: fs.Target.Dir,
+			RW:          fs.ReadOnly == nil,
+			Type:        libvirtMountType(fs.Type),
+		})
+	}
+	if image == "" {
+		image = domain.OS.Init
+	}
+
+	id := l.containerID(domain)
+	// Status files are written on domain start: it is the start time too.
+	return event.Info{
+		Container: event.Container{
+			Type:           typeLibvirtLxc.ToCTValue(),
+			ID:             id,
+			Name:           domain.Name,
+			Image:          image,
+			CPUPeriod:      cpuPeriod,
+			CPUQuota:       cpuQuota,
+			CPUShares:      cpuShares,
+			CPUSetCPUCount: cpusetCount,
+			CreatedTime:    created.Unix(),
+			CreatedAt:      unixNano(created),
+			StartedAt:      unixNano(created),
+			FullID:         id,
+			Labels:         map[string]string{"libvirt.uuid": domain.UUID},
+			MemoryLimit:    libvirtMemoryToBytes(domain.Memory.Value, domain.Memory.Unit),
+			Mounts:         mounts,
+			PortMappings:   []event.PortMapping{},
+			Size:           -1,
+		},
+	}
+}
+
+func (l *libvirtLxcEngine) readDomain(path string) (*event.Event, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var status libvirtDomStatus
+	if err = xml.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	if status.Domain.Type != "lxc" {
+		return nil, fmt.Errorf("%s is not an lxc domain", path)
+	}
+	return &event.Event{
+		Info:     l.domainToInfo(&status, stat.ModTime()),
+		IsCreate: true,
+	}, nil
+}
+
+func (l *libvirtLxcEngine) get(_ context.Context, containerId string) (*event.Event, error) {
+	evts, err := l.List(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, evt := range evts {
+		if evt.ID == containerId {
+			return &evt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l *libvirtLxcEngine) Name() string {
+	return string(typeLibvirtLxc)
+}
+
+func (l *libvirtLxcEngine) Sock() string {
+	return l.stateDir
+}
+
+func (l *libvirtLxcEngine) List(_ context.Context) ([]event.Event, error) {
+	paths, err := filepath.Glob(filepath.Join(l.stateDir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(paths))
+	for _, path := range paths {
+		evt, err := l.readDomain(path)
+		if err != nil {
+			l.logger.LogAttrs(context.Background(), slog.LevelDebug, "failed to read domain status", slog.String("path", path), slog.String("err", err.Error()))
+			continue
+		}
+		evts = append(evts, *evt)
+	}
+	return evts, nil
+}
+
+// Listen polls the state directory, since status files are created on domain start and removed on domain stop.
+func (l *libvirtLxcEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, l.logger, libvirtLxcPollInterval, l.List)
+}
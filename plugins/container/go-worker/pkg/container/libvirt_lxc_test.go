@@ -0,0 +1,108 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const libvirtDomStatusXml = `<domstatus state='running' reason='booted' pid='2293906'>
+  <domain type='lxc' id='2293906'>
+    <name>libvirt-container</name>
+    <uuid>a1b2c3d4-e5f6-4789-abcd-ef0123456789</uuid>
+    <memory unit='KiB'>524288</memory>
+    <vcpu placement='static' cpuset='0-1'>2</vcpu>
+    <cputune>
+      <shares>2048</shares>
+      <period>50000</period>
+      <quota>25000</quota>
+    </cputune>
+    <os>
+      <type>exe</type>
+      <init>/sbin/init</init>
+    </os>
+    <devices>
+      <filesystem type='mount'>
+        <source dir='/var/lib/libvirt/lxc/rootfs'/>
+        <target dir='/'/>
+      </filesystem>
+      <filesystem type='mount'>
+        <source dir='/srv/data'/>
+        <target dir='/data'/>
+        <readonly/>
+      </filesystem>
+    </devices>
+  </domain>
+</domstatus>`
+
+func TestLibvirtLxc(t *testing.T) {
+	stateDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(stateDir, "libvirt-container.xml"), []byte(libvirtDomStatusXml), 0644)
+	require.NoError(t, err)
+	// Not a domain status file
+	err = os.WriteFile(filepath.Join(stateDir, "libvirt-container.pid"), []byte("2293906"), 0644)
+	require.NoError(t, err)
+
+	engine, err := newLibvirtLxcEngine(context.Background(), slog.Default(), stateDir)
+	require.NoError(t, err)
+
+	tCases := map[string]struct {
+		systemd    bool
+		expectedID string
+	}{
+		"No systemd": {
+			systemd:    false,
+			expectedID: "libvirt-container",
+		},
+		"Systemd machine scope": {
+			systemd:    true,
+			expectedID: `2293906\x2dlibvirt\x2dcontainer`,
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			engine.(*libvirtLxcEngine).systemd = tc.systemd
+
+			events, err := engine.List(context.Background())
+			assert.NoError(t, err)
+			require.Len(t, events, 1)
+
+			ctr := events[0].Container
+			assert.True(t, events[0].IsCreate)
+			assert.Equal(t, typeLibvirtLxc.ToCTValue(), ctr.Type)
+			assert.Equal(t, tc.expectedID, ctr.ID)
+			assert.Equal(t, tc.expectedID, ctr.FullID)
+			assert.Equal(t, "libvirt-container", ctr.Name)
+			assert.Equal(t, "/var/lib/libvirt/lxc/rootfs", ctr.Image)
+			assert.Equal(t, int64(2048), ctr.CPUShares)
+			assert.Equal(t, int64(50000), ctr.CPUPeriod)
+			assert.Equal(t, int64(25000), ctr.CPUQuota)
+			assert.Equal(t, int64(2), ctr.CPUSetCPUCount)
+			assert.Equal(t, int64(512*1024*1024), ctr.MemoryLimit)
+			assert.Equal(t, map[string]string{"libvirt.uuid": "a1b2c3d4-e5f6-4789-abcd-ef0123456789"}, ctr.Labels)
+			assert.Equal(t, []event.Mount{
+				{Source: "/var/lib/libvirt/lxc/rootfs", Destination: "/", RW: true, Type: "bind"},
+				{Source: "/srv/data", Destination: "/data", RW: false, Type: "bind"},
+			}, ctr.Mounts)
+
+			evt, err := engine.(getter).get(context.Background(), tc.expectedID)
+			assert.NoError(t, err)
+			require.NotNil(t, evt)
+			assert.Equal(t, events[0], *evt)
+		})
+	}
+}
+
+func TestSystemdEscape(t *testing.T) {
+	assert.Equal(t, `lxc\x2d1234\x2dmy\x2dcontainer`, systemdEscape("lxc-1234-my-container"))
+	assert.Equal(t, `my_container.1`, systemdEscape("my_container.1"))
+	assert.Equal(t, `\x2emy\x20ctr`, systemdEscape(".my ctr"))
+}
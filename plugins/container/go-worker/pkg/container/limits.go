@@ -0,0 +1,30 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// ApplyLimits applies the runtime limits of the worker, ie: the GOMAXPROCS override, when set.
+// Being embedded in the plugin, the worker runtime would otherwise use all the CPUs of the node.
+func ApplyLimits() {
+	if procs := config.GetGoMaxProcs(); procs > 0 {
+		prev := runtime.GOMAXPROCS(procs)
+		slog.Default().LogAttrs(context.Background(), slog.LevelDebug, "GOMAXPROCS overridden", slog.Int("gomaxprocs", procs), slog.Int("previous", prev))
+	}
+}
+
+// spawnAllowed reports whether an optional goroutine can be spawned, ie: the worker goroutines
+// are below config.GetMaxGoroutines(); otherwise, the caller must do without it,
+// and it is accounted in the worker metrics.
+func spawnAllowed() bool {
+	limit := config.GetMaxGoroutines()
+	if limit <= 0 || runtime.NumGoroutine() < limit {
+		return true
+	}
+	countThrottled()
+	return false
+}
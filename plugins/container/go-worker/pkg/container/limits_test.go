@@ -0,0 +1,63 @@
+package container
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestApplyLimits(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		_ = config.Load(`{"worker_limits": {"gomaxprocs": 0}}`)
+	})
+
+	// No override by default
+	ApplyLimits()
+	assert.Equal(t, procs, runtime.GOMAXPROCS(0))
+
+	require.NoError(t, config.Load(`{"worker_limits": {"gomaxprocs": 1}}`))
+	ApplyLimits()
+	assert.Equal(t, 1, runtime.GOMAXPROCS(0))
+}
+
+func TestSpawnAllowed(t *testing.T) {
+	t.Cleanup(func() {
+		_ = config.Load(`{"worker_limits": {"max_goroutines": 0}}`)
+	})
+	assert.True(t, spawnAllowed())
+
+	require.NoError(t, config.Load(`{"worker_limits": {"max_goroutines": 1000000}}`))
+	assert.True(t, spawnAllowed())
+
+	throttled := Metric(MetricThrottled)
+	require.NoError(t, config.Load(`{"worker_limits": {"max_goroutines": 1}}`))
+	assert.False(t, spawnAllowed())
+	assert.Equal(t, throttled+1, Metric(MetricThrottled))
+
+	// Once throttled, slow inspections are waited for, instead of being followed by an update
+	minimal := event.Info{Container: event.Container{ID: "test"}}
+	full := event.Info{Container: event.Container{ID: "test", Name: "test"}}
+	outCh := make(chan event.Event, 2)
+	enr := newEnricher(typeDocker, outCh)
+	enr.timeout = time.Millisecond
+	enr.enrich(context.Background(), minimal, func(_ context.Context) (event.Info, error) {
+		time.Sleep(10 * time.Millisecond)
+		return full, nil
+	})
+	enr.wait()
+	close(outCh)
+	evts := make([]event.Event, 0)
+	for evt := range outCh {
+		evts = append(evts, evt)
+	}
+	assert.Equal(t, []event.Event{{Info: full, IsCreate: true}}, evts)
+}
@@ -0,0 +1,169 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	// listRetryAttempts bounds the inspections retried for each partially listed container.
+	listRetryAttempts   = 5
+	listRetryMinBackoff = 1 * time.Second
+	listRetryMaxBackoff = 30 * time.Second
+)
+
+// inspectAll calls inspect for each index in [0, n), running at most config.GetListConcurrency()
+// inspections concurrently, each one bounded by the inspect timeout of the engine, through its circuit breaker.
+// Once ctx is done, remaining containers are still passed to inspect, with an expired context,
+// so that engines can fill them with the minimal set of infos they got from the listing.
+func inspectAll(ctx context.Context, engine engineType, n int, inspect func(ctx context.Context, idx int)) {
+	sem := make(chan struct{}, config.GetListConcurrency())
+	var wg sync.WaitGroup
+	for idx := range n {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			guardedInspect(ctx, engine, func(ctx context.Context) {
+				inspect(ctx, idx)
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+// inspectContext bounds a single container inspection by config.GetEngineInspectTimeout(), if any.
+func inspectContext(ctx context.Context, engine engineType) (context.Context, context.CancelFunc) {
+	if timeout := config.GetEngineInspectTimeout(string(engine)); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// listRetry is a fake engine inspecting again the containers that an engine only partially listed,
+// ie: whose inspection failed, eg: on permission errors, timeouts, or races with their removal.
+// Containers are retried with an exponential backoff, up to listRetryAttempts times; once inspected,
+// their full infos are sent as update events, while the ones that are gone are dropped.
+// Its events channel is closed once no container is left to retry.
+type listRetry struct {
+	logger     *slog.Logger
+	engineType engineType
+	getter     getter
+	// pending holds the minimal infos of the containers to inspect again.
+	pending []event.Event
+	// minBackoff is the delay before the first retry, doubling at each attempt up to listRetryMaxBackoff.
+	minBackoff time.Duration
+}
+
+// NewListRetryEngine returns an engine retrying the inspection of the containers partially listed by engine,
+// or nil when all of them were fully inspected.
+func NewListRetryEngine(engine Engine, evts []event.Event) Engine {
+	g, ok := engine.(getter)
+	if !ok {
+		return nil
+	}
+	var pending []event.Event
+	for _, evt := range evts {
+		if evt.IsPartial {
+			pending = append(pending, evt)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return &listRetry{
+		logger:     slog.With("engine", engine.Name()),
+		engineType: engineType(engine.Name()),
+		getter:     g,
+		pending:    pending,
+		minBackoff: listRetryMinBackoff,
+	}
+}
+
+func (l *listRetry) Name() string {
+	return ""
+}
+
+func (l *listRetry) Sock() string {
+	return ""
+}
+
+func (l *listRetry) List(_ context.Context) ([]event.Event, error) {
+	return []event.Event{}, nil
+}
+
+// inspect inspects a partially listed container, through the circuit breaker of its engine.
+func (l *listRetry) inspect(ctx context.Context, minimal event.Event) (*event.Event, error) {
+	id := minimal.FullID
+	if id == "" {
+		id = minimal.ID
+	}
+	var (
+		evt *event.Event
+		err error
+	)
+	guardedInspect(ctx, l.engineType, func(ctx context.Context) {
+		defer recoverAsError(&err)
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		start := time.Now()
+		evt, err = l.getter.get(ctx, id)
+		countInspect(l.engineType, err, start)
+	})
+	return evt, err
+}
+
+// Listen inspects the pending containers again in background, sending their full infos as update events.
+func (l *listRetry) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer close(outCh)
+		defer wg.Done()
+		bo := newBackoff(l.minBackoff, listRetryMaxBackoff)
+		pending := l.pending
+		for attempt := 0; attempt < listRetryAttempts && len(pending) > 0; attempt++ {
+			timer := time.NewTimer(bo.next())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			var failed []event.Event
+			for _, minimal := range pending {
+				evt, err := l.inspect(ctx, minimal)
+				if err != nil {
+					l.logger.LogAttrs(ctx, slog.LevelDebug, "failed to inspect partially listed container",
+						slog.String("container_id", minimal.ID), slog.String("err", err.Error()))
+					failed = append(failed, minimal)
+					continue
+				}
+				if evt == nil {
+					// The container is gone
+					continue
+				}
+				evt.IsCreate = true
+				evt.IsUpdate = true
+				select {
+				case outCh <- *evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+			pending = failed
+		}
+		if len(pending) > 0 {
+			l.logger.LogAttrs(ctx, slog.LevelWarn, "giving up inspecting partially listed containers",
+				slog.Int("containers", len(pending)))
+		}
+	}()
+	return outCh, nil
+}
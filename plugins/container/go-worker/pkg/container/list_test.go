@@ -0,0 +1,141 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestInspectAll(t *testing.T) {
+	require.NoError(t, config.Load(`{"list_concurrency": 2, "inspect_timeout_ms": 50}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"list_concurrency": 10, "inspect_timeout_ms": 5000}`)
+	})
+
+	var running, maxRunning atomic.Int32
+	inspected := make([]bool, 5)
+	timedOut := make([]bool, 5)
+	inspectAll(context.Background(), typeDocker, len(inspected), func(ctx context.Context, idx int) {
+		cur := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := maxRunning.Load()
+			if cur <= old || maxRunning.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		inspected[idx] = true
+		if idx == 0 {
+			// Simulate a stuck inspection
+			<-ctx.Done()
+			timedOut[idx] = true
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	})
+	assert.Equal(t, []bool{true, true, true, true, true}, inspected)
+	assert.Equal(t, []bool{true, false, false, false, false}, timedOut)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	// Once the listing deadline expired, remaining containers still get an (expired) context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var expired atomic.Int32
+	inspectAll(ctx, typeDocker, 3, func(ctx context.Context, _ int) {
+		if ctx.Err() != nil {
+			expired.Add(1)
+		}
+	})
+	assert.Equal(t, int32(3), expired.Load())
+}
+
+func TestInspectContextEngineTimeout(t *testing.T) {
+	require.NoError(t, config.Load(`{"inspect_timeout_ms": 5000, "engines": {"cri": {"enabled": true, "timeout_ms": 100}}}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"inspect_timeout_ms": 5000, "engines": null}`)
+	})
+
+	ctx, cancel := inspectContext(context.Background(), typeCri)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(100*time.Millisecond), deadline, 50*time.Millisecond)
+
+	// Engines without their own timeout use the global one
+	ctx, cancel = inspectContext(context.Background(), typeDocker)
+	defer cancel()
+	deadline, ok = ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, 50*time.Millisecond)
+}
+
+// flakyEngine fails the first inspections of its containers.
+type flakyEngine struct {
+	Engine
+	mu       sync.Mutex
+	failures map[string]int
+	infos    map[string]event.Info
+}
+
+func (f *flakyEngine) Name() string {
+	return string(typeDocker)
+}
+
+func (f *flakyEngine) get(_ context.Context, containerId string) (*event.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures[containerId] > 0 {
+		f.failures[containerId]--
+		return nil, errors.New("permission denied")
+	}
+	info, ok := f.infos[containerId]
+	if !ok {
+		return nil, nil
+	}
+	return &event.Event{Info: info, IsCreate: true}, nil
+}
+
+func TestListRetry(t *testing.T) {
+	engine := &flakyEngine{
+		failures: map[string]int{"aaa": 1, "ccc": listRetryAttempts},
+		infos: map[string]event.Info{
+			"aaa": {Container: event.Container{ID: "aaa", FullID: "aaa", Image: "nginx"}},
+			"ccc": {Container: event.Container{ID: "ccc", FullID: "ccc", Image: "redis"}},
+		},
+	}
+	evts := []event.Event{
+		{Info: event.Info{Container: event.Container{ID: "aaa", FullID: "aaa"}}, IsCreate: true, IsPartial: true},
+		// Removed in the meantime
+		{Info: event.Info{Container: event.Container{ID: "bbb", FullID: "bbb"}}, IsCreate: true, IsPartial: true},
+		// Never inspectable
+		{Info: event.Info{Container: event.Container{ID: "ccc", FullID: "ccc"}}, IsCreate: true, IsPartial: true},
+		{Info: event.Info{Container: event.Container{ID: "ddd", FullID: "ddd"}}, IsCreate: true},
+	}
+	assert.Nil(t, NewListRetryEngine(engine, evts[3:]))
+	retry := NewListRetryEngine(engine, evts)
+	require.NotNil(t, retry)
+	retry.(*listRetry).minBackoff = time.Millisecond
+
+	wg := sync.WaitGroup{}
+	ch, err := retry.Listen(context.Background(), &wg)
+	require.NoError(t, err)
+	var received []event.Event
+	for evt := range ch {
+		received = append(received, evt)
+	}
+	wg.Wait()
+	require.Len(t, received, 1)
+	assert.Equal(t, "nginx", received[0].Image)
+	assert.True(t, received[0].IsCreate)
+	assert.True(t, received[0].IsUpdate)
+	assert.Equal(t, 0, engine.failures["ccc"])
+}
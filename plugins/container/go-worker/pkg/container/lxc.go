@@ -0,0 +1,268 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	// LXD events are only exposed through websockets; poll the instances list instead.
+	lxcPollInterval = 2 * time.Second
+	lxcTimeout      = 5 * time.Second
+)
+
+func init() {
+	engineGenerators[typeLxc] = newLxcEngine
+}
+
+// lxcEngine talks with the LXD (or Incus) REST API, exposed on a unix socket,
+// to enumerate system containers.
+type lxcEngine struct {
+	client *http.Client
+	logger *slog.Logger
+	socket string
+}
+
+// See https://documentation.ubuntu.com/lxd/en/latest/rest-api/
+type lxdResponse struct {
+	Type       string          `json:"type"`
+	StatusCode int             `json:"status_code"`
+	Error      string          `json:"error"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+type lxdInstance struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Status         string            `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	LastUsedAt     time.Time         `json:"last_used_at"` // last start
+	Config         map[string]string `json:"config"`
+	ExpandedConfig map[string]string `json:"expanded_config"`
+}
+
+func newLxcEngine(ctx context.Context, logger *slog.Logger, socket string) (Engine, error) {
+	socketPath := strings.TrimPrefix(socket, "unix://")
+	e := &lxcEngine{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+			Timeout: lxcTimeout,
+		},
+		logger: logger,
+		socket: socket,
+	}
+	// Make sure that the socket is actually served by LXD
+	if _, err := e.request(ctx, "/1.0"); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (l *lxcEngine) copy(ctx context.Context) (Engine, error) {
+	return newLxcEngine(ctx, l.logger, l.socket)
+}
+
+func (l *lxcEngine) request(ctx context.Context, path string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://lxd"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var lxdResp lxdResponse
+	if err = json.NewDecoder(resp.Body).Decode(&lxdResp); err != nil {
+		return nil, err
+	}
+	if lxdResp.Type == "error" || resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lxd request %q failed with status %d: %s", path, resp.StatusCode, lxdResp.Error)
+	}
+	return lxdResp.Metadata, nil
+}
+
+func (l *lxcEngine) instances(ctx context.Context) ([]lxdInstance, error) {
+	metadata, err := l.request(ctx, "/1.0/instances?recursion=1")
+	if err != nil {
+		return nil, err
+	}
+	var instances []lxdInstance
+	if err = json.Unmarshal(metadata, &instances); err != nil {
+		return nil, err
+	}
+	// Skip virtual machines: only system containers are relevant here.
+	ctrs := make([]lxdInstance, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Type == "" || instance.Type == "container" {
+			ctrs = append(ctrs, instance)
+		}
+	}
+	return ctrs, nil
+}
+
+// parseLxcMemoryLimit parses an LXD memory limit, eg: "512MB", "1GiB".
+// Percentage limits are relative to the host memory and reported as 0.
+func parseLxcMemoryLimit(limit string) int64 {
+	if limit == "" || strings.HasSuffix(limit, "%") {
+		return 0
+	}
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"kB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000}, {"TB", 1000 * 1000 * 1000 * 1000},
+		{"B", 1},
+	}
+	for _, unit := range units {
+		if value, ok := strings.CutSuffix(limit, unit.suffix); ok {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0
+			}
+			return parsed * unit.multiplier
+		}
+	}
+	parsed, _ := strconv.ParseInt(limit, 10, 64)
+	return parsed
+}
+
+// parseLxcCPULimit parses an LXD cpu limit, that is either a number of cpus (eg: "2")
+// or a cpuset (eg: "0-3", "1,3").
+func parseLxcCPULimit(limit string) int64 {
+	if strings.ContainsAny(limit, "-,") {
+		return countCPUSet(limit)
+	}
+	count, _ := strconv.ParseInt(limit, 10, 64)
+	return count
+}
+
+// parseLxcCPUAllowance parses an LXD cpu allowance hard limit, eg: "25ms/100ms",
+// returning the CFS quota and period in microseconds.
+// Percentage allowances are soft limits and are not reported.
+func parseLxcCPUAllowance(allowance string) (int64, int64) {
+	quota, period, ok := strings.Cut(allowance, "/")
+	if !ok {
+		return 0, defaultCpuPeriod
+	}
+	parsedQuota, err := time.ParseDuration(quota)
+	if err != nil {
+		return 0, defaultCpuPeriod
+	}
+	parsedPeriod, err := time.ParseDuration(period)
+	if err != nil || parsedPeriod <= 0 {
+		return 0, defaultCpuPeriod
+	}
+	return parsedQuota.Microseconds(), parsedPeriod.Microseconds()
+}
+
+func (l *lxcEngine) instanceToInfo(instance *lxdInstance) event.Info {
+	cfg := instance.ExpandedConfig
+	if cfg == nil {
+		cfg = instance.Config
+	}
+
+	image := cfg["image.description"]
+	if image == "" && cfg["image.os"] != "" {
+		image = cfg["image.os"]
+		if cfg["image.release"] != "" {
+			image += "/" + cfg["image.release"]
+		}
+	}
+
+	labels := make(map[string]string)
+	for key, val := range cfg {
+		if key, ok := strings.CutPrefix(key, "user."); ok && len(val) <= config.GetLabelMaxLen() {
+			labels[key] = val
+		}
+	}
+
+	cpuQuota, cpuPeriod := parseLxcCPUAllowance(cfg["limits.cpu.allowance"])
+
+	return event.Info{
+		Container: event.Container{
+			Type:           typeLxc.ToCTValue(),
+			ID:             instance.Name,
+			Name:           instance.Name,
+			Image:          image,
+			ImageID:        cfg["volatile.base_image"],
+			CPUPeriod:      cpuPeriod,
+			CPUQuota:       cpuQuota,
+			CPUShares:      defaultCpuShares,
+			CPUSetCPUCount: parseLxcCPULimit(cfg["limits.cpu"]),
+			CreatedTime:    instance.CreatedAt.Unix(),
+			CreatedAt:      unixNano(instance.CreatedAt),
+			StartedAt:      unixNano(instance.LastUsedAt),
+			FullID:         instance.Name,
+			Labels:         labels,
+			MemoryLimit:    parseLxcMemoryLimit(cfg["limits.memory"]),
+			Privileged:     cfg["security.privileged"] == "true",
+			Mounts:         []event.Mount{},
+			PortMappings:   []event.PortMapping{},
+			Size:           -1,
+		},
+	}
+}
+
+func (l *lxcEngine) get(ctx context.Context, containerId string) (*event.Event, error) {
+	metadata, err := l.request(ctx, "/1.0/instances/"+url.PathEscape(containerId))
+	if err != nil {
+		return nil, err
+	}
+	var instance lxdInstance
+	if err = json.Unmarshal(metadata, &instance); err != nil {
+		return nil, err
+	}
+	return &event.Event{
+		Info:     l.instanceToInfo(&instance),
+		IsCreate: true,
+	}, nil
+}
+
+func (l *lxcEngine) Name() string {
+	return string(typeLxc)
+}
+
+func (l *lxcEngine) Sock() string {
+	return l.socket
+}
+
+func (l *lxcEngine) List(ctx context.Context) ([]event.Event, error) {
+	instances, err := l.instances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	evts := make([]event.Event, 0, len(instances))
+	for _, instance := range instances {
+		evts = append(evts, event.Event{
+			Info:     l.instanceToInfo(&instance),
+			IsCreate: true,
+		})
+	}
+	return evts, nil
+}
+
+// Listen polls the instances list every lxcPollInterval,
+// sending create and remove events for instances that appeared or disappeared since last poll.
+func (l *lxcEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	return pollEvents(ctx, wg, l.logger, lxcPollInterval, l.List)
+}
@@ -0,0 +1,128 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const lxdInstancesJson = `{
+  "type": "sync",
+  "status": "Success",
+  "status_code": 200,
+  "metadata": [
+    {
+      "name": "test-container",
+      "type": "container",
+      "status": "Running",
+      "created_at": "2024-11-07T10:30:03Z",
+      "last_used_at": "2024-11-07T10:31:15.5Z",
+      "expanded_config": {
+        "image.os": "Ubuntu",
+        "image.release": "noble",
+        "limits.cpu": "0-1",
+        "limits.cpu.allowance": "50ms/100ms",
+        "limits.memory": "512MiB",
+        "security.privileged": "true",
+        "user.foo": "bar",
+        "volatile.base_image": "4b5cbe9fa2a1"
+      }
+    },
+    {
+      "name": "test-vm",
+      "type": "virtual-machine",
+      "status": "Running",
+      "created_at": "2024-11-07T10:30:03Z"
+    }
+  ]
+}`
+
+func startFakeLxd(t *testing.T) string {
+	socket := filepath.Join(t.TempDir(), "unix.socket")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1.0", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"type": "sync", "status_code": 200, "metadata": {}}`))
+	})
+	mux.HandleFunc("/1.0/instances", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(lxdInstancesJson))
+	})
+	server := &http.Server{Handler: mux}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+	return socket
+}
+
+func TestLxc(t *testing.T) {
+	socket := startFakeLxd(t)
+
+	engine, err := newLxcEngine(context.Background(), slog.Default(), socket)
+	require.NoError(t, err)
+
+	expectedEvent := event.Event{
+		Info: event.Info{
+			Container: event.Container{
+				Type:           typeLxc.ToCTValue(),
+				ID:             "test-container",
+				Name:           "test-container",
+				Image:          "Ubuntu/noble",
+				ImageID:        "4b5cbe9fa2a1",
+				CPUPeriod:      defaultCpuPeriod,
+				CPUQuota:       50000,
+				CPUShares:      defaultCpuShares,
+				CPUSetCPUCount: 2,
+				CreatedTime:    1730975403,
+				CreatedAt:      1730975403000000000,
+				StartedAt:      1730975475500000000,
+				FullID:         "test-container",
+				Labels:         map[string]string{"foo": "bar"},
+				MemoryLimit:    512 * 1024 * 1024,
+				Privileged:     true,
+				Mounts:         []event.Mount{},
+				PortMappings:   []event.PortMapping{},
+				Size:           -1,
+			},
+		},
+		IsCreate: true,
+	}
+
+	events, err := engine.List(context.Background())
+	assert.NoError(t, err)
+	// Virtual machines must be skipped
+	assert.Len(t, events, 1)
+	assert.Equal(t, expectedEvent, events[0])
+}
+
+func TestParseLxcLimits(t *testing.T) {
+	assert.Equal(t, int64(512*1024*1024), parseLxcMemoryLimit("512MiB"))
+	assert.Equal(t, int64(2*1000*1000*1000), parseLxcMemoryLimit("2GB"))
+	assert.Equal(t, int64(0), parseLxcMemoryLimit("50%"))
+	assert.Equal(t, int64(1024), parseLxcMemoryLimit("1024"))
+	assert.Equal(t, int64(2), parseLxcCPULimit("2"))
+	assert.Equal(t, int64(3), parseLxcCPULimit("0-1,3"))
+	assert.Equal(t, int64(0), parseLxcCPULimit(""))
+
+	cpuQuota, cpuPeriod := parseLxcCPUAllowance("25ms/100ms")
+	assert.Equal(t, int64(25000), cpuQuota)
+	assert.Equal(t, int64(100000), cpuPeriod)
+	cpuQuota, cpuPeriod = parseLxcCPUAllowance("50%")
+	assert.Equal(t, int64(0), cpuQuota)
+	assert.Equal(t, int64(defaultCpuPeriod), cpuPeriod)
+	cpuQuota, cpuPeriod = parseLxcCPUAllowance("")
+	assert.Equal(t, int64(0), cpuQuota)
+	assert.Equal(t, int64(defaultCpuPeriod), cpuPeriod)
+}
@@ -0,0 +1,165 @@
+package container
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the worker metrics, exported through the plugin metrics.
+const (
+	MetricEvents        = "n_worker_events"
+	MetricInspects      = "n_inspects"
+	MetricCacheHits     = "n_cache_hits"
+	MetricCacheMisses   = "n_cache_misses"
+	MetricFetchDropped  = "n_fetch_requests_dropped"
+	MetricEventsDropped = "n_worker_events_dropped"
+	MetricPanics        = "n_worker_panics"
+	MetricReconciled    = "n_worker_reconciled"
+	MetricLookups       = "n_lookups"
+	MetricLookupsFailed = "n_lookups_failed"
+	MetricDeduplicated  = "n_worker_updates_deduplicated"
+	MetricBreakerOpened = "n_circuit_breaker_opened"
+	MetricThrottled     = "n_worker_goroutines_throttled"
+	// Inspect failures are tracked by engine, eg: "n_inspect_failures_docker".
+	metricInspectFailuresPrefix = "n_inspect_failures_"
+	// Latency histograms are tracked by engine, eg: "inspect_latency_ms_docker_le_100".
+	metricInspectLatencyPrefix = "inspect_latency_ms_"
+	metricListLatencyPrefix    = "list_latency_ms_"
+)
+
+// latencyBucketsMs are the upper bounds, in milliseconds, of the latency histograms buckets.
+// Each histogram is made of a cumulative counter per bucket, eg: "inspect_latency_ms_docker_le_100",
+// counting the observations lower or equal than the bound, an unbounded one, eg: "inspect_latency_ms_docker_le_inf",
+// and the sum of the observations, eg: "inspect_latency_ms_docker_sum".
+// They must be kept in sync with the ones exported by the plugin.
+var latencyBucketsMs = []int64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// metrics holds the worker counters, by name.
+var metrics sync.Map
+
+func counter(name string) *atomic.Uint64 {
+	c, _ := metrics.LoadOrStore(name, &atomic.Uint64{})
+	return c.(*atomic.Uint64)
+}
+
+// Metric returns the value of a worker counter; unknown counters are 0.
+func Metric(name string) uint64 {
+	if c, ok := metrics.Load(name); ok {
+		return c.(*atomic.Uint64).Load()
+	}
+	return 0
+}
+
+// CountEvent accounts for an event received from the engines.
+func CountEvent() {
+	counter(MetricEvents).Add(1)
+}
+
+// CountFetchDropped accounts for a container info request dropped because the fetcher is busy.
+func CountFetchDropped() {
+	counter(MetricFetchDropped).Add(1)
+}
+
+// countEventDropped accounts for an event dropped because the event queue is full.
+func countEventDropped() {
+	counter(MetricEventsDropped).Add(1)
+}
+
+// countPanic accounts for a panic recovered in a worker goroutine.
+func countPanic() {
+	counter(MetricPanics).Add(1)
+}
+
+// countReconciled accounts for the events sent to repair the drift between the known containers
+// and the ones listed by an engine, after a reconnection or during a periodic reconciliation.
+func countReconciled(n int) {
+	counter(MetricReconciled).Add(uint64(n))
+}
+
+// countLookup accounts for a synchronous container lookup requested by the plugin.
+func countLookup() {
+	counter(MetricLookups).Add(1)
+}
+
+// countLookupFailure accounts for a synchronous container lookup that timed out.
+func countLookupFailure() {
+	counter(MetricLookupsFailed).Add(1)
+}
+
+// countDeduplicated accounts for an update event not sent, since it did not change the container infos.
+func countDeduplicated() {
+	counter(MetricDeduplicated).Add(1)
+}
+
+// countBreakerOpened accounts for an engine whose inspections got stopped, since they kept timing out.
+func countBreakerOpened() {
+	counter(MetricBreakerOpened).Add(1)
+}
+
+// countThrottled accounts for a goroutine not spawned, since the worker reached its max number of goroutines.
+func countThrottled() {
+	counter(MetricThrottled).Add(1)
+}
+
+// countInspect accounts for a container inspection performed by an engine, started at start,
+// for its latency, and for its failure, if any.
+func countInspect(engine engineType, err error, start time.Time) {
+	counter(MetricInspects).Add(1)
+	histogram(metricInspectLatencyPrefix + string(engine)).observe(time.Since(start))
+	if err != nil {
+		counter(metricInspectFailuresPrefix + string(engine)).Add(1)
+	}
+}
+
+// ObserveListLatency accounts for the latency of the listing of the containers of an engine.
+func ObserveListLatency(engine string, latency time.Duration) {
+	histogram(metricListLatencyPrefix + engine).observe(latency)
+}
+
+// latencyHistogram holds the counters of a latency histogram, see latencyBucketsMs.
+type latencyHistogram struct {
+	buckets []*atomic.Uint64
+	inf     *atomic.Uint64
+	sum     *atomic.Uint64
+}
+
+// histograms holds the latency histograms, by name.
+var histograms sync.Map
+
+func histogram(name string) *latencyHistogram {
+	if h, ok := histograms.Load(name); ok {
+		return h.(*latencyHistogram)
+	}
+	h := &latencyHistogram{
+		buckets: make([]*atomic.Uint64, len(latencyBucketsMs)),
+		inf:     counter(name + "_le_inf"),
+		sum:     counter(name + "_sum"),
+	}
+	for i, bound := range latencyBucketsMs {
+		h.buckets[i] = counter(name + "_le_" + strconv.FormatInt(bound, 10))
+	}
+	actual, _ := histograms.LoadOrStore(name, h)
+	return actual.(*latencyHistogram)
+}
+
+func (h *latencyHistogram) observe(latency time.Duration) {
+	ms := latency.Milliseconds()
+	for i, bound := range latencyBucketsMs {
+		if ms <= bound {
+			h.buckets[i].Add(1)
+		}
+	}
+	h.inf.Add(1)
+	h.sum.Add(uint64(max(ms, 0)))
+}
+
+// countCacheLookup accounts for a metadata cache hit or miss.
+func countCacheLookup(hit bool) {
+	if hit {
+		counter(MetricCacheHits).Add(1)
+	} else {
+		counter(MetricCacheMisses).Add(1)
+	}
+}
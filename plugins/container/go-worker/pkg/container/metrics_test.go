@@ -0,0 +1,82 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestMetrics(t *testing.T) {
+	assert.Zero(t, Metric("unknown"))
+
+	inspects := Metric(MetricInspects)
+	failures := Metric(metricInspectFailuresPrefix + string(typeCri))
+	countInspect(typeCri, nil, time.Now())
+	countInspect(typeCri, errors.New("inspect failed"), time.Now())
+	assert.Equal(t, inspects+2, Metric(MetricInspects))
+	assert.Equal(t, failures+1, Metric("n_inspect_failures_cri"))
+
+	events := Metric(MetricEvents)
+	CountEvent()
+	assert.Equal(t, events+1, Metric(MetricEvents))
+
+	dropped := Metric(MetricFetchDropped)
+	CountFetchDropped()
+	assert.Equal(t, dropped+1, Metric(MetricFetchDropped))
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	ObserveListLatency("fixture", 3*time.Millisecond)
+	ObserveListLatency("fixture", 40*time.Millisecond)
+	ObserveListLatency("fixture", 20*time.Second)
+
+	assert.Equal(t, uint64(1), Metric("list_latency_ms_fixture_le_5"))
+	assert.Equal(t, uint64(1), Metric("list_latency_ms_fixture_le_25"))
+	assert.Equal(t, uint64(2), Metric("list_latency_ms_fixture_le_50"))
+	assert.Equal(t, uint64(2), Metric("list_latency_ms_fixture_le_10000"))
+	assert.Equal(t, uint64(3), Metric("list_latency_ms_fixture_le_inf"))
+	assert.Equal(t, uint64(20043), Metric("list_latency_ms_fixture_sum"))
+
+	// Inspections account for their latency too
+	le500, le1000 := Metric("inspect_latency_ms_cri_le_500"), Metric("inspect_latency_ms_cri_le_1000")
+	countInspect(typeCri, nil, time.Now().Add(-700*time.Millisecond))
+	assert.Equal(t, le500, Metric("inspect_latency_ms_cri_le_500"))
+	assert.Equal(t, le1000+1, Metric("inspect_latency_ms_cri_le_1000"))
+}
+
+func TestCacheMetrics(t *testing.T) {
+	m := newMetadataCache(time.Hour, 10)
+	hits, misses := Metric(MetricCacheHits), Metric(MetricCacheMisses)
+
+	m.get("first")
+	m.add(cacheInfo("first", "first"))
+	m.get("first")
+	assert.Equal(t, hits+1, Metric(MetricCacheHits))
+	assert.Equal(t, misses+1, Metric(MetricCacheMisses))
+
+	// Disabled cache lookups are not accounted
+	var disabled *metadataCache
+	disabled.get("first")
+	assert.Equal(t, misses+1, Metric(MetricCacheMisses))
+}
+
+func TestEnricherMetrics(t *testing.T) {
+	outCh := make(chan event.Event, 2)
+	enr := newEnricher(typePodman, outCh)
+	inspects := Metric(MetricInspects)
+	failures := Metric(metricInspectFailuresPrefix + string(typePodman))
+
+	enr.enrich(context.Background(), cacheInfo("ok", ""), func(context.Context) (event.Info, error) {
+		return cacheInfo("ok", "ok"), nil
+	})
+	enr.enrich(context.Background(), cacheInfo("ko", ""), func(context.Context) (event.Info, error) {
+		return event.Info{}, errors.New("inspect failed")
+	})
+	assert.Equal(t, inspects+2, Metric(MetricInspects))
+	assert.Equal(t, failures+1, Metric("n_inspect_failures_podman"))
+}
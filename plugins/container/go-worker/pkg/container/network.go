@@ -0,0 +1,136 @@
+package container
+
+import (
+	"encoding/json"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// sortNetworks sorts the networks of a container by name, since engines report them as maps.
+func sortNetworks(networks []event.Network) []event.Network {
+	if len(networks) == 0 {
+		return nil
+	}
+	slices.SortFunc(networks, func(a, b event.Network) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return networks
+}
+
+// firstNetworkIP returns the first IPv4 address of networks, if any.
+func firstNetworkIP(networks []event.Network) string {
+	for _, network := range networks {
+		if network.IP != "" {
+			return network.IP
+		}
+	}
+	return ""
+}
+
+// setNetworkIP sets ip on network, as IPv4 or IPv6 address, unless it is already set.
+func setNetworkIP(network *event.Network, ip net.IP, gateway net.IP) {
+	if ip == nil {
+		return
+	}
+	if ip.To4() != nil {
+		if network.IP == "" {
+			network.IP = ip.String()
+			if gateway != nil {
+				network.Gateway = gateway.String()
+			}
+		}
+	} else if network.IPv6 == "" {
+		network.IPv6 = ip.String()
+		if network.Gateway == "" && gateway != nil {
+			network.Gateway = gateway.String()
+		}
+	}
+}
+
+// containerdCNIResult is the result of the CNI plugins of a pod sandbox, as reported by containerd
+// in the sandbox verbose info; it has an interface for each network the pod is attached to,
+// eg: with Multus, and the host side veth interfaces, that have no sandbox.
+// See https://github.com/containerd/go-cni/blob/main/result.go
+type containerdCNIResult struct {
+	CNIResult *struct {
+		Interfaces map[string]*struct {
+			IPConfigs []*struct {
+				IP      net.IP
+				Gateway net.IP
+			}
+			Mac     string
+			Sandbox string
+		}
+	} `json:"cniResult"`
+}
+
+// networks returns the networks of the pod sandbox, named after their interfaces.
+func (r *containerdCNIResult) networks() []event.Network {
+	if r.CNIResult == nil {
+		return nil
+	}
+	networks := make([]event.Network, 0, len(r.CNIResult.Interfaces))
+	for name, iface := range r.CNIResult.Interfaces {
+		if iface == nil || iface.Sandbox == "" || name == "lo" {
+			continue
+		}
+		network := event.Network{Name: name, MAC: iface.Mac}
+		for _, ipConfig := range iface.IPConfigs {
+			if ipConfig != nil {
+				setNetworkIP(&network, ipConfig.IP, ipConfig.Gateway)
+			}
+		}
+		networks = append(networks, network)
+	}
+	return sortNetworks(networks)
+}
+
+// cniResult is a CNI result, as stored by CRI-O in the io.kubernetes.cri-o.CNIResult sandbox annotation.
+// See https://github.com/containernetworking/cni/blob/main/SPEC.md#add-success
+type cniResult struct {
+	Interfaces []struct {
+		Name    string `json:"name"`
+		Mac     string `json:"mac"`
+		Sandbox string `json:"sandbox"`
+	} `json:"interfaces"`
+	IPs []struct {
+		Interface *int   `json:"interface"`
+		Address   string `json:"address"`
+		Gateway   string `json:"gateway"`
+	} `json:"ips"`
+}
+
+// parseCNIResultNetworks returns the networks of a CNI result, named after their interfaces.
+func parseCNIResultNetworks(result string) []event.Network {
+	var res cniResult
+	if err := json.Unmarshal([]byte(result), &res); err != nil {
+		return nil
+	}
+	networks := make([]event.Network, 0, len(res.Interfaces))
+	byIface := make(map[int]int, len(res.Interfaces))
+	for idx, iface := range res.Interfaces {
+		if iface.Sandbox == "" || iface.Name == "lo" {
+			continue
+		}
+		byIface[idx] = len(networks)
+		networks = append(networks, event.Network{Name: iface.Name, MAC: iface.Mac})
+	}
+	for _, ipConfig := range res.IPs {
+		if ipConfig.Interface == nil {
+			continue
+		}
+		networkIdx, ok := byIface[*ipConfig.Interface]
+		if !ok {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(ipConfig.Address)
+		if err != nil {
+			continue
+		}
+		setNetworkIP(&networks[networkIdx], ip, net.ParseIP(ipConfig.Gateway))
+	}
+	return sortNetworks(networks)
+}
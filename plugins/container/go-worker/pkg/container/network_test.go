@@ -0,0 +1,83 @@
+package container
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestContainerdCNIResultNetworks(t *testing.T) {
+	// Pod attached to a secondary macvlan network through Multus
+	const info = `{
+  "pid": 4242,
+  "cniResult": {
+    "Interfaces": {
+      "cni0": {"IPConfigs": null, "Mac": "6a:3e:5f:01:02:03", "Sandbox": ""},
+      "veth1a2b3c4d": {"IPConfigs": null, "Mac": "6a:3e:5f:04:05:06", "Sandbox": ""},
+      "lo": {"IPConfigs": [{"IP": "127.0.0.1", "Gateway": ""}], "Mac": "00:00:00:00:00:00", "Sandbox": "/var/run/netns/cni-1234"},
+      "eth0": {
+        "IPConfigs": [
+          {"IP": "10.244.0.12", "Gateway": "10.244.0.1"},
+          {"IP": "fd00:10:244::c", "Gateway": "fd00:10:244::1"}
+        ],
+        "Mac": "0a:58:0a:f4:00:0c",
+        "Sandbox": "/var/run/netns/cni-1234"
+      },
+      "net1": {
+        "IPConfigs": [{"IP": "192.168.1.200", "Gateway": null}],
+        "Mac": "2e:9a:7c:11:22:33",
+        "Sandbox": "/var/run/netns/cni-1234"
+      }
+    },
+    "DNS": [{}],
+    "Routes": null
+  }
+}`
+	var result containerdCNIResult
+	require.NoError(t, json.Unmarshal([]byte(info), &result))
+	assert.Equal(t, []event.Network{
+		{Name: "eth0", IP: "10.244.0.12", IPv6: "fd00:10:244::c", MAC: "0a:58:0a:f4:00:0c", Gateway: "10.244.0.1"},
+		{Name: "net1", IP: "192.168.1.200", MAC: "2e:9a:7c:11:22:33"},
+	}, result.networks())
+
+	result = containerdCNIResult{}
+	require.NoError(t, json.Unmarshal([]byte(`{"pid": 4242}`), &result))
+	assert.Nil(t, result.networks())
+}
+
+func TestParseCNIResultNetworks(t *testing.T) {
+	// io.kubernetes.cri-o.CNIResult annotation
+	const result = `{
+  "cniVersion": "1.0.0",
+  "interfaces": [
+    {"name": "cni0", "mac": "6a:3e:5f:01:02:03"},
+    {"name": "veth1a2b3c4d", "mac": "6a:3e:5f:04:05:06"},
+    {"name": "eth0", "mac": "0a:58:0a:55:00:07", "sandbox": "/var/run/netns/5b3c1f4e"},
+    {"name": "net1", "mac": "2e:9a:7c:11:22:33", "sandbox": "/var/run/netns/5b3c1f4e"}
+  ],
+  "ips": [
+    {"interface": 2, "address": "10.85.0.7/16", "gateway": "10.85.0.1"},
+    {"interface": 2, "address": "1100:200::7/24", "gateway": "1100:200::1"},
+    {"interface": 3, "address": "192.168.1.201/24"},
+    {"address": "10.0.0.1/8"}
+  ]
+}`
+	assert.Equal(t, []event.Network{
+		{Name: "eth0", IP: "10.85.0.7", IPv6: "1100:200::7", MAC: "0a:58:0a:55:00:07", Gateway: "10.85.0.1"},
+		{Name: "net1", IP: "192.168.1.201", MAC: "2e:9a:7c:11:22:33"},
+	}, parseCNIResultNetworks(result))
+
+	assert.Nil(t, parseCNIResultNetworks("not json"))
+}
+
+func TestFirstNetworkIP(t *testing.T) {
+	assert.Empty(t, firstNetworkIP(nil))
+	assert.Equal(t, "172.18.0.2", firstNetworkIP([]event.Network{
+		{Name: "ipv6only", IPv6: "fd00::2"},
+		{Name: "backend", IP: "172.18.0.2"},
+	}))
+}
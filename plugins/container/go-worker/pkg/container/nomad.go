@@ -0,0 +1,157 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// Label set by the nomad docker driver, and environment variables set by nomad task drivers
+// (ie: docker and containerd ones).
+const (
+	nomadAllocIDLabel  = "com.hashicorp.nomad.alloc_id"
+	nomadAllocIDEnv    = "NOMAD_ALLOC_ID"
+	nomadJobIDEnv      = "NOMAD_JOB_ID"
+	nomadTaskGroupEnv  = "NOMAD_GROUP_NAME"
+	nomadTokenHeader   = "X-Nomad-Token"
+	nomadAllocationAPI = "/v1/allocation/"
+)
+
+// nomadAllocs resolves the allocations of nomad tasks from the local nomad client API.
+// It is nil, thus disabled, until InitNomadResolver is called with the lookup enabled.
+var nomadAllocs *nomadResolver
+
+// nomadAlloc is the subset of the nomad allocation API response we report.
+type nomadAlloc struct {
+	ID        string `json:"ID"`
+	JobID     string `json:"JobID"`
+	TaskGroup string `json:"TaskGroup"`
+}
+
+type nomadEntry struct {
+	alloc   nomadAlloc
+	ok      bool
+	expires time.Time
+}
+
+// nomadResolver looks up nomad allocations, caching the results
+// (failed lookups too, to not hammer the agent).
+type nomadResolver struct {
+	client  *http.Client
+	address string
+	token   string
+	timeout time.Duration
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]nomadEntry
+}
+
+func newNomadResolver(cfg config.NomadCfg, client *http.Client) *nomadResolver {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &nomadResolver{
+		client:  client,
+		address: strings.TrimSuffix(cfg.Address, "/"),
+		token:   cfg.Token,
+		timeout: time.Duration(max(cfg.TimeoutMs, 0)) * time.Millisecond,
+		ttl:     time.Duration(max(cfg.CacheTTLMs, 0)) * time.Millisecond,
+		entries: make(map[string]nomadEntry),
+	}
+}
+
+// InitNomadResolver sets up the lookup of nomad allocations from the current config.
+func InitNomadResolver() {
+	nomadAllocs = newNomadResolver(config.GetNomad(), http.DefaultClient)
+}
+
+// setNomadMetadata sets the nomad allocation metadata of a task container from its environment,
+// falling back at the local nomad client API, when the lookup is enabled, for the missing ones.
+func setNomadMetadata(ctx context.Context, ctr *event.Container, labels map[string]string, env []string) {
+	for _, kv := range env {
+		key, val, _ := strings.Cut(kv, "=")
+		switch key {
+		case nomadAllocIDEnv:
+			ctr.NomadAllocID = val
+		case nomadJobIDEnv:
+			ctr.NomadJobID = val
+		case nomadTaskGroupEnv:
+			ctr.NomadTaskGroup = val
+		}
+	}
+	if ctr.NomadAllocID == "" {
+		ctr.NomadAllocID = labels[nomadAllocIDLabel]
+	}
+	if ctr.NomadAllocID == "" || nomadAllocs == nil || (ctr.NomadJobID != "" && ctr.NomadTaskGroup != "") {
+		return
+	}
+	alloc, ok := nomadAllocs.resolve(ctx, ctr.NomadAllocID)
+	if !ok {
+		return
+	}
+	if ctr.NomadJobID == "" {
+		ctr.NomadJobID = alloc.JobID
+	}
+	if ctr.NomadTaskGroup == "" {
+		ctr.NomadTaskGroup = alloc.TaskGroup
+	}
+}
+
+func (r *nomadResolver) resolve(ctx context.Context, allocID string) (nomadAlloc, bool) {
+	r.mu.Lock()
+	entry, ok := r.entries[allocID]
+	r.mu.Unlock()
+	if ok && (r.ttl == 0 || time.Now().Before(entry.expires)) {
+		return entry.alloc, entry.ok
+	}
+
+	lookupCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		lookupCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	alloc, err := r.lookup(lookupCtx, allocID)
+	if err != nil && ctx.Err() != nil {
+		// Do not cache lookups interrupted by the caller.
+		return nomadAlloc{}, false
+	}
+
+	r.mu.Lock()
+	r.entries[allocID] = nomadEntry{alloc: alloc, ok: err == nil, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return alloc, err == nil
+}
+
+// lookup retrieves an allocation from the nomad client API.
+func (r *nomadResolver) lookup(ctx context.Context, allocID string) (nomadAlloc, error) {
+	allocURL := r.address + nomadAllocationAPI + url.PathEscape(allocID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, allocURL, nil)
+	if err != nil {
+		return nomadAlloc{}, err
+	}
+	if r.token != "" {
+		req.Header.Set(nomadTokenHeader, r.token)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nomadAlloc{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nomadAlloc{}, fmt.Errorf("unexpected status from %s: %s", allocURL, res.Status)
+	}
+	var alloc nomadAlloc
+	if err = json.NewDecoder(res.Body).Decode(&alloc); err != nil {
+		return nomadAlloc{}, err
+	}
+	return alloc, nil
+}
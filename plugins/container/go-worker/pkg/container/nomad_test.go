@@ -0,0 +1,77 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const testNomadAllocID = "5b3c1f4e-9d2a-4e8b-a3f7-1c2d3e4f5a6b"
+
+func TestSetNomadMetadata(t *testing.T) {
+	var allocHits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(nomadTokenHeader) != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != nomadAllocationAPI+testNomadAllocID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		allocHits.Add(1)
+		_, _ = w.Write([]byte(`{"ID": "` + testNomadAllocID + `", "JobID": "web", "TaskGroup": "frontend"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	// Task drivers environment
+	nomadAllocs = nil
+	var ctr event.Container
+	setNomadMetadata(context.Background(), &ctr, nil, []string{
+		"PATH=/usr/bin",
+		nomadAllocIDEnv + "=" + testNomadAllocID,
+		nomadJobIDEnv + "=web",
+		nomadTaskGroupEnv + "=frontend",
+	})
+	assert.Equal(t, testNomadAllocID, ctr.NomadAllocID)
+	assert.Equal(t, "web", ctr.NomadJobID)
+	assert.Equal(t, "frontend", ctr.NomadTaskGroup)
+
+	// Docker driver label only, lookup disabled
+	ctr = event.Container{}
+	labels := map[string]string{nomadAllocIDLabel: testNomadAllocID}
+	setNomadMetadata(context.Background(), &ctr, labels, nil)
+	assert.Equal(t, testNomadAllocID, ctr.NomadAllocID)
+	assert.Empty(t, ctr.NomadJobID)
+	assert.Empty(t, ctr.NomadTaskGroup)
+
+	nomadAllocs = newNomadResolver(config.NomadCfg{Enabled: true, Address: srv.URL + "/", Token: "secret", TimeoutMs: 1000}, srv.Client())
+	t.Cleanup(func() { nomadAllocs = nil })
+	for range 2 {
+		ctr = event.Container{}
+		setNomadMetadata(context.Background(), &ctr, labels, nil)
+		assert.Equal(t, testNomadAllocID, ctr.NomadAllocID)
+		assert.Equal(t, "web", ctr.NomadJobID)
+		assert.Equal(t, "frontend", ctr.NomadTaskGroup)
+	}
+	assert.Equal(t, int32(1), allocHits.Load())
+
+	// Failed lookups only report the allocation ID
+	ctr = event.Container{}
+	setNomadMetadata(context.Background(), &ctr, map[string]string{nomadAllocIDLabel: "unknown"}, nil)
+	assert.Equal(t, "unknown", ctr.NomadAllocID)
+	assert.Empty(t, ctr.NomadJobID)
+
+	// Not a nomad container
+	ctr = event.Container{}
+	setNomadMetadata(context.Background(), &ctr, map[string]string{"foo": "bar"}, []string{"PATH=/usr/bin"})
+	assert.Equal(t, event.Container{}, ctr)
+	assert.Equal(t, int32(1), allocHits.Load())
+}
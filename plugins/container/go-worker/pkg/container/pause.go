@@ -0,0 +1,19 @@
+package container
+
+import (
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// pausedEvent builds the pause event of a container,
+// carrying the cached infos of the container, if any, and its paused-at timestamp.
+func pausedEvent(minimal event.Info, pausedAt int64) event.Event {
+	info := cachedInfo(minimal)
+	info.PausedAt = pausedAt
+	return event.Event{Info: info, IsPause: true}
+}
+
+// unpausedEvent builds the unpause event of a container,
+// carrying the cached infos of the container, if any.
+func unpausedEvent(minimal event.Info) event.Event {
+	return event.Event{Info: cachedInfo(minimal), IsUnpause: true}
+}
@@ -0,0 +1,37 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestPauseEvents(t *testing.T) {
+	metadata = newMetadataCache(time.Hour, 10)
+	t.Cleanup(func() {
+		metadata = nil
+	})
+	metadata.add(cacheInfo("cached", "cached"))
+
+	evt := pausedEvent(cacheInfo("cached", ""), 1000)
+	assert.Equal(t, event.KindPaused, evt.Kind())
+	assert.Equal(t, "cached", evt.Name)
+	assert.Equal(t, int64(1000), evt.PausedAt)
+
+	evt = unpausedEvent(cacheInfo("cached", ""))
+	assert.Equal(t, event.KindUnpaused, evt.Kind())
+	assert.Equal(t, "cached", evt.Name)
+	assert.Zero(t, evt.PausedAt)
+
+	evt = pausedEvent(cacheInfo("uncached", ""), 2000)
+	assert.Equal(t, "uncached", evt.ID)
+	assert.Equal(t, int64(2000), evt.PausedAt)
+
+	// Pause events leave the cache untouched
+	CacheEvent(evt)
+	_, ok := metadata.get("uncached")
+	assert.False(t, ok)
+}
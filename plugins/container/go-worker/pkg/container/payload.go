@@ -0,0 +1,55 @@
+package container
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// k8sLabelsPrefix is the prefix of the labels set by kubelet on the containers of pods,
+// kept in the payload since the plugin falls back at them for the pod metadata.
+const k8sLabelsPrefix = "io.kubernetes."
+
+// ShapePayload returns evt without the metadata sections excluded from the events payload
+// by config, and with the digest of its configuration, when enabled; it must only be applied
+// to the events sent to the plugin, after they got cached.
+func ShapePayload(evt event.Event) event.Event {
+	payloadCfg := config.GetPayload()
+	// Partial and other events (eg: removals) only carry the minimal set of infos
+	if kind := evt.Kind(); payloadCfg.Digest && !evt.IsPartial && (kind == event.KindAdded || kind == event.KindUpdated) {
+		evt.ConfigDigest = configDigest(evt.Container)
+	}
+	exclude := payloadCfg.Exclude
+	if len(exclude) == 0 {
+		return evt
+	}
+	if slices.Contains(exclude, config.PayloadMounts) {
+		evt.Mounts = nil
+	}
+	if slices.Contains(exclude, config.PayloadEnv) {
+		evt.Env = nil
+	}
+	if slices.Contains(exclude, config.PayloadLabels) && len(evt.Labels) > 0 {
+		// Do not modify the labels map, shared with the cached infos.
+		labels := maps.Clone(evt.Labels)
+		maps.DeleteFunc(labels, func(key, _ string) bool {
+			return !strings.HasPrefix(key, k8sLabelsPrefix)
+		})
+		evt.Labels = labels
+	}
+	if slices.Contains(exclude, config.PayloadAnnotations) {
+		evt.Annotations = nil
+		evt.PodAnnotations = nil
+	}
+	if slices.Contains(exclude, config.PayloadHealthcheck) {
+		evt.Healthcheck = nil
+		evt.HealthOutput = ""
+	}
+	if slices.Contains(exclude, config.PayloadCniJson) {
+		evt.CniJson = ""
+	}
+	return evt
+}
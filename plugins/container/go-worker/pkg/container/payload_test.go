@@ -0,0 +1,83 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestShapePayload(t *testing.T) {
+	t.Cleanup(func() {
+		_ = config.Load(`{"payload": {}}`)
+	})
+
+	evt := event.Event{Info: event.Info{Container: event.Container{
+		ID:  "test",
+		Env: []string{"APP=1"},
+		Labels: map[string]string{
+			"io.kubernetes.pod.name": "pod",
+			"team":                   "security",
+		},
+		Annotations:    map[string]string{"annotation": "value"},
+		PodAnnotations: map[string]string{"annotation": "value"},
+		CniJson:        `{"cniVersion": "1.0.0"}`,
+		Mounts:         []event.Mount{{Source: "/src", Destination: "/dst"}},
+		HealthStatus:   "unhealthy",
+		HealthOutput:   "failed",
+		Healthcheck:    &event.HealthProbe{Exe: "true"},
+	}}, IsCreate: true}
+
+	// Nothing is excluded by default
+	require.NoError(t, config.Load(`{"payload": {}}`))
+	assert.Equal(t, evt, ShapePayload(evt))
+
+	require.NoError(t, config.Load(`{"payload": {"exclude": ["mounts", "env", "labels", "annotations", "healthcheck", "cni_json"]}}`))
+	shaped := ShapePayload(evt)
+	assert.Equal(t, event.Event{Info: event.Info{Container: event.Container{
+		ID: "test",
+		// Kubernetes labels are kept for the pod metadata
+		Labels:       map[string]string{"io.kubernetes.pod.name": "pod"},
+		HealthStatus: "unhealthy",
+	}}, IsCreate: true}, shaped)
+	// The original labels are left untouched
+	assert.Len(t, evt.Labels, 2)
+}
+
+func TestShapePayloadDigest(t *testing.T) {
+	t.Cleanup(func() {
+		_ = config.Load(`{"payload": {}}`)
+	})
+
+	evt := event.Event{Info: event.Info{Container: event.Container{
+		ID:     "test",
+		Labels: map[string]string{"a": "1", "b": "2"},
+		Mounts: []event.Mount{{Source: "/src", Destination: "/dst"}},
+	}}, IsCreate: true}
+
+	// No digest by default
+	require.NoError(t, config.Load(`{"payload": {}}`))
+	assert.Empty(t, ShapePayload(evt).ConfigDigest)
+
+	require.NoError(t, config.Load(`{"payload": {"digest": true, "exclude": ["mounts"]}}`))
+	digest := ShapePayload(evt).ConfigDigest
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, digest)
+
+	// The runtime state does not change the digest
+	restarted := evt
+	restarted.RestartCount = 2
+	restarted.HealthStatus = "healthy"
+	assert.Equal(t, digest, ShapePayload(restarted).ConfigDigest)
+
+	// The excluded sections do
+	remounted := evt
+	remounted.Mounts = []event.Mount{{Source: "/other", Destination: "/dst"}}
+	assert.NotEqual(t, digest, ShapePayload(remounted).ConfigDigest)
+
+	// Removed containers and partial infos do not carry it
+	assert.Empty(t, ShapePayload(event.Event{Info: evt.Info}).ConfigDigest)
+	assert.Empty(t, ShapePayload(event.Event{Info: evt.Info, IsCreate: true, IsPartial: true}).ConfigDigest)
+}
@@ -4,15 +4,16 @@ package container
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/bindings"
 	"github.com/containers/podman/v5/pkg/bindings/containers"
+	"github.com/containers/podman/v5/pkg/bindings/images"
 	"github.com/containers/podman/v5/pkg/bindings/system"
 	"github.com/containers/podman/v5/pkg/domain/entities/types"
 	"github.com/docker/docker/api/types/events"
@@ -27,20 +28,36 @@ func init() {
 
 type podmanEngine struct {
 	pCtx   context.Context
+	logger *slog.Logger
 	socket string
+	// known tracks the containers notified by the engine, seeded by List.
+	known *knownContainers
 }
 
-func newPodmanEngine(ctx context.Context, _ *slog.Logger, socket string) (Engine, error) {
+func newPodmanEngine(ctx context.Context, logger *slog.Logger, socket string) (Engine, error) {
 	conn, err := bindings.NewConnection(ctx, enforceUnixProtocolIfEmpty(socket))
 	if err != nil {
 		return nil, err
 	}
-	return &podmanEngine{pCtx: conn, socket: socket}, nil
+	return &podmanEngine{pCtx: conn, logger: logger, socket: socket, known: newKnownContainers()}, nil
 }
 
 func (pc *podmanEngine) copy(ctx context.Context) (Engine, error) {
-	// TODO: change to use logger member once handled
-	return newPodmanEngine(ctx, nil, pc.socket)
+	return newPodmanEngine(ctx, pc.logger, pc.socket)
+}
+
+// parsePodmanPort parses a podman port key in the form "port/proto" (e.g. "80/tcp")
+// and returns its numerical port and protocol.
+func parsePodmanPort(port string) (int, string, error) {
+	portStr, proto, found := strings.Cut(port, "/")
+	if !found {
+		proto = "tcp"
+	}
+	containerPort, err := strconv.Atoi(portStr)
+	if err != nil {
+		return 0, "", err
+	}
+	return containerPort, proto, nil
 }
 
 func (pc *podmanEngine) ctrToInfo(ctr *define.InspectContainerData) event.Info {
@@ -69,42 +86,49 @@ func (pc *podmanEngine) ctrToInfo(ctr *define.InspectContainerData) event.Info {
 			Mode:        m.Mode,
 			RW:          m.RW,
 			Propagation: m.Propagation,
+			Type:        m.Type,
+			Name:        m.Name,
 		})
 	}
 
 	portMappings := make([]event.PortMapping, 0)
 	for port, portBindings := range netCfg.Ports {
-		if !strings.Contains(port, "/tcp") {
-			continue
-		}
-		containerPort, err := strconv.Atoi(port)
+		containerPort, proto, err := parsePodmanPort(port)
 		if err != nil {
 			continue
 		}
 		for _, portBinding := range portBindings {
-			hostIP, err := parsePortBindingHostIP(portBinding.HostIP)
-			if err != nil {
-				continue
-			}
-
-			hostPort, err := parsePortBindingHostPort(portBinding.HostPort)
+			portMapping, err := newPortMapping(containerPort, proto, portBinding.HostIP, portBinding.HostPort)
 			if err != nil {
 				continue
 			}
-
-			portMappings = append(portMappings, event.PortMapping{
-				HostIP:        hostIP,
-				HostPort:      hostPort,
-				ContainerPort: containerPort,
-			})
+			portMappings = append(portMappings, portMapping)
 		}
 	}
+	sortPortMappings(portMappings)
 
 	var (
 		imageRepo string
 		imageTag  string
 	)
-	imageRepo, imageTag = parseImageRepoTag(ctr.ImageName)
+	imageRef := parseImageReference(ctr.ImageName)
+	imageRepo, imageTag = imageRef.repository, imageRef.tag
+	imageDigest := ctr.ImageDigest
+	if imageDigest == "" {
+		imageDigest = imageRef.digest
+	}
+	imageDigest = resolveImageDigest(ctr.ImageName, imageDigest)
+
+	var (
+		imageSize   int64
+		imageLayers int64
+	)
+	if img, err := images.GetImage(pc.pCtx, ctr.Image, new(images.GetOptions).WithSize(true)); err == nil && img.ImageData != nil {
+		imageSize = img.Size
+		if img.RootFS != nil {
+			imageLayers = int64(len(img.RootFS.Layers))
+		}
+	}
 
 	labels := make(map[string]string)
 	for key, val := range cfg.Labels {
@@ -125,43 +149,162 @@ func (pc *podmanEngine) ctrToInfo(ctr *define.InspectContainerData) event.Info {
 	}
 	cpusetCount := countCPUSet(hostCfg.CpusetCpus)
 
+	ip := netCfg.IPAddress
+	networks := podmanNetworks(netCfg.Networks)
+	if ip == "" {
+		if secondaryID, ok := strings.CutPrefix(hostCfg.NetworkMode, "container:"); ok {
+			secondary, err := containers.Inspect(pc.pCtx, secondaryID, nil)
+			if err == nil && secondary.NetworkSettings != nil {
+				ip = secondary.NetworkSettings.IPAddress
+				networks = podmanNetworks(secondary.NetworkSettings.Networks)
+			}
+		}
+	}
+	if ip == "" {
+		ip = firstNetworkIP(networks)
+	}
+
+	secOpts := parseSecurityOpts(hostCfg.SecurityOpt, hostCfg.Privileged)
+	// Prefer the actually applied profiles
+	if ctr.AppArmorProfile != "" {
+		secOpts.apparmorProfile = ctr.AppArmorProfile
+	}
+	if ctr.ProcessLabel != "" {
+		secOpts.selinuxLabel = ctr.ProcessLabel
+	}
+
+	var uidMappings, gidMappings []event.IDMapping
+	if hostCfg.IDMappings != nil {
+		uidMappings = parseIDMappings(hostCfg.IDMappings.UIDMap)
+		gidMappings = parseIDMappings(hostCfg.IDMappings.GIDMap)
+	}
+	userns := len(uidMappings) > 0 || (hostCfg.UsernsMode != "" && hostCfg.UsernsMode != "host")
+
 	var size int64 = -1
 	if ctr.SizeRw != nil {
 		size = *ctr.SizeRw
 	}
 
-	return event.Info{
+	info := event.Info{
 		Container: event.Container{
 			Type:             typePodman.ToCTValue(),
 			ID:               shortContainerID(ctr.ID),
 			Name:             name,
 			Image:            ctr.ImageName,
-			ImageDigest:      ctr.ImageDigest,
+			ImageDigest:      imageDigest,
 			ImageID:          ctr.Image,
 			ImageRepo:        imageRepo,
 			ImageTag:         imageTag,
+			ImageRegistry:    imageRef.registry,
+			ImageSize:        imageSize,
+			ImageLayers:      imageLayers,
 			User:             cfg.User,
 			CPUPeriod:        cpuPeriod,
 			CPUQuota:         hostCfg.CpuQuota,
 			CPUShares:        cpuShares,
 			CPUSetCPUCount:   cpusetCount,
 			CreatedTime:      ctr.Created.Unix(),
-			Env:              cfg.Env,
+			CreatedAt:        unixNano(ctr.Created),
+			Env:              filterEnv(cfg.Env),
 			FullID:           ctr.ID,
 			HostIPC:          hostCfg.IpcMode == "host",
 			HostNetwork:      hostCfg.NetworkMode == "host",
 			HostPID:          hostCfg.PidMode == "host",
-			Ip:               netCfg.IPAddress,
+			Ip:               ip,
+			Networks:         networks,
 			IsPodSandbox:     isPodSandbox,
 			Labels:           labels,
 			MemoryLimit:      hostCfg.Memory,
 			SwapLimit:        hostCfg.MemorySwap,
+			PidsLimit:        max(hostCfg.PidsLimit, 0),
 			Privileged:       hostCfg.Privileged,
+			Runtime:          ctr.OCIRuntime,
+			SandboxedRuntime: isSandboxedRuntime(ctr.OCIRuntime),
+			CapAdd:           normalizeCaps(hostCfg.CapAdd),
+			CapDrop:          normalizeCaps(hostCfg.CapDrop),
+			CapEffective:     normalizeCaps(ctr.EffectiveCaps),
+			SeccompProfile:   secOpts.seccompProfile,
+			AppArmorProfile:  secOpts.apparmorProfile,
+			SELinuxLabel:     secOpts.selinuxLabel,
+			NoNewPrivileges:  secOpts.noNewPrivileges,
+			UserNamespace:    userns,
+			UIDMappings:      uidMappings,
+			GIDMappings:      gidMappings,
 			PortMappings:     portMappings,
 			Mounts:           mounts,
 			Size:             size,
+			RestartCount:     int64(ctr.RestartCount),
 		},
 	}
+	if ctr.State != nil {
+		fillProcCgroupLimits(&info.Container, ctr.State.Pid)
+		// Podman knows the cgroup of its containers and the cgroup manager in use
+		if ctr.State.CgroupPath != "" && ctr.HostConfig != nil && ctr.HostConfig.CgroupManager != "" {
+			info.CgroupPath = ctr.State.CgroupPath
+			info.CgroupDriver = ctr.HostConfig.CgroupManager
+		} else {
+			fillCgroupPath(&info.Container, "", ctr.State.Pid)
+		}
+		info.StartedAt = unixNano(ctr.State.StartedAt)
+		// Exit status of the last run of stopped containers
+		if !ctr.State.Running && !ctr.State.Restarting && !ctr.State.FinishedAt.IsZero() {
+			info.ExitCode = int64(ctr.State.ExitCode)
+			info.FinishedAt = ctr.State.FinishedAt.UnixNano()
+			info.OOMKilled = ctr.State.OOMKilled
+		}
+	}
+	if hc := cfg.Healthcheck; hc != nil {
+		info.Healthcheck = newHealthProbe(hc.Test, hc.Interval, hc.Timeout, hc.StartPeriod, hc.Retries)
+	}
+	var gpus gpuDevices
+	gpus.addEnv(cfg.Env)
+	gpus.addCDIAnnotations(cfg.Annotations)
+	for _, dev := range hostCfg.Devices {
+		gpus.addDeviceNodes(dev.PathOnHost)
+	}
+	info.GPUDevices = gpus
+	// Containers of podman-compose services
+	setComposeMetadata(&info.Container, cfg.Labels)
+	return info
+}
+
+// newPodmanUsageSample returns the usage sample of a container from its stats.
+func newPodmanUsageSample(stats define.ContainerStats, sampledAt int64) usageSample {
+	return usageSample{
+		minimal: event.Info{
+			Container: event.Container{
+				Type:   typePodman.ToCTValue(),
+				ID:     shortContainerID(stats.ContainerID),
+				FullID: stats.ContainerID,
+			},
+		},
+		cpuNano:     stats.CPUNano,
+		memoryUsage: stats.MemUsage,
+		memoryLimit: stats.MemLimit,
+		pids:        stats.PIDs,
+		sampledAt:   sampledAt,
+	}
+}
+
+// sampleUsage samples the resource usage of the running containers, all at once.
+func (pc *podmanEngine) sampleUsage(_ context.Context) ([]usageSample, error) {
+	reports, err := containers.Stats(pc.pCtx, nil, new(containers.StatsOptions).WithStream(false))
+	if err != nil {
+		return nil, err
+	}
+	report, ok := <-reports
+	sampledAt := time.Now().UnixNano()
+	if !ok {
+		return []usageSample{}, nil
+	}
+	if report.Error != nil {
+		return nil, report.Error
+	}
+	samples := make([]usageSample, 0, len(report.Stats))
+	for _, stats := range report.Stats {
+		samples = append(samples, newPodmanUsageSample(stats, sampledAt))
+	}
+	return samples, nil
 }
 
 func (pc *podmanEngine) get(_ context.Context, containerId string) (*event.Event, error) {
@@ -185,18 +328,43 @@ func (pc *podmanEngine) Sock() string {
 	return pc.socket
 }
 
-func (pc *podmanEngine) List(_ context.Context) ([]event.Event, error) {
-	evts := make([]event.Event, 0)
+// List lists all containers, considering them already known by the listener.
+func (pc *podmanEngine) List(ctx context.Context) ([]event.Event, error) {
+	evts, err := pc.list(ctx, true)
+	if err == nil {
+		pc.known.seed(evts)
+	}
+	return evts, err
+}
+
+// list lists all containers, serving their infos from the metadata cache if cached.
+func (pc *podmanEngine) list(ctx context.Context, cached bool) ([]event.Event, error) {
+	// podman bindings need the connection context: bind it to ctx cancellation.
+	pCtx, cancel := context.WithCancel(pc.pCtx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
 	all := true
 	size := config.GetWithSize()
-	cList, err := containers.List(pc.pCtx, &containers.ListOptions{All: &all})
+	cList, err := containers.List(pCtx, &containers.ListOptions{All: &all})
 	if err != nil {
 		return nil, err
 	}
-	for _, c := range cList {
-		ctrInfo, err := containers.Inspect(pc.pCtx, c.ID, &containers.InspectOptions{Size: &size})
+	evts := make([]event.Event, len(cList))
+	inspectAll(pCtx, typePodman, len(cList), func(ctx context.Context, idx int) {
+		c := cList[idx]
+		if cached {
+			if info, ok := metadata.get(shortContainerID(c.ID)); ok {
+				evts[idx] = event.Event{Info: info, IsCreate: true}
+				return
+			}
+		}
+		start := time.Now()
+		ctrInfo, err := containers.Inspect(ctx, c.ID, &containers.InspectOptions{Size: &size})
+		countInspect(typePodman, err, start)
 		if err != nil {
-			evts = append(evts, event.Event{
+			evts[idx] = event.Event{
 				Info: event.Info{
 					Container: event.Container{
 						Type:        typePodman.ToCTValue(),
@@ -205,18 +373,19 @@ func (pc *podmanEngine) List(_ context.Context) ([]event.Event, error) {
 						FullID:      c.ID,
 						ImageID:     c.ImageID,
 						CreatedTime: c.Created.Unix(),
+						CreatedAt:   unixNano(c.Created),
 					},
 				},
-				IsCreate: true,
-			})
+				IsCreate:  true,
+				IsPartial: true,
+			}
 		} else {
-			evts = append(evts, event.Event{
+			evts[idx] = event.Event{
 				Info:     pc.ctrToInfo(ctrInfo),
 				IsCreate: true,
-			})
+			}
 		}
-
-	}
+	})
 	return evts, nil
 }
 
@@ -238,6 +407,14 @@ func (pc *podmanEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan
 	if config.IsHookEnabled(config.HookRemove) {
 		filters["event"] = append(filters["event"], string(events.ActionRemove))
 	}
+	if config.IsHookEnabled(config.HookPause) {
+		filters["event"] = append(filters["event"], string(events.ActionPause), string(events.ActionUnPause))
+	}
+	// Image pulls are correlated with the containers created from the pulled images
+	if config.IsHookEnabled(config.HookCreate) || config.IsHookEnabled(config.HookStart) {
+		filters["type"] = append(filters["type"], string(events.ImageEventType))
+		filters["event"] = append(filters["event"], string(events.ActionPull))
+	}
 
 	evChn := make(chan types.Event)
 	cancelChan := make(chan bool)
@@ -250,62 +427,106 @@ func (pc *podmanEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan
 	}
 
 	outCh := make(chan event.Event)
+	enr := newEnricher(typePodman, outCh)
 	wg.Add(1)
 	go func() {
 		defer func() {
+			enr.wait()
 			wg.Done()
 			close(cancelChan)
 			close(outCh)
 		}()
-		size := config.GetWithSize()
 		// Blocking: convert all events from podman to json strings
 		// and send them to the main loop until the channel is closed
-		for {
-			select {
-			case <-ctx.Done():
-				cancelChan <- true
-				return
-			case ev, ok := <-evChn:
-				if !ok {
-					// evChn has been closed - kill the goroutine
-					// NOTE this should never happen since we are the ones closing the channel.
-					return
-				}
-				var (
-					ctr *define.InspectContainerData
-					err error
-				)
-				switch ev.Action {
-				case events.ActionCreate, events.ActionStart:
-					ctr, err = containers.Inspect(pc.pCtx, ev.Actor.ID, &containers.InspectOptions{Size: &size})
-					if err == nil {
-						outCh <- event.Event{
-							Info:     pc.ctrToInfo(ctr),
-							IsCreate: true,
-						}
-					}
-				case events.ActionRemove:
-					err = errors.New("inspect useless on action destroy")
-				}
+		supervise(ctx, pc.logger, string(typePodman), func() error {
+			return pc.processEvents(ctx, evChn, cancelChan, enr, outCh)
+		})
+	}()
+	var evtCh <-chan event.Event = outCh
+	if config.GetReconcileInterval() > 0 {
+		// Periodically reconcile the known containers, since events might be missed under load.
+		evtCh = pc.known.forward(ctx, wg, pc.logger, uncached(pc.list), outCh)
+	}
+	return sampleStats(ctx, wg, pc.logger, pc.sampleUsage, evtCh), nil
+}
 
-				// This is called for ActionRemove
-				// AND as a fallback whenever Inspect fails.
-				if err != nil {
-					// At least send an event with the minimal set of data
-					outCh <- event.Event{
-						Info: event.Info{
-							Container: event.Container{
-								Type:   typePodman.ToCTValue(),
-								ID:     shortContainerID(ev.Actor.ID),
-								FullID: ev.Actor.ID,
-								Image:  ev.Actor.Attributes["image"],
-							},
-						},
-						IsCreate: ev.Action != events.ActionRemove,
+// processEvents notifies the events of evChn until ctx is done or evChn gets closed.
+func (pc *podmanEngine) processEvents(ctx context.Context, evChn <-chan types.Event, cancelChan chan<- bool, enr *enricher,
+	outCh chan<- event.Event) error {
+	size := config.GetWithSize()
+	for {
+		select {
+		case <-ctx.Done():
+			cancelChan <- true
+			return nil
+		case ev, ok := <-evChn:
+			if !ok {
+				// evChn has been closed - kill the goroutine
+				// NOTE this should never happen since we are the ones closing the channel.
+				return nil
+			}
+			if ev.Type == events.ImageEventType {
+				// Image events also match the remove action, that must not be mistaken for a container one
+				if ev.Action == events.ActionPull {
+					// The actor ID is the image ID, while its name is the pulled reference
+					pc.logger.LogAttrs(ctx, config.LevelTrace, "image pull event", slog.String("image", ev.Actor.Attributes["name"]))
+					imagePulls.pulled(ev.Actor.Attributes["name"], ev.TimeNano)
+				}
+				break
+			}
+			// Minimal set of infos, sent for ActionRemove
+			// AND as a fallback whenever Inspect fails or is too slow.
+			minimal := event.Info{
+				Container: event.Container{
+					Type:   typePodman.ToCTValue(),
+					ID:     shortContainerID(ev.Actor.ID),
+					FullID: ev.Actor.ID,
+					Image:  ev.Actor.Attributes["image"],
+				},
+			}
+			switch ev.Action {
+			case events.ActionCreate, events.ActionStart:
+				pc.logger.LogAttrs(ctx, config.LevelTrace, "container create or start event", slog.String("container_id", ev.Actor.ID))
+				enr.enrich(ctx, minimal, func(_ context.Context) (event.Info, error) {
+					ctr, err := containers.Inspect(pc.pCtx, ev.Actor.ID, &containers.InspectOptions{Size: &size})
+					if err != nil {
+						return event.Info{}, err
 					}
+					return pc.ctrToInfo(ctr), nil
+				})
+			case events.ActionPause:
+				pc.logger.LogAttrs(ctx, config.LevelTrace, "container pause event", slog.String("container_id", ev.Actor.ID))
+				outCh <- pausedEvent(minimal, ev.TimeNano)
+			case events.ActionUnPause:
+				pc.logger.LogAttrs(ctx, config.LevelTrace, "container unpause event", slog.String("container_id", ev.Actor.ID))
+				outCh <- unpausedEvent(minimal)
+			case events.ActionRemove:
+				pc.logger.LogAttrs(ctx, config.LevelTrace, "container remove event", slog.String("container_id", ev.Actor.ID))
+				enr.forget(minimal.ID)
+				outCh <- event.Event{
+					Info:     minimal,
+					IsCreate: false,
 				}
 			}
 		}
-	}()
-	return outCh, nil
+	}
+}
+
+// podmanNetworks returns the networks a container is attached to.
+func podmanNetworks(endpoints map[string]*define.InspectAdditionalNetwork) []event.Network {
+	networks := make([]event.Network, 0, len(endpoints))
+	for name, endpoint := range endpoints {
+		if endpoint == nil {
+			continue
+		}
+		networks = append(networks, event.Network{
+			Name:    name,
+			IP:      endpoint.IPAddress,
+			IPv6:    endpoint.GlobalIPv6Address,
+			MAC:     endpoint.MacAddress,
+			Gateway: endpoint.Gateway,
+			Aliases: endpoint.Aliases,
+		})
+	}
+	return sortNetworks(networks)
 }
@@ -52,6 +52,8 @@ func testPodman(t *testing.T, withFetcher bool) {
 		_, err = images.Pull(podmanCtx, "alpine:3.20.3", nil)
 		assert.NoError(t, err)
 	}
+	img, err := images.GetImage(podmanCtx, "alpine:3.20.3", new(images.GetOptions).WithSize(true))
+	assert.NoError(t, err)
 
 	engine, err := newPodmanEngine(context.Background(), slog.Default(), podmanSocket)
 	assert.NoError(t, err)
@@ -81,6 +83,8 @@ func testPodman(t *testing.T, withFetcher bool) {
 		},
 	}, nil)
 	assert.NoError(t, err)
+	ctrData, err := containers.Inspect(podmanCtx, ctr.ID, nil)
+	assert.NoError(t, err)
 
 	imageId := "63b790fccc9078ab8bb913d94a5d869e19fca9b77712b315da3fa45bb8f14636"
 	if runtime.GOARCH == "arm64" {
@@ -90,25 +94,34 @@ func testPodman(t *testing.T, withFetcher bool) {
 	expectedEvent := event.Event{
 		Info: event.Info{
 			Container: event.Container{
-				Type:           typePodman.ToCTValue(),
-				ID:             shortContainerID(ctr.ID),
-				Name:           "test_container",
-				Image:          "docker.io/library/alpine:3.20.3",
-				ImageDigest:    "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
-				ImageID:        imageId,
-				ImageRepo:      "docker.io/library/alpine",
-				ImageTag:       "3.20.3",
-				User:           "testuser",
-				CPUPeriod:      defaultCpuPeriod,
-				CPUQuota:       2000,
-				CPUShares:      defaultCpuShares,
-				CPUSetCPUCount: 2, // 0-1
-				FullID:         ctr.ID,
-				Labels:         map[string]string{"foo": "bar"},
-				Privileged:     true,
-				Mounts:         []event.Mount{},
-				PortMappings:   []event.PortMapping{},
-				Size:           -1,
+				Type:            typePodman.ToCTValue(),
+				ID:              shortContainerID(ctr.ID),
+				Name:            "test_container",
+				Image:           "docker.io/library/alpine:3.20.3",
+				ImageDigest:     "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a",
+				ImageID:         imageId,
+				ImageRepo:       "docker.io/library/alpine",
+				ImageTag:        "3.20.3",
+				ImageRegistry:   "docker.io",
+				ImageSize:       img.Size,
+				ImageLayers:     1,
+				User:            "testuser",
+				CPUPeriod:       defaultCpuPeriod,
+				CPUQuota:        2000,
+				CPUShares:       defaultCpuShares,
+				CPUSetCPUCount:  2, // 0-1
+				FullID:          ctr.ID,
+				Labels:          map[string]string{"foo": "bar"},
+				Privileged:      true,
+				Runtime:         ctrData.OCIRuntime,
+				Networks:        podmanNetworks(ctrData.NetworkSettings.Networks),
+				CapEffective:    ctrData.EffectiveCaps,
+				SeccompProfile:  "unconfined",
+				AppArmorProfile: ctrData.AppArmorProfile,
+				SELinuxLabel:    ctrData.ProcessLabel,
+				Mounts:          []event.Mount{},
+				PortMappings:    []event.PortMapping{},
+				Size:            -1,
 			}},
 		IsCreate: true,
 	}
@@ -128,6 +141,8 @@ func testPodman(t *testing.T, withFetcher bool) {
 			found = true
 			// We don't have this before creation
 			expectedEvent.CreatedTime = evt.CreatedTime
+			expectedEvent.CreatedAt = evt.CreatedAt
+			expectedEvent.StartedAt = evt.StartedAt
 			assert.Contains(t, evt.Env, "env=env")
 			expectedEvent.Env = evt.Env
 			assert.Equal(t, expectedEvent, evt)
@@ -168,3 +183,48 @@ func testPodman(t *testing.T, withFetcher bool) {
 func TestPodman(t *testing.T) {
 	testPodman(t, false)
 }
+
+func TestParsePodmanPort(t *testing.T) {
+	tCases := map[string]struct {
+		port            string
+		expectedPort    int
+		expectedProto   string
+		successExpected bool
+	}{
+		"Tcp port": {
+			port:            "80/tcp",
+			expectedPort:    80,
+			expectedProto:   "tcp",
+			successExpected: true,
+		},
+		"Udp port": {
+			port:            "53/udp",
+			expectedPort:    53,
+			expectedProto:   "udp",
+			successExpected: true,
+		},
+		"Port without protocol": {
+			port:            "8080",
+			expectedPort:    8080,
+			expectedProto:   "tcp",
+			successExpected: true,
+		},
+		"Wrong literal": {
+			port:            "http/tcp",
+			successExpected: false,
+		},
+	}
+
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			port, proto, err := parsePodmanPort(tc.port)
+			if !tc.successExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPort, port)
+				assert.Equal(t, tc.expectedProto, proto)
+			}
+		})
+	}
+}
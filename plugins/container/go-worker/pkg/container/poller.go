@@ -0,0 +1,114 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// listFunc lists all containers of an engine.
+type listFunc func(ctx context.Context) ([]event.Event, error)
+
+// pollEvents is used by engines without an events API: it lists containers every interval,
+// sending create events for containers that appeared or changed and remove events
+// for containers that disappeared since last poll.
+// Containers listed on first call are considered already known.
+func pollEvents(ctx context.Context, wg *sync.WaitGroup, logger *slog.Logger,
+	interval time.Duration, list listFunc) (<-chan event.Event, error) {
+	list = recoveredList(list)
+	evts, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := &poller{logger: logger, list: list, known: make(map[string]event.Info, len(evts))}
+	for _, evt := range evts {
+		p.known[evt.ID] = evt.Info
+	}
+
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer close(outCh)
+		defer wg.Done()
+		supervise(ctx, logger, "poller", func() error {
+			return p.poll(ctx, interval, outCh)
+		})
+	}()
+	return outCh, nil
+}
+
+// poller tracks the containers listed by pollEvents.
+type poller struct {
+	logger *slog.Logger
+	list   listFunc
+	known  map[string]event.Info
+}
+
+// poll lists containers every interval until ctx is done, notifying the differences with the known ones.
+func (p *poller) poll(ctx context.Context, interval time.Duration, outCh chan<- event.Event) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	send := func(evt event.Event) bool {
+		select {
+		case outCh <- evt:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			evts, err := p.list(ctx)
+			if err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelDebug, "failed to list containers", slog.String("err", err.Error()))
+				continue
+			}
+			current := make(map[string]event.Info, len(evts))
+			for _, evt := range evts {
+				current[evt.ID] = evt.Info
+				// Containers whose metadata changed are sent again as create events,
+				// overwriting the stale ones.
+				if info, ok := p.known[evt.ID]; ok && reflect.DeepEqual(info, evt.Info) {
+					continue
+				}
+				if !config.IsHookEnabled(config.HookCreate) && !config.IsHookEnabled(config.HookStart) {
+					continue
+				}
+				p.logger.LogAttrs(ctx, config.LevelTrace, "container create event", slog.String("container_id", evt.ID))
+				if !send(evt) {
+					return nil
+				}
+			}
+			for id, info := range p.known {
+				if _, ok := current[id]; ok || !config.IsHookEnabled(config.HookRemove) {
+					continue
+				}
+				p.logger.LogAttrs(ctx, config.LevelTrace, "container remove event", slog.String("container_id", id))
+				// Send the minimum set of infos
+				if !send(event.Event{
+					Info: event.Info{
+						Container: event.Container{
+							Type:   info.Type,
+							ID:     info.ID,
+							FullID: info.FullID,
+							Name:   info.Name,
+							Image:  info.Image,
+						},
+					},
+					IsCreate: false,
+				}) {
+					return nil
+				}
+			}
+			p.known = current
+		}
+	}
+}
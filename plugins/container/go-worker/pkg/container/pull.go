@@ -0,0 +1,91 @@
+package container
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// imagePullWindow is how long after an image pull the containers created from it
+// are considered as started from a freshly pulled image.
+const imagePullWindow = 10 * time.Minute
+
+// imagePulls tracks the image pulls notified by the engines events streams.
+var imagePulls = newPullTracker()
+
+// pullTracker remembers when images got last pulled, by normalized image reference,
+// so that pulls can be correlated with the containers created right after them.
+// Engines events streams record pulls from their own goroutines, hence the lock.
+type pullTracker struct {
+	mu sync.Mutex
+	// pulls holds the pull timestamps, in nanoseconds since epoch.
+	pulls map[string]int64
+}
+
+func newPullTracker() *pullTracker {
+	return &pullTracker{pulls: make(map[string]int64)}
+}
+
+// normalizedImageRef returns the fully qualified form of an image reference, defaulting its tag to "latest",
+// so that eg: "nginx" and "docker.io/library/nginx:latest" match.
+// References that are not valid (eg: image IDs) are returned as is.
+func normalizedImageRef(image string) string {
+	if image == "" || strings.HasPrefix(image, "sha256:") {
+		return image
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return reference.TagNameOnly(named).String()
+}
+
+// pulled records the pull of an image, forgetting the pulls older than imagePullWindow.
+func (p *pullTracker) pulled(image string, pulledAt int64) {
+	if image == "" || pulledAt <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pulls[normalizedImageRef(image)] = pulledAt
+	for ref, at := range p.pulls {
+		if pulledAt-at > imagePullWindow.Nanoseconds() {
+			delete(p.pulls, ref)
+		}
+	}
+}
+
+// attach sets the pull timestamp of the image of a container, if pulled within imagePullWindow
+// before the container got created (or before now, when the creation time is unknown).
+func (p *pullTracker) attach(info *event.Info) {
+	if info.Image == "" {
+		return
+	}
+	createdAt := info.CreatedAt
+	if createdAt == 0 {
+		createdAt = time.Now().UnixNano()
+	}
+	p.mu.Lock()
+	pulledAt, ok := p.pulls[normalizedImageRef(info.Image)]
+	p.mu.Unlock()
+	if !ok || pulledAt > createdAt || createdAt-pulledAt > imagePullWindow.Nanoseconds() {
+		return
+	}
+	info.ImagePulledAt = pulledAt
+}
+
+// withImagePull wraps inspect to attach the pull timestamp of the image, if freshly pulled, to the inspected infos.
+func withImagePull(inspect inspectFunc) inspectFunc {
+	return func(ctx context.Context) (event.Info, error) {
+		info, err := inspect(ctx)
+		if err == nil {
+			imagePulls.attach(&info)
+		}
+		return info, err
+	}
+}
@@ -0,0 +1,95 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestNormalizedImageRef(t *testing.T) {
+	tCases := map[string]struct {
+		image    string
+		expected string
+	}{
+		"short name":     {image: "nginx", expected: "docker.io/library/nginx:latest"},
+		"tagged":         {image: "nginx:1.25", expected: "docker.io/library/nginx:1.25"},
+		"fully named":    {image: "docker.io/library/nginx:latest", expected: "docker.io/library/nginx:latest"},
+		"other registry": {image: "quay.io/foo/bar", expected: "quay.io/foo/bar:latest"},
+		"image id":       {image: "sha256:aaaa", expected: "sha256:aaaa"},
+		"invalid":        {image: "Invalid Image", expected: "Invalid Image"},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizedImageRef(tc.image))
+		})
+	}
+}
+
+func TestPullTracker(t *testing.T) {
+	now := time.Now().UnixNano()
+	minute := time.Minute.Nanoseconds()
+	pulls := newPullTracker()
+	pulls.pulled("nginx:latest", now-2*minute)
+	pulls.pulled("quay.io/foo/bar:1.0", now-time.Hour.Nanoseconds())
+
+	tCases := map[string]struct {
+		info     event.Info
+		expected int64
+	}{
+		"pulled before creation": {
+			info:     event.Info{Container: event.Container{Image: "docker.io/library/nginx", CreatedAt: now - minute}},
+			expected: now - 2*minute,
+		},
+		"unknown creation time": {
+			info:     event.Info{Container: event.Container{Image: "nginx"}},
+			expected: now - 2*minute,
+		},
+		"created before the pull": {
+			info: event.Info{Container: event.Container{Image: "nginx", CreatedAt: now - 3*minute}},
+		},
+		"created long after the pull": {
+			info: event.Info{Container: event.Container{Image: "nginx", CreatedAt: now + time.Hour.Nanoseconds()}},
+		},
+		"pull out of the window": {
+			info: event.Info{Container: event.Container{Image: "quay.io/foo/bar:1.0", CreatedAt: now}},
+		},
+		"other tag": {
+			info: event.Info{Container: event.Container{Image: "nginx:1.25", CreatedAt: now}},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			info := tc.info
+			pulls.attach(&info)
+			assert.Equal(t, tc.expected, info.ImagePulledAt)
+		})
+	}
+
+	// Pulls out of the window are forgotten on the next pull
+	pulls.pulled("alpine", now)
+	assert.Len(t, pulls.pulls, 2)
+}
+
+func TestEnrichImagePull(t *testing.T) {
+	now := time.Now().UnixNano()
+	imagePulls.pulled("busybox:1.36", now)
+	t.Cleanup(func() {
+		imagePulls = newPullTracker()
+	})
+
+	outCh := make(chan event.Event, 1)
+	enr := newEnricher(typeDocker, outCh)
+	enr.timeout = 0
+	minimal := event.Info{Container: event.Container{ID: "test"}}
+	enr.enrich(context.Background(), minimal, func(_ context.Context) (event.Info, error) {
+		return event.Info{Container: event.Container{ID: "test", Image: "busybox:1.36", CreatedAt: now + 1}}, nil
+	})
+	evt := <-outCh
+	require.True(t, evt.IsCreate)
+	assert.Equal(t, now, evt.ImagePulledAt)
+}
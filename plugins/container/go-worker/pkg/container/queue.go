@@ -0,0 +1,123 @@
+package container
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// EventQueue is a bounded queue of the events waiting to be consumed by the plugin,
+// so that a slow consumer does not stall the engines, nor makes the worker memory grow unbounded.
+// It supports a single producer.
+type EventQueue struct {
+	mu         sync.Mutex
+	events     []event.Event
+	size       int
+	dropOldest bool
+	// ready is signaled when events are queued, room when events are consumed.
+	ready chan struct{}
+	room  chan struct{}
+}
+
+// NewEventQueue returns an EventQueue configured from the current config.
+func NewEventQueue() *EventQueue {
+	return newEventQueue(config.GetEventQueueSize(), config.GetEventQueuePolicy() == config.EventQueueDropOldest)
+}
+
+func newEventQueue(size int, dropOldest bool) *EventQueue {
+	return &EventQueue{
+		events:     make([]event.Event, 0, size),
+		size:       size,
+		dropOldest: dropOldest,
+		ready:      make(chan struct{}, 1),
+		room:       make(chan struct{}, 1),
+	}
+}
+
+// Ready returns a channel signaled when events can be popped.
+func (q *EventQueue) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Pop returns the oldest queued event, if any.
+func (q *EventQueue) Pop() (event.Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == 0 {
+		return event.Event{}, false
+	}
+	evt := q.events[0]
+	q.events[0] = event.Event{}
+	q.events = q.events[1:]
+	if len(q.events) > 0 {
+		signal(q.ready)
+	}
+	signal(q.room)
+	return evt, true
+}
+
+// Push queues an event. When the queue is full, it either waits for the consumer
+// until ctx is done, or drops the oldest queued events, depending on the queue policy.
+// Only container added, updated and stats events are dropped: the other ones (eg: removals)
+// are never lost, since the plugin would otherwise keep stale containers;
+// they wait for the consumer if there is nothing to drop.
+func (q *EventQueue) Push(ctx context.Context, evt event.Event) {
+	for {
+		if q.push(evt) {
+			return
+		}
+		select {
+		case <-q.room:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// push queues evt, making room for it according to the queue policy.
+// It returns false if evt has to wait for the consumer.
+func (q *EventQueue) push(evt event.Event) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) >= q.size {
+		if !q.dropOldest {
+			return false
+		}
+		idx := slices.IndexFunc(q.events, droppable)
+		switch {
+		case idx >= 0:
+			q.events = append(q.events[:idx], q.events[idx+1:]...)
+			countEventDropped()
+		case droppable(evt):
+			// Every queued event has to be kept: drop the new one.
+			countEventDropped()
+			return true
+		default:
+			return false
+		}
+	}
+	q.events = append(q.events, evt)
+	signal(q.ready)
+	return true
+}
+
+// droppable reports whether evt can be dropped from a full queue.
+func droppable(evt event.Event) bool {
+	switch evt.Kind() {
+	case event.KindAdded, event.KindUpdated, event.KindStats:
+		return true
+	default:
+		return false
+	}
+}
+
+// signal notifies ch, a channel with a buffer of 1, without blocking.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,119 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func queueEvent(id string) event.Event {
+	return event.Event{Info: event.Info{Container: event.Container{ID: id}}, IsCreate: true}
+}
+
+// popEvent waits for the next queued event.
+func popEvent(t *testing.T, q *EventQueue) event.Event {
+	select {
+	case <-q.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("no queued event")
+	}
+	evt, ok := q.Pop()
+	require.True(t, ok)
+	return evt
+}
+
+func TestEventQueueDropOldest(t *testing.T) {
+	q := newEventQueue(2, true)
+	dropped := Metric(MetricEventsDropped)
+
+	for _, id := range []string{"first", "second", "third"} {
+		q.Push(context.Background(), queueEvent(id))
+	}
+	assert.Equal(t, dropped+1, Metric(MetricEventsDropped))
+	assert.Equal(t, "second", popEvent(t, q).ID)
+	assert.Equal(t, "third", popEvent(t, q).ID)
+}
+
+func TestEventQueueDropOldestKeepsRemovals(t *testing.T) {
+	q := newEventQueue(2, true)
+	dropped := Metric(MetricEventsDropped)
+
+	removed := queueEvent("removed")
+	removed.IsCreate = false
+	q.Push(context.Background(), removed)
+	q.Push(context.Background(), queueEvent("first"))
+	// The oldest droppable event makes room, removals are kept
+	q.Push(context.Background(), queueEvent("second"))
+	assert.Equal(t, dropped+1, Metric(MetricEventsDropped))
+
+	died := queueEvent("died")
+	died.IsDie = true
+	q.Push(context.Background(), died)
+	assert.Equal(t, dropped+2, Metric(MetricEventsDropped))
+
+	// Nothing left to drop: new droppable events are dropped, the other ones wait for the consumer
+	q.Push(context.Background(), queueEvent("third"))
+	assert.Equal(t, dropped+3, Metric(MetricEventsDropped))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Push(ctx, removed)
+	assert.Equal(t, dropped+3, Metric(MetricEventsDropped))
+
+	evt := popEvent(t, q)
+	assert.Equal(t, event.KindRemoved, evt.Kind())
+	evt = popEvent(t, q)
+	assert.Equal(t, event.KindDied, evt.Kind())
+	_, ok := q.Pop()
+	assert.False(t, ok)
+}
+
+func TestEventQueueBlock(t *testing.T) {
+	q := newEventQueue(1, false)
+	dropped := Metric(MetricEventsDropped)
+	q.Push(context.Background(), queueEvent("first"))
+
+	pushed := make(chan struct{})
+	go func() {
+		q.Push(context.Background(), queueEvent("second"))
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push did not block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, "first", popEvent(t, q).ID)
+	<-pushed
+	assert.Equal(t, "second", popEvent(t, q).ID)
+	assert.Equal(t, dropped, Metric(MetricEventsDropped))
+
+	// A blocked push gives up once ctx is done
+	q.Push(context.Background(), queueEvent("third"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Push(ctx, queueEvent("fourth"))
+	assert.Equal(t, "third", popEvent(t, q).ID)
+	_, ok := q.Pop()
+	assert.False(t, ok)
+}
+
+func TestNewEventQueue(t *testing.T) {
+	t.Cleanup(func() { _ = config.Load(`{"event_queue": {"size": 1024, "policy": "block"}}`) })
+
+	require.NoError(t, config.Load(`{"event_queue": {"size": 0, "policy": "unknown"}}`))
+	q := NewEventQueue()
+	assert.Equal(t, 1, q.size)
+	assert.False(t, q.dropOldest)
+
+	require.NoError(t, config.Load(`{"event_queue": {"size": 16, "policy": "drop_oldest"}}`))
+	q = NewEventQueue()
+	assert.Equal(t, 16, q.size)
+	assert.True(t, q.dropOldest)
+}
@@ -0,0 +1,294 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	reconnectMinBackoff = 500 * time.Millisecond
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// reconnects counts the event streams reconnections of all engines.
+var reconnects atomic.Uint64
+
+// Reconnects returns the number of times engines had to reconnect to their events stream.
+func Reconnects() uint64 {
+	return reconnects.Load()
+}
+
+// backoff is an exponential backoff, doubling the delay at each attempt up to max.
+type backoff struct {
+	min   time.Duration
+	max   time.Duration
+	delay time.Duration
+}
+
+func newBackoff(minDelay, maxDelay time.Duration) *backoff {
+	return &backoff{min: minDelay, max: maxDelay}
+}
+
+func (b *backoff) next() time.Duration {
+	if b.delay == 0 {
+		b.delay = b.min
+	} else {
+		b.delay = min(b.delay*2, b.max)
+	}
+	return b.delay
+}
+
+func (b *backoff) reset() {
+	b.delay = 0
+}
+
+// errStreamClosed is returned by the events loops when their events stream got closed without an error.
+var errStreamClosed = errors.New("stream closed")
+
+// streamError returns the error that dropped an events stream, or errStreamClosed if it just got closed.
+func streamError(err error) error {
+	if err == nil {
+		return errStreamClosed
+	}
+	return err
+}
+
+// waitReconnect waits for the next backoff delay before reconnecting to an events stream.
+// The first drop since the engine was last reachable notifies it as down.
+// It returns false if ctx got cancelled in the meantime.
+func waitReconnect(ctx context.Context, logger *slog.Logger, ep endpoint, b *backoff, err error) bool {
+	if b.delay == 0 {
+		notifyStatus(ep, event.EngineStatusDown, err)
+	}
+	delay := b.next()
+	errStr := "stream closed"
+	if err != nil {
+		errStr = err.Error()
+	}
+	logger.LogAttrs(ctx, slog.LevelWarn, "events stream dropped, reconnecting", slog.String("err", errStr), slog.Duration("backoff", delay))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		reconnects.Add(1)
+		return true
+	}
+}
+
+// knownContainers tracks the containers notified by an engine listener,
+// so that resyncs after a reconnection only notify what changed while the events stream was down.
+type knownContainers struct {
+	mu    sync.Mutex
+	infos map[string]event.Info
+}
+
+func newKnownContainers() *knownContainers {
+	return &knownContainers{infos: make(map[string]event.Info)}
+}
+
+// seed records the containers listed when the engine gets attached, without notifying them.
+func (k *knownContainers) seed(evts []event.Event) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, evt := range evts {
+		k.infos[evt.ID] = evt.Info
+	}
+}
+
+// track records an event notified by the listener.
+// Lifecycle events of existing containers (die, pause, unpause, oom, stats) do not change the known containers.
+func (k *knownContainers) track(evt event.Event) {
+	if evt.IsDie || evt.IsPause || evt.IsUnpause || evt.IsOOM || evt.IsStats {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !evt.IsCreate {
+		delete(k.infos, evt.ID)
+		return
+	}
+	if _, ok := k.infos[evt.ID]; ok && evt.IsPartial {
+		// Do not overwrite the full infos of a container with its minimal ones.
+		return
+	}
+	k.infos[evt.ID] = evt.Info
+}
+
+// reconcile returns the events notifying the differences between the known containers and the listed ones:
+// create events for new containers, update events for containers whose infos changed,
+// and remove events for containers that disappeared.
+func (k *knownContainers) reconcile(evts []event.Event) []event.Event {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	res := make([]event.Event, 0)
+	current := make(map[string]struct{}, len(evts))
+	for _, evt := range evts {
+		current[evt.ID] = struct{}{}
+		info, ok := k.infos[evt.ID]
+		switch {
+		case !ok:
+			res = append(res, evt)
+		case evt.IsPartial || reflect.DeepEqual(info, evt.Info):
+			// Unchanged, or not inspected in time: keep the known infos.
+		default:
+			evt.IsUpdate = true
+			res = append(res, evt)
+		}
+	}
+	for id, info := range k.infos {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		// Forget the containers that disappeared even if their removal is not notified,
+		// not to track them forever.
+		delete(k.infos, id)
+		if !config.IsHookEnabled(config.HookRemove) {
+			continue
+		}
+		// Send the minimum set of infos
+		res = append(res, event.Event{
+			Info: event.Info{
+				Container: event.Container{
+					Type:         info.Type,
+					ID:           info.ID,
+					FullID:       info.FullID,
+					Image:        info.Image,
+					EngineSocket: info.EngineSocket,
+					RootlessUID:  info.RootlessUID,
+				},
+			},
+			IsCreate: false,
+		})
+	}
+	return res
+}
+
+// forward sends the events of in to the returned channel, tracking them.
+// Containers listed when the engine got attached are considered already known (see seed).
+// If config.GetReconcileInterval() is set, containers are also periodically listed
+// and reconciled with the known ones, to repair the drift due to missed events.
+// The returned channel gets closed once in is closed.
+func (k *knownContainers) forward(ctx context.Context, wg *sync.WaitGroup, logger *slog.Logger, list listFunc,
+	in <-chan event.Event) <-chan event.Event {
+	list = recoveredList(list)
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer func() {
+			close(outCh)
+			wg.Done()
+		}()
+		send := func(evt event.Event) {
+			k.track(evt)
+			select {
+			case outCh <- evt:
+			case <-ctx.Done():
+				// Keep draining until the listener closes its channel.
+			}
+		}
+
+		var tickCh <-chan time.Time
+		if interval := config.GetReconcileInterval(); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tickCh = ticker.C
+		}
+		// Sweeps list containers on their own goroutine, not to stall the events of the listener.
+		var sweepCh chan []event.Event
+		for {
+			select {
+			case evt, ok := <-in:
+				if !ok {
+					return
+				}
+				send(evt)
+			case <-tickCh:
+				if sweepCh != nil || ctx.Err() != nil {
+					// Previous sweep still running.
+					break
+				}
+				sweepCh = make(chan []event.Event, 1)
+				go func(resCh chan<- []event.Event) {
+					evts, err := list(ctx)
+					if err != nil {
+						logger.LogAttrs(ctx, slog.LevelDebug, "failed to list containers for reconciliation", slog.String("err", err.Error()))
+					}
+					resCh <- evts
+				}(sweepCh)
+			case evts := <-sweepCh:
+				sweepCh = nil
+				if evts == nil {
+					break
+				}
+				evts = k.reconcile(evts)
+				if len(evts) > 0 {
+					logger.LogAttrs(ctx, slog.LevelInfo, "reconciled containers drift", slog.Int("changes", len(evts)))
+				}
+				countReconciled(len(evts))
+				for _, evt := range evts {
+					send(evt)
+				}
+			}
+		}
+	}()
+	return outCh
+}
+
+// uncached returns a listFunc bypassing the metadata cache, so that reconciliations
+// compare the known containers with their current infos, and not with the cached ones.
+func uncached(list func(ctx context.Context, cached bool) ([]event.Event, error)) listFunc {
+	return func(ctx context.Context) ([]event.Event, error) {
+		return list(ctx, false)
+	}
+}
+
+// uncachedLister is implemented by the engines whose listings can bypass the metadata cache.
+type uncachedLister interface {
+	list(ctx context.Context, cached bool) ([]event.Event, error)
+}
+
+// listUncached lists the containers of an engine as ListRecovered, bypassing the metadata cache if supported.
+func listUncached(ctx context.Context, engine Engine) ([]event.Event, error) {
+	if l, ok := engine.(uncachedLister); ok {
+		return recoveredList(uncached(l.list))(ctx)
+	}
+	return ListRecovered(ctx, engine)
+}
+
+// resync reconciles the known containers with the ones listed by an engine after a reconnection,
+// since events might have been lost in the meantime (eg: the daemon got restarted):
+// new containers are sent as create events, changed ones as update events,
+// and the ones that disappeared as remove events.
+// Once the engine is reachable again, the backoff gets reset and the engine is notified as up.
+// It returns false if ctx got cancelled in the meantime.
+func resync(ctx context.Context, logger *slog.Logger, ep endpoint, b *backoff, list listFunc, known *knownContainers,
+	outCh chan<- event.Event) bool {
+	evts, err := recoveredList(list)(ctx)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelDebug, "failed to list containers after reconnection", slog.String("err", err.Error()))
+		return true
+	}
+	b.reset()
+	notifyStatus(ep, event.EngineStatusUp, nil)
+	evts = known.reconcile(evts)
+	countReconciled(len(evts))
+	logger.LogAttrs(ctx, slog.LevelDebug, "resynced containers after reconnection", slog.Int("changes", len(evts)))
+	for _, evt := range evts {
+		select {
+		case outCh <- evt:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(time.Second, 5*time.Second)
+	assert.Equal(t, time.Second, b.next())
+	assert.Equal(t, 2*time.Second, b.next())
+	assert.Equal(t, 4*time.Second, b.next())
+	assert.Equal(t, 5*time.Second, b.next())
+	assert.Equal(t, 5*time.Second, b.next())
+	b.reset()
+	assert.Equal(t, time.Second, b.next())
+}
+
+func TestWaitReconnect(t *testing.T) {
+	before := Reconnects()
+	b := newBackoff(time.Millisecond, time.Millisecond)
+	assert.True(t, waitReconnect(context.Background(), slog.Default(), endpoint{}, b, errors.New("EOF")))
+	assert.Equal(t, before+1, Reconnects())
+
+	// A cancelled context stops the reconnection
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = newBackoff(time.Hour, time.Hour)
+	assert.False(t, waitReconnect(ctx, slog.Default(), endpoint{}, b, nil))
+	assert.Equal(t, before+1, Reconnects())
+}
+
+func TestResync(t *testing.T) {
+	evts := []event.Event{
+		{Info: event.Info{Container: event.Container{ID: "first"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "second"}}, IsCreate: true},
+	}
+	list := func(_ context.Context) ([]event.Event, error) {
+		return evts, nil
+	}
+	failingList := func(_ context.Context) ([]event.Event, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	b := newBackoff(time.Second, time.Minute)
+	b.next()
+	b.next()
+
+	// Engine still unreachable: the backoff is kept
+	known := newKnownContainers()
+	outCh := make(chan event.Event, len(evts))
+	assert.True(t, resync(context.Background(), slog.Default(), endpoint{}, b, failingList, known, outCh))
+	assert.Len(t, outCh, 0)
+	assert.Equal(t, 4*time.Second, b.next())
+
+	// Unknown containers are sent and the backoff is reset
+	assert.True(t, resync(context.Background(), slog.Default(), endpoint{}, b, list, known, outCh))
+	assert.Equal(t, evts[0], <-outCh)
+	assert.Equal(t, evts[1], <-outCh)
+	assert.Equal(t, time.Second, b.next())
+}
+
+// listingEngine records whether its listings are served from the metadata cache.
+type listingEngine struct {
+	Engine
+	cached []bool
+}
+
+func (e *listingEngine) list(_ context.Context, cached bool) ([]event.Event, error) {
+	e.cached = append(e.cached, cached)
+	return nil, nil
+}
+
+func TestListUncached(t *testing.T) {
+	engine := &listingEngine{}
+	_, err := listUncached(context.Background(), engine)
+	assert.NoError(t, err)
+	_, err = uncached(engine.list)(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false}, engine.cached)
+}
+
+func TestKnownContainersReconcile(t *testing.T) {
+	require.NoError(t, config.Load(fmt.Sprintf(`{"hooks": %d}`, config.HookCreate|config.HookRemove)))
+
+	known := newKnownContainers()
+	known.seed([]event.Event{
+		{Info: event.Info{Container: event.Container{ID: "unchanged", Name: "unchanged"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "changed", Name: "before"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "partial", Name: "partial"}}, IsCreate: true},
+	})
+	// Tracked events update the known containers
+	known.track(event.Event{Info: event.Info{Container: event.Container{ID: "removed", FullID: "removed-full", Type: 7}}, IsCreate: true})
+	known.track(event.Event{Info: event.Info{Container: event.Container{ID: "partial"}}, IsCreate: true, IsPartial: true})
+	known.track(event.Event{Info: event.Info{Container: event.Container{ID: "unchanged"}}, IsDie: true})
+
+	listed := []event.Event{
+		{Info: event.Info{Container: event.Container{ID: "unchanged", Name: "unchanged"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "changed", Name: "after"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "partial"}}, IsCreate: true, IsPartial: true},
+		{Info: event.Info{Container: event.Container{ID: "new", Name: "new"}}, IsCreate: true},
+	}
+	assert.Equal(t, []event.Event{
+		{Info: event.Info{Container: event.Container{ID: "changed", Name: "after"}}, IsCreate: true, IsUpdate: true},
+		{Info: event.Info{Container: event.Container{ID: "new", Name: "new"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "removed", FullID: "removed-full", Type: 7}}, IsCreate: false},
+	}, known.reconcile(listed))
+
+	// Once notified, the reconciled events are tracked: nothing changed anymore
+	for _, evt := range known.reconcile(listed) {
+		known.track(evt)
+	}
+	assert.Empty(t, known.reconcile(listed))
+
+	// Without the remove hook, removed containers are forgotten without notifying them
+	require.NoError(t, config.Load(fmt.Sprintf(`{"hooks": %d}`, config.HookCreate)))
+	t.Cleanup(func() {
+		_ = config.Load(`{}`)
+	})
+	assert.Empty(t, known.reconcile(listed[:1]))
+	assert.Len(t, known.infos, 1)
+}
+
+func TestKnownContainersSweep(t *testing.T) {
+	require.NoError(t, config.Load(fmt.Sprintf(`{"hooks": %d, "reconcile_interval_ms": 10}`, config.HookCreate|config.HookRemove)))
+	t.Cleanup(func() {
+		_ = config.Load(`{"reconcile_interval_ms": 0}`)
+	})
+
+	var (
+		mu     sync.Mutex
+		listed = []event.Event{
+			{Info: event.Info{Container: event.Container{ID: "kept"}}, IsCreate: true},
+			{Info: event.Info{Container: event.Container{ID: "notified"}}, IsCreate: true},
+			{Info: event.Info{Container: event.Container{ID: "missed-remove"}}, IsCreate: true},
+		}
+	)
+	list := func(_ context.Context) ([]event.Event, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return listed, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	in := make(chan event.Event)
+	// Containers listed when attached are already known
+	known := newKnownContainers()
+	known.seed(listed)
+	out := known.forward(ctx, &wg, slog.Default(), list, in)
+	t.Cleanup(func() {
+		cancel()
+		close(in)
+		wg.Wait()
+	})
+
+	// Listener events are forwarded as is
+	evt := event.Event{Info: event.Info{Container: event.Container{ID: "notified"}}, IsCreate: true}
+	in <- evt
+	assert.Equal(t, evt, waitOnChannelOrTimeout(t, out))
+
+	// Events missed by the listener get repaired by the next sweep
+	before := Metric(MetricReconciled)
+	mu.Lock()
+	listed = []event.Event{
+		{Info: event.Info{Container: event.Container{ID: "kept"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "notified"}}, IsCreate: true},
+		{Info: event.Info{Container: event.Container{ID: "missed-create"}}, IsCreate: true},
+	}
+	mu.Unlock()
+	assert.Equal(t, event.Event{Info: event.Info{Container: event.Container{ID: "missed-create"}}, IsCreate: true}, waitOnChannelOrTimeout(t, out))
+	assert.Equal(t, event.Event{Info: event.Info{Container: event.Container{ID: "missed-remove"}}, IsCreate: false}, waitOnChannelOrTimeout(t, out))
+	assert.Equal(t, before+2, Metric(MetricReconciled))
+}
@@ -0,0 +1,93 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// recoverAsError converts a panic of the calling goroutine (ie: on a malformed engine response)
+// into an error stored in err, accounting for it in the worker metrics.
+// It must be deferred.
+func recoverAsError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	countPanic()
+	slog.Default().LogAttrs(context.Background(), slog.LevelWarn, "recovered panic", slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+	*err = fmt.Errorf("panic: %v", r)
+}
+
+// runRecovered calls f, returning its panic, if any, as an error.
+func runRecovered(f func()) (err error) {
+	defer recoverAsError(&err)
+	f()
+	return nil
+}
+
+// recovered wraps inspect so that its panics are returned as errors,
+// thus making the container be reported with its minimal set of infos.
+func recovered(inspect inspectFunc) inspectFunc {
+	return func(ctx context.Context) (info event.Info, err error) {
+		defer recoverAsError(&err)
+		return inspect(ctx)
+	}
+}
+
+// getRecovered inspects a container through g, returning its panics as errors.
+func getRecovered(ctx context.Context, g getter, containerID string) (evt *event.Event, err error) {
+	defer recoverAsError(&err)
+	return g.get(ctx, containerID)
+}
+
+// recoveredList wraps list so that its panics are returned as errors.
+func recoveredList(list listFunc) listFunc {
+	return func(ctx context.Context) (evts []event.Event, err error) {
+		defer recoverAsError(&err)
+		return list(ctx)
+	}
+}
+
+// recoveredGenerator wraps gen so that its panics (ie: while connecting to the engine) are returned as errors.
+func recoveredGenerator(gen engineGenerator) engineGenerator {
+	return func(ctx context.Context, logger *slog.Logger, socket string) (e Engine, err error) {
+		defer recoverAsError(&err)
+		return gen(ctx, logger, socket)
+	}
+}
+
+// ListRecovered lists the containers of engine, returning its panics as errors,
+// so that a malformed engine response does not take the plugin down.
+func ListRecovered(ctx context.Context, engine Engine) ([]event.Event, error) {
+	return recoveredList(engine.List)(ctx)
+}
+
+// supervise runs the events loop of a listener until it returns, restarting it with a backoff whenever it panics,
+// so that a single malformed event does not stop the listener for the rest of the worker lifetime.
+// It returns the error returned by loop, or ctx.Err() if ctx got cancelled while waiting for a restart.
+func supervise(ctx context.Context, logger *slog.Logger, listener string, loop func() error) error {
+	bo := newBackoff(reconnectMinBackoff, reconnectMaxBackoff)
+	for {
+		var loopErr error
+		err := runRecovered(func() {
+			loopErr = loop()
+		})
+		if err == nil {
+			return loopErr
+		}
+		delay := bo.next()
+		logger.LogAttrs(ctx, slog.LevelError, "listener failed, restarting", slog.String("listener", listener), slog.String("err", err.Error()), slog.Duration("backoff", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
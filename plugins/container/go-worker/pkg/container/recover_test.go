@@ -0,0 +1,69 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestRecovered(t *testing.T) {
+	panics := Metric(MetricPanics)
+	inspect := recovered(func(_ context.Context) (event.Info, error) {
+		var ctr *event.Container
+		return event.Info{Container: *ctr}, nil
+	})
+	_, err := inspect(context.Background())
+	assert.ErrorContains(t, err, "panic: runtime error: invalid memory address or nil pointer dereference")
+	assert.Equal(t, panics+1, Metric(MetricPanics))
+
+	assert.NoError(t, runRecovered(func() {}))
+	assert.Equal(t, panics+1, Metric(MetricPanics))
+}
+
+func TestEnricherRecoversInspectPanic(t *testing.T) {
+	outCh := make(chan event.Event, 1)
+	enr := newEnricher(typeDocker, outCh)
+	minimal := event.Info{Container: event.Container{ID: "test"}}
+	enr.enrich(context.Background(), minimal, func(_ context.Context) (event.Info, error) {
+		panic("malformed response")
+	})
+	assert.Equal(t, event.Event{Info: minimal, IsCreate: true, IsPartial: true}, <-outCh)
+}
+
+func TestSupervise(t *testing.T) {
+	panics := Metric(MetricPanics)
+	runs := 0
+	err := supervise(context.Background(), slog.Default(), "test", func() error {
+		runs++
+		if runs == 1 {
+			panic("malformed event")
+		}
+		return errStreamClosed
+	})
+	assert.ErrorIs(t, err, errStreamClosed)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, panics+1, Metric(MetricPanics))
+
+	// A cancelled context stops the restarts
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runs = 0
+	err = supervise(ctx, slog.Default(), "test", func() error {
+		runs++
+		panic("malformed event")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, runs)
+}
+
+func TestListRecovered(t *testing.T) {
+	list := recoveredList(func(_ context.Context) ([]event.Event, error) {
+		panic("malformed response")
+	})
+	_, err := list(context.Background())
+	assert.ErrorContains(t, err, "panic: malformed response")
+}
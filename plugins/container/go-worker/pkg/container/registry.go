@@ -0,0 +1,286 @@
+package container
+
+import (
+	"container/list"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+// registryDigests resolves the digest of images only referenced by tag.
+// It is nil, thus disabled, until InitDigestResolver is called with digest resolution enabled.
+var registryDigests *digestResolver
+
+const dockerHubRegistry = "registry-1.docker.io"
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var authParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+const (
+	// digestCacheMaxEntries bounds the resolved digests cache, evicting the least recently used ones.
+	digestCacheMaxEntries = 1024
+	// digestFailureTTL is the expiration of failed lookups, retried sooner than resolved digests.
+	digestFailureTTL = time.Minute
+)
+
+type digestEntry struct {
+	key     string
+	digest  string
+	expires time.Time
+}
+
+// digestResolver looks up image manifests digests from registries, caching the results
+// (failed lookups too, for a shorter time, to not hammer registries).
+// Lookups run in background, so that a slow registry does not stall the inspections.
+type digestResolver struct {
+	client     *http.Client
+	timeout    time.Duration
+	ttl        time.Duration
+	failureTTL time.Duration
+	maxEntries int
+	auths      map[string]config.RegistryAuth
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	pending    map[string]struct{}
+	wg         sync.WaitGroup
+}
+
+func newDigestResolver(cfg config.DigestResolutionCfg, client *http.Client) *digestResolver {
+	if !cfg.Enabled {
+		return nil
+	}
+	ttl := time.Duration(max(cfg.CacheTTLMs, 0)) * time.Millisecond
+	failureTTL := digestFailureTTL
+	if ttl > 0 {
+		failureTTL = min(failureTTL, ttl)
+	}
+	return &digestResolver{
+		client:     client,
+		timeout:    time.Duration(max(cfg.TimeoutMs, 0)) * time.Millisecond,
+		ttl:        ttl,
+		failureTTL: failureTTL,
+		maxEntries: digestCacheMaxEntries,
+		auths:      cfg.Auths,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		pending:    make(map[string]struct{}),
+	}
+}
+
+// InitDigestResolver sets up the lookup of missing image digests from the current config.
+func InitDigestResolver() {
+	registryDigests = newDigestResolver(config.GetDigestResolution(), http.DefaultClient)
+}
+
+// resolveImageDigest returns digest, if set, or the cached digest of image resolved from its registry.
+// An empty string is returned when the digest cannot be resolved, or while it is being looked up:
+// containers inspected in the meantime get it once inspected again (eg: by the reconciliation).
+func resolveImageDigest(image, digest string) string {
+	if digest != "" || registryDigests == nil {
+		return digest
+	}
+	return registryDigests.resolve(image)
+}
+
+func (r *digestResolver) resolve(image string) string {
+	if strings.HasPrefix(image, "sha256:") {
+		return ""
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	if _, ok := named.(reference.Digested); ok {
+		// Nothing to resolve.
+		return ""
+	}
+	tagged := reference.TagNameOnly(named).(reference.Tagged)
+	key := tagged.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if elem, ok := r.entries[key]; ok {
+		entry := elem.Value.(*digestEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			r.lru.MoveToFront(elem)
+			return entry.digest
+		}
+		r.lru.Remove(elem)
+		delete(r.entries, key)
+	}
+	if _, ok := r.pending[key]; ok || !spawnAllowed() {
+		return ""
+	}
+	r.pending[key] = struct{}{}
+	r.wg.Add(1)
+	go r.fetch(key, reference.Domain(named), reference.Path(named), tagged.Tag())
+	return ""
+}
+
+// fetch looks up the digest of key, caching the result.
+func (r *digestResolver) fetch(key, registry, repository, tag string) {
+	defer r.wg.Done()
+	ctx := context.Background()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	digest, err := r.lookup(ctx, registry, repository, tag)
+	ttl := r.ttl
+	if err != nil {
+		ttl = r.failureTTL
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, key)
+	r.entries[key] = r.lru.PushFront(&digestEntry{key: key, digest: digest, expires: expires})
+	if r.lru.Len() > r.maxEntries {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*digestEntry).key)
+	}
+}
+
+// lookup retrieves the manifest digest of repository:tag from registry,
+// authenticating through the registry token service when requested.
+func (r *digestResolver) lookup(ctx context.Context, registry, repository, tag string) (string, error) {
+	host := registry
+	if host == "docker.io" {
+		host = dockerHubRegistry
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, tag)
+	auth := r.auths[registry]
+
+	res, err := r.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		authorization, err := r.authorize(ctx, res.Header.Get("WWW-Authenticate"), auth)
+		if err != nil {
+			return "", err
+		}
+		res, err = r.headManifest(ctx, manifestURL, authorization)
+		if err != nil {
+			return "", err
+		}
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status from %s: %s", manifestURL, res.Status)
+	}
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("missing digest from %s", manifestURL)
+	}
+	return digest, nil
+}
+
+func (r *digestResolver) headManifest(ctx context.Context, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = res.Body.Close()
+	return res, nil
+}
+
+// authorize returns the Authorization header value answering the challenge of a registry.
+func (r *digestResolver) authorize(ctx context.Context, challenge string, auth config.RegistryAuth) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if auth.Username == "" {
+			return "", fmt.Errorf("missing credentials for basic authentication")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password)), nil
+	case "bearer":
+		token, err := r.token(ctx, params, auth)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// token requests an access token to the registry token service described by the bearer challenge params.
+func (r *digestResolver) token(ctx context.Context, params string, auth config.RegistryAuth) (string, error) {
+	values := make(map[string]string)
+	for _, match := range authParamRegexp.FindAllStringSubmatch(params, -1) {
+		values[match[1]] = match[2]
+	}
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", values["realm"])
+	}
+	query := realm.Query()
+	for _, param := range []string{"service", "scope"} {
+		if values[param] != "" {
+			query.Set(param, values[param])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth.Username != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status from %s: %s", realm.Host, res.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("missing token from %s", realm.Host)
+}
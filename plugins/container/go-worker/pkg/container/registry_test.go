@@ -0,0 +1,152 @@
+package container
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+const testManifestDigest = "sha256:1e42bbe2508154c9126d48c2b8a75420c3544343bf86fd041fb7527e017a4b4a"
+
+func newTestRegistry(t *testing.T, manifestHits *atomic.Int32) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "repository:foo/bar:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token": "secret"}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/foo/bar/manifests/"):
+			manifestHits.Add(1)
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test",scope="repository:foo/bar:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.HasSuffix(r.URL.Path, "/1.0") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", testManifestDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDigestResolver(t *testing.T) {
+	var manifestHits atomic.Int32
+	srv := newTestRegistry(t, &manifestHits)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	tCases := map[string]struct {
+		image          string
+		digest         string
+		auths          map[string]config.RegistryAuth
+		expectedDigest string
+		expectedHits   int32
+	}{
+		"resolved": {
+			image:          registry + "/foo/bar:1.0",
+			auths:          map[string]config.RegistryAuth{registry: {Username: "user", Password: "pass"}},
+			expectedDigest: testManifestDigest,
+			expectedHits:   2,
+		},
+		"digest already set": {
+			image:          registry + "/foo/bar:1.0",
+			digest:         "sha256:known",
+			expectedDigest: "sha256:known",
+		},
+		"digest in reference": {
+			image: registry + "/foo/bar@" + testManifestDigest,
+		},
+		"image ID": {
+			image: testManifestDigest,
+		},
+		"missing credentials": {
+			image:        registry + "/foo/bar:1.0",
+			expectedHits: 1,
+		},
+		"unknown tag": {
+			image:        registry + "/foo/bar:2.0",
+			auths:        map[string]config.RegistryAuth{registry: {Username: "user", Password: "pass"}},
+			expectedHits: 2,
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			manifestHits.Store(0)
+			registryDigests = newDigestResolver(config.DigestResolutionCfg{
+				Enabled:    true,
+				TimeoutMs:  1000,
+				CacheTTLMs: 60000,
+				Auths:      tc.auths,
+			}, srv.Client())
+			t.Cleanup(func() {
+				registryDigests = nil
+			})
+
+			// Digests are looked up in background
+			assert.Equal(t, tc.digest, resolveImageDigest(tc.image, tc.digest))
+			registryDigests.wg.Wait()
+			assert.Equal(t, tc.expectedDigest, resolveImageDigest(tc.image, tc.digest))
+			assert.Equal(t, tc.expectedHits, manifestHits.Load())
+
+			// Lookups, even failed ones, are cached
+			assert.Equal(t, tc.expectedDigest, resolveImageDigest(tc.image, tc.digest))
+			registryDigests.wg.Wait()
+			assert.Equal(t, tc.expectedHits, manifestHits.Load())
+		})
+	}
+}
+
+func TestDigestResolverCache(t *testing.T) {
+	var manifestHits atomic.Int32
+	srv := newTestRegistry(t, &manifestHits)
+	registry := strings.TrimPrefix(srv.URL, "https://")
+
+	registryDigests = newDigestResolver(config.DigestResolutionCfg{
+		Enabled:    true,
+		TimeoutMs:  1000,
+		CacheTTLMs: 60000,
+		Auths:      map[string]config.RegistryAuth{registry: {Username: "user", Password: "pass"}},
+	}, srv.Client())
+	t.Cleanup(func() {
+		registryDigests = nil
+	})
+	assert.Equal(t, digestFailureTTL, registryDigests.failureTTL)
+	registryDigests.maxEntries = 1
+
+	// Failed lookups expire sooner than resolved digests
+	registryDigests.failureTTL = time.Nanosecond
+	resolveImageDigest(registry+"/foo/bar:2.0", "")
+	registryDigests.wg.Wait()
+	time.Sleep(time.Millisecond)
+	resolveImageDigest(registry+"/foo/bar:2.0", "")
+	registryDigests.wg.Wait()
+	assert.Equal(t, int32(4), manifestHits.Load())
+
+	// The least recently used digests are evicted
+	resolveImageDigest(registry+"/foo/bar:1.0", "")
+	registryDigests.wg.Wait()
+	assert.Equal(t, testManifestDigest, resolveImageDigest(registry+"/foo/bar:1.0", ""))
+	assert.Len(t, registryDigests.entries, 1)
+	assert.Equal(t, 1, registryDigests.lru.Len())
+}
+
+func TestDigestResolverDisabled(t *testing.T) {
+	assert.Nil(t, newDigestResolver(config.DigestResolutionCfg{}, http.DefaultClient))
+	assert.Empty(t, resolveImageDigest("nginx:1.25", ""))
+}
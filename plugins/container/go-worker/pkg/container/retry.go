@@ -0,0 +1,154 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// retrying is a fake engine bound to a socket that could not be attached at startup,
+// eg: because Falco started before the container runtime on boot.
+// It keeps trying to attach to the socket in background, with an exponential backoff
+// bounded by config.GetConnectRetryMaxBackoff(); once attached, pre-existing containers
+// are notified as new ones, and the engine events are forwarded to its output channel.
+type retrying struct {
+	logger     *slog.Logger
+	engineType engineType
+	generator  engineGenerator
+	socket     string
+
+	mu sync.Mutex
+	// getter is a copy of the attached engine, used by the fetcher engine.
+	getter getter
+}
+
+// newEngineOrRetry attaches an engine to the socket; if it fails and retries are enabled,
+// a retrying engine is returned instead, that will attach to the socket as soon as it is reachable.
+func newEngineOrRetry(ctx context.Context, logger *slog.Logger, engineType engineType, generator engineGenerator,
+	socket string) (Engine, error) {
+	engine, err := generator(ctx, logger, socket)
+	ep := endpoint{engine: engineType, socket: socket}
+	if err == nil {
+		notifyStatus(ep, event.EngineStatusUp, nil)
+		return engine, nil
+	}
+	notifyStatus(ep, event.EngineStatusDown, err)
+	if config.GetConnectRetryMaxBackoff() == 0 {
+		return nil, err
+	}
+	logger.LogAttrs(ctx, slog.LevelWarn, "failed to attach socket, retrying in background", slog.String("socket", socket), slog.String("err", err.Error()))
+	return newRetryingEngine(logger, engineType, generator, socket), nil
+}
+
+func newRetryingEngine(logger *slog.Logger, engineType engineType, generator engineGenerator, socket string) *retrying {
+	return &retrying{
+		logger:     logger,
+		engineType: engineType,
+		generator:  generator,
+		socket:     socket,
+	}
+}
+
+func (r *retrying) copy(_ context.Context) (Engine, error) {
+	// The attached engine is shared with the fetcher, that will access it through get().
+	return r, nil
+}
+
+func (r *retrying) get(ctx context.Context, containerId string) (*event.Event, error) {
+	r.mu.Lock()
+	g := r.getter
+	r.mu.Unlock()
+	if g == nil {
+		return nil, nil
+	}
+	return g.get(ctx, containerId)
+}
+
+func (r *retrying) Name() string {
+	return string(r.engineType)
+}
+
+func (r *retrying) Sock() string {
+	return r.socket
+}
+
+func (r *retrying) List(_ context.Context) ([]event.Event, error) {
+	// Pre-existing containers are notified once the socket gets attached.
+	return []event.Event{}, nil
+}
+
+// connect retries to attach an engine to the socket, until it succeeds.
+// It returns nil if ctx got cancelled in the meantime.
+func (r *retrying) connect(ctx context.Context) Engine {
+	maxBackoff := config.GetConnectRetryMaxBackoff()
+	bo := newBackoff(min(reconnectMinBackoff, maxBackoff), maxBackoff)
+	for {
+		timer := time.NewTimer(bo.next())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		engine, err := r.generator(ctx, r.logger, r.socket)
+		if err == nil {
+			return engine
+		}
+		r.logger.LogAttrs(ctx, slog.LevelDebug, "failed to attach socket", slog.String("socket", r.socket), slog.String("err", err.Error()))
+	}
+}
+
+// Listen attaches to the socket in background, then forwards the events of the attached engine.
+func (r *retrying) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer func() {
+			close(outCh)
+			wg.Done()
+		}()
+		engine := r.connect(ctx)
+		if engine == nil {
+			return
+		}
+		ch, err := engine.Listen(ctx, wg)
+		if err != nil {
+			r.logger.LogAttrs(ctx, slog.LevelWarn, "failed to listen on socket", slog.String("socket", r.socket), slog.String("err", err.Error()))
+			return
+		}
+		r.logger.LogAttrs(ctx, slog.LevelInfo, "attached socket", slog.String("socket", r.socket))
+		notifyStatus(endpoint{engine: r.engineType, socket: r.socket}, event.EngineStatusUp, nil)
+
+		if cp, ok := engine.(copier); ok {
+			if e, _ := cp.copy(context.Background()); e != nil {
+				r.mu.Lock()
+				r.getter = e.(getter)
+				r.mu.Unlock()
+			}
+		}
+
+		// Pre-existing containers are notified as new ones.
+		containers, err := ListRecovered(ctx, engine)
+		if err == nil {
+			for _, ctr := range containers {
+				select {
+				case outCh <- ctr:
+				case <-ctx.Done():
+				}
+			}
+		}
+
+		for evt := range ch {
+			select {
+			case outCh <- evt:
+			case <-ctx.Done():
+				// Keep draining until the engine closes its channel.
+			}
+		}
+	}()
+	return outCh, nil
+}
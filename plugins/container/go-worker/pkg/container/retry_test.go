@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func TestNewEngineOrRetry(t *testing.T) {
+	failingGenerator := func(context.Context, *slog.Logger, string) (Engine, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	// Retries are enabled by default
+	engine, err := newEngineOrRetry(context.Background(), slog.Default(), typeDocker, failingGenerator, "/var/run/docker.sock")
+	require.NoError(t, err)
+	assert.IsType(t, &retrying{}, engine)
+	assert.Equal(t, string(typeDocker), engine.Name())
+	assert.Equal(t, "/var/run/docker.sock", engine.Sock())
+
+	require.NoError(t, config.Load(`{"connect_retry_max_backoff_ms": 0}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"connect_retry_max_backoff_ms": 30000}`)
+	})
+	_, err = newEngineOrRetry(context.Background(), slog.Default(), typeDocker, failingGenerator, "/var/run/docker.sock")
+	assert.Error(t, err)
+}
+
+func TestRetryingAttach(t *testing.T) {
+	require.NoError(t, config.Load(`{"connect_retry_max_backoff_ms": 10}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"connect_retry_max_backoff_ms": 30000}`)
+	})
+
+	// Use the fixture engine, whose "socket" is a directory, as attached engine
+	socket := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(socket, "ctr.json"), []byte(`{"container": {"id": "retried"}}`), 0o644))
+	attempts := 0
+	generator := func(ctx context.Context, logger *slog.Logger, socket string) (Engine, error) {
+		// Runtime still starting for the first attempts
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return newFixtureEngine(ctx, logger, socket)
+	}
+	r := newRetryingEngine(slog.Default(), typeFixture, generator, socket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	ch, err := r.Listen(ctx, &wg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// Pre-existing containers are notified once attached
+	select {
+	case evt := <-ch:
+		assert.Equal(t, "retried", evt.ID)
+		assert.True(t, evt.IsCreate)
+	case <-time.After(5 * time.Second):
+		t.Fatal("socket not attached")
+	}
+	assert.Equal(t, 3, attempts)
+
+	// The attached engine is used by the fetcher too
+	evt, err := r.get(context.Background(), "retried")
+	require.NoError(t, err)
+	require.NotNil(t, evt)
+	assert.Equal(t, "retried", evt.ID)
+}
@@ -0,0 +1,28 @@
+package container
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// rootlessDockerSocket matches the sockets of rootless docker daemons, bound to $XDG_RUNTIME_DIR/docker.sock,
+// where XDG_RUNTIME_DIR is /run/user/<uid>.
+const rootlessDockerSocket = "/run/user/*/docker.sock"
+
+// rootlessSocketRegex extracts the uid owning a rootless docker daemon from its socket,
+// even under HOST_ROOT or with the unix:// scheme.
+var rootlessSocketRegex = regexp.MustCompile(`(?:^|/)run/user/([0-9]+)/docker\.sock$`)
+
+// rootlessUID returns the uid owning the rootless docker daemon bound to socket,
+// or 0 for the sockets of system daemons.
+func rootlessUID(socket string) uint32 {
+	matches := rootlessSocketRegex.FindStringSubmatch(socket)
+	if matches == nil {
+		return 0
+	}
+	uid, err := strconv.ParseUint(matches[1], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(uid)
+}
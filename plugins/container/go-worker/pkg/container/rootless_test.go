@@ -0,0 +1,44 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootlessUID(t *testing.T) {
+	tCases := map[string]struct {
+		socket   string
+		expected uint32
+	}{
+		"system daemon": {
+			socket: "/var/run/docker.sock",
+		},
+		"rootless daemon": {
+			socket:   "/run/user/1000/docker.sock",
+			expected: 1000,
+		},
+		"rootless daemon under host root": {
+			socket:   "/host/run/user/1001/docker.sock",
+			expected: 1001,
+		},
+		"rootless daemon with scheme": {
+			socket:   "unix:///run/user/1002/docker.sock",
+			expected: 1002,
+		},
+		"rootless podman": {
+			socket: "/run/user/1000/podman/podman.sock",
+		},
+		"remote daemon": {
+			socket: "tcp://192.168.1.10:2376",
+		},
+		"out of range uid": {
+			socket: "/run/user/4294967296/docker.sock",
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, rootlessUID(tc.socket))
+		})
+	}
+}
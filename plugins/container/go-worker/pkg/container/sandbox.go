@@ -0,0 +1,82 @@
+package container
+
+import (
+	"sync"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// sandboxes tracks the network infos of the pod sandbox (pause) containers seen by the worker,
+// keyed by their full ID, so that they can be reported by workload containers
+// whose engine did not provide them (ie: containerd, that does not know the pod IP).
+var sandboxes = newSandboxRegistry()
+
+type sandboxNetwork struct {
+	ip    string
+	netns string
+}
+
+type sandboxRegistry struct {
+	mu      sync.Mutex
+	entries map[string]sandboxNetwork
+}
+
+func newSandboxRegistry() *sandboxRegistry {
+	return &sandboxRegistry{entries: make(map[string]sandboxNetwork)}
+}
+
+func (r *sandboxRegistry) get(id string) (sandboxNetwork, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	network, ok := r.entries[id]
+	return network, ok
+}
+
+func (r *sandboxRegistry) add(id string, network sandboxNetwork) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[id] = network
+}
+
+func (r *sandboxRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+}
+
+// TrackPodSandbox keeps track of the pod sandbox containers sent by the engines,
+// and associates workload containers to their sandbox, filling its IP and network namespace when missing.
+func TrackPodSandbox(evt *event.Event) {
+	if evt.IsDie || evt.IsPause || evt.IsUnpause || evt.IsOOM || evt.IsStats {
+		return
+	}
+	if evt.IsPodSandbox {
+		if !evt.IsCreate {
+			sandboxes.remove(evt.FullID)
+		} else if !evt.IsPartial {
+			sandboxes.add(evt.FullID, sandboxNetwork{ip: evt.Ip, netns: evt.PodSandboxNetNS})
+		}
+		return
+	}
+	if evt.PodSandboxID == "" {
+		return
+	}
+	network, ok := sandboxes.get(evt.PodSandboxID)
+	if !ok {
+		return
+	}
+	if evt.Ip == "" {
+		evt.Ip = network.ip
+	}
+	if evt.PodSandboxNetNS == "" {
+		evt.PodSandboxNetNS = network.netns
+	}
+}
+
+// IsSuppressed returns whether an event must not be sent,
+// ie: it concerns a pod sandbox container while they are suppressed by config,
+// an ignored infrastructure container, or a container not matching the configured label selectors.
+func IsSuppressed(evt event.Event) bool {
+	return (evt.IsPodSandbox && config.GetSuppressPodSandboxes()) || isIgnored(evt) || isUnselected(evt)
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func sandboxEvent(id string, isCreate bool) event.Event {
+	return event.Event{
+		Info: event.Info{Container: event.Container{
+			FullID:          id,
+			Ip:              "10.0.0.2",
+			IsPodSandbox:    true,
+			PodSandboxID:    id,
+			PodSandboxNetNS: "/var/run/netns/cni-1234",
+		}},
+		IsCreate: isCreate,
+	}
+}
+
+func workloadEvent(id, sandboxID string) event.Event {
+	return event.Event{
+		Info: event.Info{Container: event.Container{
+			FullID:       id,
+			PodSandboxID: sandboxID,
+		}},
+		IsCreate: true,
+	}
+}
+
+func TestTrackPodSandbox(t *testing.T) {
+	sandboxes = newSandboxRegistry()
+
+	// Unknown sandbox
+	evt := workloadEvent("workload", "sandbox")
+	TrackPodSandbox(&evt)
+	assert.Empty(t, evt.Ip)
+	assert.Empty(t, evt.PodSandboxNetNS)
+
+	sandbox := sandboxEvent("sandbox", true)
+	TrackPodSandbox(&sandbox)
+	evt = workloadEvent("workload", "sandbox")
+	TrackPodSandbox(&evt)
+	assert.Equal(t, "10.0.0.2", evt.Ip)
+	assert.Equal(t, "/var/run/netns/cni-1234", evt.PodSandboxNetNS)
+
+	// Infos reported by the engine are kept
+	evt = workloadEvent("workload", "sandbox")
+	evt.Ip = "10.0.0.3"
+	TrackPodSandbox(&evt)
+	assert.Equal(t, "10.0.0.3", evt.Ip)
+
+	// Lifecycle events of the sandbox do not untrack it
+	die := sandboxEvent("sandbox", false)
+	die.IsDie = true
+	TrackPodSandbox(&die)
+	_, ok := sandboxes.get("sandbox")
+	assert.True(t, ok)
+
+	sandbox = sandboxEvent("sandbox", false)
+	TrackPodSandbox(&sandbox)
+	evt = workloadEvent("workload", "sandbox")
+	TrackPodSandbox(&evt)
+	assert.Empty(t, evt.Ip)
+	assert.Empty(t, evt.PodSandboxNetNS)
+}
+
+func TestIsSuppressed(t *testing.T) {
+	t.Cleanup(func() { _ = config.Load(`{"suppress_pod_sandboxes": false}`) })
+
+	assert.False(t, IsSuppressed(sandboxEvent("sandbox", true)))
+
+	require.NoError(t, config.Load(`{"suppress_pod_sandboxes": true}`))
+	assert.True(t, IsSuppressed(sandboxEvent("sandbox", true)))
+	assert.False(t, IsSuppressed(workloadEvent("workload", "sandbox")))
+}
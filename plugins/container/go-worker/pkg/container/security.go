@@ -0,0 +1,65 @@
+package container
+
+import "strings"
+
+// Seccomp profiles, as reported in the container metadata; profiles loaded from a path
+// are reported as "localhost/<path>".
+const (
+	seccompUnconfined     = "unconfined"
+	seccompRuntimeDefault = "runtime/default"
+	// seccompCustom is a profile only known by its rules, eg: from an OCI spec.
+	seccompCustom = "custom"
+)
+
+// securityOpts are the security options applied to a container.
+type securityOpts struct {
+	seccompProfile  string
+	apparmorProfile string
+	selinuxLabel    string
+	noNewPrivileges bool
+}
+
+// parseSecurityOpts parses docker and podman HostConfig.SecurityOpt entries,
+// eg: "seccomp=unconfined", "apparmor=docker-default", "label=type:svirt_lxc_net_t", "no-new-privileges".
+// Legacy entries using ':' as separator are supported too.
+func parseSecurityOpts(opts []string, privileged bool) securityOpts {
+	var (
+		secOpts       securityOpts
+		selinuxLabels []string
+	)
+	for _, opt := range opts {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			key, value, _ = strings.Cut(opt, ":")
+		}
+		switch key {
+		case "seccomp":
+			switch {
+			case value == "unconfined":
+				secOpts.seccompProfile = seccompUnconfined
+			case value == "builtin" || value == "default" || value == "":
+				secOpts.seccompProfile = seccompRuntimeDefault
+			case strings.HasPrefix(value, "/"):
+				secOpts.seccompProfile = "localhost/" + strings.TrimPrefix(value, "/")
+			default:
+				// docker stores the whole JSON profile
+				secOpts.seccompProfile = seccompCustom
+			}
+		case "apparmor":
+			secOpts.apparmorProfile = value
+		case "label":
+			selinuxLabels = append(selinuxLabels, value)
+		case "no-new-privileges":
+			secOpts.noNewPrivileges = value == "" || value == "true"
+		}
+	}
+	if secOpts.seccompProfile == "" {
+		if privileged {
+			secOpts.seccompProfile = seccompUnconfined
+		} else {
+			secOpts.seccompProfile = seccompRuntimeDefault
+		}
+	}
+	secOpts.selinuxLabel = strings.Join(selinuxLabels, ",")
+	return secOpts
+}
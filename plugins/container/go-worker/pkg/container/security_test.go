@@ -0,0 +1,50 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSecurityOpts(t *testing.T) {
+	tCases := map[string]struct {
+		opts            []string
+		privileged      bool
+		expectedSecOpts securityOpts
+	}{
+		"defaults": {
+			expectedSecOpts: securityOpts{seccompProfile: seccompRuntimeDefault},
+		},
+		"privileged": {
+			privileged:      true,
+			expectedSecOpts: securityOpts{seccompProfile: seccompUnconfined},
+		},
+		"unconfined": {
+			opts:            []string{"seccomp=unconfined", "apparmor=unconfined", "label=disable"},
+			expectedSecOpts: securityOpts{seccompProfile: seccompUnconfined, apparmorProfile: "unconfined", selinuxLabel: "disable"},
+		},
+		"custom profiles": {
+			opts: []string{"seccomp={\"defaultAction\":\"SCMP_ACT_ERRNO\"}", "apparmor=my-profile",
+				"label=type:svirt_lxc_net_t", "label=level:s0:c100,c200", "no-new-privileges"},
+			expectedSecOpts: securityOpts{
+				seccompProfile:  seccompCustom,
+				apparmorProfile: "my-profile",
+				selinuxLabel:    "type:svirt_lxc_net_t,level:s0:c100,c200",
+				noNewPrivileges: true,
+			},
+		},
+		"profile path": {
+			opts:            []string{"seccomp=/etc/seccomp.json", "no-new-privileges=false"},
+			expectedSecOpts: securityOpts{seccompProfile: "localhost/etc/seccomp.json"},
+		},
+		"legacy separator": {
+			opts:            []string{"seccomp:unconfined", "label:disable", "no-new-privileges:true"},
+			expectedSecOpts: securityOpts{seccompProfile: seccompUnconfined, selinuxLabel: "disable", noNewPrivileges: true},
+		},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedSecOpts, parseSecurityOpts(tc.opts, tc.privileged))
+		})
+	}
+}
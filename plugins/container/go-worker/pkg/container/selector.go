@@ -0,0 +1,157 @@
+package container
+
+import (
+	"cmp"
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// idSetMaxEntries bounds the tracked container IDs when the metadata cache is disabled.
+const idSetMaxEntries = 4096
+
+// unselected tracks the IDs of the containers dropped by the label selectors,
+// since their lifecycle and remove events do not carry their labels.
+var unselected = newIDSet(idSetMaxEntries)
+
+// idSet is a set of container IDs, bounded by evicting the least recently seen ones,
+// since removed containers are not always notified (ie: without the remove hook).
+// The next lifecycle events of an evicted container are just not suppressed anymore.
+type idSet struct {
+	mu         sync.Mutex
+	maxEntries int
+	lru        *list.List
+	ids        map[string]*list.Element
+}
+
+func newIDSet(maxEntries int) *idSet {
+	return &idSet{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		ids:        make(map[string]*list.Element),
+	}
+}
+
+// initIDSets bounds the tracked ignored and unselected containers as the metadata cache, from the current config.
+func initIDSets() {
+	maxEntries := cmp.Or(config.GetCacheMaxEntries(), idSetMaxEntries)
+	ignored = newIDSet(maxEntries)
+	unselected = newIDSet(maxEntries)
+}
+
+func (s *idSet) has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.ids[id]
+	if ok {
+		s.lru.MoveToFront(elem)
+	}
+	return ok
+}
+
+func (s *idSet) set(id string, in bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.ids[id]
+	switch {
+	case ok && in:
+		s.lru.MoveToFront(elem)
+	case ok:
+		s.lru.Remove(elem)
+		delete(s.ids, id)
+	case in:
+		s.ids[id] = s.lru.PushFront(id)
+		if s.lru.Len() > s.maxEntries {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.ids, oldest.Value.(string))
+		}
+	}
+}
+
+// isUnselected returns whether an event concerns a container not matching the configured label selectors.
+// Partial events lack labels, thus they are only dropped if their container was already dropped.
+func isUnselected(evt event.Event) bool {
+	selectors := config.GetLabelSelectors()
+	if len(selectors.Include) == 0 && len(selectors.Exclude) == 0 {
+		return false
+	}
+	switch {
+	case evt.IsDie || evt.IsPause || evt.IsUnpause || evt.IsOOM || evt.IsStats || evt.IsPartial:
+		return unselected.has(evt.ID)
+	case !evt.IsCreate:
+		dropped := unselected.has(evt.ID)
+		unselected.set(evt.ID, false)
+		return dropped
+	}
+	labels := containerLabels(evt.Info)
+	dropped := (len(selectors.Include) > 0 && !matchSelectors(selectors.Include, labels)) ||
+		matchSelectors(selectors.Exclude, labels)
+	unselected.set(evt.ID, dropped)
+	return dropped
+}
+
+// containerLabels returns the labels of a container, merged with the ones of its pod, if any.
+func containerLabels(info event.Info) map[string]string {
+	if len(info.PodSandboxLabels) == 0 {
+		return info.Labels
+	}
+	labels := make(map[string]string, len(info.Labels)+len(info.PodSandboxLabels))
+	for k, v := range info.PodSandboxLabels {
+		labels[k] = v
+	}
+	for k, v := range info.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// matchSelectors returns whether labels match any selector.
+func matchSelectors(selectors []string, labels map[string]string) bool {
+	for _, selector := range selectors {
+		if matchSelector(selector, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSelector returns whether labels satisfy all the comma-separated requirements of selector:
+// "key=value" (or "key==value"), "key!=value", "key" and "!key".
+// Empty selectors and requirements without a key never match.
+func matchSelector(selector string, labels map[string]string) bool {
+	if strings.TrimSpace(selector) == "" {
+		return false
+	}
+	for _, req := range strings.Split(selector, ",") {
+		req = strings.TrimSpace(req)
+		if key, value, ok := strings.Cut(req, "!="); ok {
+			key = strings.TrimSpace(key)
+			if key == "" || labels[key] == strings.TrimSpace(value) {
+				return false
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(req, "="); ok {
+			key = strings.TrimSpace(key)
+			value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+			if actual, exists := labels[key]; key == "" || !exists || actual != value {
+				return false
+			}
+			continue
+		}
+		if key, ok := strings.CutPrefix(req, "!"); ok {
+			if _, exists := labels[strings.TrimSpace(key)]; key == "" || exists {
+				return false
+			}
+			continue
+		}
+		if _, exists := labels[req]; req == "" || !exists {
+			return false
+		}
+	}
+	return true
+}
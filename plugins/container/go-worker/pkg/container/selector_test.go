@@ -0,0 +1,82 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func TestMatchSelector(t *testing.T) {
+	labels := map[string]string{"team": "security", "env": "prod", "critical": ""}
+
+	tCases := map[string]struct {
+		selector string
+		expected bool
+	}{
+		"equality":                 {selector: "team=security", expected: true},
+		"double equality":          {selector: "team==security", expected: true},
+		"equality mismatch":        {selector: "team=payments", expected: false},
+		"equality missing label":   {selector: "owner=security", expected: false},
+		"inequality":               {selector: "env!=dev", expected: true},
+		"inequality mismatch":      {selector: "env!=prod", expected: false},
+		"inequality missing label": {selector: "owner!=security", expected: true},
+		"exists":                   {selector: "critical", expected: true},
+		"not exists":               {selector: "!owner", expected: true},
+		"not exists mismatch":      {selector: "!critical", expected: false},
+		"all requirements":         {selector: "team=security, env!=dev, critical", expected: true},
+		"any requirement mismatch": {selector: "team=security,env=dev", expected: false},
+		"empty":                    {selector: "", expected: false},
+		"empty requirement":        {selector: "team=security,", expected: false},
+		"empty key":                {selector: "=security", expected: false},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchSelector(tc.selector, labels))
+		})
+	}
+}
+
+func TestIsSuppressedByLabelSelectors(t *testing.T) {
+	require.NoError(t, config.Load(`{"label_selectors": {"include": ["team=security"], "exclude": ["falco.org/ignore"]}}`))
+	t.Cleanup(func() { _ = config.Load(`{"label_selectors": {}}`) })
+
+	owned := event.Event{Info: event.Info{Container: event.Container{ID: "owned", Labels: map[string]string{"team": "security"}}}, IsCreate: true}
+	other := event.Event{Info: event.Info{Container: event.Container{ID: "other", Labels: map[string]string{"team": "payments"}}}, IsCreate: true}
+	ignored := event.Event{Info: event.Info{Container: event.Container{ID: "ignored", Labels: map[string]string{"team": "security", "falco.org/ignore": "true"}}}, IsCreate: true}
+	// Pod labels are matched too
+	pod := event.Event{Info: event.Info{Container: event.Container{ID: "pod", PodSandboxLabels: map[string]string{"team": "security"}}}, IsCreate: true}
+	assert.False(t, IsSuppressed(owned))
+	assert.True(t, IsSuppressed(other))
+	assert.True(t, IsSuppressed(ignored))
+	assert.False(t, IsSuppressed(pod))
+
+	// Lifecycle and remove events, lacking labels, follow their container
+	died := event.Event{Info: event.Info{Container: event.Container{ID: "other"}}, IsCreate: true, IsDie: true}
+	assert.True(t, IsSuppressed(died))
+	removed := event.Event{Info: event.Info{Container: event.Container{ID: "other"}}}
+	assert.True(t, IsSuppressed(removed))
+	assert.False(t, unselected.has("other"))
+	removed.ID = "owned"
+	assert.False(t, IsSuppressed(removed))
+}
+
+func TestIDSetEviction(t *testing.T) {
+	set := newIDSet(2)
+	set.set("first", true)
+	set.set("second", true)
+	// Seen containers are kept, the least recently seen one is evicted
+	assert.True(t, set.has("first"))
+	set.set("third", true)
+	assert.True(t, set.has("first"))
+	assert.False(t, set.has("second"))
+	assert.True(t, set.has("third"))
+	assert.Len(t, set.ids, 2)
+
+	set.set("first", false)
+	assert.False(t, set.has("first"))
+	assert.Equal(t, 1, set.lru.Len())
+}
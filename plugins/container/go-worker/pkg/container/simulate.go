@@ -0,0 +1,236 @@
+package container
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+const (
+	defaultSimulateCreateRate = 10
+	// simulatedLabel marks the synthetic containers, so that they can be told apart from real ones.
+	simulatedLabel = "io.falcosecurity.simulated"
+)
+
+var (
+	simulatedTypes  = []engineType{typeDocker, typePodman, typeContainerd, typeCrio}
+	simulatedImages = []string{
+		"docker.io/library/nginx:1.25", "docker.io/library/redis:7", "docker.io/library/busybox:latest",
+		"quay.io/prometheus/node-exporter:v1.8.1", "registry.k8s.io/pause:3.9", "ghcr.io/falcosecurity/falco:0.39.0",
+	}
+	simulatedNamespaces = []string{"default", "kube-system", "monitoring", "web"}
+)
+
+func init() {
+	engineGenerators[typeSimulate] = newSimulateEngine
+}
+
+// simulateEngine generates synthetic containers with randomized metadata, created at a configurable rate
+// and removed once their lifetime expires. It allows soak and performance testing of the worker to plugin
+// pipeline, without any container engine.
+type simulateEngine struct {
+	logger *slog.Logger
+	cfg    config.SimulateCfg
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+	// containers are the running synthetic containers, by ID.
+	containers map[string]event.Info
+	// running queues the running containers IDs by creation time, ie: by expiration.
+	running []string
+}
+
+func newSimulateEngine(_ context.Context, logger *slog.Logger, _ string) (Engine, error) {
+	cfg := config.GetEngineSimulate(string(typeSimulate))
+	if cfg.CreateRate <= 0 {
+		cfg.CreateRate = defaultSimulateCreateRate
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &simulateEngine{
+		logger:     logger,
+		cfg:        cfg,
+		rnd:        rand.New(rand.NewPCG(seed, seed)),
+		containers: make(map[string]event.Info),
+	}, nil
+}
+
+func (s *simulateEngine) copy(ctx context.Context) (Engine, error) {
+	return newSimulateEngine(ctx, s.logger, "")
+}
+
+// newContainer returns the randomized metadata of a synthetic container.
+func (s *simulateEngine) newContainer(now time.Time) event.Info {
+	var id [32]byte
+	for i := range id {
+		id[i] = byte(s.rnd.UintN(256))
+	}
+	fullID := hex.EncodeToString(id[:])
+	image := simulatedImages[s.rnd.IntN(len(simulatedImages))]
+	ref := parseImageReference(image)
+	app := path.Base(ref.repository)
+	info := event.Info{
+		Container: event.Container{
+			Type:          simulatedTypes[s.rnd.IntN(len(simulatedTypes))].ToCTValue(),
+			ID:            shortContainerID(fullID),
+			FullID:        fullID,
+			Name:          fmt.Sprintf("sim-%s", fullID[:8]),
+			Image:         image,
+			ImageRepo:     ref.repository,
+			ImageTag:      ref.tag,
+			ImageRegistry: ref.registry,
+			User:          fmt.Sprintf("%d:%d", s.rnd.IntN(2)*1000, s.rnd.IntN(2)*1000),
+			Ip:            fmt.Sprintf("10.%d.%d.%d", s.rnd.IntN(256), s.rnd.IntN(256), 1+s.rnd.IntN(254)),
+			CPUPeriod:     defaultCpuPeriod,
+			CPUQuota:      int64(s.rnd.IntN(4)) * defaultCpuPeriod / 2,
+			MemoryLimit:   int64(s.rnd.IntN(8)) * 128 * 1024 * 1024,
+			Privileged:    s.rnd.IntN(20) == 0,
+			CreatedTime:   now.Unix(),
+			CreatedAt:     now.UnixNano(),
+			StartedAt:     now.UnixNano(),
+			Env:           []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin", fmt.Sprintf("SIM_INDEX=%d", s.rnd.IntN(1000))},
+			Labels:        map[string]string{simulatedLabel: "true", "app": app},
+		},
+	}
+	// Half of the containers run in a k8s pod
+	if s.rnd.IntN(2) == 0 {
+		info.PodNamespace = simulatedNamespaces[s.rnd.IntN(len(simulatedNamespaces))]
+		info.PodName = fmt.Sprintf("%s-%s", app, fullID[8:13])
+		info.PodUID = fmt.Sprintf("%s-%s-%s-%s-%s", fullID[16:24], fullID[24:28], fullID[28:32], fullID[32:36], fullID[36:48])
+		info.K8sContainerName = app
+		info.Labels["io.kubernetes.pod.name"] = info.PodName
+		info.Labels["io.kubernetes.pod.namespace"] = info.PodNamespace
+		info.Labels["io.kubernetes.pod.uid"] = info.PodUID
+	}
+	return info
+}
+
+// tick removes the containers whose lifetime expired, then creates a new one unless MaxContainers
+// are already running, returning the related events.
+func (s *simulateEngine) tick(now time.Time) []event.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var evts []event.Event
+	lifetime := time.Duration(s.cfg.LifetimeMs) * time.Millisecond
+	for lifetime > 0 && len(s.running) > 0 {
+		info := s.containers[s.running[0]]
+		if now.Sub(time.Unix(0, info.CreatedAt)) < lifetime {
+			break
+		}
+		s.running = s.running[1:]
+		delete(s.containers, info.ID)
+		if config.IsHookEnabled(config.HookRemove) {
+			// Send the minimum set of infos
+			evts = append(evts, event.Event{
+				Info: event.Info{
+					Container: event.Container{
+						Type:   info.Type,
+						ID:     info.ID,
+						FullID: info.FullID,
+						Name:   info.Name,
+						Image:  info.Image,
+					},
+				},
+				IsCreate: false,
+			})
+		}
+	}
+	if s.cfg.MaxContainers > 0 && len(s.running) >= s.cfg.MaxContainers {
+		return evts
+	}
+	info := s.newContainer(now)
+	s.containers[info.ID] = info
+	s.running = append(s.running, info.ID)
+	if config.IsHookEnabled(config.HookCreate) || config.IsHookEnabled(config.HookStart) {
+		evts = append(evts, event.Event{
+			Info:     info,
+			IsCreate: true,
+		})
+	}
+	return evts
+}
+
+func (s *simulateEngine) get(_ context.Context, containerId string) (*event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, info := range s.containers {
+		if info.ID == containerId || info.FullID == containerId {
+			return &event.Event{
+				Info:     info,
+				IsCreate: true,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *simulateEngine) Name() string {
+	return string(typeSimulate)
+}
+
+func (s *simulateEngine) Sock() string {
+	return ""
+}
+
+func (s *simulateEngine) List(_ context.Context) ([]event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	evts := make([]event.Event, 0, len(s.running))
+	for _, id := range s.running {
+		evts = append(evts, event.Event{
+			Info:     s.containers[id],
+			IsCreate: true,
+		})
+	}
+	return evts, nil
+}
+
+// Listen creates and removes the synthetic containers, at CreateRate per second.
+func (s *simulateEngine) Listen(ctx context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	outCh := make(chan event.Event)
+	interval := max(time.Second/time.Duration(s.cfg.CreateRate), time.Microsecond)
+	s.logger.LogAttrs(ctx, slog.LevelInfo, "simulating containers",
+		slog.Int("create_rate", s.cfg.CreateRate),
+		slog.Int("lifetime_ms", s.cfg.LifetimeMs),
+		slog.Int("max_containers", s.cfg.MaxContainers))
+	wg.Add(1)
+	go func() {
+		defer close(outCh)
+		defer wg.Done()
+		supervise(ctx, s.logger, string(typeSimulate), func() error {
+			return s.run(ctx, interval, outCh)
+		})
+	}()
+	return outCh, nil
+}
+
+// run creates and removes the synthetic containers every interval, until ctx is done.
+func (s *simulateEngine) run(ctx context.Context, interval time.Duration, outCh chan<- event.Event) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			for _, evt := range s.tick(now) {
+				select {
+				case outCh <- evt:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func newTestSimulateEngine(t *testing.T, cfg string) *simulateEngine {
+	err := config.Load(`{"engines": {"simulate": ` + cfg + `}}`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"engines": null}`)
+	})
+	engine, err := newSimulateEngine(context.Background(), slog.Default(), "")
+	require.NoError(t, err)
+	return engine.(*simulateEngine)
+}
+
+func TestSimulateTick(t *testing.T) {
+	engine := newTestSimulateEngine(t, `{"enabled": true, "lifetime_ms": 1000, "max_containers": 2, "seed": 42}`)
+	now := time.Now()
+
+	evts := engine.tick(now)
+	require.Len(t, evts, 1)
+	first := evts[0]
+	assert.True(t, first.IsCreate)
+	assert.Len(t, first.FullID, 64)
+	assert.Equal(t, shortContainerID(first.FullID), first.ID)
+	assert.Equal(t, "true", first.Labels[simulatedLabel])
+	assert.NotEmpty(t, first.Image)
+
+	evts = engine.tick(now.Add(100 * time.Millisecond))
+	require.Len(t, evts, 1)
+	second := evts[0]
+	assert.NotEqual(t, first.ID, second.ID)
+
+	// Creations are paused while max_containers are running
+	assert.Empty(t, engine.tick(now.Add(200*time.Millisecond)))
+	listed, err := engine.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+	got, err := engine.get(context.Background(), first.FullID)
+	require.NoError(t, err)
+	assert.Equal(t, first.Info, got.Info)
+
+	// The first container expired: it is removed and a new one is created
+	evts = engine.tick(now.Add(time.Second))
+	require.Len(t, evts, 2)
+	assert.False(t, evts[0].IsCreate)
+	assert.Equal(t, event.Container{Type: first.Type, ID: first.ID, FullID: first.FullID, Name: first.Name, Image: first.Image}, evts[0].Container)
+	assert.True(t, evts[1].IsCreate)
+	got, err = engine.get(context.Background(), first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSimulateSeed(t *testing.T) {
+	now := time.Now()
+	first := newTestSimulateEngine(t, `{"enabled": true, "seed": 7}`).tick(now)
+	second := newTestSimulateEngine(t, `{"enabled": true, "seed": 7}`).tick(now)
+	assert.Equal(t, first, second)
+}
+
+func TestSimulateListen(t *testing.T) {
+	engine := newTestSimulateEngine(t, `{"enabled": true, "create_rate": 1000, "lifetime_ms": 5}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	evts, err := engine.Listen(ctx, &wg)
+	require.NoError(t, err)
+
+	created := make(map[string]struct{})
+	var removed int
+	for removed == 0 {
+		select {
+		case evt := <-evts:
+			if evt.IsCreate {
+				created[evt.ID] = struct{}{}
+			} else {
+				assert.Contains(t, created, evt.ID)
+				removed++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for simulated events")
+		}
+	}
+	cancel()
+	wg.Wait()
+}
+
+func TestGeneratorsSimulate(t *testing.T) {
+	err := config.Load(`{"engines": {"simulate": {"enabled": true, "create_rate": 5}}}`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = config.Load(`{"engines": null}`)
+	})
+
+	generators, err := Generators()
+	require.NoError(t, err)
+	require.Len(t, generators, 1)
+	engine, err := generators[0](context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, string(typeSimulate), engine.Name())
+	assert.Equal(t, 5, engine.(*simulateEngine).cfg.CreateRate)
+}
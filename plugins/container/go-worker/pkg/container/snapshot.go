@@ -0,0 +1,117 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// snapshotEntry is a metadata cache entry, as saved to the cache snapshot.
+type snapshotEntry struct {
+	Info event.Info `json:"info"`
+	// Expires is not set when entries do not expire.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// SaveCacheSnapshot saves the metadata cache to config.GetCacheSnapshotPath(), if set,
+// so that the worker started next does not need to inspect again the containers still running.
+func SaveCacheSnapshot() {
+	path := config.GetCacheSnapshotPath()
+	if path == "" || metadata == nil {
+		return
+	}
+	if err := metadata.save(path); err != nil {
+		slog.Default().LogAttrs(context.Background(), slog.LevelWarn, "failed to save metadata cache snapshot", slog.String("path", path), slog.String("err", err.Error()))
+	}
+}
+
+// LoadCacheSnapshot fills the metadata cache with the entries saved by SaveCacheSnapshot, if any.
+// Expired entries are skipped, while the other ones keep their expiration.
+// The snapshot is removed once loaded, so that a worker not stopping gracefully
+// does not leave stale entries behind for the next one.
+func LoadCacheSnapshot() {
+	path := config.GetCacheSnapshotPath()
+	if path == "" || metadata == nil {
+		return
+	}
+	n, err := metadata.load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Default().LogAttrs(context.Background(), slog.LevelWarn, "failed to load metadata cache snapshot", slog.String("path", path), slog.String("err", err.Error()))
+	} else {
+		slog.Default().LogAttrs(context.Background(), slog.LevelInfo, "loaded metadata cache snapshot", slog.String("path", path), slog.Int("entries", n))
+	}
+	_ = os.Remove(path)
+}
+
+// save writes the cache entries, from the least to the most recently used one,
+// through a temporary file, so that a partially written snapshot is never loaded.
+func (m *metadataCache) save(path string) error {
+	m.mu.Lock()
+	entries := make([]snapshotEntry, 0, m.lru.Len())
+	for elem := m.lru.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*cacheEntry)
+		snapEntry := snapshotEntry{Info: entry.info}
+		if m.ttl > 0 {
+			snapEntry.Expires = entry.expires
+		}
+		entries = append(entries, snapEntry)
+	}
+	m.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// load adds the entries of a snapshot to the cache, returning how many of them got loaded.
+func (m *metadataCache) load(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var entries []snapshotEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	n := 0
+	for _, entry := range entries {
+		if entry.Info.ID == "" || (m.ttl > 0 && !entry.Expires.IsZero() && now.After(entry.Expires)) {
+			continue
+		}
+		m.add(entry.Info)
+		if m.ttl > 0 && !entry.Expires.IsZero() {
+			m.mu.Lock()
+			if elem, ok := m.entries[entry.Info.ID]; ok {
+				elem.Value.(*cacheEntry).expires = entry.Expires
+			}
+			m.mu.Unlock()
+		}
+		n++
+	}
+	return n, nil
+}
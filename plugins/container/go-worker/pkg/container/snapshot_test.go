@@ -0,0 +1,76 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+)
+
+func TestCacheSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, config.Load(`{"cache_snapshot_path": "`+path+`"}`))
+	t.Cleanup(func() {
+		metadata = nil
+		_ = config.Load(`{"cache_snapshot_path": ""}`)
+	})
+
+	// Nothing to load on first start
+	metadata = newMetadataCache(time.Hour, 2)
+	LoadCacheSnapshot()
+	_, ok := metadata.get("running")
+	assert.False(t, ok)
+
+	metadata.add(cacheInfo("expired", "expired"))
+	metadata.add(cacheInfo("running", "running"))
+	metadata.add(cacheInfo("recent", "recent"))
+	metadata.mu.Lock()
+	metadata.entries["running"].Value.(*cacheEntry).expires = time.Now().Add(time.Minute)
+	metadata.mu.Unlock()
+	SaveCacheSnapshot()
+	_, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// A restarted worker gets the saved entries back, keeping their expiration
+	metadata = newMetadataCache(time.Hour, 2)
+	LoadCacheSnapshot()
+	info, ok := metadata.get("running")
+	require.True(t, ok)
+	assert.Equal(t, "running", info.Name)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), metadata.entries["running"].Value.(*cacheEntry).expires, time.Second)
+	_, ok = metadata.get("recent")
+	assert.True(t, ok)
+	// Evicted when full, thus never saved
+	_, ok = metadata.get("expired")
+	assert.False(t, ok)
+
+	// The snapshot is only loaded once
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCacheSnapshotSkipsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"info": {"container": {"id": "expired"}}, "expires": "2000-01-01T00:00:00Z"},
+		{"info": {"container": {"id": "running"}}}
+	]`), 0o600))
+	cache := newMetadataCache(time.Hour, 10)
+	n, err := cache.load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, ok := cache.get("expired")
+	assert.False(t, ok)
+	_, ok = cache.get("running")
+	assert.True(t, ok)
+
+	// Corrupted snapshots are not loaded
+	require.NoError(t, os.WriteFile(path, []byte(`[{"info"`), 0o600))
+	_, err = cache.load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// usageSample is a sample of the resource usage of a running container, as reported by its engine.
+type usageSample struct {
+	minimal     event.Info
+	cpuNano     uint64 // CPU time consumed since the container started
+	memoryUsage uint64
+	memoryLimit uint64
+	pids        uint64
+	sampledAt   int64 // nanoseconds since epoch
+}
+
+// sampleFunc samples the resource usage of all the running containers of an engine.
+type sampleFunc func(ctx context.Context) ([]usageSample, error)
+
+// cpuSample is the CPU time consumed by a container when it got sampled.
+type cpuSample struct {
+	cpuNano   uint64
+	sampledAt int64
+}
+
+// statsTracker remembers the last CPU sample of the running containers, by container ID,
+// to compute their CPU usage between consecutive samples.
+// It is only accessed by the goroutine sampling an engine.
+type statsTracker map[string]cpuSample
+
+// stats builds the stats events of the sampled containers, carrying their cached infos, if any.
+// Containers sampled for the first time, or whose CPU time got reset by a restart,
+// only prime the CPU usage computation, thus they are not notified yet.
+func (t statsTracker) stats(samples []usageSample) []event.Event {
+	evts := make([]event.Event, 0, len(samples))
+	sampled := make(map[string]struct{}, len(samples))
+	for _, sample := range samples {
+		id := sample.minimal.ID
+		sampled[id] = struct{}{}
+		prev, ok := t[id]
+		t[id] = cpuSample{cpuNano: sample.cpuNano, sampledAt: sample.sampledAt}
+		if !ok || sample.sampledAt <= prev.sampledAt || sample.cpuNano < prev.cpuNano {
+			continue
+		}
+		info := cachedInfo(sample.minimal)
+		info.Stats = &event.Stats{
+			CPUPercent:  float64(sample.cpuNano-prev.cpuNano) / float64(sample.sampledAt-prev.sampledAt) * 100,
+			MemoryUsage: sample.memoryUsage,
+			MemoryLimit: sample.memoryLimit,
+			Pids:        sample.pids,
+			SampledAt:   sample.sampledAt,
+		}
+		evts = append(evts, event.Event{Info: info, IsStats: true})
+	}
+	// Forget the containers that are not running anymore
+	for id := range t {
+		if _, ok := sampled[id]; !ok {
+			delete(t, id)
+		}
+	}
+	return evts
+}
+
+// sampleStats sends the events of in to the returned channel, along with the stats events
+// of the running containers, sampled every config.GetStatsInterval().
+// If the interval is not set, in is returned as is.
+// The returned channel gets closed once in is closed.
+func sampleStats(ctx context.Context, wg *sync.WaitGroup, logger *slog.Logger, sample sampleFunc,
+	in <-chan event.Event) <-chan event.Event {
+	interval := config.GetStatsInterval()
+	if interval <= 0 {
+		return in
+	}
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer func() {
+			close(outCh)
+			wg.Done()
+		}()
+		send := func(evt event.Event) {
+			select {
+			case outCh <- evt:
+			case <-ctx.Done():
+				// Keep draining until the listener closes its channel.
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tracker := make(statsTracker)
+		// Samples are taken on their own goroutine, not to stall the events of the listener.
+		var samplesCh chan []usageSample
+		takeSamples := func() {
+			samplesCh = make(chan []usageSample, 1)
+			go func(resCh chan<- []usageSample) {
+				samples, err := sample(ctx)
+				if err != nil {
+					logger.LogAttrs(ctx, slog.LevelDebug, "failed to sample containers stats", slog.String("err", err.Error()))
+				}
+				resCh <- samples
+			}(samplesCh)
+		}
+		// The first samples prime the CPU usage computation
+		takeSamples()
+		for {
+			select {
+			case evt, ok := <-in:
+				if !ok {
+					return
+				}
+				send(evt)
+			case <-ticker.C:
+				if samplesCh != nil || ctx.Err() != nil {
+					// Previous sampling still running.
+					break
+				}
+				takeSamples()
+			case samples := <-samplesCh:
+				samplesCh = nil
+				if samples == nil {
+					break
+				}
+				for _, evt := range tracker.stats(samples) {
+					send(evt)
+				}
+			}
+		}
+	}()
+	return outCh
+}
@@ -0,0 +1,120 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+func testUsage(id string, cpuNano uint64, sampledAt int64) usageSample {
+	return usageSample{
+		minimal:     cacheInfo(id, ""),
+		cpuNano:     cpuNano,
+		memoryUsage: 64 << 20,
+		memoryLimit: 256 << 20,
+		pids:        3,
+		sampledAt:   sampledAt,
+	}
+}
+
+func TestStatsTracker(t *testing.T) {
+	metadata = newMetadataCache(time.Hour, 10)
+	t.Cleanup(func() {
+		metadata = nil
+	})
+	metadata.add(cacheInfo("cached", "cached"))
+
+	tracker := make(statsTracker)
+
+	// First samples only prime the CPU usage computation
+	assert.Empty(t, tracker.stats([]usageSample{
+		testUsage("cached", 1_000_000_000, 10_000_000_000),
+		testUsage("restarted", 5_000_000_000, 10_000_000_000),
+	}))
+
+	evts := tracker.stats([]usageSample{
+		testUsage("cached", 1_500_000_000, 11_000_000_000),
+		// CPU time got reset by a restart
+		testUsage("restarted", 100_000_000, 11_000_000_000),
+		testUsage("new", 100_000_000, 11_000_000_000),
+	})
+	require.Len(t, evts, 1)
+	evt := evts[0]
+	assert.True(t, evt.IsStats)
+	assert.Equal(t, event.KindStats, evt.Kind())
+	assert.Equal(t, "cached", evt.Name)
+	assert.Equal(t, &event.Stats{
+		CPUPercent:  50,
+		MemoryUsage: 64 << 20,
+		MemoryLimit: 256 << 20,
+		Pids:        3,
+		SampledAt:   11_000_000_000,
+	}, evt.Stats)
+
+	// Cached infos are left untouched
+	info, ok := metadata.get("cached")
+	assert.True(t, ok)
+	assert.Nil(t, info.Stats)
+
+	// Containers not running anymore are forgotten
+	evts = tracker.stats([]usageSample{testUsage("restarted", 2_100_000_000, 12_000_000_000)})
+	require.Len(t, evts, 1)
+	assert.Equal(t, "restarted", evts[0].ID)
+	assert.Equal(t, float64(200), evts[0].Stats.CPUPercent)
+	assert.NotContains(t, tracker, "cached")
+	assert.NotContains(t, tracker, "new")
+}
+
+func TestSampleStats(t *testing.T) {
+	require.NoError(t, config.Load(`{"stats_interval_ms": 10}`))
+	t.Cleanup(func() {
+		_ = config.Load(`{"stats_interval_ms": 0}`)
+	})
+
+	var (
+		mu      sync.Mutex
+		cpuNano uint64
+	)
+	sample := func(_ context.Context) ([]usageSample, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		cpuNano += 1_000_000
+		return []usageSample{testUsage("running", cpuNano, time.Now().UnixNano())}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	in := make(chan event.Event)
+	out := sampleStats(ctx, &wg, slog.Default(), sample, in)
+	t.Cleanup(func() {
+		cancel()
+		close(in)
+		wg.Wait()
+	})
+
+	// Listener events are forwarded as is
+	evt := event.Event{Info: event.Info{Container: event.Container{ID: "created"}}, IsCreate: true}
+	in <- evt
+	for {
+		got := waitOnChannelOrTimeout(t, out)
+		if !got.IsStats {
+			assert.Equal(t, evt, got)
+			break
+		}
+	}
+
+	// Running containers are periodically sampled
+	evt = waitOnChannelOrTimeout(t, out)
+	assert.True(t, evt.IsStats)
+	assert.Equal(t, "running", evt.ID)
+	assert.Equal(t, uint64(3), evt.Stats.Pids)
+	assert.Positive(t, evt.Stats.CPUPercent)
+}
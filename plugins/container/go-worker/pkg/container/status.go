@@ -0,0 +1,41 @@
+package container
+
+import (
+	"log/slog"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// engineStatusesSize bounds the engine statuses not yet notified to the plugin, eg: while starting.
+const engineStatusesSize = 100
+
+var engineStatuses = make(chan event.EngineStatus, engineStatusesSize)
+
+// EngineStatuses returns the channel where the connection status changes of the engines are notified.
+func EngineStatuses() <-chan event.EngineStatus {
+	return engineStatuses
+}
+
+// endpoint identifies the socket an engine is attached to.
+type endpoint struct {
+	engine engineType
+	socket string
+}
+
+// notifyStatus notifies a connection status change of the engine attached to ep, along with its cause, if any.
+// Statuses are dropped, rather than stalling the engine, when the plugin does not keep up.
+func notifyStatus(ep endpoint, status string, err error) {
+	evt := event.EngineStatus{
+		Engine: string(ep.engine),
+		Socket: ep.socket,
+		Status: status,
+	}
+	if err != nil {
+		evt.Error = err.Error()
+	}
+	select {
+	case engineStatuses <- evt:
+	default:
+		slog.Debug("dropped engine status", slog.String("engine", evt.Engine), slog.String("socket", evt.Socket), slog.String("status", status))
+	}
+}
@@ -0,0 +1,50 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// drainStatuses discards the engine statuses notified by other tests.
+func drainStatuses() {
+	for {
+		select {
+		case <-EngineStatuses():
+		default:
+			return
+		}
+	}
+}
+
+func TestEngineStatus(t *testing.T) {
+	drainStatuses()
+	ep := endpoint{engine: typeDocker, socket: "/run/docker.sock"}
+	list := func(_ context.Context) ([]event.Event, error) {
+		return nil, nil
+	}
+
+	// Only the first drop notifies the engine as down
+	b := newBackoff(time.Millisecond, time.Millisecond)
+	assert.True(t, waitReconnect(context.Background(), slog.Default(), ep, b, errors.New("EOF")))
+	assert.True(t, waitReconnect(context.Background(), slog.Default(), ep, b, errors.New("EOF")))
+	assert.Equal(t, event.EngineStatus{Engine: "docker", Socket: "/run/docker.sock", Status: event.EngineStatusDown, Error: "EOF"}, <-EngineStatuses())
+	assert.Len(t, EngineStatuses(), 0)
+
+	// Recovering notifies it as up
+	assert.True(t, resync(context.Background(), slog.Default(), ep, b, list, newKnownContainers(), make(chan event.Event)))
+	assert.Equal(t, event.EngineStatus{Engine: "docker", Socket: "/run/docker.sock", Status: event.EngineStatusUp}, <-EngineStatuses())
+
+	// Statuses are dropped rather than blocking when not consumed
+	for range engineStatusesSize + 1 {
+		notifyStatus(ep, event.EngineStatusUp, nil)
+	}
+	assert.Len(t, EngineStatuses(), engineStatusesSize)
+	drainStatuses()
+}
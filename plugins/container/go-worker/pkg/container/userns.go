@@ -0,0 +1,77 @@
+package container
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+)
+
+// parseIDMappings parses podman inspect id mappings, eg: "0:100000:65536".
+func parseIDMappings(mappings []string) []event.IDMapping {
+	var res []event.IDMapping
+	for _, m := range mappings {
+		if mapping, ok := parseIDMapping(strings.Split(m, ":")); ok {
+			res = append(res, mapping)
+		}
+	}
+	return res
+}
+
+// parseProcIDMappings parses a /proc/<pid>/{uid,gid}_map file content,
+// eg: "         0     100000      65536".
+// The identity mapping of the initial user namespace is not reported.
+func parseProcIDMappings(r io.Reader) []event.IDMapping {
+	var res []event.IDMapping
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		mapping, ok := parseIDMapping(strings.Fields(scanner.Text()))
+		if !ok {
+			continue
+		}
+		if mapping.ContainerID == 0 && mapping.HostID == 0 && mapping.Size == math.MaxUint32 {
+			return nil
+		}
+		res = append(res, mapping)
+	}
+	return res
+}
+
+func parseIDMapping(fields []string) (event.IDMapping, bool) {
+	if len(fields) != 3 {
+		return event.IDMapping{}, false
+	}
+	var ids [3]uint32
+	for i, field := range fields {
+		id, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return event.IDMapping{}, false
+		}
+		ids[i] = uint32(id)
+	}
+	return event.IDMapping{ContainerID: ids[0], HostID: ids[1], Size: ids[2]}, true
+}
+
+// procIDMappings returns the uid and gid mappings of the user namespace of a running process,
+// for engines not exposing them; both are nil when the process runs in the initial user namespace.
+func procIDMappings(pid int) ([]event.IDMapping, []event.IDMapping) {
+	if pid <= 0 {
+		return nil, nil
+	}
+	procDir := filepath.Join(config.GetHostRoot(), "/proc", strconv.Itoa(pid))
+	readMappings := func(name string) []event.IDMapping {
+		f, err := os.Open(filepath.Join(procDir, name))
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		return parseProcIDMappings(f)
+	}
+	return readMappings("uid_map"), readMappings("gid_map")
+}
@@ -0,0 +1,79 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIDMappings(t *testing.T) {
+	mappings := parseIDMappings([]string{"0:100000:65536", "65536:1000:1", "invalid", "1:2"})
+	assert.Equal(t, []event.IDMapping{
+		{ContainerID: 0, HostID: 100000, Size: 65536},
+		{ContainerID: 65536, HostID: 1000, Size: 1},
+	}, mappings)
+
+	assert.Nil(t, parseIDMappings(nil))
+}
+
+func TestParseProcIDMappings(t *testing.T) {
+	tCases := map[string]struct {
+		content  string
+		expected []event.IDMapping
+	}{
+		"initial user namespace": {
+			content: "         0          0 4294967295\n",
+		},
+		"remapped": {
+			content: "         0     100000      65536\n",
+			expected: []event.IDMapping{
+				{ContainerID: 0, HostID: 100000, Size: 65536},
+			},
+		},
+		"multiple ranges": {
+			content: "         0       1000          1\n         1     100000      65536\n",
+			expected: []event.IDMapping{
+				{ContainerID: 0, HostID: 1000, Size: 1},
+				{ContainerID: 1, HostID: 100000, Size: 65536},
+			},
+		},
+		"empty": {},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseProcIDMappings(strings.NewReader(tc.content)))
+		})
+	}
+}
+
+func TestProcIDMappings(t *testing.T) {
+	hostRoot := t.TempDir()
+	procDir := filepath.Join(hostRoot, "proc", "42")
+	require.NoError(t, os.MkdirAll(procDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "uid_map"), []byte("0 100000 65536\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "gid_map"), []byte("0 200000 65536\n"), 0o644))
+
+	err := config.Load(`{"host_root": "` + hostRoot + `"}`)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = config.Load(`{"host_root": ""}`) })
+
+	uidMappings, gidMappings := procIDMappings(42)
+	assert.Equal(t, []event.IDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}}, uidMappings)
+	assert.Equal(t, []event.IDMapping{{ContainerID: 0, HostID: 200000, Size: 65536}}, gidMappings)
+
+	// Missing process
+	uidMappings, gidMappings = procIDMappings(43)
+	assert.Nil(t, uidMappings)
+	assert.Nil(t, gidMappings)
+
+	// Unknown pid
+	uidMappings, gidMappings = procIDMappings(0)
+	assert.Nil(t, uidMappings)
+	assert.Nil(t, gidMappings)
+}
@@ -0,0 +1,24 @@
+package container
+
+import (
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Isolation modes of windows containers: process isolated containers share the host kernel,
+// while hyperv isolated ones run in a lightweight utility VM.
+const (
+	isolationProcess = "process"
+	isolationHyperV  = "hyperv"
+)
+
+// specIsolation returns the isolation mode of a container from the windows section of its OCI spec,
+// ie: empty for linux containers.
+func specIsolation(windows *specs.Windows) string {
+	if windows == nil {
+		return ""
+	}
+	if windows.HyperV != nil {
+		return isolationHyperV
+	}
+	return isolationProcess
+}
@@ -0,0 +1,14 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecIsolation(t *testing.T) {
+	assert.Empty(t, specIsolation(nil))
+	assert.Equal(t, isolationProcess, specIsolation(&specs.Windows{}))
+	assert.Equal(t, isolationHyperV, specIsolation(&specs.Windows{HyperV: &specs.WindowsHyperV{}}))
+}
@@ -3,9 +3,12 @@ package event
 import "encoding/json"
 
 type PortMapping struct {
-	HostIP        uint32 `json:"HostIp"`
-	HostPort      uint16 `json:"HostPort"`
+	HostIP        uint32 `json:"HostIp"`                // IPv4 host address
+	HostIPv6      string `json:"HostIpv6,omitempty"`    // IPv6 host address; HostIP is 0 for IPv6 bindings
+	HostPort      uint16 `json:"HostPort"`              // first port of published ranges
+	HostPortEnd   uint16 `json:"HostPortEnd,omitempty"` // last port of published ranges, any of which can be picked
 	ContainerPort int    `json:"ContainerPort"`
+	Protocol      string `json:"Protocol,omitempty"` // tcp, udp or sctp
 }
 
 type Mount struct {
@@ -14,6 +17,45 @@ type Mount struct {
 	Mode        string `json:"Mode"`
 	RW          bool   `json:"RW"`
 	Propagation string `json:"Propagation"`
+	Type        string `json:"Type,omitempty"` // eg: bind, volume, tmpfs
+	Name        string `json:"Name,omitempty"` // volume name, for named volumes
+}
+
+// HealthProbe is a command periodically run by the engine in a container to check its health.
+type HealthProbe struct {
+	Exe         string   `json:"exe"`
+	Args        []string `json:"args"`
+	Interval    int64    `json:"interval,omitempty"`     // nanoseconds
+	Timeout     int64    `json:"timeout,omitempty"`      // nanoseconds
+	StartPeriod int64    `json:"start_period,omitempty"` // nanoseconds
+	Retries     int64    `json:"retries,omitempty"`
+}
+
+// Network is a network a container is attached to, eg: a docker network or a CNI attachment.
+type Network struct {
+	Name    string   `json:"name"`
+	IP      string   `json:"ip,omitempty"`
+	IPv6    string   `json:"ipv6,omitempty"`
+	MAC     string   `json:"mac,omitempty"`
+	Gateway string   `json:"gateway,omitempty"`
+	Aliases []string `json:"aliases,omitempty"` // DNS names of the container on the network
+}
+
+// Stats is a sample of the resource usage of a running container, periodically polled from its engine.
+type Stats struct {
+	CPUPercent  float64 `json:"cpu_percent"`            // of a single CPU, since the previous sample
+	MemoryUsage uint64  `json:"memory_usage"`           // bytes, excluding the inactive page cache
+	MemoryLimit uint64  `json:"memory_limit,omitempty"` // bytes
+	Pids        uint64  `json:"pids"`
+	SampledAt   int64   `json:"sampled_at"` // nanoseconds since epoch
+}
+
+// IDMapping is a range of user or group ids of a user namespace,
+// mapped to the host ones.
+type IDMapping struct {
+	ContainerID uint32 `json:"containerID"`
+	HostID      uint32 `json:"hostID"`
+	Size        uint32 `json:"size"`
 }
 
 type Container struct {
@@ -25,29 +67,92 @@ type Container struct {
 	ImageID          string            `json:"imageid"`
 	ImageRepo        string            `json:"imagerepo"`
 	ImageTag         string            `json:"imagetag"`
+	ImageRegistry    string            `json:"imageregistry"`
+	ImageSize        int64             `json:"image_size,omitempty"` // bytes
+	ImageLayers      int64             `json:"image_layers,omitempty"`
+	ImagePulledAt    int64             `json:"image_pulled_at,omitempty"` // nanoseconds since epoch
 	User             string            `json:"User"`
-	CniJson          string            `json:"cni_json"` // cri only
+	UserIDs          string            `json:"user_ids,omitempty"` // containerd and cri only, "uid:gid"
+	CniJson          string            `json:"cni_json"`           // cri only
 	CPUPeriod        int64             `json:"cpu_period"`
 	CPUQuota         int64             `json:"cpu_quota"`
 	CPUShares        int64             `json:"cpu_shares"`
 	CPUSetCPUCount   int64             `json:"cpuset_cpu_count"`
-	CreatedTime      int64             `json:"created_time"`
+	CreatedTime      int64             `json:"created_time"`         // seconds since epoch
+	CreatedAt        int64             `json:"created_at,omitempty"` // nanoseconds since epoch
+	StartedAt        int64             `json:"started_at,omitempty"` // nanoseconds since epoch, of the last start
 	Env              []string          `json:"env"`
 	FullID           string            `json:"full_id"`
+	RuntimeID        string            `json:"runtime_id,omitempty"` // cri only, eg: containerd://<full_id>
 	HostIPC          bool              `json:"host_ipc"`
 	HostNetwork      bool              `json:"host_network"`
 	HostPID          bool              `json:"host_pid"`
 	Ip               string            `json:"ip"`
-	Size             int64             `json:"size"`
+	Networks         []Network         `json:"networks,omitempty"`
+	Size             int64             `json:"size"`                  // bytes of the writable layer, -1 if unknown
+	Snapshotter      string            `json:"snapshotter,omitempty"` // containerd only
 	IsPodSandbox     bool              `json:"is_pod_sandbox"`
 	Labels           map[string]string `json:"labels"`
 	MemoryLimit      int64             `json:"memory_limit"`
 	SwapLimit        int64             `json:"swap_limit"`
+	PidsLimit        int64             `json:"pids_limit,omitempty"`
 	PodSandboxID     string            `json:"pod_sandbox_id"` // cri only
+	PodSandboxNetNS  string            `json:"pod_sandbox_netns,omitempty"`
+	PodName          string            `json:"pod_name,omitempty"`
+	PodNamespace     string            `json:"pod_namespace,omitempty"`
+	PodUID           string            `json:"pod_uid,omitempty"`
+	K8sContainerName string            `json:"k8s_container_name,omitempty"`
+	K8sWorkloadKind  string            `json:"k8s_workload_kind,omitempty"`
+	K8sWorkloadName  string            `json:"k8s_workload_name,omitempty"`
+	ComposeProject   string            `json:"compose_project,omitempty"`
+	ComposeService   string            `json:"compose_service,omitempty"`
+	ComposeNumber    int64             `json:"compose_container_number,omitempty"`
+	EcsTaskArn       string            `json:"ecs_task_arn,omitempty"`
+	EcsCluster       string            `json:"ecs_cluster,omitempty"`
+	EcsServiceName   string            `json:"ecs_service_name,omitempty"`
+	EcsTaskFamily    string            `json:"ecs_task_family,omitempty"`
+	EcsTaskRevision  string            `json:"ecs_task_revision,omitempty"`
+	NomadAllocID     string            `json:"nomad_alloc_id,omitempty"`
+	NomadJobID       string            `json:"nomad_job_id,omitempty"`
+	NomadTaskGroup   string            `json:"nomad_task_group,omitempty"`
+	CfAppGUID        string            `json:"cf_app_guid,omitempty"`
+	CfSpaceGUID      string            `json:"cf_space_guid,omitempty"`
+	CfOrgGUID        string            `json:"cf_org_guid,omitempty"`
 	Privileged       bool              `json:"privileged"`
+	Runtime          string            `json:"runtime,omitempty"` // OCI runtime, eg: runc, io.containerd.kata.v2, runsc
+	SandboxedRuntime bool              `json:"sandboxed_runtime,omitempty"`
+	Isolation        string            `json:"isolation,omitempty"` // windows only: process or hyperv
+	CapAdd           []string          `json:"cap_add,omitempty"`
+	CapDrop          []string          `json:"cap_drop,omitempty"`
+	CapEffective     []string          `json:"cap_effective,omitempty"`
+	SeccompProfile   string            `json:"seccomp_profile,omitempty"`
+	AppArmorProfile  string            `json:"apparmor_profile,omitempty"`
+	SELinuxLabel     string            `json:"selinux_label,omitempty"`
+	NoNewPrivileges  bool              `json:"no_new_privileges,omitempty"`
+	UserNamespace    bool              `json:"userns,omitempty"`
+	UIDMappings      []IDMapping       `json:"uid_mappings,omitempty"`
+	GIDMappings      []IDMapping       `json:"gid_mappings,omitempty"`
+	GPUDevices       []string          `json:"gpu_devices,omitempty"`
+	PodAnnotations   map[string]string `json:"pod_annotations,omitempty"`
+	CgroupPath       string            `json:"cgroup_path,omitempty"`
+	CgroupDriver     string            `json:"cgroup_driver,omitempty"`
+	RootlessUID      uint32            `json:"rootless_uid,omitempty"`
+	ConfigDigest     string            `json:"config_digest,omitempty"`
 	PodSandboxLabels map[string]string `json:"pod_sandbox_labels"` // cri only
+	Annotations      map[string]string `json:"annotations"`        // cri only
+	LogPath          string            `json:"log_path"`           // cri only
+	EngineSocket     string            `json:"engine_socket"`      // docker only
 	PortMappings     []PortMapping     `json:"port_mappings"`
 	Mounts           []Mount           `json:"Mounts"`
+	RestartCount     int64             `json:"restart_count,omitempty"`
+	ExitCode         int64             `json:"exit_code,omitempty"`     // only set on terminated or restarted containers, of their last run
+	FinishedAt       int64             `json:"finished_at,omitempty"`   // nanoseconds since epoch
+	OOMKilled        bool              `json:"oom_killed,omitempty"`    // last run killed by the OOM killer
+	PausedAt         int64             `json:"paused_at,omitempty"`     // nanoseconds since epoch, only set on paused containers
+	HealthStatus     string            `json:"health_status,omitempty"` // docker only
+	HealthOutput     string            `json:"health_output,omitempty"` // docker only, output of the last failing check
+	Healthcheck      *HealthProbe      `json:"Healthcheck,omitempty"`   // docker and podman only
+	Stats            *Stats            `json:"stats,omitempty"`         // only set on stats events
 }
 
 // Info struct wraps Container because we need the `container` struct in the json for backward compatibility.
@@ -90,6 +195,9 @@ type Container struct {
     "pod_sandbox_id": "",
     "privileged": false,
     "pod_sandbox_labels": null,
+    "annotations": null,
+    "log_path": "",
+    "engine_socket": "unix:///var/run/docker.sock",
     "port_mappings": [],
     "Mounts": [
       {
@@ -110,6 +218,62 @@ type Info struct {
 type Event struct {
 	Info
 	IsCreate bool
+	// IsPartial is set on create events only carrying the minimal set of infos,
+	// since the container could not be inspected.
+	IsPartial bool
+	// IsUpdate is set on create events carrying the full infos of a container
+	// already notified through a partial event.
+	IsUpdate bool
+	// IsDie is set on events notifying the termination of a container,
+	// carrying its exit code, finished-at timestamp and OOM-killed flag.
+	IsDie bool
+	// IsPause and IsUnpause are set on events notifying that a container
+	// got paused or unpaused; pause events carry the paused-at timestamp.
+	IsPause   bool
+	IsUnpause bool
+	// IsOOM is set on events notifying that a process of a container
+	// got killed by the OOM killer, while the container might still be running.
+	IsOOM bool
+	// IsStats is set on events carrying a sample of the resource usage of a running container.
+	IsStats bool
+}
+
+// Kind is the kind of notification of an event.
+// Its values match the async_event_kind enum exposed to the plugin.
+type Kind int
+
+const (
+	KindRemoved Kind = iota
+	KindAdded
+	KindUpdated
+	KindDied
+	KindPaused
+	KindUnpaused
+	KindOOMKilled
+	KindStats
+	KindEngineStatus
+)
+
+// Kind returns how the event has to be notified to the plugin.
+func (e *Event) Kind() Kind {
+	switch {
+	case e.IsDie:
+		return KindDied
+	case e.IsPause:
+		return KindPaused
+	case e.IsUnpause:
+		return KindUnpaused
+	case e.IsOOM:
+		return KindOOMKilled
+	case e.IsStats:
+		return KindStats
+	case !e.IsCreate:
+		return KindRemoved
+	case e.IsUpdate:
+		return KindUpdated
+	default:
+		return KindAdded
+	}
 }
 
 func (i *Info) String() string {
@@ -119,3 +283,25 @@ func (i *Info) String() string {
 	}
 	return string(str)
 }
+
+const (
+	EngineStatusUp   = "up"
+	EngineStatusDown = "down"
+)
+
+// EngineStatus notifies that the connection of an engine to its socket got established, lost or recovered,
+// telling apart hosts without containers from hosts whose enrichment is broken.
+type EngineStatus struct {
+	Engine string `json:"engine"`
+	Socket string `json:"socket"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (s *EngineStatus) String() string {
+	str, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return string(str)
+}
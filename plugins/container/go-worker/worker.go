@@ -3,26 +3,38 @@ package main
 /*
 #include <stdbool.h>
 #include <stdlib.h>
-typedef void (*async_cb)(const char *json, bool added, bool initial_state);
-extern void makeCallback(const char *json, bool added, bool initial_state, async_cb cb) {
-	cb(json, added, initial_state);
+typedef void (*async_cb)(const char *json, int kind, bool initial_state);
+extern void makeCallback(const char *json, int kind, bool initial_state, async_cb cb) {
+	cb(json, kind, initial_state);
+}
+typedef void (*log_cb)(const char *msg, int severity);
+extern void makeLogCallback(const char *msg, int severity, log_cb cb) {
+	cb(msg, severity);
 }
 */
 import "C"
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/container"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"log/slog"
 	"reflect"
 	"sync"
+	"time"
 )
 
-const ctxDoneIdx = 0
+const (
+	ctxDoneIdx = 0
+	attachIdx  = 1
+)
 
-type asyncCb func(string, bool, bool)
+type asyncCb func(string, event.Kind, bool)
 
-func workerLoop(ctx context.Context, cb asyncCb, containerEngines []container.Engine, wg *sync.WaitGroup) {
+func workerLoop(ctx context.Context, cb asyncCb, containerEngines []container.Engine, attachCh <-chan container.Engine,
+	wg *sync.WaitGroup) {
 	var evt event.Event
 
 	// We need to use a reflect.SelectCase here since
@@ -35,6 +47,12 @@ func workerLoop(ctx context.Context, cb asyncCb, containerEngines []container.En
 		Chan: reflect.ValueOf(ctx.Done()),
 	})
 
+	// Emplace back case for the engines attached by a reload of the engines configuration
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(attachCh),
+	})
+
 	// Emplace back cases for each container engine listener
 	for _, engine := range containerEngines {
 		ch, err := engine.Listen(ctx, wg)
@@ -47,18 +65,288 @@ func workerLoop(ctx context.Context, cb asyncCb, containerEngines []container.En
 		})
 	}
 
+	// Events are sent to the plugin from a dedicated goroutine,
+	// through a bounded queue, so that a slow consumer does not stall the engines.
+	// The engines connection statuses are sent from there too.
+	queue := container.NewEventQueue()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-queue.Ready():
+				evt, ok := queue.Pop()
+				if !ok {
+					continue
+				}
+				shaped := container.ShapePayload(evt)
+				payload := shaped.String()
+				if container.IsRedundant(evt, payload) {
+					continue
+				}
+				cb(payload, evt.Kind(), false)
+			case status := <-container.EngineStatuses():
+				cb(status.String(), event.KindEngineStatus, false)
+			}
+		}
+	}()
+
 	for {
 		chosen, val, recvOk := reflect.Select(cases)
 		if chosen == ctxDoneIdx {
 			// ctx.Done!
 			return
 		}
+		if chosen == attachIdx {
+			engine, _ := val.Interface().(container.Engine)
+			if ch, err := engine.Listen(ctx, wg); err == nil {
+				cases = append(cases, reflect.SelectCase{
+					Dir:  reflect.SelectRecv,
+					Chan: reflect.ValueOf(ch),
+				})
+			}
+			continue
+		}
 		if recvOk {
 			evt, _ = val.Interface().(event.Event)
-			cb(evt.String(), evt.IsCreate, false)
+			container.CountEvent()
+			container.TrackPodSandbox(&evt)
+			container.CacheEvent(evt)
+			if !container.IsSuppressed(evt) {
+				queue.Push(ctx, evt)
+			}
 		} else {
 			// Remove the stopped goroutine
 			cases = append(cases[:chosen], cases[chosen+1:]...)
 		}
 	}
 }
+
+// listWithDeadline lists the pre-existing containers of an engine, within config.GetEngineListTimeout().
+// Containers that could not be inspected before the deadline are reported with a minimal set of infos.
+func listWithDeadline(ctx context.Context, engine container.Engine) ([]event.Event, error) {
+	start := time.Now()
+	defer func() {
+		container.ObserveListLatency(engine.Name(), time.Since(start))
+	}()
+	timeout := config.GetEngineListTimeout(engine.Name())
+	if timeout <= 0 {
+		return container.ListRecovered(ctx, engine)
+	}
+	listCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return container.ListRecovered(listCtx, engine)
+}
+
+// attachedEngine is an engine attached for an entry of the engines configuration.
+// It listens with the context of its attachment, cancelled when the entry is detached,
+// and first notifies the containers it listed when attached, if any.
+type attachedEngine struct {
+	container.Engine
+	ctx    context.Context
+	listed []event.Event
+}
+
+func (a *attachedEngine) Listen(_ context.Context, wg *sync.WaitGroup) (<-chan event.Event, error) {
+	ch, err := a.Engine.Listen(a.ctx, wg)
+	if err != nil || len(a.listed) == 0 {
+		return ch, err
+	}
+	outCh := make(chan event.Event)
+	wg.Add(1)
+	go func() {
+		defer close(outCh)
+		defer wg.Done()
+		for _, evt := range a.listed {
+			select {
+			case outCh <- evt:
+			case <-a.ctx.Done():
+				return
+			}
+		}
+		for evt := range ch {
+			select {
+			case outCh <- evt:
+			case <-a.ctx.Done():
+				return
+			}
+		}
+	}()
+	return outCh, nil
+}
+
+// reload replaces the engines configuration with cfg, detaching the entries that are gone or whose
+// engine configuration changed, and attaching the new ones in background. It returns the json of the
+// attached sockets, or false if cfg is invalid or once the worker is stopping.
+func (p *PluginCtx) reload(cfg string) (string, bool) {
+	p.lifecycleMu.Lock()
+	defer p.lifecycleMu.Unlock()
+	if p.stopping {
+		return "", false
+	}
+
+	// Only the engines configuration is reloaded; other options require a restart.
+	if err := config.LoadEngines(cfg); err != nil {
+		return "", false
+	}
+	keyedGenerators, err := container.KeyedGenerators()
+	if err != nil {
+		return "", false
+	}
+
+	p.enginesMu.Lock()
+	defer p.enginesMu.Unlock()
+	enginesCfg := config.Get().SocketsEngines
+	// Detach the entries that are gone, or whose engine configuration changed
+	for key := range p.engines {
+		_, ok := keyedGenerators[key]
+		if !ok || !reflect.DeepEqual(p.enginesCfg[key.Engine], enginesCfg[key.Engine]) {
+			p.detach(key)
+		}
+	}
+	p.enginesCfg = enginesCfg
+	// Attach the new ones in background, not to block the caller while connecting to them:
+	// their pre-existing containers are notified by the worker loop.
+	for key, generators := range keyedGenerators {
+		if _, ok := p.engines[key]; ok {
+			continue
+		}
+		att := p.reserve(key)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.attachAsync(key, att, generators)
+		}()
+	}
+	// The engines being attached are not included yet
+	return p.enabledEngines(), true
+}
+
+// stop cancels the worker and waits for its goroutines. Reloads are rejected from then on,
+// so that they never add goroutines while they are waited.
+func (p *PluginCtx) stop() {
+	p.lifecycleMu.Lock()
+	p.stopping = true
+	p.lifecycleMu.Unlock()
+
+	p.ctxCancel()
+	p.wg.Wait()
+}
+
+// attachment tracks the engines attached for an entry of the engines configuration.
+// Its engines are only set once generated, and listen with its context.
+type attachment struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	engines []container.Engine
+}
+
+// attach generates and attaches the engines of key, listing their pre-existing containers,
+// and returns them along with the engines retrying the inspection of the partially listed ones.
+// It must be called with enginesMu held.
+func (p *PluginCtx) attach(key container.EngineKey, generators []container.EngineGenerator) []*attachedEngine {
+	att := p.reserve(key)
+	generated, engines := connect(att.ctx, generators)
+	p.commit(key, att, generated)
+	return engines
+}
+
+// attachAsync generates and attaches the engines of a reserved key without holding enginesMu,
+// so that slow engines do not block the reload, then hands them over to the worker loop.
+// Nothing is attached if the key got detached in the meantime.
+func (p *PluginCtx) attachAsync(key container.EngineKey, att *attachment, generators []container.EngineGenerator) {
+	generated, engines := connect(att.ctx, generators)
+	p.enginesMu.Lock()
+	committed := p.commit(key, att, generated)
+	if committed {
+		slog.LogAttrs(att.ctx, slog.LevelDebug, "attached engine sockets", slog.String("engines", p.enabledEngines()))
+	}
+	p.enginesMu.Unlock()
+	if !committed {
+		return
+	}
+	for _, engine := range engines {
+		select {
+		case p.attachCh <- engine:
+		case <-att.ctx.Done():
+			return
+		}
+	}
+}
+
+// reserve registers the attachment of key, before its engines are generated.
+// It must be called with enginesMu held.
+func (p *PluginCtx) reserve(key container.EngineKey) *attachment {
+	ctx, cancel := context.WithCancel(p.ctx)
+	att := &attachment{ctx: ctx, cancel: cancel}
+	p.engines[key] = att
+	return att
+}
+
+// commit sets the generated engines of a reserved attachment, returning false if key got detached since.
+// It must be called with enginesMu held.
+func (p *PluginCtx) commit(key container.EngineKey, att *attachment, generated []container.Engine) bool {
+	if p.engines[key] != att {
+		return false
+	}
+	att.engines = generated
+	p.attacher.Attach(key, generated)
+	return true
+}
+
+// connect generates engines listening with ctx, listing their pre-existing containers.
+// It returns the generated engines, and the ones to listen, including the engines
+// retrying the inspection of the partially listed containers.
+func connect(ctx context.Context, generators []container.EngineGenerator) ([]container.Engine, []*attachedEngine) {
+	generated := make([]container.Engine, 0, len(generators))
+	engines := make([]*attachedEngine, 0, len(generators))
+	// Engines retrying the inspection of the containers that could not be inspected while listing.
+	retries := make([]*attachedEngine, 0)
+	for _, generator := range generators {
+		engine, err := generator(ctx)
+		if err != nil {
+			continue
+		}
+		generated = append(generated, engine)
+		attached := &attachedEngine{Engine: engine, ctx: ctx}
+		// List all pre-existing containers,
+		// bounding the listing so that huge nodes do not stall the attach.
+		containers, err := listWithDeadline(ctx, engine)
+		if err == nil {
+			attached.listed = containers
+			if retry := container.NewListRetryEngine(engine, containers); retry != nil {
+				retries = append(retries, &attachedEngine{Engine: retry, ctx: ctx})
+			}
+		}
+		engines = append(engines, attached)
+	}
+	return generated, append(engines, retries...)
+}
+
+// detach stops the engines of key. The containers they reported are kept.
+// It must be called with enginesMu held.
+func (p *PluginCtx) detach(key container.EngineKey) {
+	if att, ok := p.engines[key]; ok {
+		att.cancel()
+		p.attacher.Detach(key)
+		delete(p.engines, key)
+	}
+}
+
+// enabledEngines returns the json of the attached sockets, by engine.
+// It must be called with enginesMu held.
+func (p *PluginCtx) enabledEngines() string {
+	enabledEngines := make(map[string][]string)
+	for _, att := range p.engines {
+		for _, engine := range att.engines {
+			if _, ok := enabledEngines[engine.Name()]; !ok {
+				enabledEngines[engine.Name()] = make([]string, 0)
+			}
+			enabledEngines[engine.Name()] = append(enabledEngines[engine.Name()], engine.Sock())
+		}
+	}
+	bytes, _ := json.Marshal(enabledEngines)
+	return string(bytes)
+}
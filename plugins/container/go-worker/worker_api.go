@@ -2,18 +2,44 @@ package main
 
 /*
 #include <stdbool.h>
+#include <stdlib.h>
 typedef const char cchar_t;
-typedef void (*async_cb)(const char *json, bool added, bool initial_state);
-void makeCallback(const char *json, bool added, bool initial_state, async_cb cb);
+// Kinds of the events notified through async_cb;
+// they match event.Kind values.
+enum async_event_kind {
+	ASYNC_EVENT_KIND_REMOVED = 0,
+	ASYNC_EVENT_KIND_ADDED = 1,
+	ASYNC_EVENT_KIND_UPDATED = 2,
+	ASYNC_EVENT_KIND_DIED = 3,
+	ASYNC_EVENT_KIND_PAUSED = 4,
+	ASYNC_EVENT_KIND_UNPAUSED = 5,
+	ASYNC_EVENT_KIND_OOM_KILLED = 6,
+	ASYNC_EVENT_KIND_STATS = 7,
+	ASYNC_EVENT_KIND_ENGINE_STATUS = 8,
+};
+typedef void (*async_cb)(const char *json, int kind, bool initial_state);
+void makeCallback(const char *json, int kind, bool initial_state, async_cb cb);
+// Severities of the messages notified through log_cb;
+// they match the plugin API ss_plugin_log_severity values.
+enum log_severity {
+	LOG_SEVERITY_ERROR = 3,
+	LOG_SEVERITY_WARNING = 4,
+	LOG_SEVERITY_INFO = 6,
+	LOG_SEVERITY_DEBUG = 7,
+	LOG_SEVERITY_TRACE = 8,
+};
+typedef void (*log_cb)(const char *msg, int severity);
+void makeLogCallback(const char *msg, int severity, log_cb cb);
 */
 import "C"
 
 import (
 	"context"
-	"encoding/json"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/container"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
+	"log/slog"
 	"runtime"
 	"runtime/cgo"
 	"sync"
@@ -22,81 +48,131 @@ import (
 
 type PluginCtx struct {
 	wg           sync.WaitGroup
+	ctx          context.Context
 	ctxCancel    context.CancelFunc
 	stringBuffer ptr.StringBuffer
 	pinner       runtime.Pinner
 	fetchCh      chan string
+	looker       container.Looker
+	attacher     container.Attacher
+	// attachCh hands the engines attached by a reload over to the worker loop.
+	attachCh chan container.Engine
+	// enginesMu guards the attached engines, by the key of their configuration entry,
+	// and the engines configuration they were attached with.
+	enginesMu  sync.Mutex
+	engines    map[container.EngineKey]*attachment
+	enginesCfg map[string]config.SocketsEngine
+	// lifecycleMu serializes the reloads, replacing the engines configuration, between them and with stop.
+	// Once stopping is set, reloads are rejected, so that no goroutine is added to wg while it is waited.
+	lifecycleMu sync.Mutex
+	stopping    bool
+}
+
+//export SetWorkerLogger
+func SetWorkerLogger(cb C.log_cb) {
+	// Go cannot call C-function pointers, see StartWorker.
+	config.SetLogSink(func(level slog.Level, msg string) {
+		cMsg := C.CString(msg)
+		defer C.free(unsafe.Pointer(cMsg))
+		C.makeLogCallback(cMsg, logSeverity(level), cb)
+	})
+}
+
+// logSeverity maps a slog level to the plugin API log severity.
+func logSeverity(level slog.Level) C.int {
+	switch {
+	case level >= slog.LevelError:
+		return C.LOG_SEVERITY_ERROR
+	case level >= slog.LevelWarn:
+		return C.LOG_SEVERITY_WARNING
+	case level >= slog.LevelInfo:
+		return C.LOG_SEVERITY_INFO
+	case level >= slog.LevelDebug:
+		return C.LOG_SEVERITY_DEBUG
+	default:
+		return C.LOG_SEVERITY_TRACE
+	}
 }
 
 //export StartWorker
 func StartWorker(cb C.async_cb, initCfg *C.cchar_t, enabledSocks **C.cchar_t) unsafe.Pointer {
-	var (
-		pluginCtx PluginCtx
-		ctx       context.Context
-	)
+	var pluginCtx PluginCtx
 	const fetchChSize = 100
-	ctx, pluginCtx.ctxCancel = context.WithCancel(context.Background())
+	pluginCtx.ctx, pluginCtx.ctxCancel = context.WithCancel(context.Background())
+	ctx := pluginCtx.ctx
 
 	// See https://github.com/enobufs/go-calls-c-pointer/blob/master/counter_api.go
-	goCb := func(containerJson string, added bool, initialState bool) {
+	goCb := func(containerJson string, kind event.Kind, initialState bool) {
 		if containerJson == "" {
 			return
 		}
 		// Go cannot call C-function pointers. Instead, use
 		// a C-function to have it call the function pointer.
 		pluginCtx.stringBuffer.Write(containerJson)
-		cadded := C.bool(added)
+		ckind := C.int(kind)
 		cinitialState := C.bool(initialState)
 		cStr := (*C.char)(pluginCtx.stringBuffer.CharPtr())
-		C.makeCallback(cStr, cadded, cinitialState, cb)
+		C.makeCallback(cStr, ckind, cinitialState, cb)
 	}
 
 	err := config.Load(ptr.GoString(unsafe.Pointer(initCfg)))
 	if err != nil {
 		return nil
 	}
+	container.ApplyLimits()
+	container.InitCache()
+	container.LoadCacheSnapshot()
+	container.InitDigestResolver()
+	container.InitEcsMetadataResolver()
+	container.InitNomadResolver()
 
-	generators, err := container.Generators()
+	keyedGenerators, err := container.KeyedGenerators()
 	if err != nil {
 		return nil
 	}
 
-	containerEngines := make([]container.Engine, 0)
-	enabledEngines := make(map[string][]string)
-	for _, generator := range generators {
-		engine, err := generator(ctx)
-		if err != nil {
-			continue
-		}
-		containerEngines = append(containerEngines, engine)
-		if _, ok := enabledEngines[engine.Name()]; !ok {
-			enabledEngines[engine.Name()] = make([]string, 0)
-		}
-		enabledEngines[engine.Name()] = append(enabledEngines[engine.Name()], engine.Sock())
-		// List all pre-existing containers and run `goCb` on all of them
-		containers, err := engine.List(ctx)
-		if err == nil {
-			for _, ctr := range containers {
-				goCb(ctr.String(), true, true)
-			}
-		}
-	}
-
 	pluginCtx.fetchCh = make(chan string, fetchChSize)
+	pluginCtx.attachCh = make(chan container.Engine)
+	pluginCtx.engines = make(map[container.EngineKey]*attachment)
+	pluginCtx.enginesCfg = config.Get().SocketsEngines
 
 	// Always append the dummy engine that is required to
 	// be able to fetch container infos on the fly given other enabled engines.
-	containerEngines = append(containerEngines, container.NewFetcherEngine(ctx, pluginCtx.fetchCh, containerEngines))
+	// It follows the engines attached later on.
+	fetcherEngine := container.NewFetcherEngine(ctx, pluginCtx.fetchCh, nil)
+	containerEngines := []container.Engine{fetcherEngine}
+	pluginCtx.looker, _ = fetcherEngine.(container.Looker)
+	pluginCtx.attacher, _ = fetcherEngine.(container.Attacher)
 
+	pluginCtx.enginesMu.Lock()
+	for key, generators := range keyedGenerators {
+		for _, engine := range pluginCtx.attach(key, generators) {
+			// Run `goCb` on all pre-existing containers
+			for _, ctr := range engine.listed {
+				container.CountEvent()
+				container.TrackPodSandbox(&ctr)
+				container.CacheEvent(ctr)
+				if !container.IsSuppressed(ctr) {
+					shaped := container.ShapePayload(ctr)
+					payload := shaped.String()
+					// Track the sent infos, to deduplicate the following updates
+					container.IsRedundant(ctr, payload)
+					goCb(payload, event.KindAdded, true)
+				}
+			}
+			engine.listed = nil
+			containerEngines = append(containerEngines, engine)
+		}
+	}
 	// Store json of attached sockets in `enabledSocks`
-	bytes, _ := json.Marshal(enabledEngines)
-	*enabledSocks = C.CString(string(bytes))
+	*enabledSocks = C.CString(pluginCtx.enabledEngines())
+	pluginCtx.enginesMu.Unlock()
 
 	// Start worker goroutine
 	pluginCtx.wg.Add(1)
 	go func() {
 		defer pluginCtx.wg.Done()
-		workerLoop(ctx, goCb, containerEngines, &pluginCtx.wg)
+		workerLoop(ctx, goCb, containerEngines, pluginCtx.attachCh, &pluginCtx.wg)
 	}()
 	h := cgo.NewHandle(&pluginCtx)
 	pluginCtx.pinner.Pin(&h)
@@ -108,14 +184,39 @@ func StopWorker(pCtx unsafe.Pointer) {
 	h := (*cgo.Handle)(pCtx)
 	pluginCtx := h.Value().(*PluginCtx)
 
-	pluginCtx.ctxCancel()
-	pluginCtx.wg.Wait()
+	pluginCtx.stop()
+	container.SaveCacheSnapshot()
 	pluginCtx.stringBuffer.Free()
 	close(pluginCtx.fetchCh)
 	pluginCtx.fetchCh = nil
 
 	pluginCtx.pinner.Unpin()
 	h.Delete()
+	// The plugin logger might not outlive the worker
+	config.SetLogSink(nil)
+}
+
+//export ReloadWorkerEngines
+func ReloadWorkerEngines(pCtx unsafe.Pointer, cfg *C.cchar_t, enabledSocks **C.cchar_t) bool {
+	h := (*cgo.Handle)(pCtx)
+	pluginCtx := h.Value().(*PluginCtx)
+
+	enabled, ok := pluginCtx.reload(ptr.GoString(unsafe.Pointer(cfg)))
+	if !ok {
+		return false
+	}
+	*enabledSocks = C.CString(enabled)
+	return true
+}
+
+//export GetEngineReconnects
+func GetEngineReconnects() uint64 {
+	return container.Reconnects()
+}
+
+//export GetWorkerMetric
+func GetWorkerMetric(name *C.cchar_t) uint64 {
+	return container.Metric(C.GoString(name))
 }
 
 //export AskForContainerInfo
@@ -129,6 +230,7 @@ func AskForContainerInfo(pCtx unsafe.Pointer, containerId *C.cchar_t) bool {
 		case pluginCtx.fetchCh <- containerID:
 			return true
 		default:
+			container.CountFetchDropped()
 			return false
 		}
 	}
@@ -136,3 +238,23 @@ func AskForContainerInfo(pCtx unsafe.Pointer, containerId *C.cchar_t) bool {
 	// does not make sense, report the containerId as handled
 	return true
 }
+
+//export LookupContainerInfo
+func LookupContainerInfo(pCtx unsafe.Pointer, containerId *C.cchar_t) *C.char {
+	h := (*cgo.Handle)(pCtx)
+	pluginCtx := h.Value().(*PluginCtx)
+
+	// Synchronous lookups block the caller, thus they are bounded by the lookup timeout,
+	// and disabled when it is not set.
+	timeout := config.GetLookupTimeout()
+	if pluginCtx.looker == nil || timeout <= 0 {
+		return nil
+	}
+	evt, ok := pluginCtx.looker.Lookup(C.GoString(containerId), timeout)
+	if !ok || container.IsSuppressed(evt) {
+		return nil
+	}
+	// The returned json is freed by the caller
+	shaped := container.ShapePayload(evt)
+	return C.CString(shaped.String())
+}
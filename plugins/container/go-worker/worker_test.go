@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/config"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/container"
 	"github.com/falcosecurity/plugins/plugins/container/go-worker/pkg/event"
 	"github.com/stretchr/testify/assert"
@@ -79,13 +80,13 @@ func TestWorkerLoop(t *testing.T) {
 	globalWaitGroup.Add(1)
 	go func() {
 		defer globalWaitGroup.Done()
-		workerLoop(ctx, func(jsonEvt string, isCreate bool, _ bool) {
+		workerLoop(ctx, func(jsonEvt string, _ event.Kind, _ bool) {
 			numEvents++
 			if numEvents == 10 {
 				// This will only be executed once, because each noop engine produce just 1 event.
 				close(signalCh)
 			}
-		}, containerEngines, globalWaitGroup)
+		}, containerEngines, nil, globalWaitGroup)
 	}()
 
 	select {
@@ -122,9 +123,9 @@ func TestWorkerLoopExitBeforeCtxCancel(t *testing.T) {
 	globalWaitGroup.Add(1)
 	go func() {
 		defer globalWaitGroup.Done()
-		workerLoop(ctx, func(jsonEvt string, isCreate bool, _ bool) {
+		workerLoop(ctx, func(jsonEvt string, _ event.Kind, _ bool) {
 			numEvents++
-		}, containerEngines, globalWaitGroup)
+		}, containerEngines, nil, globalWaitGroup)
 	}()
 
 	// Signal that all noop engines' internal listening goroutines terminated.
@@ -143,3 +144,138 @@ func TestWorkerLoopExitBeforeCtxCancel(t *testing.T) {
 		assert.Equal(t, 0, numEvents)
 	}
 }
+
+func TestWorkerLoopAttach(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	evtCh := make(chan string)
+	attachCh := make(chan container.Engine)
+	globalWaitGroup := &sync.WaitGroup{}
+	globalWaitGroup.Add(1)
+	go func() {
+		defer globalWaitGroup.Done()
+		workerLoop(ctx, func(jsonEvt string, _ event.Kind, _ bool) {
+			evtCh <- jsonEvt
+		}, nil, attachCh, globalWaitGroup)
+	}()
+
+	// Attach an engine, that first notifies the containers it listed
+	listeningWaitGroup := &sync.WaitGroup{}
+	listeningWaitGroup.Add(1)
+	attachCtx, detach := context.WithCancel(ctx)
+	attachCh <- &attachedEngine{
+		Engine: &noopEngine{
+			exitAfter:          time.Duration(math.MaxInt64),
+			eventAfter:         10 * time.Millisecond,
+			listeningWaitGroup: listeningWaitGroup,
+		},
+		ctx: attachCtx,
+		listed: []event.Event{
+			{Info: event.Info{Container: event.Container{ID: "listed1"}}, IsCreate: true},
+			{Info: event.Info{Container: event.Container{ID: "listed2"}}, IsCreate: true},
+		},
+	}
+	for _, expected := range []string{"listed1", "listed2", ""} {
+		select {
+		case jsonEvt := <-evtCh:
+			assert.Contains(t, jsonEvt, `"id":"`+expected+`"`)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the attached engine events")
+		}
+	}
+
+	// Detaching stops the engine, while the worker keeps running
+	detach()
+	listeningWaitGroup.Wait()
+	cancel()
+	globalWaitGroup.Wait()
+}
+
+// socketEngine is a noopEngine bound to a socket, without pre-existing containers.
+type socketEngine struct {
+	noopEngine
+	socket string
+}
+
+func (s *socketEngine) Sock() string {
+	return s.socket
+}
+
+func (s *socketEngine) List(_ context.Context) ([]event.Event, error) {
+	return nil, nil
+}
+
+// recordingAttacher records the attached engines, by key.
+type recordingAttacher struct {
+	attached map[container.EngineKey][]container.Engine
+}
+
+func (r *recordingAttacher) Attach(key container.EngineKey, engines []container.Engine) {
+	r.attached[key] = engines
+}
+
+func (r *recordingAttacher) Detach(key container.EngineKey) {
+	delete(r.attached, key)
+}
+
+func TestAttachAsync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attacher := &recordingAttacher{attached: make(map[container.EngineKey][]container.Engine)}
+	p := &PluginCtx{
+		ctx:      ctx,
+		attacher: attacher,
+		attachCh: make(chan container.Engine, 1),
+		engines:  make(map[container.EngineKey]*attachment),
+	}
+	key := container.EngineKey{Engine: "noop", Socket: "/run/noop.sock"}
+	generators := []container.EngineGenerator{func(context.Context) (container.Engine, error) {
+		return &socketEngine{socket: key.Socket}, nil
+	}}
+
+	// A key detached while its engines are generated is not attached
+	att := p.reserve(key)
+	p.detach(key)
+	p.attachAsync(key, att, generators)
+	assert.Empty(t, attacher.attached)
+	assert.Empty(t, p.attachCh)
+
+	// Otherwise its engines are attached and handed over to the worker loop
+	att = p.reserve(key)
+	p.attachAsync(key, att, generators)
+	assert.Len(t, attacher.attached[key], 1)
+	assert.Equal(t, `{"noop":["/run/noop.sock"]}`, p.enabledEngines())
+	engine := <-p.attachCh
+	assert.Equal(t, key.Socket, engine.Sock())
+}
+
+func TestReloadStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &PluginCtx{
+		ctx:       ctx,
+		ctxCancel: cancel,
+		attacher:  &recordingAttacher{attached: make(map[container.EngineKey][]container.Engine)},
+		attachCh:  make(chan container.Engine),
+		engines:   make(map[container.EngineKey]*attachment),
+	}
+	t.Cleanup(func() { _ = config.LoadEngines(`{}`) })
+	attach := `{"engines":{"simulate":{"enabled":true}}}`
+
+	// Reloads racing with stop never add goroutines while they are waited
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(cfg string) {
+			defer wg.Done()
+			p.reload(cfg)
+		}([]string{attach, `{"engines":{}}`}[i%2])
+	}
+	p.stop()
+	wg.Wait()
+
+	// Once stopping, reloads are rejected
+	_, ok := p.reload(attach)
+	assert.False(t, ok)
+}
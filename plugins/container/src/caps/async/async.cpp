@@ -8,6 +8,18 @@
 std::unique_ptr<falcosecurity::async_event_handler>
         s_async_handler[ASYNC_HANDLER_MAX];
 
+// Logger of the go-worker messages, set while the worker is running.
+static falcosecurity::logger *s_worker_logger = nullptr;
+
+static void log_go_worker(const char *msg, int severity)
+{
+    using falcosecurity::_internal::ss_plugin_log_severity;
+    if(s_worker_logger != nullptr)
+    {
+        s_worker_logger->log(msg, (ss_plugin_log_severity)severity);
+    }
+}
+
 std::vector<std::string> my_plugin::get_async_events()
 {
     return ASYNC_EVENT_NAMES;
@@ -43,6 +55,9 @@ bool my_plugin::start_async_events(
     m_logger.log("starting async go-worker",
                  falcosecurity::_internal::SS_PLUGIN_LOG_SEV_DEBUG);
     nlohmann::json j(m_cfg);
+    // Route the go-worker logs through the plugin logger
+    s_worker_logger = &m_logger;
+    SetWorkerLogger(log_go_worker);
     const char *enabled_engines = nullptr;
     m_async_ctx = StartWorker(generate_async_event<ASYNC_HANDLER_GO_WORKER>,
                               j.dump().c_str(), &enabled_engines);
@@ -72,6 +87,7 @@ bool my_plugin::stop_async_events() noexcept
         // Implemented by GO worker.go
         StopWorker(m_async_ctx);
         m_async_ctx = nullptr;
+        s_worker_logger = nullptr;
 
         for(int i = 0; i < ASYNC_HANDLER_MAX; i++)
         {
@@ -81,6 +97,28 @@ bool my_plugin::stop_async_events() noexcept
     return true;
 }
 
+bool my_plugin::reload_async_engines()
+{
+    // The worker is not running yet: it will start with the new engines.
+    if(m_async_ctx == nullptr)
+    {
+        return true;
+    }
+    nlohmann::json j(m_cfg);
+    const char *enabled_engines = nullptr;
+    if(!ReloadWorkerEngines(m_async_ctx, j.dump().c_str(), &enabled_engines))
+    {
+        m_lasterr = "cannot reload the go-worker engines";
+        m_logger.log(m_lasterr,
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_ERROR);
+        return false;
+    }
+    m_logger.log(fmt::format("attached engine sockets: {}", enabled_engines),
+                 falcosecurity::_internal::SS_PLUGIN_LOG_SEV_DEBUG);
+    free((void *)enabled_engines);
+    return true;
+}
+
 void my_plugin::dump(
         std::unique_ptr<falcosecurity::async_event_handler> async_handler)
 {
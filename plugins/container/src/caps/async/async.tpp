@@ -19,13 +19,14 @@ static std::unordered_map<std::string, std::shared_ptr<const container_info>>
         s_preexisting_containers;
 
 template<async_handler_id id>
-void generate_async_event(const char *json, bool added, bool initial_state)
+void generate_async_event(const char *json, int kind, bool initial_state)
 {
     falcosecurity::events::asyncevent_e_encoder enc;
     enc.set_tid(0); // not-existent tid
     std::string msg = json;
-    if(added)
+    switch(kind)
     {
+    case ASYNC_EVENT_KIND_ADDED:
         enc.set_name(ASYNC_EVENT_NAME_ADDED);
         // We are being called during initial `start_async_events`.
         // Update our internal cache immediately since:
@@ -38,10 +39,33 @@ void generate_async_event(const char *json, bool added, bool initial_state)
             auto cinfo = json_event.get<container_info::ptr_t>();
             s_preexisting_containers[cinfo->m_id] = cinfo;
         }
-    }
-    else
-    {
+        break;
+    case ASYNC_EVENT_KIND_UPDATED:
+        // Full metadata of a container whose added event
+        // only carried the minimal set of infos.
+        enc.set_name(ASYNC_EVENT_NAME_UPDATED);
+        break;
+    case ASYNC_EVENT_KIND_DIED:
+        enc.set_name(ASYNC_EVENT_NAME_DIED);
+        break;
+    case ASYNC_EVENT_KIND_PAUSED:
+        enc.set_name(ASYNC_EVENT_NAME_PAUSED);
+        break;
+    case ASYNC_EVENT_KIND_UNPAUSED:
+        enc.set_name(ASYNC_EVENT_NAME_UNPAUSED);
+        break;
+    case ASYNC_EVENT_KIND_OOM_KILLED:
+        enc.set_name(ASYNC_EVENT_NAME_OOM_KILLED);
+        break;
+    case ASYNC_EVENT_KIND_STATS:
+        enc.set_name(ASYNC_EVENT_NAME_STATS);
+        break;
+    case ASYNC_EVENT_KIND_ENGINE_STATUS:
+        enc.set_name(ASYNC_EVENT_NAME_ENGINE_STATUS);
+        break;
+    default:
         enc.set_name(ASYNC_EVENT_NAME_REMOVED);
+        break;
     }
     enc.set_data((void *)msg.c_str(), msg.size() + 1);
 
@@ -1,5 +1,7 @@
 #include <plugin.h>
+#include <cmath>
 #include <optional>
+#include <set>
 
 //////////////////////////
 // Extract capability
@@ -10,11 +12,32 @@ enum ContainerFields
 {
     TYPE_CONTAINER_ID,
     TYPE_CONTAINER_FULL_CONTAINER_ID,
+    TYPE_CONTAINER_RUNTIME_ID,
     TYPE_CONTAINER_NAME,
     TYPE_CONTAINER_IMAGE,
     TYPE_CONTAINER_IMAGE_ID,
     TYPE_CONTAINER_TYPE,
+    TYPE_CONTAINER_RUNTIME,
+    TYPE_CONTAINER_SANDBOXED_RUNTIME,
+    TYPE_CONTAINER_ISOLATION,
+    TYPE_CONTAINER_CGROUP_PATH,
+    TYPE_CONTAINER_CGROUP_DRIVER,
+    TYPE_CONTAINER_ROOTLESS_UID,
+    TYPE_CONTAINER_CONFIG_DIGEST,
     TYPE_CONTAINER_PRIVILEGED,
+    TYPE_CONTAINER_CAP_ADD,
+    TYPE_CONTAINER_CAP_DROP,
+    TYPE_CONTAINER_CAP_EFFECTIVE,
+    TYPE_CONTAINER_GPU_DEVICES,
+    TYPE_CONTAINER_SECCOMP_PROFILE,
+    TYPE_CONTAINER_APPARMOR_PROFILE,
+    TYPE_CONTAINER_SELINUX_LABEL,
+    TYPE_CONTAINER_NO_NEW_PRIVILEGES,
+    TYPE_CONTAINER_USER,
+    TYPE_CONTAINER_USER_IDS,
+    TYPE_CONTAINER_USERNS,
+    TYPE_CONTAINER_UID_MAP,
+    TYPE_CONTAINER_GID_MAP,
     TYPE_CONTAINER_MOUNTS,
     TYPE_CONTAINER_MOUNT,
     TYPE_CONTAINER_MOUNT_SOURCE,
@@ -22,21 +45,71 @@ enum ContainerFields
     TYPE_CONTAINER_MOUNT_MODE,
     TYPE_CONTAINER_MOUNT_RDWR,
     TYPE_CONTAINER_MOUNT_PROPAGATION,
+    TYPE_CONTAINER_MOUNT_TYPE,
+    TYPE_CONTAINER_MOUNT_NAME,
     TYPE_CONTAINER_IMAGE_REPOSITORY,
     TYPE_CONTAINER_IMAGE_TAG,
     TYPE_CONTAINER_IMAGE_DIGEST,
+    TYPE_CONTAINER_IMAGE_REGISTRY,
+    TYPE_CONTAINER_IMAGE_SIZE,
+    TYPE_CONTAINER_IMAGE_LAYERS,
+    TYPE_CONTAINER_IMAGE_PULLED,
+    TYPE_CONTAINER_IMAGE_PULLED_TS,
+    TYPE_CONTAINER_IMAGE_PULL_AGE,
+    TYPE_CONTAINER_SIZE_RW,
+    TYPE_CONTAINER_SNAPSHOTTER,
+    TYPE_CONTAINER_LOG_PATH,
+    TYPE_CONTAINER_SOCKET,
     TYPE_CONTAINER_HEALTHCHECK,
     TYPE_CONTAINER_LIVENESS_PROBE,
     TYPE_CONTAINER_READINESS_PROBE,
     TYPE_CONTAINER_START_TS,
     TYPE_CONTAINER_DURATION,
+    TYPE_CONTAINER_CREATED_TS,
+    TYPE_CONTAINER_STARTED_TS,
     TYPE_CONTAINER_IP_ADDR,
     TYPE_CONTAINER_CNIRESULT,
+    TYPE_CONTAINER_NETWORKS,
+    TYPE_CONTAINER_IPS,
+    TYPE_CONTAINER_NETWORK_IP,
+    TYPE_CONTAINER_NETWORK_MAC,
+    TYPE_CONTAINER_NETWORK_GATEWAY,
+    TYPE_CONTAINER_NETWORK_ALIASES,
+    TYPE_CONTAINER_DNS_NAMES,
     TYPE_CONTAINER_HOST_PID,
     TYPE_CONTAINER_HOST_NETWORK,
     TYPE_CONTAINER_HOST_IPC,
+    TYPE_CONTAINER_CPU_SHARES,
+    TYPE_CONTAINER_CPU_WEIGHT,
+    TYPE_CONTAINER_CPU_QUOTA,
+    TYPE_CONTAINER_CPU_PERIOD,
+    TYPE_CONTAINER_CPU_LIMIT,
     TYPE_CONTAINER_LABEL,
     TYPE_CONTAINER_LABELS,
+    TYPE_CONTAINER_ANNOTATION,
+    TYPE_CONTAINER_ANNOTATIONS,
+    TYPE_CONTAINER_EXIT_CODE,
+    TYPE_CONTAINER_FINISHED_TS,
+    TYPE_CONTAINER_OOM_KILLED,
+    TYPE_CONTAINER_RESTART_COUNT,
+    TYPE_CONTAINER_PAUSED_TS,
+    TYPE_CONTAINER_PAUSE_DURATION,
+    TYPE_CONTAINER_HEALTH_STATUS,
+    TYPE_CONTAINER_HEALTH_OUTPUT,
+    TYPE_CONTAINER_HEALTHCHECK_CMD,
+    TYPE_CONTAINER_HEALTHCHECK_INTERVAL,
+    TYPE_CONTAINER_HEALTHCHECK_TIMEOUT,
+    TYPE_CONTAINER_HEALTHCHECK_RETRIES,
+    TYPE_CONTAINER_STATS_CPU_PERCENT,
+    TYPE_CONTAINER_STATS_MEMORY_USAGE,
+    TYPE_CONTAINER_STATS_MEMORY_LIMIT,
+    TYPE_CONTAINER_STATS_PIDS,
+    TYPE_CONTAINER_STATS_SAMPLED_TS,
+    TYPE_CONTAINER_ENGINE_TYPE,
+    TYPE_CONTAINER_ENGINE_SOCKET,
+    TYPE_CONTAINER_ENGINE_STATUS,
+    TYPE_CONTAINER_ENGINE_ERROR,
+    TYPE_CONTAINER_ENV,
     TYPE_IS_CONTAINER_HEALTHCHECK,
     TYPE_IS_CONTAINER_LIVENESS_PROBE,
     TYPE_IS_CONTAINER_READINESS_PROBE,
@@ -48,8 +121,27 @@ enum ContainerFields
     TYPE_K8S_POD_FULL_SANDBOX_ID,
     TYPE_K8S_POD_LABEL,
     TYPE_K8S_POD_LABELS,
+    TYPE_K8S_POD_ANNOTATION,
+    TYPE_K8S_POD_ANNOTATIONS,
     TYPE_K8S_POD_IP,
     TYPE_K8S_POD_CNIRESULT,
+    TYPE_K8S_POD_NETNS,
+    TYPE_K8S_WORKLOAD_KIND,
+    TYPE_K8S_WORKLOAD_NAME,
+    TYPE_COMPOSE_PROJECT,
+    TYPE_COMPOSE_SERVICE,
+    TYPE_COMPOSE_CONTAINER_NUMBER,
+    TYPE_ECS_TASK_ARN,
+    TYPE_ECS_CLUSTER,
+    TYPE_ECS_SERVICE_NAME,
+    TYPE_ECS_TASK_FAMILY,
+    TYPE_ECS_TASK_REVISION,
+    TYPE_NOMAD_ALLOC_ID,
+    TYPE_NOMAD_JOB_ID,
+    TYPE_NOMAD_TASK_GROUP,
+    TYPE_CF_APP_GUID,
+    TYPE_CF_SPACE_GUID,
+    TYPE_CF_ORG_GUID,
     // below fields are all deprecated
     TYPE_K8S_RC_NAME,
     TYPE_K8S_RC_ID,
@@ -125,6 +217,12 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "instances of userspace container engine lookup delays, this "
              "field may not be available "
              "yet."},
+            {ft::FTYPE_STRING, "container.runtime_id", "Runtime Container ID",
+             "The container ID prefixed by the runtime name, as reported by "
+             "Kubernetes in the pod status, e.g. "
+             "containerd://"
+             "3ad7b26ded6d8e7b23da7d48fe889434573036c27ae5a74837233de441c3601e."
+             " Only available for containers discovered through the CRI API."},
             {ft::FTYPE_STRING,
              "container.name",
              "Container Name",
@@ -150,11 +248,125 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "lookup delays, this field may not be available yet."},
             {ft::FTYPE_STRING, "container.type", "Type",
              "The container type, e.g. docker, cri-o, containerd etc."},
+            {ft::FTYPE_STRING, "container.runtime", "Runtime",
+             "The OCI runtime running the container, as reported by the "
+             "container engine (e.g. runc, io.containerd.kata.v2, runsc); for "
+             "CRI-O containers, the runtime handler of their pod runtime "
+             "class. In instances of userspace container engine lookup "
+             "delays, this field may not be available yet."},
+            {ft::FTYPE_BOOL, "container.sandboxed_runtime",
+             "Sandboxed Runtime",
+             "'true' for containers run in a sandbox by their OCI runtime, i.e. "
+             "Kata Containers or gVisor, 'false' otherwise. In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "container.isolation", "Isolation",
+             "The isolation mode of windows containers: 'process' for "
+             "containers sharing the host kernel, 'hyperv' for containers run "
+             "in a Hyper-V utility VM. Only available for windows containers "
+             "of docker, containerd and CRI. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_STRING, "container.cgroup.path", "Cgroup Path",
+             "The cgroup path of the container, relative to the cgroup root "
+             "(e.g. '/system.slice/docker-<id>.scope'), as taken from its OCI "
+             "spec or, when not reported by the container engine, from the "
+             "cgroup of its process. In instances of userspace container "
+             "engine lookup delays, this field may not be available yet."},
+            {ft::FTYPE_STRING, "container.cgroup.driver", "Cgroup Driver",
+             "The driver managing the cgroup of the container, i.e. 'systemd' "
+             "or 'cgroupfs'. In instances of userspace container engine lookup "
+             "delays, this field may not be available yet."},
+            {ft::FTYPE_UINT64, "container.rootless_uid", "Rootless UID",
+             "The uid owning the rootless docker daemon managing the "
+             "container. Only available for containers of rootless docker "
+             "daemons."},
+            {ft::FTYPE_STRING, "container.config_digest", "Config Digest",
+             "A stable digest of the full configuration of the container, "
+             "excluding its runtime state (e.g. restarts and health status), "
+             "to detect configuration drifts. Only available when "
+             "'payload.digest' is enabled."},
             {ft::FTYPE_BOOL, "container.privileged", "Privileged",
              "'true' for containers running as privileged, 'false' otherwise. "
              "In instances of "
              "userspace container engine lookup delays, this field may not be "
              "available yet."},
+            {ft::FTYPE_STRING, "container.cap_add", "Added Capabilities",
+             "A comma-separated list of the capabilities added to the "
+             "container engine defaults (e.g. CAP_NET_ADMIN,CAP_SYS_PTRACE). "
+             "In instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_STRING, "container.cap_drop", "Dropped Capabilities",
+             "A comma-separated list of the capabilities dropped from the "
+             "container engine defaults (e.g. ALL). In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_STRING, "container.cap_effective",
+             "Effective Capabilities",
+             "A comma-separated list of the effective capabilities of the "
+             "container init process, from its OCI spec. Only available for "
+             "containerd, CRI and podman containers. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_STRING, "container.gpu_devices", "GPU Devices",
+             "A comma-separated list of the GPU devices requested by or "
+             "assigned to the container, as found in its metadata: indexes or "
+             "UUIDs requested to the nvidia runtime (e.g. through "
+             "NVIDIA_VISIBLE_DEVICES or `docker run --gpus`, 'all' for all "
+             "GPUs), CDI device names (e.g. nvidia.com/gpu=0) and GPU device "
+             "nodes (e.g. /dev/nvidia0, /dev/dri/renderD128). In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "container.seccomp_profile", "Seccomp Profile",
+             "The container seccomp profile: 'unconfined', 'runtime/default' "
+             "for the container engine default profile, 'localhost/...' for "
+             "profiles loaded from a path, or 'custom' for profiles only known "
+             "by their rules (e.g. from the OCI spec of containerd "
+             "containers). In instances of userspace container engine lookup "
+             "delays, this field may not be available yet."},
+            {ft::FTYPE_STRING, "container.apparmor_profile", "AppArmor Profile",
+             "The container AppArmor profile (e.g. docker-default, "
+             "unconfined). In instances of userspace container engine lookup "
+             "delays, this field may not be available yet."},
+            {ft::FTYPE_STRING, "container.selinux_label", "SELinux Label",
+             "The container SELinux process label (e.g. "
+             "system_u:system_r:container_t:s0:c1,c2). In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_BOOL, "container.no_new_privileges",
+             "No New Privileges",
+             "'true' for containers whose processes cannot gain new privileges "
+             "(no_new_privs), 'false' otherwise. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_STRING, "container.user", "User",
+             "The user the container processes run as, as configured in the "
+             "container engine (e.g. 'nginx', '1000:1000'). In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "container.user_ids", "User IDs",
+             "The numeric user and group ids the container processes run as, "
+             "in the format 'uid:gid' (e.g. '0:0'). Only reported for "
+             "containerd and CRI containers. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_BOOL, "container.userns", "User Namespace",
+             "'true' for containers running in their own user namespace (e.g. "
+             "with docker userns-remap), whose root user is not mapped to the "
+             "host one, 'false' otherwise. In instances of userspace container "
+             "engine lookup delays, this field may not be available yet."},
+            {ft::FTYPE_STRING, "container.uid_map", "UID Mappings",
+             "A comma-separated list of the container user namespace uid "
+             "mappings. Each item in the list has the format "
+             "'container_id:host_id:size' (e.g. 0:100000:65536). In instances "
+             "of userspace container engine lookup delays, this field may not "
+             "be available yet."},
+            {ft::FTYPE_STRING, "container.gid_map", "GID Mappings",
+             "A comma-separated list of the container user namespace gid "
+             "mappings. Each item in the list has the format "
+             "'container_id:host_id:size' (e.g. 0:100000:65536). In instances "
+             "of userspace container engine lookup delays, this field may not "
+             "be available yet."},
             {ft::FTYPE_STRING, "container.mounts", "Mounts",
              "A space-separated list of mount information. Each item in the "
              "list has the format "
@@ -218,6 +430,20 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "field may not be "
              "available yet.",
              req_both_arg},
+            {ft::FTYPE_STRING, "container.mount.type", "Mount Type",
+             "The mount type (e.g. bind, volume, tmpfs), specified by number "
+             "(e.g. container.mount.type[0]) or mount source "
+             "(container.mount.type[/var/run/docker.sock]). The pathname can "
+             "be a glob. In instances of userspace container engine lookup "
+             "delays, this field may not be available yet.",
+             req_both_arg},
+            {ft::FTYPE_STRING, "container.mount.name", "Mount Volume Name",
+             "The name of the volume backing the mount, for named volumes, "
+             "specified by number (e.g. container.mount.name[0]) or mount "
+             "source (container.mount.name[/usr/local]). The pathname can be "
+             "a glob. In instances of userspace container engine lookup "
+             "delays, this field may not be available yet.",
+             req_both_arg},
             {ft::FTYPE_STRING,
              "container.image.repository",
              "Repository",
@@ -246,6 +472,46 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              " In instances of "
              "userspace container engine lookup delays, this field may not be "
              "available yet."},
+            {ft::FTYPE_STRING, "container.image.registry", "Registry",
+             "The container image registry (e.g. docker.io, quay.io). In "
+             "instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_UINT64, "container.image.size", "Image Size",
+             "The container image size in bytes, as reported by the container "
+             "engine image service. In instances of userspace container "
+             "engine lookup delays, this field may not be available yet."},
+            {ft::FTYPE_UINT64, "container.image.layers", "Image Layers",
+             "The number of layers of the container image. In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_BOOL, "container.image.pulled", "Image Freshly Pulled",
+             "'true' if the container image got pulled shortly (up to 10 "
+             "minutes) before the container creation, as notified by the "
+             "image pull events of docker, podman and containerd. Always "
+             "'false' for cri containers, whose runtime does not notify "
+             "image pulls, and for containers created before the plugin "
+             "started."},
+            {ft::FTYPE_ABSTIME, "container.image.pulled_ts", "Image Pull",
+             "Pull of the container image as epoch timestamp in nanoseconds, "
+             "only set when container.image.pulled is 'true'."},
+            {ft::FTYPE_RELTIME, "container.image.pull_age", "Image Pull Age",
+             "Number of nanoseconds between the pull of the container image "
+             "and the container creation, only set when "
+             "container.image.pulled is 'true'."},
+            {ft::FTYPE_UINT64, "container.size_rw", "Writable Layer Size",
+             "The size in bytes of the files written to the container "
+             "writable layer. Only available when `with_size` is enabled, for "
+             "docker, podman, cri and containerd containers."},
+            {ft::FTYPE_STRING, "container.snapshotter", "Snapshotter",
+             "The snapshotter backing the container rootfs (e.g. overlayfs, "
+             "native). Only available for containerd containers."},
+            {ft::FTYPE_STRING, "container.log_path", "Log Path",
+             "The path of the container log file on the host, as reported "
+             "by the container runtime. Only available for cri containers."},
+            {ft::FTYPE_STRING, "container.socket", "Container Socket",
+             "The socket of the container engine the container was reported "
+             "by, telling apart the engines when several sockets are "
+             "configured. Only available for docker containers."},
             {ft::FTYPE_STRING, "container.healthcheck",
              "[Deprecated] Health Check",
              "Deprecated, will be removed in a future version."},
@@ -264,6 +530,17 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "socket / container engine."},
             {ft::FTYPE_RELTIME, "container.duration", "Container Duration",
              "Number of nanoseconds since container.start_ts."},
+            {ft::FTYPE_ABSTIME, "container.created_ts", "Container Creation",
+             "Container creation as epoch timestamp in nanoseconds, as "
+             "reported by the container engine. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_ABSTIME, "container.started_ts", "Container Last Start",
+             "Last start of the container as epoch timestamp in nanoseconds, "
+             "as reported by the container engine. Unlike container.start_ts, "
+             "it is updated when the container gets restarted. In instances "
+             "of userspace container engine lookup delays, this field may not "
+             "be available yet."},
             {ft::FTYPE_STRING, "container.ip", "Container ip address",
              "The container's / pod's primary ip address as retrieved from the "
              "container engine. Only "
@@ -287,6 +564,45 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "instances of userspace container engine lookup delays, this "
              "field may not be available "
              "yet."},
+            {ft::FTYPE_STRING, "container.networks", "Networks",
+             "A comma-separated list of the names of the networks the "
+             "container is attached to, e.g. docker networks, or the pod "
+             "interfaces for CRI containers (e.g. 'eth0,net1' with Multus). In "
+             "instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_STRING, "container.ips", "IP Addresses",
+             "A comma-separated list of the ipv4 and ipv6 addresses of the "
+             "container on all of its networks. In instances of userspace "
+             "container engine lookup delays, this field may not be available "
+             "yet."},
+            {ft::FTYPE_STRING, "container.network.ip", "Network IP Address",
+             "The ip address of the container on a network, specified by "
+             "number (e.g. container.network.ip[0]) or name (e.g. "
+             "container.network.ip[backend]). The ipv6 address is returned "
+             "for ipv6-only networks.",
+             req_both_arg},
+            {ft::FTYPE_STRING, "container.network.mac", "Network MAC Address",
+             "The MAC address of the container interface on a network, "
+             "specified by number (e.g. container.network.mac[0]) or name "
+             "(e.g. container.network.mac[backend]).",
+             req_both_arg},
+            {ft::FTYPE_STRING, "container.network.gateway", "Network Gateway",
+             "The gateway of a network, specified by number (e.g. "
+             "container.network.gateway[0]) or name (e.g. "
+             "container.network.gateway[backend]).",
+             req_both_arg},
+            {ft::FTYPE_STRING, "container.network.aliases", "Network Aliases",
+             "A comma-separated list of the DNS names of the container on a "
+             "network, e.g. its compose service and network aliases, "
+             "specified by number (e.g. container.network.aliases[0]) or name "
+             "(e.g. container.network.aliases[backend]).",
+             req_both_arg},
+            {ft::FTYPE_STRING, "container.dns_names", "DNS Names",
+             "A comma-separated list of the DNS names of the container on all "
+             "of its networks, without duplicates. Unlike the container name, "
+             "they usually refer to the service identity. In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
             {ft::FTYPE_BOOL, "container.host_pid", "Host PID Namespace",
              "'true' if the container is running in the host PID namespace, "
              "'false' otherwise."},
@@ -296,11 +612,140 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
             {ft::FTYPE_BOOL, "container.host_ipc", "Host IPC Namespace",
              "'true' if the container is running in the host IPC namespace, "
              "'false' otherwise."},
+            {ft::FTYPE_UINT64, "container.cpu_shares", "CPU Shares",
+             "The container CPU shares (relative weight, 1024 by default). In "
+             "instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_UINT64, "container.cpu_weight", "CPU Weight",
+             "The container cgroup v2 CPU weight, converted from its CPU "
+             "shares as OCI runtimes do (e.g. 39 for 1024 shares). In "
+             "instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_UINT64, "container.cpu_quota", "CPU Quota",
+             "The container CFS quota in microseconds, per CPU period. Only "
+             "available for containers with a CPU limit. In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_UINT64, "container.cpu_period", "CPU Period",
+             "The container CFS period in microseconds. In instances of "
+             "userspace container engine lookup delays, this field may not be "
+             "available yet."},
+            {ft::FTYPE_UINT64, "container.cpu_limit", "CPU Limit",
+             "The container CPU limit in millicores (e.g. 1500 for 1.5 cores), "
+             "computed from its CFS quota and period. Only available for "
+             "containers with a CPU limit. In instances of userspace container "
+             "engine lookup delays, this field may not be available yet."},
             {ft::FTYPE_STRING, "container.label", "Container Label",
              "Container label. E.g. 'container.label.foo'.", req_key_arg},
             {ft::FTYPE_STRING, "container.labels", "Container Labels",
              "Container comma-separated key/value labels. E.g. "
              "'foo1:bar1,foo2:bar2'."},
+            {ft::FTYPE_STRING, "container.annotation", "Container Annotation",
+             "CRI container annotation, only available for the annotations "
+             "matching the `annotations.include` patterns. E.g. "
+             "'container.annotation[sidecar.istio.io/inject]'.",
+             req_key_arg},
+            {ft::FTYPE_STRING, "container.annotations",
+             "Container Annotations",
+             "CRI container comma-separated key/value annotations, only "
+             "reporting the ones matching the `annotations.include` "
+             "patterns. E.g. 'foo1:bar1,foo2:bar2'."},
+            {ft::FTYPE_UINT64, "container.exit_code", "Container Exit Code",
+             "The exit code of the container init process. Only available "
+             "once the container terminated, e.g. in 'container_died' "
+             "events, or got restarted, referring to its last run. Negative "
+             "exit codes, reported by some runtimes when the exit status is "
+             "unknown, are not available."},
+            {ft::FTYPE_ABSTIME, "container.finished_ts", "Container Finish",
+             "Container termination as epoch timestamp in nanoseconds. Only "
+             "available once the container terminated, e.g. in "
+             "'container_died' events, or got restarted, referring to its "
+             "last run."},
+            {ft::FTYPE_BOOL, "container.oom_killed", "Container OOM Killed",
+             "'true' if the container init process got killed by the OOM "
+             "killer, 'false' otherwise. Only available once the container "
+             "terminated, e.g. in 'container_died' events, or got restarted, "
+             "referring to its last run."},
+            {ft::FTYPE_UINT64, "container.restart_count",
+             "Container Restart Count",
+             "Number of times the container got restarted by its container "
+             "engine, or by the kubelet for Kubernetes containers. In "
+             "instances of userspace container engine lookup delays, this "
+             "field may not be available yet."},
+            {ft::FTYPE_ABSTIME, "container.paused_ts", "Container Pause",
+             "Container pause as epoch timestamp in nanoseconds. Only "
+             "available while the container is paused, and in "
+             "'container_unpaused' events."},
+            {ft::FTYPE_RELTIME, "container.pause_duration",
+             "Container Pause Duration",
+             "Number of nanoseconds since container.paused_ts. In "
+             "'container_unpaused' events, it is the whole duration of the "
+             "container suspension."},
+            {ft::FTYPE_STRING, "container.health_status",
+             "Container Health Status",
+             "The health status of the container, as reported by its "
+             "healthcheck. Can be 'healthy', 'unhealthy' or 'starting'. Only "
+             "available for docker containers with a healthcheck."},
+            {ft::FTYPE_STRING, "container.health_output",
+             "Container Health Output",
+             "The output of the last container healthcheck, if failing."},
+            {ft::FTYPE_STRING, "container.healthcheck.cmd",
+             "Container Healthcheck Command",
+             "The command line of the configured container healthcheck, e.g. "
+             "'/bin/sh -c nc -z localhost 5432'. Only available for docker "
+             "and podman containers with a healthcheck."},
+            {ft::FTYPE_RELTIME, "container.healthcheck.interval",
+             "Container Healthcheck Interval",
+             "Number of nanoseconds between two runs of the container "
+             "healthcheck, if configured."},
+            {ft::FTYPE_RELTIME, "container.healthcheck.timeout",
+             "Container Healthcheck Timeout",
+             "Number of nanoseconds after which a run of the container "
+             "healthcheck is considered failed, if configured."},
+            {ft::FTYPE_UINT64, "container.healthcheck.retries",
+             "Container Healthcheck Retries",
+             "Number of consecutive failures of the container healthcheck "
+             "needed to consider the container unhealthy, if configured."},
+            {ft::FTYPE_UINT64, "container.stats.cpu_percent",
+             "Container CPU Usage Percent",
+             "CPU usage of the container since the previous stats sample, in "
+             "percentage of a single CPU, thus up to 100 times the number of "
+             "CPUs. Only available once the container got sampled, e.g. in "
+             "'container_stats' events, when `stats_interval_ms` is set."},
+            {ft::FTYPE_UINT64, "container.stats.memory_usage",
+             "Container Memory Usage",
+             "Memory usage of the container in bytes, excluding the inactive "
+             "page cache, as of the last stats sample."},
+            {ft::FTYPE_UINT64, "container.stats.memory_limit",
+             "Container Memory Usage Limit",
+             "Memory limit of the container in bytes, as of the last stats "
+             "sample; the host memory for unlimited containers."},
+            {ft::FTYPE_UINT64, "container.stats.pids", "Container Pids",
+             "Number of processes and threads of the container, as of the last "
+             "stats sample."},
+            {ft::FTYPE_ABSTIME, "container.stats.sampled_ts",
+             "Container Stats Sample",
+             "Last stats sample of the container as epoch timestamp in "
+             "nanoseconds."},
+            {ft::FTYPE_STRING, "container.engine.type", "Engine Type",
+             "Type of the container engine whose connection status changed, "
+             "e.g. 'docker'. Only available in 'container_engine_status' "
+             "events."},
+            {ft::FTYPE_STRING, "container.engine.socket", "Engine Socket",
+             "Socket of the container engine whose connection status changed. "
+             "Only available in 'container_engine_status' events."},
+            {ft::FTYPE_STRING, "container.engine.status", "Engine Status",
+             "'up' once the connection of the container engine got "
+             "established or recovered, 'down' once it got lost. Only "
+             "available in 'container_engine_status' events."},
+            {ft::FTYPE_STRING, "container.engine.error", "Engine Error",
+             "Cause of the connection loss of the container engine, if any. "
+             "Only available in 'container_engine_status' events."},
+            {ft::FTYPE_STRING, "container.env", "Container Environment",
+             "Value of a container environment variable. E.g. "
+             "'container.env[DEPLOYMENT_ID]'. Only the variables allowed by "
+             "the `env` plugin config are available.",
+             req_key_arg},
             {ft::FTYPE_BOOL, "proc.is_container_healthcheck",
              "[Deprecated] Process Is Container Healthcheck",
              "Deprecated, will be removed in a future version."},
@@ -405,6 +850,18 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "simultaneously as we look up the "
              "'container.*' fields. In cases of lookup delays, it may not be "
              "available yet."},
+            {ft::FTYPE_STRING, "k8s.pod.annotation", "Pod Annotation",
+             "The Kubernetes pod annotation, as reported by the CRI runtime, "
+             "only available for the annotations matching the "
+             "`annotations.include` patterns. E.g. "
+             "'k8s.pod.annotation[sidecar.istio.io/inject]'. In cases of "
+             "lookup delays, it may not be available yet.",
+             req_key_arg},
+            {ft::FTYPE_STRING, "k8s.pod.annotations", "Pod Annotations",
+             "The Kubernetes pod comma-separated key/value annotations, as "
+             "reported by the CRI runtime, only reporting the ones matching "
+             "the `annotations.include` patterns. E.g. 'foo1:bar1,foo2:bar2'. "
+             "In cases of lookup delays, it may not be available yet."},
             {ft::FTYPE_STRING, "k8s.pod.ip", "Pod Ip",
              "The Kubernetes pod ip, same as container.ip field as each "
              "container in a pod shares the "
@@ -432,6 +889,73 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "simultaneously as we look up the 'container.*' fields. In cases "
              "of lookup delays, it may "
              "not be available yet."},
+            {ft::FTYPE_STRING, "k8s.pod.netns", "Pod Network Namespace",
+             "The path of the Kubernetes pod sandbox network namespace, "
+             "shared by each container in the pod, e.g. "
+             "/var/run/netns/cni-1a2b3c4d. This field is extracted from the "
+             "container runtime socket simultaneously as we look up the "
+             "'container.*' fields. In cases of lookup delays, it may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "k8s.workload.kind", "Pod Workload Kind",
+             "The kind of the Kubernetes workload owning the pod, one of "
+             "Deployment, StatefulSet, DaemonSet or Job. It is inferred from "
+             "the labels set on the pod by its controller, or from the pod "
+             "name for deployments, without querying the API server; empty "
+             "for bare pods. In cases of lookup delays, it may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "k8s.workload.name", "Pod Workload Name",
+             "The name of the Kubernetes workload owning the pod, e.g. the "
+             "deployment name. See k8s.workload.kind. In cases of lookup "
+             "delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "compose.project", "Compose Project",
+             "The name of the docker compose (or podman-compose) project of "
+             "the container. In cases of lookup delays, it may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "compose.service", "Compose Service",
+             "The name of the compose service of the container, that is also "
+             "its DNS name on the project networks. In cases of lookup "
+             "delays, it may not be available yet."},
+            {ft::FTYPE_UINT64, "compose.container_number",
+             "Compose Container Number",
+             "The replica number of the container within its compose service, "
+             "starting from 1. In cases of lookup delays, it may not be "
+             "available yet."},
+            {ft::FTYPE_STRING, "ecs.task.arn", "ECS Task ARN",
+             "The ARN of the ECS task of the container, e.g. "
+             "arn:aws:ecs:us-west-2:111122223333:task/default/"
+             "158d1c8083dd49d6b527399fd6414f5c. In cases of lookup delays, it "
+             "may not be available yet."},
+            {ft::FTYPE_STRING, "ecs.cluster", "ECS Cluster",
+             "The name of the ECS cluster of the container task. In cases of "
+             "lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "ecs.service.name", "ECS Service Name",
+             "The name of the ECS service of the container task, if any. Only "
+             "available with the `ecs_metadata` plugin config enabled, since "
+             "it is retrieved from the task metadata endpoint. In cases of "
+             "lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "ecs.task.family", "ECS Task Family",
+             "The family of the task definition of the container task. In "
+             "cases of lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "ecs.task.revision", "ECS Task Revision",
+             "The revision of the task definition of the container task. In "
+             "cases of lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "nomad.alloc.id", "Nomad Allocation ID",
+             "The ID of the Nomad allocation of the container, e.g. "
+             "5b3c1f4e-9d2a-4e8b-a3f7-1c2d3e4f5a6b. In cases of lookup "
+             "delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "nomad.job.id", "Nomad Job ID",
+             "The ID of the Nomad job of the container allocation. In cases of "
+             "lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "nomad.task_group", "Nomad Task Group",
+             "The name of the Nomad task group of the container allocation. "
+             "In cases of lookup delays, it may not be available yet."},
+            {ft::FTYPE_STRING, "cf.app.guid", "Cloud Foundry App GUID",
+             "The GUID of the Cloud Foundry app of the container, for app "
+             "instances run by Diego cells."},
+            {ft::FTYPE_STRING, "cf.space.guid", "Cloud Foundry Space GUID",
+             "The GUID of the Cloud Foundry space of the container app."},
+            {ft::FTYPE_STRING, "cf.org.guid", "Cloud Foundry Org GUID",
+             "The GUID of the Cloud Foundry org of the container app."},
             {ft::FTYPE_STRING, "k8s.rc.name",
              "[Deprecated] Replication Controller Name",
              "Deprecated. Use `k8smeta` plugin instead."},
@@ -510,6 +1034,34 @@ concatenate_container_labels(const std::map<std::string, std::string> &labels,
     }
 }
 
+static inline void concatenate_strings(const std::vector<std::string> &strs,
+                                       std::string *s)
+{
+    for(auto const &str : strs)
+    {
+        if(!s->empty())
+        {
+            s->append(",");
+        }
+        s->append(str);
+    }
+}
+
+// Convert CPU shares to a cgroup v2 CPU weight, mapping the [2, 262144] shares
+// range to the [1, 10000] weight one, the same way OCI runtimes do.
+static inline uint64_t cpu_shares_to_weight(int64_t shares)
+{
+    if(shares <= 2)
+    {
+        return 1;
+    }
+    if(shares >= 262144)
+    {
+        return 10000;
+    }
+    return 1 + ((shares - 2) * 9999) / 262142;
+}
+
 bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
 {
     auto &req = in.get_extract_request();
@@ -522,6 +1074,12 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
 
     bool is_container_async_event_create = false;
     bool is_container_async_event_remove = false;
+    bool is_container_async_event_update = false;
+    bool is_container_async_event_die = false;
+    bool is_container_async_event_pause = false;
+    bool is_container_async_event_oom_killed = false;
+    bool is_container_async_event_stats = false;
+    bool is_engine_status_async_event = false;
 
     /*
      * NOTE: Extract might be called in two cases:
@@ -545,12 +1103,63 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
                 std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_ADDED) == 0;
         is_container_async_event_remove =
                 std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_REMOVED) == 0;
+        is_container_async_event_update =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_UPDATED) == 0;
+        is_container_async_event_die =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_DIED) == 0;
+        is_container_async_event_pause =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_PAUSED) == 0 ||
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_UNPAUSED) == 0;
+        is_container_async_event_oom_killed =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_OOM_KILLED) == 0;
+        is_container_async_event_stats =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_STATS) == 0;
+        is_engine_status_async_event =
+                std::strcmp(ad.get_name(), ASYNC_EVENT_NAME_ENGINE_STATUS) == 0;
+    }
+
+    if(field_id >= TYPE_CONTAINER_ENGINE_TYPE &&
+       field_id <= TYPE_CONTAINER_ENGINE_ERROR)
+    {
+        // Engine statuses carry no container: the engine fields are only
+        // available in their events, once parsed.
+        if(!is_engine_status_async_event ||
+           m_last_engine_status.first != evt_reader.get_num())
+        {
+            return true;
+        }
+        const auto &status = m_last_engine_status.second;
+        switch(field_id)
+        {
+        case TYPE_CONTAINER_ENGINE_TYPE:
+            req.set_value(status.m_engine);
+            break;
+        case TYPE_CONTAINER_ENGINE_SOCKET:
+            req.set_value(status.m_socket);
+            break;
+        case TYPE_CONTAINER_ENGINE_STATUS:
+            req.set_value(status.m_status);
+            break;
+        default:
+            if(!status.m_error.empty())
+            {
+                req.set_value(status.m_error);
+            }
+            break;
+        }
+        return true;
     }
 
     bool is_container_event{
             evt_type == PPME_CONTAINER_E || evt_type == PPME_CONTAINER_JSON_E ||
             evt_type == PPME_CONTAINER_JSON_2_E ||
-            is_container_async_event_create || is_container_async_event_remove};
+            is_container_async_event_create ||
+            is_container_async_event_remove ||
+            is_container_async_event_update ||
+            is_container_async_event_die ||
+            is_container_async_event_pause ||
+            is_container_async_event_oom_killed ||
+            is_container_async_event_stats};
     // As mentioned above, m_last_container might be null or relative to another
     // event. Check the timestamp.
     if(is_container_event && m_last_container.first == evt_reader.get_num())
@@ -619,6 +1228,12 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
     case TYPE_CONTAINER_FULL_CONTAINER_ID:
         req.set_value(cinfo->m_full_id);
         break;
+    case TYPE_CONTAINER_RUNTIME_ID:
+        if(!cinfo->m_runtime_id.empty())
+        {
+            req.set_value(cinfo->m_runtime_id);
+        }
+        break;
     case TYPE_CONTAINER_NAME:
         req.set_value(cinfo->m_name);
         break;
@@ -631,9 +1246,130 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
     case TYPE_CONTAINER_TYPE:
         req.set_value(to_string(cinfo->m_type));
         break;
+    case TYPE_CONTAINER_RUNTIME:
+        if(!cinfo->m_runtime.empty())
+        {
+            req.set_value(cinfo->m_runtime);
+        }
+        break;
+    case TYPE_CONTAINER_SANDBOXED_RUNTIME:
+        req.set_value(cinfo->m_sandboxed_runtime);
+        break;
+    case TYPE_CONTAINER_ISOLATION:
+        if(!cinfo->m_isolation.empty())
+        {
+            req.set_value(cinfo->m_isolation);
+        }
+        break;
+    case TYPE_CONTAINER_CGROUP_PATH:
+        if(!cinfo->m_cgroup_path.empty())
+        {
+            req.set_value(cinfo->m_cgroup_path);
+        }
+        break;
+    case TYPE_CONTAINER_CGROUP_DRIVER:
+        if(!cinfo->m_cgroup_driver.empty())
+        {
+            req.set_value(cinfo->m_cgroup_driver);
+        }
+        break;
+    case TYPE_CONTAINER_ROOTLESS_UID:
+        if(cinfo->m_rootless_uid != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_rootless_uid);
+        }
+        break;
+    case TYPE_CONTAINER_CONFIG_DIGEST:
+        if(!cinfo->m_config_digest.empty())
+        {
+            req.set_value(cinfo->m_config_digest);
+        }
+        break;
     case TYPE_CONTAINER_PRIVILEGED:
         req.set_value(cinfo->m_privileged);
         break;
+    case TYPE_CONTAINER_CAP_ADD:
+    case TYPE_CONTAINER_CAP_DROP:
+    case TYPE_CONTAINER_CAP_EFFECTIVE:
+    {
+        const auto &caps = field_id == TYPE_CONTAINER_CAP_ADD
+                                   ? cinfo->m_cap_add
+                                   : (field_id == TYPE_CONTAINER_CAP_DROP
+                                              ? cinfo->m_cap_drop
+                                              : cinfo->m_cap_effective);
+        if(!caps.empty())
+        {
+            std::string tstr;
+            concatenate_strings(caps, &tstr);
+            req.set_value(tstr);
+        }
+        break;
+    }
+    case TYPE_CONTAINER_GPU_DEVICES:
+        if(!cinfo->m_gpu_devices.empty())
+        {
+            std::string tstr;
+            concatenate_strings(cinfo->m_gpu_devices, &tstr);
+            req.set_value(tstr);
+        }
+        break;
+    case TYPE_CONTAINER_SECCOMP_PROFILE:
+        if(!cinfo->m_seccomp_profile.empty())
+        {
+            req.set_value(cinfo->m_seccomp_profile);
+        }
+        break;
+    case TYPE_CONTAINER_APPARMOR_PROFILE:
+        if(!cinfo->m_apparmor_profile.empty())
+        {
+            req.set_value(cinfo->m_apparmor_profile);
+        }
+        break;
+    case TYPE_CONTAINER_SELINUX_LABEL:
+        if(!cinfo->m_selinux_label.empty())
+        {
+            req.set_value(cinfo->m_selinux_label);
+        }
+        break;
+    case TYPE_CONTAINER_NO_NEW_PRIVILEGES:
+        req.set_value(cinfo->m_no_new_privileges);
+        break;
+    case TYPE_CONTAINER_USER:
+        if(!cinfo->m_container_user.empty())
+        {
+            req.set_value(cinfo->m_container_user);
+        }
+        break;
+    case TYPE_CONTAINER_USER_IDS:
+        if(!cinfo->m_container_user_ids.empty())
+        {
+            req.set_value(cinfo->m_container_user_ids);
+        }
+        break;
+    case TYPE_CONTAINER_USERNS:
+        req.set_value(cinfo->m_userns);
+        break;
+    case TYPE_CONTAINER_UID_MAP:
+    case TYPE_CONTAINER_GID_MAP:
+    {
+        const auto &mappings = field_id == TYPE_CONTAINER_UID_MAP
+                                       ? cinfo->m_uid_mappings
+                                       : cinfo->m_gid_mappings;
+        if(!mappings.empty())
+        {
+            std::string tstr;
+            for(const auto &mapping : mappings)
+            {
+                if(!tstr.empty())
+                {
+                    tstr += ",";
+                }
+                tstr += mapping.to_string();
+            }
+            req.set_value(tstr);
+        }
+        break;
+    }
     case TYPE_CONTAINER_MOUNTS:
     {
         std::string tstr;
@@ -659,6 +1395,8 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
     case TYPE_CONTAINER_MOUNT_MODE:
     case TYPE_CONTAINER_MOUNT_RDWR:
     case TYPE_CONTAINER_MOUNT_PROPAGATION:
+    case TYPE_CONTAINER_MOUNT_TYPE:
+    case TYPE_CONTAINER_MOUNT_NAME:
     {
         const container_mount_info *mntinfo;
         auto arg_id = req.get_arg_index();
@@ -703,6 +1441,12 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
             case TYPE_CONTAINER_MOUNT_PROPAGATION:
                 tstr = mntinfo->m_propagation;
                 break;
+            case TYPE_CONTAINER_MOUNT_TYPE:
+                tstr = mntinfo->m_type;
+                break;
+            case TYPE_CONTAINER_MOUNT_NAME:
+                tstr = mntinfo->m_name;
+                break;
             }
             req.set_value(tstr);
         }
@@ -717,6 +1461,61 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
     case TYPE_CONTAINER_IMAGE_DIGEST:
         req.set_value(cinfo->m_imagedigest);
         break;
+    case TYPE_CONTAINER_IMAGE_REGISTRY:
+        req.set_value(cinfo->m_imageregistry);
+        break;
+    case TYPE_CONTAINER_IMAGE_SIZE:
+        if(cinfo->m_image_size > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_image_size);
+        }
+        break;
+    case TYPE_CONTAINER_IMAGE_LAYERS:
+        if(cinfo->m_image_layers > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_image_layers);
+        }
+        break;
+    case TYPE_CONTAINER_IMAGE_PULLED:
+        req.set_value(cinfo->m_image_pulled_at != 0);
+        break;
+    case TYPE_CONTAINER_IMAGE_PULLED_TS:
+        if(cinfo->m_image_pulled_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_image_pulled_at);
+        }
+        break;
+    case TYPE_CONTAINER_IMAGE_PULL_AGE:
+        if(cinfo->m_image_pulled_at != 0 && cinfo->m_created_at != 0)
+        {
+            req.set_value(
+                    (uint64_t)(cinfo->m_created_at - cinfo->m_image_pulled_at));
+        }
+        break;
+    case TYPE_CONTAINER_SIZE_RW:
+        if(cinfo->m_size_rw_bytes > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_size_rw_bytes);
+        }
+        break;
+    case TYPE_CONTAINER_SNAPSHOTTER:
+        if(!cinfo->m_snapshotter.empty())
+        {
+            req.set_value(cinfo->m_snapshotter);
+        }
+        break;
+    case TYPE_CONTAINER_LOG_PATH:
+        if(!cinfo->m_log_path.empty())
+        {
+            req.set_value(cinfo->m_log_path);
+        }
+        break;
+    case TYPE_CONTAINER_SOCKET:
+        if(!cinfo->m_engine_socket.empty())
+        {
+            req.set_value(cinfo->m_engine_socket);
+        }
+        break;
     case TYPE_CONTAINER_HEALTHCHECK:
     case TYPE_CONTAINER_LIVENESS_PROBE:
     case TYPE_CONTAINER_READINESS_PROBE:
@@ -751,12 +1550,127 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
         }
         break;
     }
+    case TYPE_CONTAINER_CREATED_TS:
+        if(cinfo->m_created_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_created_at);
+        }
+        break;
+    case TYPE_CONTAINER_STARTED_TS:
+        if(cinfo->m_started_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_started_at);
+        }
+        break;
     case TYPE_CONTAINER_IP_ADDR:
         req.set_value(cinfo->m_container_ip);
         break;
     case TYPE_CONTAINER_CNIRESULT:
         req.set_value(cinfo->m_pod_sandbox_cniresult);
         break;
+    case TYPE_CONTAINER_NETWORKS:
+    case TYPE_CONTAINER_IPS:
+    {
+        std::string tstr;
+        auto append = [&tstr](const std::string &value)
+        {
+            if(value.empty())
+            {
+                return;
+            }
+            if(!tstr.empty())
+            {
+                tstr += ",";
+            }
+            tstr += value;
+        };
+        for(const auto &netinfo : cinfo->m_networks)
+        {
+            if(field_id == TYPE_CONTAINER_NETWORKS)
+            {
+                append(netinfo.m_name);
+            }
+            else
+            {
+                append(netinfo.m_ip);
+                append(netinfo.m_ipv6);
+            }
+        }
+        if(!tstr.empty())
+        {
+            req.set_value(tstr);
+        }
+        break;
+    }
+    case TYPE_CONTAINER_NETWORK_IP:
+    case TYPE_CONTAINER_NETWORK_MAC:
+    case TYPE_CONTAINER_NETWORK_GATEWAY:
+    case TYPE_CONTAINER_NETWORK_ALIASES:
+    {
+        const container_network_info *netinfo;
+        auto arg_id = req.get_arg_index();
+        if(arg_id != -1)
+        {
+            netinfo = cinfo->network_by_idx(arg_id);
+        }
+        else
+        {
+            netinfo = cinfo->network_by_name(req.get_arg_key());
+        }
+        if(netinfo)
+        {
+            std::string tstr;
+            switch(field_id)
+            {
+            case TYPE_CONTAINER_NETWORK_IP:
+                tstr = netinfo->m_ip.empty() ? netinfo->m_ipv6 : netinfo->m_ip;
+                break;
+            case TYPE_CONTAINER_NETWORK_MAC:
+                tstr = netinfo->m_mac;
+                break;
+            case TYPE_CONTAINER_NETWORK_GATEWAY:
+                tstr = netinfo->m_gateway;
+                break;
+            case TYPE_CONTAINER_NETWORK_ALIASES:
+                for(const auto &alias : netinfo->m_aliases)
+                {
+                    if(!tstr.empty())
+                    {
+                        tstr += ",";
+                    }
+                    tstr += alias;
+                }
+                break;
+            }
+            req.set_value(tstr);
+        }
+        break;
+    }
+    case TYPE_CONTAINER_DNS_NAMES:
+    {
+        std::set<std::string> seen;
+        std::string tstr;
+        for(const auto &netinfo : cinfo->m_networks)
+        {
+            for(const auto &alias : netinfo.m_aliases)
+            {
+                if(alias.empty() || !seen.insert(alias).second)
+                {
+                    continue;
+                }
+                if(!tstr.empty())
+                {
+                    tstr += ",";
+                }
+                tstr += alias;
+            }
+        }
+        if(!tstr.empty())
+        {
+            req.set_value(tstr);
+        }
+        break;
+    }
     case TYPE_CONTAINER_HOST_PID:
         req.set_value(cinfo->m_host_pid);
         break;
@@ -766,6 +1680,37 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
     case TYPE_CONTAINER_HOST_IPC:
         req.set_value(cinfo->m_host_ipc);
         break;
+    case TYPE_CONTAINER_CPU_SHARES:
+        if(cinfo->m_cpu_shares > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_cpu_shares);
+        }
+        break;
+    case TYPE_CONTAINER_CPU_WEIGHT:
+        if(cinfo->m_cpu_shares > 0)
+        {
+            req.set_value(cpu_shares_to_weight(cinfo->m_cpu_shares));
+        }
+        break;
+    case TYPE_CONTAINER_CPU_QUOTA:
+        if(cinfo->m_cpu_quota > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_cpu_quota);
+        }
+        break;
+    case TYPE_CONTAINER_CPU_PERIOD:
+        if(cinfo->m_cpu_period > 0)
+        {
+            req.set_value((uint64_t)cinfo->m_cpu_period);
+        }
+        break;
+    case TYPE_CONTAINER_CPU_LIMIT:
+        if(cinfo->m_cpu_quota > 0 && cinfo->m_cpu_period > 0)
+        {
+            req.set_value((uint64_t)(cinfo->m_cpu_quota * 1000 /
+                                     cinfo->m_cpu_period));
+        }
+        break;
     case TYPE_CONTAINER_LABEL:
     {
         auto arg_key = req.get_arg_key();
@@ -782,21 +1727,197 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
         req.set_value(labels);
         break;
     }
+    case TYPE_CONTAINER_ANNOTATION:
+    case TYPE_K8S_POD_ANNOTATION:
+    {
+        const auto &annotations = field_id == TYPE_CONTAINER_ANNOTATION
+                                          ? cinfo->m_annotations
+                                          : cinfo->m_pod_annotations;
+        auto arg_key = req.get_arg_key();
+        if(annotations.count(arg_key) > 0)
+        {
+            req.set_value(annotations.at(arg_key));
+        }
+        break;
+    }
+    case TYPE_CONTAINER_ANNOTATIONS:
+    case TYPE_K8S_POD_ANNOTATIONS:
+    {
+        const auto &annotations = field_id == TYPE_CONTAINER_ANNOTATIONS
+                                          ? cinfo->m_annotations
+                                          : cinfo->m_pod_annotations;
+        if(!annotations.empty())
+        {
+            std::string tstr;
+            concatenate_container_labels(annotations, &tstr);
+            req.set_value(tstr);
+        }
+        break;
+    }
+    case TYPE_CONTAINER_EXIT_CODE:
+        // Plugin fields have no signed integer type: negative exit codes
+        // would wrap around, thus they are not reported.
+        if(cinfo->m_finished_at != 0 && cinfo->m_exit_code >= 0)
+        {
+            req.set_value((uint64_t)cinfo->m_exit_code);
+        }
+        break;
+    case TYPE_CONTAINER_FINISHED_TS:
+        if(cinfo->m_finished_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_finished_at);
+        }
+        break;
+    case TYPE_CONTAINER_OOM_KILLED:
+        if(cinfo->m_finished_at != 0)
+        {
+            req.set_value(cinfo->m_oom_killed);
+        }
+        break;
+    case TYPE_CONTAINER_RESTART_COUNT:
+        req.set_value((uint64_t)cinfo->m_restart_count);
+        break;
+    case TYPE_CONTAINER_PAUSED_TS:
+        if(cinfo->m_paused_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_paused_at);
+        }
+        break;
+    case TYPE_CONTAINER_PAUSE_DURATION:
+        if(cinfo->m_paused_at != 0 &&
+           evt_reader.get_ts() > (uint64_t)cinfo->m_paused_at)
+        {
+            req.set_value(evt_reader.get_ts() - (uint64_t)cinfo->m_paused_at);
+        }
+        break;
+    case TYPE_CONTAINER_HEALTH_STATUS:
+        if(!cinfo->m_health_status.empty())
+        {
+            req.set_value(cinfo->m_health_status);
+        }
+        break;
+    case TYPE_CONTAINER_HEALTH_OUTPUT:
+        if(!cinfo->m_health_output.empty())
+        {
+            req.set_value(cinfo->m_health_output);
+        }
+        break;
+    case TYPE_CONTAINER_HEALTHCHECK_CMD:
+    case TYPE_CONTAINER_HEALTHCHECK_INTERVAL:
+    case TYPE_CONTAINER_HEALTHCHECK_TIMEOUT:
+    case TYPE_CONTAINER_HEALTHCHECK_RETRIES:
+    {
+        auto probe = cinfo->probe_by_type(
+                container_health_probe::PT_HEALTHCHECK);
+        if(!probe)
+        {
+            break;
+        }
+        switch(field_id)
+        {
+        case TYPE_CONTAINER_HEALTHCHECK_CMD:
+        {
+            std::string tstr = probe->m_exe;
+            for(const auto &arg : probe->m_args)
+            {
+                tstr += " " + arg;
+            }
+            req.set_value(tstr);
+            break;
+        }
+        case TYPE_CONTAINER_HEALTHCHECK_INTERVAL:
+            if(probe->m_interval != 0)
+            {
+                req.set_value((uint64_t)probe->m_interval);
+            }
+            break;
+        case TYPE_CONTAINER_HEALTHCHECK_TIMEOUT:
+            if(probe->m_timeout != 0)
+            {
+                req.set_value((uint64_t)probe->m_timeout);
+            }
+            break;
+        case TYPE_CONTAINER_HEALTHCHECK_RETRIES:
+            if(probe->m_retries != 0)
+            {
+                req.set_value((uint64_t)probe->m_retries);
+            }
+            break;
+        }
+        break;
+    }
+    case TYPE_CONTAINER_STATS_CPU_PERCENT:
+        if(cinfo->m_stats.m_sampled_at != 0)
+        {
+            req.set_value(
+                    (uint64_t)std::llround(cinfo->m_stats.m_cpu_percent));
+        }
+        break;
+    case TYPE_CONTAINER_STATS_MEMORY_USAGE:
+        if(cinfo->m_stats.m_sampled_at != 0)
+        {
+            req.set_value(cinfo->m_stats.m_memory_usage);
+        }
+        break;
+    case TYPE_CONTAINER_STATS_MEMORY_LIMIT:
+        if(cinfo->m_stats.m_memory_limit != 0)
+        {
+            req.set_value(cinfo->m_stats.m_memory_limit);
+        }
+        break;
+    case TYPE_CONTAINER_STATS_PIDS:
+        if(cinfo->m_stats.m_sampled_at != 0)
+        {
+            req.set_value(cinfo->m_stats.m_pids);
+        }
+        break;
+    case TYPE_CONTAINER_STATS_SAMPLED_TS:
+        if(cinfo->m_stats.m_sampled_at != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_stats.m_sampled_at);
+        }
+        break;
+    case TYPE_CONTAINER_ENV:
+    {
+        std::string prefix = req.get_arg_key();
+        prefix += "=";
+        for(const auto& env : cinfo->m_env)
+        {
+            if(env.rfind(prefix, 0) == 0)
+            {
+                req.set_value(env.substr(prefix.size()));
+                break;
+            }
+        }
+        break;
+    }
     case TYPE_K8S_POD_NAME:
-        if(cinfo->m_labels.count("io.kubernetes.pod.name") > 0)
+        if(!cinfo->m_pod_name.empty())
+        {
+            req.set_value(cinfo->m_pod_name);
+        }
+        else if(cinfo->m_labels.count("io.kubernetes.pod.name") > 0)
         {
             req.set_value(cinfo->m_labels.at("io.kubernetes.pod.name"));
         }
         break;
     case TYPE_K8S_NS_NAME:
-        if(cinfo->m_labels.count("io.kubernetes.pod.namespace") > 0)
+        if(!cinfo->m_pod_namespace.empty())
+        {
+            req.set_value(cinfo->m_pod_namespace);
+        }
+        else if(cinfo->m_labels.count("io.kubernetes.pod.namespace") > 0)
         {
             req.set_value(cinfo->m_labels.at("io.kubernetes.pod.namespace"));
         }
         break;
     case TYPE_K8S_POD_ID:
     case TYPE_K8S_POD_UID:
-        if(cinfo->m_labels.count("io.kubernetes.pod.uid") > 0)
+        if(!cinfo->m_pod_uid.empty())
+        {
+            req.set_value(cinfo->m_pod_uid);
+        }
+        else if(cinfo->m_labels.count("io.kubernetes.pod.uid") > 0)
         {
             req.set_value(cinfo->m_labels.at("io.kubernetes.pod.uid"));
         }
@@ -890,6 +2011,117 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input &in)
             req.set_value(cinfo->m_pod_sandbox_cniresult);
         }
         break;
+    case TYPE_K8S_POD_NETNS:
+        if(cinfo->m_pod_sandbox_netns.empty())
+        {
+            auto sandbox_id = cinfo->m_pod_sandbox_id.substr(0, SHORT_ID_LEN);
+            if(m_containers.count(sandbox_id) > 0)
+            {
+                auto &sandbox_container_info = m_containers[sandbox_id];
+                req.set_value(sandbox_container_info->m_pod_sandbox_netns);
+            }
+        }
+        else
+        {
+            req.set_value(cinfo->m_pod_sandbox_netns);
+        }
+        break;
+    case TYPE_K8S_WORKLOAD_KIND:
+        if(!cinfo->m_k8s_workload_kind.empty())
+        {
+            req.set_value(cinfo->m_k8s_workload_kind);
+        }
+        break;
+    case TYPE_K8S_WORKLOAD_NAME:
+        if(!cinfo->m_k8s_workload_name.empty())
+        {
+            req.set_value(cinfo->m_k8s_workload_name);
+        }
+        break;
+    case TYPE_COMPOSE_PROJECT:
+        if(!cinfo->m_compose_project.empty())
+        {
+            req.set_value(cinfo->m_compose_project);
+        }
+        break;
+    case TYPE_COMPOSE_SERVICE:
+        if(!cinfo->m_compose_service.empty())
+        {
+            req.set_value(cinfo->m_compose_service);
+        }
+        break;
+    case TYPE_COMPOSE_CONTAINER_NUMBER:
+        if(cinfo->m_compose_container_number != 0)
+        {
+            req.set_value((uint64_t)cinfo->m_compose_container_number);
+        }
+        break;
+    case TYPE_ECS_TASK_ARN:
+        if(!cinfo->m_ecs_task_arn.empty())
+        {
+            req.set_value(cinfo->m_ecs_task_arn);
+        }
+        break;
+    case TYPE_ECS_CLUSTER:
+        if(!cinfo->m_ecs_cluster.empty())
+        {
+            req.set_value(cinfo->m_ecs_cluster);
+        }
+        break;
+    case TYPE_ECS_SERVICE_NAME:
+        if(!cinfo->m_ecs_service_name.empty())
+        {
+            req.set_value(cinfo->m_ecs_service_name);
+        }
+        break;
+    case TYPE_ECS_TASK_FAMILY:
+        if(!cinfo->m_ecs_task_family.empty())
+        {
+            req.set_value(cinfo->m_ecs_task_family);
+        }
+        break;
+    case TYPE_ECS_TASK_REVISION:
+        if(!cinfo->m_ecs_task_revision.empty())
+        {
+            req.set_value(cinfo->m_ecs_task_revision);
+        }
+        break;
+    case TYPE_NOMAD_ALLOC_ID:
+        if(!cinfo->m_nomad_alloc_id.empty())
+        {
+            req.set_value(cinfo->m_nomad_alloc_id);
+        }
+        break;
+    case TYPE_NOMAD_JOB_ID:
+        if(!cinfo->m_nomad_job_id.empty())
+        {
+            req.set_value(cinfo->m_nomad_job_id);
+        }
+        break;
+    case TYPE_NOMAD_TASK_GROUP:
+        if(!cinfo->m_nomad_task_group.empty())
+        {
+            req.set_value(cinfo->m_nomad_task_group);
+        }
+        break;
+    case TYPE_CF_APP_GUID:
+        if(!cinfo->m_cf_app_guid.empty())
+        {
+            req.set_value(cinfo->m_cf_app_guid);
+        }
+        break;
+    case TYPE_CF_SPACE_GUID:
+        if(!cinfo->m_cf_space_guid.empty())
+        {
+            req.set_value(cinfo->m_cf_space_guid);
+        }
+        break;
+    case TYPE_CF_ORG_GUID:
+        if(!cinfo->m_cf_org_guid.empty())
+        {
+            req.set_value(cinfo->m_cf_org_guid);
+        }
+        break;
     case TYPE_IS_CONTAINER_HEALTHCHECK:
     case TYPE_IS_CONTAINER_LIVENESS_PROBE:
     case TYPE_IS_CONTAINER_READINESS_PROBE:
@@ -60,7 +60,16 @@ bool my_plugin::parse_async_event(const falcosecurity::parse_event_input& in)
                  falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
     bool added = std::strcmp(name, ASYNC_EVENT_NAME_ADDED) == 0;
     bool removed = std::strcmp(name, ASYNC_EVENT_NAME_REMOVED) == 0;
-    if(!added && !removed)
+    bool updated = std::strcmp(name, ASYNC_EVENT_NAME_UPDATED) == 0;
+    bool died = std::strcmp(name, ASYNC_EVENT_NAME_DIED) == 0;
+    bool paused = std::strcmp(name, ASYNC_EVENT_NAME_PAUSED) == 0;
+    bool unpaused = std::strcmp(name, ASYNC_EVENT_NAME_UNPAUSED) == 0;
+    bool oom_killed = std::strcmp(name, ASYNC_EVENT_NAME_OOM_KILLED) == 0;
+    bool stats = std::strcmp(name, ASYNC_EVENT_NAME_STATS) == 0;
+    bool engine_status_changed =
+            std::strcmp(name, ASYNC_EVENT_NAME_ENGINE_STATUS) == 0;
+    if(!added && !removed && !updated && !died && !paused && !unpaused &&
+       !oom_killed && !stats && !engine_status_changed)
     {
         // We are not interested in parsing async events that are not
         // generated by our plugin.
@@ -79,12 +88,107 @@ bool my_plugin::parse_async_event(const falcosecurity::parse_event_input& in)
         return false;
     }
     auto json_event = nlohmann::json::parse(json_charbuf_pointer);
+    if(engine_status_changed)
+    {
+        // Engine statuses carry no container.
+        auto status = json_event.get<engine_status>();
+        m_logger.log(fmt::format("Engine {} on socket {} is {}{}",
+                                 status.m_engine, status.m_socket,
+                                 status.m_status,
+                                 status.m_error.empty()
+                                         ? ""
+                                         : ": " + status.m_error),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_DEBUG);
+        m_last_engine_status = {evt.get_num(), status};
+        return true;
+    }
     auto cinfo = json_event.get<container_info::ptr_t>();
     m_logger.log(fmt::format("Container info: type={}, id={}, name={}, "
-                             "image={}, added={}, removed={}",
+                             "image={}, added={}, removed={}, updated={}, "
+                             "died={}, paused={}, unpaused={}, "
+                             "oom_killed={}, stats={}",
                              to_string(cinfo->m_type), cinfo->m_id,
-                             cinfo->m_name, cinfo->m_image, added, removed),
+                             cinfo->m_name, cinfo->m_image, added, removed,
+                             updated, died, paused, unpaused, oom_killed,
+                             stats),
                  falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+    if(updated)
+    {
+        m_logger.log(fmt::format("Updating container: {}", cinfo->m_id),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+        // The container was added with a minimal set of infos:
+        // replace them with the full metadata.
+        m_containers[cinfo->m_id] = cinfo;
+        m_last_container = {evt.get_num(), cinfo};
+        m_asked_containers.erase(cinfo->m_id);
+        return true;
+    }
+    if(died)
+    {
+        m_logger.log(fmt::format("Container died: {}, exit_code={}",
+                                 cinfo->m_id, cinfo->m_exit_code),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+        // Keep the infos we already own, since the die event
+        // might only carry the minimal set of them.
+        if(auto it = m_containers.find(cinfo->m_id); it != m_containers.end())
+        {
+            auto dead = std::make_shared<container_info>(*it->second);
+            dead->m_exit_code = cinfo->m_exit_code;
+            dead->m_finished_at = cinfo->m_finished_at;
+            dead->m_oom_killed = cinfo->m_oom_killed;
+            cinfo = dead;
+            m_containers[cinfo->m_id] = cinfo;
+        }
+        // The container is not removed: it might be restarted.
+        m_last_container = {evt.get_num(), cinfo};
+        return true;
+    }
+    if(oom_killed)
+    {
+        m_logger.log(fmt::format("Container OOM killed: {}", cinfo->m_id),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+        // The container might still be running: its infos are left untouched,
+        // but the ones we already own are exposed to the event.
+        if(auto it = m_containers.find(cinfo->m_id); it != m_containers.end())
+        {
+            cinfo = it->second;
+        }
+        m_last_container = {evt.get_num(), cinfo};
+        return true;
+    }
+    if(stats)
+    {
+        m_logger.log(fmt::format("Container stats: {}", cinfo->m_id),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+        // Keep the last sample, to expose it to the container processes events.
+        if(auto it = m_containers.find(cinfo->m_id); it != m_containers.end())
+        {
+            auto ctr = std::make_shared<container_info>(*it->second);
+            ctr->m_stats = cinfo->m_stats;
+            cinfo = ctr;
+            m_containers[cinfo->m_id] = cinfo;
+        }
+        m_last_container = {evt.get_num(), cinfo};
+        return true;
+    }
+    if(paused || unpaused)
+    {
+        m_logger.log(fmt::format("Container {}: {}",
+                                 paused ? "paused" : "unpaused", cinfo->m_id),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_TRACE);
+        if(auto it = m_containers.find(cinfo->m_id); it != m_containers.end())
+        {
+            auto prev = it->second;
+            auto ctr = std::make_shared<container_info>(*prev);
+            ctr->m_paused_at = paused ? cinfo->m_paused_at : 0;
+            m_containers[cinfo->m_id] = ctr;
+            // Unpaused events still expose when the container got paused,
+            // to allow extracting the whole suspension duration.
+            cinfo = paused ? ctr : std::make_shared<container_info>(*prev);
+        }
+        m_last_container = {evt.get_num(), cinfo};
+        return true;
+    }
     if(added)
     {
         m_logger.log(fmt::format("Adding container: {}", cinfo->m_id),
@@ -97,6 +201,7 @@ bool my_plugin::parse_async_event(const falcosecurity::parse_event_input& in)
         // "container" added event.
         m_last_container = {evt.get_num(), cinfo};
         m_asked_containers.erase(cinfo->m_id);
+        m_failed_lookups.erase(cinfo->m_id);
     }
     else
     {
@@ -109,13 +214,14 @@ bool my_plugin::parse_async_event(const falcosecurity::parse_event_input& in)
     }
 
     // Update n_containers metric
-    m_metrics.at(0).set_value((uint64_t)m_containers.size() - 1);
+    m_metrics.at(METRIC_INDEX_N_CONTAINERS)
+            .set_value((uint64_t)m_containers.size() - 1);
 
     // Update n_missing metric
-    auto val = m_metrics.at(1).value.u64;
+    auto val = m_metrics.at(METRIC_INDEX_N_MISSING).value.u64;
     if(!cinfo->m_is_pod_sandbox && cinfo->m_image.empty())
     {
-        m_metrics.at(1).set_value(val + 1);
+        m_metrics.at(METRIC_INDEX_N_MISSING).set_value(val + 1);
     }
     return true;
 }
@@ -237,7 +343,7 @@ bool my_plugin::parse_exit_process_event(
                     auto info = it->second;
                     nlohmann::json j(info);
                     generate_async_event<ASYNC_HANDLER_DEFAULT>(
-                            j.dump().c_str(), false, false);
+                            j.dump().c_str(), ASYNC_EVENT_KIND_REMOVED, false);
                 }
 #endif
             }
@@ -37,4 +37,10 @@ constexpr auto PPME_SYSCALL_EXECVEAT_X = (_et)331;
 constexpr auto PPME_SYSCALL_CHROOT_X = (_et)267;
 constexpr auto PPME_PROCEXIT_1_E = (_et)186;
 
-#define SHORT_ID_LEN 12
\ No newline at end of file
+#define SHORT_ID_LEN 12
+
+// Time a failed synchronous lookup of a container is remembered for, not to
+// block the event processing again on each of its processes meanwhile.
+#define LOOKUP_FAILURE_TTL_S 30
+// Size of the failed lookups cache triggering the eviction of the expired ones.
+#define LOOKUP_FAILURES_PRUNE_SIZE 1024
\ No newline at end of file
@@ -65,6 +65,43 @@ container_info::mount_by_dest(const std::string &dest) const
     return NULL;
 }
 
+const container_network_info *
+container_info::network_by_idx(uint32_t idx) const
+{
+    if(idx >= m_networks.size())
+    {
+        return NULL;
+    }
+
+    return &(m_networks[idx]);
+}
+
+const container_network_info *
+container_info::network_by_name(const std::string &name) const
+{
+    for(auto &netinfo : m_networks)
+    {
+        if(netinfo.m_name == name)
+        {
+            return &netinfo;
+        }
+    }
+    return NULL;
+}
+
+const container_health_probe *
+container_info::probe_by_type(container_health_probe::probe_type ptype) const
+{
+    for(auto &probe : m_health_probes)
+    {
+        if(probe.m_type == ptype)
+        {
+            return &probe;
+        }
+    }
+    return NULL;
+}
+
 container_health_probe::probe_type
 container_info::match_health_probe(const std::string &exe,
                                    const std::vector<std::string> &args) const
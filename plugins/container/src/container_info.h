@@ -14,12 +14,20 @@
 class container_port_mapping
 {
     public:
-    container_port_mapping(): m_host_ip(0), m_host_port(0), m_container_port(0)
+    container_port_mapping():
+            m_host_ip(0), m_host_port(0), m_host_port_end(0),
+            m_container_port(0)
     {
     }
     uint32_t m_host_ip;
+    // IPv6 host address, for IPv6 bindings (m_host_ip is then 0).
+    std::string m_host_ipv6;
     uint16_t m_host_port;
+    // Last host port of published ranges, 0 for single ports.
+    uint16_t m_host_port_end;
     uint16_t m_container_port;
+    // tcp, udp or sctp.
+    std::string m_protocol;
 };
 
 class container_mount_info
@@ -27,7 +35,7 @@ class container_mount_info
     public:
     container_mount_info():
             m_source(""), m_dest(""), m_mode(""), m_rdwr(false),
-            m_propagation("")
+            m_propagation(""), m_type(""), m_name("")
     {
     }
 
@@ -50,6 +58,42 @@ class container_mount_info
     std::string m_mode;
     bool m_rdwr;
     std::string m_propagation;
+    // Mount type (e.g. bind, volume, tmpfs) and volume name, when reported by
+    // the container engine.
+    std::string m_type;
+    std::string m_name;
+};
+
+// A range of user or group ids of the container user namespace, mapped to the
+// host ones.
+class container_id_mapping
+{
+    public:
+    container_id_mapping(): m_container_id(0), m_host_id(0), m_size(0) {}
+
+    std::string to_string() const
+    {
+        return std::to_string(m_container_id) + ":" +
+               std::to_string(m_host_id) + ":" + std::to_string(m_size);
+    }
+
+    uint32_t m_container_id;
+    uint32_t m_host_id;
+    uint32_t m_size;
+};
+
+// A network the container is attached to, e.g. a docker network or a CNI
+// attachment, named after the container interface for the latter.
+class container_network_info
+{
+    public:
+    std::string m_name;
+    std::string m_ip;
+    std::string m_ipv6;
+    std::string m_mac;
+    std::string m_gateway;
+    // DNS names of the container on the network, e.g. its compose service.
+    std::vector<std::string> m_aliases;
 };
 
 class container_health_probe
@@ -85,6 +129,43 @@ class container_health_probe
     // The actual health probe exe and args.
     std::string m_exe;
     std::vector<std::string> m_args;
+
+    // How the probe is run by the engine: interval between checks, check
+    // timeout and initial grace period (IN NANOSECONDS), and consecutive
+    // failures needed to consider the container unhealthy; 0 if unset.
+    int64_t m_interval{0};
+    int64_t m_timeout{0};
+    int64_t m_start_period{0};
+    int64_t m_retries{0};
+};
+
+// A sample of the resource usage of a running container, periodically polled
+// from its engine.
+class container_stats
+{
+    public:
+    // CPU usage since the previous sample, in percentage of a single CPU.
+    double m_cpu_percent{0};
+    // Memory usage, excluding the inactive page cache, and limit, in bytes.
+    uint64_t m_memory_usage{0};
+    uint64_t m_memory_limit{0};
+    uint64_t m_pids{0};
+    // The time at which the sample got taken (IN NANOSECONDS); 0 for
+    // containers that did not get sampled yet.
+    int64_t m_sampled_at{0};
+};
+
+// The connection status of an engine to its socket, notified by the go-worker
+// when the connection gets established, lost or recovered.
+class engine_status
+{
+    public:
+    std::string m_engine;
+    std::string m_socket;
+    // Either "up" or "down".
+    std::string m_status;
+    // The cause of the connection loss, if any.
+    std::string m_error;
 };
 
 class container_info
@@ -93,11 +174,17 @@ class container_info
     using ptr_t = std::shared_ptr<container_info>;
 
     container_info():
-            m_type(CT_UNKNOWN), m_privileged(false), m_host_pid(false),
-            m_host_network(false), m_host_ipc(false), m_memory_limit(0),
-            m_swap_limit(0), m_cpu_shares(1024), m_cpu_quota(0),
+            m_type(CT_UNKNOWN), m_sandboxed_runtime(false), m_rootless_uid(0),
+            m_privileged(false),
+            m_no_new_privileges(false),
+            m_userns(false), m_host_pid(false), m_host_network(false),
+            m_host_ipc(false), m_memory_limit(0), m_swap_limit(0),
+            m_pids_limit(0), m_cpu_shares(1024), m_cpu_quota(0),
             m_cpu_period(100000), m_cpuset_cpu_count(0),
-            m_is_pod_sandbox(false), m_size_rw_bytes(-1)
+            m_compose_container_number(0), m_is_pod_sandbox(false), m_created_at(0), m_started_at(0),
+            m_size_rw_bytes(-1), m_image_size(0), m_image_layers(0),
+            m_image_pulled_at(0), m_exit_code(0), m_finished_at(0),
+            m_oom_killed(false), m_restart_count(0), m_paused_at(0)
     {
     }
 
@@ -107,6 +194,9 @@ class container_info
     const container_mount_info* mount_by_source(const std::string&) const;
     const container_mount_info* mount_by_dest(const std::string&) const;
 
+    const container_network_info* network_by_idx(uint32_t idx) const;
+    const container_network_info* network_by_name(const std::string&) const;
+
     bool is_pod_sandbox() const { return m_is_pod_sandbox; }
 
     // static utilities to build a container_info
@@ -120,6 +210,9 @@ class container_info
         return host_info;
     }
 
+    const container_health_probe*
+    probe_by_type(container_health_probe::probe_type ptype) const;
+
     // Match a process against the set of health probes
     container_health_probe::probe_type
     match_health_probe(const std::string& exe,
@@ -127,24 +220,69 @@ class container_info
 
     std::string m_id;
     std::string m_full_id;
+    // Runtime-native ID (e.g. containerd://<full id>), CRI only.
+    std::string m_runtime_id;
     container_type m_type;
+    // OCI runtime (e.g. runc, io.containerd.kata.v2, runsc) and whether it
+    // runs the container in a sandbox, i.e. Kata Containers or gVisor.
+    std::string m_runtime;
+    bool m_sandboxed_runtime;
+    // Isolation mode of windows containers, i.e. process or hyperv.
+    std::string m_isolation;
+    // Cgroup path, relative to the cgroup root, and cgroup driver, i.e.
+    // systemd or cgroupfs.
+    std::string m_cgroup_path;
+    std::string m_cgroup_driver;
+    // Uid owning the rootless docker daemon managing the container, 0 for
+    // system daemons.
+    uint32_t m_rootless_uid;
+    // Digest of the configuration of the container, when enabled.
+    std::string m_config_digest;
     std::string m_name;
     std::string m_image;
     std::string m_imageid;
     std::string m_imagerepo;
     std::string m_imagetag;
     std::string m_imagedigest;
+    std::string m_imageregistry;
     std::string m_container_ip;
     bool m_privileged;
+    // Capabilities added to and dropped from the engine defaults, and the
+    // effective ones from the OCI spec (where available), e.g. CAP_SYS_ADMIN.
+    std::vector<std::string> m_cap_add;
+    std::vector<std::string> m_cap_drop;
+    std::vector<std::string> m_cap_effective;
+    // GPU devices requested by or assigned to the container, e.g. "0",
+    // "nvidia.com/gpu=all" or "/dev/nvidia0".
+    std::vector<std::string> m_gpu_devices;
+    // Security options: seccomp profile (e.g. unconfined, runtime/default),
+    // AppArmor profile and SELinux label.
+    std::string m_seccomp_profile;
+    std::string m_apparmor_profile;
+    std::string m_selinux_label;
+    bool m_no_new_privileges;
+    // Whether the container runs in its own user namespace (e.g. with
+    // userns-remap), and its uid and gid mappings when reported.
+    bool m_userns;
+    std::vector<container_id_mapping> m_uid_mappings;
+    std::vector<container_id_mapping> m_gid_mappings;
     bool m_host_pid;
     bool m_host_network;
     bool m_host_ipc;
     std::vector<container_mount_info> m_mounts;
     std::vector<container_port_mapping> m_port_mappings;
+    // All the networks the container is attached to, sorted by name.
+    std::vector<container_network_info> m_networks;
     std::map<std::string, std::string> m_labels;
+    // CRI container and pod annotations matching the configured include
+    // patterns (and the io.kubernetes.* container ones, for CRI-O).
+    std::map<std::string, std::string> m_annotations;
+    std::map<std::string, std::string> m_pod_annotations;
     std::vector<std::string> m_env;
     int64_t m_memory_limit;
     int64_t m_swap_limit;
+    // Maximum number of processes, 0 when unlimited.
+    int64_t m_pids_limit;
     int64_t m_cpu_shares;
     int64_t m_cpu_quota;
     int64_t m_cpu_period;
@@ -153,8 +291,44 @@ class container_info
     std::string m_pod_sandbox_id;
     std::map<std::string, std::string> m_pod_sandbox_labels;
     std::string m_pod_sandbox_cniresult;
+    // Path of the pod sandbox network namespace, shared by its containers.
+    std::string m_pod_sandbox_netns;
+    // Kubernetes pod metadata, from the io.kubernetes.* labels of the
+    // container (or of its sandbox); empty for non-kubernetes containers.
+    std::string m_pod_name;
+    std::string m_pod_namespace;
+    std::string m_pod_uid;
+    std::string m_k8s_container_name;
+    // Kind and name of the workload owning the pod, e.g. Deployment, inferred
+    // from the pod labels and name.
+    std::string m_k8s_workload_kind;
+    std::string m_k8s_workload_name;
+    // Compose project, service and replica number, from the
+    // com.docker.compose.* labels of the container (or the podman-compose
+    // ones); the number is 0 for non-compose containers.
+    std::string m_compose_project;
+    std::string m_compose_service;
+    int64_t m_compose_container_number;
+    // ECS task metadata, from the com.amazonaws.ecs.* labels of the container
+    // or from the task metadata endpoint; empty for non-ECS containers.
+    std::string m_ecs_task_arn;
+    std::string m_ecs_cluster;
+    std::string m_ecs_service_name;
+    std::string m_ecs_task_family;
+    std::string m_ecs_task_revision;
+    // Nomad allocation metadata, from the environment of the task container
+    // or from the nomad client API; empty for non-nomad containers.
+    std::string m_nomad_alloc_id;
+    std::string m_nomad_job_id;
+    std::string m_nomad_task_group;
+    // Cloud Foundry GUIDs of the app instances containers of Diego cells.
+    std::string m_cf_app_guid;
+    std::string m_cf_space_guid;
+    std::string m_cf_org_guid;
     bool m_is_pod_sandbox;
     std::string m_container_user;
+    // Numeric "uid:gid" of the container processes (containerd and CRI only).
+    std::string m_container_user_ids;
 
     /**
      * The time at which the container was created (IN SECONDS), cast from a
@@ -163,5 +337,51 @@ class container_info
      * default to int64_t anyway (e.g. CRI).
      */
     int64_t m_created_time;
-    int64_t m_size_rw_bytes; // TODO: to be exposed by state API
+    /**
+     * The time at which the container was created and the time at which it
+     * was last started (IN NANOSECONDS), as reported by the container engine;
+     * 0 when not available.
+     */
+    int64_t m_created_at;
+    int64_t m_started_at;
+    // Size in bytes of the writable layer, -1 when unknown.
+    int64_t m_size_rw_bytes;
+    // Snapshotter backing the container rootfs, containerd only.
+    std::string m_snapshotter;
+    // Path of the container log file on the host, cri only.
+    std::string m_log_path;
+    // Socket of the container engine reporting the container, docker only.
+    std::string m_engine_socket;
+    // Image size in bytes and number of layers, 0 when not reported by the
+    // container engine.
+    int64_t m_image_size;
+    int64_t m_image_layers;
+    /**
+     * The time at which the container image got pulled (IN NANOSECONDS), when
+     * pulled shortly before the container creation; 0 otherwise.
+     */
+    int64_t m_image_pulled_at;
+
+    /**
+     * Exit code and time at which the container terminated (IN NANOSECONDS),
+     * and whether it got killed by the OOM killer; only set once a container
+     * died, ie: m_finished_at is 0 for running containers, unless they got
+     * restarted, in which case they refer to their last run.
+     */
+    int64_t m_exit_code;
+    int64_t m_finished_at;
+    bool m_oom_killed;
+    // Number of times the container got restarted by its engine.
+    int64_t m_restart_count;
+    /**
+     * The time at which the container got paused (IN NANOSECONDS); 0 for
+     * containers that are not paused.
+     */
+    int64_t m_paused_at;
+    // Health status (healthy/unhealthy/starting) of containers with a
+    // healthcheck, and output of the last check, if failing.
+    std::string m_health_status;
+    std::string m_health_output;
+    // Last sample of the resource usage of the container.
+    container_stats m_stats;
 };
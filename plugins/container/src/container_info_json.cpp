@@ -69,6 +69,10 @@ void from_json(const nlohmann::json& j, container_health_probe& probe)
 {
     object_from_json(j, "args", probe.m_args);
     probe.m_exe = j.value("exe", "");
+    probe.m_interval = j.value("interval", int64_t{0});
+    probe.m_timeout = j.value("timeout", int64_t{0});
+    probe.m_start_period = j.value("start_period", int64_t{0});
+    probe.m_retries = j.value("retries", int64_t{0});
 }
 
 void from_json(const nlohmann::json& j, container_mount_info& mount)
@@ -78,13 +82,53 @@ void from_json(const nlohmann::json& j, container_mount_info& mount)
     mount.m_mode = j.value("Mode", "");
     mount.m_rdwr = j.value("RW", false);
     mount.m_propagation = j.value("Propagation", "");
+    mount.m_type = j.value("Type", "");
+    mount.m_name = j.value("Name", "");
 }
 
 void from_json(const nlohmann::json& j, container_port_mapping& port)
 {
     port.m_host_ip = j.value("HostIp", 0);
+    port.m_host_ipv6 = j.value("HostIpv6", "");
     port.m_host_port = j.value("HostPort", 0);
+    port.m_host_port_end = j.value("HostPortEnd", 0);
     port.m_container_port = j.value("ContainerPort", 0);
+    // Only tcp ports used to be reported
+    port.m_protocol = j.value("Protocol", "tcp");
+}
+
+void from_json(const nlohmann::json& j, container_id_mapping& mapping)
+{
+    mapping.m_container_id = j.value("containerID", uint32_t{0});
+    mapping.m_host_id = j.value("hostID", uint32_t{0});
+    mapping.m_size = j.value("size", uint32_t{0});
+}
+
+void from_json(const nlohmann::json& j, container_network_info& network)
+{
+    network.m_name = j.value("name", "");
+    network.m_ip = j.value("ip", "");
+    network.m_ipv6 = j.value("ipv6", "");
+    network.m_mac = j.value("mac", "");
+    network.m_gateway = j.value("gateway", "");
+    object_from_json(j, "aliases", network.m_aliases);
+}
+
+void from_json(const nlohmann::json& j, container_stats& stats)
+{
+    stats.m_cpu_percent = j.value("cpu_percent", 0.0);
+    stats.m_memory_usage = j.value("memory_usage", uint64_t{0});
+    stats.m_memory_limit = j.value("memory_limit", uint64_t{0});
+    stats.m_pids = j.value("pids", uint64_t{0});
+    stats.m_sampled_at = j.value("sampled_at", int64_t{0});
+}
+
+void from_json(const nlohmann::json& j, engine_status& status)
+{
+    status.m_engine = j.value("engine", "");
+    status.m_socket = j.value("socket", "");
+    status.m_status = j.value("status", "");
+    status.m_error = j.value("error", "");
 }
 
 void from_json(const nlohmann::json& j, container_info::ptr_t& cinfo)
@@ -99,16 +143,27 @@ void from_json(const nlohmann::json& j, container_info::ptr_t& cinfo)
     info->m_imageid = container.value("imageid", "");
     info->m_imagerepo = container.value("imagerepo", "");
     info->m_imagetag = container.value("imagetag", "");
+    info->m_imageregistry = container.value("imageregistry", "");
+    info->m_image_size = container.value("image_size", int64_t{0});
+    info->m_image_layers = container.value("image_layers", int64_t{0});
+    info->m_image_pulled_at = container.value("image_pulled_at", int64_t{0});
     info->m_container_user = container.value("User", "");
+    info->m_container_user_ids = container.value("user_ids", "");
     info->m_pod_sandbox_cniresult = container.value("cni_json", "");
     info->m_cpu_period = container.value("cpu_period", int64_t{0});
     info->m_cpu_quota = container.value("cpu_quota", int64_t{0});
     info->m_cpu_shares = container.value("cpu_shares", int64_t{0});
     info->m_cpuset_cpu_count = container.value("cpuset_cpu_count", int64_t{0});
     info->m_created_time = container.value("created_time", int64_t{0});
+    info->m_created_at = container.value("created_at", int64_t{0});
+    info->m_started_at = container.value("started_at", int64_t{0});
     info->m_size_rw_bytes = container.value("size", int64_t{-1});
+    info->m_snapshotter = container.value("snapshotter", "");
+    info->m_log_path = container.value("log_path", "");
+    info->m_engine_socket = container.value("engine_socket", "");
     object_from_json(container, "env", info->m_env);
     info->m_full_id = container.value("full_id", "");
+    info->m_runtime_id = container.value("runtime_id", "");
     info->m_host_ipc = container.value("host_ipc", false);
     info->m_host_network = container.value("host_network", false);
     info->m_host_pid = container.value("host_pid", false);
@@ -117,12 +172,64 @@ void from_json(const nlohmann::json& j, container_info::ptr_t& cinfo)
     object_from_json(container, "labels", info->m_labels);
     info->m_memory_limit = container.value("memory_limit", int64_t{0});
     info->m_swap_limit = container.value("swap_limit", int64_t{0});
+    info->m_pids_limit = container.value("pids_limit", int64_t{0});
     info->m_pod_sandbox_id = container.value("pod_sandbox_id", "");
+    info->m_pod_sandbox_netns = container.value("pod_sandbox_netns", "");
+    info->m_pod_name = container.value("pod_name", "");
+    info->m_pod_namespace = container.value("pod_namespace", "");
+    info->m_pod_uid = container.value("pod_uid", "");
+    info->m_k8s_container_name = container.value("k8s_container_name", "");
+    info->m_k8s_workload_kind = container.value("k8s_workload_kind", "");
+    info->m_k8s_workload_name = container.value("k8s_workload_name", "");
+    info->m_compose_project = container.value("compose_project", "");
+    info->m_compose_service = container.value("compose_service", "");
+    info->m_compose_container_number =
+            container.value("compose_container_number", int64_t{0});
+    info->m_ecs_task_arn = container.value("ecs_task_arn", "");
+    info->m_ecs_cluster = container.value("ecs_cluster", "");
+    info->m_ecs_service_name = container.value("ecs_service_name", "");
+    info->m_ecs_task_family = container.value("ecs_task_family", "");
+    info->m_ecs_task_revision = container.value("ecs_task_revision", "");
+    info->m_nomad_alloc_id = container.value("nomad_alloc_id", "");
+    info->m_nomad_job_id = container.value("nomad_job_id", "");
+    info->m_nomad_task_group = container.value("nomad_task_group", "");
+    info->m_cf_app_guid = container.value("cf_app_guid", "");
+    info->m_cf_space_guid = container.value("cf_space_guid", "");
+    info->m_cf_org_guid = container.value("cf_org_guid", "");
     info->m_privileged = container.value("privileged", false);
+    info->m_runtime = container.value("runtime", "");
+    info->m_sandboxed_runtime = container.value("sandboxed_runtime", false);
+    info->m_isolation = container.value("isolation", "");
+    info->m_cgroup_path = container.value("cgroup_path", "");
+    info->m_cgroup_driver = container.value("cgroup_driver", "");
+    info->m_rootless_uid = container.value("rootless_uid", uint32_t{0});
+    info->m_config_digest = container.value("config_digest", "");
+    object_from_json(container, "cap_add", info->m_cap_add);
+    object_from_json(container, "cap_drop", info->m_cap_drop);
+    object_from_json(container, "cap_effective", info->m_cap_effective);
+    object_from_json(container, "gpu_devices", info->m_gpu_devices);
+    info->m_seccomp_profile = container.value("seccomp_profile", "");
+    info->m_apparmor_profile = container.value("apparmor_profile", "");
+    info->m_selinux_label = container.value("selinux_label", "");
+    info->m_no_new_privileges = container.value("no_new_privileges", false);
+    info->m_userns = container.value("userns", false);
+    object_from_json(container, "uid_mappings", info->m_uid_mappings);
+    object_from_json(container, "gid_mappings", info->m_gid_mappings);
     object_from_json(container, "pod_sandbox_labels",
                      info->m_pod_sandbox_labels);
+    object_from_json(container, "annotations", info->m_annotations);
+    object_from_json(container, "pod_annotations", info->m_pod_annotations);
     object_from_json(container, "port_mappings", info->m_port_mappings);
+    object_from_json(container, "networks", info->m_networks);
     object_from_json(container, "Mounts", info->m_mounts);
+    info->m_exit_code = container.value("exit_code", int64_t{0});
+    info->m_finished_at = container.value("finished_at", int64_t{0});
+    info->m_oom_killed = container.value("oom_killed", false);
+    info->m_restart_count = container.value("restart_count", int64_t{0});
+    info->m_paused_at = container.value("paused_at", int64_t{0});
+    info->m_health_status = container.value("health_status", "");
+    info->m_health_output = container.value("health_output", "");
+    object_from_json(container, "stats", info->m_stats);
 
     for(int probe_type = container_health_probe::PT_HEALTHCHECK;
         probe_type <= container_health_probe::PT_READINESS_PROBE; probe_type++)
@@ -145,6 +252,22 @@ void to_json(nlohmann::json& j, const container_health_probe& probe)
 {
     j["args"] = probe.m_args;
     j["exe"] = probe.m_exe;
+    if(probe.m_interval != 0)
+    {
+        j["interval"] = probe.m_interval;
+    }
+    if(probe.m_timeout != 0)
+    {
+        j["timeout"] = probe.m_timeout;
+    }
+    if(probe.m_start_period != 0)
+    {
+        j["start_period"] = probe.m_start_period;
+    }
+    if(probe.m_retries != 0)
+    {
+        j["retries"] = probe.m_retries;
+    }
 }
 
 void to_json(nlohmann::json& j, const container_mount_info& mount)
@@ -154,13 +277,74 @@ void to_json(nlohmann::json& j, const container_mount_info& mount)
     j["Mode"] = mount.m_mode;
     j["RW"] = mount.m_rdwr;
     j["Propagation"] = mount.m_propagation;
+    if(!mount.m_type.empty())
+    {
+        j["Type"] = mount.m_type;
+    }
+    if(!mount.m_name.empty())
+    {
+        j["Name"] = mount.m_name;
+    }
 }
 
 void to_json(nlohmann::json& j, const container_port_mapping& port)
 {
     j["HostIp"] = port.m_host_ip;
+    if(!port.m_host_ipv6.empty())
+    {
+        j["HostIpv6"] = port.m_host_ipv6;
+    }
     j["HostPort"] = port.m_host_port;
+    if(port.m_host_port_end != 0)
+    {
+        j["HostPortEnd"] = port.m_host_port_end;
+    }
     j["ContainerPort"] = port.m_container_port;
+    j["Protocol"] = port.m_protocol;
+}
+
+void to_json(nlohmann::json& j, const container_id_mapping& mapping)
+{
+    j["containerID"] = mapping.m_container_id;
+    j["hostID"] = mapping.m_host_id;
+    j["size"] = mapping.m_size;
+}
+
+void to_json(nlohmann::json& j, const container_network_info& network)
+{
+    j["name"] = network.m_name;
+    if(!network.m_ip.empty())
+    {
+        j["ip"] = network.m_ip;
+    }
+    if(!network.m_ipv6.empty())
+    {
+        j["ipv6"] = network.m_ipv6;
+    }
+    if(!network.m_mac.empty())
+    {
+        j["mac"] = network.m_mac;
+    }
+    if(!network.m_gateway.empty())
+    {
+        j["gateway"] = network.m_gateway;
+    }
+    if(!network.m_aliases.empty())
+    {
+        j["aliases"] = network.m_aliases;
+    }
+}
+
+void to_json(nlohmann::json& j, const container_stats& stats)
+{
+    j["cpu_percent"] = stats.m_cpu_percent;
+    j["memory_usage"] = stats.m_memory_usage;
+    if(stats.m_memory_limit != 0)
+    {
+        j["memory_limit"] = stats.m_memory_limit;
+    }
+    j["pids"] = stats.m_pids;
+    j["sampled_at"] = stats.m_sampled_at;
 }
 
 void to_json(nlohmann::json& j,
@@ -175,18 +359,59 @@ void to_json(nlohmann::json& j,
     container["imageid"] = cinfo->m_imageid;
     container["imagerepo"] = cinfo->m_imagerepo;
     container["imagetag"] = cinfo->m_imagetag;
+    container["imageregistry"] = cinfo->m_imageregistry;
+    if(cinfo->m_image_size != 0)
+    {
+        container["image_size"] = cinfo->m_image_size;
+    }
+    if(cinfo->m_image_layers != 0)
+    {
+        container["image_layers"] = cinfo->m_image_layers;
+    }
+    if(cinfo->m_image_pulled_at != 0)
+    {
+        container["image_pulled_at"] = cinfo->m_image_pulled_at;
+    }
     container["User"] = cinfo->m_container_user;
+    if(!cinfo->m_container_user_ids.empty())
+    {
+        container["user_ids"] = cinfo->m_container_user_ids;
+    }
     container["cni_json"] = cinfo->m_pod_sandbox_cniresult;
     container["cpu_period"] = cinfo->m_cpu_period;
     container["cpu_quota"] = cinfo->m_cpu_quota;
     container["cpu_shares"] = cinfo->m_cpu_shares;
     container["cpuset_cpu_count"] = cinfo->m_cpuset_cpu_count;
     container["created_time"] = cinfo->m_created_time;
+    if(cinfo->m_created_at != 0)
+    {
+        container["created_at"] = cinfo->m_created_at;
+    }
+    if(cinfo->m_started_at != 0)
+    {
+        container["started_at"] = cinfo->m_started_at;
+    }
     container["size"] = cinfo->m_size_rw_bytes;
+    if(!cinfo->m_snapshotter.empty())
+    {
+        container["snapshotter"] = cinfo->m_snapshotter;
+    }
+    if(!cinfo->m_log_path.empty())
+    {
+        container["log_path"] = cinfo->m_log_path;
+    }
+    if(!cinfo->m_engine_socket.empty())
+    {
+        container["engine_socket"] = cinfo->m_engine_socket;
+    }
     // TODO: only append a limited set of env?
     // https://github.com/falcosecurity/libs/blob/master/userspace/libsinsp/container.cpp#L232
     container["env"] = cinfo->m_env;
     container["full_id"] = cinfo->m_full_id;
+    if(!cinfo->m_runtime_id.empty())
+    {
+        container["runtime_id"] = cinfo->m_runtime_id;
+    }
     container["host_ipc"] = cinfo->m_host_ipc;
     container["host_network"] = cinfo->m_host_network;
     container["host_pid"] = cinfo->m_host_pid;
@@ -195,11 +420,210 @@ void to_json(nlohmann::json& j,
     container["labels"] = cinfo->m_labels;
     container["memory_limit"] = cinfo->m_memory_limit;
     container["swap_limit"] = cinfo->m_swap_limit;
+    if(cinfo->m_pids_limit != 0)
+    {
+        container["pids_limit"] = cinfo->m_pids_limit;
+    }
     container["pod_sandbox_id"] = cinfo->m_pod_sandbox_id;
+    if(!cinfo->m_pod_sandbox_netns.empty())
+    {
+        container["pod_sandbox_netns"] = cinfo->m_pod_sandbox_netns;
+    }
+    if(!cinfo->m_pod_name.empty())
+    {
+        container["pod_name"] = cinfo->m_pod_name;
+    }
+    if(!cinfo->m_pod_namespace.empty())
+    {
+        container["pod_namespace"] = cinfo->m_pod_namespace;
+    }
+    if(!cinfo->m_pod_uid.empty())
+    {
+        container["pod_uid"] = cinfo->m_pod_uid;
+    }
+    if(!cinfo->m_k8s_container_name.empty())
+    {
+        container["k8s_container_name"] = cinfo->m_k8s_container_name;
+    }
+    if(!cinfo->m_k8s_workload_kind.empty())
+    {
+        container["k8s_workload_kind"] = cinfo->m_k8s_workload_kind;
+    }
+    if(!cinfo->m_k8s_workload_name.empty())
+    {
+        container["k8s_workload_name"] = cinfo->m_k8s_workload_name;
+    }
+    if(!cinfo->m_compose_project.empty())
+    {
+        container["compose_project"] = cinfo->m_compose_project;
+    }
+    if(!cinfo->m_compose_service.empty())
+    {
+        container["compose_service"] = cinfo->m_compose_service;
+    }
+    if(cinfo->m_compose_container_number != 0)
+    {
+        container["compose_container_number"] =
+                cinfo->m_compose_container_number;
+    }
+    if(!cinfo->m_ecs_task_arn.empty())
+    {
+        container["ecs_task_arn"] = cinfo->m_ecs_task_arn;
+    }
+    if(!cinfo->m_ecs_cluster.empty())
+    {
+        container["ecs_cluster"] = cinfo->m_ecs_cluster;
+    }
+    if(!cinfo->m_ecs_service_name.empty())
+    {
+        container["ecs_service_name"] = cinfo->m_ecs_service_name;
+    }
+    if(!cinfo->m_ecs_task_family.empty())
+    {
+        container["ecs_task_family"] = cinfo->m_ecs_task_family;
+    }
+    if(!cinfo->m_ecs_task_revision.empty())
+    {
+        container["ecs_task_revision"] = cinfo->m_ecs_task_revision;
+    }
+    if(!cinfo->m_nomad_alloc_id.empty())
+    {
+        container["nomad_alloc_id"] = cinfo->m_nomad_alloc_id;
+    }
+    if(!cinfo->m_nomad_job_id.empty())
+    {
+        container["nomad_job_id"] = cinfo->m_nomad_job_id;
+    }
+    if(!cinfo->m_nomad_task_group.empty())
+    {
+        container["nomad_task_group"] = cinfo->m_nomad_task_group;
+    }
+    if(!cinfo->m_cf_app_guid.empty())
+    {
+        container["cf_app_guid"] = cinfo->m_cf_app_guid;
+    }
+    if(!cinfo->m_cf_space_guid.empty())
+    {
+        container["cf_space_guid"] = cinfo->m_cf_space_guid;
+    }
+    if(!cinfo->m_cf_org_guid.empty())
+    {
+        container["cf_org_guid"] = cinfo->m_cf_org_guid;
+    }
     container["privileged"] = cinfo->m_privileged;
+    if(!cinfo->m_cap_add.empty())
+    {
+        container["cap_add"] = cinfo->m_cap_add;
+    }
+    if(!cinfo->m_cap_drop.empty())
+    {
+        container["cap_drop"] = cinfo->m_cap_drop;
+    }
+    if(!cinfo->m_cap_effective.empty())
+    {
+        container["cap_effective"] = cinfo->m_cap_effective;
+    }
+    if(!cinfo->m_gpu_devices.empty())
+    {
+        container["gpu_devices"] = cinfo->m_gpu_devices;
+    }
+    if(!cinfo->m_seccomp_profile.empty())
+    {
+        container["seccomp_profile"] = cinfo->m_seccomp_profile;
+    }
+    if(!cinfo->m_apparmor_profile.empty())
+    {
+        container["apparmor_profile"] = cinfo->m_apparmor_profile;
+    }
+    if(!cinfo->m_selinux_label.empty())
+    {
+        container["selinux_label"] = cinfo->m_selinux_label;
+    }
+    if(cinfo->m_no_new_privileges)
+    {
+        container["no_new_privileges"] = cinfo->m_no_new_privileges;
+    }
+    if(!cinfo->m_runtime.empty())
+    {
+        container["runtime"] = cinfo->m_runtime;
+    }
+    if(cinfo->m_sandboxed_runtime)
+    {
+        container["sandboxed_runtime"] = cinfo->m_sandboxed_runtime;
+    }
+    if(!cinfo->m_isolation.empty())
+    {
+        container["isolation"] = cinfo->m_isolation;
+    }
+    if(!cinfo->m_cgroup_path.empty())
+    {
+        container["cgroup_path"] = cinfo->m_cgroup_path;
+    }
+    if(!cinfo->m_cgroup_driver.empty())
+    {
+        container["cgroup_driver"] = cinfo->m_cgroup_driver;
+    }
+    if(cinfo->m_rootless_uid != 0)
+    {
+        container["rootless_uid"] = cinfo->m_rootless_uid;
+    }
+    if(!cinfo->m_config_digest.empty())
+    {
+        container["config_digest"] = cinfo->m_config_digest;
+    }
+    if(cinfo->m_userns)
+    {
+        container["userns"] = cinfo->m_userns;
+    }
+    if(!cinfo->m_uid_mappings.empty())
+    {
+        container["uid_mappings"] = cinfo->m_uid_mappings;
+    }
+    if(!cinfo->m_gid_mappings.empty())
+    {
+        container["gid_mappings"] = cinfo->m_gid_mappings;
+    }
     container["pod_sandbox_labels"] = cinfo->m_pod_sandbox_labels;
+    if(!cinfo->m_annotations.empty())
+    {
+        container["annotations"] = cinfo->m_annotations;
+    }
+    if(!cinfo->m_pod_annotations.empty())
+    {
+        container["pod_annotations"] = cinfo->m_pod_annotations;
+    }
     container["port_mappings"] = cinfo->m_port_mappings;
+    if(!cinfo->m_networks.empty())
+    {
+        container["networks"] = cinfo->m_networks;
+    }
     container["Mounts"] = cinfo->m_mounts;
+    if(cinfo->m_finished_at != 0)
+    {
+        container["exit_code"] = cinfo->m_exit_code;
+        container["finished_at"] = cinfo->m_finished_at;
+        if(cinfo->m_oom_killed)
+        {
+            container["oom_killed"] = true;
+        }
+    }
+    if(cinfo->m_restart_count != 0)
+    {
+        container["restart_count"] = cinfo->m_restart_count;
+    }
+    if(cinfo->m_paused_at != 0)
+    {
+        container["paused_at"] = cinfo->m_paused_at;
+    }
+    if(!cinfo->m_health_status.empty())
+    {
+        container["health_status"] = cinfo->m_health_status;
+        container["health_output"] = cinfo->m_health_output;
+    }
+    if(cinfo->m_stats.m_sampled_at != 0)
+    {
+        container["stats"] = cinfo->m_stats;
+    }
 
     for(auto& probe : cinfo->m_health_probes)
     {
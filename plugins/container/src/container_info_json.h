@@ -7,10 +7,17 @@
 void from_json(const nlohmann::json& j, container_health_probe& probe);
 void from_json(const nlohmann::json& j, container_mount_info& mount);
 void from_json(const nlohmann::json& j, container_port_mapping& port);
+void from_json(const nlohmann::json& j, container_id_mapping& mapping);
+void from_json(const nlohmann::json& j, container_network_info& network);
+void from_json(const nlohmann::json& j, container_stats& stats);
+void from_json(const nlohmann::json& j, engine_status& status);
 void from_json(const nlohmann::json& j, container_info::ptr_t& cinfo);
 
 void to_json(nlohmann::json& j, const container_health_probe& probe);
 void to_json(nlohmann::json& j, const container_mount_info& mount);
 void to_json(nlohmann::json& j, const container_port_mapping& port);
+void to_json(nlohmann::json& j, const container_id_mapping& mapping);
+void to_json(nlohmann::json& j, const container_network_info& network);
+void to_json(nlohmann::json& j, const container_stats& stats);
 void to_json(nlohmann::json& j,
              const std::shared_ptr<const container_info>& cinfo);
@@ -16,6 +16,9 @@ enum container_type
     CT_BPM = 9,
     CT_STATIC = 10,
     CT_PODMAN = 11,
+    CT_FARGATE = 12,
+    CT_GARDEN = 13,
+    CT_APPTAINER = 14,
 
     // Default value, may be changed if necessary
     CT_HOST = 0xfffe,
@@ -56,6 +59,15 @@ static std::string inline to_string(enum container_type ct)
     case CT_PODMAN:
         return "podman";
         break;
+    case CT_FARGATE:
+        return "fargate";
+        break;
+    case CT_GARDEN:
+        return "garden";
+        break;
+    case CT_APPTAINER:
+        return "apptainer";
+        break;
     case CT_HOST:
         return "host";
         break;
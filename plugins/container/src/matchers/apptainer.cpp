@@ -0,0 +1,54 @@
+#include "apptainer.h"
+#include <cstring>
+
+// Parses the instance pid following one of the provided prefixes, eg:
+// "apptainer-" in "/system.slice/apptainer-4242.scope".
+static bool parse_instance_pid(const std::string& cgroup, const char* prefix,
+                               const char* suffix, std::string& pid)
+{
+    auto pos = cgroup.find(prefix);
+    if(pos == std::string::npos)
+    {
+        return false;
+    }
+    auto pid_start = pos + strlen(prefix);
+    auto pid_len = strspn(cgroup.c_str() + pid_start, "0123456789");
+    if(pid_len == 0)
+    {
+        return false;
+    }
+    auto pid_end = pid_start + pid_len;
+    if(cgroup.compare(pid_end, strlen(suffix), suffix) != 0)
+    {
+        return false;
+    }
+    // The pid must be followed by the end of the cgroup or by a child one.
+    auto rest = pid_end + strlen(suffix);
+    if(rest != cgroup.size() && cgroup[rest] != '/')
+    {
+        return false;
+    }
+    pid = cgroup.substr(pid_start, pid_len);
+    return true;
+}
+
+bool apptainer::resolve(const std::string& cgroup, std::string& container_id)
+{
+    //
+    // Apptainer and Singularity instances, named after their pid:
+    // /apptainer/<pid> with the cgroupfs manager,
+    // apptainer-<pid>.scope with the systemd one.
+    //
+    return parse_instance_pid(cgroup, "/apptainer/", "", container_id) ||
+           parse_instance_pid(cgroup, "/apptainer-", ".scope", container_id) ||
+           parse_instance_pid(cgroup, "/singularity/", "", container_id) ||
+           parse_instance_pid(cgroup, "/singularity-", ".scope", container_id);
+}
+
+container_info::ptr_t apptainer::to_container(const std::string& container_id)
+{
+    auto ctr = std::make_shared<container_info>();
+    ctr->m_id = container_id;
+    ctr->m_type = CT_APPTAINER;
+    return ctr;
+}
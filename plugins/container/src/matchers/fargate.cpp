@@ -0,0 +1,36 @@
+#include "fargate.h"
+#include <cstring>
+
+bool fargate::resolve(const std::string& cgroup, std::string& container_id)
+{
+    //
+    // ECS task containers on Fargate: /ecs/<task_id>/<task_id>-<n>
+    //
+    auto pos = cgroup.find("/ecs/");
+    if(pos == std::string::npos)
+    {
+        return false;
+    }
+    auto task_start = pos + sizeof("/ecs/") - 1;
+    auto task_end = cgroup.find('/', task_start);
+    if(task_end == std::string::npos || task_end == task_start)
+    {
+        return false;
+    }
+    auto task_id = cgroup.substr(task_start, task_end - task_start);
+    auto id = cgroup.substr(task_end + 1);
+
+    // Unlike runc ones, these IDs are not truncated, since all the containers
+    // of a task share the same prefix.
+    auto suffix_start = task_id.size() + 1;
+    if(id.size() > suffix_start &&
+       id.compare(0, task_id.size(), task_id) == 0 &&
+       id[task_id.size()] == '-' &&
+       strspn(id.c_str() + suffix_start, "0123456789") ==
+               id.size() - suffix_start)
+    {
+        container_id = id;
+        return true;
+    }
+    return false;
+}
@@ -0,0 +1,8 @@
+#pragma once
+
+#include "matcher.h"
+
+class fargate : public cgroup_matcher
+{
+    bool resolve(const std::string& cgroup, std::string& container_id) override;
+};
@@ -0,0 +1,29 @@
+#include "garden.h"
+#include <cstring>
+
+bool garden::resolve(const std::string& cgroup, std::string& container_id)
+{
+    //
+    // Cloud Foundry Diego cells containers, right under the garden cgroups
+    // root: /garden/<handle>
+    //
+    static const char prefix[] = "/garden/";
+    if(cgroup.compare(0, sizeof(prefix) - 1, prefix) != 0)
+    {
+        return false;
+    }
+    auto id_start = sizeof(prefix) - 1;
+    auto id_end = cgroup.find('/', id_start);
+    auto id = cgroup.substr(id_start, id_end - id_start);
+
+    // Handles are app instances or tasks GUIDs; like fargate ones, they are
+    // not truncated.
+    if(!id.empty() &&
+       strspn(id.c_str(), "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUV"
+                          "WXYZ0123456789._-") == id.size())
+    {
+        container_id = id;
+        return true;
+    }
+    return false;
+}
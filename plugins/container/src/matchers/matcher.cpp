@@ -1,6 +1,9 @@
 #include "matcher.h"
 #include "docker.h"
 #include "bpm.h"
+#include "fargate.h"
+#include "garden.h"
+#include "apptainer.h"
 #include "podman.h"
 #include "cri.h"
 #include "containerd.h"
@@ -34,6 +37,23 @@ matcher_manager::matcher_manager(const Engines& cfg)
         auto cri_engine = std::make_shared<cri>();
         m_cgroup_matchers.push_back(cri_engine);
     }
+    // Engines of specific cgroup layouts go before containerd, that matches
+    // any /<namespace>/<id> cgroup.
+    if(cfg.fargate.enabled)
+    {
+        auto fargate_engine = std::make_shared<fargate>();
+        m_cgroup_matchers.push_back(fargate_engine);
+    }
+    if(cfg.garden.enabled)
+    {
+        auto garden_engine = std::make_shared<garden>();
+        m_cgroup_matchers.push_back(garden_engine);
+    }
+    if(cfg.apptainer.enabled)
+    {
+        auto apptainer_engine = std::make_shared<apptainer>();
+        m_cgroup_matchers.push_back(apptainer_engine);
+    }
     if(cfg.containerd.enabled)
     {
         auto containerd_engine = std::make_shared<containerd>();
@@ -85,6 +85,16 @@ bool my_plugin::init(falcosecurity::init_input& in)
     auto cfg = nlohmann::json::parse(in.get_config());
     parse_init_config(cfg);
 
+    // The schema only covers single options; check their consistency too.
+    auto cfg_errors = m_cfg.validate();
+    if(!cfg_errors.empty())
+    {
+        m_lasterr = "invalid init config: " + cfg_errors;
+        m_logger.log(m_lasterr,
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_CRITICAL);
+        return false;
+    }
+
     m_logger.log("init the plugin",
                  falcosecurity::_internal::SS_PLUGIN_LOG_SEV_DEBUG);
 
@@ -155,7 +165,7 @@ bool my_plugin::init(falcosecurity::init_input& in)
     // Initialize dummy host container entry
     m_containers[""] = container_info::host_container_info();
 
-    // Initialize metrics
+    // Initialize metrics, in the order of their metric_index
     falcosecurity::metric n_container(METRIC_N_CONTAINERS);
     n_container.set_value(0);
     m_metrics.push_back(n_container);
@@ -164,14 +174,109 @@ bool my_plugin::init(falcosecurity::init_input& in)
     n_missing.set_value(0);
     m_metrics.push_back(n_missing);
 
+    falcosecurity::metric n_reconnects(METRIC_N_ENGINE_RECONNECTS);
+    n_reconnects.set_value(0);
+    m_metrics.push_back(n_reconnects);
+
+    init_worker_metrics();
+
     return true;
 }
 
+void my_plugin::init_worker_metrics()
+{
+    m_worker_metrics = {METRIC_N_WORKER_EVENTS, METRIC_N_WORKER_EVENTS_DROPPED,
+                        METRIC_N_WORKER_PANICS, METRIC_N_WORKER_RECONCILED,
+                        METRIC_N_WORKER_UPDATES_DEDUPLICATED,
+                        METRIC_N_INSPECTS, METRIC_N_CACHE_HITS,
+                        METRIC_N_CACHE_MISSES,
+                        METRIC_N_FETCH_REQUESTS_DROPPED, METRIC_N_LOOKUPS,
+                        METRIC_N_LOOKUPS_FAILED,
+                        METRIC_N_CIRCUIT_BREAKER_OPENED,
+                        METRIC_N_WORKER_GOROUTINES_THROTTLED};
+    const auto add_histogram = [this](const std::string& histogram)
+    {
+        for(const auto& bucket : METRIC_LATENCY_BUCKETS)
+        {
+            m_worker_metrics.push_back(histogram + "_le_" + bucket);
+        }
+        m_worker_metrics.push_back(histogram + "_sum");
+    };
+    // Every engine lists its containers, while only the daemon ones inspect
+    // them one by one.
+    for(const auto& engine : m_cfg.engines.enabled_worker_engines())
+    {
+        if(is_daemon_engine(engine))
+        {
+            m_worker_metrics.push_back(METRIC_N_INSPECT_FAILURES_PREFIX +
+                                       engine);
+            add_histogram(METRIC_INSPECT_LATENCY_MS_PREFIX + engine);
+        }
+        add_histogram(METRIC_LIST_LATENCY_MS_PREFIX + engine);
+    }
+
+    m_metrics.erase(m_metrics.begin() + METRIC_INDEX_WORKER, m_metrics.end());
+    for(const auto& name : m_worker_metrics)
+    {
+        falcosecurity::metric m(name);
+        m.set_value(0);
+        m_metrics.push_back(m);
+    }
+}
+
 const std::vector<falcosecurity::metric>& my_plugin::get_metrics()
 {
+    // Update n_engine_reconnects metric, tracked by the go-worker
+    m_metrics.at(METRIC_INDEX_N_ENGINE_RECONNECTS)
+            .set_value((uint64_t)GetEngineReconnects());
+    // Update the other metrics tracked by the go-worker
+    for(size_t i = 0; i < m_worker_metrics.size(); i++)
+    {
+        m_metrics.at(METRIC_INDEX_WORKER + i)
+                .set_value((uint64_t)GetWorkerMetric(
+                        m_worker_metrics[i].c_str()));
+    }
     return m_metrics;
 }
 
+bool my_plugin::set_config(falcosecurity::set_config_input& in)
+{
+    PluginConfig cfg;
+    try
+    {
+        cfg = nlohmann::json::parse(in.get_config()).get<PluginConfig>();
+    }
+    catch(const nlohmann::json::exception& e)
+    {
+        m_lasterr = fmt::format("cannot parse the plugin config: {}", e.what());
+        m_logger.log(m_lasterr,
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_ERROR);
+        return false;
+    }
+    auto cfg_errors = cfg.validate();
+    if(!cfg_errors.empty())
+    {
+        m_lasterr = "invalid config: " + cfg_errors;
+        m_logger.log(m_lasterr,
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_ERROR);
+        return false;
+    }
+
+    // Only the engines are reloaded live; other options require a restart.
+    m_logger.log("reloading the engines config",
+                 falcosecurity::_internal::SS_PLUGIN_LOG_SEV_INFO);
+    m_cfg.engines = cfg.engines;
+    m_cfg.log_engines(m_logger);
+    m_mgr = std::make_unique<matcher_manager>(m_cfg.engines);
+    // The metrics of the enabled engines follow them.
+    init_worker_metrics();
+#ifdef _HAS_ASYNC
+    return reload_async_engines();
+#else
+    return true;
+#endif
+}
+
 FALCOSECURITY_PLUGIN(my_plugin);
 
 /* Utils */
@@ -338,6 +443,75 @@ void my_plugin::write_thread_category(
     }
 }
 
+#ifdef _HAS_ASYNC
+container_info::ptr_t
+my_plugin::lookup_container_info(const std::string& container_id)
+{
+    if(m_cfg.lookup_timeout_ms <= 0)
+    {
+        return nullptr;
+    }
+    // The lookup blocks the event processing up to its timeout: do not retry
+    // it for a while once it failed, eg: for every process of a container
+    // already gone or unknown to the engines.
+    auto now = std::chrono::steady_clock::now();
+    auto failed = m_failed_lookups.find(container_id);
+    if(failed != m_failed_lookups.end())
+    {
+        if(now - failed->second < std::chrono::seconds(LOOKUP_FAILURE_TTL_S))
+        {
+            return nullptr;
+        }
+        m_failed_lookups.erase(failed);
+    }
+    // Implemented by GO worker.go
+    char* json = LookupContainerInfo(m_async_ctx, container_id.c_str());
+    if(json == nullptr)
+    {
+        m_logger.log(
+                fmt::format("synchronous lookup of container {} failed",
+                            container_id),
+                falcosecurity::_internal::SS_PLUGIN_LOG_SEV_DEBUG);
+        if(m_failed_lookups.size() >= LOOKUP_FAILURES_PRUNE_SIZE)
+        {
+            for(auto it = m_failed_lookups.begin();
+                it != m_failed_lookups.end();)
+            {
+                if(now - it->second >=
+                   std::chrono::seconds(LOOKUP_FAILURE_TTL_S))
+                {
+                    it = m_failed_lookups.erase(it);
+                }
+                else
+                {
+                    ++it;
+                }
+            }
+        }
+        m_failed_lookups[container_id] = std::chrono::steady_clock::now();
+        return nullptr;
+    }
+    container_info::ptr_t cinfo = nullptr;
+    try
+    {
+        cinfo = nlohmann::json::parse(json).get<container_info::ptr_t>();
+        // Send the metadata through the async events too, as done for the
+        // go-worker ones, so that they get into captures.
+        generate_async_event<ASYNC_HANDLER_DEFAULT>(
+                json, ASYNC_EVENT_KIND_ADDED, false);
+    }
+    catch(const std::exception& e)
+    {
+        m_logger.log(fmt::format("failed to parse the looked up metadata of "
+                                 "container {}: {}",
+                                 container_id, e.what()),
+                     falcosecurity::_internal::SS_PLUGIN_LOG_SEV_WARNING);
+    }
+    free(json);
+    return cinfo;
+}
+#endif
+
 void my_plugin::on_new_process(const falcosecurity::table_entry& thread_entry,
                                const falcosecurity::table_reader& tr,
                                const falcosecurity::table_writer& tw)
@@ -358,8 +532,8 @@ void my_plugin::on_new_process(const falcosecurity::table_entry& thread_entry,
             // up. No need to send any even when reading from a scap file since
             // the `container` event is already encoded in it.
             nlohmann::json j(info);
-            generate_async_event<ASYNC_HANDLER_DEFAULT>(j.dump().c_str(), true,
-                                                        false);
+            generate_async_event<ASYNC_HANDLER_DEFAULT>(
+                    j.dump().c_str(), ASYNC_EVENT_KIND_ADDED, false);
         }
 #endif
         // Immediately cache the container metadata
@@ -388,6 +562,15 @@ void my_plugin::on_new_process(const falcosecurity::table_entry& thread_entry,
                m_asked_containers.find(container_id) ==
                        m_asked_containers.end())
             {
+                // Try a synchronous lookup first, so that the metadata is
+                // available before the async event gets delivered.
+                auto cinfo = lookup_container_info(container_id);
+                if(cinfo != nullptr)
+                {
+                    m_containers[container_id] = cinfo;
+                    write_thread_category(cinfo, thread_entry, tr, tw);
+                    return;
+                }
                 m_logger.log(
                         fmt::format("asking the go-worker to fetch info for "
                                     "container {}",
@@ -18,6 +18,7 @@ limitations under the License.
 #include <consts.h>
 #include <macros.h>
 #include <matchers/matcher.h>
+#include <chrono>
 #include <unordered_map>
 #include <unordered_set>
 
@@ -50,6 +51,7 @@ class my_plugin
     falcosecurity::init_schema get_init_schema();
     void parse_init_config(nlohmann::json& config_json);
     bool init(falcosecurity::init_input& in);
+    bool set_config(falcosecurity::set_config_input& in);
     const std::vector<falcosecurity::metric>& get_metrics();
 
 #ifdef _HAS_ASYNC
@@ -116,6 +118,17 @@ class my_plugin
                           const falcosecurity::table_reader& tr,
                           const falcosecurity::table_writer& tw);
 
+#ifdef _HAS_ASYNC
+    // Synchronously asks the go-worker for the metadata of a container,
+    // within the configured lookup timeout. Returns nullptr on failure.
+    container_info::ptr_t
+    lookup_container_info(const std::string& container_id);
+
+    // Attaches and detaches the go-worker engines following the engines
+    // config, without restarting the worker.
+    bool reload_async_engines();
+#endif
+
     falcosecurity::_internal::ss_plugin_table_input& get_table();
 
     private:
@@ -125,11 +138,34 @@ class my_plugin
     // Last container enriched from an async event parsing.
     // Used to extract container info from aforementioned async events.
     std::pair<uint64_t, std::shared_ptr<const container_info>> m_last_container;
+    // Last engine status parsed from an async event, used to extract the
+    // engine fields from it.
+    std::pair<uint64_t, engine_status> m_last_engine_status;
     // Cache being asked containers to go-worker through AskForContainerInfo()
     // API. Avoids repeatedly calling the API.
     std::unordered_set<std::string> m_asked_containers;
-
+    // Time of the failed synchronous lookups of containers, by container id.
+    // Each container is looked up at most once every LOOKUP_FAILURE_TTL_S,
+    // since every lookup blocks the event processing.
+    std::unordered_map<std::string, std::chrono::steady_clock::time_point>
+            m_failed_lookups;
+
+    // Indexes of the metrics tracked by the plugin in m_metrics.
+    enum metric_index
+    {
+        METRIC_INDEX_N_CONTAINERS = 0,
+        METRIC_INDEX_N_MISSING,
+        METRIC_INDEX_N_ENGINE_RECONNECTS,
+        // First of the metrics tracked by the go-worker.
+        METRIC_INDEX_WORKER,
+    };
     std::vector<falcosecurity::metric> m_metrics;
+    // Names of the metrics tracked by the go-worker, following the
+    // plugin ones in m_metrics.
+    std::vector<std::string> m_worker_metrics;
+    // Sets the metrics tracked by the go-worker, including the ones of the
+    // enabled engines.
+    void init_worker_metrics();
 
     PluginConfig m_cfg;
 
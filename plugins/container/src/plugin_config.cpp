@@ -1,6 +1,7 @@
-#include <filesystem>
 #include "plugin_config.h"
 
+#include <algorithm>
+
 void from_json(const nlohmann::json& j, StaticEngine& engine)
 {
     engine.enabled = j.value("enabled", false);
@@ -14,29 +15,245 @@ void from_json(const nlohmann::json& j, SimpleEngine& engine)
     engine.enabled = j.value("enabled", true);
 }
 
+void from_json(const nlohmann::json& j, EngineTLS& tls)
+{
+    tls.ca = j.value("ca", "");
+    tls.cert = j.value("cert", "");
+    tls.key = j.value("key", "");
+    tls.server_name = j.value("server_name", "");
+    tls.insecure_skip_verify = j.value("insecure_skip_verify", false);
+}
+
 void from_json(const nlohmann::json& j, SocketsEngine& engine)
 {
     engine.enabled = j.value("enabled", true);
     engine.sockets = j.value("sockets", std::vector<std::string>{});
+    engine.timeout_ms = j.value("timeout_ms", 0);
+    engine.list_timeout_ms = j.value("list_timeout_ms", 0);
+    engine.log_level = j.value("log_level", "");
+}
+
+void from_json(const nlohmann::json& j, OptInSocketsEngine& engine)
+{
+    from_json(j, static_cast<SocketsEngine&>(engine));
+    engine.enabled = j.value("enabled", false);
+}
+
+void from_json(const nlohmann::json& j, ContainerdEngine& engine)
+{
+    from_json(j, static_cast<SocketsEngine&>(engine));
+    engine.namespaces = j.value("namespaces", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, CriEngine& engine)
+{
+    from_json(j, static_cast<SocketsEngine&>(engine));
+    engine.fallback = j.value("fallback", false);
+}
+
+void from_json(const nlohmann::json& j, DockerEngine& engine)
+{
+    from_json(j, static_cast<SocketsEngine&>(engine));
+    engine.contexts = j.value("contexts", std::vector<std::string>{});
+    engine.rootless = j.value("rootless", false);
+    engine.tls = j.value("tls", EngineTLS{});
+    engine.tls_endpoints =
+            j.value("tls_endpoints", std::map<std::string, EngineTLS>{});
+}
+
+void from_json(const nlohmann::json& j, LibvirtLxcEngine& engine)
+{
+    engine.enabled = j.value("enabled", true);
+    engine.state_dir = j.value("state_dir", DEFAULT_LIBVIRT_LXC_STATE_DIR);
+}
+
+void from_json(const nlohmann::json& j, FixtureEngine& engine)
+{
+    engine.enabled = j.value("enabled", false);
+    engine.dirs = j.value("dirs", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, SimulateEngine& engine)
+{
+    engine.enabled = j.value("enabled", false);
+    engine.create_rate = j.value("create_rate", DEFAULT_SIMULATE_CREATE_RATE);
+    engine.lifetime_ms = j.value("lifetime_ms", DEFAULT_SIMULATE_LIFETIME_MS);
+    engine.max_containers =
+            j.value("max_containers", DEFAULT_SIMULATE_MAX_CONTAINERS);
+    engine.seed = j.value("seed", uint64_t(0));
 }
 
 void from_json(const nlohmann::json& j, Engines& engines)
 {
-    engines.bpm = j.value("bpm", SimpleEngine{});
-    engines.lxc = j.value("lxc", SimpleEngine{});
-    engines.libvirt_lxc = j.value("libvirt_lxc", SimpleEngine{});
+    engines.apptainer = j.value("apptainer", OptInSocketsEngine{});
+    engines.bpm = j.value("bpm", SocketsEngine{});
+    engines.fargate = j.value("fargate", OptInSocketsEngine{});
+    engines.garden = j.value("garden", OptInSocketsEngine{});
+    engines.lxc = j.value("lxc", SocketsEngine{});
+    engines.libvirt_lxc = j.value("libvirt_lxc", LibvirtLxcEngine{});
     engines.static_ctr = j.value("static", StaticEngine{});
+    engines.fixture = j.value("fixture", FixtureEngine{});
+    engines.simulate = j.value("simulate", SimulateEngine{});
 
-    engines.docker = j.value("docker", SocketsEngine{});
+    engines.docker = j.value("docker", DockerEngine{});
     engines.podman = j.value("podman", SocketsEngine{});
-    engines.cri = j.value("cri", SocketsEngine{});
-    engines.containerd = j.value("containerd", SocketsEngine{});
+    engines.cri = j.value("cri", CriEngine{});
+    engines.containerd = j.value("containerd", ContainerdEngine{});
+}
+
+std::vector<std::string> Engines::enabled_worker_engines() const
+{
+    const std::vector<std::pair<std::string, bool>> worker_engines = {
+            {"docker", docker.enabled},
+            {"podman", podman.enabled},
+            {"containerd", containerd.enabled},
+            {"cri", cri.enabled},
+            {"lxc", lxc.enabled},
+            {"libvirt_lxc", libvirt_lxc.enabled},
+            {"bpm", bpm.enabled},
+            {"fargate", fargate.enabled},
+            {"garden", garden.enabled},
+            {"apptainer", apptainer.enabled},
+            {"fixture", fixture.enabled},
+            {"simulate", simulate.enabled}};
+    std::vector<std::string> enabled;
+    for(const auto& [name, engine_enabled] : worker_engines)
+    {
+        if(engine_enabled)
+        {
+            enabled.push_back(name);
+        }
+    }
+    return enabled;
+}
+
+bool is_daemon_engine(const std::string& engine)
+{
+    return engine == "docker" || engine == "podman" || engine == "cri" ||
+           engine == "containerd";
+}
+
+void from_json(const nlohmann::json& j, RegistryAuth& auth)
+{
+    auth.username = j.value("username", "");
+    auth.password = j.value("password", "");
+}
+
+void from_json(const nlohmann::json& j, DigestResolution& digest_resolution)
+{
+    digest_resolution.enabled = j.value("enabled", false);
+    digest_resolution.timeout_ms =
+            j.value("timeout_ms", DEFAULT_DIGEST_RESOLUTION_TIMEOUT_MS);
+    digest_resolution.cache_ttl_ms =
+            j.value("cache_ttl_ms", DEFAULT_DIGEST_RESOLUTION_CACHE_TTL_MS);
+    digest_resolution.auths =
+            j.value("auths", std::map<std::string, RegistryAuth>{});
+}
+
+void from_json(const nlohmann::json& j, EcsMetadata& ecs_metadata)
+{
+    ecs_metadata.enabled = j.value("enabled", false);
+    ecs_metadata.timeout_ms =
+            j.value("timeout_ms", DEFAULT_ECS_METADATA_TIMEOUT_MS);
+    ecs_metadata.cache_ttl_ms =
+            j.value("cache_ttl_ms", DEFAULT_ECS_METADATA_CACHE_TTL_MS);
+}
+
+void from_json(const nlohmann::json& j, NomadConfig& nomad)
+{
+    nomad.enabled = j.value("enabled", false);
+    nomad.address = j.value("address", std::string{DEFAULT_NOMAD_ADDRESS});
+    nomad.token = j.value("token", "");
+    nomad.timeout_ms = j.value("timeout_ms", DEFAULT_NOMAD_TIMEOUT_MS);
+    nomad.cache_ttl_ms = j.value("cache_ttl_ms", DEFAULT_NOMAD_CACHE_TTL_MS);
+}
+
+void from_json(const nlohmann::json& j, EventQueue& event_queue)
+{
+    event_queue.size = j.value("size", DEFAULT_EVENT_QUEUE_SIZE);
+    event_queue.policy =
+            j.value("policy", std::string{DEFAULT_EVENT_QUEUE_POLICY});
+}
+
+void from_json(const nlohmann::json& j, CircuitBreaker& circuit_breaker)
+{
+    circuit_breaker.failures = j.value("failures", 0);
+    circuit_breaker.cooldown_ms =
+            j.value("cooldown_ms", DEFAULT_CIRCUIT_BREAKER_COOLDOWN_MS);
+}
+
+void from_json(const nlohmann::json& j, WorkerLimits& worker_limits)
+{
+    worker_limits.max_goroutines = j.value("max_goroutines", 0);
+    worker_limits.gomaxprocs = j.value("gomaxprocs", 0);
+}
+
+void from_json(const nlohmann::json& j, EnvConfig& env)
+{
+    env.allowlist = j.value("allowlist", std::vector<std::string>{});
+    env.redact = j.value("redact", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, AnnotationsConfig& annotations)
+{
+    annotations.include = j.value("include", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, IgnoreConfig& ignore)
+{
+    ignore.images = j.value("images", IgnoreConfig{}.images);
+    ignore.names = j.value("names", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, LabelSelectors& label_selectors)
+{
+    label_selectors.include = j.value("include", std::vector<std::string>{});
+    label_selectors.exclude = j.value("exclude", std::vector<std::string>{});
+}
+
+void from_json(const nlohmann::json& j, PayloadConfig& payload)
+{
+    payload.exclude = j.value("exclude", std::vector<std::string>{});
+    payload.digest = j.value("digest", false);
 }
 
 void from_json(const nlohmann::json& j, PluginConfig& cfg)
 {
     cfg.label_max_len = j.value("label_max_len", DEFAULT_LABEL_MAX_LEN);
     cfg.with_size = j.value("with_size", false);
+    cfg.list_concurrency =
+            j.value("list_concurrency", DEFAULT_LIST_CONCURRENCY);
+    cfg.inspect_timeout_ms =
+            j.value("inspect_timeout_ms", DEFAULT_INSPECT_TIMEOUT_MS);
+    cfg.list_timeout_ms = j.value("list_timeout_ms", DEFAULT_LIST_TIMEOUT_MS);
+    cfg.cache_ttl_ms = j.value("cache_ttl_ms", DEFAULT_CACHE_TTL_MS);
+    cfg.cache_max_entries =
+            j.value("cache_max_entries", DEFAULT_CACHE_MAX_ENTRIES);
+    cfg.cache_snapshot_path = j.value("cache_snapshot_path", "");
+    cfg.enrich_timeout_ms =
+            j.value("enrich_timeout_ms", DEFAULT_ENRICH_TIMEOUT_MS);
+    cfg.digest_resolution =
+            j.value("digest_resolution", DigestResolution{});
+    cfg.ecs_metadata = j.value("ecs_metadata", EcsMetadata{});
+    cfg.nomad = j.value("nomad", NomadConfig{});
+    cfg.env = j.value("env", EnvConfig{});
+    cfg.annotations = j.value("annotations", AnnotationsConfig{});
+    cfg.ignore = j.value("ignore", IgnoreConfig{});
+    cfg.label_selectors = j.value("label_selectors", LabelSelectors{});
+    cfg.suppress_pod_sandboxes = j.value("suppress_pod_sandboxes", false);
+    cfg.payload = j.value("payload", PayloadConfig{});
+    cfg.event_queue = j.value("event_queue", EventQueue{});
+    cfg.circuit_breaker = j.value("circuit_breaker", CircuitBreaker{});
+    cfg.worker_limits = j.value("worker_limits", WorkerLimits{});
+    cfg.connect_retry_max_backoff_ms =
+            j.value("connect_retry_max_backoff_ms",
+                    DEFAULT_CONNECT_RETRY_MAX_BACKOFF_MS);
+    cfg.reconcile_interval_ms =
+            j.value("reconcile_interval_ms", DEFAULT_RECONCILE_INTERVAL_MS);
+    cfg.stats_interval_ms =
+            j.value("stats_interval_ms", DEFAULT_STATS_INTERVAL_MS);
+    cfg.lookup_timeout_ms =
+            j.value("lookup_timeout_ms", DEFAULT_LOOKUP_TIMEOUT_MS);
     cfg.log_level = j.value("log_level", std::string{"warn"});
 
     std::vector<std::string> hooks =
@@ -54,10 +271,46 @@ void from_json(const nlohmann::json& j, PluginConfig& cfg)
         {
             cfg.hooks |= HOOK_START;
         }
+        else if(hook == "remove")
+        {
+            cfg.hooks |= HOOK_REMOVE;
+        }
+        else if(hook == "die")
+        {
+            cfg.hooks |= HOOK_DIE;
+        }
+        else if(hook == "pause")
+        {
+            cfg.hooks |= HOOK_PAUSE;
+        }
+        else if(hook == "health")
+        {
+            cfg.hooks |= HOOK_HEALTH;
+        }
+        else if(hook == "oom")
+        {
+            cfg.hooks |= HOOK_OOM;
+        }
     }
 
     cfg.engines = j.value("engines", Engines{});
 
+#ifdef _WIN32
+    // Windows engines listen on named pipes
+    if(cfg.engines.docker.sockets.empty())
+    {
+        cfg.engines.docker.sockets.emplace_back(DOCKER_NAMED_PIPE);
+    }
+    if(cfg.engines.cri.sockets.empty())
+    {
+        cfg.engines.cri.sockets.emplace_back(CONTAINERD_NAMED_PIPE);
+    }
+    if(cfg.engines.containerd.sockets.empty())
+    {
+        cfg.engines.containerd.sockets.emplace_back(CONTAINERD_NAMED_PIPE);
+    }
+#endif
+
     // Set default sockets if emtpy
     if(cfg.engines.docker.sockets.empty())
     {
@@ -66,40 +319,60 @@ void from_json(const nlohmann::json& j, PluginConfig& cfg)
     if(cfg.engines.podman.sockets.empty())
     {
         cfg.engines.podman.sockets.emplace_back("/run/podman/podman.sock");
-        try
-        {
-            for(const auto& entry : std::filesystem::directory_iterator(
-                        cfg.host_root + "/run/user"))
-            {
-                if(entry.is_directory())
-                {
-                    if(std::filesystem::exists(entry.path().string() +
-                                               "/podman/podman.sock"))
-                    {
-                        // Remove host root since it will be later added by
-                        // go-worker itself
-                        auto root = entry.path().string().substr(
-                                cfg.host_root.length());
-                        cfg.engines.podman.sockets.emplace_back(
-                                root + "/podman/podman.sock");
-                    }
-                }
-            }
-        }
-        catch(...)
-        {
-            // No error; perhaps /run/user does not exist.
-        }
+        // Rootless podman sockets, for each user in the system;
+        // go-worker expands the pattern and keeps watching for new sockets.
+        cfg.engines.podman.sockets.emplace_back(
+                "/run/user/*/podman/podman.sock");
     }
     if(cfg.engines.cri.sockets.empty())
     {
         cfg.engines.cri.sockets.emplace_back("/run/containerd/containerd.sock");
         cfg.engines.cri.sockets.emplace_back("/run/crio/crio.sock");
+        cfg.engines.cri.sockets.emplace_back("/var/run/crio/crio.sock");
         cfg.engines.cri.sockets.emplace_back(
                 "/run/k3s/containerd/containerd.sock");
         cfg.engines.cri.sockets.emplace_back(
                 "/run/host-containerd/containerd.sock");
     }
+    if(cfg.engines.lxc.sockets.empty())
+    {
+        cfg.engines.lxc.sockets.emplace_back("/var/lib/lxd/unix.socket");
+        cfg.engines.lxc.sockets.emplace_back(
+                "/var/snap/lxd/common/lxd/unix.socket");
+        cfg.engines.lxc.sockets.emplace_back("/var/lib/incus/unix.socket");
+    }
+    if(cfg.engines.bpm.sockets.empty())
+    {
+        // bpm runc root directory, holding containers state
+        cfg.engines.bpm.sockets.emplace_back("/var/vcap/sys/run/bpm-runc");
+    }
+    if(cfg.engines.fargate.sockets.empty())
+    {
+        // Task metadata endpoint, only set in ECS tasks
+        if(const char* metadata_uri =
+                   std::getenv("ECS_CONTAINER_METADATA_URI_V4"))
+        {
+            cfg.engines.fargate.sockets.emplace_back(metadata_uri);
+        }
+    }
+    if(cfg.engines.garden.sockets.empty())
+    {
+        // Garden API socket of Cloud Foundry Diego cells
+        cfg.engines.garden.sockets.emplace_back(
+                "/var/vcap/data/garden/garden.sock");
+    }
+    if(cfg.engines.apptainer.sockets.empty())
+    {
+        // Instances directories, for root and each user in the system
+        cfg.engines.apptainer.sockets.emplace_back(
+                "/root/.apptainer/instances");
+        cfg.engines.apptainer.sockets.emplace_back(
+                "/home/*/.apptainer/instances");
+        cfg.engines.apptainer.sockets.emplace_back(
+                "/root/.singularity/instances");
+        cfg.engines.apptainer.sockets.emplace_back(
+                "/home/*/.singularity/instances");
+    }
     if(cfg.engines.containerd.sockets.empty())
     {
         cfg.engines.containerd.sockets.emplace_back(
@@ -108,28 +381,387 @@ void from_json(const nlohmann::json& j, PluginConfig& cfg)
     }
 }
 
+void to_json(nlohmann::json& j, const EngineTLS& tls)
+{
+    j = nlohmann::json{{"ca", tls.ca},
+                       {"cert", tls.cert},
+                       {"key", tls.key},
+                       {"server_name", tls.server_name},
+                       {"insecure_skip_verify", tls.insecure_skip_verify}};
+}
+
 void to_json(nlohmann::json& j, const Engines& engines)
 {
     j = nlohmann::json{{"docker",
                         {{"enabled", engines.docker.enabled},
-                         {"sockets", engines.docker.sockets}}},
+                         {"sockets", engines.docker.sockets},
+                         {"timeout_ms", engines.docker.timeout_ms},
+                         {"list_timeout_ms", engines.docker.list_timeout_ms},
+                         {"log_level", engines.docker.log_level},
+                         {"contexts", engines.docker.contexts},
+                         {"rootless", engines.docker.rootless},
+                         {"tls", engines.docker.tls},
+                         {"tls_endpoints", engines.docker.tls_endpoints}}},
                        {"podman",
                         {{"enabled", engines.podman.enabled},
-                         {"sockets", engines.podman.sockets}}},
+                         {"sockets", engines.podman.sockets},
+                         {"timeout_ms", engines.podman.timeout_ms},
+                         {"list_timeout_ms", engines.podman.list_timeout_ms},
+                         {"log_level", engines.podman.log_level}}},
                        {"cri",
                         {{"enabled", engines.cri.enabled},
-                         {"sockets", engines.cri.sockets}}},
+                         {"sockets", engines.cri.sockets},
+                         {"timeout_ms", engines.cri.timeout_ms},
+                         {"list_timeout_ms", engines.cri.list_timeout_ms},
+                         {"log_level", engines.cri.log_level},
+                         {"fallback", engines.cri.fallback}}},
                        {"containerd",
                         {{"enabled", engines.containerd.enabled},
-                         {"sockets", engines.containerd.sockets}}}};
+                         {"sockets", engines.containerd.sockets},
+                         {"timeout_ms", engines.containerd.timeout_ms},
+                         {"list_timeout_ms",
+                          engines.containerd.list_timeout_ms},
+                         {"log_level", engines.containerd.log_level},
+                         {"namespaces", engines.containerd.namespaces}}},
+                       {"lxc",
+                        {{"enabled", engines.lxc.enabled},
+                         {"sockets", engines.lxc.sockets},
+                         {"list_timeout_ms", engines.lxc.list_timeout_ms},
+                         {"log_level", engines.lxc.log_level}}},
+                       // go-worker engines are bound to sockets;
+                       // for the libvirt_lxc engine, it is its state directory.
+                       {"libvirt_lxc",
+                        {{"enabled", engines.libvirt_lxc.enabled},
+                         {"sockets",
+                          std::vector<std::string>{
+                                  engines.libvirt_lxc.state_dir}}}},
+                       {"bpm",
+                        {{"enabled", engines.bpm.enabled},
+                         {"sockets", engines.bpm.sockets},
+                         {"list_timeout_ms", engines.bpm.list_timeout_ms},
+                         {"log_level", engines.bpm.log_level}}},
+                       {"fargate",
+                        {{"enabled", engines.fargate.enabled},
+                         {"sockets", engines.fargate.sockets},
+                         {"list_timeout_ms", engines.fargate.list_timeout_ms},
+                         {"log_level", engines.fargate.log_level}}},
+                       {"garden",
+                        {{"enabled", engines.garden.enabled},
+                         {"sockets", engines.garden.sockets},
+                         {"list_timeout_ms", engines.garden.list_timeout_ms},
+                         {"log_level", engines.garden.log_level}}},
+                       {"apptainer",
+                        {{"enabled", engines.apptainer.enabled},
+                         {"sockets", engines.apptainer.sockets},
+                         {"list_timeout_ms", engines.apptainer.list_timeout_ms},
+                         {"log_level", engines.apptainer.log_level}}},
+                       // go-worker engines are bound to sockets;
+                       // for the fixture engine, they are its directories.
+                       {"fixture",
+                        {{"enabled", engines.fixture.enabled},
+                         {"sockets", engines.fixture.dirs}}},
+                       {"simulate",
+                        {{"enabled", engines.simulate.enabled},
+                         {"create_rate", engines.simulate.create_rate},
+                         {"lifetime_ms", engines.simulate.lifetime_ms},
+                         {"max_containers", engines.simulate.max_containers},
+                         {"seed", engines.simulate.seed}}}};
+}
+
+void to_json(nlohmann::json& j, const RegistryAuth& auth)
+{
+    j = nlohmann::json{{"username", auth.username},
+                       {"password", auth.password}};
+}
+
+void to_json(nlohmann::json& j, const DigestResolution& digest_resolution)
+{
+    j = nlohmann::json{{"enabled", digest_resolution.enabled},
+                       {"timeout_ms", digest_resolution.timeout_ms},
+                       {"cache_ttl_ms", digest_resolution.cache_ttl_ms},
+                       {"auths", digest_resolution.auths}};
+}
+
+void to_json(nlohmann::json& j, const EcsMetadata& ecs_metadata)
+{
+    j = nlohmann::json{{"enabled", ecs_metadata.enabled},
+                       {"timeout_ms", ecs_metadata.timeout_ms},
+                       {"cache_ttl_ms", ecs_metadata.cache_ttl_ms}};
+}
+
+void to_json(nlohmann::json& j, const NomadConfig& nomad)
+{
+    j = nlohmann::json{{"enabled", nomad.enabled},
+                       {"address", nomad.address},
+                       {"token", nomad.token},
+                       {"timeout_ms", nomad.timeout_ms},
+                       {"cache_ttl_ms", nomad.cache_ttl_ms}};
+}
+
+void to_json(nlohmann::json& j, const EventQueue& event_queue)
+{
+    j = nlohmann::json{{"size", event_queue.size},
+                       {"policy", event_queue.policy}};
+}
+
+void to_json(nlohmann::json& j, const CircuitBreaker& circuit_breaker)
+{
+    j = nlohmann::json{{"failures", circuit_breaker.failures},
+                       {"cooldown_ms", circuit_breaker.cooldown_ms}};
+}
+
+void to_json(nlohmann::json& j, const WorkerLimits& worker_limits)
+{
+    j = nlohmann::json{{"max_goroutines", worker_limits.max_goroutines},
+                       {"gomaxprocs", worker_limits.gomaxprocs}};
+}
+
+void to_json(nlohmann::json& j, const EnvConfig& env)
+{
+    j = nlohmann::json{{"allowlist", env.allowlist}, {"redact", env.redact}};
+}
+
+void to_json(nlohmann::json& j, const AnnotationsConfig& annotations)
+{
+    j = nlohmann::json{{"include", annotations.include}};
+}
+
+void to_json(nlohmann::json& j, const IgnoreConfig& ignore)
+{
+    j = nlohmann::json{{"images", ignore.images}, {"names", ignore.names}};
+}
+
+void to_json(nlohmann::json& j, const LabelSelectors& label_selectors)
+{
+    j = nlohmann::json{{"include", label_selectors.include},
+                       {"exclude", label_selectors.exclude}};
+}
+
+void to_json(nlohmann::json& j, const PayloadConfig& payload)
+{
+    j = nlohmann::json{{"digest", payload.digest},
+                       {"exclude", payload.exclude}};
 }
 
 void to_json(nlohmann::json& j, const PluginConfig& cfg)
 {
     j["label_max_len"] = cfg.label_max_len;
     j["with_size"] = cfg.with_size;
+    j["list_concurrency"] = cfg.list_concurrency;
+    j["inspect_timeout_ms"] = cfg.inspect_timeout_ms;
+    j["list_timeout_ms"] = cfg.list_timeout_ms;
+    j["cache_ttl_ms"] = cfg.cache_ttl_ms;
+    j["cache_max_entries"] = cfg.cache_max_entries;
+    j["cache_snapshot_path"] = cfg.cache_snapshot_path;
+    j["enrich_timeout_ms"] = cfg.enrich_timeout_ms;
+    j["digest_resolution"] = cfg.digest_resolution;
+    j["ecs_metadata"] = cfg.ecs_metadata;
+    j["nomad"] = cfg.nomad;
+    j["env"] = cfg.env;
+    j["annotations"] = cfg.annotations;
+    j["ignore"] = cfg.ignore;
+    j["label_selectors"] = cfg.label_selectors;
+    j["suppress_pod_sandboxes"] = cfg.suppress_pod_sandboxes;
+    j["payload"] = cfg.payload;
+    j["event_queue"] = cfg.event_queue;
+    j["circuit_breaker"] = cfg.circuit_breaker;
+    j["worker_limits"] = cfg.worker_limits;
+    j["connect_retry_max_backoff_ms"] = cfg.connect_retry_max_backoff_ms;
+    j["reconcile_interval_ms"] = cfg.reconcile_interval_ms;
+    j["stats_interval_ms"] = cfg.stats_interval_ms;
+    j["lookup_timeout_ms"] = cfg.lookup_timeout_ms;
     j["host_root"] = cfg.host_root;
     j["hooks"] = cfg.hooks;
     j["log_level"] = cfg.log_level;
     j["engines"] = cfg.engines;
 }
+
+std::string PluginConfig::validate() const
+{
+    std::vector<std::string> errors;
+
+    if(list_concurrency < 1)
+    {
+        errors.push_back(fmt::format(
+                "'list_concurrency' must be at least 1, got {}",
+                list_concurrency));
+    }
+
+    if(event_queue.size < 1)
+    {
+        errors.push_back(
+                fmt::format("'event_queue.size' must be at least 1, got {}",
+                            event_queue.size));
+    }
+    if(event_queue.policy != "block" && event_queue.policy != "drop_oldest")
+    {
+        errors.push_back(fmt::format(
+                "'event_queue.policy' must be 'block' or 'drop_oldest', "
+                "got '{}'",
+                event_queue.policy));
+    }
+
+    if(!cache_snapshot_path.empty() && cache_max_entries < 1)
+    {
+        errors.push_back(
+                "'cache_snapshot_path' requires the metadata cache; set "
+                "'cache_max_entries' to at least 1");
+    }
+
+    if(worker_limits.max_goroutines < 0)
+    {
+        errors.push_back(fmt::format(
+                "'worker_limits.max_goroutines' must not be negative, got {}; "
+                "set it to 0 to disable it",
+                worker_limits.max_goroutines));
+    }
+    if(worker_limits.gomaxprocs < 0)
+    {
+        errors.push_back(fmt::format(
+                "'worker_limits.gomaxprocs' must not be negative, got {}; "
+                "set it to 0 to keep the go-worker default",
+                worker_limits.gomaxprocs));
+    }
+
+    const std::vector<std::pair<std::string, const std::vector<std::string>*>>
+            selectors = {{"include", &label_selectors.include},
+                         {"exclude", &label_selectors.exclude}};
+    for(const auto& [name, list] : selectors)
+    {
+        if(std::any_of(list->begin(), list->end(),
+                       [](const std::string& s) { return s.empty(); }))
+        {
+            errors.push_back(fmt::format(
+                    "'label_selectors.{}' contains an empty selector", name));
+        }
+    }
+
+    const std::vector<std::string> payload_sections = {
+            "mounts", "env", "labels", "annotations", "healthcheck",
+            "cni_json"};
+    for(const auto& section : payload.exclude)
+    {
+        if(std::find(payload_sections.begin(), payload_sections.end(),
+                     section) == payload_sections.end())
+        {
+            errors.push_back(fmt::format(
+                    "'payload.exclude' contains an unknown section '{}'",
+                    section));
+        }
+    }
+
+    const std::vector<std::pair<std::string, const SocketsEngine*>>
+            sockets_engines = {{"docker", &engines.docker},
+                               {"podman", &engines.podman},
+                               {"cri", &engines.cri},
+                               {"containerd", &engines.containerd},
+                               {"lxc", &engines.lxc},
+                               {"bpm", &engines.bpm},
+                               {"fargate", &engines.fargate},
+                               {"garden", &engines.garden},
+                               {"apptainer", &engines.apptainer}};
+    for(const auto& [name, engine] : sockets_engines)
+    {
+        // Only the engines of container runtime daemons bound each
+        // inspection, and attach to their well-known sockets.
+        const bool daemon = is_daemon_engine(name);
+        if(engine->timeout_ms < 0)
+        {
+            errors.push_back(fmt::format(
+                    "'engines.{}.timeout_ms' must not be negative, got {}; "
+                    "set it to 0 to use 'inspect_timeout_ms'",
+                    name, engine->timeout_ms));
+        }
+        else if(engine->timeout_ms > 0 && !daemon)
+        {
+            errors.push_back(fmt::format(
+                    "'engines.{}.timeout_ms' is only supported by the "
+                    "docker, podman, containerd and cri engines; remove it",
+                    name));
+        }
+        if(engine->list_timeout_ms < 0)
+        {
+            errors.push_back(fmt::format(
+                    "'engines.{}.list_timeout_ms' must not be negative, got "
+                    "{}; set it to 0 to use 'list_timeout_ms'",
+                    name, engine->list_timeout_ms));
+        }
+        if(!engine->enabled)
+        {
+            continue;
+        }
+        for(const auto& socket : engine->sockets)
+        {
+            if(socket.empty())
+            {
+                errors.push_back(fmt::format(
+                        "'engines.{}.sockets' contains an empty socket; "
+                        "remove it, or disable the engine",
+                        name));
+            }
+            else if(socket.rfind("tcp://", 0) == 0 && name != "docker")
+            {
+                errors.push_back(fmt::format(
+                        "'engines.{}.sockets' contains the remote socket "
+                        "'{}', only supported by the docker engine",
+                        name, socket));
+            }
+            else if(socket == AUTO_SOCKET && !daemon)
+            {
+                errors.push_back(fmt::format(
+                        "'engines.{}.sockets' contains '{}', only supported "
+                        "by the docker, podman, containerd and cri engines",
+                        name, socket));
+            }
+        }
+    }
+
+    const auto& tls = engines.docker.tls;
+    if(tls.cert.empty() != tls.key.empty())
+    {
+        errors.push_back(
+                "'engines.docker.tls.cert' and 'engines.docker.tls.key' "
+                "must be set together");
+    }
+    if(tls.enabled() &&
+       std::none_of(engines.docker.sockets.begin(),
+                    engines.docker.sockets.end(), [](const std::string& s)
+                    { return s.rfind("tcp://", 0) == 0; }))
+    {
+        errors.push_back(
+                "'engines.docker.tls' is only used by remote sockets; add "
+                "one to 'engines.docker.sockets', eg: "
+                "'tcp://192.168.1.10:2376'");
+    }
+    for(const auto& [socket, endpoint_tls] : engines.docker.tls_endpoints)
+    {
+        if(endpoint_tls.cert.empty() != endpoint_tls.key.empty())
+        {
+            errors.push_back(fmt::format(
+                    "'engines.docker.tls_endpoints.{}.cert' and "
+                    "'engines.docker.tls_endpoints.{}.key' must be set "
+                    "together",
+                    socket, socket));
+        }
+        if(std::find(engines.docker.sockets.begin(),
+                     engines.docker.sockets.end(),
+                     socket) == engines.docker.sockets.end())
+        {
+            errors.push_back(fmt::format(
+                    "'engines.docker.tls_endpoints' contains '{}', missing "
+                    "from 'engines.docker.sockets'",
+                    socket));
+        }
+    }
+
+    std::string res;
+    for(const auto& err : errors)
+    {
+        if(!res.empty())
+        {
+            res += "; ";
+        }
+        res += err;
+    }
+    return res;
+}
@@ -5,9 +5,47 @@
 #include <falcosecurity/sdk.h>
 
 #define DEFAULT_LABEL_MAX_LEN 100
+#define DEFAULT_LIST_CONCURRENCY 10
+#define DEFAULT_INSPECT_TIMEOUT_MS 5000
+#define DEFAULT_LIST_TIMEOUT_MS 30000
+#define DEFAULT_CACHE_TTL_MS 60000
+#define DEFAULT_CACHE_MAX_ENTRIES 4096
+#define DEFAULT_ENRICH_TIMEOUT_MS 0
+#define DEFAULT_DIGEST_RESOLUTION_TIMEOUT_MS 3000
+#define DEFAULT_DIGEST_RESOLUTION_CACHE_TTL_MS 3600000
+#define DEFAULT_ECS_METADATA_TIMEOUT_MS 2000
+#define DEFAULT_ECS_METADATA_CACHE_TTL_MS 300000
+#define DEFAULT_LIBVIRT_LXC_STATE_DIR "/run/libvirt/lxc"
+#define DEFAULT_NOMAD_ADDRESS "http://127.0.0.1:4646"
+#define DEFAULT_NOMAD_TIMEOUT_MS 2000
+#define DEFAULT_NOMAD_CACHE_TTL_MS 300000
+#define DEFAULT_EVENT_QUEUE_SIZE 1024
+#define DEFAULT_EVENT_QUEUE_POLICY "block"
+#define DEFAULT_CONNECT_RETRY_MAX_BACKOFF_MS 30000
+#define DEFAULT_CIRCUIT_BREAKER_COOLDOWN_MS 30000
+#define DEFAULT_RECONCILE_INTERVAL_MS 0
+#define DEFAULT_STATS_INTERVAL_MS 0
+#define DEFAULT_LOOKUP_TIMEOUT_MS 0
+#define DEFAULT_SIMULATE_CREATE_RATE 10
+#define DEFAULT_SIMULATE_LIFETIME_MS 60000
+#define DEFAULT_SIMULATE_MAX_CONTAINERS 1000
 
 #define HOOK_CREATE 1
 #define HOOK_START 2
+#define HOOK_REMOVE 4
+#define HOOK_DIE 8
+#define HOOK_PAUSE 16
+#define HOOK_HEALTH 32
+#define HOOK_OOM 64
+
+// Sockets entry standing for the well-known sockets of an engine, expanded
+// by the go-worker.
+#define AUTO_SOCKET "auto"
+
+// Named pipes of the windows engines, not prefixed by the host root.
+#define NAMED_PIPE_SCHEME "npipe://"
+#define DOCKER_NAMED_PIPE NAMED_PIPE_SCHEME "//./pipe/docker_engine"
+#define CONTAINERD_NAMED_PIPE NAMED_PIPE_SCHEME "//./pipe/containerd-containerd"
 
 struct SimpleEngine
 {
@@ -16,24 +54,107 @@ struct SimpleEngine
     SimpleEngine() { enabled = true; }
 };
 
+// TLS material of remote engine sockets (ie: docker "tcp://" sockets);
+// ca, cert and key are paths to PEM files.
+struct EngineTLS
+{
+    std::string ca;
+    std::string cert;
+    std::string key;
+    // Name verified against the engine certificate, instead of the socket
+    // host, when not empty.
+    std::string server_name;
+    bool insecure_skip_verify;
+
+    EngineTLS() { insecure_skip_verify = false; }
+
+    bool enabled() const
+    {
+        return !ca.empty() || !cert.empty() || !server_name.empty() ||
+               insecure_skip_verify;
+    }
+};
+
 struct SocketsEngine
 {
     bool enabled;
     std::vector<std::string> sockets;
+    // Timeout of each container inspection, while listing pre-existing
+    // containers and on container events, health checks and resource usage
+    // sampling included; 0 to use the global inspect_timeout_ms. Only
+    // supported by the docker, podman, containerd and cri engines.
+    int timeout_ms;
+    // Deadline of the listing of pre-existing containers; 0 to use the global
+    // list_timeout_ms.
+    int list_timeout_ms;
+    // Overrides the go-worker log_level for the engine, when not empty.
+    std::string log_level;
 
-    SocketsEngine() { enabled = true; }
+    SocketsEngine()
+    {
+        enabled = true;
+        timeout_ms = 0;
+        list_timeout_ms = 0;
+    }
 
     void log_sockets(falcosecurity::logger& logger,
                      const std::string& host_root) const
     {
         for(const auto& socket : sockets)
         {
+            if(socket == AUTO_SOCKET)
+            {
+                logger.log("* enabled container runtime well-known sockets");
+                continue;
+            }
+            if(socket.rfind(NAMED_PIPE_SCHEME, 0) == 0)
+            {
+                logger.log(fmt::format(
+                        "* enabled container runtime named pipe at '{}'",
+                        socket));
+                continue;
+            }
             logger.log(fmt::format("* enabled container runtime socket at '{}'",
                                    host_root + socket));
         }
     }
 };
 
+// Engines of specific environments, disabled by default not to probe their
+// sockets and match their cgroups on every host.
+struct OptInSocketsEngine : SocketsEngine
+{
+    OptInSocketsEngine() { enabled = false; }
+};
+
+struct ContainerdEngine : SocketsEngine
+{
+    // Namespaces to be watched; all namespaces when empty.
+    std::vector<std::string> namespaces;
+};
+
+struct CriEngine : SocketsEngine
+{
+    // Attach to the first responsive socket only, probing them again in
+    // order whenever its events stream ends.
+    bool fallback;
+
+    CriEngine() { fallback = false; }
+};
+
+struct DockerEngine : SocketsEngine
+{
+    // Docker CLI contexts whose endpoints are watched.
+    std::vector<std::string> contexts;
+    // Watch the rootless daemons of all users too.
+    bool rootless;
+    EngineTLS tls;
+    // Overrides tls for specific remote sockets, by socket.
+    std::map<std::string, EngineTLS> tls_endpoints;
+
+    DockerEngine() { rootless = false; }
+};
+
 struct StaticEngine
 {
     bool enabled;
@@ -44,22 +165,274 @@ struct StaticEngine
     StaticEngine() { enabled = false; }
 };
 
+struct LibvirtLxcEngine
+{
+    bool enabled;
+    // libvirt LXC driver state directory, holding live domains status.
+    std::string state_dir;
+
+    LibvirtLxcEngine()
+    {
+        enabled = true;
+        state_dir = DEFAULT_LIBVIRT_LXC_STATE_DIR;
+    }
+};
+
+struct FixtureEngine
+{
+    bool enabled;
+    // Directories holding container metadata json files.
+    std::vector<std::string> dirs;
+
+    FixtureEngine() { enabled = false; }
+};
+
+// Synthetic containers with randomized metadata, for load testing.
+struct SimulateEngine
+{
+    bool enabled;
+    // Containers created per second.
+    int create_rate;
+    // How long containers run before being removed; 0 never removes them.
+    int lifetime_ms;
+    // Max running containers, pausing the creations when reached; 0 is
+    // unbounded.
+    int max_containers;
+    // Seed of the randomized metadata; 0 picks a random one.
+    uint64_t seed;
+
+    SimulateEngine()
+    {
+        enabled = false;
+        create_rate = DEFAULT_SIMULATE_CREATE_RATE;
+        lifetime_ms = DEFAULT_SIMULATE_LIFETIME_MS;
+        max_containers = DEFAULT_SIMULATE_MAX_CONTAINERS;
+        seed = 0;
+    }
+};
+
 struct Engines
 {
-    SimpleEngine bpm;
-    SimpleEngine lxc;
-    SimpleEngine libvirt_lxc;
-    SocketsEngine docker;
+    // Sockets are the instances directories of apptainer users.
+    OptInSocketsEngine apptainer;
+    SocketsEngine bpm;
+    // Sockets are ECS task metadata endpoints.
+    OptInSocketsEngine fargate;
+    OptInSocketsEngine garden;
+    SocketsEngine lxc;
+    LibvirtLxcEngine libvirt_lxc;
+    DockerEngine docker;
     SocketsEngine podman;
-    SocketsEngine cri;
-    SocketsEngine containerd;
+    CriEngine cri;
+    ContainerdEngine containerd;
     StaticEngine static_ctr;
+    FixtureEngine fixture;
+    SimulateEngine simulate;
+
+    // Returns the names of the enabled engines run by the go-worker.
+    std::vector<std::string> enabled_worker_engines() const;
+};
+
+// Returns true for the engines of container runtime daemons, inspecting
+// containers through them and attaching to their well-known sockets.
+bool is_daemon_engine(const std::string& engine);
+
+struct RegistryAuth
+{
+    std::string username;
+    std::string password;
+};
+
+// Lookup of the digest of images only referenced by tag, from their registries.
+struct DigestResolution
+{
+    bool enabled;
+    int timeout_ms;
+    int cache_ttl_ms;
+    // Registries credentials, by registry host.
+    std::map<std::string, RegistryAuth> auths;
+
+    DigestResolution()
+    {
+        enabled = false;
+        timeout_ms = DEFAULT_DIGEST_RESOLUTION_TIMEOUT_MS;
+        cache_ttl_ms = DEFAULT_DIGEST_RESOLUTION_CACHE_TTL_MS;
+    }
+};
+
+// Lookup of the ECS task metadata of containers, from the task metadata
+// endpoint (v4) advertised in their environment.
+struct EcsMetadata
+{
+    bool enabled;
+    int timeout_ms;
+    int cache_ttl_ms;
+
+    EcsMetadata()
+    {
+        enabled = false;
+        timeout_ms = DEFAULT_ECS_METADATA_TIMEOUT_MS;
+        cache_ttl_ms = DEFAULT_ECS_METADATA_CACHE_TTL_MS;
+    }
+};
+
+// Lookup of the allocations of nomad task containers, from the local nomad
+// client API.
+struct NomadConfig
+{
+    bool enabled;
+    std::string address;
+    // ACL token, if ACLs are enabled.
+    std::string token;
+    int timeout_ms;
+    int cache_ttl_ms;
+
+    NomadConfig()
+    {
+        enabled = false;
+        address = DEFAULT_NOMAD_ADDRESS;
+        timeout_ms = DEFAULT_NOMAD_TIMEOUT_MS;
+        cache_ttl_ms = DEFAULT_NOMAD_CACHE_TTL_MS;
+    }
+};
+
+// Reported containers environment variables; patterns are globs matched
+// against variables names.
+struct EnvConfig
+{
+    // When not empty, only matching variables are reported.
+    std::vector<std::string> allowlist;
+    // Matching variables values are redacted.
+    std::vector<std::string> redact;
+};
+
+// Reported CRI containers and pods annotations; patterns are globs matched
+// against annotations keys.
+struct AnnotationsConfig
+{
+    // Only matching annotations are reported; none of them when empty.
+    std::vector<std::string> include;
+};
+
+// Infrastructure containers whose events are not sent, nor cached;
+// patterns are globs.
+struct IgnoreConfig
+{
+    // Matched against the containers images, with and without their tag.
+    std::vector<std::string> images;
+    // Matched against the containers names.
+    std::vector<std::string> names;
+
+    IgnoreConfig()
+    {
+        // Kubernetes pod sandbox (pause) images
+        images = {"registry.k8s.io/pause", "k8s.gcr.io/pause",
+                  "gcr.io/google_containers/pause*",
+                  "mcr.microsoft.com/oss/kubernetes/pause",
+                  "*.dkr.ecr.*.amazonaws.com/eks/pause"};
+    }
+};
+
+// Stops inspecting the containers of engines whose inspections keep timing
+// out, reporting their minimal infos, with a probe inspection every cooldown.
+struct CircuitBreaker
+{
+    // Consecutive timeouts opening the breaker; 0 disables it.
+    int failures;
+    int cooldown_ms;
+
+    CircuitBreaker()
+    {
+        failures = 0;
+        cooldown_ms = DEFAULT_CIRCUIT_BREAKER_COOLDOWN_MS;
+    }
+};
+
+// Bounds of the resources used by the go-worker, so that the enrichment does
+// not compete with the events processing on constrained nodes.
+struct WorkerLimits
+{
+    // Max goroutines, beyond which containers are inspected synchronously;
+    // 0 disables it.
+    int max_goroutines;
+    // GOMAXPROCS override of the go-worker runtime; 0 keeps its default.
+    int gomaxprocs;
+
+    WorkerLimits()
+    {
+        max_goroutines = 0;
+        gomaxprocs = 0;
+    }
+};
+
+// Metadata sections left out of the events sent by the go-worker, trading
+// their completeness against their size.
+struct PayloadConfig
+{
+    // Any of "mounts", "env", "labels", "annotations", "healthcheck" and
+    // "cni_json".
+    std::vector<std::string> exclude;
+    // Whether to add a digest of the full configuration of the containers,
+    // to detect configuration drifts.
+    bool digest;
+
+    PayloadConfig() { digest = false; }
+};
+
+// Reported containers, by their labels (including their pod ones). Each
+// selector is a comma-separated list of requirements, all of them to be
+// satisfied: "key=value", "key!=value", "key" or "!key".
+struct LabelSelectors
+{
+    // When not empty, only containers matching any selector are reported.
+    std::vector<std::string> include;
+    // Containers matching any selector are not reported.
+    std::vector<std::string> exclude;
+};
+
+// Queue of the events sent by the go-worker, waiting to be consumed.
+struct EventQueue
+{
+    int size;
+    // Applied when the queue is full: "block" or "drop_oldest".
+    std::string policy;
+
+    EventQueue()
+    {
+        size = DEFAULT_EVENT_QUEUE_SIZE;
+        policy = DEFAULT_EVENT_QUEUE_POLICY;
+    }
 };
 
 struct PluginConfig
 {
     int label_max_len;
     bool with_size;
+    int list_concurrency;
+    int inspect_timeout_ms;
+    int list_timeout_ms;
+    int cache_ttl_ms;
+    int cache_max_entries;
+    // File the go-worker metadata cache is saved to on close, and reloaded
+    // from on open; not persisted when empty.
+    std::string cache_snapshot_path;
+    int enrich_timeout_ms;
+    DigestResolution digest_resolution;
+    EcsMetadata ecs_metadata;
+    NomadConfig nomad;
+    EnvConfig env;
+    AnnotationsConfig annotations;
+    IgnoreConfig ignore;
+    LabelSelectors label_selectors;
+    bool suppress_pod_sandboxes;
+    PayloadConfig payload;
+    EventQueue event_queue;
+    CircuitBreaker circuit_breaker;
+    WorkerLimits worker_limits;
+    int connect_retry_max_backoff_ms;
+    int reconcile_interval_ms;
+    int stats_interval_ms;
+    int lookup_timeout_ms;
     uint8_t hooks;
     std::string host_root;
     std::string log_level;
@@ -69,6 +442,17 @@ struct PluginConfig
     {
         label_max_len = DEFAULT_LABEL_MAX_LEN;
         with_size = false;
+        list_concurrency = DEFAULT_LIST_CONCURRENCY;
+        inspect_timeout_ms = DEFAULT_INSPECT_TIMEOUT_MS;
+        list_timeout_ms = DEFAULT_LIST_TIMEOUT_MS;
+        cache_ttl_ms = DEFAULT_CACHE_TTL_MS;
+        cache_max_entries = DEFAULT_CACHE_MAX_ENTRIES;
+        enrich_timeout_ms = DEFAULT_ENRICH_TIMEOUT_MS;
+        suppress_pod_sandboxes = false;
+        connect_retry_max_backoff_ms = DEFAULT_CONNECT_RETRY_MAX_BACKOFF_MS;
+        reconcile_interval_ms = DEFAULT_RECONCILE_INTERVAL_MS;
+        stats_interval_ms = DEFAULT_STATS_INTERVAL_MS;
+        lookup_timeout_ms = DEFAULT_LOOKUP_TIMEOUT_MS;
         hooks = HOOK_CREATE;
         log_level = "info";
         if(const char* hroot = std::getenv("HOST_ROOT"))
@@ -77,6 +461,10 @@ struct PluginConfig
         }
     }
 
+    // Returns the errors of a config that is well-formed, but inconsistent,
+    // pointing at the options to be fixed; empty if the config is valid.
+    std::string validate() const;
+
     void log_engines(falcosecurity::logger& logger) const
     {
         if(engines.static_ctr.enabled)
@@ -99,11 +487,24 @@ struct PluginConfig
         {
             logger.log("Enabled 'docker' container engine.");
             engines.docker.log_sockets(logger, host_root);
+            for(const auto& context : engines.docker.contexts)
+            {
+                logger.log(fmt::format("* enabled docker context '{}'",
+                                       context));
+            }
+            if(engines.docker.rootless)
+            {
+                logger.log("* enabled rootless docker daemons");
+            }
         }
         if(engines.cri.enabled)
         {
             logger.log("Enabled 'cri' container engine.");
             engines.cri.log_sockets(logger, host_root);
+            if(engines.cri.fallback)
+            {
+                logger.log("* attaching the first responsive cri socket only");
+            }
         }
         if(engines.containerd.enabled)
         {
@@ -113,14 +514,55 @@ struct PluginConfig
         if(engines.lxc.enabled)
         {
             logger.log("Enabled 'lxc' container engine.");
+            engines.lxc.log_sockets(logger, host_root);
         }
         if(engines.libvirt_lxc.enabled)
         {
             logger.log("Enabled 'libvirt_lxc' container engine.");
+            logger.log(fmt::format("* reading libvirt LXC domains from '{}'",
+                                   host_root + engines.libvirt_lxc.state_dir));
         }
         if(engines.bpm.enabled)
         {
             logger.log("Enabled 'bpm' container engine.");
+            engines.bpm.log_sockets(logger, host_root);
+        }
+        if(engines.fargate.enabled)
+        {
+            logger.log("Enabled 'fargate' container engine.");
+            for(const auto& endpoint : engines.fargate.sockets)
+            {
+                logger.log(fmt::format(
+                        "* enabled ECS task metadata endpoint at '{}'",
+                        endpoint));
+            }
+        }
+        if(engines.garden.enabled)
+        {
+            logger.log("Enabled 'garden' container engine.");
+            engines.garden.log_sockets(logger, host_root);
+        }
+        if(engines.apptainer.enabled)
+        {
+            logger.log("Enabled 'apptainer' container engine.");
+            engines.apptainer.log_sockets(logger, host_root);
+        }
+        if(engines.fixture.enabled)
+        {
+            logger.log("Enabled 'fixture' container engine.");
+            for(const auto& dir : engines.fixture.dirs)
+            {
+                logger.log(fmt::format("* loading container fixtures from '{}'",
+                                       host_root + dir));
+            }
+        }
+        if(engines.simulate.enabled)
+        {
+            logger.log(fmt::format(
+                    "Enabled 'simulate' container engine: creating {} "
+                    "containers per second, running for {}ms, up to {}.",
+                    engines.simulate.create_rate, engines.simulate.lifetime_ms,
+                    engines.simulate.max_containers));
         }
     }
 };
@@ -132,10 +574,40 @@ struct PluginConfig
 void from_json(const nlohmann::json& j, StaticEngine& engine);
 void from_json(const nlohmann::json& j, SimpleEngine& engine);
 void from_json(const nlohmann::json& j, SocketsEngine& engine);
+void from_json(const nlohmann::json& j, OptInSocketsEngine& engine);
+void from_json(const nlohmann::json& j, ContainerdEngine& engine);
+void from_json(const nlohmann::json& j, CriEngine& engine);
+void from_json(const nlohmann::json& j, DockerEngine& engine);
+void from_json(const nlohmann::json& j, FixtureEngine& engine);
+void from_json(const nlohmann::json& j, SimulateEngine& engine);
 void from_json(const nlohmann::json& j, Engines& engines);
+void from_json(const nlohmann::json& j, RegistryAuth& auth);
+void from_json(const nlohmann::json& j, DigestResolution& digest_resolution);
+void from_json(const nlohmann::json& j, EcsMetadata& ecs_metadata);
+void from_json(const nlohmann::json& j, NomadConfig& nomad);
+void from_json(const nlohmann::json& j, EventQueue& event_queue);
+void from_json(const nlohmann::json& j, CircuitBreaker& circuit_breaker);
+void from_json(const nlohmann::json& j, WorkerLimits& worker_limits);
+void from_json(const nlohmann::json& j, EnvConfig& env);
+void from_json(const nlohmann::json& j, AnnotationsConfig& annotations);
+void from_json(const nlohmann::json& j, IgnoreConfig& ignore);
+void from_json(const nlohmann::json& j, LabelSelectors& label_selectors);
+void from_json(const nlohmann::json& j, PayloadConfig& payload);
 void from_json(const nlohmann::json& j, PluginConfig& cfg);
 
 // Build the json object to be passed to the go-worker as init config.
 // See go-worker/engine.go::cfg struct for the format
 void to_json(nlohmann::json& j, const Engines& engines);
+void to_json(nlohmann::json& j, const RegistryAuth& auth);
+void to_json(nlohmann::json& j, const DigestResolution& digest_resolution);
+void to_json(nlohmann::json& j, const EcsMetadata& ecs_metadata);
+void to_json(nlohmann::json& j, const NomadConfig& nomad);
+void to_json(nlohmann::json& j, const EventQueue& event_queue);
+void to_json(nlohmann::json& j, const CircuitBreaker& circuit_breaker);
+void to_json(nlohmann::json& j, const WorkerLimits& worker_limits);
+void to_json(nlohmann::json& j, const EnvConfig& env);
+void to_json(nlohmann::json& j, const AnnotationsConfig& annotations);
+void to_json(nlohmann::json& j, const IgnoreConfig& ignore);
+void to_json(nlohmann::json& j, const LabelSelectors& label_selectors);
+void to_json(nlohmann::json& j, const PayloadConfig& payload);
 void to_json(nlohmann::json& j, const PluginConfig& cfg);
\ No newline at end of file
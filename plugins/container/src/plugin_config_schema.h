@@ -18,16 +18,62 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
       "title": "Inspect containers with size",
       "description": "Inspect containers size where supported."
     },
+    "list_concurrency": {
+      "type": "integer",
+      "minimum": 1,
+      "title": "Listing concurrency",
+      "description": "Max number of containers inspected concurrently while listing pre-existing containers at startup."
+    },
+    "inspect_timeout_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Inspect timeout",
+      "description": "Timeout, in milliseconds, of each container inspection, while listing pre-existing containers at startup and on container events, health checks and resource usage sampling included; 0 means no timeout."
+    },
+    "list_timeout_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Listing deadline",
+      "description": "Deadline, in milliseconds, of the listing of pre-existing containers of each engine at startup; containers not inspected in time are reported with a minimal set of infos. 0 means no deadline."
+    },
+    "cache_ttl_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Metadata cache TTL",
+      "description": "Expiration, in milliseconds, of the go-worker containers metadata cache entries; 0 means entries only get evicted when the cache is full."
+    },
+    "cache_max_entries": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Metadata cache size",
+      "description": "Max number of containers kept in the go-worker metadata cache, evicting the least recently used ones; 0 disables the cache."
+    },
+    "cache_snapshot_path": {
+      "type": "string",
+      "title": "Metadata cache snapshot",
+      "description": "File the go-worker metadata cache is saved to when the plugin closes, and reloaded from when it opens, so that a restart does not inspect again all the running containers, nor lose their metadata while warming up; expired entries are not reloaded. The cache is not persisted when empty."
+    },
+    "enrich_timeout_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Enrichment timeout",
+      "description": "How long, in milliseconds, to wait for a new container inspection before sending its minimal set of infos, followed by a 'container_updated' event once the inspection completes; 0 means always waiting for the inspection."
+    },
     "hooks": {
       "type": "array",
       "items": {
         "enum": [
           "create",
-          "start"
+          "start",
+          "remove",
+          "die",
+          "pause",
+          "health",
+          "oom"
         ]
       },
       "title": "Hooks to be attached.",
-      "description": "Hooks to be attached from the engines SDKs. Some fields are not available in 'create' hook. By default, we only attach 'create' that is guaranteed to be notified before first process starts."
+      "description": "Hooks to be attached from the engines SDKs. Some fields are not available in 'create' hook. By default, we only attach 'create' that is guaranteed to be notified before first process starts. 'remove' and 'die' notify containers removal and termination, through 'container_removed' and 'container_died' events; 'pause' notifies containers being paused and unpaused, through 'container_paused' and 'container_unpaused' events; 'health' notifies docker containers health status changes, through 'container_updated' events; 'oom' notifies docker and containerd containers processes killed by the OOM killer, through 'container_oom_killed' events."
     },
     "log_level": {
       "type": "string",
@@ -41,6 +87,90 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
       "title": "Log level",
       "description": "Log level for the go-worker. Valid values: trace, debug, info, warn, error. Defaults to 'warn'."
     },
+    "digest_resolution": {
+      "$ref": "#/definitions/DigestResolution",
+      "title": "Image digest resolution",
+      "description": "Resolve the digest of images only referenced by tag (eg: freshly pulled images) from their registries."
+    },
+    "ecs_metadata": {
+      "$ref": "#/definitions/EcsMetadata",
+      "title": "ECS task metadata",
+      "description": "Look up the ECS service name of task containers, and refresh their task metadata, from the task metadata endpoint (v4) advertised in their environment; the other 'ecs.*' fields are always reported from the ECS agent labels."
+    },
+    "nomad": {
+      "$ref": "#/definitions/NomadConfig",
+      "title": "Nomad allocations",
+      "description": "Look up the job ID and task group of the containers of nomad allocations, missing from their environment, from the local nomad client API; the allocation ID is always reported from the task drivers environment or labels."
+    },
+    "env": {
+      "$ref": "#/definitions/EnvConfig",
+      "title": "Environment variables",
+      "description": "Restrict and redact the reported containers environment variables, to not leak secrets into events."
+    },
+    "annotations": {
+      "$ref": "#/definitions/AnnotationsConfig",
+      "title": "Annotations",
+      "description": "CRI containers and pods annotations to be reported, eg: the settings of service meshes sidecars injection."
+    },
+    "ignore": {
+      "$ref": "#/definitions/IgnoreConfig",
+      "title": "Ignored containers",
+      "description": "Infrastructure containers whose events are not sent, nor cached, eg: to not churn on pod sandboxes."
+    },
+    "label_selectors": {
+      "$ref": "#/definitions/LabelSelectors",
+      "title": "Label selectors",
+      "description": "Scope the reported containers by their labels, including their pod ones, eg: to only enrich the workloads owned by a team on multi-tenant nodes."
+    },
+    "payload": {
+      "$ref": "#/definitions/PayloadConfig",
+      "title": "Events payload",
+      "description": "Shape the metadata serialized into the events sent to the plugin, trading their completeness against their size and copy cost."
+    },
+    "suppress_pod_sandboxes": {
+      "type": "boolean",
+      "title": "Suppress pod sandboxes",
+      "description": "Do not send events for pod sandbox (pause) containers; their network infos are still reported by their workload containers."
+    },
+    "connect_retry_max_backoff_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Connection retry max backoff",
+      "description": "Max backoff, in milliseconds, between attempts to attach to engines that could not be reached at startup (eg: when Falco starts before the container runtime on boot); attempts keep going in background with an exponential backoff. 0 disables the retries."
+    },
+    "reconcile_interval_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Reconciliation interval",
+      "description": "Interval, in milliseconds, of the periodic listing of the containers of each engine, reconciled with the ones notified by events to repair the drift due to missed events (eg: under load), counted by the n_worker_reconciled metric; 0 disables it."
+    },
+    "stats_interval_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Stats sampling interval",
+      "description": "Interval, in milliseconds, of the sampling of the resource usage (CPU, memory and pids) of the running docker and podman containers, notified through 'container_stats' events; 0 disables it."
+    },
+    "lookup_timeout_ms": {
+      "type": "integer",
+      "minimum": 0,
+      "title": "Synchronous lookup timeout",
+      "description": "Timeout, in milliseconds, of the synchronous lookup of containers whose processes are seen before their metadata; the event processing waits for the lookup, for each unknown container, falling back at an asynchronous fetch on timeout, after which the container is not looked up again for 30 seconds. 0 disables the synchronous lookups."
+    },
+    "circuit_breaker": {
+      "$ref": "#/definitions/CircuitBreaker",
+      "title": "Circuit breaker",
+      "description": "Stop inspecting the containers of engines whose inspections keep timing out (eg: a hung runtime daemon), reporting their minimal infos, counted by the n_circuit_breaker_opened metric; a probe inspection is performed every cooldown, closing the breaker once it succeeds."
+    },
+    "worker_limits": {
+      "$ref": "#/definitions/WorkerLimits",
+      "title": "Worker limits",
+      "description": "Bound the goroutines and CPUs used by the go-worker, so that the enrichment does not compete with the Falco event loop on constrained nodes."
+    },
+    "event_queue": {
+      "$ref": "#/definitions/EventQueue",
+      "title": "Event queue",
+      "description": "Bound the events sent by the go-worker while waiting to be consumed, so that a slow consumer does not make its memory grow unbounded."
+    },
     "engines": {
       "$ref": "#/definitions/Engines",
       "title": "The plugin per-engine configuration",
@@ -48,33 +178,297 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
     }
   },
   "definitions": {
+    "EventQueue": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "size": {
+          "type": "integer",
+          "minimum": 1,
+          "description": "Max number of queued events."
+        },
+        "policy": {
+          "type": "string",
+          "enum": [
+            "block",
+            "drop_oldest"
+          ],
+          "description": "Applied when the queue is full: 'block' makes the engines wait for the consumer, 'drop_oldest' drops the oldest queued container added, updated and stats events (removals and the other lifecycle events are never dropped), counted by the n_worker_events_dropped metric."
+        }
+      },
+      "title": "EventQueue"
+    },
+    "CircuitBreaker": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "failures": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Consecutive timed out inspections of an engine opening its breaker; 0 disables it."
+        },
+        "cooldown_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Time, in milliseconds, between the probe inspections of an engine whose breaker is open."
+        }
+      },
+      "title": "CircuitBreaker"
+    },
+    "WorkerLimits": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "max_goroutines": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Max goroutines of the go-worker; once reached, containers are inspected without spawning further goroutines (ie: as if 'enrich_timeout_ms' was 0) and the fetcher gives up its retries, counted by the n_worker_goroutines_throttled metric. 0 disables it."
+        },
+        "gomaxprocs": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "GOMAXPROCS override of the go-worker runtime, bounding the CPUs running its goroutines at once; 0 keeps the Go runtime default, ie: the available CPUs."
+        }
+      },
+      "title": "WorkerLimits"
+    },
+    "EnvConfig": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "allowlist": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Glob patterns of the reported variables names (eg: 'APP_*'); all variables are reported when empty."
+        },
+        "redact": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Glob patterns of the variables names whose values are redacted (eg: '*PASSWORD*')."
+        }
+      },
+      "title": "EnvConfig"
+    },
+    "AnnotationsConfig": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "include": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Glob patterns of the reported annotations keys (eg: 'sidecar.istio.io/*'), where '*' does not match '/'; no annotation is reported when empty."
+        }
+      },
+      "title": "AnnotationsConfig"
+    },
+    "IgnoreConfig": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "images": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Glob patterns of the ignored containers images, matched with and without their tag (eg: 'registry.k8s.io/pause'). Defaults to the kubernetes pause images; set it to an empty list to not ignore them."
+        },
+        "names": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Glob patterns of the ignored containers names (eg: 'k8s_POD_*')."
+        }
+      },
+      "title": "IgnoreConfig"
+    },
+    "PayloadConfig": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "digest": {
+          "type": "boolean",
+          "description": "Add a digest of the full configuration of the containers (but their runtime state, eg: restarts and health status), computed before any section is excluded, to detect configuration drifts without shipping the full metadata."
+        },
+        "exclude": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "enum": [
+              "mounts",
+              "env",
+              "labels",
+              "annotations",
+              "healthcheck",
+              "cni_json"
+            ]
+          },
+          "description": "Metadata sections left out of the events payload, along with the fields extracted from them; 'labels' keeps the 'io.kubernetes.*' labels, used for the pod metadata, and 'healthcheck' keeps the health status."
+        }
+      },
+      "title": "PayloadConfig"
+    },
+    "LabelSelectors": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "include": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Only containers matching any selector are reported; all containers are reported when empty. Each selector is a comma-separated list of requirements, all of them to be satisfied: 'key=value', 'key!=value', 'key' (the label exists) or '!key' (the label does not exist)."
+        },
+        "exclude": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Containers matching any selector are not reported, with the same syntax of 'include'."
+        }
+      },
+      "title": "LabelSelectors"
+    },
+    "DigestResolution": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each registry lookup; 0 means no timeout."
+        },
+        "cache_ttl_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Expiration, in milliseconds, of resolved digests; 0 means they never expire."
+        },
+        "auths": {
+          "type": "object",
+          "description": "Registries credentials, by registry host (eg: 'docker.io', 'quay.io').",
+          "additionalProperties": {
+            "$ref": "#/definitions/RegistryAuth"
+          }
+        }
+      },
+      "title": "DigestResolution"
+    },
+    "EcsMetadata": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each task metadata lookup; 0 means no timeout."
+        },
+        "cache_ttl_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Expiration, in milliseconds, of the task metadata, shared by the containers of a task; 0 means it never expires."
+        }
+      },
+      "title": "EcsMetadata"
+    },
+    "NomadConfig": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "address": {
+          "type": "string",
+          "description": "Address of the nomad HTTP API, e.g. 'http://127.0.0.1:4646'."
+        },
+        "token": {
+          "type": "string",
+          "description": "ACL token used to read allocations, if ACLs are enabled."
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each allocation lookup; 0 means no timeout."
+        },
+        "cache_ttl_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Expiration, in milliseconds, of the allocations, shared by their containers; 0 means they never expire."
+        }
+      },
+      "title": "NomadConfig"
+    },
+    "RegistryAuth": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "username": {
+          "type": "string"
+        },
+        "password": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "username",
+        "password"
+      ],
+      "title": "RegistryAuth"
+    },
     "Engines": {
       "type": "object",
       "additionalProperties": false,
       "properties": {
         "docker": {
-          "$ref": "#/definitions/SocketsContainer"
+          "$ref": "#/definitions/DockerContainer"
         },
         "podman": {
           "$ref": "#/definitions/SocketsContainer"
         },
         "containerd": {
-          "$ref": "#/definitions/SocketsContainer"
+          "$ref": "#/definitions/ContainerdContainer"
         },
         "cri": {
-          "$ref": "#/definitions/SocketsContainer"
+          "$ref": "#/definitions/CriContainer"
         },
         "lxc": {
-          "$ref": "#/definitions/SimpleContainer"
+          "$ref": "#/definitions/OptionalSocketsContainer"
         },
         "libvirt_lxc": {
-          "$ref": "#/definitions/SimpleContainer"
+          "$ref": "#/definitions/LibvirtLxcContainer"
         },
         "bpm": {
-          "$ref": "#/definitions/SimpleContainer"
+          "$ref": "#/definitions/OptionalSocketsContainer"
+        },
+        "fargate": {
+          "$ref": "#/definitions/OptInSocketsContainer"
+        },
+        "garden": {
+          "$ref": "#/definitions/OptInSocketsContainer"
+        },
+        "apptainer": {
+          "$ref": "#/definitions/OptInSocketsContainer"
         },
         "static": {
           "$ref": "#/definitions/StaticContainer"
+        },
+        "fixture": {
+          "$ref": "#/definitions/FixtureContainer"
+        },
+        "simulate": {
+          "$ref": "#/definitions/SimulateContainer"
         }
       },
       "required": [
@@ -88,6 +482,37 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
       ],
       "title": "Engines"
     },
+    "EngineTLS": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "ca": {
+          "type": "string",
+          "description": "Path of the PEM encoded CA certificate used to verify the engine."
+        },
+        "cert": {
+          "type": "string",
+          "description": "Path of the PEM encoded client certificate; requires 'key'."
+        },
+        "key": {
+          "type": "string",
+          "description": "Path of the PEM encoded client key; requires 'cert'."
+        },
+        "server_name": {
+          "type": "string",
+          "description": "Name verified against the engine certificate (ie: one of its SANs), instead of the socket host."
+        },
+        "insecure_skip_verify": {
+          "type": "boolean",
+          "description": "Do not verify the engine certificate."
+        }
+      },
+      "dependencies": {
+        "cert": ["key"],
+        "key": ["cert"]
+      },
+      "title": "EngineTLS"
+    },
     "nonEmptyString": {
       "type": "string",
       "minLength": 1
@@ -116,7 +541,29 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
           "type": "array",
           "items": {
             "type": "string"
-          }
+          },
+          "description": "Engine sockets, or glob patterns of sockets; 'auto' stands for the well-known sockets of docker, podman, containerd and cri engines, attached when present at startup or later on."
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each container inspection of the engine, while listing pre-existing containers and on container events, health checks and resource usage sampling included; 0 means using 'inspect_timeout_ms'."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
         }
       },
       "required": [
@@ -137,6 +584,271 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
       ],
       "title": "SocketsContainer"
     },
+    "CriContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "sockets": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Engine sockets, or glob patterns of sockets; 'auto' stands for the well-known sockets of docker, podman, containerd and cri engines, attached when present at startup or later on."
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each container inspection of the engine, while listing pre-existing containers and on container events, health checks and resource usage sampling included; 0 means using 'inspect_timeout_ms'."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
+        },
+        "fallback": {
+          "type": "boolean",
+          "description": "Attach to the first responsive socket only, in the specified order, probing them again whenever its events stream ends; eg: list both containerd and CRI-O sockets, like kubelet '--container-runtime-endpoint', for a single configuration to work across nodes running either of them."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "oneOf": [
+        {
+          "properties": {
+            "enabled": { "enum": [true] }
+          },
+          "required": ["enabled", "sockets"]
+        },
+        {
+          "properties": {
+            "enabled": { "enum": [false] }
+          }
+        }
+      ],
+      "title": "CriContainer"
+    },
+    "OptionalSocketsContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "sockets": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "title": "OptionalSocketsContainer"
+    },
+    "OptInSocketsContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean",
+          "default": false,
+          "description": "Engines of specific environments are disabled by default, not to probe their sockets and match their cgroups on every host."
+        },
+        "sockets": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "title": "OptInSocketsContainer"
+    },
+    "ContainerdContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "sockets": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each container inspection of the engine, while listing pre-existing containers and on container events, health checks and resource usage sampling included; 0 means using 'inspect_timeout_ms'."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
+        },
+        "namespaces": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "description": "Containerd namespaces to be watched, eg: ['default', 'k8s.io', 'moby']. All namespaces are watched when empty."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "oneOf": [
+        {
+          "properties": {
+            "enabled": { "enum": [true] }
+          },
+          "required": ["enabled", "sockets"]
+        },
+        {
+          "properties": {
+            "enabled": { "enum": [false] }
+          }
+        }
+      ],
+      "title": "ContainerdContainer"
+    },
+    "DockerContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "sockets": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "log_level": {
+          "type": "string",
+          "enum": [
+            "trace",
+            "debug",
+            "info",
+            "warn",
+            "error"
+          ],
+          "description": "Log level of the engine, overriding the go-worker one; eg: set it to 'debug' to troubleshoot missing metadata of a single engine."
+        },
+        "timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Timeout, in milliseconds, of each container inspection of the engine, while listing pre-existing containers and on container events, health checks and resource usage sampling included; 0 means using 'inspect_timeout_ms'."
+        },
+        "list_timeout_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Deadline, in milliseconds, of the listing of pre-existing containers at startup; 0 means using 'list_timeout_ms'."
+        },
+        "contexts": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/nonEmptyString"
+          },
+          "description": "Docker CLI contexts whose endpoints are watched, as stored under $DOCKER_CONFIG/contexts (or ~/.docker/contexts)."
+        },
+        "rootless": {
+          "type": "boolean",
+          "description": "Watch the rootless docker daemons of all users too, through their $XDG_RUNTIME_DIR/docker.sock sockets (ie: /run/user/<uid>/docker.sock), tagging their containers with the owning uid."
+        },
+        "tls": {
+          "$ref": "#/definitions/EngineTLS",
+          "description": "TLS material of the remote 'tcp://' sockets; PEM files are loaded again on new connections once they change, to follow certificates rotations."
+        },
+        "tls_endpoints": {
+          "type": "object",
+          "additionalProperties": {
+            "$ref": "#/definitions/EngineTLS"
+          },
+          "description": "TLS material of specific remote sockets, by socket, overriding 'tls'."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "oneOf": [
+        {
+          "properties": {
+            "enabled": { "enum": [true] }
+          },
+          "required": ["enabled", "sockets"]
+        },
+        {
+          "properties": {
+            "enabled": { "enum": [false] }
+          }
+        }
+      ],
+      "title": "DockerContainer"
+    },
     "StaticContainer": {
       "type": "object",
       "additionalProperties": false,
@@ -171,6 +883,88 @@ const char plugin_schema_string[] = LONG_STRING_CONST(
         }
       ],
       "title": "StaticContainer"
+    },
+    "LibvirtLxcContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "state_dir": {
+          "$ref": "#/definitions/nonEmptyString",
+          "description": "libvirt LXC driver state directory, holding the live domains status files."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "title": "LibvirtLxcContainer"
+    },
+    "FixtureContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "dirs": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/nonEmptyString"
+          }
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "oneOf": [
+        {
+          "properties": {
+            "enabled": { "enum": [true] }
+          },
+          "required": ["enabled", "dirs"]
+        },
+        {
+          "properties": {
+            "enabled": { "enum": [false] }
+          }
+        }
+      ],
+      "title": "FixtureContainer"
+    },
+    "SimulateContainer": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "type": "boolean"
+        },
+        "create_rate": {
+          "type": "integer",
+          "minimum": 1,
+          "description": "Number of synthetic containers created per second."
+        },
+        "lifetime_ms": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "How long synthetic containers run before being removed; 0 never removes them."
+        },
+        "max_containers": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Max number of running synthetic containers, pausing the creations when reached; 0 is unbounded."
+        },
+        "seed": {
+          "type": "integer",
+          "minimum": 0,
+          "description": "Seed of the randomized metadata, for reproducible runs; 0 picks a random one."
+        }
+      },
+      "required": [
+        "enabled"
+      ],
+      "title": "SimulateContainer"
     }
   },
   "additionalProperties": false,
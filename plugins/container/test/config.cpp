@@ -6,10 +6,13 @@ TEST(plugin_config, from_json)
     std::string config = R"({
   "engines": {
     "bpm": {
-      "enabled": false
+      "enabled": false,
+      "list_timeout_ms": 5000,
+      "log_level": "debug"
     },
     "containerd": {
       "enabled": true,
+      "namespaces": [],
       "sockets": [
         "/run/containerd/containerd.sock"
       ]
@@ -17,17 +20,39 @@ TEST(plugin_config, from_json)
     "cri": {
       "enabled": true,
       "sockets": [
+        "/run/containerd/containerd.sock",
         "/run/crio/crio.sock"
-      ]
+      ],
+      "fallback": true,
+      "timeout_ms": 2000,
+      "list_timeout_ms": 20000,
+      "log_level": "debug"
     },
     "docker": {
       "enabled": true,
       "sockets": [
-        "/var/run/docker.sock"
-      ]
+        "/var/run/docker.sock",
+        "tcp://192.168.1.10:2376"
+      ],
+      "contexts": [
+        "remote"
+      ],
+      "rootless": true,
+      "tls": {
+        "ca": "/etc/docker/ca.pem",
+        "cert": "/etc/docker/cert.pem",
+        "key": "/etc/docker/key.pem"
+      },
+      "tls_endpoints": {
+        "tcp://192.168.1.10:2376": {
+          "ca": "/etc/docker/remote/ca.pem",
+          "server_name": "docker.internal"
+        }
+      }
     },
     "libvirt_lxc": {
-      "enabled": false
+      "enabled": false,
+      "state_dir": "/var/run/libvirt/lxc"
     },
     "podman": {
       "enabled": false,
@@ -35,26 +60,155 @@ TEST(plugin_config, from_json)
         "/run/podman/podman.sock",
         "/run/user/1000/podman/podman.sock"
       ]
+    },
+    "simulate": {
+      "enabled": true,
+      "create_rate": 500,
+      "seed": 42
     }
   },
   "label_max_len": 120,
   "with_size": true,
+  "list_concurrency": 4,
+  "list_timeout_ms": 10000,
+  "cache_max_entries": 0,
+  "enrich_timeout_ms": 200,
+  "digest_resolution": {
+    "enabled": true,
+    "auths": {
+      "quay.io": {
+        "username": "user",
+        "password": "pass"
+      }
+    }
+  },
+  "ecs_metadata": {
+    "enabled": true,
+    "timeout_ms": 500
+  },
+  "nomad": {
+    "enabled": true,
+    "token": "secret"
+  },
+  "env": {
+    "allowlist": ["APP_*"],
+    "redact": ["*_TOKEN"]
+  },
+  "annotations": {
+    "include": ["sidecar.istio.io/*"]
+  },
+  "ignore": {
+    "names": ["k8s_POD_*"]
+  },
+  "label_selectors": {
+    "include": ["team=security"]
+  },
+  "suppress_pod_sandboxes": true,
+  "payload": {
+    "exclude": ["mounts", "cni_json"],
+    "digest": true
+  },
+  "event_queue": {
+    "policy": "drop_oldest"
+  },
+  "circuit_breaker": {
+    "failures": 3
+  },
+  "worker_limits": {
+    "gomaxprocs": 2
+  },
+  "connect_retry_max_backoff_ms": 0,
+  "reconcile_interval_ms": 300000,
+  "stats_interval_ms": 10000,
+  "lookup_timeout_ms": 100,
   "hooks": ["start"]
 })";
     auto config_json = nlohmann::json::parse(config);
 
     auto cfg = config_json.get<PluginConfig>();
     EXPECT_TRUE(cfg.engines.cri.enabled);
+    EXPECT_TRUE(cfg.engines.cri.fallback);
+    EXPECT_EQ(cfg.engines.cri.sockets,
+              (std::vector<std::string>{"/run/containerd/containerd.sock",
+                                        "/run/crio/crio.sock"}));
     EXPECT_TRUE(cfg.engines.docker.enabled);
+    EXPECT_EQ(cfg.engines.docker.contexts,
+              std::vector<std::string>{"remote"});
+    EXPECT_TRUE(cfg.engines.docker.rootless);
+    EXPECT_EQ(cfg.engines.docker.tls.ca, "/etc/docker/ca.pem");
+    EXPECT_EQ(cfg.engines.docker.tls.key, "/etc/docker/key.pem");
+    EXPECT_FALSE(cfg.engines.docker.tls.insecure_skip_verify);
+    EXPECT_EQ(cfg.engines.docker.tls.server_name, "");
+    const auto& endpoint_tls =
+            cfg.engines.docker.tls_endpoints.at("tcp://192.168.1.10:2376");
+    EXPECT_EQ(endpoint_tls.ca, "/etc/docker/remote/ca.pem");
+    EXPECT_EQ(endpoint_tls.server_name, "docker.internal");
+    EXPECT_EQ(cfg.engines.docker.timeout_ms, 0);
+    EXPECT_EQ(cfg.engines.cri.timeout_ms, 2000);
+    EXPECT_EQ(cfg.engines.cri.list_timeout_ms, 20000);
+    EXPECT_EQ(cfg.engines.docker.list_timeout_ms, 0);
+    EXPECT_EQ(cfg.engines.cri.log_level, "debug");
+    EXPECT_EQ(cfg.engines.docker.log_level, "");
+    EXPECT_EQ(cfg.validate(), "");
     EXPECT_TRUE(cfg.engines.containerd.enabled);
     EXPECT_TRUE(cfg.engines.lxc.enabled); // missing defaults to enabled
 
     EXPECT_FALSE(cfg.engines.podman.enabled);
     EXPECT_FALSE(cfg.engines.libvirt_lxc.enabled);
+    EXPECT_EQ(cfg.engines.libvirt_lxc.state_dir, "/var/run/libvirt/lxc");
     EXPECT_FALSE(cfg.engines.bpm.enabled);
+    EXPECT_EQ(cfg.engines.bpm.list_timeout_ms, 5000);
+    EXPECT_EQ(cfg.engines.bpm.log_level, "debug");
+    EXPECT_TRUE(cfg.engines.simulate.enabled);
+    EXPECT_EQ(cfg.engines.simulate.create_rate, 500);
+    EXPECT_EQ(cfg.engines.simulate.lifetime_ms, DEFAULT_SIMULATE_LIFETIME_MS);
+    EXPECT_EQ(cfg.engines.simulate.max_containers,
+              DEFAULT_SIMULATE_MAX_CONTAINERS);
+    EXPECT_EQ(cfg.engines.simulate.seed, 42);
 
     EXPECT_TRUE(cfg.with_size);
     EXPECT_EQ(cfg.label_max_len, 120);
+    EXPECT_EQ(cfg.list_concurrency, 4);
+    EXPECT_EQ(cfg.inspect_timeout_ms, DEFAULT_INSPECT_TIMEOUT_MS);
+    EXPECT_EQ(cfg.list_timeout_ms, 10000);
+    EXPECT_EQ(cfg.cache_ttl_ms, DEFAULT_CACHE_TTL_MS);
+    EXPECT_EQ(cfg.cache_max_entries, 0);
+    EXPECT_EQ(cfg.enrich_timeout_ms, 200);
+    EXPECT_TRUE(cfg.digest_resolution.enabled);
+    EXPECT_EQ(cfg.digest_resolution.timeout_ms,
+              DEFAULT_DIGEST_RESOLUTION_TIMEOUT_MS);
+    EXPECT_EQ(cfg.digest_resolution.auths["quay.io"].username, "user");
+    EXPECT_TRUE(cfg.ecs_metadata.enabled);
+    EXPECT_EQ(cfg.ecs_metadata.timeout_ms, 500);
+    EXPECT_EQ(cfg.ecs_metadata.cache_ttl_ms,
+              DEFAULT_ECS_METADATA_CACHE_TTL_MS);
+    EXPECT_TRUE(cfg.nomad.enabled);
+    EXPECT_EQ(cfg.nomad.address, DEFAULT_NOMAD_ADDRESS);
+    EXPECT_EQ(cfg.nomad.token, "secret");
+    EXPECT_EQ(cfg.env.allowlist, std::vector<std::string>{"APP_*"});
+    EXPECT_EQ(cfg.env.redact, std::vector<std::string>{"*_TOKEN"});
+    EXPECT_EQ(cfg.annotations.include,
+              std::vector<std::string>{"sidecar.istio.io/*"});
+    EXPECT_EQ(cfg.ignore.images, IgnoreConfig{}.images);
+    EXPECT_EQ(cfg.ignore.names, std::vector<std::string>{"k8s_POD_*"});
+    EXPECT_EQ(cfg.label_selectors.include,
+              std::vector<std::string>{"team=security"});
+    EXPECT_TRUE(cfg.label_selectors.exclude.empty());
+    EXPECT_TRUE(cfg.suppress_pod_sandboxes);
+    EXPECT_EQ(cfg.payload.exclude,
+              (std::vector<std::string>{"mounts", "cni_json"}));
+    EXPECT_TRUE(cfg.payload.digest);
+    EXPECT_EQ(cfg.event_queue.size, DEFAULT_EVENT_QUEUE_SIZE);
+    EXPECT_EQ(cfg.event_queue.policy, "drop_oldest");
+    EXPECT_EQ(cfg.circuit_breaker.failures, 3);
+    EXPECT_EQ(cfg.circuit_breaker.cooldown_ms,
+              DEFAULT_CIRCUIT_BREAKER_COOLDOWN_MS);
+    EXPECT_EQ(cfg.worker_limits.gomaxprocs, 2);
+    EXPECT_EQ(cfg.worker_limits.max_goroutines, 0);
+    EXPECT_EQ(cfg.connect_retry_max_backoff_ms, 0);
+    EXPECT_EQ(cfg.reconcile_interval_ms, 300000);
+    EXPECT_EQ(cfg.stats_interval_ms, 10000);
+    EXPECT_EQ(cfg.lookup_timeout_ms, 100);
     EXPECT_EQ(cfg.hooks, HOOK_START);
 }
 
@@ -82,6 +236,18 @@ TEST(plugin_config, from_json_missing_engines)
     EXPECT_EQ(cfg.hooks, HOOK_CREATE);
 }
 
+TEST(plugin_config, from_json_hooks)
+{
+    std::string config = R"({
+  "hooks": ["create", "remove", "die", "pause", "health", "oom"]
+})";
+    auto config_json = nlohmann::json::parse(config);
+
+    auto cfg = config_json.get<PluginConfig>();
+    EXPECT_EQ(cfg.hooks, HOOK_CREATE | HOOK_REMOVE | HOOK_DIE | HOOK_PAUSE |
+                                 HOOK_HEALTH | HOOK_OOM);
+}
+
 TEST(plugin_config, from_json_empty_json)
 {
     std::string config = R"({})";
@@ -97,47 +263,293 @@ TEST(plugin_config, from_json_empty_json)
     EXPECT_TRUE(cfg.engines.podman.enabled);
     EXPECT_TRUE(cfg.engines.libvirt_lxc.enabled);
     EXPECT_TRUE(cfg.engines.bpm.enabled);
+    EXPECT_FALSE(cfg.engines.fargate.enabled);
+    EXPECT_FALSE(cfg.engines.garden.enabled);
+    EXPECT_FALSE(cfg.engines.apptainer.enabled);
 
     EXPECT_FALSE(cfg.with_size);
     EXPECT_EQ(cfg.label_max_len, DEFAULT_LABEL_MAX_LEN);
+    EXPECT_FALSE(cfg.suppress_pod_sandboxes);
+    EXPECT_EQ(cfg.event_queue.policy, DEFAULT_EVENT_QUEUE_POLICY);
     EXPECT_EQ(cfg.hooks, HOOK_CREATE);
 }
 
+TEST(plugin_config, enabled_worker_engines)
+{
+    auto cfg = nlohmann::json::parse(R"({
+  "engines": {
+    "docker": {"enabled": false},
+    "garden": {"enabled": true},
+    "simulate": {"enabled": true}
+  }
+})")
+                       .get<PluginConfig>();
+    EXPECT_EQ(cfg.engines.enabled_worker_engines(),
+              (std::vector<std::string>{"podman", "containerd", "cri", "lxc",
+                                        "libvirt_lxc", "bpm", "garden",
+                                        "simulate"}));
+}
+
+TEST(plugin_config, validate)
+{
+    auto cfg = nlohmann::json::parse("{}").get<PluginConfig>();
+    EXPECT_EQ(cfg.validate(), "");
+
+    cfg.engines.docker.tls.cert = "/etc/docker/cert.pem";
+    EXPECT_EQ(cfg.validate(),
+              "'engines.docker.tls.cert' and 'engines.docker.tls.key' must "
+              "be set together; 'engines.docker.tls' is only used by remote "
+              "sockets; add one to 'engines.docker.sockets', eg: "
+              "'tcp://192.168.1.10:2376'");
+
+    cfg.engines.docker.tls.key = "/etc/docker/key.pem";
+    cfg.engines.docker.sockets.emplace_back("tcp://192.168.1.10:2376");
+    EXPECT_EQ(cfg.validate(), "");
+
+    cfg.list_concurrency = 0;
+    cfg.engines.cri.timeout_ms = -1;
+    cfg.engines.podman.sockets.emplace_back("tcp://192.168.1.10:8080");
+    EXPECT_EQ(cfg.validate(),
+              "'list_concurrency' must be at least 1, got 0; "
+              "'engines.podman.sockets' contains the remote socket "
+              "'tcp://192.168.1.10:8080', only supported by the docker "
+              "engine; 'engines.cri.timeout_ms' must not be negative, got -1; "
+              "set it to 0 to use 'inspect_timeout_ms'");
+
+    // Disabled engines sockets are not checked
+    cfg.list_concurrency = 1;
+    cfg.engines.cri.timeout_ms = 0;
+    cfg.engines.podman.enabled = false;
+    EXPECT_EQ(cfg.validate(), "");
+
+    cfg.event_queue.size = 0;
+    cfg.event_queue.policy = "drop_newest";
+    EXPECT_EQ(cfg.validate(),
+              "'event_queue.size' must be at least 1, got 0; "
+              "'event_queue.policy' must be 'block' or 'drop_oldest', got "
+              "'drop_newest'");
+
+    cfg.event_queue = EventQueue{};
+    cfg.label_selectors.exclude.emplace_back("");
+    EXPECT_EQ(cfg.validate(),
+              "'label_selectors.exclude' contains an empty selector");
+
+    cfg.label_selectors = LabelSelectors{};
+    cfg.engines.cri.sockets = {AUTO_SOCKET};
+    cfg.engines.lxc.sockets = {AUTO_SOCKET};
+    EXPECT_EQ(cfg.validate(),
+              "'engines.lxc.sockets' contains 'auto', only supported by the "
+              "docker, podman, containerd and cri engines");
+
+    cfg.engines.lxc.sockets.clear();
+    cfg.engines.garden.timeout_ms = 2000;
+    EXPECT_EQ(cfg.validate(),
+              "'engines.garden.timeout_ms' is only supported by the docker, "
+              "podman, containerd and cri engines; remove it");
+
+    cfg.engines.garden.timeout_ms = 0;
+    cfg.engines.docker.tls_endpoints["tcp://192.168.1.11:2376"].cert =
+            "/etc/docker/cert.pem";
+    EXPECT_EQ(cfg.validate(),
+              "'engines.docker.tls_endpoints.tcp://192.168.1.11:2376.cert' "
+              "and 'engines.docker.tls_endpoints.tcp://192.168.1.11:2376.key' "
+              "must be set together; 'engines.docker.tls_endpoints' contains "
+              "'tcp://192.168.1.11:2376', missing from "
+              "'engines.docker.sockets'");
+
+    cfg.engines.docker.tls_endpoints.clear();
+    cfg.payload.exclude = {"mounts", "hostconfig"};
+    EXPECT_EQ(cfg.validate(),
+              "'payload.exclude' contains an unknown section 'hostconfig'");
+
+    cfg.payload = PayloadConfig{};
+    cfg.worker_limits.gomaxprocs = -1;
+    EXPECT_EQ(cfg.validate(),
+              "'worker_limits.gomaxprocs' must not be negative, got -1; set "
+              "it to 0 to keep the go-worker default");
+
+    cfg.worker_limits = WorkerLimits{};
+    cfg.cache_snapshot_path = "/var/lib/falco/container-cache.json";
+    EXPECT_EQ(cfg.validate(), "");
+    cfg.cache_max_entries = 0;
+    EXPECT_EQ(cfg.validate(),
+              "'cache_snapshot_path' requires the metadata cache; set "
+              "'cache_max_entries' to at least 1");
+}
+
 TEST(plugin_config, to_json)
 {
     std::string expected_config = R"({
+  "annotations": {
+    "include": []
+  },
+  "cache_max_entries": 4096,
+  "cache_snapshot_path": "",
+  "cache_ttl_ms": 60000,
+  "circuit_breaker": {
+    "cooldown_ms": 30000,
+    "failures": 0
+  },
+  "connect_retry_max_backoff_ms": 30000,
+  "digest_resolution": {
+    "auths": {},
+    "cache_ttl_ms": 3600000,
+    "enabled": false,
+    "timeout_ms": 3000
+  },
+  "ecs_metadata": {
+    "cache_ttl_ms": 300000,
+    "enabled": false,
+    "timeout_ms": 2000
+  },
   "engines": {
+    "apptainer": {
+      "enabled": false,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "sockets": []
+    },
+    "bpm": {
+      "enabled": true,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "sockets": []
+    },
     "containerd": {
       "enabled": true,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "namespaces": [],
       "sockets": [
         "/run/containerd/containerd.sock"
-      ]
+      ],
+      "timeout_ms": 0
     },
     "cri": {
       "enabled": true,
+      "fallback": false,
+      "list_timeout_ms": 0,
+      "log_level": "",
       "sockets": [
         "/run/crio/crio.sock"
-      ]
+      ],
+      "timeout_ms": 0
     },
     "docker": {
+      "contexts": [],
       "enabled": true,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "rootless": false,
       "sockets": [
         "/var/run/docker.sock"
+      ],
+      "timeout_ms": 0,
+      "tls": {
+        "ca": "",
+        "cert": "",
+        "insecure_skip_verify": false,
+        "key": "",
+        "server_name": ""
+      },
+      "tls_endpoints": {}
+    },
+    "fargate": {
+      "enabled": false,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "sockets": []
+    },
+    "fixture": {
+      "enabled": false,
+      "sockets": []
+    },
+    "garden": {
+      "enabled": false,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "sockets": []
+    },
+    "libvirt_lxc": {
+      "enabled": true,
+      "sockets": [
+        "/run/libvirt/lxc"
       ]
     },
+    "lxc": {
+      "enabled": true,
+      "list_timeout_ms": 0,
+      "log_level": "",
+      "sockets": []
+    },
     "podman": {
       "enabled": false,
+      "list_timeout_ms": 0,
+      "log_level": "",
       "sockets": [
         "/run/podman/podman.sock",
         "/run/user/1000/podman/podman.sock"
-      ]
+      ],
+      "timeout_ms": 0
+    },
+    "simulate": {
+      "create_rate": 10,
+      "enabled": false,
+      "lifetime_ms": 60000,
+      "max_containers": 1000,
+      "seed": 0
     }
   },
+  "enrich_timeout_ms": 0,
+  "env": {
+    "allowlist": [],
+    "redact": []
+  },
+  "event_queue": {
+    "policy": "block",
+    "size": 1024
+  },
   "hooks": 3,
   "host_root": "",
+  "ignore": {
+    "images": [
+      "registry.k8s.io/pause",
+      "k8s.gcr.io/pause",
+      "gcr.io/google_containers/pause*",
+      "mcr.microsoft.com/oss/kubernetes/pause",
+      "*.dkr.ecr.*.amazonaws.com/eks/pause"
+    ],
+    "names": []
+  },
+  "inspect_timeout_ms": 5000,
   "label_max_len": 120,
+  "label_selectors": {
+    "exclude": [],
+    "include": []
+  },
+  "list_concurrency": 10,
+  "list_timeout_ms": 30000,
   "log_level": "trace",
-  "with_size": true
+  "lookup_timeout_ms": 0,
+  "nomad": {
+    "address": "http://127.0.0.1:4646",
+    "cache_ttl_ms": 300000,
+    "enabled": false,
+    "timeout_ms": 2000,
+    "token": ""
+  },
+  "payload": {
+    "digest": false,
+    "exclude": []
+  },
+  "reconcile_interval_ms": 0,
+  "stats_interval_ms": 0,
+  "suppress_pod_sandboxes": false,
+  "with_size": true,
+  "worker_limits": {
+    "gomaxprocs": 0,
+    "max_goroutines": 0
+  }
 })";
     auto cfg = PluginConfig{};
     cfg.engines.cri.enabled = true;
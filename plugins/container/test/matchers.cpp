@@ -29,6 +29,16 @@ test_name_generator(const testing::TestParamInfo<matchers_test_case>& info)
     return info.param.name;
 }
 
+// Engines with the opt-in ones enabled too, to test all the matchers.
+static Engines all_engines()
+{
+    Engines engines;
+    engines.fargate.enabled = true;
+    engines.garden.enabled = true;
+    engines.apptainer.enabled = true;
+    return engines;
+}
+
 // Parametrized test for detect_podman
 class matchers_test : public testing::TestWithParam<matchers_test_case>
 {
@@ -36,7 +46,7 @@ class matchers_test : public testing::TestWithParam<matchers_test_case>
     matcher_manager m_mgr;
 
     public:
-    matchers_test(): m_mgr(Engines{}) {}
+    matchers_test(): m_mgr(all_engines()) {}
 };
 
 TEST_P(matchers_test, detect_container_id)
@@ -251,5 +261,62 @@ INSTANTIATE_TEST_SUITE_P(
                 {.name = "containerd_id_with_special_chars",
                  .cgroup = "/k8s.io/my-test_container",
                  .expected_container_id = "my-test_cont",
-                 .should_match = true}}),
+                 .should_match = true},
+                // ECS task containers on Fargate, not truncated
+                {.name = "fargate",
+                 .cgroup = "/ecs/158d1c8083dd49d6b527399fd6414f5c/"
+                           "158d1c8083dd49d6b527399fd6414f5c-2495160603",
+                 .expected_container_id =
+                         "158d1c8083dd49d6b527399fd6414f5c-2495160603",
+                 .should_match = true},
+                {.name = "fargate_other_task",
+                 .cgroup = "/ecs/158d1c8083dd49d6b527399fd6414f5c/"
+                           "0b5f7d0e0ad84b5d9a5b1bd4a9c3e8f2-2495160603",
+                 .should_match = false},
+                // ECS task containers on EC2, run by docker
+                {.name = "ecs_docker",
+                 .cgroup = "/ecs/158d1c8083dd49d6b527399fd6414f5c/"
+                           "1234567890abcdef1234567890abcdef1234567890abcdef123"
+                           "4567890abcdef",
+                 .expected_container_id = "1234567890ab",
+                 .should_match = true},
+                // Cloud Foundry Diego cells containers, not truncated
+                {.name = "garden",
+                 .cgroup = "/garden/9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+                 .expected_container_id = "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+                 .should_match = true},
+                {.name = "garden_nested",
+                 .cgroup = "/garden/9a4b8c2d-1e3f-4a5b-6c7d-8e9f/init",
+                 .expected_container_id = "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+                 .should_match = true},
+                {.name = "garden_root",
+                 .cgroup = "/garden/",
+                 .should_match = false},
+                // Garden run by other tools, eg: Concourse workers
+                {.name = "garden_not_cgroups_root",
+                 .cgroup = "/docker/1234567890abcdef1234567890abcdef1234567890"
+                           "abcdef1234567890abcdef/garden/"
+                           "9a4b8c2d-1e3f-4a5b-6c7d-8e9f",
+                 .should_match = false},
+                // Apptainer and Singularity instances, named after their pid
+                {.name = "apptainer_cgroupfs",
+                 .cgroup = "/apptainer/4242",
+                 .expected_container_id = "4242",
+                 .should_match = true},
+                {.name = "apptainer_systemd",
+                 .cgroup = "/user.slice/user-1000.slice/user@1000.service/"
+                           "user.slice/apptainer-4242.scope",
+                 .expected_container_id = "4242",
+                 .should_match = true},
+                {.name = "singularity_cgroupfs",
+                 .cgroup = "/singularity/4242",
+                 .expected_container_id = "4242",
+                 .should_match = true},
+                {.name = "singularity_systemd",
+                 .cgroup = "/system.slice/singularity-4242.scope",
+                 .expected_container_id = "4242",
+                 .should_match = true},
+                {.name = "apptainer_service",
+                 .cgroup = "/system.slice/apptainer-4242.service",
+                 .should_match = false}}),
         test_name_generator);
@@ -44,6 +44,15 @@ static const std::string INIT_CONFIG_STR = R"({
         "bpm": {
             "enabled": false
         },
+        "fargate": {
+            "enabled": false
+        },
+        "garden": {
+            "enabled": false
+        },
+        "apptainer": {
+            "enabled": false
+        },
         "lxc": {
             "enabled": false
         },
@@ -76,6 +85,15 @@ static const std::string INIT_CONFIG_DOCKER_ENABLED_STR = R"({
         "bpm": {
             "enabled": false
         },
+        "fargate": {
+            "enabled": false
+        },
+        "garden": {
+            "enabled": false
+        },
+        "apptainer": {
+            "enabled": false
+        },
         "lxc": {
             "enabled": false
         },
@@ -177,21 +195,99 @@ static const char* TEST_CONTAINER_JSON = R"({
         "imageid": "sha256:a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
         "imagerepo": "nginx",
         "imagetag": "1.25-alpine",
+        "imageregistry": "docker.io",
+        "image_size": 48234567,
+        "image_layers": 7,
+        "image_pulled_at": 1699999970123456789,
+        "size": 1048576,
+        "snapshotter": "overlayfs",
+        "log_path": "/var/log/pods/web_nginx_5e1a0a3c/nginx/0.log",
+        "engine_socket": "/var/run/docker.sock",
         "imagedigest": "sha256:a8758716bb6a",
         "privileged": true,
+        "runtime": "io.containerd.kata.v2",
+        "sandboxed_runtime": true,
+        "cgroup_path": "/kubepods.slice/cri-containerd-abc.scope",
+        "cgroup_driver": "systemd",
+        "rootless_uid": 1000,
+        "config_digest": "sha256:9f86d081884c7d65",
+        "restart_count": 3,
+        "created_at": 1700000000123456789,
+        "started_at": 1700000001987654321,
+        "cap_add": ["CAP_NET_ADMIN", "CAP_SYS_PTRACE"],
+        "gpu_devices": ["0", "nvidia.com/gpu=1"],
+        "cap_drop": ["CAP_MKNOD"],
+        "seccomp_profile": "unconfined",
+        "apparmor_profile": "docker-default",
+        "no_new_privileges": true,
+        "User": "101:101",
+        "user_ids": "101:101",
+        "pod_name": "nginx-7c5ddbdf54-2xkqv",
+        "pod_namespace": "web",
+        "pod_uid": "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e",
+        "k8s_container_name": "nginx",
+        "k8s_workload_kind": "Deployment",
+        "k8s_workload_name": "nginx",
+        "compose_project": "shop",
+        "Healthcheck": {"exe": "/bin/sh", "args": ["-c", "curl -f http://localhost/"], "interval": 30000000000, "timeout": 5000000000, "retries": 3},
+        "compose_service": "web",
+        "compose_container_number": 2,
+        "pod_sandbox_netns": "/var/run/netns/cni-1a2b3c4d",
+        "ecs_task_arn": "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+        "ecs_cluster": "default",
+        "ecs_service_name": "web-svc",
+        "ecs_task_family": "web",
+        "ecs_task_revision": "7",
+        "nomad_alloc_id": "5b3c1f4e-9d2a-4e8b-a3f7-1c2d3e4f5a6b",
+        "nomad_job_id": "web",
+        "nomad_task_group": "frontend",
+        "cf_app_guid": "7c2d1f5e-3a4b-4c6d-8e9f-0a1b2c3d4e5f",
+        "cf_space_guid": "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+        "cf_org_guid": "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a",
+        "userns": true,
+        "uid_mappings": [{"containerID": 0, "hostID": 100000, "size": 65536}],
+        "gid_mappings": [{"containerID": 0, "hostID": 100000, "size": 65536}],
         "labels": {
             "app": "webserver",
             "env": "testing",
             "version": "1.0"
         },
+        "annotations": {"sidecar.istio.io/inject": "false"},
+        "pod_annotations": {
+            "sidecar.istio.io/inject": "true",
+            "sidecar.istio.io/proxyCPU": "100m"
+        },
         "ip": "172.17.0.5",
+        "networks": [
+            {"name": "bridge", "ip": "172.17.0.5", "mac": "02:42:ac:11:00:05", "gateway": "172.17.0.1"},
+            {"name": "frontend", "ip": "172.18.0.3", "ipv6": "fd00:18::3", "gateway": "172.18.0.1", "aliases": ["web", "nginx"]},
+            {"name": "frontend_internal", "ip": "172.19.0.4", "aliases": ["web"]}
+        ],
         "created_time": 1700000000,
-        "Mounts": [],
-        "env": [],
+        "Mounts": [
+            {
+                "Source": "/var/run/docker.sock",
+                "Destination": "/var/run/docker.sock",
+                "Mode": "",
+                "RW": true,
+                "Propagation": "rprivate",
+                "Type": "bind"
+            },
+            {
+                "Source": "/var/lib/docker/volumes/html/_data",
+                "Destination": "/usr/share/nginx/html",
+                "Mode": "z",
+                "RW": false,
+                "Propagation": "",
+                "Type": "volume",
+                "Name": "html"
+            }
+        ],
+        "env": ["DEPLOYMENT_ID=42", "APP_DB_PASSWORD=<redacted>"],
         "port_mappings": [],
         "memory_limit": 536870912,
         "cpu_shares": 1024,
-        "cpu_quota": 0,
+        "cpu_quota": 150000,
         "cpu_period": 100000
     }
 })";
@@ -276,6 +372,17 @@ TEST_F(sinsp_with_test_input,
     std::string env_label =
             get_field_as_string(evt, "container.label[env]", pl_flist);
     ASSERT_EQ(env_label, "testing");
+
+    ASSERT_EQ(get_field_as_string(
+                      evt, "container.annotation[sidecar.istio.io/inject]",
+                      pl_flist),
+              "false");
+    ASSERT_EQ(get_field_as_string(
+                      evt, "k8s.pod.annotation[sidecar.istio.io/inject]",
+                      pl_flist),
+              "true");
+    ASSERT_EQ(get_field_as_string(evt, "k8s.pod.annotations", pl_flist),
+              "sidecar.istio.io/inject:true, sidecar.istio.io/proxyCPU:100m");
 }
 
 TEST_F(sinsp_with_test_input, plugin_container_extract_multiple_containers)
@@ -394,8 +501,198 @@ TEST_F(sinsp_with_test_input, plugin_container_extract_on_async_event)
     // they try to read from the uninitialized thread_entry.
     ASSERT_EQ(get_field_as_string(async_evt, "container.id", pl_flist),
               "abc123def456");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.full_id", pl_flist),
+              "abc123def4567890123456789012345678901234567890123456789012345678");
+    // Only reported for CRI containers
+    ASSERT_FALSE(
+            field_has_value(async_evt, "container.runtime_id", pl_flist));
     ASSERT_EQ(get_field_as_string(async_evt, "container.name", pl_flist),
               "test-nginx-container");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.image.registry",
+                                  pl_flist),
+              "docker.io");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.image.size", pl_flist),
+              "48234567");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.image.layers", pl_flist),
+            "7");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.image.pulled", pl_flist),
+            "true");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.image.pulled_ts",
+                                  pl_flist),
+              "1699999970123456789");
+    // Pulled 30 seconds before the container creation
+    ASSERT_EQ(get_field_as_string(async_evt, "container.image.pull_age",
+                                  pl_flist),
+              "30000000000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.size_rw", pl_flist),
+              "1048576");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.snapshotter", pl_flist),
+              "overlayfs");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.log_path", pl_flist),
+              "/var/log/pods/web_nginx_5e1a0a3c/nginx/0.log");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.socket", pl_flist),
+              "/var/run/docker.sock");
+    ASSERT_EQ(get_field_as_string(async_evt,
+                                  "container.mount.type[/var/run/docker.sock]",
+                                  pl_flist),
+              "bind");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.mount.type[1]",
+                                  pl_flist),
+              "volume");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.mount.name[1]",
+                                  pl_flist),
+              "html");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.mount[0]", pl_flist),
+              "/var/run/docker.sock:/var/run/docker.sock::true:rprivate");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.networks", pl_flist),
+              "bridge,frontend,frontend_internal");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.ips", pl_flist),
+              "172.17.0.5,172.18.0.3,fd00:18::3,172.19.0.4");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.network.ip[frontend]",
+                                  pl_flist),
+              "172.18.0.3");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.network.mac[0]",
+                                  pl_flist),
+              "02:42:ac:11:00:05");
+    ASSERT_EQ(get_field_as_string(async_evt,
+                                  "container.network.gateway[frontend]",
+                                  pl_flist),
+              "172.18.0.1");
+    ASSERT_FALSE(field_has_value(async_evt, "container.network.ip[unknown]",
+                                 pl_flist));
+    ASSERT_EQ(get_field_as_string(async_evt,
+                                  "container.network.aliases[frontend]",
+                                  pl_flist),
+              "web,nginx");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.dns_names", pl_flist),
+              "web,nginx");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.workload.kind", pl_flist),
+              "Deployment");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.workload.name", pl_flist),
+              "nginx");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.healthcheck.cmd",
+                                  pl_flist),
+              "/bin/sh -c curl -f http://localhost/");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.healthcheck.interval",
+                                  pl_flist),
+              "30000000000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.healthcheck.timeout",
+                                  pl_flist),
+              "5000000000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.healthcheck.retries",
+                                  pl_flist),
+              "3");
+    ASSERT_EQ(get_field_as_string(async_evt, "compose.project", pl_flist),
+              "shop");
+    ASSERT_EQ(get_field_as_string(async_evt, "compose.service", pl_flist),
+              "web");
+    ASSERT_EQ(get_field_as_string(async_evt, "compose.container_number",
+                                  pl_flist),
+              "2");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.env[DEPLOYMENT_ID]",
+                                  pl_flist),
+              "42");
+    ASSERT_FALSE(field_has_value(async_evt, "container.env[DEPLOYMENT]",
+                                 pl_flist));
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cap_add", pl_flist),
+              "CAP_NET_ADMIN,CAP_SYS_PTRACE");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cap_drop", pl_flist),
+              "CAP_MKNOD");
+    ASSERT_FALSE(
+            field_has_value(async_evt, "container.cap_effective", pl_flist));
+    ASSERT_EQ(get_field_as_string(async_evt, "container.gpu_devices", pl_flist),
+              "0,nvidia.com/gpu=1");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.seccomp_profile",
+                                  pl_flist),
+              "unconfined");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.apparmor_profile",
+                                  pl_flist),
+              "docker-default");
+    ASSERT_FALSE(
+            field_has_value(async_evt, "container.selinux_label", pl_flist));
+    ASSERT_EQ(get_field_as_string(async_evt, "container.no_new_privileges",
+                                  pl_flist),
+              "true");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cpu_shares", pl_flist),
+              "1024");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cpu_weight", pl_flist),
+              "39");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cpu_quota", pl_flist),
+              "150000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cpu_period", pl_flist),
+              "100000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cpu_limit", pl_flist),
+              "1500");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.pod.name", pl_flist),
+              "nginx-7c5ddbdf54-2xkqv");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.ns.name", pl_flist), "web");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.pod.uid", pl_flist),
+              "5e1a0a3c-8f8c-4b8e-9d1e-2f5c6b7a8d9e");
+    ASSERT_EQ(get_field_as_string(async_evt, "k8s.pod.netns", pl_flist),
+              "/var/run/netns/cni-1a2b3c4d");
+    ASSERT_EQ(get_field_as_string(async_evt, "ecs.task.arn", pl_flist),
+              "arn:aws:ecs:us-west-2:111122223333:task/default/"
+              "158d1c8083dd49d6b527399fd6414f5c");
+    ASSERT_EQ(get_field_as_string(async_evt, "ecs.cluster", pl_flist),
+              "default");
+    ASSERT_EQ(get_field_as_string(async_evt, "ecs.service.name", pl_flist),
+              "web-svc");
+    ASSERT_EQ(get_field_as_string(async_evt, "ecs.task.family", pl_flist),
+              "web");
+    ASSERT_EQ(get_field_as_string(async_evt, "ecs.task.revision", pl_flist),
+              "7");
+    ASSERT_EQ(get_field_as_string(async_evt, "nomad.alloc.id", pl_flist),
+              "5b3c1f4e-9d2a-4e8b-a3f7-1c2d3e4f5a6b");
+    ASSERT_EQ(get_field_as_string(async_evt, "nomad.job.id", pl_flist),
+              "web");
+    ASSERT_EQ(get_field_as_string(async_evt, "nomad.task_group", pl_flist),
+              "frontend");
+    ASSERT_EQ(get_field_as_string(async_evt, "cf.app.guid", pl_flist),
+              "7c2d1f5e-3a4b-4c6d-8e9f-0a1b2c3d4e5f");
+    ASSERT_EQ(get_field_as_string(async_evt, "cf.space.guid", pl_flist),
+              "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d");
+    ASSERT_EQ(get_field_as_string(async_evt, "cf.org.guid", pl_flist),
+              "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.user", pl_flist),
+              "101:101");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.user_ids", pl_flist),
+              "101:101");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.userns", pl_flist),
+              "true");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.runtime", pl_flist),
+              "io.containerd.kata.v2");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.sandboxed_runtime",
+                                  pl_flist),
+              "true");
+    // Only reported for windows containers
+    ASSERT_FALSE(field_has_value(async_evt, "container.isolation", pl_flist));
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cgroup.path",
+                                  pl_flist),
+              "/kubepods.slice/cri-containerd-abc.scope");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.cgroup.driver",
+                                  pl_flist),
+              "systemd");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.rootless_uid", pl_flist),
+            "1000");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.config_digest",
+                                  pl_flist),
+              "sha256:9f86d081884c7d65");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.restart_count", pl_flist),
+            "3");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.created_ts", pl_flist),
+            "1700000000123456789");
+    ASSERT_EQ(
+            get_field_as_string(async_evt, "container.started_ts", pl_flist),
+            "1700000001987654321");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.uid_map", pl_flist),
+              "0:100000:65536");
+    ASSERT_EQ(get_field_as_string(async_evt, "container.gid_map", pl_flist),
+              "0:100000:65536");
 
     // Fields that read from thread_entry (pidns_init_start_ts):
     (void)field_has_value(async_evt, "container.duration", pl_flist);
@@ -405,3 +702,247 @@ TEST_F(sinsp_with_test_input, plugin_container_extract_on_async_event)
     // proc.is_container_readiness_probe are deprecated and no longer extract
     // values.
 }
+
+TEST_F(sinsp_with_test_input, plugin_container_extract_on_died_async_event)
+{
+    filter_check_list pl_flist;
+    auto plugin_owner = assert_plugin_initialization(m_inspector, pl_flist);
+
+    add_default_init_thread();
+    open_inspector();
+
+    scap_const_sized_buffer json_buf = {TEST_CONTAINER_JSON,
+                                        strlen(TEST_CONTAINER_JSON) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container", json_buf);
+    sinsp_evt* evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_FALSE(field_has_value(evt, "container.exit_code", pl_flist));
+    ASSERT_FALSE(field_has_value(evt, "container.oom_killed", pl_flist));
+
+    // The died event only carries the minimal set of infos:
+    // the ones of the added container are kept.
+    const char* died_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456",
+        "full_id": "abc123def4567890123456789012345678901234567890123456789012345678",
+        "exit_code": 137,
+        "finished_at": 1700000100000000000,
+        "oom_killed": true
+    }
+})";
+    scap_const_sized_buffer died_buf = {died_json, strlen(died_json) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container_died", died_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.name", pl_flist),
+              "test-nginx-container");
+    ASSERT_EQ(get_field_as_string(evt, "container.exit_code", pl_flist),
+              "137");
+    ASSERT_TRUE(field_has_value(evt, "container.finished_ts", pl_flist));
+    ASSERT_EQ(get_field_as_string(evt, "container.oom_killed", pl_flist),
+              "true");
+
+    // Unknown exit statuses, reported as negative exit codes, do not wrap
+    const char* unknown_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456",
+        "full_id": "abc123def4567890123456789012345678901234567890123456789012345678",
+        "exit_code": -1,
+        "finished_at": 1700000200000000000
+    }
+})";
+    scap_const_sized_buffer unknown_buf = {unknown_json,
+                                           strlen(unknown_json) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container_died", unknown_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_FALSE(field_has_value(evt, "container.exit_code", pl_flist));
+    ASSERT_TRUE(field_has_value(evt, "container.finished_ts", pl_flist));
+}
+
+TEST_F(sinsp_with_test_input, plugin_container_extract_on_paused_async_events)
+{
+    filter_check_list pl_flist;
+    auto plugin_owner = assert_plugin_initialization(m_inspector, pl_flist);
+
+    add_default_init_thread();
+    open_inspector();
+
+    scap_const_sized_buffer json_buf = {TEST_CONTAINER_JSON,
+                                        strlen(TEST_CONTAINER_JSON) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container", json_buf);
+    sinsp_evt* evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_FALSE(field_has_value(evt, "container.paused_ts", pl_flist));
+
+    uint64_t paused_ts = increasing_ts();
+    std::string paused_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456",
+        "paused_at": )" + std::to_string(paused_ts) +
+                              R"(
+    }
+})";
+    scap_const_sized_buffer paused_buf = {paused_json.c_str(),
+                                          paused_json.size() + 1};
+    add_async_event(paused_ts, INIT_TID, PPME_ASYNCEVENT_E, 3, (uint32_t)0,
+                    "container_paused", paused_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.name", pl_flist),
+              "test-nginx-container");
+    ASSERT_EQ(get_field_as_string(evt, "container.paused_ts", pl_flist),
+              std::to_string(paused_ts));
+
+    // The unpaused event still exposes the pause, to extract its duration.
+    std::string unpaused_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456"
+    }
+})";
+    scap_const_sized_buffer unpaused_buf = {unpaused_json.c_str(),
+                                            unpaused_json.size() + 1};
+    uint64_t unpaused_ts = increasing_ts();
+    add_async_event(unpaused_ts, INIT_TID, PPME_ASYNCEVENT_E, 3, (uint32_t)0,
+                    "container_unpaused", unpaused_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.pause_duration", pl_flist),
+              std::to_string(unpaused_ts - paused_ts));
+}
+
+TEST_F(sinsp_with_test_input, plugin_container_extract_on_oom_killed_async_events)
+{
+    filter_check_list pl_flist;
+    auto plugin_owner = assert_plugin_initialization(m_inspector, pl_flist);
+
+    add_default_init_thread();
+    open_inspector();
+
+    scap_const_sized_buffer json_buf = {TEST_CONTAINER_JSON,
+                                        strlen(TEST_CONTAINER_JSON) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container", json_buf);
+    sinsp_evt* evt = next_event();
+    ASSERT_NE(evt, nullptr);
+
+    // The OOM killed event only carries the minimal set of infos,
+    // but exposes the ones already known for the container.
+    std::string oom_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456"
+    }
+})";
+    scap_const_sized_buffer oom_buf = {oom_json.c_str(), oom_json.size() + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container_oom_killed", oom_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.id", pl_flist),
+              "abc123def456");
+    ASSERT_EQ(get_field_as_string(evt, "container.name", pl_flist),
+              "test-nginx-container");
+    ASSERT_FALSE(field_has_value(evt, "container.exit_code", pl_flist));
+}
+
+TEST_F(sinsp_with_test_input, plugin_container_extract_on_stats_async_events)
+{
+    filter_check_list pl_flist;
+    auto plugin_owner = assert_plugin_initialization(m_inspector, pl_flist);
+
+    add_default_init_thread();
+    open_inspector();
+
+    scap_const_sized_buffer json_buf = {TEST_CONTAINER_JSON,
+                                        strlen(TEST_CONTAINER_JSON) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container", json_buf);
+    sinsp_evt* evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_FALSE(field_has_value(evt, "container.stats.pids", pl_flist));
+
+    std::string stats_json = R"({
+    "container": {
+        "type": 0,
+        "id": "abc123def456",
+        "stats": {
+            "cpu_percent": 37.6,
+            "memory_usage": 67108864,
+            "memory_limit": 268435456,
+            "pids": 3,
+            "sampled_at": 1700000000000000000
+        }
+    }
+})";
+    scap_const_sized_buffer stats_buf = {stats_json.c_str(),
+                                         stats_json.size() + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container_stats", stats_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.name", pl_flist),
+              "test-nginx-container");
+    ASSERT_EQ(get_field_as_string(evt, "container.stats.cpu_percent",
+                                  pl_flist),
+              "38");
+    ASSERT_EQ(get_field_as_string(evt, "container.stats.memory_usage",
+                                  pl_flist),
+              "67108864");
+    ASSERT_EQ(get_field_as_string(evt, "container.stats.memory_limit",
+                                  pl_flist),
+              "268435456");
+    ASSERT_EQ(get_field_as_string(evt, "container.stats.pids", pl_flist),
+              "3");
+    ASSERT_EQ(get_field_as_string(evt, "container.stats.sampled_ts",
+                                  pl_flist),
+              "1700000000000000000");
+}
+
+TEST_F(sinsp_with_test_input, plugin_container_extract_on_engine_status_async_events)
+{
+    filter_check_list pl_flist;
+    auto plugin_owner = assert_plugin_initialization(m_inspector, pl_flist);
+
+    add_default_init_thread();
+    open_inspector();
+
+    std::string status_json = R"({
+    "engine": "docker",
+    "socket": "/var/run/docker.sock",
+    "status": "down",
+    "error": "unexpected EOF"
+})";
+    scap_const_sized_buffer status_buf = {status_json.c_str(),
+                                          status_json.size() + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container_engine_status", status_buf);
+    sinsp_evt* evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_EQ(get_field_as_string(evt, "container.engine.type", pl_flist),
+              "docker");
+    ASSERT_EQ(get_field_as_string(evt, "container.engine.socket", pl_flist),
+              "/var/run/docker.sock");
+    ASSERT_EQ(get_field_as_string(evt, "container.engine.status", pl_flist),
+              "down");
+    ASSERT_EQ(get_field_as_string(evt, "container.engine.error", pl_flist),
+              "unexpected EOF");
+    ASSERT_FALSE(field_has_value(evt, "container.name", pl_flist));
+
+    // Engine fields are not available in other events
+    scap_const_sized_buffer json_buf = {TEST_CONTAINER_JSON,
+                                        strlen(TEST_CONTAINER_JSON) + 1};
+    add_async_event(increasing_ts(), INIT_TID, PPME_ASYNCEVENT_E, 3,
+                    (uint32_t)0, "container", json_buf);
+    evt = next_event();
+    ASSERT_NE(evt, nullptr);
+    ASSERT_FALSE(field_has_value(evt, "container.engine.type", pl_flist));
+}
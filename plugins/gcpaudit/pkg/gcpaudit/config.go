@@ -36,6 +36,9 @@ type PluginConfig struct {
 	CredentialsFile        string `json:"credentials_file" jsonschema:"title=Credentials File,description=If non-empty overrides the default GCP credentials file (e.g. ~/.config/gcloud/application_default_credentials.json) and env variables such as GOOGLE_APPLICATION_CREDENTIALS (Default: empty),default="`
 	NumGoroutines          int    `json:"num_goroutines" jsonschema:"title=Num Goroutines,description=The number of goroutines that each datastructure along the Receive path will spawn (Default: 10),default=10"`
 	MaxOutstandingMessages int    `json:"max_outstanding_messages" jsonschema:"title=Max Outstanding Messages,description=The maximum number of unprocessed messages (Default: 1000),default=1000"`
+	MaxOutstandingBytes    int    `json:"max_outstanding_bytes" jsonschema:"title=Max Outstanding Bytes,description=The maximum size of unprocessed messages (Default: 1000000000),default=1000000000"`
+	EnableMessageOrdering  bool   `json:"enable_message_ordering" jsonschema:"title=Enable Message Ordering,description=If true then the subscription is required to have message ordering enabled and the messages sharing an ordering key are processed in the order they were published (Default: false),default=false"`
+	GCSInterval            string `json:"gcs_interval" jsonschema:"title=GCS log interval,description=Read the logs exported to GCS (open params 'gs://<bucket>/<prefix>') over the specified interval (e.g. '2d' or '2024-05-01T00:00:00Z-2024-05-02T00:00:00Z') (Default: no interval),default="`
 	UseAsync               bool   `json:"useAsync" jsonschema:"title=Use async extraction,description=If true then async extraction optimization is enabled (Default: true),default=true"`
 }
 
@@ -45,4 +48,7 @@ func (p *PluginConfig) Reset() {
 	p.CredentialsFile = ""
 	p.NumGoroutines = 10
 	p.MaxOutstandingMessages = 1000
+	p.MaxOutstandingBytes = 1e9
+	p.EnableMessageOrdering = false
+	p.GCSInterval = ""
 }
@@ -28,6 +28,9 @@ import (
 func (p *Plugin) Fields() []sdk.FieldEntry {
 	return []sdk.FieldEntry{
 		{Type: "string", Name: "gcp.user", Display: "User", Desc: "GCP principal, actor of the action"},
+		{Type: "string", Name: "gcp.delegation.principals", Display: "Delegation Principals", Desc: "Principals of the service account delegation chain, starting from the original caller. With an index, only the principal at that position (e.g. gcp.delegation.principals[0])", IsList: true, Arg: sdk.FieldEntryArg{IsRequired: false, IsIndex: true}},
+		{Type: "uint64", Name: "gcp.delegation.count", Display: "Delegation Count", Desc: "Number of principals in the service account delegation chain, 0 if the caller didn't impersonate any service account"},
+		{Type: "string", Name: "gcp.delegation.origin", Display: "Delegation Origin", Desc: "Original caller of the service account delegation chain, which impersonated the first service account"},
 		{Type: "string", Name: "gcp.callerIP", Display: "Caller IP", Desc: "Actor's IP"},
 		{Type: "string", Name: "gcp.userAgent", Display: "User Agent", Desc: "Actor's User Agent"},
 		{Type: "string", Name: "gcp.authorizationInfo", Display: "Authorization Info", Desc: "GCP authorization (JSON)"},
@@ -72,6 +75,30 @@ func (p *Plugin) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
 	var fsval *fastjson.Value
 
 	switch req.Field() {
+	case "gcp.delegation.principals":
+		principals := delegationPrincipals(p.jdata)
+		if req.ArgPresent() {
+			idx := req.ArgIndex()
+			if idx >= uint64(len(principals)) {
+				return nil
+			}
+			principals = principals[idx : idx+1]
+		}
+		if len(principals) > 0 {
+			req.SetValue(principals)
+		}
+		return nil
+
+	case "gcp.delegation.count":
+		req.SetValue(uint64(len(delegationPrincipals(p.jdata))))
+		return nil
+
+	case "gcp.delegation.origin":
+		if principals := delegationPrincipals(p.jdata); len(principals) > 0 {
+			req.SetValue(principals[0])
+		}
+		return nil
+
 	case "gcp.user":
 		fsval = p.jdata.Get("protoPayload", "authenticationInfo", "principalEmail")
 
@@ -201,3 +228,17 @@ func (p *Plugin) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
 
 	return nil
 }
+
+// delegationPrincipals returns the principals of the service account delegation
+// chain of an audit log, in the order they impersonated the following service account
+func delegationPrincipals(jdata *fastjson.Value) []string {
+	var principals []string
+	for _, delegation := range jdata.GetArray("protoPayload", "authenticationInfo", "serviceAccountDelegationInfo") {
+		principal := delegation.GetStringBytes("firstPartyPrincipal", "principalEmail")
+		if principal == nil {
+			principal = delegation.GetStringBytes("principalSubject")
+		}
+		principals = append(principals, string(principal))
+	}
+	return principals
+}
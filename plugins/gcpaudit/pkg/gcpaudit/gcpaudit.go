@@ -20,6 +20,7 @@ package gcpaudit
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
@@ -47,7 +48,17 @@ func (p *Plugin) Info() *plugins.Info {
 
 func (p *Plugin) Open(params string) (source.Instance, error) {
 	if params == "" {
-		return nil, fmt.Errorf("no subscriptionID provided")
+		return nil, fmt.Errorf("no subscriptionID or GCS URI provided")
+	}
+
+	if strings.HasPrefix(params, gcsPrefix) {
+		ctx, cancel := context.WithCancel(context.Background())
+		pushEventC, err := p.readGCS(ctx, params)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return source.NewPushInstance(pushEventC, source.WithInstanceClose(cancel))
 	}
 
 	subscriptionID := params
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcpaudit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
+	"github.com/valyala/fastjson"
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+)
+
+const (
+	gcsPrefix = "gs://"
+
+	// maxLogEntrySize is the maximum size of a line of the exported log files
+	maxLogEntrySize = 1024 * 1024
+)
+
+// Log sinks export audit logs to GCS in hourly files, whose object names end with
+// "<YYYY>/<MM>/<DD>/<HH>:00:00_<HH>:59:59_<shard>.json"
+var gcsObjectHourRE = regexp.MustCompile(`(\d{4})/(\d{2})/(\d{2})/(\d{2}):00:00_\d{2}:59:59_[^/]*\.json$`)
+
+// gcsObject is an exported log file, along with the hour its entries belong to
+type gcsObject struct {
+	name string
+	hour time.Time
+}
+
+// parseGCSURI splits a "gs://<bucket>/<prefix>" URI into its bucket and prefix
+func parseGCSURI(uri string) (string, string, error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(uri, gcsPrefix), "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid GCS URI %q: no bucket name", uri)
+	}
+	return bucket, prefix, nil
+}
+
+// objectHour returns the hour of the entries of an exported log file, or the
+// zero time if the object name doesn't follow the log sinks' layout
+func objectHour(name string) time.Time {
+	matches := gcsObjectHourRE.FindStringSubmatch(name)
+	if matches == nil {
+		return time.Time{}
+	}
+	hour, err := time.Parse("2006/01/02/15", strings.Join(matches[1:], "/"))
+	if err != nil {
+		return time.Time{}
+	}
+	return hour
+}
+
+// inInterval returns true if t is within [startTime, endTime], where zero
+// endpoints are unbounded
+func inInterval(t, startTime, endTime time.Time) bool {
+	return (startTime.IsZero() || !t.Before(startTime)) && (endTime.IsZero() || !t.After(endTime))
+}
+
+// intervalObject returns the exported log file named name, and true if its hour
+// overlaps with the interval. Files not following the log sinks' layout are
+// always kept, their entries being filtered by timestamp once read.
+func intervalObject(name string, startTime, endTime time.Time) (gcsObject, bool) {
+	hour := objectHour(name)
+	if !hour.IsZero() && !inInterval(hour, startTime.Truncate(time.Hour), endTime) {
+		return gcsObject{}, false
+	}
+	return gcsObject{name: name, hour: hour}, true
+}
+
+// listGCSObjects returns the exported log files under a prefix whose hour overlaps
+// with the interval, sorted by hour so that the log types are interleaved chronologically
+func listGCSObjects(ctx context.Context, svc *storage.Service, bucket, prefix string, startTime, endTime time.Time) ([]gcsObject, error) {
+	var objects []gcsObject
+	err := svc.Objects.List(bucket).Prefix(prefix).Fields("items/name", "nextPageToken").Pages(ctx, func(page *storage.Objects) error {
+		for _, item := range page.Items {
+			if !strings.HasSuffix(item.Name, ".json") {
+				continue
+			}
+			if object, ok := intervalObject(item.Name, startTime, endTime); ok {
+				objects = append(objects, object)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].hour.Before(objects[j].hour)
+	})
+	return objects, nil
+}
+
+// readGCSObject sends the log entries of an exported log file, one per line,
+// skipping the ones whose timestamp is outside of the interval
+func readGCSObject(ctx context.Context, svc *storage.Service, bucket string, object gcsObject, startTime, endTime time.Time, pushEventC chan<- source.PushEvent) error {
+	resp, err := svc.Objects.Get(bucket, object.name).Context(ctx).Download()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var parser fastjson.Parser
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogEntrySize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		entry, err := parser.ParseBytes(line)
+		if err != nil {
+			return fmt.Errorf("invalid log entry in %s: %w", object.name, err)
+		}
+		var timestamp time.Time
+		if ts := entry.GetStringBytes("timestamp"); ts != nil {
+			timestamp, _ = time.Parse(time.RFC3339Nano, string(ts))
+		}
+		if !timestamp.IsZero() && !inInterval(timestamp, startTime, endTime) {
+			continue
+		}
+
+		// the scanner reuses its buffer, so the line must be copied
+		data := make([]byte, len(line))
+		copy(data, line)
+		select {
+		case pushEventC <- source.PushEvent{Data: data, Timestamp: timestamp}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// readGCS sends the audit log entries exported by a log sink to the bucket
+// of a "gs://<bucket>/<prefix>" URI, over the configured GCS interval.
+// The returned channel is closed once all the entries have been sent.
+func (p *Plugin) readGCS(ctx context.Context, uri string) (chan source.PushEvent, error) {
+	bucket, prefix, err := parseGCSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	startTime, endTime, err := parseInterval(p.Config.GCSInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GCS interval %q: %w", p.Config.GCSInterval, err)
+	}
+
+	clientOptions := []option.ClientOption{option.WithScopes(storage.DevstorageReadOnlyScope)}
+	if len(p.Config.CredentialsFile) > 0 {
+		clientOptions = append(clientOptions, option.WithCredentialsFile(p.Config.CredentialsFile))
+	}
+	svc, err := storage.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	pushEventC := make(chan source.PushEvent)
+	go func() {
+		defer close(pushEventC)
+		sendErr := func(err error) {
+			select {
+			case pushEventC <- source.PushEvent{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+
+		objects, err := listGCSObjects(ctx, svc, bucket, prefix, startTime, endTime)
+		if err != nil {
+			sendErr(err)
+			return
+		}
+		for _, object := range objects {
+			if err := readGCSObject(ctx, svc, bucket, object, startTime, endTime, pushEventC); err != nil {
+				sendErr(err)
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return pushEventC, nil
+}
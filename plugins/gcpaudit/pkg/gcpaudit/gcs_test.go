@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcpaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalObject(t *testing.T) {
+	const name = "logs/cloudaudit.googleapis.com/activity/2024/05/01/10:00:00_10:59:59_S0.json"
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		object    string
+		startTime time.Time
+		endTime   time.Time
+		hour      time.Time
+		expected  bool
+	}{
+		{name: "no interval", object: name, hour: hour, expected: true},
+		{name: "start within the hour", object: name, startTime: hour.Add(30 * time.Minute), hour: hour, expected: true},
+		{name: "start at the next hour", object: name, startTime: hour.Add(time.Hour), expected: false},
+		{name: "end within the hour", object: name, endTime: hour.Add(30 * time.Minute), hour: hour, expected: true},
+		{name: "end at the hour", object: name, endTime: hour, hour: hour, expected: true},
+		{name: "end before the hour", object: name, endTime: hour.Add(-time.Second), expected: false},
+		{name: "hour within the interval", object: name, startTime: hour.Add(-24 * time.Hour), endTime: hour.Add(24 * time.Hour), hour: hour, expected: true},
+		{name: "unknown layout kept", object: "logs/export.json", startTime: hour, endTime: hour, expected: true},
+		{name: "invalid hour kept", object: "logs/2024/05/01/25:00:00_25:59:59_S0.json", startTime: hour, endTime: hour, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, ok := intervalObject(tt.object, tt.startTime, tt.endTime)
+			if ok != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, ok)
+			}
+			if !ok {
+				return
+			}
+			if object.name != tt.object || !object.hour.Equal(tt.hour) {
+				t.Fatalf("expected object %q of hour %v, got %q of hour %v", tt.object, tt.hour, object.name, object.hour)
+			}
+		})
+	}
+}
+
+func TestObjectHour(t *testing.T) {
+	tests := []struct {
+		object   string
+		expected time.Time
+	}{
+		{"2024/05/01/10:00:00_10:59:59_S0.json", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"logs/cloudaudit.googleapis.com/data_access/2024/12/31/23:00:00_23:59:59_S12.json", time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC)},
+		{"2024/05/01/10:00:00_10:59:59_S0.json.gz", time.Time{}},
+		{"2024/05/01/10:30:00_10:59:59_S0.json", time.Time{}},
+		{"2024/05/01/export.json", time.Time{}},
+		{"2024/13/01/10:00:00_10:59:59_S0.json", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.object, func(t *testing.T) {
+			if got := objectHour(tt.object); !got.Equal(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -39,5 +39,6 @@ func (p *Plugin) InitSchema() *sdk.SchemaInfo {
 
 // initialize state
 func (p *Plugin) Init(cfg string) error {
+	p.Config.Reset()
 	return json.Unmarshal([]byte(cfg), &p.Config)
 }
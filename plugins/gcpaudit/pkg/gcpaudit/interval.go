@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcpaudit
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var rfc3339Simple = "2006-01-02T15:04:05Z"
+
+var durationRE = regexp.MustCompile(`^(\d+)([wdhms])$`)
+
+var intervalRE = regexp.MustCompile(`(.*)\s*-\s*(\d+[wdhms]|\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z)$`)
+
+// parseEndpoint parses either a duration in the past (e.g. "2d") or an
+// absolute RFC3339 UTC time (e.g. "2024-05-01T10:00:00Z")
+func parseEndpoint(endpoint string) (time.Time, error) {
+	matches := durationRE.FindStringSubmatch(endpoint)
+	if matches == nil {
+		return time.Parse(rfc3339Simple, endpoint)
+	}
+
+	durI, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	duration := time.Duration(durI)
+	switch matches[2] {
+	case "w":
+		duration *= time.Hour * 24 * 7
+	case "d":
+		duration *= time.Hour * 24
+	case "h":
+		duration *= time.Hour
+	case "m":
+		duration *= time.Minute
+	case "s":
+		duration *= time.Second
+	}
+	return time.Now().UTC().Add(-duration), nil
+}
+
+// parseInterval parses an interval in the same format of the cloudtrail
+// plugin's s3Interval: either a single start endpoint or two endpoints
+// separated by "-". endTime will be zero if no end endpoint was supplied.
+func parseInterval(interval string) (time.Time, time.Time, error) {
+	var startTime time.Time
+	var endTime time.Time
+	var err error
+
+	matches := intervalRE.FindStringSubmatch(interval)
+	if matches != nil {
+		startTime, err = parseEndpoint(matches[1])
+		if err == nil {
+			endTime, err = parseEndpoint(matches[2])
+		}
+	} else if interval != "" {
+		startTime, err = parseEndpoint(interval)
+	}
+	return startTime, endTime, err
+}
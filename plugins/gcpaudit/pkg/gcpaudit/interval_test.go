@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcpaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	may1 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	may2 := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		interval string
+		start    time.Time
+		end      time.Time
+		// startAgo and endAgo are the expected durations in the past of
+		// relative endpoints
+		startAgo time.Duration
+		endAgo   time.Duration
+		wantErr  bool
+	}{
+		{name: "empty", interval: ""},
+		{name: "absolute start", interval: "2024-05-01T00:00:00Z", start: may1},
+		{name: "absolute start and end", interval: "2024-05-01T00:00:00Z-2024-05-02T00:00:00Z", start: may1, end: may2},
+		{name: "absolute start and relative end", interval: "2024-05-01T00:00:00Z-1h", start: may1, endAgo: time.Hour},
+		{name: "relative weeks", interval: "1w", startAgo: 7 * 24 * time.Hour},
+		{name: "relative days", interval: "2d", startAgo: 48 * time.Hour},
+		{name: "relative minutes and seconds", interval: "30m-10s", startAgo: 30 * time.Minute, endAgo: 10 * time.Second},
+		{name: "invalid start", interval: "yesterday", wantErr: true},
+		{name: "invalid start with end", interval: "yesterday-1h", wantErr: true},
+		{name: "invalid unit", interval: "2y", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().UTC()
+			start, end, err := parseInterval(tt.interval)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got start %v and end %v", start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checkEndpoint(t, "start", start, tt.start, tt.startAgo, now)
+			checkEndpoint(t, "end", end, tt.end, tt.endAgo, now)
+		})
+	}
+}
+
+// checkEndpoint checks got against either the expected time, or the expected
+// duration in the past of now when ago is set
+func checkEndpoint(t *testing.T, name string, got, expected time.Time, ago time.Duration, now time.Time) {
+	t.Helper()
+	if ago == 0 {
+		if !got.Equal(expected) {
+			t.Fatalf("expected %s %v, got %v", name, expected, got)
+		}
+		return
+	}
+	if delta := now.Add(-ago).Sub(got); delta < -time.Minute || delta > time.Minute {
+		t.Fatalf("expected %s %v ago, got %v", name, ago, got)
+	}
+}
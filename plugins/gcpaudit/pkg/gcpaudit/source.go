@@ -51,7 +51,23 @@ func (p *Plugin) pullMsgsSync(ctx context.Context, subscriptionID string) (chan
 		// attempt subscribing with exponential backoff
 		sub := client.Subscription(subscriptionID)
 		sub.ReceiveSettings.MaxOutstandingMessages = p.Config.MaxOutstandingMessages
+		sub.ReceiveSettings.MaxOutstandingBytes = p.Config.MaxOutstandingBytes
 		sub.ReceiveSettings.NumGoroutines = p.Config.NumGoroutines
+
+		// with message ordering enabled on the subscription, the client
+		// delivers the messages sharing an ordering key one at a time
+		// and in order, so they must be handed over before being acked
+		if p.Config.EnableMessageOrdering {
+			cfg, err := sub.Config(ctx)
+			if err != nil {
+				errC <- err
+				return
+			}
+			if !cfg.EnableMessageOrdering {
+				errC <- fmt.Errorf("message ordering is not enabled on subscription %s", subscriptionID)
+				return
+			}
+		}
 		maxRetries := 3
 		retryDelay := time.Second
 		for retries := 0; retries < maxRetries; retries++ {
@@ -81,8 +97,14 @@ func isQuotaExceededError(err error) bool {
 
 func performPubSubOperation(subscription *pubsub.Subscription, ctx context.Context, eventC chan []byte) error {
 	return subscription.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
-		eventC <- msg.Data
-		msg.Ack()
+		select {
+		case eventC <- msg.Data:
+			msg.Ack()
+		case <-ctx.Done():
+			// the message is redelivered, before the following ones with
+			// the same ordering key if message ordering is enabled
+			msg.Nack()
+		}
 	})
 }
 
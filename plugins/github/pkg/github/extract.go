@@ -20,6 +20,7 @@ package github
 import (
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strings"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
@@ -54,6 +55,30 @@ func (p *Plugin) Fields() []sdk.FieldEntry {
 		{Type: "string", Name: "github.workflow.has_miners", Display: "Workflow Has Miner", Desc: "For workflow_run messages, 'true' if the a miner has been detected in the workflow definition file."},
 		{Type: "string", Name: "github.workflow.miners.type", Display: "Workflow Miner Type", Desc: "For workflow_run messages, if one or more miners is detected in the workflow definition file, this field contains the type of each of the detected miner, as a comma separated list (e.g. xmrig, stratum)."},
 		{Type: "string", Name: "github.workflow.filename", Display: "Workflow File", Desc: "For workflow_run messages, the name of the workflow definition file."},
+		{Type: "string", Name: "github.enterprise.host", Display: "Enterprise Host", Desc: "For messages delivered by GitHub Enterprise Server, the hostname of the instance."},
+		{Type: "string", Name: "github.enterprise.version", Display: "Enterprise Version", Desc: "For messages delivered by GitHub Enterprise Server, the version of the instance."},
+		{Type: "string", Name: "github.alert.number", Display: "Alert Number", Desc: "For secret_scanning_alert, code_scanning_alert and dependabot_alert messages, the number of the alert in the repository."},
+		{Type: "string", Name: "github.alert.state", Display: "Alert State", Desc: "For secret_scanning_alert, code_scanning_alert and dependabot_alert messages, the state of the alert, e.g. 'open', 'dismissed', 'fixed' or 'resolved'."},
+		{Type: "string", Name: "github.alert.url", Display: "Alert URL", Desc: "For secret_scanning_alert, code_scanning_alert and dependabot_alert messages, the URL of the alert."},
+		{Type: "string", Name: "github.alert.severity", Display: "Alert Severity", Desc: "For code_scanning_alert and dependabot_alert messages, the severity of the alert, e.g. 'critical' or 'high'. For code scanning alerts, the security severity is used if the rule has one."},
+		{Type: "string", Name: "github.alert.resolution", Display: "Alert Resolution", Desc: "For secret_scanning_alert messages, the reason the alert was resolved, e.g. 'false_positive' or 'revoked'. For code_scanning_alert and dependabot_alert messages, the reason the alert was dismissed."},
+		{Type: "string", Name: "github.alert.secret_type", Display: "Secret Type", Desc: "For secret_scanning_alert messages, the type of the detected secret, e.g. 'GitHub Personal Access Token'."},
+		{Type: "string", Name: "github.alert.push_protection_bypassed", Display: "Push Protection Bypassed", Desc: "For secret_scanning_alert messages, 'true' if the secret was pushed by bypassing the push protection. 'false' otherwise."},
+		{Type: "string", Name: "github.alert.rule", Display: "Alert Rule", Desc: "For code_scanning_alert messages, the ID of the rule that triggered the alert, e.g. 'js/sql-injection'."},
+		{Type: "string", Name: "github.alert.rule.description", Display: "Alert Rule Description", Desc: "For code_scanning_alert messages, the description of the rule that triggered the alert."},
+		{Type: "string", Name: "github.alert.tool", Display: "Alert Tool", Desc: "For code_scanning_alert messages, the name of the tool that detected the alert, e.g. 'CodeQL'."},
+		{Type: "string", Name: "github.alert.location", Display: "Alert Location", Desc: "For code_scanning_alert messages, the file and line of the most recent instance of the alert, in the file:line format."},
+		{Type: "string", Name: "github.alert.package", Display: "Vulnerable Package", Desc: "For dependabot_alert messages, the name of the vulnerable package."},
+		{Type: "string", Name: "github.alert.ecosystem", Display: "Package Ecosystem", Desc: "For dependabot_alert messages, the ecosystem of the vulnerable package, e.g. 'npm' or 'pip'."},
+		{Type: "string", Name: "github.alert.manifest", Display: "Manifest Path", Desc: "For dependabot_alert messages, the path of the manifest file declaring the vulnerable package."},
+		{Type: "string", Name: "github.alert.advisory.ghsa_id", Display: "Advisory GHSA ID", Desc: "For dependabot_alert messages, the GitHub Security Advisory ID of the vulnerability."},
+		{Type: "string", Name: "github.alert.advisory.cve_id", Display: "Advisory CVE ID", Desc: "For dependabot_alert messages, the CVE ID of the vulnerability, if any."},
+		{Type: "string", Name: "github.branch_protection.name", Display: "Protected Branch Pattern", Desc: "For branch_protection_rule messages, the name or pattern of the branches the rule applies to."},
+		{Type: "string", Name: "github.branch_protection.admin_enforced", Display: "Enforced For Admins", Desc: "For branch_protection_rule messages, 'true' if the rule is enforced for repository administrators. 'false' otherwise."},
+		{Type: "string", Name: "github.branch_protection.force_pushes", Display: "Force Pushes Enforcement", Desc: "For branch_protection_rule messages, to whom force pushes are allowed, among 'off', 'non_admins' and 'everyone'."},
+		{Type: "string", Name: "github.branch_protection.deletions", Display: "Deletions Enforcement", Desc: "For branch_protection_rule messages, to whom branch deletions are allowed, among 'off', 'non_admins' and 'everyone'."},
+		{Type: "string", Name: "github.branch_protection.required_reviews", Display: "Required Reviews", Desc: "For branch_protection_rule messages, the number of approving reviews required to merge pull requests."},
+		{Type: "string", Name: "github.branch_protection.changes", Display: "Changed Settings", Desc: "For branch_protection_rule messages with action 'edited', the settings of the rule that have been changed, as a comma separated list."},
 	}
 }
 
@@ -129,6 +154,66 @@ func getFilesFromCommits(jdata *fastjson.Value, commitSubKey string) string {
 	return res
 }
 
+// getValueStr returns the value at the given path as a string. Values that
+// are missing or null are not present.
+func getValueStr(jdata *fastjson.Value, keys ...string) (bool, string) {
+	v := jdata.Get(keys...)
+	if v == nil {
+		return false, ""
+	}
+
+	switch v.Type() {
+	case fastjson.TypeNull:
+		return false, ""
+	case fastjson.TypeString:
+		return true, string(v.GetStringBytes())
+	default:
+		return true, v.String()
+	}
+}
+
+func getAlertSeverity(jdata *fastjson.Value) (bool, string) {
+	switch string(jdata.GetStringBytes("webhook_type")) {
+	case "code_scanning_alert":
+		present, res := getValueStr(jdata, "alert", "rule", "security_severity_level")
+		if !present {
+			present, res = getValueStr(jdata, "alert", "rule", "severity")
+		}
+		return present, res
+	case "dependabot_alert":
+		present, res := getValueStr(jdata, "alert", "security_advisory", "severity")
+		if !present {
+			present, res = getValueStr(jdata, "alert", "security_vulnerability", "severity")
+		}
+		return present, res
+	}
+	return false, ""
+}
+
+func getAlertLocation(jdata *fastjson.Value) (bool, string) {
+	present, path := getValueStr(jdata, "alert", "most_recent_instance", "location", "path")
+	if !present {
+		return false, ""
+	}
+
+	return true, fmt.Sprintf("%s:%d", path, jdata.GetUint64("alert", "most_recent_instance", "location", "start_line"))
+}
+
+func getChangedKeys(jdata *fastjson.Value) (bool, string) {
+	changes := jdata.GetObject("changes")
+	if changes == nil {
+		return false, ""
+	}
+
+	var keys []string
+	changes.Visit(func(key []byte, v *fastjson.Value) {
+		keys = append(keys, string(key))
+	})
+	sort.Strings(keys)
+
+	return true, strings.Join(keys, ",")
+}
+
 func getfieldStr(jdata *fastjson.Value, field string) (bool, string) {
 	var res string
 
@@ -144,8 +229,13 @@ func getfieldStr(jdata *fastjson.Value, field string) (bool, string) {
 	case "github.repo.url":
 		res = string(jdata.Get("repository", "html_url").GetStringBytes())
 	case "github.repo.name":
-		res = string(jdata.Get("repository", "html_url").GetStringBytes())
-		res = strings.TrimPrefix(res, "https://github.com/")
+		// The full name doesn't depend on the host, which differs on
+		// GitHub Enterprise Server
+		res = string(jdata.Get("repository", "full_name").GetStringBytes())
+		if res == "" {
+			res = string(jdata.Get("repository", "html_url").GetStringBytes())
+			res = strings.TrimPrefix(res, "https://github.com/")
+		}
 	case "github.repo.description":
 		res = string(jdata.Get("repository", "description").GetStringBytes())
 	case "github.org":
@@ -230,6 +320,61 @@ func getfieldStr(jdata *fastjson.Value, field string) (bool, string) {
 		return getMinerTypes(jdata)
 	case "github.workflow.filename":
 		res = string(jdata.Get("workflow", "path").GetStringBytes())
+	case "github.enterprise.host":
+		return getValueStr(jdata, "enterprise_host")
+	case "github.enterprise.version":
+		return getValueStr(jdata, "enterprise_version")
+	case "github.alert.number":
+		return getValueStr(jdata, "alert", "number")
+	case "github.alert.state":
+		return getValueStr(jdata, "alert", "state")
+	case "github.alert.url":
+		return getValueStr(jdata, "alert", "html_url")
+	case "github.alert.severity":
+		return getAlertSeverity(jdata)
+	case "github.alert.resolution":
+		if string(jdata.GetStringBytes("webhook_type")) == "secret_scanning_alert" {
+			return getValueStr(jdata, "alert", "resolution")
+		}
+		return getValueStr(jdata, "alert", "dismissed_reason")
+	case "github.alert.secret_type":
+		present, res := getValueStr(jdata, "alert", "secret_type_display_name")
+		if !present {
+			present, res = getValueStr(jdata, "alert", "secret_type")
+		}
+		return present, res
+	case "github.alert.push_protection_bypassed":
+		return getValueStr(jdata, "alert", "push_protection_bypassed")
+	case "github.alert.rule":
+		return getValueStr(jdata, "alert", "rule", "id")
+	case "github.alert.rule.description":
+		return getValueStr(jdata, "alert", "rule", "description")
+	case "github.alert.tool":
+		return getValueStr(jdata, "alert", "tool", "name")
+	case "github.alert.location":
+		return getAlertLocation(jdata)
+	case "github.alert.package":
+		return getValueStr(jdata, "alert", "dependency", "package", "name")
+	case "github.alert.ecosystem":
+		return getValueStr(jdata, "alert", "dependency", "package", "ecosystem")
+	case "github.alert.manifest":
+		return getValueStr(jdata, "alert", "dependency", "manifest_path")
+	case "github.alert.advisory.ghsa_id":
+		return getValueStr(jdata, "alert", "security_advisory", "ghsa_id")
+	case "github.alert.advisory.cve_id":
+		return getValueStr(jdata, "alert", "security_advisory", "cve_id")
+	case "github.branch_protection.name":
+		return getValueStr(jdata, "rule", "name")
+	case "github.branch_protection.admin_enforced":
+		return getValueStr(jdata, "rule", "admin_enforced")
+	case "github.branch_protection.force_pushes":
+		return getValueStr(jdata, "rule", "allow_force_pushes_enforcement_level")
+	case "github.branch_protection.deletions":
+		return getValueStr(jdata, "rule", "allow_deletions_enforcement_level")
+	case "github.branch_protection.required_reviews":
+		return getValueStr(jdata, "rule", "required_approving_review_count")
+	case "github.branch_protection.changes":
+		return getChangedKeys(jdata)
 	default:
 		return false, ""
 	}
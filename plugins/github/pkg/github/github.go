@@ -96,6 +96,8 @@ type PluginInstance struct {
 	whSrv          *http.Server
 	whSrvChan      chan []byte
 	whSecret       string
+	apiURL         string
+	enterpriseHost string
 	ghOauth        oauthContext
 	installedHooks []githubHookInfo
 	ghClient       *github.Client
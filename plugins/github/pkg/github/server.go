@@ -20,12 +20,19 @@ package github
 import (
 	"bufio"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -35,7 +42,14 @@ import (
 	"github.com/valyala/fastjson"
 )
 
-const apiDownloadBufSize = 16 * 1024 * 1024
+const (
+	apiDownloadBufSize = 16 * 1024 * 1024
+
+	signatureHeader         = "X-Hub-Signature"
+	signature256Header      = "X-Hub-Signature-256"
+	enterpriseHostHeader    = "X-GitHub-Enterprise-Host"
+	enterpriseVersionHeader = "X-GitHub-Enterprise-Version"
+)
 
 var (
 	rgxHunkShort = regexp.MustCompile(`^@@ -(?:\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@.*`)
@@ -86,7 +100,7 @@ func scanDiffPatch(patch string, onAddition func(lineNum uint64, line string)) e
 
 func scanDiff(oCtx *PluginInstance, repo string, refs string, diffFiles *[]diffFileInfo) error {
 	// Issue the compare request
-	resp, err := oCtx.ghOauth.tc.Get("https://api.github.com/repos/" + repo + "/compare/" + refs)
+	resp, err := oCtx.ghOauth.tc.Get(oCtx.apiURL + "repos/" + repo + "/compare/" + refs)
 	if err != nil {
 		return err
 	}
@@ -152,7 +166,7 @@ func scanDiff(oCtx *PluginInstance, repo string, refs string, diffFiles *[]diffF
 }
 
 func scanWorkFlowYaml(oCtx *PluginInstance, fileName string, repoName string, workflowInfo *workflowFileInfo) error {
-	fileUrl := "https://api.github.com/repos/" + repoName + "/contents/" + fileName
+	fileUrl := oCtx.apiURL + "repos/" + repoName + "/contents/" + fileName
 
 	// Issue the compare request
 	resp, err := oCtx.ghOauth.tc.Get(fileUrl)
@@ -212,14 +226,68 @@ func scanWorkFlowYaml(oCtx *PluginInstance, fileName string, repoName string, wo
 	return nil
 }
 
+// validatePayload returns the JSON payload of a webhook delivery, after
+// checking its signature. Differently from github.ValidatePayload, the SHA-256
+// signature is preferred when present, since GitHub Enterprise Server can be
+// configured not to send the SHA-1 one.
+func validatePayload(r *http.Request, secretKey []byte) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	switch ct := r.Header.Get("Content-Type"); ct {
+	case "application/json":
+		payload = body
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		payload = []byte(form.Get("payload"))
+	default:
+		return nil, fmt.Errorf("webhook request has unsupported Content-Type %q", ct)
+	}
+
+	var hashFunc func() hash.Hash
+	signature := r.Header.Get(signature256Header)
+	if signature != "" {
+		hashFunc = sha256.New
+		signature = strings.TrimPrefix(signature, "sha256=")
+	} else {
+		hashFunc = sha1.New
+		signature = strings.TrimPrefix(r.Header.Get(signatureHeader), "sha1=")
+	}
+	expectedMAC, err := hex.DecodeString(signature)
+	if err != nil || len(expectedMAC) == 0 {
+		return nil, fmt.Errorf("missing or malformed payload signature")
+	}
+
+	mac := hmac.New(hashFunc, secretKey)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expectedMAC) {
+		return nil, fmt.Errorf("payload signature check failed")
+	}
+	return payload, nil
+}
+
 func handleHook(w http.ResponseWriter, r *http.Request, oCtx *PluginInstance) {
-	payload, err := github.ValidatePayload(r, []byte(oCtx.whSecret))
+	payload, err := validatePayload(r, []byte(oCtx.whSecret))
 	if err != nil {
 		// oCtx.whSrvChan <- []byte("E " + err.Error())
 		log.Printf("[%s] signature check failed, skipping message from %s.\n", PluginName, r.RemoteAddr)
 		return
 	}
 
+	// GitHub Enterprise Server identifies itself in the delivery headers, so
+	// we only accept the deliveries coming from the configured instance.
+	enterpriseHost := r.Header.Get(enterpriseHostHeader)
+	if oCtx.enterpriseHost != "" && !strings.EqualFold(enterpriseHost, oCtx.enterpriseHost) {
+		log.Printf("[%s] unexpected GitHub Enterprise Server host %q, skipping message from %s.\n", PluginName, enterpriseHost, r.RemoteAddr)
+		return
+	}
+
 	defer r.Body.Close()
 
 	// GitHub's webhook messages encode the webhook type as a http header instead of
@@ -306,6 +374,10 @@ func handleHook(w http.ResponseWriter, r *http.Request, oCtx *PluginInstance) {
 	}
 
 	jmap["webhook_type"] = whType
+	if enterpriseHost != "" {
+		jmap["enterprise_host"] = enterpriseHost
+		jmap["enterprise_version"] = r.Header.Get(enterpriseVersionHeader)
+	}
 	jsonString, err := json.Marshal(jmap)
 	if err != nil {
 		jsonString = []byte("E " + err.Error())
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -55,7 +56,7 @@ func listRepos(oCtx *PluginInstance) ([]RepoInfo, error) {
 		Page++
 		// NOTE: we don't use Repositories.List from the github API because it doesn't support pagination and therefore it's
 		//       essentially useless
-		resp, err := oCtx.ghOauth.tc.Get("https://api.github.com/user/repos?type=all&per_page=" + strconv.Itoa(perPage) + "&page=" + strconv.Itoa(Page))
+		resp, err := oCtx.ghOauth.tc.Get(oCtx.apiURL + "user/repos?type=all&per_page=" + strconv.Itoa(perPage) + "&page=" + strconv.Itoa(Page))
 		if err != nil {
 			return res, err
 		}
@@ -161,6 +162,25 @@ func (p *Plugin) initInstance(oCtx *PluginInstance) error {
 	oCtx.ghOauth.tc = oauth2.NewClient(oCtx.ghOauth.ctx, oCtx.ghOauth.ts)
 	oCtx.ghClient = github.NewClient(oCtx.ghOauth.tc)
 
+	// Point the client to the configured API, which is a GitHub Enterprise
+	// Server one unless it's the default
+	apiBaseURL := p.config.APIBaseURL
+	if apiBaseURL == "" {
+		apiBaseURL = defaultAPIBaseURL
+	}
+	apiURL, err := url.Parse(apiBaseURL)
+	if err != nil {
+		return fmt.Errorf("[%s] invalid API base URL %s: %s", PluginName, apiBaseURL, err.Error())
+	}
+	if !strings.HasSuffix(apiURL.Path, "/") {
+		apiURL.Path += "/"
+	}
+	oCtx.apiURL = apiURL.String()
+	oCtx.ghClient.BaseURL = apiURL
+	if oCtx.apiURL != defaultAPIBaseURL {
+		oCtx.enterpriseHost = apiURL.Hostname()
+	}
+
 	return nil
 }
 
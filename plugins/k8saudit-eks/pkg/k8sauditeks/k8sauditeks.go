@@ -56,6 +56,7 @@ type PluginConfig struct {
 	PollingInterval uint64 `json:"polling_interval" jsonschema:"title=polling_interval,description=Polling Interval in seconds (default: 5s),default=5"`
 	UseAsync        bool   `json:"use_async"        jsonschema:"title=use_async,description=If true then async extraction optimization is enabled (default: true),default=true"`
 	MaxEventSize    uint64 `json:"max_event_size"   jsonschema:"title=max_event_size,description=Maximum size of single audit event (default: 262144),default=262144"`
+	CheckpointFile  string `json:"checkpoint_file"  jsonschema:"title=checkpoint_file,description=File where the next tokens of the log streams are persisted to resume reading after restarts; if empty they are only kept in memory (default: empty)"`
 }
 
 func (k *Plugin) Info() *plugins.Info {
@@ -144,6 +145,10 @@ func (p *Plugin) Open(clustername string) (source.Instance, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error creating AWS config: %w", err)
 	}
+	checkpoints, err := cloudwatchlogs.LoadCheckpoints(p.Config.CheckpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading checkpoints: %w", err)
+	}
 	client := cloudwatchlogs.CreateClient(cfg)
 	ctx, cancel := context.WithCancel(context.Background())
 	options := cloudwatchlogs.CreateOptions(
@@ -151,38 +156,29 @@ func (p *Plugin) Open(clustername string) (source.Instance, error) {
 		time.Duration(p.Config.PollingInterval*uint64(time.Second)),
 		p.Config.BufferSize,
 	)
-	eventsC, errC := client.Open(ctx, filter, options)
+	eventsC, errC := client.OpenStreams(ctx, filter, options, checkpoints)
 	pushEventC := make(chan source.PushEvent)
 	go func() {
 		for {
 			select {
 			case i := <-eventsC:
-				message := *i.Message
-				if strings.HasSuffix(message, "[Truncated...]") {
-					auditID := regExpCAuditID.FindStringSubmatch(message)
-					if len(auditID) > 0 {
-						p.Logger.Printf("truncated log line, can't be parsed (%v)\n", auditID[0])
-					} else {
-						p.Logger.Println("truncated log line, can't be parsed")
-					}
-					continue
+				if !p.pushEvents(ctx, *i.Message, pushEventC) {
+					// closed before the event is consumed, its stream resumes from it
+					return
 				}
-				values, err := p.Plugin.ParseAuditEventsPayload([]byte(*i.Message))
-				if err != nil {
-					p.Logger.Println(err)
-					continue
-				}
-				for _, j := range values {
-					if j.Err != nil {
-						p.Logger.Println(j.Err)
-						continue
-					}
-					pushEventC <- *j
+				// the event is consumed, even if dropped, thus its stream can resume after it
+				if err := checkpoints.Commit(i); err != nil {
+					p.Logger.Printf("error saving checkpoints: %v\n", err)
 				}
 			case e := <-errC:
-				pushEventC <- source.PushEvent{Err: e}
+				select {
+				case pushEventC <- source.PushEvent{Err: e}:
+				case <-ctx.Done():
+				}
 				// errors are blocking, so we can stop here
 				return
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
@@ -192,3 +188,34 @@ func (p *Plugin) Open(clustername string) (source.Instance, error) {
 		source.WithInstanceEventSize(uint32(p.Config.MaxEventSize)),
 	)
 }
+
+// pushEvents sends the audit events of a log message, dropping the ones that can't be parsed.
+// It returns false if ctx is done before all the events are sent
+func (p *Plugin) pushEvents(ctx context.Context, message string, pushEventC chan source.PushEvent) bool {
+	if strings.HasSuffix(message, "[Truncated...]") {
+		auditID := regExpCAuditID.FindStringSubmatch(message)
+		if len(auditID) > 0 {
+			p.Logger.Printf("truncated log line, can't be parsed (%v)\n", auditID[0])
+		} else {
+			p.Logger.Println("truncated log line, can't be parsed")
+		}
+		return true
+	}
+	values, err := p.Plugin.ParseAuditEventsPayload([]byte(message))
+	if err != nil {
+		p.Logger.Println(err)
+		return true
+	}
+	for _, j := range values {
+		if j.Err != nil {
+			p.Logger.Println(j.Err)
+			continue
+		}
+		select {
+		case pushEventC <- *j:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -40,6 +40,8 @@ type PluginConfig struct {
 	CredentialsFile        string `json:"credentials_file"         jsonschema:"title=Credentials File,description=If non-empty overrides the default GCP credentials file (e.g. ~/.config/gcloud/application_default_credentials.json) and env variables such as GOOGLE_APPLICATION_CREDENTIALS (Default: empty),default="`
 	NumGoroutines          int    `json:"num_goroutines"           jsonschema:"title=Num Goroutines,description=The number of goroutines that each datastructure along the PubSub Receive path will spawn (Default: 10),default=10"`
 	MaxOutstandingMessages int    `json:"max_outstanding_messages" jsonschema:"title=Max Outstanding Messages,description=The maximum number of unprocessed PubSub messages (Default: 1000),default=1000"`
+	MaxOutstandingBytes    int    `json:"max_outstanding_bytes"    jsonschema:"title=Max Outstanding Bytes,description=The maximum size of unprocessed PubSub messages (Default: 1000000000),default=1000000000"`
+	DeadLetterTopic        string `json:"dead_letter_topic"        jsonschema:"title=Dead Letter Topic,description=If non-empty the PubSub topic (either an ID in project_id or projects/<project>/topics/<topic>) where the messages failing processing are published instead of being dropped or nacked (Default: empty),default="`
 	FetchClusterMetadata   bool   `json:"fetch_cluster_metadata"   jsonschema:"title=Fetch cluster metadata labels,description=(Default: false),default=false"`
 	CacheExpiration        uint64 `json:"cache_expiration"         jsonschema:"title=Cluster metadata cache expiration (in minutes),description=(Default: 10),default=10"`
 	UseAsync               bool   `json:"use_async"                jsonschema:"title=Use async extraction,description=If true then async extraction optimization is enabled (Default: true),default=true"`
@@ -52,6 +54,8 @@ func (p *PluginConfig) Reset() {
 	p.CredentialsFile = ""
 	p.NumGoroutines = 10
 	p.MaxOutstandingMessages = 1000
+	p.MaxOutstandingBytes = 1e9
+	p.DeadLetterTopic = ""
 	p.FetchClusterMetadata = false
 	p.CacheExpiration = 10
 	p.UseAsync = true
@@ -20,6 +20,7 @@ package k8sauditgke
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -54,14 +55,22 @@ func (p *Plugin) pullMsgsSync(ctx context.Context, subscriptionID string) (chan
 			return
 		}
 
+		// setup the optional dead-letter topic for the messages failing processing
+		var deadLetter *pubsub.Topic
+		if len(p.Config.DeadLetterTopic) > 0 {
+			deadLetter = deadLetterTopic(client, p.Config.DeadLetterTopic)
+			defer deadLetter.Stop()
+		}
+
 		// attempt subscribing with exponential backoff
 		sub := client.Subscription(subscriptionID)
 		sub.ReceiveSettings.MaxOutstandingMessages = p.Config.MaxOutstandingMessages
+		sub.ReceiveSettings.MaxOutstandingBytes = p.Config.MaxOutstandingBytes
 		sub.ReceiveSettings.NumGoroutines = p.Config.NumGoroutines
 		maxRetries := 3
 		retryDelay := time.Second
 		for retries := 0; retries < maxRetries; retries++ {
-			err = p.performPubSubOperation(sub, ctx, eventC)
+			err = p.performPubSubOperation(sub, deadLetter, ctx, eventC)
 			if err == nil {
 				// Operation succeeded, break out of the loop
 				return
@@ -84,52 +93,123 @@ func isQuotaExceededError(err error) bool {
 	return strings.Contains(err.Error(), "quota exceeded")
 }
 
-func (p *Plugin) performPubSubOperation(subscription *pubsub.Subscription, ctx context.Context, eventC chan source.PushEvent) error {
-	return subscription.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
-		defer msg.Ack()
+// deadLetterTopic returns the topic identified either by an ID in the client's project,
+// or by its full name (i.e. projects/<project>/topics/<topic>)
+func deadLetterTopic(client *pubsub.Client, name string) *pubsub.Topic {
+	if rest, ok := strings.CutPrefix(name, "projects/"); ok {
+		if project, topic, ok := strings.Cut(rest, "/topics/"); ok {
+			return client.TopicInProject(topic, project)
+		}
+	}
+	return client.Topic(name)
+}
 
-		logEntry := &logging.LogEntry{}
-		err := protojson.Unmarshal(msg.Data, logEntry)
+func (p *Plugin) performPubSubOperation(subscription *pubsub.Subscription, deadLetter *pubsub.Topic, ctx context.Context, eventC chan source.PushEvent) error {
+	return subscription.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		event, err := p.processMessage(msg)
 		if err != nil {
-			p.logger.Printf("failed to unmarshal PubSub message to log entry: %v\n", err)
+			p.logger.Printf("%v\n", err)
+			p.rejectMessage(ctx, deadLetter, msg, err)
 			return
 		}
-
-		if !isValidLogEntry(logEntry) {
-			p.logger.Printf("dropped unrecognised log entry (insertId=%s)\n", logEntry.InsertId)
+		if event == nil {
+			// the message is not a GKE audit log entry, there's no point in redelivering it
+			msg.Ack()
 			return
 		}
 
-		switch payload := logEntry.Payload.(type) {
-		case *logging.LogEntry_ProtoPayload:
-			switch payload.ProtoPayload.TypeUrl {
-			case "type.googleapis.com/google.cloud.audit.AuditLog":
-				auditLog := &audit.AuditLog{}
-				err := proto.UnmarshalOptions{DiscardUnknown: false}.Unmarshal(payload.ProtoPayload.Value, auditLog)
-				if err != nil {
-					p.logger.Printf("failed to unmarshal log entry payload (insertId=%s): %v\n", logEntry.InsertId, err)
-					return
-				}
+		// messages are acked only once pushed, so that none is lost on close
+		select {
+		case eventC <- *event:
+			msg.Ack()
+		case <-ctx.Done():
+			msg.Nack()
+		}
+	})
+}
 
-				// Check audit log service name
-				if auditLog.ServiceName != "k8s.io" {
-					p.logger.Printf("dropped log entry with unrecognised service name (insertId=%s)\n", logEntry.InsertId)
-					return
-				}
+// permanentError is a processing failure that would happen again on every redelivery of a message,
+// e.g. a malformed message
+type permanentError struct {
+	err error
+}
 
-				event, err := p.processAuditLogEntry(logEntry, auditLog)
-				if err != nil {
-					p.logger.Printf("failed to process log entry (insertId=%s): %v\n", logEntry.InsertId, err)
-					return
-				}
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
 
-				eventC <- *event
-			default:
-				p.logger.Printf("unsupported payload type: %s", payload.ProtoPayload.TypeUrl)
-				return
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// rejectMessage publishes a message failing processing to the dead-letter topic, if any. Otherwise,
+// the message is acked and dropped if it can never be processed, and nacked so that it is redelivered
+// (or dead-lettered by the subscription's own policy) if it failed for a transient reason
+func (p *Plugin) rejectMessage(ctx context.Context, deadLetter *pubsub.Topic, msg *pubsub.Message, reason error) {
+	if deadLetter == nil {
+		var permanent *permanentError
+		if errors.As(reason, &permanent) {
+			msg.Ack()
+		} else {
+			msg.Nack()
+		}
+		return
+	}
+	attributes := make(map[string]string, len(msg.Attributes)+2)
+	for k, v := range msg.Attributes {
+		attributes[k] = v
+	}
+	attributes["falco_error"] = reason.Error()
+	attributes["falco_message_id"] = msg.ID
+	_, err := deadLetter.Publish(ctx, &pubsub.Message{Data: msg.Data, Attributes: attributes}).Get(ctx)
+	if err != nil {
+		p.logger.Printf("failed to publish message to dead-letter topic (id=%s): %v\n", msg.ID, err)
+		msg.Nack()
+		return
+	}
+	msg.Ack()
+}
+
+// processMessage converts a PubSub message to an event, returning a nil event
+// if the message is not a GKE audit log entry and an error if it fails processing
+func (p *Plugin) processMessage(msg *pubsub.Message) (*source.PushEvent, error) {
+	logEntry := &logging.LogEntry{}
+	err := protojson.Unmarshal(msg.Data, logEntry)
+	if err != nil {
+		return nil, &permanentError{fmt.Errorf("failed to unmarshal PubSub message to log entry: %v", err)}
+	}
+
+	if !isValidLogEntry(logEntry) {
+		p.logger.Printf("dropped unrecognised log entry (insertId=%s)\n", logEntry.InsertId)
+		return nil, nil
+	}
+
+	switch payload := logEntry.Payload.(type) {
+	case *logging.LogEntry_ProtoPayload:
+		switch payload.ProtoPayload.TypeUrl {
+		case "type.googleapis.com/google.cloud.audit.AuditLog":
+			auditLog := &audit.AuditLog{}
+			err := proto.UnmarshalOptions{DiscardUnknown: false}.Unmarshal(payload.ProtoPayload.Value, auditLog)
+			if err != nil {
+				return nil, &permanentError{fmt.Errorf("failed to unmarshal log entry payload (insertId=%s): %v", logEntry.InsertId, err)}
+			}
+
+			// Check audit log service name
+			if auditLog.ServiceName != "k8s.io" {
+				p.logger.Printf("dropped log entry with unrecognised service name (insertId=%s)\n", logEntry.InsertId)
+				return nil, nil
 			}
+
+			event, err := p.processAuditLogEntry(logEntry, auditLog)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process log entry (insertId=%s): %v", logEntry.InsertId, err)
+			}
+			return event, nil
+		default:
+			p.logger.Printf("unsupported payload type: %s", payload.ProtoPayload.TypeUrl)
 		}
-	})
+	}
+	return nil, nil
 }
 
 func isValidLogEntry(logEntry *logging.LogEntry) bool {
@@ -171,7 +251,7 @@ func (p *Plugin) processAuditLogEntry(logEntry *logging.LogEntry, auditLog *audi
 	}
 
 	if len(eventJSON) > int(p.Config.MaxEventSize) {
-		return nil, fmt.Errorf("event larger than maxEventSize: size=%d", len(eventJSON))
+		return nil, &permanentError{fmt.Errorf("event larger than maxEventSize: size=%d", len(eventJSON))}
 	}
 
 	pushEvent := &source.PushEvent{
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
+)
+
+const (
+	// batchInstanceTimeout is the time waited for new events before
+	// flushing an empty batch, same as the SDK's push-mode instances
+	batchInstanceTimeout = 30 * time.Millisecond
+)
+
+// batchInstance is an event source instance receiving events in batches,
+// such as all the ones of an EventList posted to the webhook. Differently
+// from the SDK's push-mode instances, which receive events one by one, each
+// NextBatch call fills the event batch with the received ones right away,
+// keeping the exceeding events for the next call.
+type batchInstance struct {
+	source.BaseInstance
+	ctx           context.Context
+	batchC        <-chan []source.PushEvent
+	pending       []source.PushEvent
+	timeoutTicker *time.Ticker
+	shutdown      func()
+	eof           bool
+	// lastRead is the time of the last NextBatch call, in nanoseconds
+	lastRead atomic.Int64
+}
+
+// newBatchInstance opens an instance receiving events from batchC, until
+// either the channel is closed, an event with a non-nil Err is received or
+// ctx is done. The shutdown callback is invoked on Close.
+func newBatchInstance(ctx context.Context, batchC <-chan []source.PushEvent, eventSize uint32, shutdown func()) (*batchInstance, error) {
+	batch, err := sdk.NewEventWriters(int64(sdk.DefaultBatchSize), int64(eventSize))
+	if err != nil {
+		return nil, err
+	}
+	res := &batchInstance{
+		ctx:           ctx,
+		batchC:        batchC,
+		timeoutTicker: time.NewTicker(batchInstanceTimeout),
+		shutdown:      shutdown,
+	}
+	res.SetEvents(batch)
+	res.lastRead.Store(time.Now().UnixNano())
+	return res, nil
+}
+
+// lastReadTime returns the time events were last requested, or the
+// opening time if they have never been
+func (b *batchInstance) lastReadTime() time.Time {
+	return time.Unix(0, b.lastRead.Load())
+}
+
+func (b *batchInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (int, error) {
+	// once EOF has been hit, we should return it at each new call of NextBatch
+	if b.eof {
+		return 0, sdk.ErrEOF
+	}
+	b.lastRead.Store(time.Now().UnixNano())
+	b.timeoutTicker.Reset(batchInstanceTimeout)
+
+	n := 0
+	for n < evts.Len() {
+		if len(b.pending) == 0 {
+			// wait for new events only if none is ready to be returned
+			batch, err := b.receive(n == 0)
+			if err == sdk.ErrEOF && n > 0 {
+				// the ready events are returned first, as EOF is returned
+				// at the next call anyways
+				return n, nil
+			}
+			if err != nil {
+				return n, err
+			}
+			if batch == nil {
+				return n, nil
+			}
+			b.pending = batch
+			continue
+		}
+
+		evt := b.pending[0]
+		if evt.Err != nil && n > 0 {
+			// the ready events are returned first, and the error at the next call
+			return n, nil
+		}
+		b.pending = b.pending[1:]
+		if evt.Err == nil {
+			if l, err := evts.Get(n).Writer().Write(evt.Data); err != nil {
+				evt.Err = err
+			} else if l < len(evt.Data) {
+				evt.Err = io.ErrShortWrite
+			}
+		}
+		if evt.Err != nil {
+			b.eof = true
+			return n, evt.Err
+		}
+		if evt.Timestamp.IsZero() {
+			evts.Get(n).SetTimestamp(math.MaxUint64)
+		} else {
+			evts.Get(n).SetTimestamp(uint64(evt.Timestamp.UnixNano()))
+		}
+		n++
+	}
+	return n, nil
+}
+
+// receive returns the next batch of events, waiting for it until the timeout
+// only if wait is true. A nil batch is returned if none is ready.
+func (b *batchInstance) receive(wait bool) ([]source.PushEvent, error) {
+	var batch []source.PushEvent
+	var ok bool
+	if wait {
+		select {
+		case batch, ok = <-b.batchC:
+		case <-b.timeoutTicker.C:
+			return nil, sdk.ErrTimeout
+		case <-b.ctx.Done():
+			b.eof = true
+			return nil, sdk.ErrEOF
+		}
+	} else {
+		select {
+		case batch, ok = <-b.batchC:
+		default:
+			return nil, nil
+		}
+	}
+	if !ok {
+		b.eof = true
+		return nil, sdk.ErrEOF
+	}
+	return batch, nil
+}
+
+func (b *batchInstance) Close() {
+	b.shutdown()
+	b.timeoutTicker.Stop()
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
+)
+
+func newTestBatch(size int) []source.PushEvent {
+	batch := make([]source.PushEvent, size)
+	for i := range batch {
+		batch[i] = source.PushEvent{
+			Data:      []byte(fmt.Sprintf("event-%d", i)),
+			Timestamp: time.Unix(int64(i), 0),
+		}
+	}
+	return batch
+}
+
+func openTestBatchInstance(t *testing.T, batchC <-chan []source.PushEvent) source.Instance {
+	inst, err := newBatchInstance(context.Background(), batchC, sdk.DefaultEvtSize, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(inst.Close)
+	return inst
+}
+
+// testEventWriter is an in-memory sdk.EventWriter, whose data can be read back
+type testEventWriter struct {
+	data      bytes.Buffer
+	timestamp uint64
+}
+
+func (w *testEventWriter) Writer() io.Writer {
+	w.data.Reset()
+	return &w.data
+}
+
+func (w *testEventWriter) SetTimestamp(value uint64) {
+	w.timestamp = value
+}
+
+type testEventWriters []*testEventWriter
+
+func newTestEventWriters(size int) testEventWriters {
+	evts := make(testEventWriters, size)
+	for i := range evts {
+		evts[i] = &testEventWriter{}
+	}
+	return evts
+}
+
+func (w testEventWriters) Get(eventIndex int) sdk.EventWriter { return w[eventIndex] }
+func (w testEventWriters) Len() int                           { return len(w) }
+func (w testEventWriters) ArrayPtr() unsafe.Pointer           { return nil }
+func (w testEventWriters) Free()                              {}
+
+func TestBatchInstanceNextBatch(t *testing.T) {
+	batchC := make(chan []source.PushEvent, 2)
+	batchC <- newTestBatch(300)
+	batchC <- newTestBatch(10)
+	close(batchC)
+	inst := openTestBatchInstance(t, batchC)
+	evts := newTestEventWriters(int(sdk.DefaultBatchSize))
+
+	// batches are filled with the events received at once, carrying over
+	// the exceeding ones to the next calls
+	var total int
+	for _, expected := range []int{evts.Len(), evts.Len(), 300 + 10 - 2*evts.Len()} {
+		n, err := inst.NextBatch(nil, evts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != expected {
+			t.Fatalf("expected %d events, got %d", expected, n)
+		}
+		first := total % 300
+		if data := evts[0].data.String(); data != fmt.Sprintf("event-%d", first) {
+			t.Fatalf("unexpected first event data: %s", data)
+		}
+		if ts := evts[0].timestamp; ts != uint64(time.Unix(int64(first), 0).UnixNano()) {
+			t.Fatalf("unexpected first event timestamp: %d", ts)
+		}
+		total += n
+	}
+	if n, err := inst.NextBatch(nil, evts); n != 0 || err != sdk.ErrEOF {
+		t.Fatalf("expected EOF, got %d events and error %v", n, err)
+	}
+}
+
+func TestBatchInstanceTimeout(t *testing.T) {
+	inst := openTestBatchInstance(t, make(chan []source.PushEvent))
+	if n, err := inst.NextBatch(nil, newTestEventWriters(4)); n != 0 || err != sdk.ErrTimeout {
+		t.Fatalf("expected timeout, got %d events and error %v", n, err)
+	}
+}
+
+func TestBatchInstanceError(t *testing.T) {
+	batchC := make(chan []source.PushEvent, 1)
+	testErr := errors.New("test error")
+	batchC <- append(newTestBatch(2), source.PushEvent{Err: testErr})
+	inst := openTestBatchInstance(t, batchC)
+
+	// the events preceding the error are returned first
+	if n, err := inst.NextBatch(nil, newTestEventWriters(4)); n != 2 || err != nil {
+		t.Fatalf("expected 2 events, got %d events and error %v", n, err)
+	}
+	if _, err := inst.NextBatch(nil, newTestEventWriters(4)); err != testErr {
+		t.Fatalf("expected test error, got %v", err)
+	}
+	if _, err := inst.NextBatch(nil, newTestEventWriters(4)); err != sdk.ErrEOF {
+		t.Fatalf("expected EOF after error, got %v", err)
+	}
+}
+
+func TestOpenWebServerEventList(t *testing.T) {
+	p, _ := newTestPlugin()
+	inst, url := openTestWebServerInstance(t, p)
+
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = testAuditEvent
+	}
+	payload := `{"kind":"EventList","items":[` + strings.Join(items, ",") + `]}`
+	resp, err := http.Post(url, "application/json", strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+
+	evts := newTestEventWriters(int(sdk.DefaultBatchSize))
+	var total int
+	deadline := time.Now().Add(5 * time.Second)
+	for total < len(items) && time.Now().Before(deadline) {
+		n, err := inst.NextBatch(nil, evts)
+		if err == sdk.ErrTimeout {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total == 0 && n != evts.Len() {
+			t.Fatalf("expected a full batch of %d events, got %d", evts.Len(), n)
+		}
+		total += n
+	}
+	if total != len(items) {
+		t.Fatalf("expected %d events, got %d", len(items), total)
+	}
+}
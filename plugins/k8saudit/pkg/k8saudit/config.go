@@ -20,15 +20,24 @@ package k8saudit
 import "github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 
 type PluginConfig struct {
-	SSLCertificate      string `json:"sslCertificate"       jsonschema:"title=SSL certificate,description=The SSL Certificate to be used with the HTTPS Webhook endpoint (Default: /etc/falco/falco.pem),default=/etc/falco/falco.pem"`
-	UseAsync            bool   `json:"useAsync"             jsonschema:"title=Use async extraction,description=If true then async extraction optimization is enabled (Default: true),default=true"`
-	MaxEventSize        uint64 `json:"maxEventSize"         jsonschema:"title=Maximum event size,description=Maximum size of single audit event (Default: 262144),default=262144"`
-	WebhookMaxBatchSize uint64 `json:"webhookMaxBatchSize"  jsonschema:"title=Maximum webhook request size,description=Maximum size of incoming webhook POST request bodies (Default: 12582912),default=12582912"`
+	SSLCertificate       string   `json:"sslCertificate"       jsonschema:"title=SSL certificate,description=The SSL Certificate to be used with the HTTPS Webhook endpoint (Default: /etc/falco/falco.pem),default=/etc/falco/falco.pem"`
+	SSLClientCA          string   `json:"sslClientCA"          jsonschema:"title=SSL client CA bundle,description=The CA bundle used to verify the client certificates of the HTTPS Webhook endpoint. If set then clients are required to present a certificate signed by one of its CAs (Default: empty)"`
+	SSLClientAllowedSANs []string `json:"sslClientAllowedSANs" jsonschema:"title=SSL client allowed SANs,description=If set then client certificates are also required to carry at least one of these DNS names or IP addresses or email addresses or URIs as subject alternative name. Requires sslClientCA (Default: empty)"`
+	UseAsync             bool     `json:"useAsync"             jsonschema:"title=Use async extraction,description=If true then async extraction optimization is enabled (Default: true),default=true"`
+	MaxEventSize         uint64   `json:"maxEventSize"         jsonschema:"title=Maximum event size,description=Maximum size of single audit event (Default: 262144),default=262144"`
+	WebhookMaxBatchSize  uint64   `json:"webhookMaxBatchSize"  jsonschema:"title=Maximum webhook request size,description=Maximum size of incoming webhook POST request bodies; also applied to gzip-encoded bodies once decompressed (Default: 12582912),default=12582912"`
+	WebhookRateLimit     uint64   `json:"webhookRateLimit"     jsonschema:"title=Webhook rate limit,description=Maximum number of webhook requests per second accepted from each source IP address; requests above it are rejected with 429 Too Many Requests. 0 disables the rate limiting (Default: 0),default=0"`
+	WebhookRateBurst     uint64   `json:"webhookRateBurst"     jsonschema:"title=Webhook rate burst,description=Maximum number of webhook requests accepted at once from each source IP address when rate limiting is enabled (Default: 10),default=10"`
+	// WebhookClusters maps webhook paths to the names of the clusters posting to them
+	WebhookClusters map[string]string `json:"webhookClusters" jsonschema:"title=Webhook clusters,description=Cluster names by webhook path (e.g. /k8s-audit/prod). Each path is served by the webhook server in addition to the one of the open parameters and the events posted to it get tagged with the cluster name (Default: empty)"`
 }
 
 // Resets sets the configuration to its default values
 func (k *PluginConfig) Reset() {
 	k.SSLCertificate = "/etc/falco/falco.pem"
+	k.SSLClientCA = ""
+	k.SSLClientAllowedSANs = nil
+	k.WebhookClusters = nil
 	k.UseAsync = true
 	k.MaxEventSize = uint64(sdk.DefaultEvtSize)
 
@@ -38,4 +47,6 @@ func (k *PluginConfig) Reset() {
 	// The following values have been chosen by increasing by ~20% the default
 	// values of the K8S docs
 	k.WebhookMaxBatchSize = 12 * 1024 * 1024
+	k.WebhookRateLimit = 0
+	k.WebhookRateBurst = 10
 }
@@ -440,15 +440,57 @@ func (e *Plugin) ExtractFromJSON(req sdk.ExtractRequest, jsonValue *fastjson.Val
 	case "ka.useragent":
 		return e.extractFromKeys(req, jsonValue, "userAgent")
 	case "ka.sourceips":
-		return e.extractRulesField(req, jsonValue, "sourceIPs")
+		ips := jsonValue.GetArray("sourceIPs")
+		if ips == nil {
+			return ErrExtractNotAvailable
+		}
+		if index := e.argIndexFilter(req); index != noIndexFilter {
+			if index >= len(ips) {
+				return ErrExtractNotAvailable
+			}
+			ips = ips[index : index+1]
+		}
+		req.SetValue(e.arrayAsStringsSkipNil(ips))
 	case "ka.cluster.name":
-		return e.extractFromKeys(req, jsonValue, "annotations", "cluster_name")
+		return e.extractFromKeys(req, jsonValue, "annotations", clusterNameAnnotation)
+	case "ka.node":
+		uri := jsonValue.GetStringBytes("requestURI")
+		if uri == nil {
+			return ErrExtractNotAvailable
+		}
+		node, ok := nodeProxyName(string(uri))
+		if !ok {
+			return ErrExtractNotAvailable
+		}
+		req.SetValue(node)
 	default:
 		return fmt.Errorf("unsupported extraction field: %s", req.Field())
 	}
 	return nil
 }
 
+// nodeProxyName returns the name of the node targeted by a node proxy request
+// URI, i.e. /api/v1/nodes/[<scheme>:]<name>[:<port>]/proxy[/<path>].
+func nodeProxyName(requestURI string) (string, bool) {
+	uri, err := url.Parse(requestURI)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+	if len(parts) < 5 || parts[0] != "api" || parts[1] != "v1" || parts[2] != "nodes" || parts[4] != "proxy" {
+		return "", false
+	}
+	// the node can be qualified as name, name:port or scheme:name:port
+	var name string
+	switch ids := strings.Split(parts[3], ":"); len(ids) {
+	case 1, 2:
+		name = ids[0]
+	case 3:
+		name = ids[1]
+	}
+	return name, len(name) > 0
+}
+
 func (e *Plugin) argIndexFilter(req sdk.ExtractRequest) int {
 	if !req.ArgPresent() {
 		return noIndexFilter
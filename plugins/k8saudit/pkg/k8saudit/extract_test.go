@@ -21,6 +21,7 @@ import (
 	"bufio"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -37,6 +38,7 @@ type testExtractRequest struct {
 	argPresent bool
 	argIndex   uint64
 	argKey     string
+	value      interface{}
 }
 
 type jsonData struct {
@@ -73,7 +75,7 @@ func (t *testExtractRequest) IsList() bool {
 }
 
 func (t *testExtractRequest) SetValue(v interface{}) {
-
+	t.value = v
 }
 
 func (t *testExtractRequest) SetPtr(unsafe.Pointer) {
@@ -172,3 +174,75 @@ func BenchmarkExtractFromJSON(b *testing.B) {
 	b.ReportMetric(exOp, "extractions/op")
 	b.ReportMetric(nsOp/exOp, "ns/extraction/op")
 }
+
+func TestExtractRequestFields(t *testing.T) {
+	const evt = `{"kind":"Event","auditID":"test","verb":"get","requestURI":"/api/v1/nodes/worker-1:10250/proxy/metrics?x=1","userAgent":"kubectl/v1.30.0","sourceIPs":["10.0.0.1","192.168.0.1"],"annotations":{"authorization.k8s.io/decision":"allow"},"stageTimestamp":"2023-01-01T00:00:01.000000Z"}`
+	tests := []struct {
+		req  testExtractRequest
+		want interface{}
+	}{
+		{
+			req:  testExtractRequest{field: "ka.useragent", fieldType: sdk.FieldTypeCharBuf},
+			want: "kubectl/v1.30.0",
+		},
+		{
+			req:  testExtractRequest{field: "ka.auth.decision", fieldType: sdk.FieldTypeCharBuf},
+			want: "allow",
+		},
+		{
+			req:  testExtractRequest{field: "ka.sourceips", fieldType: sdk.FieldTypeCharBuf, isList: true},
+			want: []string{"10.0.0.1", "192.168.0.1"},
+		},
+		{
+			req:  testExtractRequest{field: "ka.sourceips", fieldType: sdk.FieldTypeCharBuf, isList: true, argPresent: true, argIndex: 1},
+			want: []string{"192.168.0.1"},
+		},
+		{
+			req:  testExtractRequest{field: "ka.node", fieldType: sdk.FieldTypeCharBuf},
+			want: "worker-1",
+		},
+	}
+
+	p, _ := newTestPlugin()
+	value, err := p.DecodeReader(1, strings.NewReader(evt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.req.field, func(t *testing.T) {
+			req := tt.req
+			if err := p.ExtractFromJSON(&req, value); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(req.value, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, req.value)
+			}
+		})
+	}
+
+	// out of range indexes are not available
+	req := testExtractRequest{field: "ka.sourceips", fieldType: sdk.FieldTypeCharBuf, isList: true, argPresent: true, argIndex: 2}
+	if err := p.ExtractFromJSON(&req, value); err != ErrExtractNotAvailable {
+		t.Fatalf("expected field not available, got: %v", err)
+	}
+}
+
+func TestNodeProxyName(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/nodes/worker-1/proxy":                  "worker-1",
+		"/api/v1/nodes/worker-1/proxy/":                 "worker-1",
+		"/api/v1/nodes/worker-1:10250/proxy/logs/?x=1":  "worker-1",
+		"/api/v1/nodes/https:worker-1:10250/proxy/pods": "worker-1",
+		"/api/v1/nodes/a:b:c:d/proxy":                   "",
+		"/api/v1/nodes/worker-1":                        "",
+		"/api/v1/nodes/worker-1/status":                 "",
+		"/api/v1/namespaces/default/pods/test/proxy":    "",
+		"/apis/apps/v1/nodes/worker-1/proxy":            "",
+	}
+	for uri, want := range tests {
+		node, ok := nodeProxyName(uri)
+		if node != want || ok != (want != "") {
+			t.Errorf("%s: expected %q, got %q (%v)", uri, want, node, ok)
+		}
+	}
+}
@@ -519,5 +519,10 @@ func (k *Plugin) Fields() []sdk.FieldEntry {
 			Name: "ka.cluster.name",
 			Desc: "The name of the k8s cluster",
 		},
+		{
+			Type: "string",
+			Name: "ka.node",
+			Desc: "The node targeted by node proxy requests (e.g. kubelet API calls through the apiserver), from the request URI",
+		},
 	}
 }
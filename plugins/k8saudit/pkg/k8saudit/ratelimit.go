@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimiterMaxSources is the number of sources above which the
+	// buckets of idle sources are discarded
+	rateLimiterMaxSources = 1024
+)
+
+// rateLimiter limits the rate of the webhook requests of each source with a
+// token bucket, refilled at rate tokens per second up to burst tokens.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a rate limiter allowing rate requests per second to
+// each source, with bursts of burst requests (at least 1), or nil if rate is 0.
+func newRateLimiter(rate, burst uint64) *rateLimiter {
+	if rate == 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:    float64(rate),
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// refill adds the tokens accumulated since the last refill to b.
+func (r *rateLimiter) refill(b *tokenBucket, now time.Time) {
+	b.tokens = min(r.burst, b.tokens+now.Sub(b.last).Seconds()*r.rate)
+	b.last = now
+}
+
+// allow returns true if a request of source is allowed, or the time to wait
+// before the next one is.
+func (r *rateLimiter) allow(source string) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	b, ok := r.buckets[source]
+	if !ok {
+		if len(r.buckets) >= rateLimiterMaxSources {
+			r.discardIdle(now)
+		}
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[source] = b
+	}
+	r.refill(b, now)
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / r.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// discardIdle discards the buckets that got full again, whose sources are
+// allowed as much as new ones.
+func (r *rateLimiter) discardIdle(now time.Time) {
+	for source, b := range r.buckets {
+		if r.refill(b, now); b.tokens >= r.burst {
+			delete(r.buckets, source)
+		}
+	}
+}
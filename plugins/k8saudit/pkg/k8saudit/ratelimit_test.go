@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0, 10) != nil {
+		t.Fatal("expected rate limiting to be disabled")
+	}
+
+	now := time.Unix(0, 0)
+	r := newRateLimiter(2, 3)
+	r.now = func() time.Time { return now }
+
+	// bursts are allowed, then requests are limited at rate
+	for i := 0; i < 3; i++ {
+		if ok, _ := r.allow("10.0.0.1"); !ok {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+	ok, wait := r.allow("10.0.0.1")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected request to be limited for 500ms, got: %v, %v", ok, wait)
+	}
+	// sources are limited independently
+	if ok, _ := r.allow("10.0.0.2"); !ok {
+		t.Fatal("expected request of another source to be allowed")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := r.allow("10.0.0.1"); !ok {
+		t.Fatal("expected request to be allowed after waiting")
+	}
+	if ok, _ := r.allow("10.0.0.1"); ok {
+		t.Fatal("expected request to be limited")
+	}
+}
+
+func TestRateLimiterDiscardIdle(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newRateLimiter(1, 1)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < rateLimiterMaxSources; i++ {
+		r.allow(fmt.Sprintf("source-%d", i))
+	}
+	// buckets are still empty, they are discarded once they get full again
+	r.allow("new")
+	if len(r.buckets) != rateLimiterMaxSources+1 {
+		t.Fatalf("expected no bucket to be discarded, got %d buckets", len(r.buckets))
+	}
+	now = now.Add(time.Second)
+	r.allow("newer")
+	if len(r.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be discarded, got %d buckets", len(r.buckets))
+	}
+}
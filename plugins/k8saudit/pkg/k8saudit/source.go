@@ -19,15 +19,21 @@ package k8saudit
 
 import (
 	"bufio"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,6 +46,24 @@ import (
 const (
 	webServerShutdownTimeoutSecs = 5
 	webServerEventChanBufSize    = 50
+	// webServerRetryAfter is the time clients are asked to wait before
+	// retrying requests rejected since the event buffer is full
+	webServerRetryAfter = time.Second
+	// webServerHealthzPath and webServerReadyzPath are the paths of the
+	// liveness and readiness probes of the webhook
+	webServerHealthzPath = "/healthz"
+	webServerReadyzPath  = "/readyz"
+	// webServerReadyMaxOccupancy is the event buffer occupancy above which
+	// the webhook is reported as not ready
+	webServerReadyMaxOccupancy = 0.9
+	// webServerReadyStallTimeout is the time after which the webhook is
+	// reported as not ready if its buffered events are not consumed
+	webServerReadyStallTimeout = 10 * time.Second
+	// maxRotatedFiles bounds the rotated files remembered while watching files
+	maxRotatedFiles = 16
+	// clusterNameAnnotation is the audit events annotation carrying the
+	// name of their cluster
+	clusterNameAnnotation = "cluster_name"
 )
 
 func (k *Plugin) Open(params string) (source.Instance, error) {
@@ -114,7 +138,7 @@ func (k *Plugin) OpenReader(r io.ReadCloser) (source.Instance, error) {
 		for scanner.Scan() {
 			line := scanner.Text()
 			if len(line) > 0 {
-				k.parseAuditEventsAndPush(&parser, ([]byte)(line), evtC)
+				k.parseAuditEventsAndPush(&parser, ([]byte)(line), "", evtC)
 			}
 		}
 		err := scanner.Err()
@@ -132,12 +156,23 @@ func (k *Plugin) OpenReader(r io.ReadCloser) (source.Instance, error) {
 // OpenFileWatch opens a source.Instance that continuously watches a file for
 // new K8S Audit Events using fsnotify. It watches the parent directory (as
 // recommended by fsnotify) to handle atomic file replacements and log rotation.
+// The path can also be a glob pattern (see filepath.Match) matching the base
+// name of multiple files, eg: the audit logs of several API servers, each one
+// followed independently. Files existing at open are followed from their end,
+// while files created afterwards are read from their beginning.
 func (k *Plugin) OpenFileWatch(path string) (source.Instance, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 	dir := filepath.Dir(absPath)
+	if _, err := filepath.Match(absPath, absPath); err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
+	matches := func(name string) bool {
+		ok, _ := filepath.Match(absPath, name)
+		return ok
+	}
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -152,58 +187,78 @@ func (k *Plugin) OpenFileWatch(path string) (source.Instance, error) {
 		defer watcher.Close()
 
 		var parser fastjson.Parser
-		var file *os.File
-		var offset int64
-
-		openFile := func(seekEnd bool) bool {
-			if file != nil {
-				file.Close()
-				file = nil
+		// followed are the files being followed, by path
+		followed := make(map[string]*followedFile)
+		// rotated are the infos of the followed files that got renamed,
+		// already read up to their end, so that they are not read again if
+		// they get renamed to a name matching the pattern (eg: audit-<ts>.log)
+		var rotated []os.FileInfo
+
+		closeAll := func() {
+			for _, f := range followed {
+				f.file.Close()
+			}
+		}
+		readNewLines := func(f *followedFile) {
+			if err := f.readNewLines(int(k.Config.MaxEventSize), func(line []byte) {
+				k.parseAuditEventsAndPush(&parser, line, "", evtC)
+			}); err != nil {
+				k.logger.Println(err.Error())
+			}
+		}
+		follow := func(name string, seekEnd bool) {
+			if f, ok := followed[name]; ok {
+				f.file.Close()
+				delete(followed, name)
 			}
-			f, err := os.Open(absPath)
+			file, err := os.Open(name)
 			if err != nil {
-				return false
+				return
 			}
-			file = f
+			f := &followedFile{file: file}
 			if seekEnd {
-				offset, _ = file.Seek(0, io.SeekEnd)
-			} else {
-				offset = 0
+				f.offset, _ = file.Seek(0, io.SeekEnd)
 			}
-			return true
+			followed[name] = f
 		}
-
-		readNewLines := func() {
-			if file == nil {
+		unfollow := func(name string, renamed bool) {
+			f, ok := followed[name]
+			if !ok {
 				return
 			}
-			// Detect file truncation (e.g. logrotate copytruncate)
-			if info, err := file.Stat(); err == nil && info.Size() < offset {
-				offset = 0
-			}
-			file.Seek(offset, io.SeekStart)
-			scanner := bufio.NewScanner(file)
-			scanner.Buffer(nil, int(k.Config.MaxEventSize))
-			for scanner.Scan() {
-				line := scanner.Bytes()
-				if len(line) > 0 {
-					k.parseAuditEventsAndPush(&parser, line, evtC)
+			// read the lines written before the file got rotated
+			readNewLines(f)
+			if info, err := f.file.Stat(); err == nil && renamed {
+				rotated = append(rotated, info)
+				if len(rotated) > maxRotatedFiles {
+					rotated = rotated[1:]
 				}
 			}
-			if err := scanner.Err(); err != nil {
-				k.logger.Println(err.Error())
+			f.file.Close()
+			delete(followed, name)
+		}
+		isRotated := func(name string) bool {
+			info, err := os.Stat(name)
+			if err != nil {
+				return false
 			}
-			if pos, err := file.Seek(0, io.SeekCurrent); err == nil {
-				offset = pos
+			for i, r := range rotated {
+				if os.SameFile(info, r) {
+					rotated = append(rotated[:i], rotated[i+1:]...)
+					return true
+				}
 			}
+			return false
 		}
 
-		openFile(true)
+		if names, err := filepath.Glob(absPath); err == nil {
+			for _, name := range names {
+				follow(name, true)
+			}
+		}
 
 		if err := watcher.Add(dir); err != nil {
-			if file != nil {
-				file.Close()
-			}
+			closeAll()
 			evtC <- source.PushEvent{Err: err}
 			return
 		}
@@ -211,38 +266,33 @@ func (k *Plugin) OpenFileWatch(path string) (source.Instance, error) {
 		for {
 			select {
 			case <-ctx.Done():
-				if file != nil {
-					file.Close()
-				}
+				closeAll()
 				return
 			case event, ok := <-watcher.Events:
 				if !ok {
-					if file != nil {
-						file.Close()
-					}
+					closeAll()
 					return
 				}
-				if event.Name != absPath {
+				if !matches(event.Name) {
 					continue
 				}
 				if event.Op&fsnotify.Write == fsnotify.Write {
-					readNewLines()
+					if f, ok := followed[event.Name]; ok {
+						readNewLines(f)
+					}
 				}
-				if event.Op&fsnotify.Create == fsnotify.Create {
-					openFile(false)
-					readNewLines()
+				if event.Op&fsnotify.Create == fsnotify.Create && !isRotated(event.Name) {
+					follow(event.Name, false)
+					if f, ok := followed[event.Name]; ok {
+						readNewLines(f)
+					}
 				}
 				if event.Op&(fsnotify.Rename|fsnotify.Remove) != 0 {
-					if file != nil {
-						file.Close()
-						file = nil
-					}
+					unfollow(event.Name, event.Op&fsnotify.Rename == fsnotify.Rename)
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
-					if file != nil {
-						file.Close()
-					}
+					closeAll()
 					return
 				}
 				k.logger.Println("file watcher error:", err)
@@ -257,14 +307,59 @@ func (k *Plugin) OpenFileWatch(path string) (source.Instance, error) {
 		source.WithInstanceEventSize(uint32(k.Config.MaxEventSize)))
 }
 
+// followedFile is a file followed by OpenFileWatch, read up to offset.
+type followedFile struct {
+	file   *os.File
+	offset int64
+}
+
+// readNewLines calls onLine for each non-empty line written to the file
+// since the last read, lines being at most maxLineSize bytes long.
+func (f *followedFile) readNewLines(maxLineSize int, onLine func([]byte)) error {
+	// Detect file truncation (e.g. logrotate copytruncate)
+	if info, err := f.file.Stat(); err == nil && info.Size() < f.offset {
+		f.offset = 0
+	}
+	f.file.Seek(f.offset, io.SeekStart)
+	scanner := bufio.NewScanner(f.file)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) > 0 {
+			onLine(line)
+		}
+	}
+	err := scanner.Err()
+	if pos, seekErr := f.file.Seek(0, io.SeekCurrent); seekErr == nil {
+		f.offset = pos
+	}
+	return err
+}
+
 // OpenWebServer opens a source.Instance event stream that receives K8S Audit
 // Events by starting a server and listening for JSON webhooks. The expected
 // JSON format is the one of K8S API Server webhook backend
 // (see: https://kubernetes.io/docs/tasks/debug/debug-cluster/audit/#webhook-backend).
 func (k *Plugin) OpenWebServer(address, endpoint string, ssl bool) (source.Instance, error) {
+	var tlsConfig *tls.Config
+	if ssl {
+		var err error
+		if tlsConfig, err = k.webServerTLSConfig(); err != nil {
+			return nil, err
+		}
+	} else if len(k.Config.SSLClientCA) > 0 || len(k.Config.SSLClientAllowedSANs) > 0 {
+		return nil, fmt.Errorf("client certificates verification requires an HTTPS Webhook endpoint")
+	}
+
+	for path := range k.Config.WebhookClusters {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("webhook path %q must start with '/'", path)
+		}
+	}
+
 	ctx, cancelCtx := context.WithCancel(context.Background())
-	serverEvtChan := make(chan []byte, webServerEventChanBufSize)
-	evtChan := make(chan source.PushEvent)
+	serverEvtChan := make(chan webhookPayload, webServerEventChanBufSize)
+	batchChan := make(chan []source.PushEvent)
 
 	// launch webserver gorountine. This listens for webhooks coming from
 	// the k8s api server and sends every valid payload to serverEvtChan so
@@ -272,35 +367,93 @@ func (k *Plugin) OpenWebServer(address, endpoint string, ssl bool) (source.Insta
 	// then parsed to extract the list of audit events contained by the
 	// event-parser goroutine
 	m := http.NewServeMux()
-	s := &http.Server{Addr: address, Handler: m}
-	sendBody := func(b []byte) {
+	s := &http.Server{Addr: address, Handler: m, TLSConfig: tlsConfig}
+	// sendBody returns false if the event buffer is full, so that the
+	// request can be retried later instead of blocking the server
+	sendBody := func(b []byte, cluster string) (sent bool) {
 		defer func() {
 			if r := recover(); r != nil {
 				k.logger.Println("request dropped while shutting down server ")
 			}
 		}()
-		serverEvtChan <- b
-	}
-	m.HandleFunc(endpoint, func(w http.ResponseWriter, req *http.Request) {
-		if req.Method != "POST" {
-			http.Error(w, fmt.Sprintf("%s method not allowed", req.Method), http.StatusMethodNotAllowed)
-			return
+		select {
+		case serverEvtChan <- webhookPayload{body: b, cluster: cluster}:
+			return true
+		default:
+			return false
 		}
-		if !strings.Contains(req.Header.Get("Content-Type"), "application/json") {
-			http.Error(w, "wrong Content Type", http.StatusBadRequest)
-			return
+	}
+	limiter := newRateLimiter(k.Config.WebhookRateLimit, k.Config.WebhookRateBurst)
+	handler := func(cluster string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != "POST" {
+				http.Error(w, fmt.Sprintf("%s method not allowed", req.Method), http.StatusMethodNotAllowed)
+				return
+			}
+			if !strings.Contains(req.Header.Get("Content-Type"), "application/json") {
+				http.Error(w, "wrong Content Type", http.StatusBadRequest)
+				return
+			}
+			if limiter != nil {
+				if ok, wait := limiter.allow(requestSource(req)); !ok {
+					tooManyRequests(w, "rate limit exceeded", wait)
+					return
+				}
+			}
+			bytes, code, err := k.readWebhookBody(w, req)
+			if err != nil {
+				msg := fmt.Sprintf("bad request: %s", err.Error())
+				k.logger.Println(msg)
+				http.Error(w, msg, code)
+				return
+			}
+			if !sendBody(bytes, cluster) {
+				k.logger.Println("request rejected: event buffer full")
+				tooManyRequests(w, "event buffer full", webServerRetryAfter)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
 		}
-		req.Body = http.MaxBytesReader(w, req.Body, int64(k.Config.WebhookMaxBatchSize))
-		bytes, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			msg := fmt.Sprintf("bad request: %s", err.Error())
-			k.logger.Println(msg)
-			http.Error(w, msg, http.StatusBadRequest)
-			return
+	}
+	m.HandleFunc(endpoint, handler(k.Config.WebhookClusters[endpoint]))
+	for path, cluster := range k.Config.WebhookClusters {
+		if path != endpoint {
+			m.HandleFunc(path, handler(cluster))
 		}
-		w.WriteHeader(http.StatusOK)
-		sendBody(bytes)
+	}
+
+	// the event source instance is created in advance, as the readiness
+	// probe depends on its consumption of events
+	inst, err := newBatchInstance(ctx, batchChan, uint32(k.Config.MaxEventSize), func() {
+		// on close, attempt shutting down the webserver gracefully
+		timedCtx, cancelTimeoutCtx := context.WithTimeout(ctx, time.Second*webServerShutdownTimeoutSecs)
+		defer cancelTimeoutCtx()
+		s.Shutdown(timedCtx)
+		cancelCtx()
 	})
+	if err != nil {
+		cancelCtx()
+		return nil, err
+	}
+	probes := map[string]http.HandlerFunc{
+		webServerHealthzPath: func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintln(w, "ok")
+		},
+		webServerReadyzPath: func(w http.ResponseWriter, req *http.Request) {
+			reason := webServerNotReadyReason(ctx, len(serverEvtChan), cap(serverEvtChan), inst.lastReadTime(), time.Now())
+			if len(reason) > 0 {
+				http.Error(w, reason, http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintln(w, "ok")
+		},
+	}
+	for path, probe := range probes {
+		// probes can't shadow the paths receiving audit events
+		if _, ok := k.Config.WebhookClusters[path]; !ok && path != endpoint {
+			m.HandleFunc(path, probe)
+		}
+	}
 	go func() {
 		defer close(serverEvtChan)
 		var err error
@@ -313,42 +466,150 @@ func (k *Plugin) OpenWebServer(address, endpoint string, ssl bool) (source.Insta
 			err = s.ListenAndServe()
 		}
 		if err != nil && err != http.ErrServerClosed {
-			evtChan <- source.PushEvent{Err: err}
+			select {
+			case batchChan <- []source.PushEvent{{Err: err}}:
+			case <-ctx.Done():
+			}
 		}
 	}()
 
 	// launch event-parser gorountine. This received webhook payloads
 	// and parses their content to extract the list of audit events contained.
-	// Then, events are sent to the Push-mode event source instance channel.
+	// Then, the events of each payload are sent at once to the event source
+	// instance, so that large EventLists fill whole event batches.
 	go func() {
-		defer close(evtChan)
+		defer close(batchChan)
 		var parser fastjson.Parser
 		for {
 			select {
-			case bytes, ok := <-serverEvtChan:
+			case payload, ok := <-serverEvtChan:
 				if !ok {
 					return
 				}
-				k.parseAuditEventsAndPush(&parser, bytes, evtChan)
+				batch := k.parseAuditEvents(&parser, payload.body, payload.cluster)
+				if len(batch) == 0 {
+					continue
+				}
+				select {
+				case batchChan <- batch:
+				case <-ctx.Done():
+					return
+				}
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	// open new instance in with "push" prebuilt
-	return source.NewPushInstance(
-		evtChan,
-		source.WithInstanceContext(ctx),
-		source.WithInstanceClose(func() {
-			// on close, attempt shutting down the webserver gracefully
-			timedCtx, cancelTimeoutCtx := context.WithTimeout(ctx, time.Second*webServerShutdownTimeoutSecs)
-			defer cancelTimeoutCtx()
-			s.Shutdown(timedCtx)
-			cancelCtx()
-		}),
-		source.WithInstanceEventSize(uint32(k.Config.MaxEventSize)),
-	)
+	return inst, nil
+}
+
+// webServerNotReadyReason returns why the webhook can't currently handle
+// requests in a timely manner, or an empty string if it can. This happens
+// while shutting down, when the event buffer is almost full, or when the
+// buffered events have not been consumed for a while.
+func webServerNotReadyReason(ctx context.Context, buffered, capacity int, lastRead, now time.Time) string {
+	if ctx.Err() != nil {
+		return "shutting down"
+	}
+	if float64(buffered) >= webServerReadyMaxOccupancy*float64(capacity) {
+		return fmt.Sprintf("event buffer almost full (%d/%d)", buffered, capacity)
+	}
+	if buffered > 0 && now.Sub(lastRead) > webServerReadyStallTimeout {
+		return fmt.Sprintf("events not consumed since %s", now.Sub(lastRead).Truncate(time.Second))
+	}
+	return ""
+}
+
+// readWebhookBody reads the body of a webhook request, decompressing it while
+// reading if it is gzip-encoded. Both the received body and the decoded payload
+// are bounded by WebhookMaxBatchSize, so that compressed batches can't expand
+// without limits. On error, the HTTP status code to reply with is returned too.
+func (k *Plugin) readWebhookBody(w http.ResponseWriter, req *http.Request) ([]byte, int, error) {
+	maxSize := int64(k.Config.WebhookMaxBatchSize)
+	var body io.Reader = http.MaxBytesReader(w, req.Body, maxSize)
+	switch encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, webhookBodyErrorCode(err), err
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("content encoding %s not supported", encoding)
+	}
+
+	// read one more byte to tell apart payloads exceeding the max size
+	bytes, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, webhookBodyErrorCode(err), err
+	}
+	if int64(len(bytes)) > maxSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("decoded payload larger than webhookMaxBatchSize: limit=%d", maxSize)
+	}
+	return bytes, http.StatusOK, nil
+}
+
+func webhookBodyErrorCode(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// requestSource returns the IP address of the client of req.
+func requestSource(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// tooManyRequests replies to a webhook request with 429 Too Many Requests,
+// asking the client to retry after wait (rounded up to seconds).
+func tooManyRequests(w http.ResponseWriter, msg string, wait time.Duration) {
+	secs := int64(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(max(secs, 1), 10))
+	http.Error(w, msg, http.StatusTooManyRequests)
+}
+
+// webhookPayload is the body of a webhook request, along with the name of
+// the cluster configured for its path, if any.
+type webhookPayload struct {
+	body    []byte
+	cluster string
+}
+
+// tagClusterName sets the cluster name annotation, extracted by the
+// ka.cluster.name field, to the audit events contained by value. Annotations
+// already carried by events are overwritten, so that clients posting to the
+// path of a cluster can't claim another one. New values are allocated from
+// arena.
+func tagClusterName(arena *fastjson.Arena, value *fastjson.Value, cluster string) {
+	switch value.Type() {
+	case fastjson.TypeArray:
+		for _, v := range value.GetArray() {
+			tagClusterName(arena, v, cluster)
+		}
+	case fastjson.TypeObject:
+		switch string(value.GetStringBytes("kind")) {
+		case "EventList":
+			for _, item := range value.GetArray("items") {
+				tagClusterName(arena, item, cluster)
+			}
+		case "Event":
+			annotations := value.Get("annotations")
+			if annotations == nil || annotations.Type() != fastjson.TypeObject {
+				annotations = arena.NewObject()
+				value.Set("annotations", annotations)
+			}
+			annotations.Set(clusterNameAnnotation, arena.NewString(cluster))
+		}
+	}
 }
 
 // todo: optimize this to cache by event number
@@ -362,8 +623,17 @@ func (k *Plugin) String(evt sdk.EventReader) (string, error) {
 
 // here we make all errors non-blocking for single events by
 // simply logging them, to ensure consumers don't close the
-// event source with bad or malicious payloads
-func (k *Plugin) parseAuditEventsAndPush(parser *fastjson.Parser, payload []byte, c chan<- source.PushEvent) {
+// event source with bad or malicious payloads. If cluster is
+// not empty, events are tagged with it (see tagClusterName)
+func (k *Plugin) parseAuditEventsAndPush(parser *fastjson.Parser, payload []byte, cluster string, c chan<- source.PushEvent) {
+	for _, v := range k.parseAuditEvents(parser, payload, cluster) {
+		c <- v
+	}
+}
+
+// parseAuditEvents is the same as parseAuditEventsAndPush, but returns
+// all the valid events parsed from the payload at once
+func (k *Plugin) parseAuditEvents(parser *fastjson.Parser, payload []byte, cluster string) (res []source.PushEvent) {
 	// Recover from panics in the JSON parser (e.g., when payload exceeds internal buffer limits)
 	defer func() {
 		if r := recover(); r != nil {
@@ -373,21 +643,27 @@ func (k *Plugin) parseAuditEventsAndPush(parser *fastjson.Parser, payload []byte
 	data, err := parser.ParseBytes(payload)
 	if err != nil {
 		k.logger.Println(err.Error())
-		return
+		return nil
+	}
+	if len(cluster) > 0 {
+		var arena fastjson.Arena
+		tagClusterName(&arena, data, cluster)
 	}
 	values, err := k.ParseAuditEventsJSON(data)
 	if err != nil {
 		k.logger.Println(err.Error())
-		return
+		return nil
 	}
+	res = make([]source.PushEvent, 0, len(values))
 	for _, v := range values {
 		if v.Err != nil {
 			k.logger.Println(v.Err.Error())
 			continue
 		} else {
-			c <- *v
+			res = append(res, *v)
 		}
 	}
+	return res
 }
 
 // ParseAuditEventsPayload parses a byte slice representing a JSON payload
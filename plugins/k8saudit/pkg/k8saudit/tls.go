@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// webServerTLSConfig returns the TLS configuration of the webhook server
+// requiring and verifying the client certificates, or nil if no client CA
+// bundle is configured. When allowed SANs are configured, the client
+// certificate must also carry at least one of them, which requires a client
+// CA bundle.
+func (k *Plugin) webServerTLSConfig() (*tls.Config, error) {
+	if len(k.Config.SSLClientCA) == 0 {
+		if len(k.Config.SSLClientAllowedSANs) > 0 {
+			return nil, fmt.Errorf("client certificates SANs verification requires a client CA bundle")
+		}
+		return nil, nil
+	}
+	caPEM, err := os.ReadFile(k.Config.SSLClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("can't read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no valid certificate found in client CA bundle %s", k.Config.SSLClientCA)
+	}
+	allowedSANs := k.Config.SSLClientAllowedSANs
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(allowedSANs) == 0 || len(cs.PeerCertificates) == 0 {
+				return nil
+			}
+			if !clientCertSANAllowed(cs.PeerCertificates[0], allowedSANs) {
+				return fmt.Errorf("client certificate SANs not allowed")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// clientCertSANAllowed returns true if any of the subject alternative names
+// of cert (DNS names, IP addresses, email addresses or URIs) is allowed.
+func clientCertSANAllowed(cert *x509.Certificate, allowedSANs []string) bool {
+	sans := slices.Clone(cert.DNSNames)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, san := range sans {
+		if slices.Contains(allowedSANs, san) {
+			return true
+		}
+	}
+	return false
+}
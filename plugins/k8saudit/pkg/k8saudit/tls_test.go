@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCA writes a self-signed CA certificate to a PEM file and returns
+// its path.
+func writeTestCA(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWebServerTLSConfig(t *testing.T) {
+	p, _ := newTestPlugin()
+
+	// no client CA bundle, client certificates are not required
+	cfg, err := p.webServerTLSConfig()
+	if err != nil || cfg != nil {
+		t.Fatalf("expected no TLS config, got: %v, %v", cfg, err)
+	}
+
+	// allowed SANs can't be verified without a client CA bundle
+	p.Config.SSLClientAllowedSANs = []string{"kube-apiserver"}
+	if _, err := p.webServerTLSConfig(); err == nil {
+		t.Fatal("expected an error for allowed SANs without a client CA bundle")
+	}
+	p.Config.SSLClientAllowedSANs = nil
+
+	p.Config.SSLClientCA = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := p.webServerTLSConfig(); err == nil {
+		t.Fatal("expected an error for a missing client CA bundle")
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p.Config.SSLClientCA = invalid
+	if _, err := p.webServerTLSConfig(); err == nil {
+		t.Fatal("expected an error for an invalid client CA bundle")
+	}
+
+	p.Config.SSLClientCA = writeTestCA(t)
+	cfg, err = p.webServerTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("expected client certificates to be required, got: %v", cfg.ClientAuth)
+	}
+
+	client := &x509.Certificate{DNSNames: []string{"kube-apiserver"}}
+	// any client certificate signed by the CA is allowed by default
+	if err := cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}); err != nil {
+		t.Fatal(err)
+	}
+
+	p.Config.SSLClientAllowedSANs = []string{"kube-apiserver"}
+	cfg, err = p.webServerTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}); err != nil {
+		t.Fatal(err)
+	}
+	other := &x509.Certificate{DNSNames: []string{"attacker"}}
+	if err := cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}}); err == nil {
+		t.Fatal("expected an error for a client certificate with SANs not allowed")
+	}
+}
+
+func TestClientCertSANAllowed(t *testing.T) {
+	uri, _ := url.Parse("spiffe://cluster.local/ns/kube-system/sa/kube-apiserver")
+	cert := &x509.Certificate{
+		DNSNames:       []string{"kube-apiserver.kube-system.svc"},
+		EmailAddresses: []string{"apiserver@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{uri},
+	}
+	for _, san := range []string{
+		"kube-apiserver.kube-system.svc",
+		"apiserver@example.com",
+		"10.0.0.1",
+		"spiffe://cluster.local/ns/kube-system/sa/kube-apiserver",
+	} {
+		if !clientCertSANAllowed(cert, []string{"other", san}) {
+			t.Errorf("expected SAN %s to be allowed", san)
+		}
+	}
+	if clientCertSANAllowed(cert, []string{"kube-apiserver", "10.0.0.2"}) {
+		t.Error("expected SANs not to be allowed")
+	}
+}
+
+func TestOpenWebServerClientCA(t *testing.T) {
+	p, _ := newTestPlugin()
+	p.Config.SSLClientCA = writeTestCA(t)
+	if _, err := p.OpenWebServer("127.0.0.1:0", "/k8s-audit", false); err == nil {
+		t.Fatal("expected an error for client certificates verification over HTTP")
+	}
+	p.Config.SSLClientCA = ""
+	p.Config.SSLClientAllowedSANs = []string{"kube-apiserver"}
+	if _, err := p.OpenWebServer("127.0.0.1:0", "/k8s-audit", false); err == nil {
+		t.Fatal("expected an error for client certificates SANs verification over HTTP")
+	}
+}
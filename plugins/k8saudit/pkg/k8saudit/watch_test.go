@@ -216,3 +216,94 @@ func TestOpen_FileScheme(t *testing.T) {
 	}
 	defer inst.(sdk.Closer).Close()
 }
+
+func TestOpenFileWatch_FollowsPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"audit-a.log", "audit-b.log"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("EXISTING\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, logBuf := newTestPlugin()
+	inst, err := p.OpenFileWatch(filepath.Join(tmpDir, "audit-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	f, err := os.OpenFile(filepath.Join(tmpDir, "audit-b.log"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("BMARKER\n")
+	f.Close()
+	// Files created after open are read from their beginning
+	if err := os.WriteFile(filepath.Join(tmpDir, "audit-c.log"), []byte("CMARKER\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Files not matching the pattern are ignored
+	if err := os.WriteFile(filepath.Join(tmpDir, "other.log"), []byte("XMARKER\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	logged := logBuf.String()
+	for _, marker := range []string{"BMARKER", "CMARKER"} {
+		if !strings.Contains(logged, marker) {
+			t.Errorf("watcher did not process %s, log: %s", marker, logged)
+		}
+	}
+	for _, marker := range []string{"EXISTING", "XMARKER"} {
+		if strings.Contains(logged, marker) {
+			t.Errorf("watcher unexpectedly processed %s, log: %s", marker, logged)
+		}
+	}
+}
+
+func TestOpenFileWatch_HandlesRenameRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "audit.log")
+
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, logBuf := newTestPlugin()
+	inst, err := p.OpenFileWatch(filepath.Join(tmpDir, "audit*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Simulate a rotation renaming the file right after a write, as done by
+	// the API server: the rotated file matches the pattern too, but its lines
+	// must be read once
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("BEFORE\n")
+	f.Close()
+	if err := os.Rename(filePath, filepath.Join(tmpDir, "audit-2026-01-01T00-00-00.000.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte("AFTER\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	logged := logBuf.String()
+	if n := strings.Count(logged, "BEFORE"); n != 1 {
+		t.Errorf("expected lines written before the rotation to be read once, read %d times, log: %s", n, logged)
+	}
+	if !strings.Contains(logged, "AFTER") {
+		t.Errorf("watcher did not process rotated file content, log: %s", logged)
+	}
+}
@@ -0,0 +1,333 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8saudit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
+	"github.com/valyala/fastjson"
+)
+
+func gzipBody(t *testing.T, data string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadWebhookBody(t *testing.T) {
+	p, _ := newTestPlugin()
+	p.Config.WebhookMaxBatchSize = uint64(len(testAuditEvent))
+
+	tests := []struct {
+		name     string
+		body     func() *bytes.Buffer
+		encoding string
+		wantCode int
+	}{
+		{
+			name:     "plain",
+			body:     func() *bytes.Buffer { return bytes.NewBufferString(testAuditEvent) },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "gzip",
+			body:     func() *bytes.Buffer { return gzipBody(t, testAuditEvent) },
+			encoding: "gzip",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "plain too large",
+			body:     func() *bytes.Buffer { return bytes.NewBufferString(testAuditEvent + " ") },
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			// compresses well below the max size, but expands beyond it
+			name:     "gzip decoded too large",
+			body:     func() *bytes.Buffer { return gzipBody(t, testAuditEvent+strings.Repeat(" ", 1024)) },
+			encoding: "gzip",
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "gzip corrupted",
+			body:     func() *bytes.Buffer { return bytes.NewBufferString(testAuditEvent) },
+			encoding: "gzip",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unsupported encoding",
+			body:     func() *bytes.Buffer { return bytes.NewBufferString(testAuditEvent) },
+			encoding: "br",
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/k8s-audit", tt.body())
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			data, code, err := p.readWebhookBody(httptest.NewRecorder(), req)
+			if code != tt.wantCode {
+				t.Fatalf("expected status code %d, got %d (err: %v)", tt.wantCode, code, err)
+			}
+			if code != http.StatusOK {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != testAuditEvent {
+				t.Fatalf("unexpected body: %s", data)
+			}
+		})
+	}
+}
+
+func TestTagClusterName(t *testing.T) {
+	tagged := `{"kind":"Event","auditID":"tagged","annotations":{"cluster_name":"other"}}`
+	payload := `{"kind":"EventList","items":[` + testAuditEvent + `,` + tagged + `]}`
+	value, err := fastjson.Parse(`[` + payload + `,{"kind":"Unknown"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var arena fastjson.Arena
+	tagClusterName(&arena, value, "prod")
+	items := value.GetArray("0", "items")
+	if len(items) != 2 {
+		t.Fatalf("unexpected items: %s", value)
+	}
+	if cluster := string(items[0].GetStringBytes("annotations", clusterNameAnnotation)); cluster != "prod" {
+		t.Fatalf("expected cluster name prod, got %q", cluster)
+	}
+	// the cluster name already carried by events is overwritten
+	if cluster := string(items[1].GetStringBytes("annotations", clusterNameAnnotation)); cluster != "prod" {
+		t.Fatalf("expected cluster name prod, got %q", cluster)
+	}
+	if value.Get("1", "annotations") != nil {
+		t.Fatalf("unexpected annotations on non-event objects: %s", value)
+	}
+}
+
+func TestOpenWebServerClusters(t *testing.T) {
+	p, _ := newTestPlugin()
+	p.Config.WebhookClusters = map[string]string{"/k8s-audit/prod": "prod"}
+	inst, url := openTestWebServerInstance(t, p)
+
+	// clients posting to the path of a cluster can't claim another one
+	forged := strings.TrimSuffix(testAuditEvent, "}") + `,"annotations":{"cluster_name":"staging"}}`
+	resp, err := http.Post(url+"/prod", "application/json", strings.NewReader(forged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+
+	evts := newTestEventWriters(1)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		n, err := inst.NextBatch(nil, evts)
+		if err == sdk.ErrTimeout {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 1 {
+			t.Fatalf("expected 1 event, got %d", n)
+		}
+		value, err := fastjson.ParseBytes(evts[0].data.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cluster := string(value.GetStringBytes("annotations", clusterNameAnnotation)); cluster != "prod" {
+			t.Fatalf("expected cluster name prod, got %q", cluster)
+		}
+		return
+	}
+	t.Fatal("expected an event")
+}
+
+// openTestWebServer opens the webhook server of p on a free local port,
+// returning the URL of its endpoint.
+func openTestWebServer(t *testing.T, p *Plugin) string {
+	_, url := openTestWebServerInstance(t, p)
+	return url
+}
+
+// openTestWebServerInstance is the same as openTestWebServer, but returns
+// the opened event source instance too.
+func openTestWebServerInstance(t *testing.T, p *Plugin) (source.Instance, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	inst, err := p.OpenWebServer(address, "/k8s-audit", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { inst.(sdk.Closer).Close() })
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return inst, "http://" + address + "/k8s-audit"
+}
+
+func postAuditEvent(t *testing.T, url string) *http.Response {
+	resp, err := http.Post(url, "application/json", strings.NewReader(testAuditEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestOpenWebServerRateLimit(t *testing.T) {
+	p, _ := newTestPlugin()
+	p.Config.WebhookRateLimit = 1
+	p.Config.WebhookRateBurst = 2
+	url := openTestWebServer(t, p)
+
+	for i := 0; i < 2; i++ {
+		if resp := postAuditEvent(t, url); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected request %d to be accepted, got status code %d", i, resp.StatusCode)
+		}
+	}
+	resp := postAuditEvent(t, url)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected request to be rate limited, got status code %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "1" {
+		t.Fatalf("expected Retry-After of 1 second, got %q", retryAfter)
+	}
+}
+
+func TestOpenWebServerBackpressure(t *testing.T) {
+	p, _ := newTestPlugin()
+	url := openTestWebServer(t, p)
+
+	// events are not consumed: once the event buffer is full, requests are
+	// rejected instead of blocking
+	var rejected *http.Response
+	for i := 0; i < webServerEventChanBufSize+10 && rejected == nil; i++ {
+		if resp := postAuditEvent(t, url); resp.StatusCode == http.StatusTooManyRequests {
+			rejected = resp
+		} else if resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code %d", resp.StatusCode)
+		}
+	}
+	if rejected == nil {
+		t.Fatal("expected requests to be rejected once the event buffer is full")
+	}
+	if retryAfter := rejected.Header.Get("Retry-After"); retryAfter == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+}
+
+func TestWebServerNotReadyReason(t *testing.T) {
+	now := time.Now()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tCases := map[string]struct {
+		ctx      context.Context
+		buffered int
+		lastRead time.Time
+		ready    bool
+	}{
+		"empty":                {ctx: context.Background(), lastRead: now.Add(-time.Hour), ready: true},
+		"consumed":             {ctx: context.Background(), buffered: 10, lastRead: now, ready: true},
+		"almost full":          {ctx: context.Background(), buffered: 45, lastRead: now},
+		"not consumed":         {ctx: context.Background(), buffered: 1, lastRead: now.Add(-time.Minute)},
+		"shutting down":        {ctx: canceled, lastRead: now},
+		"below full threshold": {ctx: context.Background(), buffered: 44, lastRead: now, ready: true},
+	}
+	for name, tc := range tCases {
+		t.Run(name, func(t *testing.T) {
+			reason := webServerNotReadyReason(tc.ctx, tc.buffered, 50, tc.lastRead, now)
+			if ready := reason == ""; ready != tc.ready {
+				t.Fatalf("expected ready=%v, got reason %q", tc.ready, reason)
+			}
+		})
+	}
+}
+
+func TestOpenWebServerProbes(t *testing.T) {
+	p, _ := newTestPlugin()
+	url := strings.TrimSuffix(openTestWebServer(t, p), "/k8s-audit")
+
+	for _, path := range []string{webServerHealthzPath, webServerReadyzPath} {
+		resp, err := http.Get(url + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected %s to succeed, got status code %d", path, resp.StatusCode)
+		}
+	}
+
+	// events are not consumed: once the event buffer is almost full, the
+	// webhook is not ready anymore while still being alive
+	for i := 0; i < webServerEventChanBufSize; i++ {
+		postAuditEvent(t, url+"/k8s-audit")
+	}
+	resp, err := http.Get(url + webServerReadyzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected not ready webhook, got status code %d", resp.StatusCode)
+	}
+	resp, err = http.Get(url + webServerHealthzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected alive webhook, got status code %d", resp.StatusCode)
+	}
+}
@@ -46,6 +46,10 @@ K8sMetaClient::K8sMetaClient(const std::string& node_name,
     (*sel.mutable_resourcekinds())["Service"] = "true";
     (*sel.mutable_resourcekinds())["ReplicaSet"] = "true";
     (*sel.mutable_resourcekinds())["ReplicaController"] = "true";
+    (*sel.mutable_resourcekinds())["Job"] = "true";
+    (*sel.mutable_resourcekinds())["CronJob"] = "true";
+    (*sel.mutable_resourcekinds())["NetworkPolicy"] = "true";
+    (*sel.mutable_resourcekinds())["HorizontalPodAutoscaler"] = "true";
 
     if(!ca_PEM_encoding.empty())
     {
@@ -131,6 +131,36 @@ falcosecurity::init_schema my_plugin::get_init_schema()
 			"title": "The path to the PEM encoding of the server root certificates",
 			"description": "The path to the PEM encoding of the server root certificates. E.g. '/etc/ssl/certs/ca-certificates.crt'"
 		},
+		"namespaces": {
+			"type": "array",
+			"items": {
+				"type": "string"
+			},
+			"title": "The namespaces whose resources are kept",
+			"description": "If not empty, only the namespaced resources belonging to these namespaces (and the namespaces themselves) are kept by the plugin. The collector Watch API has no namespace selector: resources are still received from the collector, and dropped before their spec and status are parsed. E.g. '[\"default\", \"prod\"]'"
+		},
+		"labelSelector": {
+			"type": "object",
+			"additionalProperties": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "string"
+				}
+			},
+			"title": "The labels that resources must have to be kept, by resource kind",
+			"description": "If not empty, only the resources of the specified kinds having all the labels with the same values are kept by the plugin; the other kinds are not affected. As for 'namespaces', resources are still received from the collector. E.g. '{\"Pod\": {\"app\": \"nginx\"}}'"
+		},
+		"annotationSelector": {
+			"type": "object",
+			"additionalProperties": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "string"
+				}
+			},
+			"title": "The annotations that resources must have to be kept, by resource kind",
+			"description": "If not empty, only the resources of the specified kinds having all the annotations with the same values are kept by the plugin; the other kinds are not affected. Annotations are matched against the metadata sent by the collector. As for 'namespaces', resources are still received from the collector. E.g. '{\"Deployment\": {\"falco.org/monitor\": \"true\"}}'"
+		},
 		"hostProc": {
 			"type": "string",
 			"title": "[DEPRECATED] Path to reach the '/proc' folder we want to scan.",
@@ -252,6 +282,39 @@ void my_plugin::parse_init_config(nlohmann::json& config_json)
         }
     }
 
+    // Scoping
+    m_namespaces.clear();
+    if(config_json.contains(nlohmann::json::json_pointer(NAMESPACES_PATH)))
+    {
+        std::vector<std::string> namespaces;
+        config_json.at(nlohmann::json::json_pointer(NAMESPACES_PATH))
+                .get_to(namespaces);
+        m_namespaces.insert(namespaces.begin(), namespaces.end());
+        SPDLOG_DEBUG("metadata are collected only from {} namespaces",
+                     m_namespaces.size());
+    }
+
+    m_label_selectors.clear();
+    if(config_json.contains(nlohmann::json::json_pointer(LABEL_SELECTOR_PATH)))
+    {
+        config_json.at(nlohmann::json::json_pointer(LABEL_SELECTOR_PATH))
+                .get_to(m_label_selectors);
+        SPDLOG_DEBUG("metadata are collected only from {} resource kinds "
+                     "matching labels",
+                     m_label_selectors.size());
+    }
+
+    m_annotation_selectors.clear();
+    if(config_json.contains(
+               nlohmann::json::json_pointer(ANNOTATION_SELECTOR_PATH)))
+    {
+        config_json.at(nlohmann::json::json_pointer(ANNOTATION_SELECTOR_PATH))
+                .get_to(m_annotation_selectors);
+        SPDLOG_DEBUG("metadata are collected only from {} resource kinds "
+                     "matching annotations",
+                     m_annotation_selectors.size());
+    }
+
     // TODO: clean this up after deprecation period is over
     if(config_json.contains(nlohmann::json::json_pointer("/hostProc")))
     {
@@ -535,12 +598,113 @@ std::vector<falcosecurity::field_info> my_plugin::get_fields()
              "Kubernetes replication controller comma-separated key/value "
              "labels. E.g. '(foo1:bar1,foo2:bar2)'.",
              falcosecurity::field_arg(), true},
+            {ft::FTYPE_STRING, "k8smeta.job.name", "Job Name",
+             "Kubernetes job name."},
+            {ft::FTYPE_STRING, "k8smeta.job.uid", "Job UID",
+             "Kubernetes job UID."},
+            {ft::FTYPE_STRING,
+             "k8smeta.job.label",
+             "Job Label",
+             "Kubernetes job label. E.g. 'k8smeta.job.label[foo]'.",
+             {.key = true, .required = true}},
+            {ft::FTYPE_STRING, "k8smeta.job.labels", "Job Labels",
+             "Kubernetes job comma-separated key/value labels. E.g. "
+             "'(foo1:bar1,foo2:bar2)'.",
+             falcosecurity::field_arg(), true},
+
+            {ft::FTYPE_STRING, "k8smeta.cronjob.name", "Cron Job Name",
+             "Kubernetes cron job name."},
+            {ft::FTYPE_STRING, "k8smeta.cronjob.uid", "Cron Job UID",
+             "Kubernetes cron job UID."},
+            {ft::FTYPE_STRING,
+             "k8smeta.cronjob.label",
+             "Cron Job Label",
+             "Kubernetes cron job label. E.g. 'k8smeta.cronjob.label[foo]'.",
+             {.key = true, .required = true}},
+            {ft::FTYPE_STRING, "k8smeta.cronjob.labels", "Cron Job Labels",
+             "Kubernetes cron job comma-separated key/value labels. E.g. "
+             "'(foo1:bar1,foo2:bar2)'.",
+             falcosecurity::field_arg(), true},
+
+            {ft::FTYPE_STRING, "k8smeta.netpol.name", "Network Policies Name",
+             "Kubernetes network policies name. Return a list with all the "
+             "names of the network policies selecting the current pod. E.g. "
+             "'(policy1,policy2)'",
+             falcosecurity::field_arg(), true},
+            {ft::FTYPE_STRING, "k8smeta.netpol.uid", "Network Policies UID",
+             "Kubernetes network policies UID. Return a list with all the UIDs "
+             "of the network policies selecting the current pod.",
+             falcosecurity::field_arg(), true},
+            {ft::FTYPE_STRING,
+             "k8smeta.netpol.label",
+             "Network Policies Label",
+             "Kubernetes network policies label. If the network policies "
+             "selecting the current pod have a label with this name, return "
+             "the list of label's values. E.g. 'k8smeta.netpol.label[foo]'.",
+             {.key = true, .required = true},
+             true},
+            {ft::FTYPE_STRING, "k8smeta.netpol.labels",
+             "Network Policies Labels",
+             "Kubernetes network policies labels. Return a list with all the "
+             "comma-separated key/value labels of the network policies "
+             "selecting the current pod. E.g. '(foo1:bar1,foo2:bar2)'",
+             falcosecurity::field_arg(), true},
+
+            {ft::FTYPE_STRING, "k8smeta.hpa.name",
+             "Horizontal Pod Autoscaler Name",
+             "Kubernetes horizontal pod autoscaler name. The autoscaler is the "
+             "one scaling the deployment, replica set or replication "
+             "controller of the current pod."},
+            {ft::FTYPE_STRING, "k8smeta.hpa.uid",
+             "Horizontal Pod Autoscaler UID",
+             "Kubernetes horizontal pod autoscaler UID."},
+            {ft::FTYPE_STRING,
+             "k8smeta.hpa.label",
+             "Horizontal Pod Autoscaler Label",
+             "Kubernetes horizontal pod autoscaler label. E.g. "
+             "'k8smeta.hpa.label[foo]'.",
+             {.key = true, .required = true}},
+            {ft::FTYPE_STRING, "k8smeta.hpa.labels",
+             "Horizontal Pod Autoscaler Labels",
+             "Kubernetes horizontal pod autoscaler comma-separated key/value "
+             "labels. E.g. '(foo1:bar1,foo2:bar2)'.",
+             falcosecurity::field_arg(), true},
     };
     const int fields_size = sizeof(fields) / sizeof(fields[0]);
     static_assert(fields_size == K8S_FIELD_MAX, "Wrong number of k8s fields.");
     return std::vector<falcosecurity::field_info>(fields, fields + fields_size);
 }
 
+std::unordered_map<std::string, resource_layout>*
+my_plugin::get_table(enum K8sResource resource)
+{
+    switch(resource)
+    {
+    case POD:
+        return &m_pod_table;
+    case NS:
+        return &m_namespace_table;
+    case DEPLOYMENT:
+        return &m_deployment_table;
+    case SVC:
+        return &m_service_table;
+    case RS:
+        return &m_replicaset_table;
+    case RC:
+        return &m_replication_controller_table;
+    case JOB:
+        return &m_job_table;
+    case CRONJOB:
+        return &m_cronjob_table;
+    case NETPOL:
+        return &m_network_policy_table;
+    case HPA:
+        return &m_hpa_table;
+    default:
+        return nullptr;
+    }
+}
+
 bool inline my_plugin::get_uid_array(nlohmann::json& pod_refs_json,
                                      enum K8sResource resource,
                                      std::vector<std::string>& uid_array)
@@ -568,11 +732,41 @@ bool inline my_plugin::get_uid_array(nlohmann::json& pod_refs_json,
         json_path = "/resources/ReplicationController/list";
         break;
 
+    case JOB:
+        json_path = "/resources/Job/list";
+        break;
+
+    case CRONJOB:
+        json_path = "/resources/CronJob/list";
+        break;
+
+    case NETPOL:
+        json_path = "/resources/NetworkPolicy/list";
+        break;
+
+    case HPA:
+        json_path = "/resources/HorizontalPodAutoscaler/list";
+        break;
+
     default:
         return false;
     }
     if(!pod_refs_json.contains(nlohmann::json::json_pointer(json_path)))
     {
+        // Pods are owned by jobs, which are in turn owned by cron jobs, while
+        // autoscalers refer to the workloads they scale. So, if the pod refs
+        // don't contain them, we look for them through the pod's workloads.
+        if(resource == CRONJOB)
+        {
+            resource_layout job_layout;
+            return get_layout(pod_refs_json, JOB, job_layout) &&
+                   job_layout.refs.is_object() &&
+                   get_uid_array(job_layout.refs, CRONJOB, uid_array);
+        }
+        if(resource == HPA)
+        {
+            return get_hpa_uid_array(pod_refs_json, uid_array);
+        }
         return false;
     }
     pod_refs_json.at(nlohmann::json::json_pointer(json_path)).get_to(uid_array);
@@ -587,6 +781,49 @@ bool inline my_plugin::get_uid_array(nlohmann::json& pod_refs_json,
     return true;
 }
 
+// Find the autoscalers whose scale target is one of the pod's workloads
+bool inline my_plugin::get_hpa_uid_array(nlohmann::json& pod_refs_json,
+                                         std::vector<std::string>& uid_array)
+{
+    const std::pair<enum K8sResource, std::string> targets[] = {
+            {DEPLOYMENT, "Deployment"},
+            {RS, "ReplicaSet"},
+            {RC, "ReplicationController"},
+    };
+    const auto kind_path = nlohmann::json::json_pointer(SCALE_TARGET_KIND_PATH);
+    const auto name_path = nlohmann::json::json_pointer(SCALE_TARGET_NAME_PATH);
+    const auto ns_path = nlohmann::json::json_pointer(NAMESPACE_PATH);
+    const auto target_name_path = nlohmann::json::json_pointer(NAME_PATH);
+    for(const auto& target : targets)
+    {
+        resource_layout target_layout;
+        if(!get_layout(pod_refs_json, target.first, target_layout) ||
+           !target_layout.meta.is_object())
+        {
+            continue;
+        }
+        std::string name = target_layout.meta.value(target_name_path, "");
+        std::string ns = target_layout.meta.value(ns_path, "");
+        for(const auto& hpa : m_hpa_table)
+        {
+            const auto& spec = hpa.second.spec;
+            const auto& meta = hpa.second.meta;
+            if(spec.is_object() && meta.is_object() &&
+               spec.value(kind_path, "") == target.second &&
+               spec.value(name_path, "") == name &&
+               meta.value(ns_path, "") == ns)
+            {
+                uid_array.push_back(hpa.first);
+            }
+        }
+        if(!uid_array.empty())
+        {
+            return true;
+        }
+    }
+    return false;
+}
+
 bool inline my_plugin::get_layout(nlohmann::json& pod_refs_json,
                                   enum K8sResource resource,
                                   resource_layout& layout)
@@ -597,35 +834,14 @@ bool inline my_plugin::get_layout(nlohmann::json& pod_refs_json,
         return false;
     }
 
-    std::unordered_map<std::string, resource_layout> table;
-    switch(resource)
+    auto table = get_table(resource);
+    if(table == nullptr)
     {
-    case NS:
-        table = m_namespace_table;
-        break;
-
-    case DEPLOYMENT:
-        table = m_deployment_table;
-        break;
-
-    case SVC:
-        table = m_service_table;
-        break;
-
-    case RS:
-        table = m_replicaset_table;
-        break;
-
-    case RC:
-        table = m_replication_controller_table;
-        break;
-
-    default:
         return false;
     }
 
-    auto it = table.find(uid_array[0]);
-    if(it == table.end())
+    auto it = table->find(uid_array[0]);
+    if(it == table->end())
     {
         return false;
     }
@@ -784,30 +1000,9 @@ bool inline my_plugin::extract_name_array_from_refs(
         return false;
     }
 
-    std::unordered_map<std::string, resource_layout> table;
-    switch(resource)
+    auto table = get_table(resource);
+    if(table == nullptr)
     {
-    case NS:
-        table = m_namespace_table;
-        break;
-
-    case DEPLOYMENT:
-        table = m_deployment_table;
-        break;
-
-    case SVC:
-        table = m_service_table;
-        break;
-
-    case RS:
-        table = m_replicaset_table;
-        break;
-
-    case RC:
-        table = m_replication_controller_table;
-        break;
-
-    default:
         return false;
     }
 
@@ -815,8 +1010,8 @@ bool inline my_plugin::extract_name_array_from_refs(
     std::string name;
     for(const auto& uid : uid_array)
     {
-        auto it = table.find(uid);
-        if(it == table.end())
+        auto it = table->find(uid);
+        if(it == table->end())
         {
             continue;
         }
@@ -857,30 +1052,9 @@ bool inline my_plugin::extract_label_value_array_from_refs(
         return false;
     }
 
-    std::unordered_map<std::string, resource_layout> table;
-    switch(resource)
+    auto table = get_table(resource);
+    if(table == nullptr)
     {
-    case NS:
-        table = m_namespace_table;
-        break;
-
-    case DEPLOYMENT:
-        table = m_deployment_table;
-        break;
-
-    case SVC:
-        table = m_service_table;
-        break;
-
-    case RS:
-        table = m_replicaset_table;
-        break;
-
-    case RC:
-        table = m_replication_controller_table;
-        break;
-
-    default:
         return false;
     }
 
@@ -889,8 +1063,8 @@ bool inline my_plugin::extract_label_value_array_from_refs(
     std::unordered_map<std::string, std::string> labels_map;
     for(const auto& uid : uid_array)
     {
-        auto layout_it = table.find(uid);
-        if(layout_it == table.end())
+        auto layout_it = table->find(uid);
+        if(layout_it == table->end())
         {
             continue;
         }
@@ -931,30 +1105,9 @@ bool inline my_plugin::extract_labels_array_from_refs(
         return false;
     }
 
-    std::unordered_map<std::string, resource_layout> table;
-    switch(resource)
+    auto table = get_table(resource);
+    if(table == nullptr)
     {
-    case NS:
-        table = m_namespace_table;
-        break;
-
-    case DEPLOYMENT:
-        table = m_deployment_table;
-        break;
-
-    case SVC:
-        table = m_service_table;
-        break;
-
-    case RS:
-        table = m_replicaset_table;
-        break;
-
-    case RC:
-        table = m_replication_controller_table;
-        break;
-
-    default:
         return false;
     }
 
@@ -962,8 +1115,8 @@ bool inline my_plugin::extract_labels_array_from_refs(
     std::unordered_map<std::string, std::string> labels_map;
     for(const auto& uid : uid_array)
     {
-        auto layout_it = table.find(uid);
-        if(layout_it == table.end())
+        auto layout_it = table->find(uid);
+        if(layout_it == table->end())
         {
             continue;
         }
@@ -1130,6 +1283,39 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input& in)
         return extract_label_value_from_refs(pod_layout.refs, RC, req);
     case K8S_RC_LABELS:
         return extract_labels_from_refs(pod_layout.refs, RC, req);
+    case K8S_JOB_NAME:
+        return extract_name_from_refs(pod_layout.refs, JOB, req);
+    case K8S_JOB_UID:
+        return extract_uid_from_refs(pod_layout.refs, JOB, req);
+    case K8S_JOB_LABEL:
+        return extract_label_value_from_refs(pod_layout.refs, JOB, req);
+    case K8S_JOB_LABELS:
+        return extract_labels_from_refs(pod_layout.refs, JOB, req);
+    case K8S_CRONJOB_NAME:
+        return extract_name_from_refs(pod_layout.refs, CRONJOB, req);
+    case K8S_CRONJOB_UID:
+        return extract_uid_from_refs(pod_layout.refs, CRONJOB, req);
+    case K8S_CRONJOB_LABEL:
+        return extract_label_value_from_refs(pod_layout.refs, CRONJOB, req);
+    case K8S_CRONJOB_LABELS:
+        return extract_labels_from_refs(pod_layout.refs, CRONJOB, req);
+    case K8S_NETPOL_NAME:
+        return extract_name_array_from_refs(pod_layout.refs, NETPOL, req);
+    case K8S_NETPOL_UID:
+        return extract_uid_array_from_refs(pod_layout.refs, NETPOL, req);
+    case K8S_NETPOL_LABEL:
+        return extract_label_value_array_from_refs(pod_layout.refs, NETPOL,
+                                                   req);
+    case K8S_NETPOL_LABELS:
+        return extract_labels_array_from_refs(pod_layout.refs, NETPOL, req);
+    case K8S_HPA_NAME:
+        return extract_name_from_refs(pod_layout.refs, HPA, req);
+    case K8S_HPA_UID:
+        return extract_uid_from_refs(pod_layout.refs, HPA, req);
+    case K8S_HPA_LABEL:
+        return extract_label_value_from_refs(pod_layout.refs, HPA, req);
+    case K8S_HPA_LABELS:
+        return extract_labels_from_refs(pod_layout.refs, HPA, req);
 
     default:
         SPDLOG_ERROR(
@@ -1145,6 +1331,65 @@ bool my_plugin::extract(const falcosecurity::extract_fields_input& in)
 // Parse capability
 //////////////////////////
 
+// Check that the map at `path` in the resource meta contains all the selector
+// entries, with the same values.
+static inline bool
+matches_selector(const nlohmann::json& meta_json, const char* path,
+                 const std::map<std::string, std::string>& selector)
+{
+    // As for labels, keys can contain `/`, so we fetch the whole map.
+    if(!meta_json.contains(nlohmann::json::json_pointer(path)))
+    {
+        return false;
+    }
+    std::unordered_map<std::string, std::string> values_map;
+    meta_json.at(nlohmann::json::json_pointer(path)).get_to(values_map);
+    for(const auto& [key, value] : selector)
+    {
+        auto it = values_map.find(key);
+        if(it == values_map.end() || it->second != value)
+        {
+            return false;
+        }
+    }
+    return true;
+}
+
+bool inline my_plugin::is_in_scope(const resource_layout& layout)
+{
+    if(!m_namespaces.empty())
+    {
+        // Namespaces are matched by name, the other resources by the
+        // namespace they belong to. Cluster-scoped resources are always kept.
+        auto path = layout.kind.compare("Namespace") == 0 ? NAME_PATH
+                                                           : NAMESPACE_PATH;
+        if(layout.meta.contains(nlohmann::json::json_pointer(path)))
+        {
+            std::string ns;
+            layout.meta.at(nlohmann::json::json_pointer(path)).get_to(ns);
+            if(!ns.empty() && m_namespaces.count(ns) == 0)
+            {
+                return false;
+            }
+        }
+    }
+
+    auto labels = m_label_selectors.find(layout.kind);
+    if(labels != m_label_selectors.end() &&
+       !matches_selector(layout.meta, LABELS_PATH, labels->second))
+    {
+        return false;
+    }
+
+    auto annotations = m_annotation_selectors.find(layout.kind);
+    if(annotations != m_annotation_selectors.end() &&
+       !matches_selector(layout.meta, ANNOTATIONS_PATH, annotations->second))
+    {
+        return false;
+    }
+    return true;
+}
+
 void inline my_plugin::parse_added_modified_resource(nlohmann::json& json_event,
                                                      std::string& resource_uid,
                                                      std::string& resource_kind)
@@ -1163,6 +1408,16 @@ void inline my_plugin::parse_added_modified_resource(nlohmann::json& json_event,
         res_layout.meta = nlohmann::json::parse(meta_string);
     }
 
+    // Scoping only needs the meta, so out of scope resources are dropped
+    // before parsing the rest. Resources going out of scope after an update
+    // are removed as well.
+    if(!is_in_scope(res_layout))
+    {
+        SPDLOG_DEBUG("{} '{}' is out of scope", resource_kind, resource_uid);
+        parse_deleted_resource(json_event, resource_uid, resource_kind);
+        return;
+    }
+
     if(json_event.contains(nlohmann::json::json_pointer(SPEC_PATH)))
     {
         std::string spec_string;
@@ -1195,6 +1450,10 @@ void inline my_plugin::parse_added_modified_resource(nlohmann::json& json_event,
     ADD_MODIFY_TABLE_ENTRY("ReplicationController",
                            m_replication_controller_table)
     ADD_MODIFY_TABLE_ENTRY("DeamonSet", m_deamonset_table)
+    ADD_MODIFY_TABLE_ENTRY("Job", m_job_table)
+    ADD_MODIFY_TABLE_ENTRY("CronJob", m_cronjob_table)
+    ADD_MODIFY_TABLE_ENTRY("NetworkPolicy", m_network_policy_table)
+    ADD_MODIFY_TABLE_ENTRY("HorizontalPodAutoscaler", m_hpa_table)
 }
 
 void inline my_plugin::parse_deleted_resource(nlohmann::json& json_event,
@@ -1208,6 +1467,10 @@ void inline my_plugin::parse_deleted_resource(nlohmann::json& json_event,
     DELETE_TABLE_ENTRY("ReplicaSet", m_replicaset_table)
     DELETE_TABLE_ENTRY("ReplicationController", m_replication_controller_table)
     DELETE_TABLE_ENTRY("DeamonSet", m_deamonset_table)
+    DELETE_TABLE_ENTRY("Job", m_job_table)
+    DELETE_TABLE_ENTRY("CronJob", m_cronjob_table)
+    DELETE_TABLE_ENTRY("NetworkPolicy", m_network_policy_table)
+    DELETE_TABLE_ENTRY("HorizontalPodAutoscaler", m_hpa_table)
 }
 
 bool inline my_plugin::parse_async_event(
@@ -23,6 +23,8 @@ limitations under the License.
 #include <atomic>
 #include <chrono>
 #include <unordered_map>
+#include <unordered_set>
+#include <map>
 #include <sstream>
 
 struct resource_layout
@@ -84,6 +86,22 @@ class my_plugin
         K8S_RC_UID,
         K8S_RC_LABEL,
         K8S_RC_LABELS,
+        K8S_JOB_NAME,
+        K8S_JOB_UID,
+        K8S_JOB_LABEL,
+        K8S_JOB_LABELS,
+        K8S_CRONJOB_NAME,
+        K8S_CRONJOB_UID,
+        K8S_CRONJOB_LABEL,
+        K8S_CRONJOB_LABELS,
+        K8S_NETPOL_NAME,
+        K8S_NETPOL_UID,
+        K8S_NETPOL_LABEL,
+        K8S_NETPOL_LABELS,
+        K8S_HPA_NAME,
+        K8S_HPA_UID,
+        K8S_HPA_LABEL,
+        K8S_HPA_LABELS,
         K8S_FIELD_MAX
     };
 
@@ -95,6 +113,10 @@ class my_plugin
         SVC,
         RS,
         RC,
+        JOB,
+        CRONJOB,
+        NETPOL,
+        HPA,
     };
 
     //////////////////////////
@@ -171,10 +193,16 @@ class my_plugin
                         const falcosecurity::table_writer& tw);
     std::vector<falcosecurity::field_info> get_fields();
 
+    std::unordered_map<std::string, resource_layout>*
+    get_table(enum K8sResource resource);
+
     bool inline get_uid_array(nlohmann::json& pod_refs_json,
                               enum K8sResource resource,
                               std::vector<std::string>& uid_array);
 
+    bool inline get_hpa_uid_array(nlohmann::json& pod_refs_json,
+                                  std::vector<std::string>& uid_array);
+
     bool inline get_layout(nlohmann::json& pod_refs_json,
                            enum K8sResource resource, resource_layout& layout);
 
@@ -240,6 +268,8 @@ class my_plugin
         return PARSE_EVENT_CODES;
     }
 
+    bool inline is_in_scope(const resource_layout& layout);
+
     void inline parse_added_modified_resource(nlohmann::json& json_event,
                                               std::string& resource_uid,
                                               std::string& resource_kind);
@@ -267,6 +297,14 @@ class my_plugin
     std::string m_collector_port;
     std::string m_node_name;
     std::string m_ca_PEM_encoding;
+    // Resources outside these namespaces are not stored, if not empty
+    std::unordered_set<std::string> m_namespaces;
+    // Resources of these kinds without these labels are not stored
+    std::unordered_map<std::string, std::map<std::string, std::string>>
+            m_label_selectors;
+    // Resources of these kinds without these annotations are not stored
+    std::unordered_map<std::string, std::map<std::string, std::string>>
+            m_annotation_selectors;
 
     // State tables
     std::unordered_map<std::string, resource_layout> m_pod_table;
@@ -277,6 +315,10 @@ class my_plugin
     std::unordered_map<std::string, resource_layout>
             m_replication_controller_table;
     std::unordered_map<std::string, resource_layout> m_deamonset_table;
+    std::unordered_map<std::string, resource_layout> m_job_table;
+    std::unordered_map<std::string, resource_layout> m_cronjob_table;
+    std::unordered_map<std::string, resource_layout> m_network_policy_table;
+    std::unordered_map<std::string, resource_layout> m_hpa_table;
 
     // Last error of the plugin
     std::string m_lasterr;
@@ -92,12 +92,18 @@ limitations under the License.
 #define NAME_PATH "/name"
 #define NAMESPACE_PATH "/namespace"
 #define LABELS_PATH "/labels"
+#define ANNOTATIONS_PATH "/annotations"
 #define POD_IP_PATH "/podIP"
 #define SPEC_PATH "/spec"
 #define STATUS_PATH "/status"
 #define REFS_PATH "/refs"
+#define SCALE_TARGET_KIND_PATH "/scaleTargetRef/kind"
+#define SCALE_TARGET_NAME_PATH "/scaleTargetRef/name"
 #define VERBOSITY_PATH "/verbosity"
 #define HOSTNAME_PATH "/collectorHostname"
 #define PORT_PATH "/collectorPort"
 #define NODENAME_PATH "/nodeName"
 #define CA_CERT_PATH "/caPEMBundle"
+#define NAMESPACES_PATH "/namespaces"
+#define LABEL_SELECTOR_PATH "/labelSelector"
+#define ANNOTATION_SELECTOR_PATH "/annotationSelector"
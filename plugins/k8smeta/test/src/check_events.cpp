@@ -175,6 +175,39 @@ class k8s_json_extractor
     uint32_t m_num_events;
 };
 
+// Craft a resource event, as sent by the k8s-metacollector.
+static std::string resource_event(const std::string& reason,
+                                  const std::string& kind,
+                                  const std::string& uid,
+                                  const nlohmann::json& meta,
+                                  const nlohmann::json& spec = nullptr,
+                                  const nlohmann::json& refs = nullptr)
+{
+    nlohmann::json evt = {{"reason", reason},
+                          {"kind", kind},
+                          {"uid", uid},
+                          {"meta", meta.dump()}};
+    if(!spec.is_null())
+    {
+        evt["spec"] = spec.dump();
+    }
+    if(!refs.is_null())
+    {
+        evt["refs"] = refs;
+    }
+    return evt.dump();
+}
+
+// Feed the plugin with a resource event, without going through the collector.
+#define ADD_RESOURCE_EVENT(_payload)                                           \
+    {                                                                          \
+        std::string payload = _payload;                                        \
+        add_event_advance_ts(increasing_ts(), (uint64_t)-1, PPME_ASYNCEVENT_E, \
+                             3, (uint32_t)0, ASYNC_EVENT_NAME,                 \
+                             scap_const_sized_buffer{payload.c_str(),          \
+                                                     payload.size() + 1});     \
+    }
+
 // Check plugin basic APIs
 TEST_F(sinsp_with_test_input, plugin_k8s_basic_API)
 {
@@ -255,6 +288,22 @@ TEST_F(sinsp_with_test_input, plugin_k8s_fields_existance)
     ASSERT_TRUE(field_exists(evt, "k8smeta.rc.uid", pl_flist));
     ASSERT_TRUE(field_exists(evt, "k8smeta.rc.label[exists]", pl_flist));
     ASSERT_TRUE(field_exists(evt, "k8smeta.rc.labels", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.job.name", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.job.uid", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.job.label[exists]", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.job.labels", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.cronjob.name", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.cronjob.uid", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.cronjob.label[exists]", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.cronjob.labels", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.netpol.name", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.netpol.uid", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.netpol.label[exists]", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.netpol.labels", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.hpa.name", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.hpa.uid", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.hpa.label[exists]", pl_flist));
+    ASSERT_TRUE(field_exists(evt, "k8smeta.hpa.labels", pl_flist));
 
     // The label field must always have an argument with `[]` notation
     ASSERT_THROW(field_exists(evt, "k8smeta.pod.label.notexists", pl_flist),
@@ -676,3 +725,262 @@ TEST_F(sinsp_with_test_input, plugin_k8s_update_a_pod)
               "10.16.1.20");
     m_inspector.close();
 }
+
+// Check job and cron job fields, the cron job being found through the job
+// owning the pod when the pod refs don't contain it
+TEST_F(sinsp_with_test_input, plugin_k8s_pod_with_job_and_cronjob)
+{
+    std::shared_ptr<sinsp_plugin> plugin_owner;
+    filter_check_list pl_flist;
+    ASSERT_PLUGIN_INITIALIZATION(plugin_owner, pl_flist)
+
+    // Open test inspector
+    add_default_init_thread();
+    open_inspector();
+
+    std::string cronjob_uid = "6f0d3a4c-4c8e-4b8e-9d49-0d3f1c2a7b11";
+    std::string job_uid = "9b1e2c3d-7a4f-4e2b-8c1d-2f3e4a5b6c22";
+    std::string pod_uid = "3c4d5e6f-8b9a-4c1d-9e2f-3a4b5c6d7e33";
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "CronJob", cronjob_uid,
+            {{"name", "backup"},
+             {"namespace", "default"},
+             {"labels", {{"app", "backup"}}}}));
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "Job", job_uid,
+            {{"name", "backup-28000000"},
+             {"namespace", "default"},
+             {"labels", {{"job-name", "backup-28000000"}}}},
+            nullptr,
+            {{"resources", {{"CronJob", {{"list", {cronjob_uid}}}}}}}));
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "Pod", pod_uid,
+            {{"name", "backup-28000000-x7k2p"}, {"namespace", "default"}},
+            nullptr, {{"resources", {{"Job", {{"list", {job_uid}}}}}}}));
+
+    sinsp_evt* evt = NULL;
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+
+    // K8S_JOB_NAME
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.job.name", pl_flist),
+              "backup-28000000");
+
+    // K8S_JOB_UID
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.job.uid", pl_flist), job_uid);
+
+    // K8S_JOB_LABEL
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.job.label[no]", pl_flist));
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.job.label[job-name]",
+                                  pl_flist),
+              "backup-28000000");
+
+    // K8S_JOB_LABELS
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.job.labels", pl_flist),
+              "(job-name:backup-28000000)");
+
+    // K8S_CRONJOB_NAME
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.cronjob.name", pl_flist),
+              "backup");
+
+    // K8S_CRONJOB_UID
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.cronjob.uid", pl_flist),
+              cronjob_uid);
+
+    // K8S_CRONJOB_LABEL
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.cronjob.label[app]",
+                                  pl_flist),
+              "backup");
+
+    // K8S_CRONJOB_LABELS
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.cronjob.labels", pl_flist),
+              "(app:backup)");
+
+    // Once the job is deleted, its cron job cannot be found anymore
+    ADD_RESOURCE_EVENT(resource_event(REASON_DELETE, "Job", job_uid,
+                                      nlohmann::json::object()));
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.job.name", pl_flist));
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.cronjob.name", pl_flist));
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.cronjob.uid", pl_flist));
+
+    m_inspector.close();
+}
+
+// Check network policy and autoscaler fields, the autoscaler being found
+// through the deployment it scales when the pod refs don't contain it
+TEST_F(sinsp_with_test_input, plugin_k8s_pod_with_netpol_and_hpa)
+{
+    std::shared_ptr<sinsp_plugin> plugin_owner;
+    filter_check_list pl_flist;
+    ASSERT_PLUGIN_INITIALIZATION(plugin_owner, pl_flist)
+
+    // Open test inspector
+    add_default_init_thread();
+    open_inspector();
+
+    std::string deployment_uid = "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c44";
+    std::string hpa_uid = "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d55";
+    std::string other_ns_hpa_uid = "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f66";
+    std::string netpol_uid = "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a77";
+    std::string pod_uid = "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b88";
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "Deployment", deployment_uid,
+            {{"name", "web"},
+             {"namespace", "default"},
+             {"labels", {{"app", "web"}}}}));
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "HorizontalPodAutoscaler", hpa_uid,
+            {{"name", "web-hpa"},
+             {"namespace", "default"},
+             {"labels", {{"tier", "frontend"}}}},
+            {{"scaleTargetRef", {{"kind", "Deployment"}, {"name", "web"}}}}));
+    // Same target name, but in another namespace: it must not match.
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "HorizontalPodAutoscaler", other_ns_hpa_uid,
+            {{"name", "web-hpa"}, {"namespace", "prod"}},
+            {{"scaleTargetRef", {{"kind", "Deployment"}, {"name", "web"}}}}));
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "NetworkPolicy", netpol_uid,
+            {{"name", "deny-all"},
+             {"namespace", "default"},
+             {"labels", {{"policy", "deny"}}}}));
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "Pod", pod_uid,
+            {{"name", "web-5d8f7c9b4d-q2w3e"}, {"namespace", "default"}},
+            nullptr,
+            {{"resources",
+              {{"Deployment", {{"list", {deployment_uid}}}},
+               {"NetworkPolicy", {{"list", {netpol_uid}}}}}}}));
+
+    sinsp_evt* evt = NULL;
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+
+    // K8S_NETPOL_NAME
+    // This field is a list so we have this `( )` notation
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.netpol.name", pl_flist),
+              "(deny-all)");
+
+    // K8S_NETPOL_UID
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.netpol.uid", pl_flist),
+              "(" + netpol_uid + ")");
+
+    // K8S_NETPOL_LABEL
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.netpol.label[no]", pl_flist));
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.netpol.label[policy]",
+                                  pl_flist),
+              "(deny)");
+
+    // K8S_NETPOL_LABELS
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.netpol.labels", pl_flist),
+              "(policy:deny)");
+
+    // K8S_HPA_NAME
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.hpa.name", pl_flist),
+              "web-hpa");
+
+    // K8S_HPA_UID
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.hpa.uid", pl_flist), hpa_uid);
+
+    // K8S_HPA_LABEL
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.hpa.label[tier]", pl_flist),
+              "frontend");
+
+    // K8S_HPA_LABELS
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.hpa.labels", pl_flist),
+              "(tier:frontend)");
+
+    // Once the autoscaler is deleted, the one in the other namespace is not
+    // reported in its place
+    ADD_RESOURCE_EVENT(resource_event(REASON_DELETE, "HorizontalPodAutoscaler",
+                                      hpa_uid, nlohmann::json::object()));
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.hpa.name", pl_flist));
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.hpa.uid", pl_flist));
+
+    m_inspector.close();
+}
+
+// Check that out of scope resources are not stored
+TEST_F(sinsp_with_test_input, plugin_k8s_scoping)
+{
+    auto plugin_owner = m_inspector.register_plugin(PLUGIN_PATH);
+    ASSERT_TRUE(plugin_owner.get());
+    std::string err;
+    ASSERT_TRUE(plugin_owner->init(
+            R"({"collectorHostname":"localhost","collectorPort":45000,"nodeName":"control-plane",
+"namespaces":["default"],"labelSelector":{"Pod":{"app":"web"}},
+"annotationSelector":{"Deployment":{"falco.org/monitor":"true"}}})",
+            err))
+            << "err: " << err;
+    filter_check_list pl_flist;
+    pl_flist.add_filter_check(m_inspector.new_generic_filtercheck());
+    pl_flist.add_filter_check(sinsp_plugin::new_filtercheck(plugin_owner));
+
+    // Open test inspector
+    add_default_init_thread();
+    open_inspector();
+
+    std::string deployment_uid = "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c99";
+    std::string pod_uid = "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d11";
+    std::string unlabeled_pod_uid = "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e22";
+    std::string other_ns_pod_uid = "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f33";
+    nlohmann::json refs = {
+            {"resources", {{"Deployment", {{"list", {deployment_uid}}}}}}};
+    // The deployment is missing the annotation
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_CREATE, "Deployment", deployment_uid,
+            {{"name", "web"},
+             {"namespace", "default"},
+             {"annotations", {{"falco.org/monitor", "false"}}}}));
+    ADD_RESOURCE_EVENT(resource_event(REASON_CREATE, "Pod", pod_uid,
+                                      {{"name", "web-1"},
+                                       {"namespace", "default"},
+                                       {"labels", {{"app", "web"}}}},
+                                      nullptr, refs));
+    ADD_RESOURCE_EVENT(resource_event(REASON_CREATE, "Pod", unlabeled_pod_uid,
+                                      {{"name", "web-2"},
+                                       {"namespace", "default"},
+                                       {"labels", {{"app", "db"}}}},
+                                      nullptr, refs));
+    ADD_RESOURCE_EVENT(resource_event(REASON_CREATE, "Pod", other_ns_pod_uid,
+                                      {{"name", "web-3"},
+                                       {"namespace", "prod"},
+                                       {"labels", {{"app", "web"}}}},
+                                      nullptr, refs));
+
+    sinsp_evt* evt = NULL;
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.pod.name", pl_flist),
+              "web-1");
+    // The deployment is out of scope, only its uid from the pod refs is known
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.deployment.uid", pl_flist),
+              deployment_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.deployment.name", pl_flist));
+
+    GENERATE_EXECVE_EVENT_FOR_INIT(unlabeled_pod_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.pod.name", pl_flist));
+
+    GENERATE_EXECVE_EVENT_FOR_INIT(other_ns_pod_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.pod.name", pl_flist));
+
+    // Once annotated, the deployment is in scope
+    ADD_RESOURCE_EVENT(resource_event(
+            REASON_UPDATE, "Deployment", deployment_uid,
+            {{"name", "web"},
+             {"namespace", "default"},
+             {"annotations", {{"falco.org/monitor", "true"}}}}));
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+    ASSERT_EQ(get_field_as_string(evt, "k8smeta.deployment.name", pl_flist),
+              "web");
+
+    // While a pod losing the label goes out of scope
+    ADD_RESOURCE_EVENT(resource_event(REASON_UPDATE, "Pod", pod_uid,
+                                      {{"name", "web-1"},
+                                       {"namespace", "default"},
+                                       {"labels", {{"app", "db"}}}},
+                                      nullptr, refs));
+    GENERATE_EXECVE_EVENT_FOR_INIT(pod_uid);
+    ASSERT_FALSE(field_has_value(evt, "k8smeta.pod.name", pl_flist));
+
+    m_inspector.close();
+}
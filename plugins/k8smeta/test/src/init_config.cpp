@@ -104,3 +104,28 @@ TEST_F(sinsp_with_test_input, plugin_k8s_with_host_proc)
                                        err));
     ASSERT_EQ(err, "");
 }
+
+TEST_F(sinsp_with_test_input, plugin_k8s_with_scoping)
+{
+    auto plugin_owner = m_inspector.register_plugin(PLUGIN_PATH);
+    ASSERT_TRUE(plugin_owner.get());
+    std::string err;
+
+    ASSERT_NO_THROW(plugin_owner->init(R"(
+{"collectorHostname":"localhost","collectorPort":45000,"nodeName":"kind-control-plane","namespaces":["default","prod"],"labelSelector":{"Pod":{"app":"nginx"}},"annotationSelector":{"Deployment":{"falco.org/monitor":"true"}}})",
+                                       err));
+    ASSERT_EQ(err, "");
+}
+
+TEST_F(sinsp_with_test_input, plugin_k8s_with_invalid_label_selector)
+{
+    auto plugin_owner = m_inspector.register_plugin(PLUGIN_PATH);
+    ASSERT_TRUE(plugin_owner.get());
+    std::string err;
+
+    // Label values must be strings
+    ASSERT_THROW(plugin_owner->init(R"(
+{"collectorHostname":"localhost","collectorPort":45000,"nodeName":"kind-control-plane","labelSelector":{"Deployment":{"replicas":3}}})",
+                                    err),
+                 sinsp_exception);
+}
@@ -0,0 +1,374 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudwatchlogs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwlogs "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// maxRetryDelay bounds the exponential backoff between the retries of a log stream read failing
+const maxRetryDelay = time.Minute
+
+// Checkpoints holds the next tokens of the log streams read by OpenStreams,
+// persisting them to a file if a path is set, so that reads resume where they stopped
+type Checkpoints struct {
+	mu     sync.Mutex
+	path   string
+	tokens map[string]string
+	// pending holds the next tokens of the batches sent but not yet committed, in order, by log stream
+	pending map[string][]pendingToken
+	// streams holds the log stream of the last event of each pending batch
+	streams map[*types.OutputLogEvent]string
+}
+
+type pendingToken struct {
+	last  *types.OutputLogEvent
+	token string
+}
+
+// streamsAPI is the subset of the CloudwatchLogs API used to read log streams
+type streamsAPI interface {
+	cwlogs.DescribeLogStreamsAPIClient
+	cwlogs.GetLogEventsAPIClient
+}
+
+// LoadCheckpoints returns Checkpoints persisted to path, loading the tokens it already holds.
+// With an empty path, tokens are only kept in memory
+func LoadCheckpoints(path string) (*Checkpoints, error) {
+	c := &Checkpoints{
+		path:    path,
+		tokens:  make(map[string]string),
+		pending: make(map[string][]pendingToken),
+		streams: make(map[*types.OutputLogEvent]string),
+	}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.tokens); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Token returns the next token of a log stream, or an empty string if it has never been read
+func (c *Checkpoints) Token(stream string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[stream]
+}
+
+// Save sets the next token of a log stream and persists all the tokens
+func (c *Checkpoints) Save(stream, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[stream] = token
+	return c.write()
+}
+
+// Commit must be called for every event received from OpenStreams once it is consumed. Events are
+// received in order for each log stream, thus once the last event of a batch is committed the whole
+// batch is consumed, and the next token of its log stream is saved. The batches of the same stream
+// sent before are considered consumed too, so that committing them later never moves the token back
+func (c *Checkpoints) Commit(event *types.OutputLogEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stream, ok := c.streams[event]
+	if !ok {
+		return nil
+	}
+	queue := c.pending[stream]
+	for i, p := range queue {
+		if p.last != event {
+			continue
+		}
+		for _, done := range queue[:i+1] {
+			delete(c.streams, done.last)
+		}
+		if i+1 == len(queue) {
+			delete(c.pending, stream)
+		} else {
+			c.pending[stream] = queue[i+1:]
+		}
+		c.tokens[stream] = p.token
+		return c.write()
+	}
+	return nil
+}
+
+// advance sets the next token of a log stream once a batch is sent, whose last event is last.
+// The token is saved only once last is committed, or right away for empty batches if none
+// of the stream is pending, so that a restart never skips events sent but not yet consumed
+func (c *Checkpoints) advance(stream, token string, last *types.OutputLogEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	queue := c.pending[stream]
+	if last == nil {
+		if len(queue) == 0 {
+			c.tokens[stream] = token
+			return c.write()
+		}
+		// an empty batch follows the pending ones: its token is saved once they are committed
+		queue[len(queue)-1].token = token
+		return nil
+	}
+	c.pending[stream] = append(queue, pendingToken{last: last, token: token})
+	c.streams[last] = stream
+	return nil
+}
+
+// forget drops the pending batches of a log stream no longer read, which will never be committed
+func (c *Checkpoints) forget(stream string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.pending[stream] {
+		delete(c.streams, p.last)
+	}
+	delete(c.pending, stream)
+}
+
+// Retain forgets the tokens of the log streams not in streams, which no longer exist
+func (c *Checkpoints) Retain(streams map[string]bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := false
+	for stream := range c.tokens {
+		if !streams[stream] {
+			delete(c.tokens, stream)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return c.write()
+}
+
+// write persists the tokens, replacing the file atomically so that it is never left partially written
+func (c *Checkpoints) write() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.tokens)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// OpenStreams reads concurrently all the log streams of the log group matching the filter's prefix,
+// discovering the new ones every polling interval. Each stream is read from its token in checkpoints,
+// which is updated once each batch of events is committed by the caller (see Checkpoints.Commit),
+// if not nil.
+// Streams without a token are read from the time shift in past if they exist at opening, and from their
+// beginning if they are discovered later. Reads failing are retried for each stream, while errors are
+// sent only if the log streams can't be listed at opening.
+// The filter pattern is ignored, as it isn't supported when reading single streams.
+func (client *Client) OpenStreams(ctx context.Context, filter *Filter, options *Options, checkpoints *Checkpoints) (chan *types.OutputLogEvent, chan error) {
+	return openStreams(ctx, client, filter, options, checkpoints)
+}
+
+func openStreams(ctx context.Context, api streamsAPI, filter *Filter, options *Options, checkpoints *Checkpoints) (chan *types.OutputLogEvent, chan error) {
+	if options == nil {
+		options = new(Options)
+		options.setDefault()
+	}
+	prefix := filter.LogStreamNamePrefix
+	if prefix == "*" {
+		prefix = ""
+	}
+	startTime := time.Now().Add(-1 * options.Shift).UnixMilli()
+
+	eventC := make(chan *types.OutputLogEvent, options.BufferSize)
+	errC := make(chan error)
+
+	sendErr := func(err error) {
+		select {
+		case errC <- err:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		followed := make(map[string]bool)
+		for {
+			streams, err := listStreams(ctx, api, filter.LogGroupName, prefix, filter.LogStreamNames)
+			if err != nil && len(followed) == 0 {
+				sendErr(err)
+				return
+			}
+			if err == nil && checkpoints != nil {
+				// a failing write is retried by the next one, tokens are kept in memory meanwhile
+				_ = checkpoints.Retain(streams)
+			}
+			for stream := range streams {
+				if followed[stream] {
+					continue
+				}
+				var start *int64
+				if len(followed) == 0 {
+					// the streams of the first discovery start from the time shift, as Open does
+					start = aws.Int64(startTime)
+				}
+				go followStream(ctx, api, filter.LogGroupName, stream, start, options, checkpoints, eventC)
+			}
+			// streams are marked as followed only once all the new ones are started
+			for stream := range streams {
+				followed[stream] = true
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(options.PollingInterval):
+			}
+		}
+	}()
+	return eventC, errC
+}
+
+// listStreams returns the names of the log streams of a log group, matching either a prefix or a list of names
+func listStreams(ctx context.Context, api cwlogs.DescribeLogStreamsAPIClient, logGroupName, prefix string, names []string) (map[string]bool, error) {
+	input := &cwlogs.DescribeLogStreamsInput{
+		LogGroupName: aws.String(logGroupName),
+	}
+	if prefix != "" {
+		input.LogStreamNamePrefix = aws.String(prefix)
+	}
+
+	streams := make(map[string]bool)
+	paginator := cwlogs.NewDescribeLogStreamsPaginator(api, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, stream := range page.LogStreams {
+			streams[*stream.LogStreamName] = true
+		}
+	}
+
+	if prefix == "" && len(names) > 0 {
+		selected := make(map[string]bool)
+		for _, name := range names {
+			if streams[name] {
+				selected[name] = true
+			}
+		}
+		return selected, nil
+	}
+	return streams, nil
+}
+
+// followStream sends the events of a log stream, starting from its token in checkpoints if any,
+// otherwise from start, or from its beginning if start is nil. Failing reads are retried with
+// exponential backoff. It returns when ctx is done or once the stream is deleted, dropping the batches
+// of the stream not yet committed
+func followStream(ctx context.Context, api cwlogs.GetLogEventsAPIClient, logGroupName, stream string, start *int64, options *Options, checkpoints *Checkpoints, eventC chan *types.OutputLogEvent) {
+	input := &cwlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(logGroupName),
+		LogStreamName: aws.String(stream),
+		StartFromHead: aws.Bool(true),
+	}
+	input.StartTime = start
+	if checkpoints != nil {
+		if token := checkpoints.Token(stream); token != "" {
+			input.NextToken = aws.String(token)
+			input.StartTime = nil
+		}
+		defer checkpoints.forget(stream)
+	}
+
+	retryDelay := time.Second
+	for {
+		output, err := api.GetLogEvents(ctx, input)
+		if err != nil {
+			var notFound *types.ResourceNotFoundException
+			if ctx.Err() != nil || errors.As(err, &notFound) {
+				// the stream has been deleted once expired, its token is forgotten at the next discovery
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			retryDelay = min(retryDelay*2, maxRetryDelay)
+			continue
+		}
+		retryDelay = time.Second
+
+		var last *types.OutputLogEvent
+		for _, event := range output.Events {
+			e := event
+			select {
+			case eventC <- &e:
+				last = &e
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// the same token is returned once the end of the stream is reached
+		caughtUp := output.NextForwardToken == nil ||
+			(input.NextToken != nil && *input.NextToken == *output.NextForwardToken)
+		if !caughtUp {
+			input.NextToken = output.NextForwardToken
+			input.StartTime = nil
+			if checkpoints != nil {
+				// a failing write is retried by the next one, tokens are kept in memory meanwhile
+				_ = checkpoints.advance(stream, *output.NextForwardToken, last)
+			}
+		}
+		if caughtUp || len(output.Events) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(options.PollingInterval):
+			}
+		}
+	}
+}
@@ -0,0 +1,464 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudwatchlogs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwlogs "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// response is the outcome of a GetLogEvents call
+type response struct {
+	events []string
+	token  string
+	err    error
+}
+
+// fakeAPI serves scripted responses for each log stream, then reports them as caught up
+type fakeAPI struct {
+	mu        sync.Mutex
+	streams   []string
+	responses map[string][]response
+	inputs    map[string][]cwlogs.GetLogEventsInput
+}
+
+func newFakeAPI(responses map[string][]response) *fakeAPI {
+	f := &fakeAPI{
+		responses: responses,
+		inputs:    make(map[string][]cwlogs.GetLogEventsInput),
+	}
+	for stream := range responses {
+		f.streams = append(f.streams, stream)
+	}
+	return f
+}
+
+func (f *fakeAPI) addStream(stream string, responses []response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams = append(f.streams, stream)
+	f.responses[stream] = responses
+}
+
+func (f *fakeAPI) firstInput(stream string) (cwlogs.GetLogEventsInput, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.inputs[stream]) == 0 {
+		return cwlogs.GetLogEventsInput{}, false
+	}
+	return f.inputs[stream][0], true
+}
+
+func (f *fakeAPI) DescribeLogStreams(_ context.Context, _ *cwlogs.DescribeLogStreamsInput, _ ...func(*cwlogs.Options)) (*cwlogs.DescribeLogStreamsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	output := &cwlogs.DescribeLogStreamsOutput{}
+	for _, stream := range f.streams {
+		output.LogStreams = append(output.LogStreams, types.LogStream{LogStreamName: aws.String(stream)})
+	}
+	return output, nil
+}
+
+func (f *fakeAPI) GetLogEvents(_ context.Context, input *cwlogs.GetLogEventsInput, _ ...func(*cwlogs.Options)) (*cwlogs.GetLogEventsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stream := *input.LogStreamName
+	f.inputs[stream] = append(f.inputs[stream], *input)
+	queue := f.responses[stream]
+	if len(queue) == 0 {
+		// the same token is returned once the end of the stream is reached
+		return &cwlogs.GetLogEventsOutput{NextForwardToken: input.NextToken}, nil
+	}
+	r := queue[0]
+	f.responses[stream] = queue[1:]
+	if r.err != nil {
+		return nil, r.err
+	}
+	output := &cwlogs.GetLogEventsOutput{NextForwardToken: aws.String(r.token)}
+	for _, message := range r.events {
+		output.Events = append(output.Events, types.OutputLogEvent{Message: aws.String(message)})
+	}
+	return output, nil
+}
+
+func receive(t *testing.T, eventC chan *types.OutputLogEvent) *types.OutputLogEvent {
+	t.Helper()
+	select {
+	case event := <-eventC:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event")
+		return nil
+	}
+}
+
+func TestCheckpointsCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		// steps advance and commit the events e of the "a" and "b" streams
+		steps func(c *Checkpoints, e []*types.OutputLogEvent)
+		want  map[string]string
+	}{
+		{
+			name: "batches committed in order",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				_ = c.advance("a", "t2", e[3])
+				_ = c.Commit(e[0])
+				_ = c.Commit(e[1])
+				_ = c.Commit(e[2])
+				_ = c.Commit(e[3])
+			},
+			want: map[string]string{"a": "t2"},
+		},
+		{
+			name: "batches committed out of order",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				_ = c.advance("a", "t2", e[3])
+				_ = c.Commit(e[3])
+				_ = c.Commit(e[1])
+			},
+			want: map[string]string{"a": "t2"},
+		},
+		{
+			name: "batch partially committed",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				_ = c.Commit(e[0])
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "empty batch with nothing pending",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", nil)
+			},
+			want: map[string]string{"a": "t1"},
+		},
+		{
+			name: "empty batch following a pending one",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				_ = c.advance("a", "t2", nil)
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "empty batch following a committed one",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				_ = c.advance("a", "t2", nil)
+				_ = c.Commit(e[1])
+			},
+			want: map[string]string{"a": "t2"},
+		},
+		{
+			name: "streams committed independently",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "ta", e[0])
+				_ = c.advance("b", "tb", e[1])
+				_ = c.Commit(e[1])
+			},
+			want: map[string]string{"b": "tb"},
+		},
+		{
+			name: "stream forgotten before commit",
+			steps: func(c *Checkpoints, e []*types.OutputLogEvent) {
+				_ = c.advance("a", "t1", e[1])
+				c.forget("a")
+				_ = c.Commit(e[1])
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := LoadCheckpoints("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			events := make([]*types.OutputLogEvent, 4)
+			for i := range events {
+				events[i] = &types.OutputLogEvent{}
+			}
+			tt.steps(c, events)
+			if !reflect.DeepEqual(c.tokens, tt.want) {
+				t.Fatalf("expected tokens %v, got %v", tt.want, c.tokens)
+			}
+			// committed and forgotten batches are not retained
+			for stream, queue := range c.pending {
+				for _, p := range queue {
+					if c.streams[p.last] != stream {
+						t.Fatalf("expected pending batch of %q to be indexed", stream)
+					}
+				}
+			}
+			pending := 0
+			for _, queue := range c.pending {
+				pending += len(queue)
+			}
+			if pending != len(c.streams) {
+				t.Fatalf("expected %d indexed pending batches, got %d", pending, len(c.streams))
+			}
+		})
+	}
+}
+
+func TestLoadCheckpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "missing file",
+			want: map[string]string{},
+		},
+		{
+			name:    "saved tokens",
+			content: aws.String(`{"a":"t1","b":"t2"}`),
+			want:    map[string]string{"a": "t1", "b": "t2"},
+		},
+		{
+			name:    "corrupt file",
+			content: aws.String(`{"a":"t1"`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoints.json")
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c, err := LoadCheckpoints(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.tokens, tt.want) {
+				t.Fatalf("expected tokens %v, got %v", tt.want, c.tokens)
+			}
+		})
+	}
+}
+
+func TestCheckpointsSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoints.json")
+	c, err := LoadCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("a", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("b", "t2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Retain(map[string]bool{"b": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"b": "t2"}; !reflect.DeepEqual(loaded.tokens, want) {
+		t.Fatalf("expected tokens %v, got %v", want, loaded.tokens)
+	}
+	// the file is replaced atomically, without leaving temporary files behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the checkpoints file, got %d files", len(entries))
+	}
+
+	// without a path, tokens are only kept in memory
+	c, err = LoadCheckpoints("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("a", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Token("a") != "t1" {
+		t.Fatalf("expected token t1, got %q", c.Token("a"))
+	}
+}
+
+func TestFollowStream(t *testing.T) {
+	notFound := &types.ResourceNotFoundException{Message: aws.String("log stream does not exist")}
+	tests := []struct {
+		name      string
+		responses []response
+		want      []string
+	}{
+		{
+			name: "stream deleted mid-follow",
+			responses: []response{
+				{events: []string{"e1", "e2"}, token: "t1"},
+				{err: notFound},
+			},
+			want: []string{"e1", "e2"},
+		},
+		{
+			name: "read retried after a failure",
+			responses: []response{
+				{err: errors.New("throttled")},
+				{events: []string{"e1"}, token: "t1"},
+				{err: notFound},
+			},
+			want: []string{"e1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(map[string][]response{"s": tt.responses})
+			checkpoints, err := LoadCheckpoints("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			options := CreateOptions(time.Second, 10*time.Millisecond, 10)
+			eventC := make(chan *types.OutputLogEvent, 10)
+			done := make(chan struct{})
+			go func() {
+				followStream(context.Background(), api, "group", "s", nil, options, checkpoints, eventC)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected following to stop once the stream is deleted")
+			}
+			close(eventC)
+
+			var got []string
+			for event := range eventC {
+				got = append(got, *event.Message)
+				// the batches not committed are dropped with the stream
+				if err := checkpoints.Commit(event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected events %v, got %v", tt.want, got)
+			}
+			if token := checkpoints.Token("s"); token != "" {
+				t.Fatalf("expected no token for the deleted stream, got %q", token)
+			}
+			if len(checkpoints.pending) != 0 || len(checkpoints.streams) != 0 {
+				t.Fatal("expected no pending batches for the deleted stream")
+			}
+		})
+	}
+}
+
+func TestOpenStreams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	checkpoints, err := LoadCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the token of a stream which no longer exists is forgotten
+	if err := checkpoints.Save("gone", "tg"); err != nil {
+		t.Fatal(err)
+	}
+
+	api := newFakeAPI(map[string][]response{
+		"a": {{events: []string{"a1"}, token: "ta1"}},
+	})
+	filter := CreateFilter("", "group", "", nil)
+	options := CreateOptions(time.Second, 10*time.Millisecond, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	eventC, _ := openStreams(ctx, api, filter, options, checkpoints)
+
+	event := receive(t, eventC)
+	if *event.Message != "a1" {
+		t.Fatalf("expected event a1, got %s", *event.Message)
+	}
+	if token := checkpoints.Token("a"); token != "" {
+		t.Fatalf("expected the token to be saved only once the event is committed, got %q", token)
+	}
+	if err := checkpoints.Commit(event); err != nil {
+		t.Fatal(err)
+	}
+	if token := checkpoints.Token("a"); token != "ta1" {
+		t.Fatalf("expected token ta1, got %q", token)
+	}
+	if token := checkpoints.Token("gone"); token != "" {
+		t.Fatalf("expected the token of the deleted stream to be forgotten, got %q", token)
+	}
+
+	// streams discovered later are read from their beginning
+	api.addStream("b", []response{{events: []string{"b1"}, token: "tb1"}})
+	if event := receive(t, eventC); *event.Message != "b1" {
+		t.Fatalf("expected event b1, got %s", *event.Message)
+	}
+	cancel()
+	if input, ok := api.firstInput("a"); !ok || input.StartTime == nil {
+		t.Fatal("expected the streams of the first discovery to be read from the time shift")
+	}
+	if input, ok := api.firstInput("b"); !ok || input.StartTime != nil {
+		t.Fatal("expected the streams discovered later to be read from their beginning")
+	}
+
+	// once restarted, reads resume from the committed tokens
+	loaded, err := LoadCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = newFakeAPI(map[string][]response{"a": nil})
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	_, _ = openStreams(ctx, api, filter, options, loaded)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if input, ok := api.firstInput("a"); ok {
+			if input.NextToken == nil || *input.NextToken != "ta1" || input.StartTime != nil {
+				t.Fatal("expected the read to resume from token ta1")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the stream to be read")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}